@@ -2,14 +2,17 @@ package custom
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
 )
 
 // CustomAdapter is a template for custom system adapters
 type CustomAdapter struct {
 	adapter.BaseAdapter
 	CustomConfig map[string]interface{}
+	HTTPClient   *http.Client
 }
 
 // NewCustomAdapter creates a new custom adapter
@@ -32,13 +35,20 @@ func NewCustomAdapter(name, adapterType string, description string, customConfig
 	return &CustomAdapter{
 		BaseAdapter:  *base,
 		CustomConfig: customConfig,
+		HTTPClient:   &http.Client{},
 	}
 }
 
+// SetAuth adds credentials from p to calls made with HTTPClient, e.g. OAuth2
+// access tokens
+func (a *CustomAdapter) SetAuth(p authclient.Provider) {
+	authclient.Apply(a.HTTPClient, p)
+}
+
 // Initialize sets up the custom adapter
 func (a *CustomAdapter) Initialize() error {
 	// Log initialization
-	fmt.Printf("Initializing custom adapter: %s\n", a.Name)
+	a.Logger().Info("initializing custom adapter")
 
 	// Validate required configuration
 	if err := a.validateConfig(); err != nil {
@@ -75,7 +85,7 @@ func (a *CustomAdapter) validateConfig() error {
 // setupConnection establishes connection to the target system
 func (a *CustomAdapter) setupConnection() error {
 	// Implement connection logic based on adapter type
-	fmt.Printf("Setting up connection for %s adapter\n", a.Type)
+	a.Logger().Debug("setting up connection", "type", a.Type)
 	return nil
 }
 
@@ -106,7 +116,7 @@ func (a *CustomAdapter) GetCapabilities() (map[string]interface{}, error) {
 
 // ExecuteTask executes a task on the custom system
 func (a *CustomAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
-	fmt.Printf("Executing custom action: %s with params: %v\n", action, params)
+	a.Logger().Debug("executing custom action", "action", action, "params", params)
 
 	// Validate parameters for the requested action
 	if err := a.validateParams(action, params); err != nil {
@@ -174,7 +184,7 @@ func (a *CustomAdapter) executeFileAction(action string, params map[string]inter
 
 // Close cleans up resources
 func (a *CustomAdapter) Close() error {
-	fmt.Println("Closing custom adapter")
+	a.Logger().Info("closing custom adapter")
 
 	// Implement resource cleanup based on adapter type
 	switch a.Type {
@@ -35,6 +35,9 @@ type OracleAdapter struct {
 	DB            interface{} // This would be *sql.DB in actual implementation
 	ConnPoolSize  int
 	ConnTimeout   time.Duration
+
+	// Policy limits the statements and procedures tasks run; nil runs any
+	Policy *adapter.SQLPolicy
 }
 
 // OracleAdapterConfig contains configuration for the Oracle adapter
@@ -125,7 +128,7 @@ func (a *OracleAdapter) buildConnectString() {
 
 // Initialize sets up the Oracle adapter
 func (a *OracleAdapter) Initialize() error {
-	fmt.Println("Initializing Oracle adapter:", a.Name)
+	a.Logger().Info("initializing Oracle adapter")
 
 	// Validate configuration
 	if err := a.validateConfig(); err != nil {
@@ -137,20 +140,20 @@ func (a *OracleAdapter) Initialize() error {
 
 	// In a real implementation, this would establish an Oracle connection
 	// using a library like godror or go-oci8
-	fmt.Printf("Connecting to Oracle database with connection string: %s\n", a.maskConnectString())
+	a.Logger().Info("connecting to Oracle database", "connectString", a.maskConnectString())
 
 	// Simulate connection setup
 	time.Sleep(500 * time.Millisecond)
 
 	// Setup connection pool
-	fmt.Printf("Setting up connection pool with size: %d\n", a.ConnPoolSize)
+	a.Logger().Debug("setting up connection pool", "size", a.ConnPoolSize)
 
 	// Test connection
 	if err := a.testConnection(); err != nil {
 		return fmt.Errorf("connection test failed: %w", err)
 	}
 
-	fmt.Println("Oracle adapter initialized successfully")
+	a.Logger().Info("Oracle adapter initialized")
 	return nil
 }
 
@@ -193,7 +196,7 @@ func (a *OracleAdapter) maskConnectString() string {
 func (a *OracleAdapter) testConnection() error {
 	// In a real implementation, this would ping the database
 	// For simulation, we'll just return success
-	fmt.Println("Testing Oracle connection...")
+	a.Logger().Debug("testing Oracle connection")
 
 	// Simulate a quick database operation
 	time.Sleep(200 * time.Millisecond)
@@ -220,7 +223,7 @@ func (a *OracleAdapter) GetCapabilities() (map[string]interface{}, error) {
 
 // ExecuteTask executes a task on the Oracle system
 func (a *OracleAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
-	fmt.Printf("Executing Oracle action: %s with params: %v\n", action, params)
+	a.Logger().Debug("executing Oracle action", "action", action, "params", params)
 
 	// Switch based on action type
 	switch action {
@@ -254,18 +257,21 @@ func (a *OracleAdapter) handleQuery(params map[string]interface{}) (map[string]i
 	if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "SELECT ") {
 		return nil, fmt.Errorf("invalid SQL query format, must start with SELECT")
 	}
+	if err := a.Policy.CheckStatement("query", query); err != nil {
+		return nil, err
+	}
 
 	// Handle parameters if provided
 	queryParams, _ := params["parameters"].([]interface{})
 	if len(queryParams) > 0 {
-		fmt.Printf("Executing query with %d parameters\n", len(queryParams))
+		a.Logger().Debug("executing query", "paramCount", len(queryParams))
 	}
 
 	// Pagination support
 	offset, _ := params["offset"].(int)
 	limit, _ := params["limit"].(int)
 	if limit > 0 {
-		fmt.Printf("Applying pagination: offset %d, limit %d\n", offset, limit)
+		a.Logger().Debug("applying pagination", "offset", offset, "limit", limit)
 	}
 
 	// In a real implementation, this would execute the query against Oracle
@@ -295,11 +301,14 @@ func (a *OracleAdapter) handleExecute(params map[string]interface{}) (map[string
 		strings.HasPrefix(stmtUpper, "DELETE ")) {
 		return nil, fmt.Errorf("invalid SQL statement format, must be INSERT, UPDATE, or DELETE")
 	}
+	if err := a.Policy.CheckStatement("execute", statement); err != nil {
+		return nil, err
+	}
 
 	// Handle parameters if provided
 	stmtParams, _ := params["parameters"].([]interface{})
 	if len(stmtParams) > 0 {
-		fmt.Printf("Executing statement with %d parameters\n", len(stmtParams))
+		a.Logger().Debug("executing statement", "paramCount", len(stmtParams))
 	}
 
 	// In a real implementation, this would execute the statement against Oracle
@@ -323,6 +332,9 @@ func (a *OracleAdapter) handleProcedure(params map[string]interface{}) (map[stri
 	if !ok || procedure == "" {
 		return nil, fmt.Errorf("procedure parameter is required")
 	}
+	if err := a.Policy.CheckProcedure(procedure); err != nil {
+		return nil, err
+	}
 
 	// Handle input parameters
 	inParams, _ := params["inParams"].(map[string]interface{})
@@ -333,7 +345,7 @@ func (a *OracleAdapter) handleProcedure(params map[string]interface{}) (map[stri
 	// Handle output parameters
 	outParams, _ := params["outParams"].([]string)
 	if len(outParams) > 0 {
-		fmt.Printf("Procedure has %d output parameters\n", len(outParams))
+		a.Logger().Debug("procedure output parameters", "count", len(outParams))
 	}
 
 	// In a real implementation, this would call the stored procedure
@@ -361,6 +373,9 @@ func (a *OracleAdapter) handleFunction(params map[string]interface{}) (map[strin
 	if !ok || function == "" {
 		return nil, fmt.Errorf("function parameter is required")
 	}
+	if err := a.Policy.CheckProcedure(function); err != nil {
+		return nil, err
+	}
 
 	// Handle input parameters
 	inParams, _ := params["parameters"].([]interface{})
@@ -380,6 +395,12 @@ func (a *OracleAdapter) handleBatch(params map[string]interface{}) (map[string]i
 	if !ok || len(statements) == 0 {
 		return nil, fmt.Errorf("statements parameter is required and cannot be empty")
 	}
+	for i, statement := range statements {
+		statement, _ := statement.(string)
+		if err := a.Policy.CheckStatement("execute", statement); err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i, err)
+		}
+	}
 
 	// In a real implementation, this would execute the batch
 	// For simulation, we'll return mock data
@@ -405,6 +426,11 @@ func (a *OracleAdapter) handleTransaction(params map[string]interface{}) (map[st
 	if !ok || len(operations) == 0 {
 		return nil, fmt.Errorf("operations parameter is required and cannot be empty")
 	}
+	for i, operation := range operations {
+		if err := a.checkOperation(operation); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
 
 	// In a real implementation, this would execute the transaction
 	// For simulation, we'll return mock data
@@ -423,6 +449,28 @@ func (a *OracleAdapter) handleTransaction(params map[string]interface{}) (map[st
 	}, nil
 }
 
+// checkOperation checks the query, statement or procedure of a transaction
+// operation against the policy
+func (a *OracleAdapter) checkOperation(operation interface{}) error {
+	if a.Policy == nil {
+		return nil
+	}
+	op, ok := operation.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("operation must be an object")
+	}
+	if query, ok := op["query"].(string); ok {
+		return a.Policy.CheckStatement("query", query)
+	}
+	if statement, ok := op["statement"].(string); ok {
+		return a.Policy.CheckStatement("execute", statement)
+	}
+	if procedure, ok := op["procedure"].(string); ok {
+		return a.Policy.CheckProcedure(procedure)
+	}
+	return fmt.Errorf("operation has no query, statement or procedure")
+}
+
 // handleMetadata handles retrieving database metadata
 func (a *OracleAdapter) handleMetadata(params map[string]interface{}) (map[string]interface{}, error) {
 	objectType, ok := params["objectType"].(string)
@@ -498,11 +546,11 @@ func (a *OracleAdapter) getSchemaMetadata(schemaName string) (map[string]interfa
 
 // Close cleans up resources
 func (a *OracleAdapter) Close() error {
-	fmt.Println("Closing Oracle adapter")
+	a.Logger().Info("closing Oracle adapter")
 
 	// In a real implementation, this would close the database connection
 	if a.DB != nil {
-		fmt.Println("Closing database connection pool")
+		a.Logger().Debug("closing database connection pool")
 		// db.Close() would be called here
 	}
 
@@ -0,0 +1,196 @@
+// The protocol of adapter plugins. The connector starts a plugin with
+// hashicorp/go-plugin and calls the Adapter service over gRPC; plugins in
+// languages other than Go implement the go-plugin handshake and health
+// service as described in its documentation.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: adapter.proto
+
+package plugin
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Adapter_Initialize_FullMethodName      = "/a2aconnector.plugin.v1.Adapter/Initialize"
+	Adapter_GetCapabilities_FullMethodName = "/a2aconnector.plugin.v1.Adapter/GetCapabilities"
+	Adapter_ExecuteTask_FullMethodName     = "/a2aconnector.plugin.v1.Adapter/ExecuteTask"
+)
+
+// AdapterClient is the client API for Adapter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdapterClient interface {
+	// Initialize is called once the plugin started, before any other call
+	Initialize(ctx context.Context, in *InitializeRequest, opts ...grpc.CallOption) (*InitializeResponse, error)
+	// GetCapabilities describes the adapter to agents
+	GetCapabilities(ctx context.Context, in *GetCapabilitiesRequest, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error)
+	// ExecuteTask runs an action of a mapping; calls are concurrent and their
+	// deadline is the adapter timeout
+	ExecuteTask(ctx context.Context, in *ExecuteTaskRequest, opts ...grpc.CallOption) (*ExecuteTaskResponse, error)
+}
+
+type adapterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdapterClient(cc grpc.ClientConnInterface) AdapterClient {
+	return &adapterClient{cc}
+}
+
+func (c *adapterClient) Initialize(ctx context.Context, in *InitializeRequest, opts ...grpc.CallOption) (*InitializeResponse, error) {
+	out := new(InitializeResponse)
+	err := c.cc.Invoke(ctx, Adapter_Initialize_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adapterClient) GetCapabilities(ctx context.Context, in *GetCapabilitiesRequest, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error) {
+	out := new(GetCapabilitiesResponse)
+	err := c.cc.Invoke(ctx, Adapter_GetCapabilities_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adapterClient) ExecuteTask(ctx context.Context, in *ExecuteTaskRequest, opts ...grpc.CallOption) (*ExecuteTaskResponse, error) {
+	out := new(ExecuteTaskResponse)
+	err := c.cc.Invoke(ctx, Adapter_ExecuteTask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdapterServer is the server API for Adapter service.
+// All implementations must embed UnimplementedAdapterServer
+// for forward compatibility
+type AdapterServer interface {
+	// Initialize is called once the plugin started, before any other call
+	Initialize(context.Context, *InitializeRequest) (*InitializeResponse, error)
+	// GetCapabilities describes the adapter to agents
+	GetCapabilities(context.Context, *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error)
+	// ExecuteTask runs an action of a mapping; calls are concurrent and their
+	// deadline is the adapter timeout
+	ExecuteTask(context.Context, *ExecuteTaskRequest) (*ExecuteTaskResponse, error)
+	mustEmbedUnimplementedAdapterServer()
+}
+
+// UnimplementedAdapterServer must be embedded to have forward compatible implementations.
+type UnimplementedAdapterServer struct {
+}
+
+func (UnimplementedAdapterServer) Initialize(context.Context, *InitializeRequest) (*InitializeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Initialize not implemented")
+}
+func (UnimplementedAdapterServer) GetCapabilities(context.Context, *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCapabilities not implemented")
+}
+func (UnimplementedAdapterServer) ExecuteTask(context.Context, *ExecuteTaskRequest) (*ExecuteTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteTask not implemented")
+}
+func (UnimplementedAdapterServer) mustEmbedUnimplementedAdapterServer() {}
+
+// UnsafeAdapterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdapterServer will
+// result in compilation errors.
+type UnsafeAdapterServer interface {
+	mustEmbedUnimplementedAdapterServer()
+}
+
+func RegisterAdapterServer(s grpc.ServiceRegistrar, srv AdapterServer) {
+	s.RegisterService(&Adapter_ServiceDesc, srv)
+}
+
+func _Adapter_Initialize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitializeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServer).Initialize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Adapter_Initialize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServer).Initialize(ctx, req.(*InitializeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Adapter_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCapabilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServer).GetCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Adapter_GetCapabilities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServer).GetCapabilities(ctx, req.(*GetCapabilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Adapter_ExecuteTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServer).ExecuteTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Adapter_ExecuteTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServer).ExecuteTask(ctx, req.(*ExecuteTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Adapter_ServiceDesc is the grpc.ServiceDesc for Adapter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Adapter_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "a2aconnector.plugin.v1.Adapter",
+	HandlerType: (*AdapterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Initialize",
+			Handler:    _Adapter_Initialize_Handler,
+		},
+		{
+			MethodName: "GetCapabilities",
+			Handler:    _Adapter_GetCapabilities_Handler,
+		},
+		{
+			MethodName: "ExecuteTask",
+			Handler:    _Adapter_ExecuteTask_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "adapter.proto",
+}
@@ -0,0 +1,64 @@
+// Package plugin is the protocol of adapter plugins: binaries, written in any
+// language, that the connector runs to adapt a system without forking it.
+// The connector starts a plugin with hashicorp/go-plugin and calls the
+// Adapter service of adapter.proto over gRPC. Plugins written in Go pass
+// their implementation of AdapterServer to Serve.
+package plugin
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative adapter.proto
+
+import (
+	"context"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+// ProtocolVersion is the version of the adapter protocol plugins announce
+// in their handshake
+const ProtocolVersion = 1
+
+// CookieKey is set to CookieValue in the environment of plugins, so a plugin
+// binary can tell it was started by the connector rather than by hand
+const (
+	CookieKey   = "A2A_PLUGIN_COOKIE"
+	CookieValue = "a2a-connector-adapter-plugin"
+)
+
+// Name is the name the Adapter service is dispensed under
+const Name = "adapter"
+
+// Handshake is the go-plugin handshake of adapter plugins
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  ProtocolVersion,
+	MagicCookieKey:   CookieKey,
+	MagicCookieValue: CookieValue,
+}
+
+// GRPCPlugin serves the Adapter service in a plugin, and dispenses an
+// AdapterClient in the connector
+type GRPCPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+	// Impl implements the service; it is only set in plugins
+	Impl AdapterServer
+}
+
+// GRPCServer registers the Adapter service
+func (p *GRPCPlugin) GRPCServer(broker *goplugin.GRPCBroker, s *grpc.Server) error {
+	RegisterAdapterServer(s, p.Impl)
+	return nil
+}
+
+// GRPCClient returns a client of the Adapter service
+func (p *GRPCPlugin) GRPCClient(ctx context.Context, broker *goplugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return NewAdapterClient(conn), nil
+}
+
+// Serve serves server as an adapter plugin until the connector stops it
+func Serve(server AdapterServer) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         goplugin.PluginSet{Name: &GRPCPlugin{Impl: server}},
+		GRPCServer:      goplugin.DefaultGRPCServer,
+	})
+}
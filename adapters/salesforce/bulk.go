@@ -0,0 +1,492 @@
+package salesforce
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// APIVersion is the Salesforce REST API version the adapter calls
+const APIVersion = "v57.0"
+
+// Default Bulk API 2.0 settings used when the adapter leaves them empty
+const (
+	DefaultBulkPollInterval = 5 * time.Second
+	DefaultBulkTimeout      = 10 * time.Minute
+	// DefaultBulkMaxRecords is the number of query results returned at most
+	// by one task; the rest is fetched with the returned locator
+	DefaultBulkMaxRecords = 50000
+)
+
+// Bulk API 2.0 job states
+const (
+	bulkJobComplete    = "JobComplete"
+	bulkFailed         = "Failed"
+	bulkAborted        = "Aborted"
+	bulkUploadComplete = "UploadComplete"
+)
+
+// bulkNull sets a field to null in a bulk ingest; empty values are ignored
+const bulkNull = "#N/A"
+
+var (
+	bulkJobIDPattern = regexp.MustCompile(`^[0-9A-Za-z]{15,18}$`)
+	bulkOperations   = map[string]bool{"insert": true, "update": true, "upsert": true, "delete": true, "hardDelete": true}
+)
+
+// restURL returns the URL of a REST API resource, relative to the version
+func (a *SalesforceAdapter) restURL(path string) string {
+	return strings.TrimRight(a.InstanceURL, "/") + "/services/data/" + APIVersion + "/" + path
+}
+
+// restDo sends a REST API request and returns the response for a 2xx
+// status. GET requests are retried with the retry policy; the others would
+// create or change records and jobs twice.
+func (a *SalesforceAdapter) restDo(method, path, contentType, accept string, body []byte) (*http.Response, error) {
+	var resp *http.Response
+	do := func() error {
+		req, err := http.NewRequest(method, a.restURL(path), bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+a.AccessToken)
+		req.Header.Set("Accept", accept)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err = a.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			defer resp.Body.Close()
+			data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+			httpErr := &adapter.HTTPError{
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+				Method:     method,
+				URL:        req.URL.String(),
+			}
+			// Salesforce reports errors as a list of errorCode and message
+			var errs interface{}
+			if json.Unmarshal(data, &errs) == nil {
+				httpErr.Body = map[string]interface{}{"errors": errs}
+			}
+			return httpErr
+		}
+		return nil
+	}
+	if method != http.MethodGet {
+		return resp, do()
+	}
+	return resp, a.Retry.Do(do)
+}
+
+// restJSON sends a REST API request with a JSON payload, if any, and
+// decodes the JSON response
+func (a *SalesforceAdapter) restJSON(method, path string, payload interface{}) (map[string]interface{}, error) {
+	var body []byte
+	contentType := ""
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+		contentType = "application/json"
+	}
+	resp, err := a.restDo(method, path, contentType, "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	result := map[string]interface{}{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error decoding %s response: %w", path, err)
+	}
+	return result, nil
+}
+
+// waitForJob polls a job until it is complete and fails when it failed, was
+// aborted or is still running after the bulk timeout
+func (a *SalesforceAdapter) waitForJob(kind, id string) (map[string]interface{}, error) {
+	interval, timeout := a.BulkPollInterval, a.BulkTimeout
+	if interval <= 0 {
+		interval = DefaultBulkPollInterval
+	}
+	if timeout <= 0 {
+		timeout = DefaultBulkTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		job, err := a.restJSON(http.MethodGet, "jobs/"+kind+"/"+id, nil)
+		if err != nil {
+			return nil, err
+		}
+		switch job["state"] {
+		case bulkJobComplete:
+			return job, nil
+		case bulkFailed, bulkAborted:
+			return nil, fmt.Errorf("bulk job %s %s: %v", id, strings.ToLower(job["state"].(string)), job["errorMessage"])
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return nil, fmt.Errorf("bulk job %s is still %v after %s; poll it with bulk_status", id, job["state"], timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// jobResult returns the fields of a job the task result reports
+func jobResult(job map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{
+		"job_id": job["id"],
+		"state":  job["state"],
+	}
+	for _, key := range []string{"object", "operation", "numberRecordsProcessed", "numberRecordsFailed", "errorMessage"} {
+		if v, ok := job[key]; ok && v != nil {
+			result[key] = v
+		}
+	}
+	return result
+}
+
+// handleBulkQuery creates a Bulk API 2.0 query job and, unless wait is
+// false, returns its results once it is complete
+func (a *SalesforceAdapter) handleBulkQuery(params map[string]interface{}) (map[string]interface{}, error) {
+	query, err := a.soqlQuery(params)
+	if err != nil {
+		return nil, err
+	}
+	operation := "query"
+	if all, _ := params["all"].(bool); all {
+		operation = "queryAll"
+	}
+
+	job, err := a.restJSON(http.MethodPost, "jobs/query", map[string]interface{}{
+		"operation": operation,
+		"query":     query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	id, _ := job["id"].(string)
+	if wait, ok := params["wait"].(bool); ok && !wait {
+		return jobResult(job), nil
+	}
+	if job, err = a.waitForJob("query", id); err != nil {
+		return nil, err
+	}
+
+	result, err := a.queryResults(id, "", maxRecords(params))
+	if err != nil {
+		return nil, err
+	}
+	for key, v := range jobResult(job) {
+		result[key] = v
+	}
+	return result, nil
+}
+
+func maxRecords(params map[string]interface{}) int {
+	if n, ok := params["max_records"].(float64); ok && n > 0 {
+		return int(n)
+	}
+	if n, ok := params["max_records"].(int); ok && n > 0 {
+		return n
+	}
+	return DefaultBulkMaxRecords
+}
+
+// queryResults reads the results of a query job from locator on, up to max
+// records
+func (a *SalesforceAdapter) queryResults(id, locator string, max int) (map[string]interface{}, error) {
+	records := []map[string]interface{}{}
+	for {
+		query := url.Values{"maxRecords": {strconv.Itoa(max - len(records))}}
+		if locator != "" {
+			query.Set("locator", locator)
+		}
+		resp, err := a.restDo(http.MethodGet, "jobs/query/"+id+"/results?"+query.Encode(), "", "text/csv", nil)
+		if err != nil {
+			return nil, err
+		}
+		page, err := readCSVRecords(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+
+		locator = resp.Header.Get("Sforce-Locator")
+		if locator == "null" {
+			locator = ""
+		}
+		if locator == "" || len(records) >= max {
+			break
+		}
+	}
+
+	result := map[string]interface{}{
+		"records":   records,
+		"totalSize": len(records),
+		"done":      locator == "",
+	}
+	if locator != "" {
+		result["locator"] = locator
+	}
+	return result, nil
+}
+
+// handleBulkIngest uploads records as CSV to a Bulk API 2.0 ingest job and,
+// unless wait is false, returns the outcome and the failed records once it
+// is complete
+func (a *SalesforceAdapter) handleBulkIngest(params map[string]interface{}) (map[string]interface{}, error) {
+	objectType, ok := params["object"].(string)
+	if !ok || objectType == "" {
+		return nil, fmt.Errorf("object parameter is required")
+	}
+	operation, _ := params["operation"].(string)
+	if operation == "" {
+		operation = "insert"
+	}
+	if !bulkOperations[operation] {
+		return nil, fmt.Errorf("unsupported bulk operation: %s", operation)
+	}
+	spec := map[string]interface{}{
+		"object":      objectType,
+		"operation":   operation,
+		"contentType": "CSV",
+		"lineEnding":  "LF",
+	}
+	if operation == "upsert" {
+		externalField, _ := params["external_id_field"].(string)
+		if externalField == "" {
+			return nil, fmt.Errorf("external_id_field parameter is required for upsert")
+		}
+		spec["externalIdFieldName"] = externalField
+	}
+	records, _ := params["records"].([]interface{})
+	if len(records) == 0 {
+		return nil, fmt.Errorf("records parameter is required and cannot be empty")
+	}
+	data, err := recordsCSV(records)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := a.restJSON(http.MethodPost, "jobs/ingest", spec)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := job["id"].(string)
+	resp, err := a.restDo(http.MethodPut, "jobs/ingest/"+id+"/batches", "text/csv", "application/json", data)
+	if err != nil {
+		a.abortJob(id)
+		return nil, err
+	}
+	resp.Body.Close()
+	if job, err = a.restJSON(http.MethodPatch, "jobs/ingest/"+id, map[string]interface{}{"state": bulkUploadComplete}); err != nil {
+		return nil, err
+	}
+	if wait, ok := params["wait"].(bool); ok && !wait {
+		return jobResult(job), nil
+	}
+	if job, err = a.waitForJob("ingest", id); err != nil {
+		return nil, err
+	}
+
+	result := jobResult(job)
+	failed, err := a.ingestResults(id, "failedResults")
+	if err != nil {
+		return nil, err
+	}
+	result["failed"] = failed
+	return result, nil
+}
+
+// abortJob aborts an ingest job whose upload failed, so it does not stay open
+func (a *SalesforceAdapter) abortJob(id string) {
+	if _, err := a.restJSON(http.MethodPatch, "jobs/ingest/"+id, map[string]interface{}{"state": bulkAborted}); err != nil {
+		slog.Warn("bulk job was not aborted", logging.KeyError, err, "job", id)
+	}
+}
+
+// ingestResults reads the successfulResults, failedResults or
+// unprocessedrecords of an ingest job
+func (a *SalesforceAdapter) ingestResults(id, kind string) ([]map[string]interface{}, error) {
+	resp, err := a.restDo(http.MethodGet, "jobs/ingest/"+id+"/"+kind, "", "text/csv", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return readCSVRecords(resp.Body)
+}
+
+// handleBulkStatus returns the state of a query or ingest job
+func (a *SalesforceAdapter) handleBulkStatus(params map[string]interface{}) (map[string]interface{}, error) {
+	kind, id, err := bulkJob(params)
+	if err != nil {
+		return nil, err
+	}
+	job, err := a.restJSON(http.MethodGet, "jobs/"+kind+"/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return jobResult(job), nil
+}
+
+// handleBulkResults returns the records of a complete query job from an
+// optional locator on, or the successful, failed or unprocessed records of
+// an ingest job
+func (a *SalesforceAdapter) handleBulkResults(params map[string]interface{}) (map[string]interface{}, error) {
+	kind, id, err := bulkJob(params)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "query" {
+		locator, _ := params["locator"].(string)
+		result, err := a.queryResults(id, locator, maxRecords(params))
+		if err != nil {
+			return nil, err
+		}
+		result["job_id"] = id
+		return result, nil
+	}
+
+	results, _ := params["results"].(string)
+	switch results {
+	case "", "failed":
+		results = "failedResults"
+	case "successful":
+		results = "successfulResults"
+	case "unprocessed":
+		results = "unprocessedrecords"
+	default:
+		return nil, fmt.Errorf("results must be successful, failed or unprocessed")
+	}
+	records, err := a.ingestResults(id, results)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"job_id":    id,
+		"records":   records,
+		"totalSize": len(records),
+	}, nil
+}
+
+// bulkJob returns the kind (query or ingest) and ID of the job of params
+func bulkJob(params map[string]interface{}) (string, string, error) {
+	id, _ := params["job_id"].(string)
+	if !bulkJobIDPattern.MatchString(id) {
+		return "", "", fmt.Errorf("job_id parameter is required and must be a Salesforce ID")
+	}
+	kind, _ := params["type"].(string)
+	switch kind {
+	case "", "query":
+		return "query", id, nil
+	case "ingest":
+		return "ingest", id, nil
+	}
+	return "", "", fmt.Errorf("type must be query or ingest")
+}
+
+// recordsCSV writes records as the CSV of an ingest job. Columns are the
+// fields of all records; nested objects become relationship columns like
+// Account.ExternalId__c and null values are sent as #N/A.
+func recordsCSV(records []interface{}) ([]byte, error) {
+	rows := make([]map[string]string, len(records))
+	columns := map[string]bool{}
+	for i, r := range records {
+		record, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("record %d is not an object", i)
+		}
+		rows[i] = map[string]string{}
+		flattenRecord("", record, rows[i])
+		for column := range rows[i] {
+			columns[column] = true
+		}
+	}
+	header := make([]string, 0, len(columns))
+	for column := range columns {
+		header = append(header, column)
+	}
+	sort.Strings(header)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(header)
+	for _, row := range rows {
+		line := make([]string, len(header))
+		for i, column := range header {
+			line[i] = row[column]
+		}
+		w.Write(line)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func flattenRecord(prefix string, record map[string]interface{}, row map[string]string) {
+	for key, v := range record {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			flattenRecord(prefix+key+".", value, row)
+		case nil:
+			row[prefix+key] = bulkNull
+		case string:
+			row[prefix+key] = value
+		case float64:
+			row[prefix+key] = strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			row[prefix+key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// readCSVRecords reads bulk results; CSV has no null, so empty values are
+// returned as null
+func readCSVRecords(r io.Reader) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading bulk results: %w", err)
+	}
+	records := []map[string]interface{}{}
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading bulk results: %w", err)
+		}
+		record := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if i < len(line) && line[i] != "" {
+				record[column] = line[i]
+			} else {
+				record[column] = nil
+			}
+		}
+		records = append(records, record)
+	}
+}
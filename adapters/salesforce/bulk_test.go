@@ -0,0 +1,166 @@
+package salesforce_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/adapters/salesforce"
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+)
+
+const jobsPath = "/services/data/" + salesforce.APIVersion + "/jobs/"
+
+func newBulkAdapter(t *testing.T, handler http.HandlerFunc) *salesforce.SalesforceAdapter {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	a := salesforce.NewSalesforceAdapter("crm", server.URL, "user", "pass", "token", "client", "secret", nil)
+	if err := a.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	a.BulkPollInterval = time.Millisecond
+	return a
+}
+
+func TestBulkQuery(t *testing.T) {
+	polls := 0
+	a := newBulkAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			t.Errorf("Expected the access token on %s", r.URL.Path)
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == jobsPath+"query":
+			var spec map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&spec)
+			if spec["operation"] != "queryAll" || spec["query"] != "SELECT Id, Name FROM Account" {
+				t.Errorf("Unexpected job %v", spec)
+			}
+			w.Write([]byte(`{"id":"7501x000002AbcdAAC","state":"UploadComplete"}`))
+		case r.URL.Path == jobsPath+"query/7501x000002AbcdAAC":
+			polls++
+			state := "InProgress"
+			if polls > 1 {
+				state = "JobComplete"
+			}
+			w.Write([]byte(`{"id":"7501x000002AbcdAAC","state":"` + state + `","numberRecordsProcessed":3}`))
+		case r.URL.Path == jobsPath+"query/7501x000002AbcdAAC/results":
+			if r.URL.Query().Get("locator") == "" {
+				w.Header().Set("Sforce-Locator", "MjAwMDAw")
+				w.Write([]byte("\"Id\",\"Name\"\n\"0011\",\"Acme, Inc.\"\n\"0012\",\"\"\n"))
+				return
+			}
+			w.Header().Set("Sforce-Locator", "null")
+			w.Write([]byte("\"Id\",\"Name\"\n\"0013\",\"Globex\"\n"))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+	})
+
+	result, err := a.ExecuteTask("bulk_query", map[string]interface{}{"query": "SELECT Id, Name FROM Account", "all": true})
+	if err != nil {
+		t.Fatalf("Bulk query failed: %v", err)
+	}
+	records := result["records"].([]map[string]interface{})
+	if len(records) != 3 || records[0]["Name"] != "Acme, Inc." || records[1]["Name"] != nil || result["done"] != true {
+		t.Errorf("Expected the records of both result pages, got %v", result)
+	}
+	if polls != 2 || result["state"] != "JobComplete" {
+		t.Errorf("Expected the job to be polled until complete, polled %d times: %v", polls, result)
+	}
+
+	// A smaller max returns a locator to continue with
+	result, err = a.ExecuteTask("bulk_results", map[string]interface{}{"job_id": "7501x000002AbcdAAC", "max_records": 2.0})
+	if err != nil {
+		t.Fatalf("Bulk results failed: %v", err)
+	}
+	if result["locator"] != "MjAwMDAw" || result["done"] != false {
+		t.Errorf("Expected a locator for the remaining records, got %v", result)
+	}
+}
+
+func TestBulkIngest(t *testing.T) {
+	var uploaded string
+	a := newBulkAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == jobsPath+"ingest":
+			var spec map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&spec)
+			if spec["operation"] != "upsert" || spec["externalIdFieldName"] != "ExtId__c" || spec["contentType"] != "CSV" {
+				t.Errorf("Unexpected job %v", spec)
+			}
+			w.Write([]byte(`{"id":"7501x000002IngsAAC","state":"Open"}`))
+		case r.Method == http.MethodPut && r.URL.Path == jobsPath+"ingest/7501x000002IngsAAC/batches":
+			data, _ := ioutil.ReadAll(r.Body)
+			uploaded = string(data)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && r.URL.Path == jobsPath+"ingest/7501x000002IngsAAC":
+			w.Write([]byte(`{"id":"7501x000002IngsAAC","state":"UploadComplete"}`))
+		case r.Method == http.MethodGet && r.URL.Path == jobsPath+"ingest/7501x000002IngsAAC":
+			w.Write([]byte(`{"id":"7501x000002IngsAAC","state":"JobComplete","object":"Account","numberRecordsProcessed":2,"numberRecordsFailed":1}`))
+		case r.URL.Path == jobsPath+"ingest/7501x000002IngsAAC/failedResults":
+			w.Write([]byte("\"sf__Id\",\"sf__Error\",\"ExtId__c\",\"Name\"\n\"\",\"REQUIRED_FIELD_MISSING:Required fields are missing: [Name]\",\"A-2\",\"\"\n"))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+	})
+
+	result, err := a.ExecuteTask("bulk_ingest", map[string]interface{}{
+		"object":            "Account",
+		"operation":         "upsert",
+		"external_id_field": "ExtId__c",
+		"records": []interface{}{
+			map[string]interface{}{"ExtId__c": "A-1", "Name": "Acme", "Employees": 120.0, "Parent": map[string]interface{}{"ExtId__c": "P-1"}},
+			map[string]interface{}{"ExtId__c": "A-2", "Name": nil},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Bulk ingest failed: %v", err)
+	}
+	want := "Employees,ExtId__c,Name,Parent.ExtId__c\n120,A-1,Acme,P-1\n,A-2,#N/A,\n"
+	if uploaded != want {
+		t.Errorf("Expected CSV\n%s\ngot\n%s", want, uploaded)
+	}
+	failed := result["failed"].([]map[string]interface{})
+	if result["numberRecordsFailed"] != 1.0 || len(failed) != 1 || failed[0]["ExtId__c"] != "A-2" {
+		t.Errorf("Expected the failed record, got %v", result)
+	}
+}
+
+func TestBulkJobFailed(t *testing.T) {
+	a := newBulkAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"id":"7501x000002FailAAC","state":"UploadComplete"}`))
+		case r.URL.Path == jobsPath+"query/7501x000002FailAAC":
+			w.Write([]byte(`{"id":"7501x000002FailAAC","state":"Failed","errorMessage":"INVALID_FIELD: No such column 'Foo'"}`))
+		case r.URL.Path == jobsPath+"query/7501x000002MissAAC":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`[{"errorCode":"NOT_FOUND","message":"The requested resource does not exist"}]`))
+		}
+	})
+
+	_, err := a.ExecuteTask("bulk_query", map[string]interface{}{"query": "SELECT Foo FROM Account"})
+	if err == nil || !strings.Contains(err.Error(), "No such column") {
+		t.Errorf("Expected the job error, got %v", err)
+	}
+
+	result, err := a.ExecuteTask("bulk_query", map[string]interface{}{"query": "SELECT Id FROM Account", "wait": false})
+	if err != nil || result["job_id"] != "7501x000002FailAAC" {
+		t.Errorf("Expected the job without waiting, got %v, %v", result, err)
+	}
+
+	_, err = a.ExecuteTask("bulk_status", map[string]interface{}{"job_id": "7501x000002MissAAC"})
+	var httpErr *adapter.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound || httpErr.Body["errors"] == nil {
+		t.Errorf("Expected the Salesforce error, got %v", err)
+	}
+	if _, err := a.ExecuteTask("bulk_status", map[string]interface{}{"job_id": "../sobjects"}); err == nil {
+		t.Error("Expected an invalid job ID to be rejected")
+	}
+}
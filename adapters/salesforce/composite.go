@@ -0,0 +1,254 @@
+package salesforce
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// MaxCompositeRequests is the number of subrequests one Composite API call
+// may hold
+const MaxCompositeRequests = 25
+
+var (
+	// compositeNamePattern matches reference IDs and object and field names
+	compositeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	// compositeReference is a reference to the result of an earlier
+	// subrequest, e.g. @{NewAccount.id} or @{Contacts.records[0].Id}
+	compositeReference = regexp.MustCompile(`@\{[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z0-9_\[\]]+)*\}`)
+	compositeMethods   = map[string]bool{"GET": true, "POST": true, "PATCH": true, "PUT": true, "DELETE": true, "HEAD": true}
+)
+
+// handleComposite sends dependent subrequests in one Composite API call. A
+// subrequest refers to the results of earlier ones with
+// @{reference_id.field} in its URL or body. With all_or_none, the default,
+// a failed subrequest rolls back the others and fails the task; otherwise
+// the result reports each outcome.
+func (a *SalesforceAdapter) handleComposite(params map[string]interface{}) (map[string]interface{}, error) {
+	items, _ := params["requests"].([]interface{})
+	if len(items) == 0 {
+		return nil, fmt.Errorf("requests parameter is required and cannot be empty")
+	}
+	if len(items) > MaxCompositeRequests {
+		return nil, fmt.Errorf("a composite request holds at most %d subrequests, got %d", MaxCompositeRequests, len(items))
+	}
+	allOrNone := true
+	if v, ok := params["all_or_none"].(bool); ok {
+		allOrNone = v
+	}
+
+	subrequests := make([]map[string]interface{}, 0, len(items))
+	references := make(map[string]bool, len(items))
+	for i, item := range items {
+		spec, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("request %d must be an object", i+1)
+		}
+		sub, err := a.compositeSubrequest(spec)
+		if err != nil {
+			return nil, fmt.Errorf("request %d: %w", i+1, err)
+		}
+		ref, _ := spec["reference_id"].(string)
+		if ref == "" {
+			ref = fmt.Sprintf("request%d", i+1)
+		}
+		if !compositeNamePattern.MatchString(ref) {
+			return nil, fmt.Errorf("request %d: invalid reference_id %q", i+1, ref)
+		}
+		if references[ref] {
+			return nil, fmt.Errorf("request %d: duplicate reference_id %q", i+1, ref)
+		}
+		references[ref] = true
+		sub["referenceId"] = ref
+		subrequests = append(subrequests, sub)
+	}
+
+	response, err := a.restJSON(http.MethodPost, "composite", map[string]interface{}{
+		"allOrNone":        allOrNone,
+		"compositeRequest": subrequests,
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries, _ := response["compositeResponse"].([]interface{})
+	results := make([]map[string]interface{}, 0, len(entries))
+	var failed map[string]interface{}
+	for _, entry := range entries {
+		r, _ := entry.(map[string]interface{})
+		status, _ := r["httpStatusCode"].(float64)
+		result := map[string]interface{}{
+			"reference_id": r["referenceId"],
+			"status":       int(status),
+			"body":         r["body"],
+		}
+		results = append(results, result)
+		// Rolled back subrequests report PROCESSING_HALTED; the cause is the
+		// subrequest that failed with another error
+		if status >= 400 && failed == nil && compositeErrorCode(r["body"]) != "PROCESSING_HALTED" {
+			failed = result
+		}
+	}
+	if failed != nil && allOrNone {
+		return nil, fmt.Errorf("composite subrequest %v failed with HTTP %d: %s; all subrequests were rolled back",
+			failed["reference_id"], failed["status"], compositeErrorMessage(failed["body"]))
+	}
+	return map[string]interface{}{
+		"results": results,
+		"success": failed == nil,
+	}, nil
+}
+
+// compositeSubrequest builds a subrequest from a method and url relative to
+// the API version, or from one of the record actions of the adapter
+func (a *SalesforceAdapter) compositeSubrequest(spec map[string]interface{}) (map[string]interface{}, error) {
+	if action, ok := spec["action"].(string); ok && action != "" {
+		method, path, body, err := a.compositeAction(action, spec)
+		if err != nil {
+			return nil, err
+		}
+		sub := map[string]interface{}{
+			"method": method,
+			"url":    "/services/data/" + APIVersion + "/" + path,
+		}
+		if body != nil {
+			sub["body"] = body
+		}
+		return sub, nil
+	}
+
+	method, _ := spec["method"].(string)
+	method = strings.ToUpper(method)
+	if !compositeMethods[method] {
+		return nil, fmt.Errorf("method or action is required, got method %q", method)
+	}
+	path, _ := spec["url"].(string)
+	if path == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if !strings.HasPrefix(path, "/services/data/") {
+		path = "/services/data/" + APIVersion + "/" + strings.TrimLeft(path, "/")
+	}
+	sub := map[string]interface{}{
+		"method": method,
+		"url":    path,
+	}
+	if body, ok := spec["body"]; ok && body != nil {
+		sub["body"] = body
+	}
+	return sub, nil
+}
+
+// compositeAction maps a query, get, create, update, upsert or delete onto
+// the method, path and body of its REST API call
+func (a *SalesforceAdapter) compositeAction(action string, spec map[string]interface{}) (string, string, interface{}, error) {
+	if action == "query" {
+		query, err := a.soqlQuery(spec)
+		if err != nil {
+			return "", "", nil, err
+		}
+		return http.MethodGet, "query?q=" + escapeReferences(query, url.QueryEscape), nil, nil
+	}
+
+	objectType, _ := spec["object"].(string)
+	if !compositeNamePattern.MatchString(objectType) {
+		return "", "", nil, fmt.Errorf("object parameter is required")
+	}
+	path := "sobjects/" + objectType
+	fields, _ := spec["fields"].(map[string]interface{})
+	needFields := func() error {
+		if len(fields) == 0 {
+			return fmt.Errorf("fields parameter is required and cannot be empty")
+		}
+		return nil
+	}
+	id, _ := spec["id"].(string)
+	needID := func() error {
+		if id == "" {
+			return fmt.Errorf("id parameter is required")
+		}
+		path += "/" + escapeReferences(id, url.PathEscape)
+		return nil
+	}
+
+	switch action {
+	case "create":
+		return http.MethodPost, path, fields, needFields()
+	case "get":
+		if err := needID(); err != nil {
+			return "", "", nil, err
+		}
+		if names, _ := spec["fields"].([]interface{}); len(names) > 0 {
+			list := make([]string, len(names))
+			for i, name := range names {
+				list[i] = fmt.Sprint(name)
+			}
+			path += "?fields=" + url.QueryEscape(strings.Join(list, ","))
+		}
+		return http.MethodGet, path, nil, nil
+	case "update":
+		if err := needID(); err != nil {
+			return "", "", nil, err
+		}
+		return http.MethodPatch, path, fields, needFields()
+	case "delete":
+		if err := needID(); err != nil {
+			return "", "", nil, err
+		}
+		return http.MethodDelete, path, nil, nil
+	case "upsert":
+		externalField, _ := spec["external_field"].(string)
+		if !compositeNamePattern.MatchString(externalField) {
+			return "", "", nil, fmt.Errorf("external_field parameter is required")
+		}
+		externalValue, _ := spec["external_value"].(string)
+		if externalValue == "" {
+			return "", "", nil, fmt.Errorf("external_value parameter is required")
+		}
+		path += "/" + externalField + "/" + escapeReferences(externalValue, url.PathEscape)
+		return http.MethodPatch, path, fields, needFields()
+	default:
+		return "", "", nil, fmt.Errorf("unsupported composite action: %s", action)
+	}
+}
+
+// escapeReferences escapes s for a URL but keeps its references to other
+// subrequests, which Salesforce resolves before the URL is decoded
+func escapeReferences(s string, escape func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range compositeReference.FindAllStringIndex(s, -1) {
+		b.WriteString(escape(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(escape(s[last:]))
+	return b.String()
+}
+
+// compositeErrorCode returns the code of the first error in the body of a
+// failed subrequest
+func compositeErrorCode(body interface{}) string {
+	errs, _ := body.([]interface{})
+	if len(errs) == 0 {
+		return ""
+	}
+	e, _ := errs[0].(map[string]interface{})
+	code, _ := e["errorCode"].(string)
+	return code
+}
+
+// compositeErrorMessage joins the errors in the body of a failed subrequest
+func compositeErrorMessage(body interface{}) string {
+	errs, _ := body.([]interface{})
+	messages := make([]string, 0, len(errs))
+	for _, item := range errs {
+		e, _ := item.(map[string]interface{})
+		messages = append(messages, fmt.Sprintf("%v: %v", e["errorCode"], e["message"]))
+	}
+	if len(messages) == 0 {
+		return fmt.Sprint(body)
+	}
+	return strings.Join(messages, "; ")
+}
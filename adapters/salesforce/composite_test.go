@@ -0,0 +1,86 @@
+package salesforce_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/adapters/salesforce"
+)
+
+const compositePath = "/services/data/" + salesforce.APIVersion + "/composite"
+
+func TestComposite(t *testing.T) {
+	var sent map[string]interface{}
+	a := newBulkAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != compositePath {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"compositeResponse":[
+			{"referenceId":"NewAccount","httpStatusCode":201,"body":{"id":"001R0000003fEt1IAE","success":true}},
+			{"referenceId":"request2","httpStatusCode":201,"body":{"id":"003R00000025REHIA2","success":true}},
+			{"referenceId":"Contacts","httpStatusCode":200,"body":{"totalSize":1,"done":true,"records":[]}}
+		]}`))
+	})
+
+	result, err := a.ExecuteTask("composite", map[string]interface{}{
+		"requests": []interface{}{
+			map[string]interface{}{"action": "create", "object": "Account", "reference_id": "NewAccount", "fields": map[string]interface{}{"Name": "Acme"}},
+			map[string]interface{}{"action": "create", "object": "Contact", "fields": map[string]interface{}{"LastName": "Smith", "AccountId": "@{NewAccount.id}"}},
+			map[string]interface{}{"action": "query", "reference_id": "Contacts", "query": "SELECT Id FROM Contact WHERE AccountId = '@{NewAccount.id}'"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Composite failed: %v", err)
+	}
+	if sent["allOrNone"] != true {
+		t.Errorf("Expected all or none by default, got %v", sent)
+	}
+	subrequests := sent["compositeRequest"].([]interface{})
+	account := subrequests[0].(map[string]interface{})
+	if account["method"] != "POST" || account["url"] != "/services/data/v57.0/sobjects/Account" || account["referenceId"] != "NewAccount" {
+		t.Errorf("Unexpected subrequest %v", account)
+	}
+	contact := subrequests[1].(map[string]interface{})
+	if contact["referenceId"] != "request2" || contact["body"].(map[string]interface{})["AccountId"] != "@{NewAccount.id}" {
+		t.Errorf("Expected the reference in the body, got %v", contact)
+	}
+	query := subrequests[2].(map[string]interface{})["url"].(string)
+	if !strings.HasSuffix(query, "query?q=SELECT+Id+FROM+Contact+WHERE+AccountId+%3D+%27@{NewAccount.id}%27") {
+		t.Errorf("Expected the reference to stay unescaped in the query, got %s", query)
+	}
+	results := result["results"].([]map[string]interface{})
+	if result["success"] != true || len(results) != 3 || results[1]["status"] != 201 {
+		t.Errorf("Unexpected result %v", result)
+	}
+}
+
+func TestCompositeFailure(t *testing.T) {
+	a := newBulkAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"compositeResponse":[
+			{"referenceId":"NewAccount","httpStatusCode":400,"body":[{"errorCode":"PROCESSING_HALTED","message":"The transaction was rolled back since another operation in the same transaction failed."}]},
+			{"referenceId":"NewContact","httpStatusCode":400,"body":[{"errorCode":"REQUIRED_FIELD_MISSING","message":"Required fields are missing: [LastName]"}]}
+		]}`))
+	})
+	requests := []interface{}{
+		map[string]interface{}{"method": "post", "url": "sobjects/Account", "reference_id": "NewAccount", "body": map[string]interface{}{"Name": "Acme"}},
+		map[string]interface{}{"action": "update", "object": "Contact", "id": "@{NewAccount.id}", "reference_id": "NewContact", "fields": map[string]interface{}{"LastName": ""}},
+	}
+
+	_, err := a.ExecuteTask("composite", map[string]interface{}{"requests": requests})
+	if err == nil || !strings.Contains(err.Error(), "NewContact") || !strings.Contains(err.Error(), "REQUIRED_FIELD_MISSING") {
+		t.Errorf("Expected the failed subrequest to fail the task, got %v", err)
+	}
+
+	result, err := a.ExecuteTask("composite", map[string]interface{}{"requests": requests, "all_or_none": false})
+	if err != nil || result["success"] != false {
+		t.Errorf("Expected the outcomes without all_or_none, got %v, %v", result, err)
+	}
+
+	duplicate := []interface{}{requests[0], requests[0]}
+	if _, err := a.ExecuteTask("composite", map[string]interface{}{"requests": duplicate}); err == nil {
+		t.Error("Expected a duplicate reference_id to be rejected")
+	}
+}
@@ -1,6 +1,7 @@
 package salesforce
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -8,6 +9,8 @@ import (
 	"time"
 
 	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/tlsclient"
 )
 
 // AuthResponse represents the Salesforce OAuth response
@@ -32,6 +35,18 @@ type SalesforceAdapter struct {
 	AccessToken    string
 	TokenExpiresAt time.Time
 	HTTPClient     *http.Client
+
+	// Retry retries transient failures; nil runs every call once
+	Retry *resilience.RetryPolicy
+
+	// BulkPollInterval and BulkTimeout set how often and how long bulk jobs
+	// are polled; DefaultBulkPollInterval and DefaultBulkTimeout when zero
+	BulkPollInterval time.Duration
+	BulkTimeout      time.Duration
+
+	// StructuredQueries rejects SOQL query strings, so queries can only be
+	// built from object, fields and where params
+	StructuredQueries bool
 }
 
 // NewSalesforceAdapter creates a new Salesforce adapter
@@ -49,9 +64,15 @@ func NewSalesforceAdapter(name, instanceURL, username, password, securityToken,
 	}
 }
 
+// SetTLSConfig sets the TLS used toward Salesforce, e.g. a client certificate
+// or a corporate CA of an intercepting proxy; nil keeps the default
+func (a *SalesforceAdapter) SetTLSConfig(tlsConfig *tls.Config) error {
+	return tlsclient.Apply(a.HTTPClient, tlsConfig)
+}
+
 // Initialize sets up the Salesforce adapter
 func (a *SalesforceAdapter) Initialize() error {
-	fmt.Printf("Initializing Salesforce adapter: %s at %s\n", a.Name, a.InstanceURL)
+	a.Logger().Info("initializing Salesforce adapter", "url", a.InstanceURL)
 
 	// Validate configuration
 	if err := a.validateConfig(); err != nil {
@@ -63,7 +84,7 @@ func (a *SalesforceAdapter) Initialize() error {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
 
-	fmt.Println("Salesforce adapter initialized successfully")
+	a.Logger().Info("Salesforce adapter initialized")
 	return nil
 }
 
@@ -93,7 +114,7 @@ func (a *SalesforceAdapter) validateConfig() error {
 
 // authenticate performs OAuth authentication with Salesforce
 func (a *SalesforceAdapter) authenticate() error {
-	fmt.Println("Authenticating with Salesforce...")
+	a.Logger().Debug("authenticating with Salesforce")
 
 	// In a real implementation, this would make an OAuth request
 	// For simulation, we'll set a mock token
@@ -123,9 +144,9 @@ func (a *SalesforceAdapter) GetCapabilities() (map[string]interface{}, error) {
 	// using the Salesforce Metadata API or Describe API
 	capabilities := map[string]interface{}{
 		"type":         "salesforce",
-		"version":      "v57.0", // Salesforce API version
+		"version":      APIVersion,
 		"objects":      []string{"Account", "Contact", "Opportunity", "Lead", "Case", "Custom__c"},
-		"operations":   []string{"query", "create", "update", "delete", "upsert", "describe"},
+		"operations":   []string{"query", "create", "update", "delete", "upsert", "describe", "bulk_query", "bulk_ingest", "bulk_status", "bulk_results", "composite"},
 		"bulk_support": true,
 	}
 
@@ -139,9 +160,26 @@ func (a *SalesforceAdapter) ExecuteTask(action string, params map[string]interfa
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	fmt.Printf("Executing Salesforce action: %s with params: %v\n", action, params)
+	a.Logger().Debug("executing Salesforce action", "action", action, "params", params)
 
-	// Route the action to the appropriate handler
+	// A retried bulk action would create its job twice and a retried
+	// composite request its records; restDo retries the requests that can be
+	// repeated instead
+	if strings.HasPrefix(action, "bulk_") || action == "composite" {
+		return a.dispatch(action, params)
+	}
+
+	var result map[string]interface{}
+	err := a.Retry.Do(func() error {
+		var err error
+		result, err = a.dispatch(action, params)
+		return err
+	})
+	return result, err
+}
+
+// dispatch routes the action to the appropriate handler
+func (a *SalesforceAdapter) dispatch(action string, params map[string]interface{}) (map[string]interface{}, error) {
 	switch action {
 	case "query":
 		return a.handleQuery(params)
@@ -157,6 +195,16 @@ func (a *SalesforceAdapter) ExecuteTask(action string, params map[string]interfa
 		return a.handleDescribe(params)
 	case "execute_apex":
 		return a.handleExecuteApex(params)
+	case "bulk_query":
+		return a.handleBulkQuery(params)
+	case "bulk_ingest":
+		return a.handleBulkIngest(params)
+	case "bulk_status":
+		return a.handleBulkStatus(params)
+	case "bulk_results":
+		return a.handleBulkResults(params)
+	case "composite":
+		return a.handleComposite(params)
 	default:
 		return nil, fmt.Errorf("unsupported Salesforce action: %s", action)
 	}
@@ -164,14 +212,8 @@ func (a *SalesforceAdapter) ExecuteTask(action string, params map[string]interfa
 
 // handleQuery handles a SOQL query
 func (a *SalesforceAdapter) handleQuery(params map[string]interface{}) (map[string]interface{}, error) {
-	query, ok := params["query"].(string)
-	if !ok || query == "" {
-		return nil, fmt.Errorf("query parameter is required")
-	}
-
-	// Validate SOQL query (basic check)
-	if !strings.HasPrefix(strings.ToUpper(query), "SELECT ") {
-		return nil, fmt.Errorf("invalid SOQL query format, must start with SELECT")
+	if _, err := a.soqlQuery(params); err != nil {
+		return nil, err
 	}
 
 	// Simulate query result
@@ -360,7 +402,7 @@ func (a *SalesforceAdapter) handleExecuteApex(params map[string]interface{}) (ma
 
 // Close cleans up resources
 func (a *SalesforceAdapter) Close() error {
-	fmt.Println("Closing Salesforce adapter")
+	a.Logger().Info("closing Salesforce adapter")
 	// In a real implementation, this would revoke the OAuth token
 	a.AccessToken = ""
 	return nil
@@ -0,0 +1,210 @@
+package salesforce
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// soqlFieldPattern matches a field, or a relationship path such as
+	// Account.Owner.Name
+	soqlFieldPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)*$`)
+	soqlOrderPattern = regexp.MustCompile(`(?i)^([A-Za-z][A-Za-z0-9_.]*)( (ASC|DESC))?( NULLS (FIRST|LAST))?$`)
+	// soqlLiteralPattern matches date and datetime literals and date
+	// keywords such as TODAY or LAST_N_DAYS:30, which SOQL does not quote
+	soqlLiteralPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2}))?|[A-Z][A-Z_]*(:\d+)?)$`)
+	soqlOperators      = map[string]bool{"=": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "LIKE": true, "IN": true, "NOT IN": true, "INCLUDES": true, "EXCLUDES": true}
+	soqlEscaper        = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\b", `\b`, "\f", `\f`)
+)
+
+// soqlQuery returns the SOQL query of a task: the query param as is, or a
+// query built from object, fields, where, order_by, limit and offset. With
+// StructuredQueries set only the latter is accepted.
+func (a *SalesforceAdapter) soqlQuery(params map[string]interface{}) (string, error) {
+	if query, ok := params["query"].(string); ok && query != "" {
+		if a.StructuredQueries {
+			return "", fmt.Errorf("SOQL query strings are disabled; pass object, fields and where instead")
+		}
+		if !strings.HasPrefix(strings.ToUpper(query), "SELECT ") {
+			return "", fmt.Errorf("invalid SOQL query format, must start with SELECT")
+		}
+		return query, nil
+	}
+	if _, ok := params["object"]; !ok {
+		return "", fmt.Errorf("query or object parameter is required")
+	}
+	return buildSOQL(params)
+}
+
+// buildSOQL builds a SOQL query from structured params, validating names
+// and quoting values so they cannot change the query
+func buildSOQL(params map[string]interface{}) (string, error) {
+	objectType, _ := params["object"].(string)
+	if !compositeNamePattern.MatchString(objectType) {
+		return "", fmt.Errorf("invalid object %q", objectType)
+	}
+	fields := []string{"Id"}
+	if list, ok := params["fields"].([]interface{}); ok && len(list) > 0 {
+		fields = fields[:0]
+		for _, item := range list {
+			field, _ := item.(string)
+			if !soqlFieldPattern.MatchString(field) {
+				return "", fmt.Errorf("invalid field %v", item)
+			}
+			fields = append(fields, field)
+		}
+	}
+	var b strings.Builder
+	b.WriteString("SELECT " + strings.Join(fields, ", ") + " FROM " + objectType)
+
+	conditions, err := soqlConditions(params["where"])
+	if err != nil {
+		return "", err
+	}
+	if len(conditions) > 0 {
+		b.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+
+	var order []string
+	switch v := params["order_by"].(type) {
+	case string:
+		order = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			order = append(order, fmt.Sprint(item))
+		}
+	}
+	for _, o := range order {
+		if !soqlOrderPattern.MatchString(o) {
+			return "", fmt.Errorf("invalid order_by %q", o)
+		}
+	}
+	if len(order) > 0 {
+		b.WriteString(" ORDER BY " + strings.Join(order, ", "))
+	}
+
+	for _, clause := range []string{"limit", "offset"} {
+		v, ok := params[clause]
+		if !ok {
+			continue
+		}
+		n, ok := v.(float64)
+		if i, isInt := v.(int); isInt {
+			n, ok = float64(i), true
+		}
+		if !ok || n < 0 || n != float64(int64(n)) {
+			return "", fmt.Errorf("%s must be a non-negative integer, got %v", clause, v)
+		}
+		b.WriteString(" " + strings.ToUpper(clause) + " " + strconv.FormatInt(int64(n), 10))
+	}
+	return b.String(), nil
+}
+
+// soqlConditions builds the conditions of where: an object of fields and the
+// values they equal, or a list of conditions with a field, an op and a value,
+// or a literal for dates and date keywords
+func soqlConditions(where interface{}) ([]string, error) {
+	switch w := where.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		fields := make([]string, 0, len(w))
+		for field := range w {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		conditions := make([]string, 0, len(w))
+		for _, field := range fields {
+			c, err := soqlCondition(map[string]interface{}{"field": field, "value": w[field]})
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, c)
+		}
+		return conditions, nil
+	case []interface{}:
+		conditions := make([]string, 0, len(w))
+		for _, item := range w {
+			spec, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("where conditions must be objects, got %v", item)
+			}
+			c, err := soqlCondition(spec)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, c)
+		}
+		return conditions, nil
+	default:
+		return nil, fmt.Errorf("where must be an object or a list of conditions")
+	}
+}
+
+func soqlCondition(spec map[string]interface{}) (string, error) {
+	field, _ := spec["field"].(string)
+	if !soqlFieldPattern.MatchString(field) {
+		return "", fmt.Errorf("invalid field %v in where", spec["field"])
+	}
+	op, _ := spec["op"].(string)
+	op = strings.ToUpper(strings.Join(strings.Fields(op), " "))
+	if op == "" {
+		op = "="
+	}
+	if !soqlOperators[op] {
+		return "", fmt.Errorf("unsupported operator %q for %s", op, field)
+	}
+
+	if literal, ok := spec["literal"].(string); ok {
+		if !soqlLiteralPattern.MatchString(literal) {
+			return "", fmt.Errorf("invalid date literal %q for %s", literal, field)
+		}
+		return field + " " + op + " " + literal, nil
+	}
+	value := spec["value"]
+	if list, ok := value.([]interface{}); ok {
+		if op != "IN" && op != "NOT IN" && op != "INCLUDES" && op != "EXCLUDES" {
+			return "", fmt.Errorf("a list value for %s needs IN, NOT IN, INCLUDES or EXCLUDES", field)
+		}
+		if len(list) == 0 {
+			return "", fmt.Errorf("empty list value for %s", field)
+		}
+		values := make([]string, len(list))
+		for i, item := range list {
+			v, err := soqlValue(item)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", field, err)
+			}
+			values[i] = v
+		}
+		return field + " " + op + " (" + strings.Join(values, ", ") + ")", nil
+	}
+	v, err := soqlValue(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", field, err)
+	}
+	return field + " " + op + " " + v, nil
+}
+
+// soqlValue returns value as a SOQL literal, quoting and escaping strings
+func soqlValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case string:
+		return "'" + soqlEscaper.Replace(v) + "'", nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
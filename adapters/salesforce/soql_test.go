@@ -0,0 +1,55 @@
+package salesforce_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestStructuredQuery(t *testing.T) {
+	var query interface{}
+	a := newBulkAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		var spec map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&spec)
+		query = spec["query"]
+		w.Write([]byte(`{"id":"7501x000002AbcdAAC","state":"UploadComplete"}`))
+	})
+	a.StructuredQueries = true
+
+	_, err := a.ExecuteTask("bulk_query", map[string]interface{}{
+		"object": "Contact",
+		"fields": []interface{}{"Id", "Name", "Account.Name"},
+		"where": []interface{}{
+			map[string]interface{}{"field": "LastName", "value": `O'Brien\' OR Name != '`},
+			map[string]interface{}{"field": "MailingCountry", "op": "not  in", "value": []interface{}{"US", "CA"}},
+			map[string]interface{}{"field": "CreatedDate", "op": ">=", "literal": "LAST_N_DAYS:30"},
+			map[string]interface{}{"field": "HasOptedOutOfEmail", "value": false},
+		},
+		"order_by": []interface{}{"Name DESC NULLS LAST"},
+		"limit":    100.0,
+		"wait":     false,
+	})
+	if err != nil {
+		t.Fatalf("Structured query failed: %v", err)
+	}
+	want := `SELECT Id, Name, Account.Name FROM Contact WHERE LastName = 'O\'Brien\\\' OR Name != \'' AND MailingCountry NOT IN ('US', 'CA') AND CreatedDate >= LAST_N_DAYS:30 AND HasOptedOutOfEmail = false ORDER BY Name DESC NULLS LAST LIMIT 100`
+	if query != want {
+		t.Errorf("Expected\n%s\ngot\n%v", want, query)
+	}
+
+	invalid := []map[string]interface{}{
+		{"query": "SELECT Id FROM Contact"},
+		{"object": "Contact WHERE Id != null"},
+		{"object": "Contact", "fields": []interface{}{"Id FROM User"}},
+		{"object": "Contact", "where": map[string]interface{}{"Name = 'x' OR Id": "y"}},
+		{"object": "Contact", "where": []interface{}{map[string]interface{}{"field": "Name", "op": "= 'x' OR Name =", "value": "y"}}},
+		{"object": "Contact", "where": []interface{}{map[string]interface{}{"field": "CreatedDate", "literal": "2024-01-01 OR Id != null"}}},
+		{"object": "Contact", "order_by": "Name; DELETE"},
+		{"object": "Contact", "limit": "10 OFFSET 5"},
+	}
+	for _, params := range invalid {
+		if _, err := a.ExecuteTask("query", params); err == nil {
+			t.Errorf("Expected %v to be rejected", params)
+		}
+	}
+}
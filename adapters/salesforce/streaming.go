@@ -0,0 +1,389 @@
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// Replay positions of channels without a stored replay ID
+const (
+	ReplayNew = -1
+	ReplayAll = -2
+)
+
+// DefaultConnectTimeout is how long a long-polling connect may take; the
+// Streaming API answers within 110 seconds when no event arrives
+const DefaultConnectTimeout = 2 * time.Minute
+
+// errHandshake is returned when the server advises a new handshake, e.g.
+// after the client ID expired
+var errHandshake = errors.New("server advised a new handshake")
+
+// Subscriber receives Change Data Capture, platform and PushTopic events
+// from the CometD (Bayeux) endpoint of the Salesforce Streaming API. Events
+// are handled in order; the replay ID of the last handled event of each
+// channel is kept, in ReplayFile when set, so a reconnect or restart resumes
+// after it and events are received at least once.
+type Subscriber struct {
+	InstanceURL string
+	Channels    []string
+	// Replay is where channels without a stored replay ID start: ReplayNew
+	// (the default) or ReplayAll for the events of the retention window
+	Replay     int64
+	ReplayFile string
+	// HTTPClient sends the Bayeux messages; it must add the credentials and
+	// keep the cookies of the session
+	HTTPClient *http.Client
+	// Handle is called with each event and its channel; an error reconnects
+	// and receives the event again
+	Handle func(ctx context.Context, channel string, event map[string]interface{}) error
+	// Active reports whether to stay subscribed, e.g. while holding the
+	// leader lease; nil always subscribes
+	Active func() bool
+
+	mu       sync.Mutex
+	replays  map[string]int64
+	clientID string
+}
+
+// NewSubscriber creates a subscriber to channels of the org at instanceURL.
+// The client is copied with a cookie jar and no timeout, since connects are
+// long-polling requests.
+func NewSubscriber(instanceURL string, channels []string, client *http.Client) *Subscriber {
+	streaming := &http.Client{}
+	if client != nil {
+		*streaming = *client
+	}
+	streaming.Timeout = 0
+	if streaming.Jar == nil {
+		streaming.Jar, _ = cookiejar.New(nil)
+	}
+	return &Subscriber{
+		InstanceURL: instanceURL,
+		Channels:    channels,
+		Replay:      ReplayNew,
+		HTTPClient:  streaming,
+	}
+}
+
+// Run subscribes and handles events until ctx is done, reconnecting with
+// backoff after failures
+func (s *Subscriber) Run(ctx context.Context) error {
+	if err := s.loadReplays(); err != nil {
+		return err
+	}
+	backoff := time.Second
+	for ctx.Err() == nil {
+		if s.Active != nil && !s.Active() {
+			sleepContext(ctx, 5*time.Second)
+			continue
+		}
+		err := s.session(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		if err == nil {
+			backoff = time.Second
+			continue
+		}
+		if errors.Is(err, errHandshake) {
+			// Expired sessions are renewed at once, but not in a tight loop
+			backoff = time.Second
+			sleepContext(ctx, backoff)
+			continue
+		}
+		slog.Warn("Salesforce streaming failed", logging.KeyError, err, "retryIn", backoff)
+		sleepContext(ctx, backoff)
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+	s.disconnect()
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// session handshakes, subscribes and connects until the server asks for a
+// new handshake, the subscriber is no longer active or a call fails
+func (s *Subscriber) session(ctx context.Context) error {
+	// A rejected handshake is retried with backoff like any failure
+	if err := s.handshake(ctx); err != nil {
+		return fmt.Errorf("handshake failed: %v", err)
+	}
+	for _, channel := range s.Channels {
+		if err := s.subscribe(ctx, channel); err != nil {
+			return err
+		}
+	}
+	slog.Info("subscribed to Salesforce streaming channels", "channels", s.Channels)
+	for ctx.Err() == nil {
+		if s.Active != nil && !s.Active() {
+			s.disconnect()
+			return nil
+		}
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bayeux is a message of the Bayeux protocol
+type bayeux struct {
+	Channel                  string                 `json:"channel"`
+	ClientID                 string                 `json:"clientId,omitempty"`
+	ID                       string                 `json:"id,omitempty"`
+	Version                  string                 `json:"version,omitempty"`
+	MinimumVersion           string                 `json:"minimumVersion,omitempty"`
+	SupportedConnectionTypes []string               `json:"supportedConnectionTypes,omitempty"`
+	ConnectionType           string                 `json:"connectionType,omitempty"`
+	Subscription             string                 `json:"subscription,omitempty"`
+	Successful               bool                   `json:"successful,omitempty"`
+	Error                    string                 `json:"error,omitempty"`
+	Advice                   *bayeuxAdvice          `json:"advice,omitempty"`
+	Ext                      map[string]interface{} `json:"ext,omitempty"`
+	Data                     map[string]interface{} `json:"data,omitempty"`
+}
+
+type bayeuxAdvice struct {
+	Reconnect string `json:"reconnect,omitempty"`
+	Interval  int    `json:"interval,omitempty"`
+}
+
+// endpoint returns the CometD URL of the API version
+func (s *Subscriber) endpoint() string {
+	return strings.TrimRight(s.InstanceURL, "/") + "/cometd/" + strings.TrimPrefix(APIVersion, "v")
+}
+
+// send posts Bayeux messages and returns the messages of the response
+func (s *Subscriber) send(ctx context.Context, timeout time.Duration, messages ...bayeux) ([]bayeux, error) {
+	body, err := json.Marshal(messages)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%s: %w", resp.Status, errHandshake)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP %s", messages[0].Channel, resp.Status)
+	}
+	var replies []bayeux
+	if err := json.Unmarshal(data, &replies); err != nil {
+		return nil, fmt.Errorf("error decoding %s response: %w", messages[0].Channel, err)
+	}
+	return replies, nil
+}
+
+// reply returns the reply on the meta channel of a request
+func reply(replies []bayeux, channel string) (bayeux, error) {
+	for _, r := range replies {
+		if r.Channel == channel {
+			if !r.Successful {
+				if r.Advice != nil && r.Advice.Reconnect == "handshake" {
+					return r, fmt.Errorf("%s: %s: %w", channel, r.Error, errHandshake)
+				}
+				return r, fmt.Errorf("%s failed: %s", channel, r.Error)
+			}
+			return r, nil
+		}
+	}
+	return bayeux{}, fmt.Errorf("no %s reply", channel)
+}
+
+func (s *Subscriber) handshake(ctx context.Context) error {
+	replies, err := s.send(ctx, time.Minute, bayeux{
+		Channel:                  "/meta/handshake",
+		Version:                  "1.0",
+		MinimumVersion:           "1.0",
+		SupportedConnectionTypes: []string{"long-polling"},
+		Ext:                      map[string]interface{}{"replay": true},
+	})
+	if err != nil {
+		return err
+	}
+	r, err := reply(replies, "/meta/handshake")
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.clientID = r.ClientID
+	s.mu.Unlock()
+	return nil
+}
+
+// subscribe subscribes to channel after its stored replay ID. A replay ID
+// that is no longer retained is dropped for the configured start.
+func (s *Subscriber) subscribe(ctx context.Context, channel string) error {
+	for {
+		replay, stored := s.replayID(channel)
+		replies, err := s.send(ctx, time.Minute, bayeux{
+			Channel:      "/meta/subscribe",
+			ClientID:     s.clientID,
+			Subscription: channel,
+			Ext:          map[string]interface{}{"replay": map[string]int64{channel: replay}},
+		})
+		if err != nil {
+			return err
+		}
+		r, err := reply(replies, "/meta/subscribe")
+		if err != nil && stored && strings.Contains(r.Error, "replayId") {
+			slog.Warn("stored replay ID is no longer retained; events after it may be lost", "channel", channel, "replayId", replay)
+			s.mu.Lock()
+			delete(s.replays, channel)
+			s.mu.Unlock()
+			continue
+		}
+		return err
+	}
+}
+
+// connect waits for events and handles them
+func (s *Subscriber) connect(ctx context.Context) error {
+	replies, err := s.send(ctx, DefaultConnectTimeout, bayeux{
+		Channel:        "/meta/connect",
+		ClientID:       s.clientID,
+		ConnectionType: "long-polling",
+	})
+	if err != nil {
+		return err
+	}
+
+	handled := false
+	defer func() {
+		if handled {
+			if err := s.saveReplays(); err != nil {
+				slog.Warn("replay IDs were not saved", logging.KeyError, err)
+			}
+		}
+	}()
+	for _, event := range replies {
+		if strings.HasPrefix(event.Channel, "/meta/") || event.Data == nil {
+			continue
+		}
+		data := map[string]interface{}{"channel": event.Channel}
+		for key, v := range event.Data {
+			data[key] = v
+		}
+		if err := s.Handle(ctx, event.Channel, data); err != nil {
+			return fmt.Errorf("event on %s not handled: %w", event.Channel, err)
+		}
+		if meta, ok := event.Data["event"].(map[string]interface{}); ok {
+			if id, ok := meta["replayId"].(float64); ok {
+				s.mu.Lock()
+				s.replays[event.Channel] = int64(id)
+				s.mu.Unlock()
+				handled = true
+			}
+		}
+	}
+	_, err = reply(replies, "/meta/connect")
+	return err
+}
+
+func (s *Subscriber) disconnect() {
+	s.mu.Lock()
+	clientID := s.clientID
+	s.clientID = ""
+	s.mu.Unlock()
+	if clientID == "" {
+		return
+	}
+	s.send(context.Background(), 10*time.Second, bayeux{Channel: "/meta/disconnect", ClientID: clientID})
+}
+
+// replayID returns where to subscribe to channel and whether it is a stored
+// replay ID
+func (s *Subscriber) replayID(channel string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id, ok := s.replays[channel]; ok {
+		return id, true
+	}
+	if s.Replay == ReplayAll {
+		return ReplayAll, false
+	}
+	return ReplayNew, false
+}
+
+// ReplayIDs returns the replay ID of the last handled event of each channel
+func (s *Subscriber) ReplayIDs() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make(map[string]int64, len(s.replays))
+	for channel, id := range s.replays {
+		ids[channel] = id
+	}
+	return ids
+}
+
+func (s *Subscriber) loadReplays() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replays = make(map[string]int64)
+	if s.ReplayFile == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(s.ReplayFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &s.replays); err != nil {
+		return fmt.Errorf("replay file %s: %w", s.ReplayFile, err)
+	}
+	return nil
+}
+
+func (s *Subscriber) saveReplays() error {
+	if s.ReplayFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.ReplayIDs())
+	if err != nil {
+		return err
+	}
+	tmp := s.ReplayFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.ReplayFile)
+}
@@ -0,0 +1,131 @@
+package salesforce_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/adapters/salesforce"
+)
+
+// cometd is a Streaming API endpoint that delivers events on its first
+// connect and records the replay IDs of the subscriptions
+type cometd struct {
+	mu         sync.Mutex
+	handshakes int
+	replays    []float64
+	delivered  bool
+}
+
+func (c *cometd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var messages []map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&messages)
+	msg := messages[0]
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var replies []map[string]interface{}
+	switch msg["channel"] {
+	case "/meta/handshake":
+		c.handshakes++
+		http.SetCookie(w, &http.Cookie{Name: "BAYEUX_BROWSER", Value: "b1"})
+		replies = append(replies, map[string]interface{}{"channel": "/meta/handshake", "successful": true, "clientId": "c1"})
+	case "/meta/subscribe":
+		replay := msg["ext"].(map[string]interface{})["replay"].(map[string]interface{})["/data/AccountChangeEvent"].(float64)
+		c.replays = append(c.replays, replay)
+		replies = append(replies, map[string]interface{}{"channel": "/meta/subscribe", "successful": true, "subscription": msg["subscription"]})
+	case "/meta/connect":
+		if _, err := r.Cookie("BAYEUX_BROWSER"); err != nil {
+			replies = append(replies, map[string]interface{}{"channel": "/meta/connect", "successful": false, "error": "403::Unknown client", "advice": map[string]interface{}{"reconnect": "handshake"}})
+			break
+		}
+		if !c.delivered {
+			c.delivered = true
+			for _, id := range []float64{11, 12} {
+				replies = append(replies, map[string]interface{}{
+					"channel": "/data/AccountChangeEvent",
+					"data": map[string]interface{}{
+						"event":   map[string]interface{}{"replayId": id},
+						"payload": map[string]interface{}{"Name": "Acme", "ChangeEventHeader": map[string]interface{}{"changeType": "UPDATE"}},
+					},
+				})
+			}
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+		replies = append(replies, map[string]interface{}{"channel": "/meta/connect", "successful": true})
+	case "/meta/disconnect":
+		replies = append(replies, map[string]interface{}{"channel": "/meta/disconnect", "successful": true})
+	}
+	json.NewEncoder(w).Encode(replies)
+}
+
+func TestSubscriberReplaysUnhandledEvents(t *testing.T) {
+	server := &cometd{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	replayFile := filepath.Join(t.TempDir(), "replay.json")
+	ioutil.WriteFile(replayFile, []byte(`{"/data/AccountChangeEvent":10}`), 0600)
+
+	s := salesforce.NewSubscriber(ts.URL, []string{"/data/AccountChangeEvent"}, nil)
+	s.ReplayFile = replayFile
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	var handled []float64
+	failed := false
+	s.Handle = func(ctx context.Context, channel string, event map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		id := event["event"].(map[string]interface{})["replayId"].(float64)
+		if id == 12 && !failed {
+			// The gateway is down: the event must be received again
+			failed = true
+			server.mu.Lock()
+			server.delivered = false
+			server.mu.Unlock()
+			return errors.New("gateway unavailable")
+		}
+		if event["channel"] != "/data/AccountChangeEvent" {
+			t.Errorf("Expected the channel in the event, got %v", event)
+		}
+		handled = append(handled, id)
+		if id == 12 {
+			cancel()
+		}
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Subscriber did not handle the events")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.replays) != 2 || server.replays[0] != 10 || server.replays[1] != 11 {
+		t.Errorf("Expected subscriptions after the stored and then the last handled replay ID, got %v", server.replays)
+	}
+	if len(handled) != 3 || handled[2] != 12 {
+		t.Errorf("Expected the failed event to be handled again, got %v", handled)
+	}
+	data, _ := ioutil.ReadFile(replayFile)
+	var stored map[string]int64
+	json.Unmarshal(data, &stored)
+	if stored["/data/AccountChangeEvent"] != 12 {
+		t.Errorf("Expected the last handled replay ID to be stored, got %s", data)
+	}
+}
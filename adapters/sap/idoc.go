@@ -0,0 +1,642 @@
+package sap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// IDocFunction is the function module IDocs are exchanged with over tRFC
+const IDocFunction = "IDOC_INBOUND_ASYNCHRONOUS"
+
+const (
+	idocControlTable = "IDOC_CONTROL_REC_40"
+	idocDataTable    = "IDOC_DATA_REC_40"
+	// idocSegmentSize is the length of the SDATA of a data record
+	idocSegmentSize = 1000
+	// DefaultIDocBatch is the number of IDocs receive_idoc returns at most
+	DefaultIDocBatch = 100
+)
+
+var idocNumberPattern = regexp.MustCompile(`^[0-9]{1,16}$`)
+
+// idocStatusTexts describe the common IDoc status codes
+var idocStatusTexts = map[string]string{
+	"01": "IDoc generated",
+	"02": "Error passing data to port",
+	"03": "Data passed to port OK",
+	"04": "Error within control information of EDI subsystem",
+	"05": "Error during translation",
+	"06": "Translation OK",
+	"12": "Dispatch OK",
+	"18": "Triggering EDI subsystem OK",
+	"20": "Error triggering EDI subsystem",
+	"26": "Error during syntax check of IDoc (outbound)",
+	"29": "Error in ALE service",
+	"30": "IDoc ready for dispatch (ALE service)",
+	"31": "Error - no further processing",
+	"41": "Application document created in receiving system",
+	"50": "IDoc added",
+	"51": "Application document not posted",
+	"53": "Application document posted",
+	"56": "IDoc with errors added",
+	"60": "Error during syntax check of IDoc (inbound)",
+	"62": "IDoc passed to application",
+	"63": "Error passing IDoc to application",
+	"64": "IDoc ready to be transferred to application",
+	"65": "Error in ALE service",
+	"66": "IDoc is waiting for predecessor IDoc",
+	"68": "Error - no further processing",
+	"69": "IDoc was edited",
+	"70": "Original of an IDoc which was edited",
+}
+
+// IDocTaskStatus maps an IDoc status code onto the status of a legacy
+// response: success for IDocs passed on or posted, error for those that
+// failed, submitted for those only just created and working for those still
+// being processed
+func IDocTaskStatus(code string) string {
+	switch code {
+	case "03", "12", "18", "41", "53":
+		return "success"
+	case "02", "04", "05", "20", "26", "29", "31", "51", "56", "60", "63", "65", "68":
+		return "error"
+	case "01", "50":
+		return "submitted"
+	}
+	return "working"
+}
+
+// segmentField is the position of a field in the SDATA of a segment
+type segmentField struct {
+	name   string
+	offset int
+	length int
+}
+
+// segmentLayouts returns the fields of the segments of an IDoc type, looked
+// up once with IDOCTYPE_READ_COMPLETE
+func (a *SAPAdapter) segmentLayouts(idocType, cimType string) (map[string][]segmentField, error) {
+	key := idocType + "/" + cimType
+	a.layoutsMu.Lock()
+	layouts, ok := a.layouts[key]
+	a.layoutsMu.Unlock()
+	if ok {
+		return layouts, nil
+	}
+
+	params := map[string]interface{}{"PI_IDOCTYP": idocType}
+	if cimType != "" {
+		params["PI_CIMTYP"] = cimType
+	}
+	result, err := a.callFunction("IDOCTYPE_READ_COMPLETE", params, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the segments of IDoc type %s: %w", idocType, err)
+	}
+	rows, _ := result["PT_FIELDS"].([]map[string]interface{})
+	layouts = make(map[string][]segmentField)
+	for _, row := range rows {
+		segment, _ := row["SEGMENTTYP"].(string)
+		name, _ := row["FIELDNAME"].(string)
+		first, length := numValue(row["BYTE_FIRST"]), numValue(row["EXTLEN"])
+		if segment == "" || name == "" || first < 1 || length < 1 {
+			continue
+		}
+		layouts[segment] = append(layouts[segment], segmentField{name: name, offset: first - 1, length: length})
+	}
+
+	a.layoutsMu.Lock()
+	if a.layouts == nil {
+		a.layouts = make(map[string]map[string][]segmentField)
+	}
+	a.layouts[key] = layouts
+	a.layoutsMu.Unlock()
+	return layouts, nil
+}
+
+// numValue converts a NUM or integer value of a function module result
+func numValue(v interface{}) int {
+	switch n := v.(type) {
+	case string:
+		i, _ := strconv.Atoi(strings.TrimSpace(n))
+		return i
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// packSegment lays out fields in the SDATA of a segment
+func packSegment(segment string, layout []segmentField, fields map[string]interface{}) (string, error) {
+	sdata := []rune(strings.Repeat(" ", idocSegmentSize))
+	for key, v := range fields {
+		var field *segmentField
+		for i := range layout {
+			if strings.EqualFold(layout[i].name, key) {
+				field = &layout[i]
+				break
+			}
+		}
+		if field == nil {
+			return "", fmt.Errorf("segment %s has no field %s", segment, key)
+		}
+		s, err := abapString(TypeChar, v)
+		if err != nil {
+			return "", fmt.Errorf("field %s of segment %s: %w", key, segment, err)
+		}
+		value := []rune(s)
+		if len(value) > field.length || field.offset+field.length > idocSegmentSize {
+			return "", fmt.Errorf("field %s of segment %s is longer than %d characters", key, segment, field.length)
+		}
+		copy(sdata[field.offset:], value)
+	}
+	return strings.TrimRight(string(sdata), " "), nil
+}
+
+// unpackSegment returns the non-empty fields of the SDATA of a segment
+func unpackSegment(layout []segmentField, sdata string) map[string]interface{} {
+	data := []rune(sdata)
+	fields := make(map[string]interface{})
+	for _, field := range layout {
+		if field.offset >= len(data) {
+			continue
+		}
+		end := field.offset + field.length
+		if end > len(data) {
+			end = len(data)
+		}
+		if value := strings.TrimRight(string(data[field.offset:end]), " "); value != "" {
+			fields[field.name] = value
+		}
+	}
+	return fields
+}
+
+// buildIDoc builds the control record and the data records of the IDoc
+// described by params. Segments give their fields, laid out by the segment
+// definitions of the IDoc type, or their SDATA as data, and may have
+// children.
+func (a *SAPAdapter) buildIDoc(params map[string]interface{}) (map[string]interface{}, []interface{}, error) {
+	idocType, _ := params["idoc_type"].(string)
+	messageType, _ := params["message_type"].(string)
+	if idocType == "" || messageType == "" {
+		return nil, nil, fmt.Errorf("idoc_type and message_type are required")
+	}
+	cimType, _ := params["cim_type"].(string)
+	segments, _ := params["segments"].([]interface{})
+	if len(segments) == 0 {
+		return nil, nil, fmt.Errorf("segments are required")
+	}
+
+	now := time.Now()
+	control := map[string]interface{}{
+		"TABNAM":  "EDI_DC40",
+		"MANDT":   a.Client,
+		"DIRECT":  "2",
+		"IDOCTYP": idocType,
+		"MESTYP":  messageType,
+		"CREDAT":  now.Format("20060102"),
+		"CRETIM":  now.Format("150405"),
+	}
+	if cimType != "" {
+		control["CIMTYP"] = cimType
+	}
+	for prefix, key := range map[string]string{"SND": "sender", "RCV": "receiver"} {
+		partner, _ := params[key].(map[string]interface{})
+		for field, name := range map[string]string{"POR": "port", "PRT": "partner_type", "PRN": "partner"} {
+			if v, ok := partner[name]; ok {
+				control[prefix+field] = v
+			}
+		}
+	}
+	overrides, _ := params["control"].(map[string]interface{})
+	for key, v := range overrides {
+		control[strings.ToUpper(key)] = v
+	}
+
+	b := &idocBuilder{adapter: a, idocType: idocType, cimType: cimType}
+	if err := b.add(segments, "000000", 2); err != nil {
+		return nil, nil, err
+	}
+	return control, b.records, nil
+}
+
+// idocBuilder numbers the segments of an IDoc into data records
+type idocBuilder struct {
+	adapter  *SAPAdapter
+	idocType string
+	cimType  string
+	records  []interface{}
+}
+
+func (b *idocBuilder) add(segments []interface{}, parent string, level int) error {
+	for i, s := range segments {
+		segment, _ := s.(map[string]interface{})
+		name, _ := segment["segment"].(string)
+		if name == "" {
+			return fmt.Errorf("segment %d below %s has no name", i, parent)
+		}
+
+		sdata, _ := segment["data"].(string)
+		if fields, ok := segment["fields"].(map[string]interface{}); ok {
+			layouts, err := b.adapter.segmentLayouts(b.idocType, b.cimType)
+			if err != nil {
+				return err
+			}
+			layout, ok := layouts[name]
+			if !ok {
+				return fmt.Errorf("IDoc type %s has no segment %s", b.idocType, name)
+			}
+			if sdata, err = packSegment(name, layout, fields); err != nil {
+				return err
+			}
+		}
+
+		number := fmt.Sprintf("%06d", len(b.records)+1)
+		b.records = append(b.records, map[string]interface{}{
+			"SEGNAM": name,
+			"MANDT":  b.adapter.Client,
+			"SEGNUM": number,
+			"PSGNUM": parent,
+			"HLEVEL": fmt.Sprintf("%02d", level),
+			"SDATA":  sdata,
+		})
+		children, _ := segment["children"].([]interface{})
+		if err := b.add(children, number, level+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseIDocs returns the IDocs of the tables of an IDOC_INBOUND_ASYNCHRONOUS
+// call, with the fields of their segments when the segment definitions can
+// be read and their SDATA otherwise
+func (a *SAPAdapter) parseIDocs(params map[string]interface{}) []map[string]interface{} {
+	records := make(map[string][]map[string]interface{})
+	for _, row := range tableRows(params[idocDataTable]) {
+		docnum, _ := row["DOCNUM"].(string)
+		records[docnum] = append(records[docnum], row)
+	}
+
+	var idocs []map[string]interface{}
+	for _, control := range tableRows(params[idocControlTable]) {
+		docnum, _ := control["DOCNUM"].(string)
+		idocType, _ := control["IDOCTYP"].(string)
+		cimType, _ := control["CIMTYP"].(string)
+		layouts, err := a.segmentLayouts(idocType, cimType)
+		if err != nil {
+			slog.Warn("IDoc segments are returned as SDATA", logging.KeyError, err, "idocType", idocType)
+		}
+
+		idocs = append(idocs, map[string]interface{}{
+			"idoc_number":  docnum,
+			"idoc_type":    idocType,
+			"message_type": control["MESTYP"],
+			"sender": map[string]interface{}{
+				"port": control["SNDPOR"], "partner_type": control["SNDPRT"], "partner": control["SNDPRN"],
+			},
+			"receiver": map[string]interface{}{
+				"port": control["RCVPOR"], "partner_type": control["RCVPRT"], "partner": control["RCVPRN"],
+			},
+			"control":  control,
+			"segments": segmentTree(records[docnum], layouts),
+		})
+	}
+	return idocs
+}
+
+// segmentTree nests the data records of an IDoc below their parents
+func segmentTree(records []map[string]interface{}, layouts map[string][]segmentField) []interface{} {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, _ := records[i]["SEGNUM"].(string)
+		b, _ := records[j]["SEGNUM"].(string)
+		return a < b
+	})
+
+	type node struct {
+		segment  map[string]interface{}
+		children []*node
+	}
+	nodes := make(map[string]*node)
+	var roots []*node
+	for _, record := range records {
+		name, _ := record["SEGNAM"].(string)
+		sdata, _ := record["SDATA"].(string)
+		segment := map[string]interface{}{"segment": name}
+		if layout, ok := layouts[name]; ok {
+			segment["fields"] = unpackSegment(layout, sdata)
+		} else {
+			segment["data"] = sdata
+		}
+
+		n := &node{segment: segment}
+		number, _ := record["SEGNUM"].(string)
+		nodes[number] = n
+		parentNumber, _ := record["PSGNUM"].(string)
+		if parent, ok := nodes[parentNumber]; ok {
+			parent.children = append(parent.children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+
+	var convert func([]*node) []interface{}
+	convert = func(nodes []*node) []interface{} {
+		list := make([]interface{}, len(nodes))
+		for i, n := range nodes {
+			if len(n.children) > 0 {
+				n.segment["children"] = convert(n.children)
+			}
+			list[i] = n.segment
+		}
+		return list
+	}
+	return convert(roots)
+}
+
+// tableRows returns the rows of a table parameter, as returned by an SAP
+// system or restored from a stored transaction
+func tableRows(v interface{}) []map[string]interface{} {
+	switch rows := v.(type) {
+	case []map[string]interface{}:
+		return rows
+	case []interface{}:
+		result := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			if m, ok := row.(map[string]interface{}); ok {
+				result = append(result, m)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// sendTransaction calls function over tRFC in the transaction tid, or a new
+// one. The transaction is stored until SAP has it, so a failed one is sent
+// again with the same ID, on restart or by passing the tid.
+func (a *SAPAdapter) sendTransaction(tid, function string, params map[string]interface{}) (string, error) {
+	if a.pool == nil || a.tids == nil {
+		return "", fmt.Errorf("IDoc connection is not initialized")
+	}
+	conn, err := a.pool.get(context.Background())
+	if err != nil {
+		return "", err
+	}
+	tc, ok := conn.(TransactionalConn)
+	if !ok {
+		a.pool.put(conn, nil)
+		return "", fmt.Errorf("the RFC connection does not support tRFC")
+	}
+	if tid == "" {
+		if tid, err = tc.NewTID(); err != nil {
+			a.pool.put(conn, err)
+			return "", err
+		}
+	}
+	entry := tidEntry{TID: tid, Direction: tidOutbound, State: tidCreated, Function: function, Params: params, Created: time.Now()}
+	if existing, ok := a.tids.get(tid); ok {
+		entry.Created = existing.Created
+	}
+	if err := a.tids.put(entry); err != nil {
+		a.pool.put(conn, nil)
+		return "", fmt.Errorf("failed to store transaction %s: %w", tid, err)
+	}
+
+	err = tc.CallTransaction(tid, function, params)
+	a.pool.put(conn, err)
+	if err != nil {
+		return tid, fmt.Errorf("transaction %s failed and is sent again on restart or with its tid: %w", tid, err)
+	}
+	if err := a.tids.remove(tid); err != nil {
+		slog.Warn("sent transaction was not removed", logging.KeyError, err, "tid", tid)
+	}
+	return tid, nil
+}
+
+// resendTransactions sends the outbound transactions that did not reach the
+// SAP system before the last stop again
+func (a *SAPAdapter) resendTransactions() {
+	for _, entry := range a.tids.list(tidOutbound, tidCreated) {
+		if _, err := a.sendTransaction(entry.TID, entry.Function, entry.Params); err != nil {
+			slog.Warn("transaction was not sent again", logging.KeyError, err, "tid", entry.TID)
+			continue
+		}
+		a.Logger().Info("sent transaction again", "tid", entry.TID, "function", entry.Function)
+	}
+}
+
+func (a *SAPAdapter) sendIDoc(params map[string]interface{}) (map[string]interface{}, error) {
+	control, records, err := a.buildIDoc(params)
+	if err != nil {
+		return nil, err
+	}
+	tables := map[string]interface{}{
+		idocControlTable: []interface{}{control},
+		idocDataTable:    records,
+	}
+	tid, _ := params["tid"].(string)
+	tid, err = a.sendTransaction(tid, IDocFunction, tables)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"success":  true,
+		"status":   "success",
+		"tid":      tid,
+		"segments": len(records),
+	}, nil
+}
+
+func (a *SAPAdapter) receiveIDoc(params map[string]interface{}) (map[string]interface{}, error) {
+	if a.receiver == nil {
+		return nil, fmt.Errorf("receiving IDocs requires a program ID to register as RFC server")
+	}
+	max := numValue(params["max"])
+	if max <= 0 {
+		max = DefaultIDocBatch
+	}
+	idocs, err := a.receiver.take(max)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"success": true,
+		"status":  "success",
+		"idocs":   idocs,
+		"count":   len(idocs),
+	}, nil
+}
+
+func (a *SAPAdapter) getIDocStatus(params map[string]interface{}) (map[string]interface{}, error) {
+	// Get IDoc number
+	idocNumber, ok := params["idoc_number"].(string)
+	if !ok || idocNumber == "" {
+		return nil, fmt.Errorf("idoc_number is required")
+	}
+	if !idocNumberPattern.MatchString(idocNumber) {
+		return nil, fmt.Errorf("idoc_number %q is not an IDoc number", idocNumber)
+	}
+	docnum := fmt.Sprintf("%016s", idocNumber)
+
+	result, err := a.callFunction("RFC_READ_TABLE", map[string]interface{}{
+		"QUERY_TABLE": "EDIDS",
+		"DELIMITER":   "|",
+		"FIELDS": []interface{}{
+			map[string]interface{}{"FIELDNAME": "COUNTR"},
+			map[string]interface{}{"FIELDNAME": "CREDAT"},
+			map[string]interface{}{"FIELDNAME": "CRETIM"},
+			map[string]interface{}{"FIELDNAME": "STATUS"},
+		},
+		"OPTIONS": []interface{}{
+			map[string]interface{}{"TEXT": "DOCNUM = '" + docnum + "'"},
+		},
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []map[string]interface{}
+	for _, row := range tableRows(result["DATA"]) {
+		wa, _ := row["WA"].(string)
+		cols := strings.Split(wa, "|")
+		if len(cols) < 4 {
+			continue
+		}
+		for i := range cols {
+			cols[i] = strings.TrimSpace(cols[i])
+		}
+		history = append(history, map[string]interface{}{
+			"counter":   cols[0],
+			"status":    cols[3],
+			"timestamp": idocTimestamp(cols[1], cols[2]),
+		})
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("IDoc %s not found", idocNumber)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i]["counter"].(string) < history[j]["counter"].(string)
+	})
+
+	current := history[len(history)-1]
+	code := current["status"].(string)
+	return map[string]interface{}{
+		"idoc_number":        idocNumber,
+		"status":             IDocTaskStatus(code),
+		"status_code":        code,
+		"status_description": idocStatusTexts[code],
+		"timestamp":          current["timestamp"],
+		"history":            history,
+	}, nil
+}
+
+// idocTimestamp formats the date and time of a status record, in the time
+// zone of the SAP system
+func idocTimestamp(date, clock string) string {
+	if len(date) != 8 || len(clock) != 6 {
+		return date + clock
+	}
+	return date[0:4] + "-" + date[4:6] + "-" + date[6:8] + "T" + clock[0:2] + ":" + clock[2:4] + ":" + clock[4:6]
+}
+
+// idocReceiver receives the IDocs an SAP system sends to the registered RFC
+// server; they are kept with their transaction until receive_idoc takes them
+type idocReceiver struct {
+	adapter *SAPAdapter
+	mu      sync.Mutex
+	// pending holds the IDocs of transactions not committed yet
+	pending map[string][]map[string]interface{}
+}
+
+func newIDocReceiver(a *SAPAdapter) *idocReceiver {
+	return &idocReceiver{adapter: a, pending: make(map[string][]map[string]interface{})}
+}
+
+// Serve receives the IDocs of an IDOC_INBOUND_ASYNCHRONOUS call
+func (r *idocReceiver) Serve(tid, function string, params map[string]interface{}) error {
+	if function != IDocFunction {
+		return fmt.Errorf("function %s is not served", function)
+	}
+	idocs := r.adapter.parseIDocs(params)
+	if tid == "" {
+		return r.commit(fmt.Sprintf("SYNC%d", time.Now().UnixNano()), idocs)
+	}
+	r.mu.Lock()
+	r.pending[tid] = append(r.pending[tid], idocs...)
+	r.mu.Unlock()
+	return nil
+}
+
+// CheckTID reports whether the transaction was not received before
+func (r *idocReceiver) CheckTID(tid string) bool {
+	if entry, ok := r.adapter.tids.get(tid); ok && entry.State != tidCreated {
+		return false
+	}
+	entry := tidEntry{TID: tid, Direction: tidInbound, State: tidCreated, Function: IDocFunction, Created: time.Now()}
+	if err := r.adapter.tids.put(entry); err != nil {
+		slog.Warn("inbound transaction was not stored", logging.KeyError, err, "tid", tid)
+	}
+	return true
+}
+
+// CommitTID keeps the IDocs of the transaction until they are taken
+func (r *idocReceiver) CommitTID(tid string) error {
+	r.mu.Lock()
+	idocs := r.pending[tid]
+	delete(r.pending, tid)
+	r.mu.Unlock()
+	return r.commit(tid, idocs)
+}
+
+func (r *idocReceiver) commit(tid string, idocs []map[string]interface{}) error {
+	list := make([]interface{}, len(idocs))
+	for i, idoc := range idocs {
+		list[i] = idoc
+	}
+	entry := tidEntry{TID: tid, Direction: tidInbound, State: tidCommitted, Function: IDocFunction,
+		Params: map[string]interface{}{"idocs": list}, Created: time.Now()}
+	if err := r.adapter.tids.put(entry); err != nil {
+		return fmt.Errorf("failed to store the IDocs of transaction %s: %w", tid, err)
+	}
+	return nil
+}
+
+// RollbackTID drops the IDocs of the transaction
+func (r *idocReceiver) RollbackTID(tid string) {
+	r.mu.Lock()
+	delete(r.pending, tid)
+	r.mu.Unlock()
+	r.adapter.tids.remove(tid)
+}
+
+// ConfirmTID is called once the SAP system will not send the transaction
+// again; its IDocs stay until they are taken
+func (r *idocReceiver) ConfirmTID(tid string) {}
+
+// take returns up to max received IDocs, oldest first, and forgets them
+func (r *idocReceiver) take(max int) ([]interface{}, error) {
+	idocs := []interface{}{}
+	for _, entry := range r.adapter.tids.list(tidInbound, tidCommitted) {
+		list, _ := entry.Params["idocs"].([]interface{})
+		if len(idocs) > 0 && len(idocs)+len(list) > max {
+			break
+		}
+		idocs = append(idocs, list...)
+		if err := r.adapter.tids.remove(entry.TID); err != nil {
+			return nil, err
+		}
+	}
+	return idocs, nil
+}
@@ -0,0 +1,245 @@
+package sap_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/adapters/sap"
+)
+
+// fakeTRFC is an RFC connection of a system that accepts tRFC transactions
+// unless fail is set
+type fakeTRFC struct {
+	fakeRFC
+	transactions map[string]map[string]interface{}
+	fail         bool
+}
+
+func (c *fakeTRFC) NewTID() (string, error) {
+	return "0A1B2C3D4E5F", nil
+}
+
+func (c *fakeTRFC) CallTransaction(tid, function string, params map[string]interface{}) error {
+	if c.fail {
+		return &sap.RFCError{Group: "COMMUNICATION_FAILURE", Message: "partner not reached"}
+	}
+	c.transactions[tid] = params
+	return nil
+}
+
+// fakeListener captures the handler of the RFC server
+type fakeListener struct {
+	handler sap.RFCServerHandler
+	closed  bool
+}
+
+func (l *fakeListener) Close() error {
+	l.closed = true
+	return nil
+}
+
+var idocResults = map[string]map[string]interface{}{
+	"IDOCTYPE_READ_COMPLETE": {"PT_FIELDS": []map[string]interface{}{
+		{"SEGMENTTYP": "E1MARAM", "FIELDNAME": "MATNR", "BYTE_FIRST": "000001", "EXTLEN": "000018"},
+		{"SEGMENTTYP": "E1MARAM", "FIELDNAME": "MTART", "BYTE_FIRST": "000019", "EXTLEN": "000004"},
+		{"SEGMENTTYP": "E1MAKTM", "FIELDNAME": "SPRAS", "BYTE_FIRST": "000001", "EXTLEN": "000001"},
+		{"SEGMENTTYP": "E1MAKTM", "FIELDNAME": "MAKTX", "BYTE_FIRST": "000002", "EXTLEN": "000040"},
+	}},
+	"RFC_READ_TABLE": {"DATA": []map[string]interface{}{
+		{"WA": "000001|20240105|101500|50"},
+		{"WA": "000003|20240105|101502|51"},
+		{"WA": "000002|20240105|101501|64"},
+	}},
+}
+
+func newIDocAdapter(t *testing.T, dir string, conn *fakeTRFC, listener *fakeListener) *sap.SAPAdapter {
+	t.Helper()
+	a := sap.NewSAPAdapter("erp", "SAP ERP", sap.SAPAdapterConfig{
+		IntegrationType: "idoc",
+		ServerHost:      "sap.example.com",
+		ServerPort:      3300,
+		SystemID:        "PRD",
+		Client:          "100",
+		Username:        "RFCUSER",
+		Password:        "secret",
+		ProgramID:       "A2A_IDOC",
+		TIDDir:          dir,
+	}, nil)
+	a.Dial = func(params map[string]string) (sap.RFCConn, error) {
+		return conn, nil
+	}
+	a.Listen = func(sysID string, params map[string]string, repository sap.RFCConn, functions []string, handler sap.RFCServerHandler) (sap.RFCListener, error) {
+		if params["gwhost"] != "sap.example.com" || params["gwserv"] != "sapgw00" || params["program_id"] != "A2A_IDOC" {
+			t.Errorf("Unexpected server parameters %v", params)
+		}
+		if len(functions) != 1 || functions[0] != sap.IDocFunction {
+			t.Errorf("Unexpected server functions %v", functions)
+		}
+		listener.handler = handler
+		return listener, nil
+	}
+	if err := a.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	return a
+}
+
+func TestSendIDoc(t *testing.T) {
+	dir := t.TempDir()
+	conn := &fakeTRFC{fakeRFC: fakeRFC{results: idocResults}, transactions: map[string]map[string]interface{}{}, fail: true}
+	a := newIDocAdapter(t, dir, conn, &fakeListener{})
+
+	params := map[string]interface{}{
+		"idoc_type":    "MATMAS05",
+		"message_type": "MATMAS",
+		"receiver":     map[string]interface{}{"port": "SAPPRD", "partner_type": "LS", "partner": "PRDCLNT100"},
+		"segments": []interface{}{
+			map[string]interface{}{
+				"segment": "E1MARAM",
+				"fields":  map[string]interface{}{"MATNR": "M-100", "MTART": "FERT"},
+				"children": []interface{}{
+					map[string]interface{}{"segment": "E1MAKTM", "fields": map[string]interface{}{"SPRAS": "E", "MAKTX": "Pump"}},
+				},
+			},
+		},
+	}
+	if _, err := a.ExecuteTask("send_idoc", params); err == nil {
+		t.Fatal("Expected the failed transaction to fail the send")
+	}
+	a.Close()
+
+	// The transaction is kept and sent with the same TID on restart
+	conn.fail = false
+	a = newIDocAdapter(t, dir, conn, &fakeListener{})
+	defer a.Close()
+	tables, ok := conn.transactions["0A1B2C3D4E5F"]
+	if !ok {
+		t.Fatalf("Expected the stored transaction to be sent again, got %v", conn.transactions)
+	}
+	control := tables["IDOC_CONTROL_REC_40"].([]interface{})[0].(map[string]interface{})
+	if control["IDOCTYP"] != "MATMAS05" || control["RCVPRN"] != "PRDCLNT100" || control["DIRECT"] != "2" {
+		t.Errorf("Unexpected control record %v", control)
+	}
+	records := tables["IDOC_DATA_REC_40"].([]interface{})
+	if len(records) != 2 {
+		t.Fatalf("Expected two data records, got %v", records)
+	}
+	child := records[1].(map[string]interface{})
+	if child["SEGNUM"] != "000002" || child["PSGNUM"] != "000001" || child["HLEVEL"] != "03" || child["SDATA"] != "EPump" {
+		t.Errorf("Unexpected child record %v", child)
+	}
+	if sdata := records[0].(map[string]interface{})["SDATA"]; sdata != "M-100             FERT" {
+		t.Errorf("Expected the fields at their offsets, got %q", sdata)
+	}
+
+	delete(conn.transactions, "0A1B2C3D4E5F")
+	result, err := a.ExecuteTask("send_idoc", params)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if result["tid"] != "0A1B2C3D4E5F" || result["segments"] != 2 {
+		t.Errorf("Unexpected result %v", result)
+	}
+}
+
+func TestReceiveIDoc(t *testing.T) {
+	conn := &fakeTRFC{fakeRFC: fakeRFC{results: idocResults}, transactions: map[string]map[string]interface{}{}}
+	listener := &fakeListener{}
+	a := newIDocAdapter(t, t.TempDir(), conn, listener)
+
+	tables := map[string]interface{}{
+		"IDOC_CONTROL_REC_40": []map[string]interface{}{
+			{"DOCNUM": "0000000000004711", "IDOCTYP": "MATMAS05", "MESTYP": "MATMAS", "SNDPRN": "PRDCLNT100"},
+		},
+		"IDOC_DATA_REC_40": []map[string]interface{}{
+			{"DOCNUM": "0000000000004711", "SEGNAM": "E1MAKTM", "SEGNUM": "000002", "PSGNUM": "000001", "SDATA": "EPump"},
+			{"DOCNUM": "0000000000004711", "SEGNAM": "E1MARAM", "SEGNUM": "000001", "PSGNUM": "000000", "SDATA": "M-100             FERT"},
+		},
+	}
+	h := listener.handler
+	if !h.CheckTID("AC1F00010001") {
+		t.Fatal("Expected a new transaction to be executed")
+	}
+	if err := h.Serve("AC1F00010001", sap.IDocFunction, tables); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	if err := h.CommitTID("AC1F00010001"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	h.ConfirmTID("AC1F00010001")
+	if h.CheckTID("AC1F00010001") {
+		t.Error("Expected a committed transaction not to be executed again")
+	}
+
+	result, err := a.ExecuteTask("receive_idoc", nil)
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	idocs := result["idocs"].([]interface{})
+	if len(idocs) != 1 {
+		t.Fatalf("Expected the received IDoc, got %v", result)
+	}
+	idoc := idocs[0].(map[string]interface{})
+	segment := idoc["segments"].([]interface{})[0].(map[string]interface{})
+	fields := segment["fields"].(map[string]interface{})
+	child := segment["children"].([]interface{})[0].(map[string]interface{})
+	if idoc["idoc_number"] != "0000000000004711" || fields["MTART"] != "FERT" || child["fields"].(map[string]interface{})["MAKTX"] != "Pump" {
+		t.Errorf("Unexpected IDoc %v", idoc)
+	}
+
+	if result, _ = a.ExecuteTask("receive_idoc", nil); result["count"] != 0 {
+		t.Errorf("Expected the IDoc to be taken once, got %v", result)
+	}
+	a.Close()
+	if !listener.closed {
+		t.Error("Expected Close to stop the RFC server")
+	}
+}
+
+func TestIDocStatus(t *testing.T) {
+	conn := &fakeTRFC{fakeRFC: fakeRFC{results: idocResults}, transactions: map[string]map[string]interface{}{}}
+	a := newIDocAdapter(t, "", conn, &fakeListener{})
+	defer a.Close()
+
+	result, err := a.ExecuteTask("get_idoc_status", map[string]interface{}{"idoc_number": "4711"})
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if result["status_code"] != "51" || result["status"] != "error" || result["timestamp"] != "2024-01-05T10:15:02" {
+		t.Errorf("Expected the latest status record, got %v", result)
+	}
+	if _, err := a.ExecuteTask("get_idoc_status", map[string]interface{}{"idoc_number": "4711' OR '1'='1"}); err == nil {
+		t.Error("Expected an invalid IDoc number to be rejected")
+	}
+
+	for code, status := range map[string]string{"53": "success", "64": "working", "50": "submitted", "56": "error"} {
+		if got := sap.IDocTaskStatus(code); got != status {
+			t.Errorf("Expected status %s for %s, got %s", status, code, got)
+		}
+	}
+}
+
+func TestIDocWithoutServer(t *testing.T) {
+	a := sap.NewSAPAdapter("erp", "SAP ERP", sap.SAPAdapterConfig{
+		IntegrationType: "idoc", ServerHost: "sap.example.com", ServerPort: 3300,
+		SystemID: "PRD", Client: "100", Username: "RFCUSER", Password: "secret",
+	}, nil)
+	a.Dial = func(params map[string]string) (sap.RFCConn, error) {
+		return &fakeRFC{}, nil
+	}
+	if err := a.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	defer a.Close()
+	if _, err := a.ExecuteTask("receive_idoc", nil); err == nil {
+		t.Error("Expected receiving without a program ID to fail")
+	}
+	_, err := a.ExecuteTask("send_idoc", map[string]interface{}{
+		"idoc_type": "MATMAS05", "message_type": "MATMAS",
+		"segments": []interface{}{map[string]interface{}{"segment": "E1MARAM", "data": "M-100"}},
+	})
+	var rfcErr *sap.RFCError
+	if err == nil || errors.As(err, &rfcErr) {
+		t.Errorf("Expected a connection without tRFC to be rejected, got %v", err)
+	}
+}
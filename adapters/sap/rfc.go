@@ -0,0 +1,358 @@
+package sap
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRFCUnavailable is returned when the connector was built without the SAP
+// NW RFC SDK, i.e. without the sapnwrfc build tag or without cgo
+var ErrRFCUnavailable = errors.New("SAP RFC requires a connector built with the SAP NW RFC SDK (go build -tags sapnwrfc)")
+
+// Parameter directions, from the caller's point of view of the function module
+const (
+	DirectionImport   = "import"
+	DirectionExport   = "export"
+	DirectionChanging = "changing"
+	DirectionTables   = "tables"
+)
+
+// ABAP types of parameters and fields
+const (
+	TypeChar      = "CHAR"
+	TypeDate      = "DATE"
+	TypeBCD       = "BCD"
+	TypeTime      = "TIME"
+	TypeByte      = "BYTE"
+	TypeTable     = "TABLE"
+	TypeNum       = "NUM"
+	TypeFloat     = "FLOAT"
+	TypeInt       = "INT"
+	TypeInt2      = "INT2"
+	TypeInt1      = "INT1"
+	TypeInt8      = "INT8"
+	TypeStructure = "STRUCTURE"
+	TypeDecF16    = "DECF16"
+	TypeDecF34    = "DECF34"
+	TypeString    = "STRING"
+	TypeXString   = "XSTRING"
+	TypeUTCLong   = "UTCLONG"
+)
+
+// FunctionMeta describes a function module as the DDIC of the SAP system
+// defines it
+type FunctionMeta struct {
+	Name       string          `json:"name"`
+	Parameters []ParameterMeta `json:"parameters"`
+}
+
+// ParameterMeta describes a parameter of a function module
+type ParameterMeta struct {
+	Name      string `json:"name"`
+	Direction string `json:"direction"`
+	Type      string `json:"type"`
+	// TypeName is the DDIC structure or table type of structures and tables
+	TypeName    string      `json:"typeName,omitempty"`
+	Length      int         `json:"length,omitempty"`
+	Decimals    int         `json:"decimals,omitempty"`
+	Optional    bool        `json:"optional,omitempty"`
+	Default     string      `json:"default,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Fields      []FieldMeta `json:"fields,omitempty"`
+}
+
+// FieldMeta describes a field of a structure or of the rows of a table
+type FieldMeta struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	TypeName string      `json:"typeName,omitempty"`
+	Length   int         `json:"length,omitempty"`
+	Decimals int         `json:"decimals,omitempty"`
+	Fields   []FieldMeta `json:"fields,omitempty"`
+}
+
+// RFCConn is an open RFC connection to an SAP system. It is used by one
+// call at a time.
+type RFCConn interface {
+	// Call invokes a function module with its import, changing and tables
+	// parameters and returns its export, changing and tables parameters
+	Call(function string, params map[string]interface{}) (map[string]interface{}, error)
+	// Describe looks up the metadata of a function module
+	Describe(function string) (*FunctionMeta, error)
+	Close() error
+}
+
+// TransactionalConn is an RFC connection that can call function modules in
+// transactional RFC (tRFC), which SAP executes once per transaction ID
+type TransactionalConn interface {
+	RFCConn
+	// NewTID returns a new transaction ID
+	NewTID() (string, error)
+	// CallTransaction calls function in the transaction tid; calling it
+	// again with the same tid after a failure does not execute it twice
+	CallTransaction(tid, function string, params map[string]interface{}) error
+}
+
+// RFCServerHandler serves the function modules an SAP system calls on a
+// registered RFC server, and the transactions of tRFC calls
+type RFCServerHandler interface {
+	// Serve handles a call of function with its import, changing and tables
+	// parameters; tid is empty for synchronous calls
+	Serve(tid, function string, params map[string]interface{}) error
+	// CheckTID reports whether the transaction tid is new; false tells the
+	// SAP system it was executed before
+	CheckTID(tid string) bool
+	// CommitTID makes the calls of tid final; an error has the SAP system
+	// send the transaction again
+	CommitTID(tid string) error
+	RollbackTID(tid string)
+	ConfirmTID(tid string)
+}
+
+// RFCListener is an RFC server registered at an SAP gateway
+type RFCListener interface {
+	Close() error
+}
+
+// RFCError is an error reported by the RFC library or the SAP system
+type RFCError struct {
+	// Group is the error group, e.g. ABAP_APPLICATION_FAILURE for an ABAP
+	// exception raised by the function module or COMMUNICATION_FAILURE
+	Group   string
+	Key     string
+	Message string
+	// ABAPMessage is the T100 message, e.g. E/V1/123, of ABAP errors
+	ABAPMessage string
+}
+
+func (e *RFCError) Error() string {
+	msg := e.Key
+	if e.Message != "" && e.Message != e.Key {
+		msg += ": " + e.Message
+	}
+	if e.ABAPMessage != "" {
+		msg += " (" + e.ABAPMessage + ")"
+	}
+	return "RFC " + strings.ToLower(strings.ReplaceAll(e.Group, "_", " ")) + ": " + msg
+}
+
+// Broken reports whether the connection the error occurred on was closed by
+// it; runtime errors such as short dumps end the session on the SAP side
+func (e *RFCError) Broken() bool {
+	switch e.Group {
+	case "COMMUNICATION_FAILURE", "ABAP_RUNTIME_FAILURE", "LOGON_FAILURE":
+		return true
+	}
+	return false
+}
+
+// rfcPool keeps up to max open connections, opening them as calls need them
+type rfcPool struct {
+	dial    func() (RFCConn, error)
+	idle    chan RFCConn
+	slots   chan struct{}
+	timeout time.Duration
+}
+
+func newRFCPool(dial func() (RFCConn, error), max int, timeout time.Duration) *rfcPool {
+	return &rfcPool{
+		dial:    dial,
+		idle:    make(chan RFCConn, max),
+		slots:   make(chan struct{}, max),
+		timeout: timeout,
+	}
+}
+
+// get returns an idle connection or opens one, waiting up to the pool
+// timeout while all connections are in use
+func (p *rfcPool) get(ctx context.Context) (RFCConn, error) {
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+	select {
+	case p.slots <- struct{}{}:
+	case <-timer.C:
+		return nil, fmt.Errorf("all %d RFC connections are in use", cap(p.slots))
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	default:
+	}
+	conn, err := p.dial()
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return conn, nil
+}
+
+// put returns conn to the pool after a call that ended with err, closing it
+// when err broke it
+func (p *rfcPool) put(conn RFCConn, err error) {
+	var rfcErr *RFCError
+	if errors.As(err, &rfcErr) && rfcErr.Broken() {
+		conn.Close()
+	} else {
+		p.idle <- conn
+	}
+	<-p.slots
+}
+
+// close closes the idle connections
+func (p *rfcPool) close() {
+	for {
+		select {
+		case conn := <-p.idle:
+			conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// abapString formats a value of a task parameter for a parameter or field of
+// type typ: dates as YYYYMMDD, times as HHMMSS and booleans as ABAP flags
+func abapString(typ string, v interface{}) (string, error) {
+	var s string
+	switch value := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		s = value
+	case bool:
+		if value {
+			return "X", nil
+		}
+		return "", nil
+	case float64:
+		s = strconv.FormatFloat(value, 'f', -1, 64)
+	case int:
+		s = strconv.Itoa(value)
+	case int64:
+		s = strconv.FormatInt(value, 10)
+	case json.Number:
+		s = value.String()
+	default:
+		return "", fmt.Errorf("cannot convert %T to %s", v, typ)
+	}
+	if s == "" {
+		return "", nil
+	}
+	switch typ {
+	case TypeDate:
+		s = strings.ReplaceAll(s, "-", "")
+		if len(s) != 8 {
+			return "", fmt.Errorf("date %q is not YYYY-MM-DD or YYYYMMDD", v)
+		}
+	case TypeTime:
+		s = strings.ReplaceAll(s, ":", "")
+		if len(s) != 6 {
+			return "", fmt.Errorf("time %q is not HH:MM:SS or HHMMSS", v)
+		}
+	}
+	return s, nil
+}
+
+// abapBytes decodes a value of a task parameter for a BYTE or XSTRING
+// parameter or field, which are exchanged base64 encoded
+func abapBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert %T to bytes, expected base64", v)
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("value is not base64: %w", err)
+	}
+	return data, nil
+}
+
+// abapInt converts a value of a task parameter for an integer parameter or
+// field
+func abapInt(v interface{}) (int64, error) {
+	s, err := abapString(TypeInt, v)
+	if err != nil {
+		return 0, err
+	}
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an integer", s)
+	}
+	return n, nil
+}
+
+// abapFloat converts a value of a task parameter for a FLOAT parameter or
+// field
+func abapFloat(v interface{}) (float64, error) {
+	s, err := abapString(TypeFloat, v)
+	if err != nil {
+		return 0, err
+	}
+	if s == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return f, nil
+}
+
+// goValue converts the text of a character-like or decimal value of type typ
+// returned by the SAP system: CHAR loses its padding, dates and times become
+// ISO 8601 and are nil when initial, and packed and decimal floating point
+// numbers keep their precision as JSON numbers. NUM stays text, since its
+// leading zeros are often part of document numbers.
+func goValue(typ, s string) interface{} {
+	switch typ {
+	case TypeChar:
+		return strings.TrimRight(s, " ")
+	case TypeDate:
+		if len(s) != 8 || s == "00000000" || strings.TrimSpace(s) == "" {
+			return nil
+		}
+		return s[0:4] + "-" + s[4:6] + "-" + s[6:8]
+	case TypeTime:
+		if len(s) != 6 || strings.TrimSpace(s) == "" {
+			return nil
+		}
+		return s[0:2] + ":" + s[2:4] + ":" + s[4:6]
+	case TypeBCD, TypeDecF16, TypeDecF34:
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return json.Number("0")
+		}
+		if strings.HasSuffix(s, "-") {
+			// ABAP writes the sign of negative numbers last
+			s = "-" + strings.TrimSuffix(s, "-")
+		}
+		return json.Number(s)
+	}
+	return s
+}
+
+// lookupParam returns the value of the parameter name in params, whose keys
+// may use any case
+func lookupParam(params map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := params[name]; ok {
+		return v, true
+	}
+	for key, v := range params {
+		if strings.EqualFold(key, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
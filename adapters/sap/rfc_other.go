@@ -0,0 +1,15 @@
+//go:build !sapnwrfc || !cgo
+
+package sap
+
+// DialRFC opens an RFC connection with the SAP NW RFC SDK connection
+// parameters; this build has no SDK, so it always fails
+func DialRFC(params map[string]string) (RFCConn, error) {
+	return nil, ErrRFCUnavailable
+}
+
+// ListenRFC registers an RFC server at an SAP gateway; this build has no SDK,
+// so it always fails
+func ListenRFC(sysID string, params map[string]string, repository RFCConn, functions []string, handler RFCServerHandler) (RFCListener, error) {
+	return nil, ErrRFCUnavailable
+}
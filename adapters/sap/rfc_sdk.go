@@ -0,0 +1,620 @@
+//go:build sapnwrfc && cgo
+
+package sap
+
+// Building with -tags sapnwrfc needs the SAP NW RFC SDK 7.50; point cgo at it
+// with CGO_CFLAGS="-I$SAPNWRFC_HOME/include" and
+// CGO_LDFLAGS="-L$SAPNWRFC_HOME/lib", and put its lib directory on the
+// library path of the connector at run time.
+
+/*
+#cgo linux CFLAGS: -DSAPonUNIX -DSAPonLIN
+#cgo darwin CFLAGS: -DSAPonUNIX -DSAPonDARW
+#cgo windows CFLAGS: -DSAPonNT
+#cgo CFLAGS: -DSAPwithUNICODE -DSAPwithTHREADS -D_LARGEFILE_SOURCE
+#cgo LDFLAGS: -lsapnwrfc -lsapucum
+#include <stdlib.h>
+#include <sapnwrfc.h>
+*/
+import "C"
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unsafe"
+)
+
+var rfcTypes = map[C.RFCTYPE]string{
+	C.RFCTYPE_CHAR:      TypeChar,
+	C.RFCTYPE_DATE:      TypeDate,
+	C.RFCTYPE_BCD:       TypeBCD,
+	C.RFCTYPE_TIME:      TypeTime,
+	C.RFCTYPE_BYTE:      TypeByte,
+	C.RFCTYPE_TABLE:     TypeTable,
+	C.RFCTYPE_NUM:       TypeNum,
+	C.RFCTYPE_FLOAT:     TypeFloat,
+	C.RFCTYPE_INT:       TypeInt,
+	C.RFCTYPE_INT2:      TypeInt2,
+	C.RFCTYPE_INT1:      TypeInt1,
+	C.RFCTYPE_INT8:      TypeInt8,
+	C.RFCTYPE_STRUCTURE: TypeStructure,
+	C.RFCTYPE_DECF16:    TypeDecF16,
+	C.RFCTYPE_DECF34:    TypeDecF34,
+	C.RFCTYPE_STRING:    TypeString,
+	C.RFCTYPE_XSTRING:   TypeXString,
+	C.RFCTYPE_UTCLONG:   TypeUTCLong,
+}
+
+var rfcDirections = map[C.RFC_DIRECTION]string{
+	C.RFC_IMPORT:   DirectionImport,
+	C.RFC_EXPORT:   DirectionExport,
+	C.RFC_CHANGING: DirectionChanging,
+	C.RFC_TABLES:   DirectionTables,
+}
+
+var rfcErrorGroups = map[C.RFC_ERROR_GROUP]string{
+	C.ABAP_APPLICATION_FAILURE:        "ABAP_APPLICATION_FAILURE",
+	C.ABAP_RUNTIME_FAILURE:            "ABAP_RUNTIME_FAILURE",
+	C.LOGON_FAILURE:                   "LOGON_FAILURE",
+	C.COMMUNICATION_FAILURE:           "COMMUNICATION_FAILURE",
+	C.EXTERNAL_RUNTIME_FAILURE:        "EXTERNAL_RUNTIME_FAILURE",
+	C.EXTERNAL_APPLICATION_FAILURE:    "EXTERNAL_APPLICATION_FAILURE",
+	C.EXTERNAL_AUTHORIZATION_FAILURE:  "EXTERNAL_AUTHORIZATION_FAILURE",
+	C.EXTERNAL_AUTHENTICATION_FAILURE: "EXTERNAL_AUTHENTICATION_FAILURE",
+	C.CRYPTOLIB_FAILURE:               "CRYPTOLIB_FAILURE",
+	C.LOCKING_FAILURE:                 "LOCKING_FAILURE",
+}
+
+// sdkConn is a connection opened by the SAP NW RFC SDK
+type sdkConn struct {
+	handle C.RFC_CONNECTION_HANDLE
+}
+
+// DialRFC opens an RFC connection with the SAP NW RFC SDK connection
+// parameters, e.g. ashost, sysnr, client, user, passwd and lang
+func DialRFC(params map[string]string) (RFCConn, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("no RFC connection parameters")
+	}
+	conParams := make([]C.RFC_CONNECTION_PARAMETER, 0, len(params))
+	var allocated []*C.SAP_UC
+	defer func() {
+		for _, p := range allocated {
+			C.free(unsafe.Pointer(p))
+		}
+	}()
+	for name, value := range params {
+		n, _ := ucString(name)
+		v, _ := ucString(value)
+		allocated = append(allocated, n, v)
+		conParams = append(conParams, C.RFC_CONNECTION_PARAMETER{name: n, value: v})
+	}
+
+	var errInfo C.RFC_ERROR_INFO
+	handle := C.RfcOpenConnection(&conParams[0], C.uint(len(conParams)), &errInfo)
+	if handle == nil {
+		return nil, rfcError(&errInfo)
+	}
+	return &sdkConn{handle: handle}, nil
+}
+
+// Close closes the connection
+func (c *sdkConn) Close() error {
+	var errInfo C.RFC_ERROR_INFO
+	if rc := C.RfcCloseConnection(c.handle, &errInfo); rc != C.RFC_OK {
+		return rfcError(&errInfo)
+	}
+	return nil
+}
+
+// Describe looks up the metadata of function in the DDIC of the SAP system;
+// the SDK caches it per system
+func (c *sdkConn) Describe(function string) (*FunctionMeta, error) {
+	desc, err := c.functionDesc(function)
+	if err != nil {
+		return nil, err
+	}
+	params, err := paramDescs(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &FunctionMeta{Name: strings.ToUpper(function)}
+	for i := range params {
+		p := &params[i]
+		param := ParameterMeta{
+			Name:        goString(&p.name[0], len(p.name)),
+			Direction:   rfcDirections[p.direction],
+			Type:        typeName(p._type),
+			Length:      int(p.nucLength),
+			Decimals:    int(p.decimals),
+			Optional:    p.optional != 0,
+			Default:     goString(&p.defaultValue[0], len(p.defaultValue)),
+			Description: goString(&p.parameterText[0], len(p.parameterText)),
+		}
+		if p.typeDescHandle != nil {
+			if param.TypeName, param.Fields, err = describeType(p.typeDescHandle); err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", param.Name, err)
+			}
+		}
+		meta.Parameters = append(meta.Parameters, param)
+	}
+	return meta, nil
+}
+
+// Call invokes function with the values in params of its import, changing
+// and tables parameters and returns its export, changing and tables
+// parameters
+func (c *sdkConn) Call(function string, params map[string]interface{}) (map[string]interface{}, error) {
+	fn, descs, err := c.createFunction(function, params)
+	if err != nil {
+		return nil, err
+	}
+	var errInfo C.RFC_ERROR_INFO
+	defer C.RfcDestroyFunction(fn, &errInfo)
+
+	if rc := C.RfcInvoke(c.handle, fn, &errInfo); rc != C.RFC_OK {
+		return nil, rfcError(&errInfo)
+	}
+	return getParams(C.DATA_CONTAINER_HANDLE(fn), descs, C.RFC_IMPORT)
+}
+
+// NewTID returns a new tRFC transaction ID
+func (c *sdkConn) NewTID() (string, error) {
+	var errInfo C.RFC_ERROR_INFO
+	var tid C.RFC_TID
+	if rc := C.RfcGetTransactionID(c.handle, &tid[0], &errInfo); rc != C.RFC_OK {
+		return "", rfcError(&errInfo)
+	}
+	return goString(&tid[0], len(tid)), nil
+}
+
+// CallTransaction calls function in the tRFC transaction tid. A failed
+// confirmation is not an error, since the call was executed; the SAP
+// system removes unconfirmed transaction IDs in its own reorganization.
+func (c *sdkConn) CallTransaction(tid, function string, params map[string]interface{}) error {
+	fn, _, err := c.createFunction(function, params)
+	if err != nil {
+		return err
+	}
+	var errInfo C.RFC_ERROR_INFO
+	defer C.RfcDestroyFunction(fn, &errInfo)
+
+	var id C.RFC_TID
+	if len(utf16.Encode([]rune(tid))) >= len(id) {
+		return fmt.Errorf("transaction ID %s is too long", tid)
+	}
+	ucCopy(id[:], tid)
+	t := C.RfcCreateTransaction(c.handle, &id[0], nil, &errInfo)
+	if t == nil {
+		return rfcError(&errInfo)
+	}
+	defer C.RfcDestroyTransaction(t, &errInfo)
+	if rc := C.RfcInvokeInTransaction(t, fn, &errInfo); rc != C.RFC_OK {
+		return rfcError(&errInfo)
+	}
+	if rc := C.RfcSubmitTransaction(t, &errInfo); rc != C.RFC_OK {
+		return rfcError(&errInfo)
+	}
+	C.RfcConfirmTransaction(t, &errInfo)
+	return nil
+}
+
+// createFunction creates a call of function with the values in params of its
+// import, changing and tables parameters; the caller destroys it
+func (c *sdkConn) createFunction(function string, params map[string]interface{}) (C.RFC_FUNCTION_HANDLE, []C.RFC_PARAMETER_DESC, error) {
+	desc, err := c.functionDesc(function)
+	if err != nil {
+		return nil, nil, err
+	}
+	descs, err := paramDescs(desc)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key := range params {
+		if findParam(descs, key) == nil {
+			return nil, nil, fmt.Errorf("function %s has no parameter %s", strings.ToUpper(function), key)
+		}
+	}
+
+	var errInfo C.RFC_ERROR_INFO
+	fn := C.RfcCreateFunction(desc, &errInfo)
+	if fn == nil {
+		return nil, nil, rfcError(&errInfo)
+	}
+	container := C.DATA_CONTAINER_HANDLE(fn)
+	for i := range descs {
+		p := &descs[i]
+		if p.direction == C.RFC_EXPORT {
+			continue
+		}
+		name := goString(&p.name[0], len(p.name))
+		v, ok := lookupParam(params, name)
+		if !ok {
+			continue
+		}
+		if err := setValue(container, &p.name[0], p._type, p.typeDescHandle, v); err != nil {
+			C.RfcDestroyFunction(fn, &errInfo)
+			return nil, nil, fmt.Errorf("parameter %s: %w", name, err)
+		}
+	}
+	return fn, descs, nil
+}
+
+// getParams returns the parameters of a call but those of direction skip
+func getParams(h C.DATA_CONTAINER_HANDLE, descs []C.RFC_PARAMETER_DESC, skip C.RFC_DIRECTION) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for i := range descs {
+		p := &descs[i]
+		if p.direction == skip {
+			continue
+		}
+		name := goString(&p.name[0], len(p.name))
+		v, err := getValue(h, &p.name[0], p._type, p.typeDescHandle, p.nucLength)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", name, err)
+		}
+		result[name] = v
+	}
+	return result, nil
+}
+
+func (c *sdkConn) functionDesc(function string) (C.RFC_FUNCTION_DESC_HANDLE, error) {
+	name, _ := ucString(strings.ToUpper(function))
+	defer C.free(unsafe.Pointer(name))
+	var errInfo C.RFC_ERROR_INFO
+	desc := C.RfcGetFunctionDesc(c.handle, name, &errInfo)
+	if desc == nil {
+		return nil, rfcError(&errInfo)
+	}
+	return desc, nil
+}
+
+func paramDescs(desc C.RFC_FUNCTION_DESC_HANDLE) ([]C.RFC_PARAMETER_DESC, error) {
+	var errInfo C.RFC_ERROR_INFO
+	var count C.uint
+	if rc := C.RfcGetParameterCount(desc, &count, &errInfo); rc != C.RFC_OK {
+		return nil, rfcError(&errInfo)
+	}
+	descs := make([]C.RFC_PARAMETER_DESC, count)
+	for i := range descs {
+		if rc := C.RfcGetParameterDescByIndex(desc, C.uint(i), &descs[i], &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+	}
+	return descs, nil
+}
+
+func findParam(descs []C.RFC_PARAMETER_DESC, name string) *C.RFC_PARAMETER_DESC {
+	for i := range descs {
+		if strings.EqualFold(goString(&descs[i].name[0], len(descs[i].name)), name) {
+			return &descs[i]
+		}
+	}
+	return nil
+}
+
+func fieldDescs(td C.RFC_TYPE_DESC_HANDLE) ([]C.RFC_FIELD_DESC, error) {
+	var errInfo C.RFC_ERROR_INFO
+	var count C.uint
+	if rc := C.RfcGetFieldCount(td, &count, &errInfo); rc != C.RFC_OK {
+		return nil, rfcError(&errInfo)
+	}
+	descs := make([]C.RFC_FIELD_DESC, count)
+	for i := range descs {
+		if rc := C.RfcGetFieldDescByIndex(td, C.uint(i), &descs[i], &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+	}
+	return descs, nil
+}
+
+// describeType returns the DDIC name and the fields of a structure or of the
+// rows of a table
+func describeType(td C.RFC_TYPE_DESC_HANDLE) (string, []FieldMeta, error) {
+	var errInfo C.RFC_ERROR_INFO
+	var name C.RFC_ABAP_NAME
+	if rc := C.RfcGetTypeName(td, &name[0], &errInfo); rc != C.RFC_OK {
+		return "", nil, rfcError(&errInfo)
+	}
+	descs, err := fieldDescs(td)
+	if err != nil {
+		return "", nil, err
+	}
+	fields := make([]FieldMeta, len(descs))
+	for i := range descs {
+		f := &descs[i]
+		fields[i] = FieldMeta{
+			Name:     goString(&f.name[0], len(f.name)),
+			Type:     typeName(f._type),
+			Length:   int(f.nucLength),
+			Decimals: int(f.decimals),
+		}
+		if f.typeDescHandle != nil {
+			if fields[i].TypeName, fields[i].Fields, err = describeType(f.typeDescHandle); err != nil {
+				return "", nil, fmt.Errorf("field %s: %w", fields[i].Name, err)
+			}
+		}
+	}
+	return goString(&name[0], len(name)), fields, nil
+}
+
+// setValue sets the parameter or field name of h of type typ to v
+func setValue(h C.DATA_CONTAINER_HANDLE, name *C.SAP_UC, typ C.RFCTYPE, td C.RFC_TYPE_DESC_HANDLE, v interface{}) error {
+	var errInfo C.RFC_ERROR_INFO
+	var rc C.RFC_RC
+	switch typ {
+	case C.RFCTYPE_STRUCTURE:
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object, got %T", v)
+		}
+		var s C.RFC_STRUCTURE_HANDLE
+		if rc = C.RfcGetStructure(h, name, &s, &errInfo); rc != C.RFC_OK {
+			return rfcError(&errInfo)
+		}
+		return setFields(C.DATA_CONTAINER_HANDLE(s), td, fields)
+	case C.RFCTYPE_TABLE:
+		rows, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of rows, got %T", v)
+		}
+		var t C.RFC_TABLE_HANDLE
+		if rc = C.RfcGetTable(h, name, &t, &errInfo); rc != C.RFC_OK {
+			return rfcError(&errInfo)
+		}
+		for i, row := range rows {
+			fields, ok := row.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("row %d: expected an object, got %T", i, row)
+			}
+			line := C.RfcAppendNewRow(t, &errInfo)
+			if line == nil {
+				return rfcError(&errInfo)
+			}
+			if err := setFields(C.DATA_CONTAINER_HANDLE(line), td, fields); err != nil {
+				return fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		return nil
+	case C.RFCTYPE_BYTE, C.RFCTYPE_XSTRING:
+		data, err := abapBytes(v)
+		if err != nil {
+			return err
+		}
+		raw := C.CBytes(data)
+		defer C.free(raw)
+		if typ == C.RFCTYPE_BYTE {
+			rc = C.RfcSetBytes(h, name, (*C.SAP_RAW)(raw), C.uint(len(data)), &errInfo)
+		} else {
+			rc = C.RfcSetXString(h, name, (*C.SAP_RAW)(raw), C.uint(len(data)), &errInfo)
+		}
+	case C.RFCTYPE_INT, C.RFCTYPE_INT1, C.RFCTYPE_INT2:
+		n, err := abapInt(v)
+		if err != nil {
+			return err
+		}
+		rc = C.RfcSetInt(h, name, C.RFC_INT(n), &errInfo)
+	case C.RFCTYPE_INT8:
+		n, err := abapInt(v)
+		if err != nil {
+			return err
+		}
+		rc = C.RfcSetInt8(h, name, C.RFC_INT8(n), &errInfo)
+	case C.RFCTYPE_FLOAT:
+		f, err := abapFloat(v)
+		if err != nil {
+			return err
+		}
+		rc = C.RfcSetFloat(h, name, C.RFC_FLOAT(f), &errInfo)
+	default:
+		// The SDK converts text to the other character-like and decimal types
+		s, err := abapString(typeName(typ), v)
+		if err != nil {
+			return err
+		}
+		value, n := ucString(s)
+		defer C.free(unsafe.Pointer(value))
+		rc = C.RfcSetString(h, name, value, n, &errInfo)
+	}
+	if rc != C.RFC_OK {
+		return rfcError(&errInfo)
+	}
+	return nil
+}
+
+func setFields(h C.DATA_CONTAINER_HANDLE, td C.RFC_TYPE_DESC_HANDLE, fields map[string]interface{}) error {
+	descs, err := fieldDescs(td)
+	if err != nil {
+		return err
+	}
+	for key, v := range fields {
+		var field *C.RFC_FIELD_DESC
+		for i := range descs {
+			if strings.EqualFold(goString(&descs[i].name[0], len(descs[i].name)), key) {
+				field = &descs[i]
+				break
+			}
+		}
+		if field == nil {
+			return fmt.Errorf("no field %s", key)
+		}
+		if err := setValue(h, &field.name[0], field._type, field.typeDescHandle, v); err != nil {
+			return fmt.Errorf("field %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// getValue returns the parameter or field name of h of type typ and length
+// as a Go value
+func getValue(h C.DATA_CONTAINER_HANDLE, name *C.SAP_UC, typ C.RFCTYPE, td C.RFC_TYPE_DESC_HANDLE, length C.uint) (interface{}, error) {
+	var errInfo C.RFC_ERROR_INFO
+	switch typ {
+	case C.RFCTYPE_STRUCTURE:
+		var s C.RFC_STRUCTURE_HANDLE
+		if rc := C.RfcGetStructure(h, name, &s, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		return getFields(C.DATA_CONTAINER_HANDLE(s), td)
+	case C.RFCTYPE_TABLE:
+		var t C.RFC_TABLE_HANDLE
+		if rc := C.RfcGetTable(h, name, &t, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		var count C.uint
+		if rc := C.RfcGetRowCount(t, &count, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		rows := make([]map[string]interface{}, 0, count)
+		for i := C.uint(0); i < count; i++ {
+			if rc := C.RfcMoveTo(t, i, &errInfo); rc != C.RFC_OK {
+				return nil, rfcError(&errInfo)
+			}
+			line := C.RfcGetCurrentRow(t, &errInfo)
+			if line == nil {
+				return nil, rfcError(&errInfo)
+			}
+			row, err := getFields(C.DATA_CONTAINER_HANDLE(line), td)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i, err)
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	case C.RFCTYPE_INT, C.RFCTYPE_INT1, C.RFCTYPE_INT2:
+		var n C.RFC_INT
+		if rc := C.RfcGetInt(h, name, &n, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		return int64(n), nil
+	case C.RFCTYPE_INT8:
+		var n C.RFC_INT8
+		if rc := C.RfcGetInt8(h, name, &n, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		return int64(n), nil
+	case C.RFCTYPE_FLOAT:
+		var f C.RFC_FLOAT
+		if rc := C.RfcGetFloat(h, name, &f, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		return float64(f), nil
+	case C.RFCTYPE_BYTE:
+		buf := C.malloc(C.size_t(length))
+		defer C.free(buf)
+		if rc := C.RfcGetBytes(h, name, (*C.SAP_RAW)(buf), length, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		return base64.StdEncoding.EncodeToString(C.GoBytes(buf, C.int(length))), nil
+	case C.RFCTYPE_XSTRING:
+		var size, n C.uint
+		if rc := C.RfcGetStringLength(h, name, &size, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		buf := C.malloc(C.size_t(size + 1))
+		defer C.free(buf)
+		if rc := C.RfcGetXString(h, name, (*C.SAP_RAW)(buf), size, &n, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		return base64.StdEncoding.EncodeToString(C.GoBytes(buf, C.int(n))), nil
+	}
+
+	// Other types are read as text, which is long enough for the digits,
+	// sign and separators of decimals
+	size := 2*length + 64
+	if typ == C.RFCTYPE_STRING {
+		if rc := C.RfcGetStringLength(h, name, &size, &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+		size++
+	}
+	buf := (*C.SAP_UC)(C.calloc(C.size_t(size), C.size_t(unsafe.Sizeof(C.SAP_UC(0)))))
+	defer C.free(unsafe.Pointer(buf))
+	var n C.uint
+	if rc := C.RfcGetString(h, name, buf, size, &n, &errInfo); rc != C.RFC_OK {
+		return nil, rfcError(&errInfo)
+	}
+	return goValue(typeName(typ), goString(buf, int(n))), nil
+}
+
+func getFields(h C.DATA_CONTAINER_HANDLE, td C.RFC_TYPE_DESC_HANDLE) (map[string]interface{}, error) {
+	descs, err := fieldDescs(td)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{}, len(descs))
+	for i := range descs {
+		f := &descs[i]
+		name := goString(&f.name[0], len(f.name))
+		v, err := getValue(h, &f.name[0], f._type, f.typeDescHandle, f.nucLength)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		fields[name] = v
+	}
+	return fields, nil
+}
+
+func typeName(typ C.RFCTYPE) string {
+	if name, ok := rfcTypes[typ]; ok {
+		return name
+	}
+	return fmt.Sprintf("RFCTYPE_%d", int(typ))
+}
+
+func rfcError(info *C.RFC_ERROR_INFO) error {
+	group, ok := rfcErrorGroups[info.group]
+	if !ok {
+		group = fmt.Sprintf("ERROR_GROUP_%d", int(info.group))
+	}
+	err := &RFCError{
+		Group:   group,
+		Key:     goString(&info.key[0], len(info.key)),
+		Message: goString(&info.message[0], len(info.message)),
+	}
+	if class := goString(&info.abapMsgClass[0], len(info.abapMsgClass)); class != "" {
+		err.ABAPMessage = goString(&info.abapMsgType[0], len(info.abapMsgType)) + "/" + class + "/" +
+			goString(&info.abapMsgNumber[0], len(info.abapMsgNumber))
+	}
+	return err
+}
+
+// ucString copies s into a NUL-terminated UTF-16 string the caller frees,
+// and returns its length without the NUL
+func ucString(s string) (*C.SAP_UC, C.uint) {
+	units := utf16.Encode([]rune(s))
+	p := (*C.SAP_UC)(C.calloc(C.size_t(len(units)+1), C.size_t(unsafe.Sizeof(C.SAP_UC(0)))))
+	buf := unsafe.Slice(p, len(units)+1)
+	for i, u := range units {
+		buf[i] = C.SAP_UC(u)
+	}
+	return p, C.uint(len(units))
+}
+
+// ucCopy copies s into the fixed-size UTF-16 buffer dst, NUL-terminated and
+// truncated to fit
+func ucCopy(dst []C.SAP_UC, s string) {
+	units := utf16.Encode([]rune(s))
+	if len(units) > len(dst)-1 {
+		units = units[:len(dst)-1]
+	}
+	for i, u := range units {
+		dst[i] = C.SAP_UC(u)
+	}
+	dst[len(units)] = 0
+}
+
+// goString returns the UTF-16 string of up to n units at p, ending at a NUL
+func goString(p *C.SAP_UC, n int) string {
+	units := make([]uint16, 0, n)
+	for _, u := range unsafe.Slice(p, n) {
+		if u == 0 {
+			break
+		}
+		units = append(units, uint16(u))
+	}
+	return string(utf16.Decode(units))
+}
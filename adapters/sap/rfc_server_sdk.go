@@ -0,0 +1,257 @@
+//go:build sapnwrfc && cgo
+
+package sap
+
+/*
+#include <stdlib.h>
+#include <sapnwrfc.h>
+
+extern RFC_RC goServeFunction(RFC_CONNECTION_HANDLE, RFC_FUNCTION_HANDLE, RFC_ERROR_INFO*);
+extern RFC_RC goCheckTID(RFC_CONNECTION_HANDLE, SAP_UC*);
+extern RFC_RC goCommitTID(RFC_CONNECTION_HANDLE, SAP_UC*);
+extern RFC_RC goRollbackTID(RFC_CONNECTION_HANDLE, SAP_UC*);
+extern RFC_RC goConfirmTID(RFC_CONNECTION_HANDLE, SAP_UC*);
+*/
+import "C"
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// The SDK dispatches calls of installed server functions and tRFC
+// transactions to C callbacks of the whole process; they find the handler
+// by the system ID of the calling SAP system
+var rfcServers = struct {
+	sync.Mutex
+	handlers map[string]RFCServerHandler
+}{handlers: make(map[string]RFCServerHandler)}
+
+// sdkListener dispatches the calls of an SAP system to a registered server
+type sdkListener struct {
+	sysID  string
+	params map[string]string
+	closed int32
+	done   chan struct{}
+}
+
+// ListenRFC registers an RFC server at the SAP gateway of params (gwhost,
+// gwserv and program_id) and serves the functions, whose metadata is looked
+// up through repository, with handler. Calls from the SAP system sysID, or
+// from any system when it is empty, reach handler.
+func ListenRFC(sysID string, params map[string]string, repository RFCConn, functions []string, handler RFCServerHandler) (RFCListener, error) {
+	repo, ok := repository.(*sdkConn)
+	if !ok {
+		return nil, fmt.Errorf("the function repository must be an SDK connection")
+	}
+
+	var id *C.SAP_UC
+	if sysID != "" {
+		id, _ = ucString(sysID)
+		defer C.free(unsafe.Pointer(id))
+	}
+	var errInfo C.RFC_ERROR_INFO
+	for _, function := range functions {
+		desc, err := repo.functionDesc(function)
+		if err != nil {
+			return nil, err
+		}
+		if rc := C.RfcInstallServerFunction(id, desc, C.RFC_SERVER_FUNCTION(C.goServeFunction), &errInfo); rc != C.RFC_OK {
+			return nil, rfcError(&errInfo)
+		}
+	}
+	rc := C.RfcInstallTransactionHandlers(id,
+		C.RFC_ON_CHECK_TRANSACTION(C.goCheckTID),
+		C.RFC_ON_COMMIT_TRANSACTION(C.goCommitTID),
+		C.RFC_ON_ROLLBACK_TRANSACTION(C.goRollbackTID),
+		C.RFC_ON_CONFIRM_TRANSACTION(C.goConfirmTID),
+		&errInfo)
+	if rc != C.RFC_OK {
+		return nil, rfcError(&errInfo)
+	}
+
+	rfcServers.Lock()
+	rfcServers.handlers[sysID] = handler
+	rfcServers.Unlock()
+
+	l := &sdkListener{sysID: sysID, params: params, done: make(chan struct{})}
+	handle, err := l.register()
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	go l.serve(handle)
+	return l, nil
+}
+
+func (l *sdkListener) register() (C.RFC_CONNECTION_HANDLE, error) {
+	conParams := make([]C.RFC_CONNECTION_PARAMETER, 0, len(l.params))
+	var allocated []*C.SAP_UC
+	defer func() {
+		for _, p := range allocated {
+			C.free(unsafe.Pointer(p))
+		}
+	}()
+	for name, value := range l.params {
+		n, _ := ucString(name)
+		v, _ := ucString(value)
+		allocated = append(allocated, n, v)
+		conParams = append(conParams, C.RFC_CONNECTION_PARAMETER{name: n, value: v})
+	}
+	if len(conParams) == 0 {
+		return nil, fmt.Errorf("no RFC server parameters")
+	}
+
+	var errInfo C.RFC_ERROR_INFO
+	handle := C.RfcRegisterServer(&conParams[0], C.uint(len(conParams)), &errInfo)
+	if handle == nil {
+		return nil, rfcError(&errInfo)
+	}
+	return handle, nil
+}
+
+// serve dispatches calls until the listener is closed, registering again
+// when the gateway connection breaks
+func (l *sdkListener) serve(handle C.RFC_CONNECTION_HANDLE) {
+	defer close(l.done)
+	var errInfo C.RFC_ERROR_INFO
+	backoff := time.Second
+	for atomic.LoadInt32(&l.closed) == 0 {
+		if handle == nil {
+			var err error
+			if handle, err = l.register(); err != nil {
+				slog.Warn("RFC server registration failed", logging.KeyError, err, "retryIn", backoff)
+				time.Sleep(backoff)
+				if backoff < time.Minute {
+					backoff *= 2
+				}
+				continue
+			}
+			backoff = time.Second
+		}
+		switch C.RfcListenAndDispatch(handle, 1, &errInfo) {
+		case C.RFC_CLOSED, C.RFC_COMMUNICATION_FAILURE:
+			slog.Warn("RFC server connection lost", logging.KeyError, rfcError(&errInfo))
+			C.RfcCloseConnection(handle, &errInfo)
+			handle = nil
+		}
+	}
+	if handle != nil {
+		C.RfcCloseConnection(handle, &errInfo)
+	}
+}
+
+// Close stops dispatching and unregisters the server
+func (l *sdkListener) Close() error {
+	if atomic.CompareAndSwapInt32(&l.closed, 0, 1) {
+		rfcServers.Lock()
+		delete(rfcServers.handlers, l.sysID)
+		rfcServers.Unlock()
+	}
+	select {
+	case <-l.done:
+	case <-time.After(5 * time.Second):
+	}
+	return nil
+}
+
+// serverHandler returns the handler of the SAP system calling on handle
+func serverHandler(handle C.RFC_CONNECTION_HANDLE) RFCServerHandler {
+	var attrs C.RFC_ATTRIBUTES
+	var errInfo C.RFC_ERROR_INFO
+	sysID := ""
+	if C.RfcGetConnectionAttributes(handle, &attrs, &errInfo) == C.RFC_OK {
+		sysID = goString(&attrs.sysId[0], len(attrs.sysId))
+	}
+	rfcServers.Lock()
+	defer rfcServers.Unlock()
+	if h, ok := rfcServers.handlers[sysID]; ok {
+		return h
+	}
+	return rfcServers.handlers[""]
+}
+
+//export goServeFunction
+func goServeFunction(handle C.RFC_CONNECTION_HANDLE, fn C.RFC_FUNCTION_HANDLE, errInfo *C.RFC_ERROR_INFO) C.RFC_RC {
+	fail := func(err error) C.RFC_RC {
+		errInfo.code = C.RFC_EXTERNAL_FAILURE
+		errInfo.group = C.EXTERNAL_APPLICATION_FAILURE
+		ucCopy(errInfo.key[:], "A2A_CONNECTOR_ERROR")
+		ucCopy(errInfo.message[:], err.Error())
+		return C.RFC_EXTERNAL_FAILURE
+	}
+	handler := serverHandler(handle)
+	if handler == nil {
+		return fail(fmt.Errorf("no RFC server handler"))
+	}
+
+	var info C.RFC_ERROR_INFO
+	var context C.RFC_SERVER_CONTEXT
+	tid := ""
+	if C.RfcGetServerContext(handle, &context, &info) == C.RFC_OK && context._type != C.RFC_SYNCHRONOUS {
+		tid = goString(&context.tid[0], len(context.tid))
+	}
+	desc := C.RfcDescribeFunction(fn, &info)
+	if desc == nil {
+		return fail(rfcError(&info))
+	}
+	var name C.RFC_ABAP_NAME
+	if C.RfcGetFunctionName(desc, &name[0], &info) != C.RFC_OK {
+		return fail(rfcError(&info))
+	}
+	descs, err := paramDescs(desc)
+	if err != nil {
+		return fail(err)
+	}
+	params, err := getParams(C.DATA_CONTAINER_HANDLE(fn), descs, C.RFC_EXPORT)
+	if err != nil {
+		return fail(err)
+	}
+	if err := handler.Serve(tid, goString(&name[0], len(name)), params); err != nil {
+		return fail(err)
+	}
+	return C.RFC_OK
+}
+
+//export goCheckTID
+func goCheckTID(handle C.RFC_CONNECTION_HANDLE, tid *C.SAP_UC) C.RFC_RC {
+	handler := serverHandler(handle)
+	if handler == nil {
+		return C.RFC_EXTERNAL_FAILURE
+	}
+	if !handler.CheckTID(goString(tid, C.RFC_TID_LN)) {
+		return C.RFC_EXECUTED
+	}
+	return C.RFC_OK
+}
+
+//export goCommitTID
+func goCommitTID(handle C.RFC_CONNECTION_HANDLE, tid *C.SAP_UC) C.RFC_RC {
+	handler := serverHandler(handle)
+	if handler == nil || handler.CommitTID(goString(tid, C.RFC_TID_LN)) != nil {
+		return C.RFC_EXTERNAL_FAILURE
+	}
+	return C.RFC_OK
+}
+
+//export goRollbackTID
+func goRollbackTID(handle C.RFC_CONNECTION_HANDLE, tid *C.SAP_UC) C.RFC_RC {
+	if handler := serverHandler(handle); handler != nil {
+		handler.RollbackTID(goString(tid, C.RFC_TID_LN))
+	}
+	return C.RFC_OK
+}
+
+//export goConfirmTID
+func goConfirmTID(handle C.RFC_CONNECTION_HANDLE, tid *C.SAP_UC) C.RFC_RC {
+	if handler := serverHandler(handle); handler != nil {
+		handler.ConfirmTID(goString(tid, C.RFC_TID_LN))
+	}
+	return C.RFC_OK
+}
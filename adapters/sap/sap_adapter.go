@@ -2,10 +2,17 @@
 package sap
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
 )
 
 // IntegrationType defines the SAP integration method
@@ -29,9 +36,31 @@ type SAPAdapter struct {
 	Username          string
 	Password          string
 	Language          string
-	ConnectionPool    interface{} // Placeholder for actual connection pool
+	SystemNumber      string // RFC/BAPI; derived from a 33NN gateway port when empty
+	Router            string // SAProuter string, e.g. /H/router.example.com/S/3299
+	GatewayHost       string // IDoc RFC server; ServerHost when empty
+	GatewayService    string // IDoc RFC server; sapgwNN when empty
+	ProgramID         string // IDoc RFC server registered to receive IDocs
+	TIDDir            string // IDoc transactions kept across restarts
 	MaxConnections    int
 	ConnectionTimeout time.Duration
+	HTTPClient        *http.Client // OData services; SetAuth adds credentials
+
+	// Dial opens RFC connections; DialRFC by default
+	Dial func(params map[string]string) (RFCConn, error)
+	// Listen registers the RFC server receiving IDocs; ListenRFC by default
+	Listen func(sysID string, params map[string]string, repository RFCConn, functions []string, handler RFCServerHandler) (RFCListener, error)
+
+	// pool holds the open RFC connections of RFC, BAPI and IDoc integrations
+	pool *rfcPool
+
+	tids       *tidStore
+	receiver   *idocReceiver
+	listener   RFCListener
+	repository RFCConn
+
+	layoutsMu sync.Mutex
+	layouts   map[string]map[string][]segmentField
 }
 
 // SAPAdapterConfig contains configuration for the SAP adapter
@@ -44,6 +73,12 @@ type SAPAdapterConfig struct {
 	Username          string
 	Password          string
 	Language          string
+	SystemNumber      string
+	Router            string
+	GatewayHost       string
+	GatewayService    string
+	ProgramID         string
+	TIDDir            string
 	MaxConnections    int
 	ConnectionTimeout int // seconds
 }
@@ -95,6 +130,12 @@ func NewSAPAdapter(name, description string, sapConfig SAPAdapterConfig, general
 		Username:          sapConfig.Username,
 		Password:          sapConfig.Password,
 		Language:          language,
+		SystemNumber:      sapConfig.SystemNumber,
+		Router:            sapConfig.Router,
+		GatewayHost:       sapConfig.GatewayHost,
+		GatewayService:    sapConfig.GatewayService,
+		ProgramID:         sapConfig.ProgramID,
+		TIDDir:            sapConfig.TIDDir,
 		MaxConnections:    maxConn,
 		ConnectionTimeout: time.Duration(timeout) * time.Second,
 	}
@@ -102,7 +143,7 @@ func NewSAPAdapter(name, description string, sapConfig SAPAdapterConfig, general
 
 // Initialize sets up the SAP adapter
 func (a *SAPAdapter) Initialize() error {
-	fmt.Printf("Initializing SAP adapter: %s using %s integration\n", a.Name, a.IntegrationType)
+	a.Logger().Info("initializing SAP adapter", "integration", a.IntegrationType)
 
 	// Validate configuration
 	if err := a.validateConfig(); err != nil {
@@ -129,7 +170,7 @@ func (a *SAPAdapter) Initialize() error {
 		}
 	}
 
-	fmt.Printf("SAP adapter initialized successfully: %s\n", a.Name)
+	a.Logger().Info("SAP adapter initialized")
 	return nil
 }
 
@@ -154,14 +195,15 @@ func (a *SAPAdapter) validateConfig() error {
 
 	// Integration-specific validation
 	switch a.IntegrationType {
-	case RFC, BAPI:
+	case RFC, BAPI, IDoc:
 		if a.SystemID == "" {
-			return fmt.Errorf("system ID is required for RFC/BAPI integration")
+			return fmt.Errorf("system ID is required for RFC/BAPI/IDoc integration")
+		}
+		if _, err := a.systemNumber(); err != nil {
+			return err
 		}
 	case OData:
 		// OData-specific validation
-	case IDoc:
-		// IDoc-specific validation
 	}
 
 	return nil
@@ -170,29 +212,140 @@ func (a *SAPAdapter) validateConfig() error {
 // Connection initialization methods
 
 func (a *SAPAdapter) initializeRFCConnection() error {
-	// TODO: Implement RFC connection initialization
-	// This would typically use a SAP RFC SDK or Go library for SAP RFC
-	fmt.Println("Initializing RFC connection")
+	a.Logger().Debug("initializing RFC connection")
+	dial := a.Dial
+	if dial == nil {
+		dial = DialRFC
+	}
+	params, err := a.rfcParams()
+	if err != nil {
+		return err
+	}
+	a.pool = newRFCPool(func() (RFCConn, error) {
+		return dial(params)
+	}, a.MaxConnections, a.ConnectionTimeout)
+
+	// Open the first connection now, so wrong logon data fails at startup
+	conn, err := a.pool.get(context.Background())
+	if err != nil {
+		return err
+	}
+	a.pool.put(conn, nil)
 	return nil
 }
 
+// rfcParams returns the SAP NW RFC SDK parameters of a direct application
+// server connection
+func (a *SAPAdapter) rfcParams() (map[string]string, error) {
+	sysnr, err := a.systemNumber()
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{
+		"ashost": a.ServerHost,
+		"sysnr":  sysnr,
+		"client": a.Client,
+		"user":   a.Username,
+		"passwd": a.Password,
+		"lang":   a.Language,
+	}
+	if a.Router != "" {
+		params["saprouter"] = a.Router
+	}
+	return params, nil
+}
+
+// systemNumber returns the configured system number, or the NN of a 33NN
+// gateway port
+func (a *SAPAdapter) systemNumber() (string, error) {
+	if a.SystemNumber != "" {
+		return a.SystemNumber, nil
+	}
+	if a.ServerPort >= 3300 && a.ServerPort <= 3399 {
+		return fmt.Sprintf("%02d", a.ServerPort-3300), nil
+	}
+	return "", fmt.Errorf("system number is required unless the server port is a 33NN gateway port")
+}
+
+// initializeIDocConnection opens the RFC connections IDocs are sent on,
+// sends the transactions left over from the last run and, with a program ID,
+// registers the RFC server IDocs are received on
 func (a *SAPAdapter) initializeIDocConnection() error {
-	// TODO: Implement IDoc connection initialization
-	fmt.Println("Initializing IDoc connection")
+	a.Logger().Debug("initializing IDoc connection")
+	if err := a.initializeRFCConnection(); err != nil {
+		return err
+	}
+	tids, err := newTIDStore(a.TIDDir)
+	if err != nil {
+		return fmt.Errorf("failed to load IDoc transactions: %w", err)
+	}
+	a.tids = tids
+	a.resendTransactions()
+
+	if a.ProgramID == "" {
+		return nil
+	}
+	sysnr, err := a.systemNumber()
+	if err != nil {
+		return err
+	}
+	params := map[string]string{
+		"gwhost":     a.GatewayHost,
+		"gwserv":     a.GatewayService,
+		"program_id": a.ProgramID,
+	}
+	if params["gwhost"] == "" {
+		params["gwhost"] = a.ServerHost
+	}
+	if params["gwserv"] == "" {
+		params["gwserv"] = "sapgw" + sysnr
+	}
+	if a.Router != "" {
+		params["saprouter"] = a.Router
+	}
+	listen := a.Listen
+	if listen == nil {
+		listen = ListenRFC
+	}
+
+	// The SDK looks up the metadata of served functions on a client
+	// connection; it stays open for the lifetime of the server
+	repository, err := a.pool.dial()
+	if err != nil {
+		return err
+	}
+	a.receiver = newIDocReceiver(a)
+	a.listener, err = listen(a.SystemID, params, repository, []string{IDocFunction}, a.receiver)
+	if err != nil {
+		repository.Close()
+		return fmt.Errorf("failed to register RFC server %s: %w", a.ProgramID, err)
+	}
+	a.repository = repository
 	return nil
 }
 
 func (a *SAPAdapter) initializeODataConnection() error {
 	// TODO: Implement OData connection initialization
-	fmt.Println("Initializing OData connection")
+	a.Logger().Debug("initializing OData connection")
+	if a.HTTPClient == nil {
+		a.HTTPClient = &http.Client{Timeout: a.ConnectionTimeout}
+	}
 	return nil
 }
 
+// SetAuth adds credentials from p to OData calls, e.g. OAuth2 access tokens
+// issued for SAP BTP
+func (a *SAPAdapter) SetAuth(p authclient.Provider) {
+	if a.HTTPClient == nil {
+		a.HTTPClient = &http.Client{Timeout: a.ConnectionTimeout}
+	}
+	authclient.Apply(a.HTTPClient, p)
+}
+
 func (a *SAPAdapter) initializeBAPIConnection() error {
-	// TODO: Implement BAPI connection initialization
-	// This is often built on top of RFC
-	fmt.Println("Initializing BAPI connection")
-	return nil
+	// BAPIs are function modules called over RFC
+	a.Logger().Debug("initializing BAPI connection")
+	return a.initializeRFCConnection()
 }
 
 // GetCapabilities returns the capabilities of the SAP system
@@ -239,7 +392,7 @@ func (a *SAPAdapter) GetCapabilities() (map[string]interface{}, error) {
 
 // ExecuteTask executes a task on the SAP system
 func (a *SAPAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
-	fmt.Printf("Executing SAP action: %s with params: %v\n", action, params)
+	a.Logger().Debug("executing SAP action", "action", action, "params", params)
 
 	// Execute action based on integration type
 	switch a.IntegrationType {
@@ -330,109 +483,141 @@ func (a *SAPAdapter) callRFCFunction(params map[string]interface{}) (map[string]
 	if !ok {
 		functionParams = make(map[string]interface{})
 	}
+	commit, _ := params["commit"].(bool)
 
-	// TODO: Implement RFC function call
-	// This would use the SAP RFC SDK or Go library
-
-	// Log the parameters we're going to use
-	fmt.Printf("Calling RFC function %s with parameters: %v\n", functionName, functionParams)
-
-	// Return mock response for now
+	a.Logger().Debug("calling RFC function", "function", functionName, "params", functionParams)
+	result, err := a.callFunction(functionName, functionParams, commit)
+	if err != nil {
+		return nil, err
+	}
 	return map[string]interface{}{
-		"function_name":   functionName,
-		"parameters_used": functionParams, // Use the variable here
-		"result": map[string]interface{}{
-			"success": true,
-			"message": fmt.Sprintf("Called function %s successfully", functionName),
-			"data": map[string]interface{}{
-				"mock_output": "Sample output",
-			},
-		},
+		"function_name": functionName,
+		"result":        result,
 	}, nil
 }
 
-func (a *SAPAdapter) getRFCFunctionMetadata(params map[string]interface{}) (map[string]interface{}, error) {
-	// Get function name
-	functionName, ok := params["function_name"].(string)
-	if !ok || functionName == "" {
-		return nil, fmt.Errorf("function_name is required")
+// callFunction calls a function module on a pooled connection. With commit,
+// BAPI_TRANSACTION_COMMIT follows on the same connection, unless the RETURN
+// parameter reports an error, when BAPI_TRANSACTION_ROLLBACK does.
+func (a *SAPAdapter) callFunction(function string, params map[string]interface{}, commit bool) (map[string]interface{}, error) {
+	if a.pool == nil {
+		return nil, fmt.Errorf("RFC connection is not initialized")
 	}
+	conn, err := a.pool.get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	result, err := conn.Call(function, params)
+	if err == nil && commit {
+		if messages := bapiErrors(result); len(messages) > 0 {
+			_, err = conn.Call("BAPI_TRANSACTION_ROLLBACK", nil)
+			if err == nil {
+				err = fmt.Errorf("%s failed and was rolled back: %s", function, strings.Join(messages, "; "))
+			}
+		} else {
+			_, err = conn.Call("BAPI_TRANSACTION_COMMIT", map[string]interface{}{"WAIT": "X"})
+		}
+	}
+	a.pool.put(conn, err)
+	return result, err
+}
 
-	// TODO: Implement metadata retrieval
-
-	// Return mock metadata for now
-	return map[string]interface{}{
-		"function_name": functionName,
-		"metadata": map[string]interface{}{
-			"import_parameters": []map[string]interface{}{
-				{"name": "PARAM1", "type": "STRING", "optional": false},
-				{"name": "PARAM2", "type": "INT", "optional": true},
-			},
-			"export_parameters": []map[string]interface{}{
-				{"name": "RESULT", "type": "STRING"},
-				{"name": "ERROR_CODE", "type": "INT"},
-			},
-			"tables": []map[string]interface{}{
-				{"name": "DATA_TABLE", "line_type": "DATA_TYPE"},
-			},
-		},
-	}, nil
+// bapiErrors returns the error and abort messages of the RETURN parameter of
+// a BAPI, a structure or a table of BAPIRET2
+func bapiErrors(result map[string]interface{}) []string {
+	var messages []map[string]interface{}
+	switch ret := result["RETURN"].(type) {
+	case map[string]interface{}:
+		messages = append(messages, ret)
+	case []map[string]interface{}:
+		messages = ret
+	}
+	var errs []string
+	for _, msg := range messages {
+		if typ, _ := msg["TYPE"].(string); typ == "E" || typ == "A" {
+			text, _ := msg["MESSAGE"].(string)
+			errs = append(errs, text)
+		}
+	}
+	return errs
 }
 
-func (a *SAPAdapter) listRFCFunctions(params map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Implement function listing
+// describeFunction looks up the metadata of a function module in the DDIC
+func (a *SAPAdapter) describeFunction(function string) (map[string]interface{}, error) {
+	if a.pool == nil {
+		return nil, fmt.Errorf("RFC connection is not initialized")
+	}
+	conn, err := a.pool.get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	meta, err := conn.Describe(function)
+	a.pool.put(conn, err)
+	if err != nil {
+		return nil, err
+	}
 
-	// Return mock function list for now
-	return map[string]interface{}{
-		"functions": []string{
-			"RFC_READ_TABLE",
-			"BAPI_COMPANYCODE_GETLIST",
-			"BAPI_CUSTOMER_GETLIST",
-		},
-	}, nil
+	// Results are maps, as those of other adapters
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
 }
 
-// IDoc implementation methods
-
-func (a *SAPAdapter) sendIDoc(params map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Implement IDoc sending
-	return map[string]interface{}{
-		"success":     true,
-		"idoc_number": "12345678",
-		"status":      "sent",
-	}, nil
+// searchFunctions returns the names of the function modules matching
+// pattern, where * is a wildcard
+func (a *SAPAdapter) searchFunctions(pattern string) ([]string, error) {
+	result, err := a.callFunction("RFC_FUNCTION_SEARCH", map[string]interface{}{"FUNCNAME": pattern}, false)
+	var rfcErr *RFCError
+	if errors.As(err, &rfcErr) && rfcErr.Key == "NOTHING_FOUND" {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result["FUNCTIONS"].([]map[string]interface{})
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if name, ok := row["FUNCNAME"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
 }
 
-func (a *SAPAdapter) receiveIDoc(params map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Implement IDoc receiving
+func (a *SAPAdapter) getRFCFunctionMetadata(params map[string]interface{}) (map[string]interface{}, error) {
+	// Get function name
+	functionName, ok := params["function_name"].(string)
+	if !ok || functionName == "" {
+		return nil, fmt.Errorf("function_name is required")
+	}
+
+	metadata, err := a.describeFunction(functionName)
+	if err != nil {
+		return nil, err
+	}
 	return map[string]interface{}{
-		"success": true,
-		"idocs": []map[string]interface{}{
-			{
-				"idoc_number": "87654321",
-				"idoc_type":   "MATMAS",
-				"mestype":     "MATMAS",
-				"status":      "new",
-			},
-		},
+		"function_name": functionName,
+		"metadata":      metadata,
 	}, nil
 }
 
-func (a *SAPAdapter) getIDocStatus(params map[string]interface{}) (map[string]interface{}, error) {
-	// Get IDoc number
-	idocNumber, ok := params["idoc_number"].(string)
-	if !ok || idocNumber == "" {
-		return nil, fmt.Errorf("idoc_number is required")
+func (a *SAPAdapter) listRFCFunctions(params map[string]interface{}) (map[string]interface{}, error) {
+	pattern, _ := params["pattern"].(string)
+	if pattern == "" {
+		pattern = "*"
+	}
+	functions, err := a.searchFunctions(pattern)
+	if err != nil {
+		return nil, err
 	}
-
-	// TODO: Implement status check
-
 	return map[string]interface{}{
-		"idoc_number":        idocNumber,
-		"status":             "processed",
-		"status_code":        3,
-		"status_description": "Processed successfully",
-		"timestamp":          time.Now().Format(time.RFC3339),
+		"functions": functions,
 	}, nil
 }
 
@@ -504,56 +689,63 @@ func (a *SAPAdapter) callBAPI(params map[string]interface{}) (map[string]interfa
 	if !ok {
 		bapiParams = make(map[string]interface{})
 	}
+	commit, _ := params["commit"].(bool)
 
-	// TODO: Implement BAPI call (often using RFC underneath)
-
-	// Log the parameters we're going to use
-	fmt.Printf("Calling BAPI function %s with parameters: %v\n", bapiName, bapiParams)
-
+	a.Logger().Debug("calling BAPI function", "function", bapiName, "params", bapiParams)
+	result, err := a.callFunction(bapiName, bapiParams, commit)
+	if err != nil {
+		return nil, err
+	}
 	return map[string]interface{}{
-		"bapi_name":   bapiName,
-		"bapi_params": bapiParams,
-		"result": map[string]interface{}{
-			"success": true,
-			"return": []map[string]interface{}{
-				{
-					"TYPE":    "S",
-					"ID":      "00",
-					"NUMBER":  "000",
-					"MESSAGE": "BAPI executed successfully",
-				},
-			},
-		},
+		"bapi_name": bapiName,
+		"result":    result,
 	}, nil
 }
 
 func (a *SAPAdapter) getBAPIMetadata(params map[string]interface{}) (map[string]interface{}, error) {
-	// Similar to RFC metadata
-	return a.getRFCFunctionMetadata(params)
+	name, _ := params["bapi_name"].(string)
+	if name == "" {
+		// Similar to RFC metadata
+		return a.getRFCFunctionMetadata(params)
+	}
+	metadata, err := a.describeFunction(name)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"bapi_name": name,
+		"metadata":  metadata,
+	}, nil
 }
 
 func (a *SAPAdapter) listBAPIs(params map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Implement BAPI listing
-
+	bapis, err := a.searchFunctions("BAPI_*")
+	if err != nil {
+		return nil, err
+	}
 	return map[string]interface{}{
-		"bapis": []string{
-			"BAPI_CUSTOMER_GETLIST",
-			"BAPI_CUSTOMER_CREATEFROMDATA",
-			"BAPI_MATERIAL_GETLIST",
-		},
+		"bapis": bapis,
 	}, nil
 }
 
 // Close cleans up resources
 func (a *SAPAdapter) Close() error {
-	fmt.Println("Closing SAP adapter")
+	a.Logger().Info("closing SAP adapter")
 
 	// Close connections based on integration type
 	switch a.IntegrationType {
 	case RFC, BAPI:
-		// TODO: Close RFC connections
+		if a.pool != nil {
+			a.pool.close()
+		}
 	case IDoc:
-		// TODO: Close IDoc connections
+		if a.listener != nil {
+			a.listener.Close()
+			a.repository.Close()
+		}
+		if a.pool != nil {
+			a.pool.close()
+		}
 	case OData:
 		// TODO: Close any persistent connections
 	}
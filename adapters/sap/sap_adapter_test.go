@@ -0,0 +1,180 @@
+package sap_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/adapters/sap"
+)
+
+// fakeRFC records the calls made on it and answers them from results
+type fakeRFC struct {
+	calls   []string
+	results map[string]map[string]interface{}
+	errs    map[string]error
+	closed  bool
+}
+
+func (c *fakeRFC) Call(function string, params map[string]interface{}) (map[string]interface{}, error) {
+	c.calls = append(c.calls, function)
+	if err := c.errs[function]; err != nil {
+		return nil, err
+	}
+	return c.results[function], nil
+}
+
+func (c *fakeRFC) Describe(function string) (*sap.FunctionMeta, error) {
+	return &sap.FunctionMeta{Name: function, Parameters: []sap.ParameterMeta{
+		{Name: "CUSTOMERNO", Direction: sap.DirectionImport, Type: sap.TypeChar, Length: 10},
+		{Name: "ADDRESS", Direction: sap.DirectionExport, Type: sap.TypeStructure, TypeName: "BAPICUSTOMER_04",
+			Fields: []sap.FieldMeta{{Name: "NAME", Type: sap.TypeChar, Length: 40}}},
+	}}, nil
+}
+
+func (c *fakeRFC) Close() error {
+	c.closed = true
+	return nil
+}
+
+func newRFCAdapter(t *testing.T, integration string, conns *[]*fakeRFC, newConn func() *fakeRFC) *sap.SAPAdapter {
+	t.Helper()
+	a := sap.NewSAPAdapter("erp", "SAP ERP", sap.SAPAdapterConfig{
+		IntegrationType: integration,
+		ServerHost:      "sap.example.com",
+		ServerPort:      3300,
+		SystemID:        "PRD",
+		Client:          "100",
+		Username:        "RFCUSER",
+		Password:        "secret",
+		MaxConnections:  1,
+	}, nil)
+	a.Dial = func(params map[string]string) (sap.RFCConn, error) {
+		if params["sysnr"] != "00" || params["ashost"] != "sap.example.com" {
+			t.Errorf("Unexpected connection parameters %v", params)
+		}
+		conn := newConn()
+		*conns = append(*conns, conn)
+		return conn, nil
+	}
+	if err := a.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	return a
+}
+
+func TestRFCCall(t *testing.T) {
+	var conns []*fakeRFC
+	a := newRFCAdapter(t, "rfc", &conns, func() *fakeRFC {
+		return &fakeRFC{results: map[string]map[string]interface{}{
+			"BAPI_CUSTOMER_GETDETAIL": {"ADDRESS": map[string]interface{}{"NAME": "ACME"}},
+			"RFC_FUNCTION_SEARCH": {"FUNCTIONS": []map[string]interface{}{
+				{"FUNCNAME": "BAPI_CUSTOMER_GETDETAIL"}, {"FUNCNAME": "BAPI_CUSTOMER_GETLIST"},
+			}},
+		}}
+	})
+
+	result, err := a.ExecuteTask("call_function", map[string]interface{}{
+		"function_name": "BAPI_CUSTOMER_GETDETAIL",
+		"parameters":    map[string]interface{}{"CUSTOMERNO": "0000001000"},
+	})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	address := result["result"].(map[string]interface{})["ADDRESS"].(map[string]interface{})
+	if address["NAME"] != "ACME" {
+		t.Errorf("Expected the export parameters, got %v", result)
+	}
+
+	functions, err := a.ExecuteTask("list_functions", map[string]interface{}{"pattern": "BAPI_CUSTOMER*"})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if names := functions["functions"].([]string); len(names) != 2 {
+		t.Errorf("Expected the functions found by RFC_FUNCTION_SEARCH, got %v", names)
+	}
+
+	meta, err := a.ExecuteTask("get_function_metadata", map[string]interface{}{"function_name": "BAPI_CUSTOMER_GETDETAIL"})
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	params := meta["metadata"].(map[string]interface{})["parameters"].([]interface{})
+	if len(params) != 2 || params[1].(map[string]interface{})["typeName"] != "BAPICUSTOMER_04" {
+		t.Errorf("Expected the DDIC metadata, got %v", meta)
+	}
+
+	if len(conns) != 1 {
+		t.Errorf("Expected calls to reuse the pooled connection, opened %d", len(conns))
+	}
+	a.Close()
+	if !conns[0].closed {
+		t.Error("Expected Close to close the pooled connection")
+	}
+}
+
+func TestRFCBrokenConnection(t *testing.T) {
+	var conns []*fakeRFC
+	a := newRFCAdapter(t, "rfc", &conns, func() *fakeRFC {
+		return &fakeRFC{errs: map[string]error{
+			"Z_DUMP":   &sap.RFCError{Group: "ABAP_RUNTIME_FAILURE", Key: "COMPUTE_INT_ZERODIVIDE"},
+			"Z_RAISES": &sap.RFCError{Group: "ABAP_APPLICATION_FAILURE", Key: "NOT_FOUND"},
+		}}
+	})
+
+	_, err := a.ExecuteTask("call_function", map[string]interface{}{"function_name": "Z_RAISES"})
+	var rfcErr *sap.RFCError
+	if !errors.As(err, &rfcErr) || rfcErr.Key != "NOT_FOUND" {
+		t.Fatalf("Expected the ABAP exception, got %v", err)
+	}
+	if _, err := a.ExecuteTask("call_function", map[string]interface{}{"function_name": "Z_DUMP"}); err == nil {
+		t.Fatal("Expected the short dump to fail the call")
+	}
+	if !conns[0].closed {
+		t.Error("Expected the connection ended by the short dump to be closed")
+	}
+	if _, err := a.ExecuteTask("call_function", map[string]interface{}{"function_name": "Z_OK"}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if len(conns) != 2 {
+		t.Errorf("Expected a new connection after the broken one, opened %d", len(conns))
+	}
+}
+
+func TestBAPICommit(t *testing.T) {
+	var conns []*fakeRFC
+	a := newRFCAdapter(t, "bapi", &conns, func() *fakeRFC {
+		return &fakeRFC{results: map[string]map[string]interface{}{
+			"BAPI_SALESORDER_CREATEFROMDAT2": {"SALESDOCUMENT": "0000012345", "RETURN": []map[string]interface{}{
+				{"TYPE": "S", "MESSAGE": "Order saved"},
+			}},
+			"BAPI_CUSTOMER_CHANGEFROMDATA1": {"RETURN": map[string]interface{}{"TYPE": "E", "MESSAGE": "Customer locked"}},
+		}}
+	})
+
+	if _, err := a.ExecuteTask("call_bapi", map[string]interface{}{"bapi_name": "BAPI_SALESORDER_CREATEFROMDAT2", "commit": true}); err != nil {
+		t.Fatalf("BAPI failed: %v", err)
+	}
+	if _, err := a.ExecuteTask("call_bapi", map[string]interface{}{"bapi_name": "BAPI_CUSTOMER_CHANGEFROMDATA1", "commit": true}); err == nil {
+		t.Error("Expected a BAPI returning an error to fail")
+	}
+	want := []string{"BAPI_SALESORDER_CREATEFROMDAT2", "BAPI_TRANSACTION_COMMIT", "BAPI_CUSTOMER_CHANGEFROMDATA1", "BAPI_TRANSACTION_ROLLBACK"}
+	calls := conns[0].calls
+	if len(calls) != len(want) {
+		t.Fatalf("Expected calls %v, got %v", want, calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("Expected calls %v, got %v", want, calls)
+			break
+		}
+	}
+}
+
+func TestRFCWithoutSDK(t *testing.T) {
+	a := sap.NewSAPAdapter("erp", "SAP ERP", sap.SAPAdapterConfig{
+		ServerHost: "sap.example.com", ServerPort: 3300, SystemID: "PRD",
+		Client: "100", Username: "RFCUSER", Password: "secret",
+	}, nil)
+	if err := a.Initialize(); !errors.Is(err, sap.ErrRFCUnavailable) {
+		t.Errorf("Expected a build without the SDK to report it, got %v", err)
+	}
+}
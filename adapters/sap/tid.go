@@ -0,0 +1,143 @@
+package sap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Directions and states of tRFC transactions
+const (
+	tidOutbound = "outbound"
+	tidInbound  = "inbound"
+
+	// tidCreated transactions were not executed yet: outbound ones are sent
+	// again with the same ID, inbound ones are being received
+	tidCreated = "created"
+	// tidCommitted inbound transactions were received and wait to be taken
+	tidCommitted = "committed"
+)
+
+var tidPattern = regexp.MustCompile(`^[0-9A-Za-z]+$`)
+
+// tidEntry is a tRFC transaction the connector has not finished with
+type tidEntry struct {
+	TID       string                 `json:"tid"`
+	Direction string                 `json:"direction"`
+	State     string                 `json:"state"`
+	Function  string                 `json:"function"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+	Created   time.Time              `json:"created"`
+}
+
+// tidStore keeps the transactions in memory and, with a directory, in one
+// file each so they survive restarts
+type tidStore struct {
+	mu      sync.Mutex
+	dir     string
+	entries map[string]tidEntry
+}
+
+func newTIDStore(dir string) (*tidStore, error) {
+	s := &tidStore{dir: dir, entries: make(map[string]tidEntry)}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var entry tidEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", filepath.Base(file), err)
+		}
+		s.entries[entry.TID] = entry
+	}
+	return s, nil
+}
+
+func (s *tidStore) path(tid string) (string, error) {
+	if !tidPattern.MatchString(tid) {
+		return "", fmt.Errorf("invalid transaction ID %q", tid)
+	}
+	return filepath.Join(s.dir, strings.ToUpper(tid)+".json"), nil
+}
+
+func (s *tidStore) put(entry tidEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir != "" {
+		path, err := s.path(entry.TID)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		tmp := path + ".tmp"
+		if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return err
+		}
+	}
+	s.entries[entry.TID] = entry
+	return nil
+}
+
+func (s *tidStore) get(tid string) (tidEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[tid]
+	return entry, ok
+}
+
+func (s *tidStore) remove(tid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, tid)
+	if s.dir == "" {
+		return nil
+	}
+	path, err := s.path(tid)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// list returns the transactions of a direction in a state, oldest first
+func (s *tidStore) list(direction, state string) []tidEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []tidEntry
+	for _, entry := range s.entries {
+		if entry.Direction == direction && entry.State == state {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Created.Before(entries[j].Created)
+	})
+	return entries
+}
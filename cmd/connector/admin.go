@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/anonymize"
+	"github.com/A2AGateway/a2a-connector/internal/deadletter"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/schedule"
+	"github.com/A2AGateway/a2a-connector/internal/security"
+)
+
+// defaultCoverageLimit is the number of unmatched intents /admin/coverage
+// reports by default
+const defaultCoverageLimit = 20
+
+// registerAdminRoutes adds the admin API used by config authors and operators
+func registerAdminRoutes(mux *http.ServeMux, unmatched *proxy.UnmatchedLog, coverage *proxy.Coverage, anon *anonymize.Anonymizer, deadLetters *deadletter.Queue, replay deadletter.RunFunc, scheduler *schedule.Scheduler) {
+	// Recent tasks whose text matched no mapping, newest first.
+	// ?anonymize=true applies the anonymize rules to export them.
+	// DELETE clears the log between iterations on the config.
+	mux.HandleFunc("/admin/unmatched", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			entries := unmatched.Entries()
+			if entries == nil {
+				entries = []proxy.UnmatchedIntent{}
+			}
+			var body interface{} = entries
+			if r.URL.Query().Get("anonymize") == "true" {
+				if anon == nil {
+					http.Error(w, "no anonymize rules configured", http.StatusBadRequest)
+					return
+				}
+				anonymized, err := anon.JSON(entries)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				body = anonymized
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"count":   len(entries),
+				"entries": body,
+			})
+		case http.MethodDelete:
+			unmatched.Clear()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Tasks matched per mapping, unused mappings included, and the most
+	// frequent unmatched intents, ?limit= of them (default 20).
+	// ?anonymize=true applies the anonymize rules to their samples. DELETE
+	// resets the counts.
+	mux.HandleFunc("/admin/coverage", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			limit := defaultCoverageLimit
+			if v := r.URL.Query().Get("limit"); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 {
+					http.Error(w, "invalid limit", http.StatusBadRequest)
+					return
+				}
+				limit = n
+			}
+			report := coverage.Report(limit)
+			var body interface{} = report
+			if r.URL.Query().Get("anonymize") == "true" {
+				if anon == nil {
+					http.Error(w, "no anonymize rules configured", http.StatusBadRequest)
+					return
+				}
+				anonymized, err := anon.JSON(report)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				body = anonymized
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(body)
+		case http.MethodDelete:
+			coverage.Reset()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Connection events of the adapters, newest first, to diagnose
+	// intermittent network issues. ?adapter= and ?kind= filter them; DELETE
+	// clears them.
+	mux.HandleFunc("/admin/connections", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("adapter")
+		switch r.Method {
+		case http.MethodGet:
+			kind := r.URL.Query().Get("kind")
+			events := []adapter.ConnEvent{}
+			for _, event := range adapter.DefaultConnEvents.Events(name) {
+				if kind == "" || event.Kind == kind {
+					events = append(events, event)
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"adapters": adapter.DefaultConnEvents.Adapters(),
+				"count":    len(events),
+				"events":   events,
+			})
+		case http.MethodDelete:
+			adapter.DefaultConnEvents.Clear(name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Configured schedules with their next and last run, and the error of the
+	// last run if it failed
+	mux.HandleFunc("/admin/schedules", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		jobs := scheduler.Status()
+		if jobs == nil {
+			jobs = []schedule.JobStatus{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"count":     len(jobs),
+			"schedules": jobs,
+		})
+	})
+
+	// Tasks that failed in a transform or the legacy system, newest first.
+	// ?mapping= and ?stage= filter them; DELETE clears them.
+	mux.HandleFunc("/admin/dead-letters", func(w http.ResponseWriter, r *http.Request) {
+		if deadLetters == nil {
+			http.Error(w, "no dead-letter queue configured", http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			entries, err := filterDeadLetters(deadLetters, r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"count":   len(entries),
+				"entries": entries,
+			})
+		case http.MethodDelete:
+			if err := deadLetters.Clear(); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// A dead-letter entry by ID, which DELETE removes. POST .../replay runs
+	// its task again through the current config; POST /admin/dead-letters/replay
+	// replays all entries, or those ?mapping= and ?stage= select. Entries of
+	// tasks that succeed are removed.
+	mux.HandleFunc("/admin/dead-letters/", func(w http.ResponseWriter, r *http.Request) {
+		if deadLetters == nil {
+			http.Error(w, "no dead-letter queue configured", http.StatusNotFound)
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/admin/dead-letters/")
+		id, replaying := strings.CutSuffix(id, "/replay")
+		if id == "replay" {
+			id, replaying = "", true
+		}
+		if replaying {
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			replayDeadLetters(w, r, deadLetters, id, replay)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			entry, err := deadLetters.Get(id)
+			if err != nil {
+				writeDeadLetterError(w, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(entry)
+		case http.MethodDelete:
+			if err := deadLetters.Delete(id); err != nil {
+				writeDeadLetterError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// The signed-in admin user and their roles when single sign-on is enabled
+	mux.HandleFunc("/admin/whoami", func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := security.PrincipalFromContext(r.Context())
+		if !ok {
+			http.Error(w, "no signed-in user", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"subject": principal.Subject,
+			"method":  principal.Method,
+			"roles":   principal.Scopes,
+		})
+	})
+}
+
+// filterDeadLetters returns the dead-letter entries selected by the mapping
+// and stage query params
+func filterDeadLetters(deadLetters *deadletter.Queue, r *http.Request) ([]deadletter.Entry, error) {
+	entries, err := deadLetters.Entries()
+	if err != nil {
+		return nil, err
+	}
+	mapping := r.URL.Query().Get("mapping")
+	stage := r.URL.Query().Get("stage")
+	selected := []deadletter.Entry{}
+	for _, entry := range entries {
+		if (mapping == "" || entry.MappingID == mapping) && (stage == "" || entry.Stage == stage) {
+			selected = append(selected, entry)
+		}
+	}
+	return selected, nil
+}
+
+// replayDeadLetters replays the entry with id, or the selected entries when
+// id is empty, oldest first. Replays run without the admin caller, whose
+// roles are not task scopes, but stop when the admin request is cancelled.
+func replayDeadLetters(w http.ResponseWriter, r *http.Request, deadLetters *deadletter.Queue, id string, replay deadletter.RunFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := context.AfterFunc(r.Context(), cancel)
+	defer stop()
+
+	w.Header().Set("Content-Type", "application/json")
+	if id != "" {
+		result, err := deadLetters.Replay(ctx, id, replay)
+		if err != nil {
+			w.Header().Del("Content-Type")
+			writeDeadLetterError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	entries, err := filterDeadLetters(deadLetters, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	results := []deadletter.ReplayResult{}
+	succeeded := 0
+	for i := len(entries) - 1; i >= 0 && ctx.Err() == nil; i-- {
+		result, err := deadLetters.Replay(ctx, entries[i].ID, replay)
+		if err != nil {
+			continue
+		}
+		if result.Succeeded {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":     len(results),
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
+
+// writeDeadLetterError answers 404 for unknown entries and 500 otherwise
+func writeDeadLetterError(w http.ResponseWriter, err error) {
+	if errors.Is(err, deadletter.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
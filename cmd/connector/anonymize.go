@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/anonymize"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// runAnonymize implements `connector anonymize`: it applies the anonymize
+// rules of a config to recorded traffic or task fixtures before they are
+// shared. The format follows the file extension: .yaml or .yml for fixtures,
+// .jsonl or .ndjson for one recorded task per line, JSON otherwise.
+func runAnonymize(args []string) error {
+	fs := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	configFile := fs.String("config", "connector.yaml", "Path to YAML/JSON config file with anonymize rules")
+	inFile := fs.String("in", "", "Path of the recorded traffic or fixtures to anonymize")
+	outFile := fs.String("out", "", "Path to write the anonymized file to (default: stdout)")
+	fs.Parse(args)
+
+	if *inFile == "" {
+		return fmt.Errorf("--in is required")
+	}
+	cfg, err := config.LoadFromFile(*configFile)
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		return err
+	}
+	anon := anonymize.New(cfg.Anonymize)
+	if anon == nil {
+		return fmt.Errorf("%s has no anonymize rules", *configFile)
+	}
+
+	data, err := ioutil.ReadFile(*inFile)
+	if err != nil {
+		return err
+	}
+	out, err := anonymizeFile(anon, data, filepath.Ext(*inFile))
+	if err != nil {
+		return fmt.Errorf("%s: %w", *inFile, err)
+	}
+	if *outFile == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return ioutil.WriteFile(*outFile, out, 0644)
+}
+
+// anonymizeFile anonymizes the documents of a file in the format of ext
+func anonymizeFile(anon *anonymize.Anonymizer, data []byte, ext string) ([]byte, error) {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		var out bytes.Buffer
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(anon.Value(doc)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	case ".jsonl", ".ndjson":
+		var out bytes.Buffer
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var doc interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+				return nil, fmt.Errorf("line %d: invalid JSON: %w", line, err)
+			}
+			if err := writeJSON(&out, anon.Value(doc), false); err != nil {
+				return nil, err
+			}
+		}
+		return out.Bytes(), scanner.Err()
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	var out bytes.Buffer
+	err := writeJSON(&out, anon.Value(doc), true)
+	return out.Bytes(), err
+}
+
+func writeJSON(w io.Writer, v interface{}, indent bool) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(v)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/batch"
+	"github.com/A2AGateway/a2a-connector/internal/deadletter"
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/metrics"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	a2a "github.com/A2AGateway/a2a-protocol"
+)
+
+// handleBatch runs the chunk tasks of a batch file in order, each like a task
+// of its own, and answers with a completion summary task
+func handleBatch(ctx context.Context, w http.ResponseWriter, rpcReq a2a.JSONRPCRequest, splitter *batch.Splitter, chunks []map[string]interface{}, transformer *proxy.Transformer, adptr adapter.Adapter, catalog *i18n.Catalog, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool, locale string) {
+	json.NewEncoder(w).Encode(a2a.JSONRPCResponse{
+		JSONRPC: a2a.JSONRPCVersion,
+		ID:      rpcReq.ID,
+		Result:  runBatch(ctx, rpcReq, splitter, chunks, transformer, adptr, catalog, taskMetrics, deadLetters, workers, locale),
+	})
+}
+
+// runBatch runs the chunk tasks of a batch file in order and returns the
+// completion summary task
+func runBatch(ctx context.Context, rpcReq a2a.JSONRPCRequest, splitter *batch.Splitter, chunks []map[string]interface{}, transformer *proxy.Transformer, adptr adapter.Adapter, catalog *i18n.Catalog, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool, locale string) map[string]interface{} {
+	batchID, _ := rpcReq.Params.(map[string]interface{})["id"].(string)
+	logger := logging.FromContext(ctx).With(logging.KeyTaskID, batchID)
+
+	summary := splitter.Run(batchID, chunks, func(chunk map[string]interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		task, rpcErr := executeTask(ctx, chunk, transformer, adptr, catalog, taskMetrics, deadLetters, workers, locale)
+		if rpcErr != nil {
+			if rpcErr.Data != nil {
+				return fmt.Errorf("%s: %v", rpcErr.Message, rpcErr.Data)
+			}
+			return fmt.Errorf("%s", rpcErr.Message)
+		}
+		status, _ := task.(map[string]interface{})["status"].(map[string]interface{})
+		if status["state"] == string(a2a.TaskStateFailed) {
+			return fmt.Errorf("%s", taskText(status))
+		}
+		return nil
+	})
+	logger.Info("batch completed", "records", summary.Records, "tasks", summary.Tasks,
+		"succeeded", summary.Succeeded, "failed", summary.Failed, "skipped", summary.Skipped)
+
+	text := catalog.T(locale, i18n.MsgBatchSummary, summary.Records, summary.Tasks, summary.Succeeded, summary.Failed, summary.Skipped)
+	return summary.Task(text)
+}
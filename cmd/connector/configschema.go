@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// runConfig implements `connector config schema`: it prints the schema of
+// config files, generated from the config types of this build, as JSON Schema
+// for editors and CI or as an example config with every field annotated
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "schema" {
+		return fmt.Errorf("usage: connector config schema [--format json|yaml] [--out file]")
+	}
+	fs := flag.NewFlagSet("config schema", flag.ExitOnError)
+	format := fs.String("format", "json", "Output format: json (JSON Schema) or yaml (annotated example config)")
+	outFile := fs.String("out", "", "Path to write the schema to (default: stdout)")
+	fs.Parse(args[1:])
+
+	var out bytes.Buffer
+	switch *format {
+	case "json":
+		if err := writeJSON(&out, config.JSONSchema(), true); err != nil {
+			return err
+		}
+	case "yaml":
+		out.WriteString(config.ExampleYAML())
+	default:
+		return fmt.Errorf("unsupported --format %q, expected json or yaml", *format)
+	}
+
+	if *outFile == "" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	return ioutil.WriteFile(*outFile, out.Bytes(), 0644)
+}
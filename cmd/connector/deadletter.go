@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/deadletter"
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+	"github.com/A2AGateway/a2a-connector/internal/metrics"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+	a2a "github.com/A2AGateway/a2a-protocol"
+)
+
+// recordDeadLetter adds a task that failed at stage to the dead-letter queue,
+// with the legacy request the transform made of it, if any
+func recordDeadLetter(ctx context.Context, deadLetters *deadletter.Queue, stage string, task, legacyRequest []byte, mappingID string, err error) {
+	if deadLetters == nil {
+		return
+	}
+	entry := deadletter.Entry{
+		MappingID: mappingID,
+		Stage:     stage,
+		Task:      task,
+		Error:     secrets.Redact(err.Error()),
+	}
+	if len(legacyRequest) > 0 {
+		entry.Request = legacyRequest
+	}
+	var taskMap map[string]interface{}
+	if json.Unmarshal(task, &taskMap) == nil {
+		entry.TaskID, _ = taskMap["id"].(string)
+	}
+	var detailed adapter.DetailedError
+	if errors.As(err, &detailed) {
+		entry.ErrorDetails = secrets.RedactValue(detailed.Details())
+	}
+	deadLetters.Add(ctx, entry)
+}
+
+// runTask returns the function that runs dead-lettered and scheduled tasks
+// through a task stack; a task fails when it ends in the failed state
+func runTask(transformer *proxy.Transformer, adptr adapter.Adapter, catalog *i18n.Catalog, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool) deadletter.RunFunc {
+	return func(ctx context.Context, data json.RawMessage) (interface{}, error) {
+		var taskParams interface{}
+		if err := json.Unmarshal(data, &taskParams); err != nil {
+			return nil, err
+		}
+		result, rpcErr := executeTask(ctx, taskParams, transformer, adptr, catalog, taskMetrics, deadLetters, workers, "")
+		if rpcErr != nil {
+			return rpcErr, fmt.Errorf("%s", rpcErr.Message)
+		}
+		task, _ := result.(map[string]interface{})
+		status, _ := task["status"].(map[string]interface{})
+		if status["state"] == string(a2a.TaskStateFailed) {
+			return result, fmt.Errorf("%s", taskText(status))
+		}
+		return result, nil
+	}
+}
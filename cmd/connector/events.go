@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/batch"
+	"github.com/A2AGateway/a2a-connector/internal/deadletter"
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+	"github.com/A2AGateway/a2a-connector/internal/metrics"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+	a2a "github.com/A2AGateway/a2a-protocol"
+)
+
+// Paths of the data listener
+const (
+	// rpcPath serves the A2A JSON-RPC task methods; they are also served on /
+	rpcPath = "/a2a"
+	// eventsPath streams the updates of tasks as server-sent events
+	eventsPath = "/events"
+	// agentCardPath serves the agent card
+	agentCardPath = "/.well-known/agent.json"
+)
+
+// writeTimeout is how long after the next job poll of an async task its
+// response may still be written; it matches the write timeout of the
+// listeners, which would otherwise cut off tasks that run longer
+const writeTimeout = 30 * time.Second
+
+// extendWriteDeadline lets the response of an async task be written until
+// writeTimeout after its next poll; writers that do not support deadlines
+// keep theirs
+func extendWriteDeadline(w http.ResponseWriter, p proxy.JobProgress) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(p.Interval + writeTimeout))
+}
+
+// exactPath serves only requests for the path of the pattern it is
+// registered with and answers 404 to the other paths under it, so unknown
+// paths do not reach the task pipeline
+func exactPath(path string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// eventStream writes JSON-RPC responses as server-sent events
+type eventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+	id interface{}
+}
+
+// newEventStream starts an event stream answering the request with id
+func newEventStream(w http.ResponseWriter, id interface{}) *eventStream {
+	w.Header().Set("Content-Type", proxy.ContentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return &eventStream{w: w, rc: http.NewResponseController(w), id: id}
+}
+
+// send writes an event with result and flushes it to the client
+func (s *eventStream) send(result interface{}) {
+	s.write(a2a.JSONRPCResponse{JSONRPC: a2a.JSONRPCVersion, ID: s.id, Result: result})
+}
+
+// progress sends a working status update with the progress of the job of an
+// async task
+func (s *eventStream) progress(taskID string, p proxy.JobProgress, catalog *i18n.Catalog, locale string) {
+	s.rc.SetWriteDeadline(time.Now().Add(p.Interval + writeTimeout))
+	text := p.Text
+	if text == "" {
+		text = catalog.T(locale, i18n.MsgJobRunning, p.JobID, p.Polls)
+	}
+	s.send(map[string]interface{}{
+		"id": taskID,
+		"status": map[string]interface{}{
+			"state":     a2a.TaskStateWorking,
+			"message":   map[string]interface{}{"role": "agent", "parts": []interface{}{map[string]interface{}{"type": "text", "text": text}}},
+			"timestamp": time.Now().UTC().Format(time.RFC3339Nano),
+		},
+		"final":    false,
+		"metadata": map[string]interface{}{"jobId": p.JobID, "polls": p.Polls},
+	})
+}
+
+// fail writes an error event
+func (s *eventStream) fail(rpcErr *a2a.JSONRPCError) {
+	s.write(a2a.JSONRPCResponse{JSONRPC: a2a.JSONRPCVersion, ID: s.id, Error: &a2a.JSONRPCError{
+		Code:    rpcErr.Code,
+		Message: secrets.Redact(rpcErr.Message),
+		Data:    secrets.RedactValue(rpcErr.Data),
+	}})
+}
+
+func (s *eventStream) write(resp a2a.JSONRPCResponse) {
+	data, _ := json.Marshal(resp)
+	fmt.Fprintf(s.w, "data: %s\n\n", data)
+	// Writers that cannot flush, such as the integrity middleware, send the
+	// events when the task is done
+	s.rc.Flush()
+}
+
+// handleTaskSubscribe runs a task like tasks/send and streams its progress: a
+// working status update when the task starts, an artifact update per
+// artifact of the result and a final status update
+func handleTaskSubscribe(ctx context.Context, w http.ResponseWriter, rpcReq a2a.JSONRPCRequest, transformer *proxy.Transformer, adptr adapter.Adapter, splitter *batch.Splitter, catalog *i18n.Catalog, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool, locale string) {
+	taskParams, _ := rpcReq.Params.(map[string]interface{})
+	taskID, _ := taskParams["id"].(string)
+
+	var chunks []map[string]interface{}
+	if taskParams != nil && splitter != nil {
+		var err error
+		if chunks, err = splitter.Split(taskParams); err != nil {
+			writeRPCError(w, rpcReq.ID, a2a.ErrCodeInvalidParams, catalog.T(locale, i18n.MsgInvalidParams), err.Error())
+			return
+		}
+	}
+
+	events := newEventStream(w, rpcReq.ID)
+	events.send(map[string]interface{}{
+		"id":     taskID,
+		"status": map[string]interface{}{"state": a2a.TaskStateWorking, "timestamp": time.Now().UTC().Format(time.RFC3339Nano)},
+		"final":  false,
+	})
+
+	// Async tasks stream the progress of their job after each poll
+	ctx = proxy.WithProgress(ctx, func(p proxy.JobProgress) {
+		events.progress(taskID, p, catalog, locale)
+	})
+	var task map[string]interface{}
+	if chunks != nil {
+		task = runBatch(ctx, rpcReq, splitter, chunks, transformer, adptr, catalog, taskMetrics, deadLetters, workers, locale)
+	} else {
+		result, rpcErr := executeTask(ctx, rpcReq.Params, transformer, adptr, catalog, taskMetrics, deadLetters, workers, locale)
+		if rpcErr != nil {
+			events.fail(rpcErr)
+			return
+		}
+		task, _ = result.(map[string]interface{})
+	}
+
+	artifacts, _ := task["artifacts"].([]interface{})
+	for _, artifact := range artifacts {
+		events.send(map[string]interface{}{"id": taskID, "artifact": artifact})
+	}
+	final := map[string]interface{}{"id": taskID, "status": task["status"], "final": true}
+	if metadata, ok := task["metadata"]; ok {
+		final["metadata"] = metadata
+	}
+	events.send(final)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// openAPICandidates are the well-known locations probed for an OpenAPI spec
+var openAPICandidates = []string{
+	"/openapi.json",
+	"/openapi.yaml",
+	"/swagger.json",
+	"/v3/api-docs",
+	"/api-docs",
+}
+
+// runInit implements `connector init`: an interactive wizard that probes the
+// legacy system, suggests mappings, and writes a validated starter config.
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	output := fs.String("output", "connector.yaml", "Path of the config file to write (.yaml, .yml, or .json)")
+	fs.Parse(args)
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+
+	fmt.Fprintln(p.out, "A2A Connector configuration wizard")
+	fmt.Fprintln(p.out, "Press Enter to accept the default shown in brackets.")
+	fmt.Fprintln(p.out)
+
+	cfg := &config.ConnectorConfig{}
+	cfg.Adapter.Name = p.ask("Connector name", "legacy-system")
+	cfg.Adapter.Type = p.choose("Legacy system type", []string{"rest", "soap", "db", "echo"}, "rest")
+
+	var suggestions []config.MappingConfig
+	var err error
+
+	switch cfg.Adapter.Type {
+	case "rest":
+		cfg.Adapter.BaseURL = p.ask("Base URL of the REST API", "http://localhost:8081")
+		suggestions, err = probeREST(p, cfg)
+	case "soap":
+		cfg.Adapter.BaseURL = p.ask("SOAP endpoint URL", "http://localhost:8081/service")
+		suggestions, err = probeSOAP(p, cfg)
+	case "db":
+		suggestions, err = probeDB(p, cfg)
+	case "echo":
+		fmt.Fprintln(p.out, "The echo adapter returns each call's params; switch to the real system once the pipeline works.")
+	}
+	if err != nil {
+		fmt.Fprintf(p.out, "Probe failed: %v\n", err)
+	}
+
+	// Let the user pick from the suggested mappings
+	if len(suggestions) > 0 {
+		fmt.Fprintf(p.out, "\nFound %d candidate mappings.\n", len(suggestions))
+		acceptAll := p.confirm("Accept all suggested mappings", true)
+		for _, mapping := range suggestions {
+			if acceptAll || p.confirm(fmt.Sprintf("Add mapping %q -> %s %s", mapping.IntentPattern, mapping.Method, mapping.Endpoint), true) {
+				cfg.Mappings = append(cfg.Mappings, mapping)
+			}
+		}
+	}
+
+	// Always end up with at least one mapping so the config validates
+	if len(cfg.Mappings) == 0 {
+		fmt.Fprintln(p.out, "\nNo mappings selected; let's add one manually.")
+		cfg.Mappings = append(cfg.Mappings, config.MappingConfig{
+			IntentPattern: p.ask("Intent pattern (regular expression)", "get.*customer"),
+			Endpoint:      p.ask("Endpoint", "/api/customers/{id}"),
+			Method:        p.ask("Method", "GET"),
+		})
+	}
+
+	if err := cfg.Compile(); err != nil {
+		return fmt.Errorf("generated config does not compile: %w", err)
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		return fmt.Errorf("generated config is invalid: %w", err)
+	}
+
+	if _, err := os.Stat(*output); err == nil {
+		if !p.confirm(fmt.Sprintf("%s already exists. Overwrite", *output), false) {
+			return fmt.Errorf("aborted: %s already exists", *output)
+		}
+	}
+	if err := config.SaveToFile(cfg, *output); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.out, "\nWrote %s with %d mappings.\n", *output, len(cfg.Mappings))
+	fmt.Fprintf(p.out, "Start the connector with: connector -use-config -config %s\n", *output)
+	return nil
+}
+
+// probeREST looks for an OpenAPI spec and suggests mappings from its operations
+func probeREST(p *prompter, cfg *config.ConnectorConfig) ([]config.MappingConfig, error) {
+	specURL := ""
+	client := &http.Client{Timeout: 10 * time.Second}
+	base := strings.TrimSuffix(cfg.Adapter.BaseURL, "/")
+
+	fmt.Fprintln(p.out, "Probing for an OpenAPI spec...")
+	for _, candidate := range openAPICandidates {
+		resp, err := client.Get(base + candidate)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			specURL = base + candidate
+			break
+		}
+	}
+
+	if specURL == "" {
+		specURL = p.ask("No spec found. OpenAPI spec URL or file (leave empty to skip)", "")
+		if specURL == "" {
+			return nil, nil
+		}
+	} else {
+		fmt.Fprintf(p.out, "Found OpenAPI spec at %s\n", specURL)
+	}
+
+	spec, err := adapter.LoadOpenAPISpec(specURL)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Adapter.OpenAPI = specURL
+
+	return adapter.GenerateMappings(spec), nil
+}
+
+// probeSOAP fetches the WSDL and suggests one mapping per operation
+func probeSOAP(p *prompter, cfg *config.ConnectorConfig) ([]config.MappingConfig, error) {
+	wsdlURL := p.ask("WSDL URL", cfg.Adapter.BaseURL+"?wsdl")
+	cfg.Adapter.WSDL = wsdlURL
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(wsdlURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetching WSDL returned HTTP %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	wsdl, err := adapter.ParseWSDL(data)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(p.out, "Found %d operations in namespace %s\n", len(wsdl.Operations), wsdl.TargetNamespace)
+
+	var mappings []config.MappingConfig
+	for _, op := range wsdl.Operations {
+		mappings = append(mappings, config.MappingConfig{
+			IntentPattern: adapter.IntentPattern(op),
+			Endpoint:      cfg.Adapter.BaseURL,
+			Method:        op,
+		})
+	}
+	return mappings, nil
+}
+
+// probeDB lists tables through the database adapter and suggests a query mapping per table
+func probeDB(p *prompter, cfg *config.ConnectorConfig) ([]config.MappingConfig, error) {
+	driver := p.ask("Database driver", "postgres")
+	dsn := p.ask("Data source name", "")
+	prefix := p.ask("Table prefix to include (empty for all)", "")
+	cfg.Adapter.Driver = driver
+	cfg.Adapter.DSN = dsn
+
+	db := adapter.NewDBAdapter(cfg.Adapter.Name, driver, dsn, prefix, nil)
+	if err := db.Initialize(); err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	caps, err := db.GetCapabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	tables, _ := caps["tables"].([]string)
+	fmt.Fprintf(p.out, "Found %d tables\n", len(tables))
+
+	var mappings []config.MappingConfig
+	for _, table := range tables {
+		generated, err := describeAndGenerate(db, table)
+		if err != nil {
+			fmt.Fprintf(p.out, "Skipping table %s: %v\n", table, err)
+			continue
+		}
+		mappings = append(mappings, generated...)
+	}
+	return mappings, nil
+}
+
+// prompter asks questions on the terminal
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prompts for a free-text answer
+func (p *prompter) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+
+	line, _ := p.in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return def
+	}
+	return line
+}
+
+// confirm prompts for a yes/no answer
+func (p *prompter) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	answer := strings.ToLower(p.ask(fmt.Sprintf("%s (%s)", question, hint), ""))
+	switch answer {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	default:
+		return def
+	}
+}
+
+// choose prompts until one of the options is selected
+func (p *prompter) choose(question string, options []string, def string) string {
+	for {
+		answer := strings.ToLower(p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), def))
+		for _, option := range options {
+			if answer == option {
+				return option
+			}
+		}
+		fmt.Fprintf(p.out, "Please choose one of: %s\n", strings.Join(options, ", "))
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+	a2a "github.com/A2AGateway/a2a-protocol"
+)
+
+// errCodeBusy is the JSON-RPC error code of tasks rejected because the worker
+// pool and its queue are full; the error data holds retryAfterSeconds
+const errCodeBusy = -32029
+
+// limitRequestBody rejects task requests with a body larger than max bytes
+// with 413 and a JSON-RPC error, before authentication and integrity checks
+// read it; zero means no limit. Accepted bodies are buffered for the handlers.
+func limitRequestBody(max int64, catalog *i18n.Catalog, next http.Handler) http.Handler {
+	if max <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+		var maxBytes *http.MaxBytesError
+		if r.ContentLength <= max {
+			body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, max))
+			switch {
+			case err == nil:
+				r.Body = ioutil.NopCloser(bytes.NewReader(body))
+				next.ServeHTTP(w, r)
+				return
+			case !errors.As(err, &maxBytes):
+				writeRPCError(w, nil, a2a.ErrCodeParseError, catalog.T(locale, i18n.MsgReadBodyFailed), nil)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		writeRPCError(w, nil, a2a.ErrCodeInvalidRequest, catalog.T(locale, i18n.MsgBodyTooLarge), map[string]interface{}{"limit": max})
+	})
+}
+
+// writeBusy answers a task rejected by the worker pool with 503 and the
+// Retry-After header, ahead of its JSON-RPC error
+func writeBusy(w http.ResponseWriter, rpcErr *a2a.JSONRPCError) {
+	if details, ok := rpcErr.Data.(map[string]interface{}); ok {
+		if seconds, ok := details["retryAfterSeconds"].(float64); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(seconds)))
+		}
+	}
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
@@ -2,83 +2,251 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	a2a "github.com/A2AGateway/a2a-protocol"
+	"github.com/A2AGateway/a2a-connector/adapters/salesforce"
 	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/anonymize"
+	"github.com/A2AGateway/a2a-connector/internal/audit"
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
+	"github.com/A2AGateway/a2a-connector/internal/batch"
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+	"github.com/A2AGateway/a2a-connector/internal/codec"
 	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/daemon"
+	"github.com/A2AGateway/a2a-connector/internal/deadletter"
+	"github.com/A2AGateway/a2a-connector/internal/egress"
 	"github.com/A2AGateway/a2a-connector/internal/gateway"
+	"github.com/A2AGateway/a2a-connector/internal/health"
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+	"github.com/A2AGateway/a2a-connector/internal/integrity"
+	"github.com/A2AGateway/a2a-connector/internal/kube"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/metrics"
 	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+	"github.com/A2AGateway/a2a-connector/internal/security"
+	"github.com/A2AGateway/a2a-connector/internal/server"
+	"github.com/A2AGateway/a2a-connector/internal/tlsclient"
 )
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "init":
+			if err := runInit(os.Args[2:]); err != nil {
+				fatal("init failed", err)
+			}
+			return
+		case "generate":
+			if err := runGenerate(os.Args[2:]); err != nil {
+				fatal("generate failed", err)
+			}
+			return
+		case "soak":
+			if err := runSoak(os.Args[2:]); err != nil {
+				fatal("soak failed", err)
+			}
+			return
+		case "trace":
+			if err := runTrace(os.Args[2:]); err != nil {
+				fatal("trace failed", err)
+			}
+			return
+		case "test-mapping":
+			if err := runTestMapping(os.Args[2:]); err != nil {
+				fatal("test-mapping failed", err)
+			}
+			return
+		case "validate":
+			if err := runValidate(os.Args[2:]); err != nil {
+				fatal("validate failed", err)
+			}
+			return
+		case "check":
+			if err := runCheck(os.Args[2:]); err != nil {
+				fatal("check failed", err)
+			}
+			return
+		case "anonymize":
+			if err := runAnonymize(os.Args[2:]); err != nil {
+				fatal("anonymize failed", err)
+			}
+			return
+		case "config":
+			if err := runConfig(os.Args[2:]); err != nil {
+				fatal("config failed", err)
+			}
+			return
+		case "support-bundle":
+			if err := runSupportBundle(os.Args[2:]); err != nil {
+				fatal("support-bundle failed", err)
+			}
+			return
+		}
+	}
+
 	var (
-		saasEndpoint  = flag.String("saas-endpoint", "", "A2A Gateway base URL for registration (e.g. http://gateway:8080)")
-		connectorID   = flag.String("connector-id", "my-connector", "Unique connector ID registered with the gateway")
-		connectorHost = flag.String("connector-host", "http://localhost:8082", "Public URL of this connector (included in agent card)")
-		legacyBaseURL = flag.String("legacy-url", "http://localhost:8081", "Legacy system base URL")
-		connectorPort = flag.String("port", "8082", "Port this connector listens on")
-		configFile    = flag.String("config", "", "Path to YAML/JSON config file")
-		useConfig     = flag.Bool("use-config", false, "Use config file instead of flags")
+		saasEndpoint      = flag.String("saas-endpoint", "", "A2A Gateway base URL for registration (e.g. http://gateway:8080)")
+		connectorID       = flag.String("connector-id", "my-connector", "Unique connector ID registered with the gateway")
+		connectorHost     = flag.String("connector-host", "http://localhost:8082", "Public URL of this connector (included in agent card)")
+		heartbeatInterval = flag.Duration("heartbeat-interval", 30*time.Second, "Interval of heartbeats to the gateway of --saas-endpoint")
+		remoteConfig      = flag.Bool("remote-config", false, "Pull the config from the gateway of --saas-endpoint and cache it in --config")
+		remoteInterval    = flag.Duration("remote-config-interval", 30*time.Second, "Interval of config polls, or the longest time the gateway may hold one")
+		legacyBaseURL     = flag.String("legacy-url", "http://localhost:8081", "Legacy system base URL")
+		connectorPort     = flag.String("port", "8082", "Port this connector listens on")
+		configFile        = flag.String("config", "", "Path to YAML/JSON config file, or a directory of them")
+		useConfig         = flag.Bool("use-config", false, "Use config file instead of flags")
+		logLevel          = flag.String("log-level", "", "Log level: debug, info, warn or error (default info)")
+		logFormat         = flag.String("log-format", "", "Log format: console or json (default console)")
+		adminAddr         = flag.String("admin-addr", "", "Address of the admin API listener, e.g. 127.0.0.1:8083 (default: same as --port)")
+		metricsAddr       = flag.String("metrics-addr", "", "Address of the metrics and health listener, e.g. :9090 (default: same as --port)")
+		socketPath        = flag.String("socket", "", "Serve the data plane on this Unix domain socket instead of --port, for same-host sidecars")
 	)
 	flag.Parse()
 
-	log.Println("Starting A2A Connector...")
+	logFlags := config.LoggingConfig{Level: *logLevel, Format: *logFormat}
+	logger := setupLogging(logFlags, config.LoggingConfig{}, *connectorID, "")
+	logger.Info("starting A2A connector")
+
+	// systemd and the Windows service control manager supervise the connector
+	// on the servers next to legacy systems
+	supervisor, err := daemon.Start()
+	if err != nil {
+		fatal("failed to start service", err)
+	}
+
+	// --- remote config ---
+	// The gateway can serve the config, which is cached in the config file so
+	// the connector starts with the last version when the gateway is down
+	var gwClient *gateway.Client
+	if *saasEndpoint != "" {
+		gwClient = gateway.NewClient(*saasEndpoint, *connectorID, *connectorHost)
+	}
+	configVersion := ""
+	if *remoteConfig {
+		if gwClient == nil || *configFile == "" {
+			fatal("invalid flags", errors.New("--remote-config requires --saas-endpoint and --config"))
+		}
+		*useConfig = true
+		configVersion = pullConfig(gwClient, *configFile, logger)
+	}
 
 	// --- build adapter + transformer ---
 	var adptr adapter.Adapter
 	var transformer *proxy.Transformer
+	var catalog *i18n.Catalog
+	var auditLog *audit.Log
+	var unmatched *proxy.UnmatchedLog
+	var coverage *proxy.Coverage
+	var pendingTasks *proxy.TaskStore
+	var sessions *proxy.SessionStore
+	var metricsCfg config.MetricsConfig
+	var integrityCfg config.IntegrityConfig
+	var serverCfg config.ServerConfig
+	var readinessCfg config.ReadinessConfig
+	var kubeCfg config.KubernetesConfig
+	var securityCfg config.SecurityConfig
+	var secretsCfg config.SecretsConfig
+	var batchCfg *config.BatchConfig
+	var anonymizeCfg *config.AnonymizeConfig
+	var bodyLimitsCfg config.BodyLimitsConfig
+	var deadLetterCfg *config.DeadLetterConfig
+	var workersCfg *config.WorkersConfig
+	var schedulesCfg []config.ScheduleConfig
+	var webhooksCfg []config.WebhookConfig
+	var egressCfg *config.EgressConfig
 	var legacyURL string
 
 	if *useConfig && *configFile != "" {
 		cfg, err := config.LoadFromFile(*configFile)
 		if err != nil {
-			log.Fatalf("Failed to load config: %v", err)
+			fatal("failed to load config", err)
 		}
-		if err := config.ValidateConfig(cfg); err != nil {
-			log.Fatalf("Invalid config: %v", err)
+		logger = setupLogging(logFlags, cfg.Logging, *connectorID, cfg.Adapter.Name)
+
+		// Calls that may change the legacy system are audited across reloads
+		auditLog, err = audit.New(cfg.Audit)
+		if err != nil {
+			fatal("invalid audit config", err)
 		}
+		defer auditLog.Close()
 
-		headers := make(map[string]string)
-		for k, v := range cfg.Adapter.Headers {
-			headers[k] = v
+		adptr, err = newAdapterStack(cfg, auditLog)
+		if err != nil {
+			fatal("failed to initialize adapter", err)
 		}
-		restAdptr := adapter.NewRESTAdapter(cfg.Adapter.Name, cfg.Adapter.BaseURL, headers, nil)
-		if err := restAdptr.Initialize(); err != nil {
-			log.Fatalf("Failed to initialize adapter: %v", err)
+
+		if err := config.ValidateConfig(cfg); err != nil {
+			fatal("invalid config", err)
 		}
-		adptr = restAdptr
 
 		ct := proxy.NewConfigTransformer(cfg)
 		transformer = &ct.Transformer
+		catalog = ct.Messages
+		unmatched = ct.Unmatched
+		coverage = ct.Coverage
+		pendingTasks = ct.Tasks
+		sessions = ct.Sessions
+		metricsCfg = cfg.Metrics
+		integrityCfg = cfg.Integrity
+		serverCfg = cfg.Server
+		readinessCfg = cfg.Readiness
+		kubeCfg = cfg.Kubernetes
+		securityCfg = cfg.Security
+		secretsCfg = cfg.Secrets
+		batchCfg = cfg.Batch
+		anonymizeCfg = cfg.Anonymize
+		bodyLimitsCfg = cfg.BodyLimits
+		deadLetterCfg = cfg.DeadLetter
+		workersCfg = cfg.Workers
+		schedulesCfg = cfg.Schedules
+		webhooksCfg = cfg.Webhooks
+		egressCfg = cfg.Egress
 		legacyURL = cfg.Adapter.BaseURL
-		log.Println("Connecting to legacy system at:", legacyURL)
+		logger.Info("connecting to legacy system", "url", legacyURL)
 	} else {
 		headers := make(map[string]string)
 		restAdptr := adapter.NewRESTAdapter("Legacy REST", *legacyBaseURL, headers, nil)
 		if err := restAdptr.Initialize(); err != nil {
-			log.Fatalf("Failed to initialize adapter: %v", err)
+			fatal("failed to initialize adapter", err)
 		}
 		adptr = restAdptr
 
 		transformer = proxy.NewTransformer()
 		transformer.SetRequestTransform(defaultRequestTransform)
 		transformer.SetResponseTransform(defaultResponseTransform)
+		catalog = i18n.NewCatalog(i18n.DefaultLocale)
 		legacyURL = *legacyBaseURL
-		log.Println("Connecting to legacy system at:", legacyURL)
+		logger = logger.With(logging.KeyAdapter, restAdptr.Name)
+		logger.Info("connecting to legacy system", "url", legacyURL)
 	}
 
+	kubeSettings, err := kube.SettingsFromConfig(kubeCfg)
+	if err != nil {
+		fatal("invalid kubernetes config", err)
+	}
+	tasks := &liveStack{}
 	defer func() {
-		if err := adptr.Close(); err != nil {
-			log.Printf("Error closing adapter: %v", err)
+		if err := tasks.Adapter().Close(); err != nil {
+			logger.Error("failed to close adapter", logging.KeyError, err)
 		}
 	}()
 
@@ -89,145 +257,653 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	if *saasEndpoint != "" {
-		gwClient := gateway.NewClient(*saasEndpoint, *connectorID, *connectorHost)
+	if gwClient != nil {
 		if err := gwClient.Register(card); err != nil {
-			log.Printf("Warning: gateway registration failed: %v", err)
+			logger.Warn("gateway registration failed; retrying with the next heartbeat", logging.KeyError, err)
 		} else {
-			log.Printf("Registered connector %q with gateway at %s", *connectorID, *saasEndpoint)
+			logger.Info("registered connector with gateway", "gateway", *saasEndpoint)
 		}
-		gwClient.StartHeartbeat(ctx, 30*time.Second)
 	} else {
-		log.Println("Warning: --saas-endpoint not set; running standalone (not registered with gateway)")
+		logger.Warn("--saas-endpoint not set; running standalone (not registered with gateway)")
 	}
 
 	// --- HTTP routes ---
-	mux := http.NewServeMux()
+	// Task traffic, the admin API and metrics/health are separate planes that
+	// can listen on their own addresses with their own TLS and auth
+	if *socketPath != "" {
+		serverCfg.Data.Address = server.UnixScheme + *socketPath
+	}
+	if serverCfg.Data.Address == "" {
+		serverCfg.Data.Address = ":" + *connectorPort
+	}
+	if *adminAddr != "" {
+		serverCfg.Admin.Address = *adminAddr
+	}
+	if *metricsAddr != "" {
+		serverCfg.Metrics.Address = *metricsAddr
+	}
+	dataMux := http.NewServeMux()
+	adminMux := http.NewServeMux()
+	metricsMux := http.NewServeMux()
+	webhooksMux := http.NewServeMux()
 
 	// Health check — used by the A2A Gateway UI to verify the connector is reachable
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	metricsMux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"status": "healthy", "connector": *connectorID})
 	})
 
+	// Kubernetes probes: liveness checks the process, readiness probes the legacy backend
+	readiness, err := health.CheckerFromConfig(tasks.Ping, readinessCfg)
+	if err != nil {
+		fatal("invalid readiness config", err)
+	}
+	metricsMux.Handle("/healthz", health.LivenessHandler(*connectorID))
+	metricsMux.Handle("/readyz", readiness.ReadinessHandler())
+
 	// A2A discovery: gateway and other agents fetch this to learn what the connector can do
-	mux.HandleFunc("/.well-known/agent.json", func(w http.ResponseWriter, r *http.Request) {
+	dataMux.HandleFunc(agentCardPath, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(card)
 	})
 
-	// A2A JSON-RPC endpoint: gateway forwards tasks here
-	mux.HandleFunc("/", a2aHandler(transformer, adptr))
+	// Prometheus metrics
+	registry := metrics.NewRegistry()
+	taskMetrics := metrics.NewTaskMetrics(registry, metricsCfg)
+	metricsMux.Handle("/metrics", registry.Handler())
 
-	server := &http.Server{
-		Addr:         ":" + *connectorPort,
-		Handler:      mux,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
+	// Pod metadata from the downward API lets dashboards join metrics to pods
+	pod := kube.PodFromEnv()
+	registry.NewGaugeVec("connector_info", "Connector and pod the metrics come from", []string{"connector", "pod", "namespace", "node"}).
+		Set(1, *connectorID, pod.Name, pod.Namespace, pod.Node)
+
+	// Failed tasks are kept for replay when a dead-letter queue is configured
+	deadLetters, err := deadletter.New(deadLetterCfg)
+	if err != nil {
+		fatal("invalid deadLetter config", err)
 	}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// The worker pool bounds the tasks executing at once across reloads
+	workers, err := resilience.WorkerPoolFromConfig(workersCfg)
+	if err != nil {
+		fatal("invalid workers config", err)
+	}
 
-	go func() {
-		log.Printf("Connector listening on :%s", *connectorPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
+	// Schedules start tasks through the current config and push their results
+	// to the gateway
+	scheduler, err := newScheduler(schedulesCfg, tasks.Run, gwClient, logger)
+	if err != nil {
+		fatal("invalid schedules config", err)
+	}
+
+	// Admin API for config authors and operators
+	registerAdminRoutes(adminMux, unmatched, coverage, anonymize.New(anonymizeCfg), deadLetters, tasks.Run, scheduler)
+
+	// Optional checksums/signatures on task payloads exchanged with the SaaS
+	verifier, err := integrity.New(integrityCfg)
+	if err != nil {
+		fatal("invalid integrity config", err)
+	}
+
+	// Optional API key, HMAC or JWT authentication of callers; the agent card stays public
+	auth, err := security.New(securityCfg)
+	if err != nil {
+		fatal("invalid security config", err)
+	}
+
+	// Task requests over the size limit are rejected before they are read
+	maxRequestBody, _, err := bodyLimitsCfg.Sizes()
+	if err != nil {
+		fatal("invalid bodyLimits config", err)
+	}
+
+	// A2A JSON-RPC endpoint: gateway forwards tasks here, and subscribes to
+	// task updates on the events endpoint; other paths are not found
+	tasks.replace(&taskStack{
+		adapter: adptr,
+		handler: newTaskHandler(logger, maxRequestBody, auth, verifier, transformer, adptr, batch.FromConfig(batchCfg), catalog, taskMetrics, deadLetters, workers),
+		run:     runTask(transformer, adptr, catalog, taskMetrics, deadLetters, workers),
+	})
+	dataMux.Handle("/", exactPath("/", tasks))
+	dataMux.Handle(rpcPath, tasks)
+	dataMux.Handle(eventsPath, tasks)
+
+	// Legacy systems post events to webhooks, which forward them to the gateway
+	var webhookPaths []string
+	egressPolicy := egress.New(egressCfg)
+	var streams []*salesforce.Subscriber
+	for _, webhook := range webhooksCfg {
+		if webhook.Salesforce != nil {
+			stream, err := newSalesforceStream(webhook, egressPolicy, gwClient, logger)
+			if err != nil {
+				fatal("invalid webhooks config", err)
+			}
+			streams = append(streams, stream)
+			continue
+		}
+		webhooksMux.Handle(webhook.Path, webhookHandler(webhook, maxRequestBody, egressPolicy, gwClient, logger))
+		webhookPaths = append(webhookPaths, webhook.Path)
+	}
+	if len(webhookPaths)+len(streams) > 0 && gwClient == nil {
+		logger.Warn("webhooks configured without --saas-endpoint; their events are rejected")
+	}
+
+	build := func(path string) (*taskStack, error) {
+		return reloadConfig(path, logger, unmatched, coverage, pendingTasks, sessions, taskMetrics, deadLetters, workers, auditLog)
+	}
+	reload := func(reason string) {
+		if err := tasks.reload(*configFile, build); err != nil {
+			logger.Error("config reload failed; keeping current config", logging.KeyError, err)
+			return
 		}
+		logger.Info("config reloaded", "path", *configFile, "reason", reason)
+	}
+
+	// A mounted ConfigMap is updated in place; rebuild the adapter and mappings on change
+	if kubeCfg.Reload && *useConfig && *configFile != "" {
+		go kube.WatchFile(ctx, *configFile, kubeSettings.ReloadInterval, func() { reload("config file changed") })
+	}
+
+	// Config versions published on the gateway are applied like a reload
+	if *remoteConfig {
+		go gwClient.WatchConfig(ctx, configVersion, *remoteInterval, applyRemoteConfig(*configFile, build, tasks, logger))
+	}
+
+	// Rotated secrets are picked up by rebuilding the task stack with the new values
+	if len(secretsCfg.Refs) > 0 && secretsCfg.RefreshInterval != "" {
+		interval, _ := time.ParseDuration(secretsCfg.RefreshInterval)
+		resolver := secrets.NewResolver(secretsCfg.Options())
+		go resolver.Watch(ctx, secretsCfg.Refs, interval, func() { reload("secrets rotated") })
+	}
+
+	// Leader election keeps singleton work on one replica
+	electionDone := make(chan struct{})
+	var elector *kube.LeaderElector
+	if kubeCfg.LeaderElection != nil {
+		client, err := kube.InClusterClient()
+		if err != nil {
+			fatal("leader election requires running in a cluster", err)
+		}
+		elector, err = kube.NewLeaderElector(client, pod.Identity(), kubeCfg.LeaderElection)
+		if err != nil {
+			fatal("invalid leader election config", err)
+		}
+		leaderGauge := registry.NewGaugeVec("connector_leader", "Whether this replica holds the leader lease", []string{"lease"})
+		leaderGauge.Set(0, elector.LeaseName)
+		elector.OnChange = func(leader bool) {
+			if leader {
+				leaderGauge.Set(1, elector.LeaseName)
+				logger.Info("acquired leader lease", "lease", elector.LeaseName)
+			} else {
+				leaderGauge.Set(0, elector.LeaseName)
+				logger.Info("lost leader lease", "lease", elector.LeaseName)
+			}
+		}
+		go func() {
+			defer close(electionDone)
+			elector.Run(ctx)
+		}()
+	} else {
+		close(electionDone)
+	}
+
+	// Scheduled tasks run on the leader only, so each run happens once
+	scheduler.Active = elector.IsLeader
+	schedulesDone := make(chan struct{})
+	go func() {
+		defer close(schedulesDone)
+		scheduler.Run(ctx)
 	}()
+	if n := scheduler.Len(); n > 0 {
+		logger.Info("schedules started", "count", n)
+	}
+
+	// Salesforce streams are subscribed by the leader only, so each event is
+	// forwarded once
+	var streamsDone sync.WaitGroup
+	for _, stream := range streams {
+		stream.Active = elector.IsLeader
+		streamsDone.Add(1)
+		go func(stream *salesforce.Subscriber) {
+			defer streamsDone.Done()
+			if err := stream.Run(ctx); err != nil {
+				logger.Error("Salesforce stream stopped", logging.KeyError, err)
+			}
+		}(stream)
+	}
+
+	// Heartbeats tell the gateway the connector is alive, with its readiness
+	// and task counts
+	if gwClient != nil {
+		started := time.Now()
+		gwClient.Status = func(ctx context.Context) gateway.Status {
+			ready := readiness.Check(ctx)
+			return gateway.Status{
+				Ready:         ready.Ready,
+				Error:         ready.Error,
+				Leader:        elector.IsLeader(),
+				UptimeSeconds: int64(time.Since(started).Seconds()),
+				Tasks:         taskMetrics.Totals(),
+				Timestamp:     time.Now(),
+			}
+		}
+		gwClient.StartHeartbeat(ctx, *heartbeatInterval)
+	}
+
+	type plane struct {
+		name     string
+		cfg      config.ListenerConfig
+		handler  http.Handler
+		patterns []string
+	}
+	planes := []plane{
+		{"data", serverCfg.Data, dataMux, []string{"/"}},
+		{"admin", serverCfg.Admin, adminMux, []string{"/admin/"}},
+		{"metrics", serverCfg.Metrics, metricsMux, []string{"/health", "/healthz", "/readyz", "/metrics"}},
+		{"webhooks", serverCfg.Webhooks, webhooksMux, webhookPaths},
+	}
+	// Further listeners serve planes on another address with their own TLS and auth
+	for _, extra := range serverCfg.Listeners {
+		for _, name := range extra.Planes {
+			for _, p := range planes[:len(config.Planes)] {
+				if p.name == name {
+					planes = append(planes, plane{name, extra, p.handler, p.patterns})
+				}
+			}
+		}
+	}
+
+	listeners := server.NewGroup()
+	tlsConfigs := make(map[*config.TLSConfig]*tls.Config)
+	for _, p := range planes {
+		if len(p.patterns) == 0 {
+			continue
+		}
+		addr := p.cfg.Address
+		if addr == "" {
+			addr = serverCfg.Data.Address
+		}
+		// The planes of a listener in server.listeners share its TLS config
+		tlsConfig, ok := tlsConfigs[p.cfg.TLS]
+		if !ok {
+			tlsConfig, err = server.TLSConfig(p.cfg.TLS)
+			if err != nil {
+				fatal("invalid "+p.name+" TLS config", err)
+			}
+			tlsConfigs[p.cfg.TLS] = tlsConfig
+		}
+		mux, err := listeners.Mux(p.name, addr, tlsConfig)
+		if err != nil {
+			fatal("invalid server config", err)
+		}
+		// Admin users can sign in with the organisation's identity provider
+		handler := p.handler
+		if p.cfg.SSO != nil {
+			sso, err := security.NewSSO(p.cfg.SSO)
+			if err != nil {
+				fatal("invalid "+p.name+" SSO config", err)
+			}
+			handler = sso.Middleware(handler)
+		}
+		for _, pattern := range p.patterns {
+			mux.Handle(pattern, server.Authenticate(p.cfg.Auth, handler))
+		}
+	}
 
-	<-sigChan
-	log.Println("Shutting down...")
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	serveErrs := make(chan error, len(listeners.Listeners()))
+	if err := listeners.Start(serveErrs); err != nil {
+		fatal("failed to start listener", err)
+	}
+	addrs := make([]string, 0, len(listeners.Listeners()))
+	for _, l := range listeners.Listeners() {
+		logger.Info("connector listening", "address", l.Addr, "planes", strings.Join(l.Planes, ","), "tls", l.TLS != nil)
+		addrs = append(addrs, l.Addr)
+	}
+	supervisor.Ready("listening on " + strings.Join(addrs, ", "))
+	go supervisor.RunWatchdog(ctx)
+
+	// SIGTERM and a stop of the Windows service shut down the same way
+	var stopReason string
+	select {
+	case sig := <-sigChan:
+		stopReason = sig.String()
+	case <-supervisor.StopRequested():
+		stopReason = "service stop"
+	case err := <-serveErrs:
+		fatal("server error", err)
+	}
+
+	// Fail readiness first so endpoints drop this pod before listeners stop,
+	// and tell the gateway to stop routing tasks here
+	supervisor.Stopping()
+	readiness.SetDraining(true)
+	if gwClient != nil {
+		deregisterCtx, cancelDeregister := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := gwClient.Deregister(deregisterCtx); err != nil {
+			logger.Warn("gateway deregistration failed", logging.KeyError, err)
+		}
+		cancelDeregister()
+	}
+	logger.Info("shutting down", "reason", stopReason, "drainDelay", kubeSettings.DrainDelay)
+	time.Sleep(kubeSettings.DrainDelay)
 	cancel()
-	if err := server.Close(); err != nil {
-		log.Printf("Error stopping server: %v", err)
+	<-electionDone
+	<-schedulesDone
+	streamsDone.Wait()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), kubeSettings.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := listeners.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed to stop server", logging.KeyError, err)
 	}
-	log.Println("Connector stopped.")
+	logger.Info("connector stopped")
+	supervisor.Stopped()
 }
 
 // a2aHandler handles incoming A2A JSON-RPC requests from the gateway.
-func a2aHandler(transformer *proxy.Transformer, adptr adapter.Adapter) http.HandlerFunc {
+func a2aHandler(transformer *proxy.Transformer, adptr adapter.Adapter, splitter *batch.Splitter, catalog *i18n.Catalog, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			http.Error(w, catalog.T(locale, i18n.MsgMethodNotAllowed), http.StatusMethodNotAllowed)
 			return
 		}
 
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
-			writeRPCError(w, nil, a2a.ErrCodeParseError, "Failed to read request body", nil)
+			writeRPCError(w, nil, a2a.ErrCodeParseError, catalog.T(locale, i18n.MsgReadBodyFailed), nil)
 			return
 		}
 
 		var rpcReq a2a.JSONRPCRequest
 		if err := json.Unmarshal(body, &rpcReq); err != nil {
-			writeRPCError(w, nil, a2a.ErrCodeParseError, "Invalid JSON", nil)
+			writeRPCError(w, nil, a2a.ErrCodeParseError, catalog.T(locale, i18n.MsgInvalidJSON), nil)
 			return
 		}
 
+		// A locale in the task metadata takes precedence over the HTTP header
+		if params, ok := rpcReq.Params.(map[string]interface{}); ok {
+			if taskLocale := proxy.GetTaskLocale(params); taskLocale != "" {
+				locale = taskLocale
+			}
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 
-		switch rpcReq.Method {
-		case "tasks/send":
-			handleTaskSend(w, rpcReq, transformer, adptr)
+		switch {
+		case rpcReq.Method == "tasks/sendSubscribe" || rpcReq.Method == "tasks/send" && r.URL.Path == eventsPath:
+			handleTaskSubscribe(r.Context(), w, rpcReq, transformer, adptr, splitter, catalog, taskMetrics, deadLetters, workers, locale)
+		case rpcReq.Method == "tasks/send":
+			handleTaskSend(r.Context(), w, rpcReq, transformer, adptr, splitter, catalog, taskMetrics, deadLetters, workers, locale)
 		default:
-			writeRPCError(w, rpcReq.ID, a2a.ErrCodeMethodNotFound, "Method not found", nil)
+			writeRPCError(w, rpcReq.ID, a2a.ErrCodeMethodNotFound, catalog.T(locale, i18n.MsgMethodNotFound), nil)
 		}
 	}
 }
 
-func handleTaskSend(w http.ResponseWriter, rpcReq a2a.JSONRPCRequest, transformer *proxy.Transformer, adptr adapter.Adapter) {
-	paramsBytes, err := json.Marshal(rpcReq.Params)
-	if err != nil {
-		writeRPCError(w, rpcReq.ID, a2a.ErrCodeInvalidParams, "Failed to parse params", nil)
+func handleTaskSend(ctx context.Context, w http.ResponseWriter, rpcReq a2a.JSONRPCRequest, transformer *proxy.Transformer, adptr adapter.Adapter, splitter *batch.Splitter, catalog *i18n.Catalog, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool, locale string) {
+	// A file of records fans out into a task per record or chunk of records
+	if taskParams, ok := rpcReq.Params.(map[string]interface{}); ok && splitter != nil {
+		chunks, err := splitter.Split(taskParams)
+		if err != nil {
+			writeRPCError(w, rpcReq.ID, a2a.ErrCodeInvalidParams, catalog.T(locale, i18n.MsgInvalidParams), err.Error())
+			return
+		}
+		if chunks != nil {
+			handleBatch(ctx, w, rpcReq, splitter, chunks, transformer, adptr, catalog, taskMetrics, deadLetters, workers, locale)
+			return
+		}
+	}
+
+	// Async tasks hold the response while their job runs
+	ctx = proxy.WithProgress(ctx, func(p proxy.JobProgress) { extendWriteDeadline(w, p) })
+	task, rpcErr := executeTask(ctx, rpcReq.Params, transformer, adptr, catalog, taskMetrics, deadLetters, workers, locale)
+	if rpcErr != nil {
+		if rpcErr.Code == errCodeBusy {
+			writeBusy(w, rpcErr)
+		}
+		writeRPCError(w, rpcReq.ID, rpcErr.Code, rpcErr.Message, rpcErr.Data)
 		return
 	}
+	json.NewEncoder(w).Encode(a2a.JSONRPCResponse{
+		JSONRPC: a2a.JSONRPCVersion,
+		ID:      rpcReq.ID,
+		Result:  task,
+	})
+}
+
+// executeTask runs a task through the request transform, the adapter and the
+// response transform, and returns the resulting A2A task
+func executeTask(ctx context.Context, taskParams interface{}, transformer *proxy.Transformer, adptr adapter.Adapter, catalog *i18n.Catalog, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool, locale string) (interface{}, *a2a.JSONRPCError) {
+	logger := logging.FromContext(ctx)
+	if taskParams, ok := taskParams.(map[string]interface{}); ok {
+		if taskID, ok := taskParams["id"].(string); ok {
+			logger = logger.With(logging.KeyTaskID, taskID)
+		}
+	}
+
+	paramsBytes, err := json.Marshal(taskParams)
+	if err != nil {
+		return nil, &a2a.JSONRPCError{Code: a2a.ErrCodeInvalidParams, Message: catalog.T(locale, i18n.MsgInvalidParams)}
+	}
 
 	// A2A task params → legacy request format
 	legacyData, err := transformer.TransformRequestData(paramsBytes)
 	if err != nil {
-		writeRPCError(w, rpcReq.ID, a2a.ErrCodeInternalError, "Request transform failed", err.Error())
-		return
+		logger.Warn("request transform failed", logging.KeyError, err)
+		// Unmatched tasks report the closest mappings instead of just the text
+		var noMatch *proxy.NoMatchError
+		if errors.As(err, &noMatch) {
+			return nil, &a2a.JSONRPCError{Code: a2a.ErrCodeInternalError, Message: catalog.T(locale, i18n.MsgRequestTransform), Data: map[string]interface{}{
+				"error":   err.Error(),
+				"details": noMatch.Details(),
+			}}
+		}
+		recordDeadLetter(ctx, deadLetters, deadletter.StageRequestTransform, paramsBytes, nil, "", err)
+		return nil, &a2a.JSONRPCError{Code: a2a.ErrCodeInternalError, Message: catalog.T(locale, i18n.MsgRequestTransform), Data: err.Error()}
 	}
 
 	var legacyReq map[string]interface{}
 	if err := json.Unmarshal(legacyData, &legacyReq); err != nil {
-		writeRPCError(w, rpcReq.ID, a2a.ErrCodeInternalError, "Bad legacy request format", err.Error())
-		return
+		return nil, &a2a.JSONRPCError{Code: a2a.ErrCodeInternalError, Message: catalog.T(locale, i18n.MsgBadLegacyRequest), Data: err.Error()}
 	}
 
 	action, _ := legacyReq["action"].(string)
 	params, _ := legacyReq["params"].(map[string]interface{})
-	result, execErr := adptr.ExecuteTask(action, params)
+	meta, _ := legacyReq["meta"].(map[string]interface{})
+	mappingID, _ := meta["mappingId"].(string)
+	logger = logger.With(logging.KeyMappingID, mappingID)
+	ctx = logging.WithContext(ctx, logger)
+	taskID, _ := meta["taskId"].(string)
+	endpoint, _ := meta["endpoint"].(string)
+	ctx = audit.WithTask(ctx, audit.Task{ID: taskID, MappingID: mappingID, Endpoint: endpoint})
+
+	// Mappings and the actions they call may require scopes and roles the
+	// authenticated caller must hold
+	decision := security.AuthorizeTask(ctx, security.Requirement{
+		Mapping: mappingID,
+		Scopes:  metaStrings(meta, "scopes"),
+		Roles:   metaStrings(meta, "roles"),
+		Actions: metaStrings(meta, "actions"),
+	})
+	if !decision.Allowed {
+		logger.Warn("task denied", decision.LogArgs()...)
+		return nil, &a2a.JSONRPCError{Code: a2a.ErrCodeInvalidRequest, Message: catalog.T(locale, i18n.MsgForbidden), Data: decision.Reason}
+	}
+	if decision.Log {
+		logger.Info("task authorized", decision.LogArgs()...)
+	}
+
+	// Configured task metadata keys become metric labels and audit fields
+	var taskMetadata map[string]interface{}
+	if taskParams, ok := taskParams.(map[string]interface{}); ok {
+		taskMetadata, _ = taskParams["metadata"].(map[string]interface{})
+	}
+	labels := taskMetrics.Labels.Values(taskMetadata)
+	fields := taskMetrics.Labels.Fields(labels)
+	if meta != nil && len(fields) > 0 {
+		meta["labels"] = fields
+	}
 
+	// Mapping-level timeouts override the adapter defaults
+	if meta != nil {
+		if timeout, ok := meta["timeout"].(map[string]interface{}); ok {
+			connect, _ := timeout["connect"].(string)
+			read, _ := timeout["read"].(string)
+			total, _ := timeout["total"].(string)
+			timeouts, err := adapter.TimeoutsFromConfig(config.TimeoutConfig{Connect: connect, Read: read, Total: total})
+			if err != nil {
+				return nil, &a2a.JSONRPCError{Code: a2a.ErrCodeInternalError, Message: catalog.T(locale, i18n.MsgBadLegacyRequest), Data: err.Error()}
+			}
+			ctx = adapter.WithTimeouts(ctx, timeouts)
+		}
+		if entry, ok := meta["cache"].(map[string]interface{}); ok {
+			key, _ := entry["key"].(string)
+			ttl, _ := entry["ttl"].(string)
+			if d, err := time.ParseDuration(ttl); err == nil {
+				ctx = adapter.WithCacheEntry(ctx, adapter.CacheEntry{Key: key, TTL: d})
+			}
+		}
+		if key, ok := meta["conditional"].(string); ok {
+			ctx = adapter.WithConditionalKey(ctx, key)
+		}
+		if name, ok := meta["charset"].(string); ok {
+			ctx = adapter.WithCharset(ctx, name)
+		}
+		if format, ok := meta["responseFormat"].(string); ok {
+			ctx = adapter.WithResponseFormat(ctx, format)
+		}
+		if queue, ok := meta["queue"].(map[string]interface{}); ok {
+			var q resilience.Queueing
+			if priority, ok := queue["priority"].(float64); ok {
+				q.Priority = int(priority)
+			}
+			if expiresAt, ok := queue["expiresAt"].(string); ok {
+				q.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+			}
+			ctx = resilience.WithQueueing(ctx, q)
+		}
+	}
+
+	// Tasks wait for a worker of the pool, unless its queue is full too
+	release, err := workers.Acquire(ctx)
+	var busy *resilience.BusyError
+	if errors.As(err, &busy) {
+		logger.Warn("task rejected", logging.KeyError, err)
+		taskMetrics.Observe(mappingID, "busy", labels, 0)
+		return nil, &a2a.JSONRPCError{Code: errCodeBusy, Message: catalog.T(locale, i18n.MsgBusy), Data: busy.Details()}
+	}
+	if err == nil {
+		defer release()
+	}
+
+	// Tasks that expired before they reached the connector, or while they
+	// waited for a worker, are not executed
+	start := time.Now()
+	var result map[string]interface{}
+	var execErr error
+	if err != nil {
+		execErr = err
+	} else if q, ok := resilience.QueueingFromContext(ctx); ok && !q.ExpiresAt.IsZero() && !start.Before(q.ExpiresAt) {
+		execErr = &resilience.ExpiredError{ExpiresAt: q.ExpiresAt}
+	} else if proxy.AwaitsInput(meta) {
+		// The mapping asked the agent for a missing param; the legacy system
+		// is called when the task is resumed with the answer
+	} else if proxy.HasSteps(meta) || proxy.HasFanOut(meta) || proxy.HasAsync(meta) {
+		// Multi-step and fan-out mappings make a legacy call per step or branch
+		// and combine the results; async mappings poll the job their call starts
+		exec := func(ctx context.Context, call proxy.StepCall) (map[string]interface{}, error) {
+			logger.Debug("running step", "step", call.Name, "action", call.Action, "endpoint", call.Endpoint)
+			ctx = audit.WithEndpoint(ctx, call.Endpoint)
+			if call.Timeout > 0 {
+				ctx = adapter.WithTimeouts(ctx, adapter.Timeouts{Total: call.Timeout})
+			}
+			return adapter.ExecuteTaskContext(ctx, adptr, call.Action, call.Params)
+		}
+		switch {
+		case proxy.HasAsync(meta):
+			result, execErr = proxy.RunAsync(ctx, meta, action, params, exec)
+		case proxy.HasFanOut(meta):
+			result, execErr = proxy.RunFanOut(ctx, meta, params, exec)
+		default:
+			result, execErr = proxy.RunSteps(ctx, meta, params, exec)
+		}
+	} else {
+		result, execErr = adapter.ExecuteTaskContext(ctx, adptr, action, params)
+	}
+	duration := time.Since(start)
+
+	// Response templates can also use the params and the task metadata
 	legacyResp := map[string]interface{}{
-		"result": result,
-		"meta":   legacyReq["meta"],
+		"result":   result,
+		"meta":     legacyReq["meta"],
+		"params":   params,
+		"metadata": taskMetadata,
 	}
 	if execErr != nil {
 		legacyResp["status"] = "error"
-		legacyResp["error"] = execErr.Error()
+		legacyResp["error"] = secrets.Redact(execErr.Error())
+
+		var detailed adapter.DetailedError
+		if errors.As(execErr, &detailed) {
+			legacyResp["errorDetails"] = secrets.RedactValue(detailed.Details())
+		}
+		var expired *resilience.ExpiredError
+		if errors.As(execErr, &expired) {
+			legacyResp["status"] = "expired"
+			legacyResp["error"] = catalog.T(locale, i18n.MsgExpired)
+		}
+	} else if proxy.AwaitsInput(meta) {
+		legacyResp["status"] = proxy.StatusInputRequired
 	} else {
 		legacyResp["status"] = "success"
 	}
 
+	status := legacyResp["status"].(string)
+	taskMetrics.Observe(mappingID, status, labels, duration)
+	logArgs := []any{"action", action, "status", status, logging.Latency(duration)}
+	for key, value := range fields {
+		logArgs = append(logArgs, key, value)
+	}
+	if execErr != nil {
+		logger.Warn("task failed", append(logArgs, logging.KeyError, execErr)...)
+		// Expired tasks are not dead-lettered; a replay would expire again
+		var expired *resilience.ExpiredError
+		if !errors.As(execErr, &expired) {
+			recordDeadLetter(ctx, deadLetters, deadletter.StageBackend, paramsBytes, legacyData, mappingID, execErr)
+		}
+	} else {
+		logger.Info("task completed", logArgs...)
+	}
+
 	legacyRespBytes, _ := json.Marshal(legacyResp)
 
 	// Legacy response → A2A task
 	a2aRespBytes, err := transformer.TransformResponseData(legacyRespBytes)
 	if err != nil {
-		writeRPCError(w, rpcReq.ID, a2a.ErrCodeInternalError, "Response transform failed", err.Error())
-		return
+		logger.Error("response transform failed", logging.KeyError, err)
+		recordDeadLetter(ctx, deadLetters, deadletter.StageResponseTransform, paramsBytes, legacyData, mappingID, err)
+		return nil, &a2a.JSONRPCError{Code: a2a.ErrCodeInternalError, Message: catalog.T(locale, i18n.MsgResponseTransform), Data: err.Error()}
 	}
 
 	var task interface{}
 	json.Unmarshal(a2aRespBytes, &task)
+	return task, nil
+}
 
-	json.NewEncoder(w).Encode(a2a.JSONRPCResponse{
-		JSONRPC: a2a.JSONRPCVersion,
-		ID:      rpcReq.ID,
-		Result:  task,
-	})
+// metaStrings returns the strings of a list in the legacy request meta
+func metaStrings(meta map[string]interface{}, key string) []string {
+	items, _ := meta[key].([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
 }
 
 func writeRPCError(w http.ResponseWriter, id interface{}, code int, msg string, data interface{}) {
@@ -235,10 +911,334 @@ func writeRPCError(w http.ResponseWriter, id interface{}, code int, msg string,
 	json.NewEncoder(w).Encode(a2a.JSONRPCResponse{
 		JSONRPC: a2a.JSONRPCVersion,
 		ID:      id,
-		Error:   &a2a.JSONRPCError{Code: code, Message: msg, Data: data},
+		Error:   &a2a.JSONRPCError{Code: code, Message: secrets.Redact(msg), Data: secrets.RedactValue(data)},
 	})
 }
 
+// appendGeneratedMappings adds generated mappings for operations that are not
+// already mapped explicitly and returns the number of mappings added.
+func appendGeneratedMappings(cfg *config.ConnectorConfig, generated []config.MappingConfig) int {
+	existing := make(map[string]bool)
+	for _, mapping := range cfg.Mappings {
+		existing[mapping.Method] = true
+	}
+
+	added := 0
+	for _, mapping := range generated {
+		if existing[mapping.Method] {
+			continue
+		}
+		cfg.Mappings = append(cfg.Mappings, mapping)
+		added++
+	}
+	return added
+}
+
+// setupLogging configures the default logger; flags take precedence over the config file
+func setupLogging(flags, cfg config.LoggingConfig, connectorID, adapterName string) *slog.Logger {
+	if flags.Level != "" {
+		cfg.Level = flags.Level
+	}
+	if flags.Format != "" {
+		cfg.Format = flags.Format
+	}
+
+	args := []any{logging.KeyConnectorID, connectorID}
+	if adapterName != "" {
+		args = append(args, logging.KeyAdapter, adapterName)
+	}
+	args = append(args, kube.PodFromEnv().LogAttrs()...)
+	logger, err := logging.Setup(cfg, args...)
+	if err != nil {
+		fatal("invalid logging config", err)
+	}
+	return logger
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.KeyError, err)
+	os.Exit(1)
+}
+
+// newAdapterStack creates the configured adapter wrapped in the audit log,
+// circuit breaker, limiter and response cache it is configured with; calls
+// are audited when they reach the adapter, not when the breaker or limiter
+// rejects them
+func newAdapterStack(cfg *config.ConnectorConfig, auditLog *audit.Log) (adapter.Adapter, error) {
+	adptr, err := newConfiguredAdapter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if auditLog != nil {
+		adptr = adapter.NewAuditedAdapter(adptr, cfg.Adapter.Name, auditLog)
+	}
+
+	breaker, err := resilience.CircuitBreakerFromConfig(cfg.Adapter.Name, cfg.CircuitBreaker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid circuitBreaker config: %w", err)
+	}
+	if breaker != nil {
+		adptr = adapter.NewCircuitBreakerAdapter(adptr, breaker)
+	}
+	limiter, err := resilience.LimiterFromConfig(cfg.Adapter.Name, cfg.Adapter.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter limits: %w", err)
+	}
+	if limiter != nil {
+		adptr = adapter.NewLimitedAdapter(adptr, limiter)
+	}
+	if cachingEnabled(cfg) {
+		responseCache, err := cache.New(cfg.Cache)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache config: %w", err)
+		}
+		adptr = adapter.NewCachingAdapter(adptr, responseCache)
+	}
+	return adptr, nil
+}
+
+// cachingEnabled reports whether any mapping caches its responses
+func cachingEnabled(cfg *config.ConnectorConfig) bool {
+	for _, mapping := range cfg.Mappings {
+		if mapping.Cache != nil && mapping.Cache.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// newConfiguredAdapter creates and initializes the adapter described by the config
+func newConfiguredAdapter(cfg *config.ConnectorConfig) (adapter.Adapter, error) {
+	retry, err := resilience.RetryPolicyFromConfig(cfg.Adapter.Retry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter retry: %w", err)
+	}
+	timeouts, err := adapter.TimeoutsFromConfig(cfg.Adapter.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter timeout: %w", err)
+	}
+	_, maxResponseBody, err := cfg.BodyLimits.Sizes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid bodyLimits: %w", err)
+	}
+
+	switch cfg.Adapter.Type {
+	case "db":
+		policy, err := adapter.SQLPolicyFromConfig(cfg.Adapter.SQLPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid adapter sqlPolicy: %w", err)
+		}
+		queryLimits, err := adapter.QueryLimitsFromConfig(cfg.Adapter.QueryLimits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid adapter queryLimits: %w", err)
+		}
+		dbAdptr := adapter.NewDBAdapter(cfg.Adapter.Name, cfg.Adapter.Driver, cfg.Adapter.DSN, "", nil)
+		dbAdptr.Retry = retry
+		dbAdptr.Timeouts = timeouts
+		dbAdptr.MaxLOBSize = maxResponseBody
+		dbAdptr.Policy = policy
+		dbAdptr.QueryLimits = queryLimits
+		if err := dbAdptr.Initialize(); err != nil {
+			return nil, err
+		}
+		return dbAdptr, nil
+	case "soap":
+		return newSOAPAdapter(cfg, retry, timeouts, maxResponseBody)
+	case "file":
+		fileAdptr := adapter.NewFileAdapter(cfg.Adapter.Name, cfg.Adapter.BasePath, nil)
+		if err := fileAdptr.Initialize(); err != nil {
+			return nil, err
+		}
+		return fileAdptr, nil
+	case "tcp":
+		return newTCPAdapter(cfg, timeouts)
+	case "telnet":
+		return newTelnetAdapter(cfg, timeouts)
+	case "echo":
+		return newEchoAdapter(cfg, timeouts)
+	case "plugin":
+		return newPluginAdapter(cfg, timeouts)
+	}
+
+	headers := make(map[string]string)
+	for k, v := range cfg.Adapter.Headers {
+		headers[k] = v
+	}
+	restAdptr := adapter.NewRESTAdapter(cfg.Adapter.Name, cfg.Adapter.BaseURL, headers, nil)
+	restAdptr.OpenAPISource = cfg.Adapter.OpenAPI
+	restAdptr.Retry = retry
+	restAdptr.Timeouts = timeouts
+	restAdptr.MaxResponseBody = maxResponseBody
+	restAdptr.Validators = cache.NewLRU(0)
+	restAdptr.Charset = cfg.Adapter.Charset
+	codecs, err := codec.FromConfig(cfg.Adapter.Codecs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter codecs: %w", err)
+	}
+	restAdptr.Codecs = codecs
+	tlsConfig, err := tlsclient.New(cfg.Adapter.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter tls: %w", err)
+	}
+	if err := restAdptr.SetTLSConfig(tlsConfig); err != nil {
+		return nil, err
+	}
+	authProvider, err := authclient.New(cfg.Adapter.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter auth: %w", err)
+	}
+	restAdptr.SetAuth(authProvider)
+	if len(cfg.Adapter.SuccessStatuses) > 0 {
+		ranges, err := adapter.ParseStatusRanges(cfg.Adapter.SuccessStatuses)
+		if err != nil {
+			return nil, fmt.Errorf("invalid adapter successStatuses: %w", err)
+		}
+		restAdptr.SuccessStatuses = ranges
+	}
+	if err := restAdptr.Initialize(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Adapter.GenerateMappings && restAdptr.Spec != nil {
+		added := appendGeneratedMappings(cfg, adapter.GenerateMappings(restAdptr.Spec))
+		if err := cfg.Compile(); err != nil {
+			return nil, fmt.Errorf("failed to compile generated mappings: %w", err)
+		}
+		slog.Info("generated mappings from OpenAPI spec", "count", added)
+	}
+
+	return restAdptr, nil
+}
+
+// newSOAPAdapter creates and initializes a soap adapter; the base URL is the
+// endpoint the envelopes are posted to
+func newSOAPAdapter(cfg *config.ConnectorConfig, retry *resilience.RetryPolicy, timeouts adapter.Timeouts, maxResponseBody int64) (adapter.Adapter, error) {
+	soapAdptr := adapter.NewSOAPAdapter(cfg.Adapter.Name, cfg.Adapter.WSDL, cfg.Adapter.BaseURL, cfg.Adapter.Namespace, nil)
+	soapAdptr.Retry = retry
+	soapAdptr.Timeouts = timeouts
+	soapAdptr.MaxResponseBody = maxResponseBody
+	soapAdptr.Charset = cfg.Adapter.Charset
+	tlsConfig, err := tlsclient.New(cfg.Adapter.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter tls: %w", err)
+	}
+	if err := soapAdptr.SetTLSConfig(tlsConfig); err != nil {
+		return nil, err
+	}
+	authProvider, err := authclient.New(cfg.Adapter.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter auth: %w", err)
+	}
+	soapAdptr.SetAuth(authProvider)
+	if err := soapAdptr.Initialize(); err != nil {
+		return nil, err
+	}
+	return soapAdptr, nil
+}
+
+// newTCPAdapter creates and initializes a tcp adapter
+func newTCPAdapter(cfg *config.ConnectorConfig, timeouts adapter.Timeouts) (adapter.Adapter, error) {
+	socket := cfg.Adapter.Socket
+	framing := adapter.Framing{
+		Mode:        socket.Framing,
+		LengthBytes: socket.LengthBytes,
+		Delimiter:   []byte(socket.Delimiter),
+		RecordSize:  socket.RecordSize,
+	}
+	tcpAdptr := adapter.NewTCPAdapter(cfg.Adapter.Name, socket.Address, framing, nil)
+	tcpAdptr.Timeouts = timeouts
+	tcpAdptr.Persistent = socket.Persistent
+	if socket.ContentType != "" {
+		codecs, err := codec.FromConfig(cfg.Adapter.Codecs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid adapter codecs: %w", err)
+		}
+		c, ok := codecs.Lookup(socket.ContentType)
+		if !ok {
+			return nil, fmt.Errorf("no codec for socket contentType %s", socket.ContentType)
+		}
+		tcpAdptr.Codec = c
+		if fixed, ok := tcpAdptr.Codec.(*codec.FixedWidth); ok && framing.Mode == adapter.FramingFixed && framing.RecordSize == 0 {
+			tcpAdptr.Framing.RecordSize = fixed.RecordLength()
+		}
+	}
+	if err := tcpAdptr.Initialize(); err != nil {
+		return nil, err
+	}
+	return tcpAdptr, nil
+}
+
+// newTelnetAdapter creates a telnet adapter with its compiled prompt and login
+func newTelnetAdapter(cfg *config.ConnectorConfig, timeouts adapter.Timeouts) (adapter.Adapter, error) {
+	telnet := cfg.Adapter.Telnet
+	prompt, err := regexp.Compile(telnet.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid telnet prompt: %w", err)
+	}
+	login, err := adapter.CompileTelnetSteps(telnet.Login)
+	if err != nil {
+		return nil, fmt.Errorf("invalid telnet login: %w", err)
+	}
+	telnetAdptr := adapter.NewTelnetAdapter(cfg.Adapter.Name, telnet.Address, prompt, nil)
+	telnetAdptr.Login = login
+	telnetAdptr.Logout = telnet.Logout
+	telnetAdptr.Timeouts = timeouts
+	if telnet.LineEnding != "" {
+		telnetAdptr.LineEnding = telnet.LineEnding
+	}
+	if err := telnetAdptr.Initialize(); err != nil {
+		return nil, err
+	}
+	return telnetAdptr, nil
+}
+
+// newEchoAdapter creates an echo adapter with its injected delay and errors
+func newEchoAdapter(cfg *config.ConnectorConfig, timeouts adapter.Timeouts) (adapter.Adapter, error) {
+	echoAdptr := adapter.NewEchoAdapter(cfg.Adapter.Name, nil)
+	echoAdptr.Timeouts = timeouts
+	if echo := cfg.Adapter.Echo; echo != nil {
+		if echo.Delay != "" {
+			delay, err := time.ParseDuration(echo.Delay)
+			if err != nil {
+				return nil, fmt.Errorf("invalid echo delay: %w", err)
+			}
+			echoAdptr.Delay = delay
+		}
+		echoAdptr.ErrorRate = echo.ErrorRate
+		echoAdptr.Error = echo.Error
+		echoAdptr.ErrorStatus = echo.ErrorStatus
+	}
+	if err := echoAdptr.Initialize(); err != nil {
+		return nil, err
+	}
+	return echoAdptr, nil
+}
+
+// newPluginAdapter starts the plugin named by adapter.plugin
+func newPluginAdapter(cfg *config.ConnectorConfig, timeouts adapter.Timeouts) (adapter.Adapter, error) {
+	plugin := cfg.Plugin(cfg.Adapter.Plugin)
+	if plugin == nil {
+		return nil, fmt.Errorf("adapter plugin %q is not defined in plugins", cfg.Adapter.Plugin)
+	}
+	pluginAdptr := adapter.NewPluginAdapter(cfg.Adapter.Name, plugin.Command, plugin.Args, plugin.Config)
+	pluginAdptr.Timeouts = timeouts
+	for name, value := range plugin.Env {
+		pluginAdptr.Env = append(pluginAdptr.Env, name+"="+value)
+	}
+	if plugin.StartTimeout != "" {
+		startTimeout, err := time.ParseDuration(plugin.StartTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plugin startTimeout: %w", err)
+		}
+		pluginAdptr.StartTimeout = startTimeout
+	}
+	if err := pluginAdptr.Initialize(); err != nil {
+		return nil, err
+	}
+	return pluginAdptr, nil
+}
+
 // buildAgentCard constructs the A2A agent card that describes this connector.
 func buildAgentCard(id, url string, adptr adapter.Adapter) *a2a.AgentCard {
 	caps, _ := adptr.GetCapabilities()
@@ -260,7 +1260,7 @@ func buildAgentCard(id, url string, adptr adapter.Adapter) *a2a.AgentCard {
 
 	card := a2a.NewAgentCard(
 		id, url, "1.0.0",
-		a2a.AgentCapabilities{Streaming: false, PushNotifications: false},
+		a2a.AgentCapabilities{Streaming: true, PushNotifications: false},
 		[]a2a.AgentSkill{skill},
 	)
 	card.WithDescription(desc)
@@ -333,6 +1333,9 @@ func defaultResponseTransform(data []byte) ([]byte, error) {
 	if errMsg, ok := resp["error"].(string); ok {
 		parts = append(parts, map[string]interface{}{"type": "text", "text": "Error: " + errMsg})
 	}
+	if details, ok := resp["errorDetails"].(map[string]interface{}); ok {
+		parts = append(parts, map[string]interface{}{"type": "data", "data": map[string]interface{}{"error": details}})
+	}
 
 	return json.Marshal(map[string]interface{}{
 		"id": taskID,
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/audit"
+	"github.com/A2AGateway/a2a-connector/internal/batch"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/deadletter"
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+	"github.com/A2AGateway/a2a-connector/internal/integrity"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/metrics"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/security"
+)
+
+// retireDelay is how long a replaced adapter stays open so in-flight tasks
+// finish; it matches the write timeout of the listeners
+const retireDelay = 30 * time.Second
+
+// taskStack is the adapter, task handler and task runner for dead-letter
+// replays and schedules built from one config version
+type taskStack struct {
+	adapter adapter.Adapter
+	handler http.Handler
+	run     deadletter.RunFunc
+}
+
+// liveStack serves tasks through the current stack, which a config reload replaces
+type liveStack struct {
+	current atomic.Pointer[taskStack]
+}
+
+// ServeHTTP serves the request with the current stack
+func (s *liveStack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.current.Load().handler.ServeHTTP(w, r)
+}
+
+// Ping checks the backend of the current adapter
+func (s *liveStack) Ping(ctx context.Context) error {
+	return adapter.Ping(ctx, s.current.Load().adapter)
+}
+
+// Run runs a task, such as a dead-lettered or scheduled one, through the
+// current stack
+func (s *liveStack) Run(ctx context.Context, task json.RawMessage) (interface{}, error) {
+	return s.current.Load().run(ctx, task)
+}
+
+// Adapter returns the current adapter
+func (s *liveStack) Adapter() adapter.Adapter {
+	return s.current.Load().adapter
+}
+
+// replace swaps in next and closes the previous adapter once in-flight tasks are done
+func (s *liveStack) replace(next *taskStack) {
+	prev := s.current.Swap(next)
+	if prev == nil {
+		return
+	}
+	time.AfterFunc(retireDelay, func() {
+		if err := prev.adapter.Close(); err != nil {
+			slog.Error("failed to close replaced adapter", logging.KeyError, err)
+		}
+	})
+}
+
+// reload builds a stack from the config file at path and swaps it in; the
+// current stack keeps serving when the build fails
+func (s *liveStack) reload(path string, build func(path string) (*taskStack, error)) error {
+	next, err := build(path)
+	if err != nil {
+		return err
+	}
+	s.replace(next)
+	return nil
+}
+
+// newTaskHandler builds the A2A JSON-RPC handler with logging, a request body limit, caller authentication and payload integrity
+func newTaskHandler(logger *slog.Logger, maxRequestBody int64, auth *security.Authenticator, verifier *integrity.Verifier, transformer *proxy.Transformer, adptr adapter.Adapter, splitter *batch.Splitter, catalog *i18n.Catalog, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool) http.Handler {
+	return logging.Middleware(logger, limitRequestBody(maxRequestBody, catalog, auth.Middleware(verifier.Middleware(a2aHandler(transformer, adptr, splitter, catalog, taskMetrics, deadLetters, workers)))))
+}
+
+// reloadConfig loads the config file again and builds a new task stack. The
+// unmatched intent log and the coverage counts are kept so the admin API
+// keeps their history, and the task store so tasks waiting for input can be
+// resumed, as are session variables, the dead-letter queue, the worker pool
+// and the audit log. Listener, metrics, logging, sessions, deadLetter,
+// workers, audit, schedules and webhooks settings only take effect on
+// restart.
+func reloadConfig(path string, logger *slog.Logger, unmatched *proxy.UnmatchedLog, coverage *proxy.Coverage, tasks *proxy.TaskStore, sessions *proxy.SessionStore, taskMetrics *metrics.TaskMetrics, deadLetters *deadletter.Queue, workers *resilience.WorkerPool, auditLog *audit.Log) (*taskStack, error) {
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// Mappings generated by the adapter are validated along with the configured ones
+	adptr, err := newAdapterStack(cfg, auditLog)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := integrity.New(cfg.Integrity)
+	var auth *security.Authenticator
+	if err == nil {
+		auth, err = security.New(cfg.Security)
+	}
+	if err == nil {
+		err = config.ValidateConfig(cfg)
+	}
+	var maxRequestBody int64
+	if err == nil {
+		maxRequestBody, _, err = cfg.BodyLimits.Sizes()
+	}
+	if err != nil {
+		adptr.Close()
+		return nil, err
+	}
+
+	ct := proxy.NewConfigTransformer(cfg)
+	if unmatched != nil {
+		ct.Unmatched = unmatched
+	}
+	if coverage != nil {
+		coverage.SetMappings(cfg.Mappings)
+		ct.Coverage = coverage
+	}
+	if tasks != nil {
+		ct.Tasks = tasks
+	}
+	if sessions != nil {
+		ct.Sessions = sessions
+	}
+	return &taskStack{
+		adapter: adptr,
+		handler: newTaskHandler(logger, maxRequestBody, auth, verifier, &ct.Transformer, adptr, batch.FromConfig(cfg.Batch), ct.Messages, taskMetrics, deadLetters, workers),
+		run:     runTask(&ct.Transformer, adptr, ct.Messages, taskMetrics, deadLetters, workers),
+	}, nil
+}
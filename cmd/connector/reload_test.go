@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/metrics"
+)
+
+// echoConfig is a config of the echo adapter with one mapping; echo holds
+// the settings of the adapter
+func echoConfig(echo string) string {
+	return "adapter:\n  type: echo\n  echo: " + echo + "\n" +
+		"mappings:\n  - intentPattern: list.*orders\n    endpoint: /orders\n    method: GET\n"
+}
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testBuild(path string) (*taskStack, error) {
+	taskMetrics := metrics.NewTaskMetrics(metrics.NewRegistry(), config.MetricsConfig{})
+	return reloadConfig(path, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, taskMetrics, nil, nil, nil)
+}
+
+func TestReloadInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "connector.yaml")
+	writeConfig(t, path, echoConfig("{}"))
+	tasks := &liveStack{}
+	if err := tasks.reload(path, testBuild); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	current := tasks.Adapter()
+
+	for name, data := range map[string]string{
+		"invalid YAML":    "adapter: [echo\n",
+		"invalid adapter": echoConfig("{delay: soon}"),
+		"invalid mapping": "adapter:\n  type: echo\nmappings:\n  - intentPattern: \"list.*(orders\"\n    endpoint: /orders\n",
+	} {
+		writeConfig(t, path, data)
+		if err := tasks.reload(path, testBuild); err == nil {
+			t.Errorf("Expected a config with an %s to fail the reload", name)
+		}
+		if tasks.Adapter() != current {
+			t.Errorf("Expected a config with an %s to keep the current stack", name)
+		}
+		if err := executeProbe(context.Background(), tasks, "list orders"); err != nil {
+			t.Errorf("Expected the current stack to keep serving after a config with an %s, got %v", name, err)
+		}
+	}
+}
+
+func TestReloadInFlight(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "connector.yaml")
+	writeConfig(t, path, echoConfig("{delay: 500ms}"))
+	tasks := &liveStack{}
+	if err := tasks.reload(path, testBuild); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+
+	inFlight := make(chan error, 1)
+	go func() { inFlight <- executeProbe(context.Background(), tasks, "list orders") }()
+	time.Sleep(100 * time.Millisecond)
+
+	select {
+	case err := <-inFlight:
+		t.Fatalf("Task finished before the reload: %v", err)
+	default:
+	}
+	writeConfig(t, path, echoConfig("{errorRate: 1, error: new config}"))
+	if err := tasks.reload(path, testBuild); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if err := executeProbe(context.Background(), tasks, "list orders"); err == nil || !strings.Contains(err.Error(), "new config") {
+		t.Errorf("Expected new tasks to run on the new stack, got %v", err)
+	}
+	select {
+	case err := <-inFlight:
+		if err != nil {
+			t.Errorf("Expected the task in flight to finish on the old stack, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Task in flight did not finish")
+	}
+}
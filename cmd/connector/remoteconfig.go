@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/gateway"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+)
+
+// remoteConfigTimeout limits fetching the config from the gateway on startup
+const remoteConfigTimeout = 10 * time.Second
+
+// pullConfig fetches the config from the gateway on startup and caches it in
+// the config file when it loads and validates. It returns the version fetched,
+// or "" when the gateway could not be reached and the cached config is used.
+func pullConfig(gwClient *gateway.Client, path string, logger *slog.Logger) string {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteConfigTimeout)
+	defer cancel()
+	remote, _, err := gwClient.FetchConfig(ctx, "", 0)
+	if err != nil {
+		logger.Warn("failed to fetch config from gateway; using cached config", "path", path, logging.KeyError, err)
+		return ""
+	}
+	if remote == nil {
+		return ""
+	}
+
+	status := gateway.ConfigStatus{Version: remote.Version, Applied: true, Timestamp: time.Now()}
+	if err := cacheConfig(path, remote.Data, checkConfig); err != nil {
+		logger.Error("config from gateway is invalid; using cached config", "version", remote.Version, logging.KeyError, err)
+		status.Applied = false
+		status.Error = err.Error()
+	} else {
+		logger.Info("fetched config from gateway", "version", remote.Version)
+	}
+	if err := gwClient.ReportConfigStatus(ctx, status); err != nil {
+		logger.Warn("failed to report config status to gateway", logging.KeyError, err)
+	}
+	return remote.Version
+}
+
+// checkConfig loads and validates a config file from the gateway. Mappings
+// generated by the adapter are not known before it starts, so their configs
+// are only loaded.
+func checkConfig(path string) error {
+	if err := checkRemoteConfig(path); err != nil {
+		return err
+	}
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		return err
+	}
+	if cfg.Adapter.GenerateMappings {
+		return nil
+	}
+	return config.ValidateConfig(cfg)
+}
+
+// checkRemoteConfig rejects a config file from the gateway that would run
+// commands or read files and environment variables of the connector host,
+// since anyone able to publish a config could then send them to an endpoint
+// of their choice. The file is loaded without resolving its secrets; plugins,
+// file: references, environment variables and includes from outside the
+// config directory are only allowed in local configs.
+func checkRemoteConfig(path string) error {
+	cfg, err := config.LoadOffline(path)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Plugins) > 0 || cfg.Adapter.Type == string(adapter.Plugin) {
+		return errors.New("configs from the gateway cannot define plugins, which run commands on the connector host")
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	for _, file := range cfg.Files() {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(dir, abs); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("configs from the gateway cannot include %s, which is outside the config directory", file)
+		}
+	}
+	for _, ref := range cfg.Secrets.Refs {
+		if strings.HasPrefix(ref, secrets.SchemeFile) {
+			return fmt.Errorf("configs from the gateway cannot reference files on the connector host: %s", ref)
+		}
+	}
+	for _, ref := range cfg.VariableReferences() {
+		if ref.Source == config.VariableSourceEnvironment {
+			return fmt.Errorf("configs from the gateway cannot reference environment variable %s (%s)", ref.Name, ref.Path)
+		}
+	}
+	return nil
+}
+
+// cacheConfig writes data to a file next to the config file, with the same
+// extension so it is parsed the same way, and replaces the config file with
+// it when check accepts it
+func cacheConfig(path string, data []byte, check func(path string) error) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("cannot cache the gateway config in config directory %s", path)
+	}
+	staged := filepath.Join(filepath.Dir(path), ".remote-"+filepath.Base(path))
+	if err := ioutil.WriteFile(staged, data, 0600); err != nil {
+		return fmt.Errorf("failed to stage config: %w", err)
+	}
+	if err := check(staged); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	if err := os.Rename(staged, path); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to cache config: %w", err)
+	}
+	return nil
+}
+
+// applyRemoteConfig returns the function that applies config versions from
+// the gateway: each is built into a task stack like a reload, and swapped in
+// once it is cached in the config file. Versions checkRemoteConfig rejects
+// fail before their secrets are resolved or anything is started. Versions
+// equal to the cached config are already applied.
+func applyRemoteConfig(path string, build func(path string) (*taskStack, error), tasks *liveStack, logger *slog.Logger) func(*gateway.RemoteConfig) error {
+	return func(remote *gateway.RemoteConfig) error {
+		if current, err := ioutil.ReadFile(path); err == nil && bytes.Equal(current, remote.Data) {
+			return nil
+		}
+		var next *taskStack
+		err := cacheConfig(path, remote.Data, func(staged string) error {
+			if err := checkRemoteConfig(staged); err != nil {
+				return err
+			}
+			var err error
+			next, err = build(staged)
+			return err
+		})
+		if err != nil {
+			if next != nil {
+				next.adapter.Close()
+			}
+			logger.Error("config from gateway is invalid; keeping current config", "version", remote.Version, logging.KeyError, err)
+			return err
+		}
+		tasks.replace(next)
+		logger.Info("applied config from gateway", "version", remote.Version)
+		return nil
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/gateway"
+)
+
+// configGateway serves a config version and records the status reports of
+// the connector
+type configGateway struct {
+	mu       sync.Mutex
+	version  string
+	data     string
+	statuses chan gateway.ConfigStatus
+}
+
+func (g *configGateway) publish(version, data string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.version, g.data = version, data
+}
+
+func (g *configGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v1/connectors/erp/config":
+		g.mu.Lock()
+		version, data := g.version, g.data
+		g.mu.Unlock()
+		if r.Header.Get("If-None-Match") == version {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", version)
+		io.WriteString(w, data)
+	case "/api/v1/connectors/erp/config/status":
+		var status gateway.ConfigStatus
+		json.NewDecoder(r.Body).Decode(&status)
+		g.statuses <- status
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (g *configGateway) status(t *testing.T) gateway.ConfigStatus {
+	t.Helper()
+	select {
+	case status := <-g.statuses:
+		return status
+	case <-time.After(5 * time.Second):
+		t.Fatal("No config status reported")
+		return gateway.ConfigStatus{}
+	}
+}
+
+// hostConfig is a config version that runs commands or reads files or
+// environment variables of the connector host, and the error it is rejected with
+type hostConfig struct {
+	name, data, err string
+}
+
+// hostConfigs returns a version whose plugin creates marker when it is
+// started, and versions that read a secret of the connector host
+func hostConfigs(t *testing.T, marker string) []hostConfig {
+	outside := t.TempDir()
+	writeConfig(t, filepath.Join(outside, "secret"), "s3cret")
+	writeConfig(t, filepath.Join(outside, "extra.yaml"), "variables:\n  region: eu\n")
+	t.Setenv("A2A_TEST_SECRET", "s3cret")
+	header := func(value string) string {
+		return "adapter:\n  type: echo\n  headers:\n    X-Secret: " + value + "\n"
+	}
+	return []hostConfig{
+		{"v3-plugin", "adapter:\n  type: plugin\n  plugin: shell\n" +
+			"plugins:\n  - name: shell\n    command: /bin/sh\n    args: [-c, 'touch " + marker + "']\n", "plugins"},
+		{"v3-file", header("file:" + filepath.Join(outside, "secret")), "files on the connector host"},
+		{"v3-env", header("${A2A_TEST_SECRET}"), "environment variable A2A_TEST_SECRET"},
+		{"v3-include", "include: [" + filepath.Join(outside, "extra.yaml") + "]\n" + echoConfig("{}"), "outside the config directory"},
+		{"v3-relative-include", "include: [../" + filepath.Base(outside) + "/extra.yaml]\n" + echoConfig("{}"), "outside the config directory"},
+	}
+}
+
+func TestApplyRemoteConfig(t *testing.T) {
+	cached := echoConfig("{}")
+	path := filepath.Join(t.TempDir(), "connector.yaml")
+	writeConfig(t, path, cached)
+	tasks := &liveStack{}
+	if err := tasks.reload(path, testBuild); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	current := tasks.Adapter()
+
+	gw := &configGateway{statuses: make(chan gateway.ConfigStatus, 4)}
+	gw.publish("v2", "adapter:\n  type: echo\n  echo: {delay: soon}\n")
+	server := httptest.NewServer(gw)
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	go gateway.NewClient(server.URL, "erp", "").WatchConfig(ctx, "", 10*time.Millisecond, applyRemoteConfig(path, testBuild, tasks, logger))
+
+	// A bad version is reported and leaves the running stack and the cache
+	status := gw.status(t)
+	if status.Version != "v2" || status.Applied || !strings.Contains(status.Error, "delay") {
+		t.Errorf("Expected the bad version to be reported as not applied, got %+v", status)
+	}
+	if tasks.Adapter() != current {
+		t.Error("Expected a bad version to keep the running stack")
+	}
+	if err := executeProbe(context.Background(), tasks, "list orders"); err != nil {
+		t.Errorf("Expected the running stack to keep serving, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != cached {
+		t.Errorf("Expected a bad version to keep the cached config, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), ".remote-connector.yaml")); !os.IsNotExist(err) {
+		t.Error("Expected the staged config to be removed")
+	}
+
+	// Plugins would run commands on the connector host, and the other
+	// versions read it
+	marker := filepath.Join(t.TempDir(), "started")
+	for _, version := range hostConfigs(t, marker) {
+		gw.publish(version.name, version.data)
+		if status := gw.status(t); status.Version != version.name || status.Applied || !strings.Contains(status.Error, version.err) {
+			t.Errorf("Expected a version with %s to be rejected, got %+v", version.err, status)
+		}
+		if tasks.Adapter() != current {
+			t.Errorf("Expected a version with %s to keep the running stack", version.err)
+		}
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("Expected the plugin of a remote config not to be started")
+	}
+
+	// A good version is cached and swapped in; it may include files of the
+	// config directory
+	writeConfig(t, filepath.Join(filepath.Dir(path), "extra.yaml"), "variables:\n  region: eu\n")
+	next := "include: [extra.yaml]\n" + echoConfig("{errorRate: 1, error: version 3}")
+	gw.publish("v4", next)
+	if status := gw.status(t); status.Version != "v4" || !status.Applied {
+		t.Fatalf("Expected the good version to be applied, got %+v", status)
+	}
+	if data, _ := os.ReadFile(path); string(data) != next {
+		t.Errorf("Expected the good version to be cached, got %q", data)
+	}
+	if err := executeProbe(context.Background(), tasks, "list orders"); err == nil || !strings.Contains(err.Error(), "version 3") {
+		t.Errorf("Expected tasks to run on the new stack, got %v", err)
+	}
+}
+
+func TestPullConfig(t *testing.T) {
+	cached := echoConfig("{}")
+	path := filepath.Join(t.TempDir(), "connector.yaml")
+	writeConfig(t, path, cached)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// An unreachable gateway falls back to the cached config
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	if version := pullConfig(gateway.NewClient(server.URL, "erp", ""), path, logger); version != "" {
+		t.Errorf("Expected no version from an unreachable gateway, got %q", version)
+	}
+	if data, _ := os.ReadFile(path); string(data) != cached {
+		t.Errorf("Expected the cached config to be kept, got %q", data)
+	}
+
+	// So does an invalid config, which is reported
+	gw := &configGateway{statuses: make(chan gateway.ConfigStatus, 4)}
+	gw.publish("v2", "adapter: [echo\n")
+	server = httptest.NewServer(gw)
+	defer server.Close()
+	if version := pullConfig(gateway.NewClient(server.URL, "erp", ""), path, logger); version != "v2" {
+		t.Errorf("Expected version v2, got %q", version)
+	}
+	if status := gw.status(t); status.Applied || status.Error == "" {
+		t.Errorf("Expected the invalid version to be reported as not applied, got %+v", status)
+	}
+	if data, _ := os.ReadFile(path); string(data) != cached {
+		t.Errorf("Expected the cached config to be kept, got %q", data)
+	}
+
+	// And configs that run commands or read the connector host
+	for _, version := range hostConfigs(t, filepath.Join(t.TempDir(), "started")) {
+		gw.publish(version.name, version.data)
+		pullConfig(gateway.NewClient(server.URL, "erp", ""), path, logger)
+		if status := gw.status(t); status.Applied || !strings.Contains(status.Error, version.err) {
+			t.Errorf("Expected the version with %s to be rejected, got %+v", version.err, status)
+		}
+		if data, _ := os.ReadFile(path); string(data) != cached {
+			t.Errorf("Expected the cached config to be kept, got %q", data)
+		}
+	}
+
+	// A valid config replaces the cache
+	gw.publish("v4", echoConfig("{delay: 10ms}"))
+	if version := pullConfig(gateway.NewClient(server.URL, "erp", ""), path, logger); version != "v4" {
+		t.Errorf("Expected version v4, got %q", version)
+	}
+	if status := gw.status(t); !status.Applied {
+		t.Errorf("Expected the valid version to be applied, got %+v", status)
+	}
+	if data, _ := os.ReadFile(path); string(data) != echoConfig("{delay: 10ms}") {
+		t.Errorf("Expected the valid version to be cached, got %q", data)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/deadletter"
+	"github.com/A2AGateway/a2a-connector/internal/gateway"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/schedule"
+)
+
+// newScheduler creates the scheduler of the configured schedules. Each run
+// starts a task through run and pushes the result to the gateway, or only
+// logs it when the connector runs standalone; failed tasks are dead-lettered
+// like those from the gateway.
+func newScheduler(schedules []config.ScheduleConfig, run deadletter.RunFunc, gwClient *gateway.Client, logger *slog.Logger) (*schedule.Scheduler, error) {
+	scheduler := schedule.New()
+	for _, sched := range schedules {
+		sched := sched
+		loc, err := time.LoadLocation(sched.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", sched.Name, err)
+		}
+		err = scheduler.Add(sched.Name, sched.Cron, loc, func(ctx context.Context, due time.Time) error {
+			task, err := json.Marshal(scheduledTask(sched, due))
+			if err != nil {
+				return err
+			}
+			result, runErr := run(ctx, task)
+			if runErr != nil {
+				logger.Warn("scheduled task failed", "schedule", sched.Name, logging.KeyError, runErr)
+			} else {
+				logger.Info("scheduled task completed", "schedule", sched.Name)
+			}
+			if gwClient != nil {
+				if err := gwClient.PushTask(ctx, "schedule:"+sched.Name, result); err != nil {
+					logger.Warn("failed to push scheduled task to gateway", "schedule", sched.Name, logging.KeyError, err)
+					if runErr == nil {
+						return err
+					}
+				}
+			}
+			return runErr
+		})
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", sched.Name, err)
+		}
+	}
+	return scheduler, nil
+}
+
+// scheduledTask returns the task params a schedule sends for the run due at
+// due; its ID names the schedule and the time
+func scheduledTask(sched config.ScheduleConfig, due time.Time) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	for key, value := range sched.Metadata {
+		metadata[key] = value
+	}
+	metadata["schedule"] = sched.Name
+	return map[string]interface{}{
+		"id": fmt.Sprintf("schedule-%s-%s", sched.Name, due.UTC().Format("20060102T150405Z")),
+		"status": map[string]interface{}{
+			"state": "submitted",
+			"message": map[string]interface{}{
+				"role":  "user",
+				"parts": []interface{}{map[string]interface{}{"type": "text", "text": sched.Text}},
+			},
+		},
+		"metadata": metadata,
+	}
+}
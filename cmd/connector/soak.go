@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/A2AGateway/a2a-connector/internal/audit"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/metrics"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/soak"
+)
+
+// runSoak implements `connector soak`: it executes the configured probe tasks
+// at a low rate for a long period and writes a certification report.
+func runSoak(args []string) error {
+	fs := flag.NewFlagSet("soak", flag.ExitOnError)
+	configFile := fs.String("config", "connector.yaml", "Path to YAML/JSON config file")
+	duration := fs.String("duration", "", "How long to run, overriding soak.duration (e.g. 72h)")
+	interval := fs.String("interval", "", "Time between probes, overriding soak.interval (e.g. 30s)")
+	reportFile := fs.String("report", "soak-report.json", "Path of the JSON certification report")
+	fs.Parse(args)
+
+	cfg, err := config.LoadFromFile(*configFile)
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		return err
+	}
+	if *duration != "" {
+		cfg.Soak.Duration = *duration
+	}
+	if *interval != "" {
+		cfg.Soak.Interval = *interval
+	}
+
+	logger := setupLogging(config.LoggingConfig{}, cfg.Logging, "soak", cfg.Adapter.Name)
+
+	// Probes are real calls, so they are audited like gateway traffic
+	auditLog, err := audit.New(cfg.Audit)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+	adptr, err := newAdapterStack(cfg, auditLog)
+	if err != nil {
+		return err
+	}
+	defer adptr.Close()
+
+	// Probes go through the same handler as gateway traffic
+	ct := proxy.NewConfigTransformer(cfg)
+	taskMetrics := metrics.NewTaskMetrics(metrics.NewRegistry(), cfg.Metrics)
+	handler := logging.Middleware(logger, a2aHandler(&ct.Transformer, adptr, nil, ct.Messages, taskMetrics, nil, nil))
+
+	runner, err := soak.NewRunner(cfg.Soak, func(ctx context.Context, text string) error {
+		return executeProbe(ctx, handler, text)
+	})
+	if err != nil {
+		return err
+	}
+	runner.Progress = func(w soak.WindowStats) {
+		logger.Info("soak window completed", "executions", w.Executions, "errors", w.Errors, "memoryBytes", w.MemoryBytes)
+	}
+
+	// Interrupting the run still produces a report for the elapsed period
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("starting soak run", "duration", runner.Duration, "interval", runner.Interval, "probes", len(runner.Probes))
+	report := runner.Run(ctx)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(*reportFile, data, 0644); err != nil {
+		return err
+	}
+	report.WriteText(os.Stdout)
+	fmt.Fprintf(os.Stdout, "Wrote %s\n", *reportFile)
+
+	if !report.Certified {
+		return fmt.Errorf("connector did not pass the soak test")
+	}
+	return nil
+}
+
+// executeProbe sends a probe task through handler and returns an error if the task failed
+func executeProbe(ctx context.Context, handler http.Handler, text string) error {
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "soak",
+		"method":  "tasks/send",
+		"params": map[string]interface{}{
+			"id": "soak-probe",
+			"status": map[string]interface{}{
+				"state": "submitted",
+				"message": map[string]interface{}{
+					"role":  "user",
+					"parts": []map[string]interface{}{{"type": "text", "text": text}},
+				},
+			},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp struct {
+		Result *struct {
+			Status struct {
+				State   string `json:"state"`
+				Message struct {
+					Parts []struct {
+						Text string `json:"text"`
+					} `json:"parts"`
+				} `json:"message"`
+			} `json:"status"`
+		} `json:"result"`
+		Error *struct {
+			Message string      `json:"message"`
+			Data    interface{} `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		return fmt.Errorf("invalid probe response: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s: %v", resp.Error.Message, resp.Error.Data)
+	}
+	if resp.Result == nil {
+		return fmt.Errorf("probe returned no task")
+	}
+	if resp.Result.Status.State == "failed" {
+		for _, part := range resp.Result.Status.Message.Parts {
+			if part.Text != "" {
+				return fmt.Errorf("probe task failed: %s", part.Text)
+			}
+		}
+		return fmt.Errorf("probe task failed")
+	}
+	slog.Debug("soak probe succeeded", "text", text)
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+	"github.com/A2AGateway/a2a-connector/internal/support"
+)
+
+// runSupportBundle implements `connector support-bundle`: it writes a
+// redacted archive of the config, version, host and network fingerprint,
+// adapter capabilities and recent errors to attach to support tickets.
+// Parts that cannot be collected are recorded in the archive rather than
+// failing the command.
+func runSupportBundle(args []string) error {
+	fs := flag.NewFlagSet("support-bundle", flag.ExitOnError)
+	configFile := fs.String("config", "connector.yaml", "Path to YAML/JSON config file")
+	outFile := fs.String("out", "", "Path of the archive (default: support-bundle-<time>.tar.gz)")
+	adminURL := fs.String("admin-url", "", "Admin API of the running connector to collect recent connection errors from, e.g. http://127.0.0.1:8083")
+	logFile := fs.String("log-file", "", "Connector log to collect recent warnings and errors from")
+	logLines := fs.Int("log-lines", support.DefaultLogLines, "Number of recent warnings and errors to collect from --log-file")
+	probe := fs.Bool("probe", false, "Resolve secrets, snapshot the adapter capabilities and probe the legacy endpoint")
+	timeout := fs.Duration("timeout", 10*time.Second, "Time limit of the probes and admin API calls")
+	fs.Parse(args)
+
+	if *outFile == "" {
+		*outFile = "support-bundle-" + time.Now().UTC().Format("20060102-150405") + ".tar.gz"
+	}
+	out, err := os.OpenFile(*outFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	bundle := support.NewBundle(out)
+
+	load := config.LoadOffline
+	if *probe {
+		load = config.LoadFromFile
+	}
+	cfg, err := load(*configFile)
+	if err == nil {
+		err = config.ValidateConfig(cfg)
+	}
+	configErr := err
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	environment := map[string]interface{}{"host": support.TakeFingerprint()}
+	if *probe && cfg != nil && cfg.Adapter.BaseURL != "" {
+		environment["legacy"] = support.Probe(ctx, cfg.Adapter.BaseURL)
+	}
+	type bundleFile struct {
+		name  string
+		value interface{}
+	}
+	files := []bundleFile{
+		{"version.json", support.BuildVersion()},
+		{"environment.json", environment},
+		{"config.json", configSummary(*configFile, cfg, configErr)},
+	}
+	if *probe && cfg != nil {
+		files = append(files, bundleFile{"capabilities.json", adapterSnapshot(ctx, cfg)})
+	}
+	if *adminURL != "" {
+		files = append(files, bundleFile{"errors.json", recentErrors(ctx, *adminURL, cfg)})
+	}
+	for _, file := range files {
+		if err := bundle.AddJSON(file.name, file.value); err != nil {
+			return err
+		}
+	}
+
+	if *logFile != "" {
+		lines, logErr := readLogErrors(*logFile, *logLines)
+		if logErr != nil {
+			lines = append(lines, fmt.Sprintf("failed to read %s: %v", *logFile, logErr))
+		}
+		if err := bundle.Add("logs.txt", []byte(strings.Join(lines, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	if err := bundle.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "wrote support bundle %s\n", *outFile)
+	return out.Close()
+}
+
+// configSummary describes the config of a support bundle. Credentials are
+// masked by the bundle; the DSN and anonymize seed are masked here since
+// their keys do not look like credentials.
+func configSummary(file string, cfg *config.ConnectorConfig, err error) map[string]interface{} {
+	summary := map[string]interface{}{"file": file, "valid": err == nil}
+	if err != nil {
+		summary["error"] = err.Error()
+	}
+	if cfg == nil {
+		return summary
+	}
+	summary["adapter"] = map[string]interface{}{"type": cfg.Adapter.Type, "name": cfg.Adapter.Name}
+	summary["mappings"] = len(cfg.Mappings)
+	summary["compiled"] = compiledSummary(cfg)
+
+	redacted := *cfg
+	if redacted.Adapter.DSN != "" {
+		redacted.Adapter.DSN = secrets.Mask
+	}
+	if redacted.Anonymize != nil && redacted.Anonymize.Seed != "" {
+		anonymize := *redacted.Anonymize
+		anonymize.Seed = secrets.Mask
+		redacted.Anonymize = &anonymize
+	}
+	summary["config"] = &redacted
+	return summary
+}
+
+// adapterSnapshot builds the adapter of cfg and returns its capabilities and
+// whether the legacy system answers a ping
+func adapterSnapshot(ctx context.Context, cfg *config.ConnectorConfig) map[string]interface{} {
+	snapshot := map[string]interface{}{}
+	adptr, err := newConfiguredAdapter(cfg)
+	if err != nil {
+		snapshot["error"] = err.Error()
+		return snapshot
+	}
+	defer adptr.Close()
+
+	if caps, err := adptr.GetCapabilities(); err != nil {
+		snapshot["capabilitiesError"] = err.Error()
+	} else {
+		snapshot["capabilities"] = caps
+	}
+	snapshot["ping"] = "ok"
+	if err := adapter.Ping(ctx, adptr); err != nil {
+		snapshot["ping"] = err.Error()
+	}
+	return snapshot
+}
+
+// recentErrors fetches the connection errors of a running connector from its
+// admin API, authenticating with the admin listener's credentials
+func recentErrors(ctx context.Context, adminURL string, cfg *config.ConnectorConfig) map[string]interface{} {
+	client := &http.Client{}
+	if cfg != nil {
+		if provider, err := authclient.New(cfg.Server.Admin.Auth); err == nil {
+			authclient.Apply(client, provider)
+		}
+	}
+
+	var events []adapter.ConnEvent
+	for _, kind := range []string{adapter.EventError, adapter.EventAuth} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(adminURL, "/")+"/admin/connections", nil)
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		q := req.URL.Query()
+		q.Set("kind", kind)
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		var body struct {
+			Events []adapter.ConnEvent `json:"events"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return map[string]interface{}{"error": "admin API returned " + resp.Status}
+		}
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		events = append(events, body.Events...)
+	}
+	return map[string]interface{}{"connections": events}
+}
+
+// readLogErrors returns the recent warnings and errors of a log file
+func readLogErrors(path string, max int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return support.RecentLogErrors(f, max)
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"gopkg.in/yaml.v3"
+)
+
+// mappingFixture is a sample task with the outcome a config must produce
+// for it, kept next to the config and run in CI
+type mappingFixture struct {
+	Name string `yaml:"name"`
+	// Text is a shorthand for a task with a single text part
+	Text string                 `yaml:"text"`
+	Task map[string]interface{} `yaml:"task"`
+	// Response is the simulated legacy result, an empty object by default
+	Response interface{}        `yaml:"response"`
+	Expect   fixtureExpectation `yaml:"expect"`
+}
+
+// fixtureExpectation lists what a fixture checks; empty fields are not checked
+type fixtureExpectation struct {
+	Unmatched bool                   `yaml:"unmatched"`
+	Mapping   string                 `yaml:"mapping"`
+	Params    map[string]interface{} `yaml:"params"`
+	Endpoint  string                 `yaml:"endpoint"`
+	State     string                 `yaml:"state"`
+	Text      string                 `yaml:"text"`
+}
+
+// mappingOutcome is what the trace of a task produced
+type mappingOutcome struct {
+	Mapping  string
+	Params   map[string]interface{}
+	Endpoint string
+	Request  interface{}
+	Task     map[string]interface{}
+}
+
+// runTestMapping implements `connector test-mapping`: it shows which mapping
+// a sample task matches and the legacy request it produces, renders a sample
+// legacy response, or checks a file of fixtures.
+func runTestMapping(args []string) error {
+	fs := flag.NewFlagSet("test-mapping", flag.ExitOnError)
+	configFile := fs.String("config", "connector.yaml", "Path to YAML/JSON config file")
+	taskFile := fs.String("task", "", "Path of a sample task or tasks/send request (JSON)")
+	responseFile := fs.String("response", "", "Path of a sample JSON legacy result to render")
+	mapping := fs.String("mapping", "", "Intent pattern of the mapping to render --response with, without a task")
+	fixturesFile := fs.String("fixtures", "", "Path of a YAML file of fixtures to check")
+	fs.Parse(args)
+
+	cfg, err := config.LoadOffline(*configFile)
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		return err
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	var result interface{}
+	if *responseFile != "" {
+		data, err := ioutil.ReadFile(*responseFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return fmt.Errorf("invalid response file: %w", err)
+		}
+	}
+
+	switch {
+	case *fixturesFile != "":
+		return runFixtures(os.Stdout, ct, *fixturesFile)
+	case *taskFile != "":
+		task, err := readTraceTask(*taskFile)
+		if err != nil {
+			return err
+		}
+		outcome, err := testMapping(ct, task, result)
+		writeOutcome(os.Stdout, outcome, *responseFile != "")
+		return err
+	case *mapping != "" && *responseFile != "":
+		task, err := ct.RenderResponse(*mapping, result)
+		if err != nil {
+			return err
+		}
+		data, _ := json.MarshalIndent(task, "", "  ")
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return nil
+	}
+	return fmt.Errorf("one of --task, --mapping with --response, or --fixtures is required")
+}
+
+// testMapping traces a task with a simulated legacy result and collects the
+// outcome of each stage; on error the outcome holds the stages that ran
+func testMapping(ct *proxy.ConfigTransformer, task []byte, result interface{}) (mappingOutcome, error) {
+	var outcome mappingOutcome
+	stages, err := ct.Trace(task, proxy.TraceOptions{Result: result})
+	for _, stage := range stages {
+		switch stage.Name {
+		case proxy.StageIntentMatch:
+			for _, score := range stage.Data.([]proxy.MappingScore) {
+				if score.Selected {
+					outcome.Mapping = score.IntentPattern
+				}
+			}
+		case proxy.StageParams:
+			outcome.Params, _ = stage.Data.(map[string]interface{})
+		case proxy.StageEndpoint:
+			outcome.Endpoint, _ = stage.Data.(string)
+		case proxy.StageRequestBody:
+			outcome.Request = stage.Data
+		case proxy.StageFinalTask:
+			outcome.Task, _ = stage.Data.(map[string]interface{})
+		}
+	}
+	return outcome, err
+}
+
+func writeOutcome(w io.Writer, outcome mappingOutcome, withTask bool) {
+	if outcome.Mapping == "" {
+		fmt.Fprintln(w, "mapping:  (none)")
+		return
+	}
+	params, _ := json.Marshal(outcome.Params)
+	request, _ := json.MarshalIndent(outcome.Request, "", "  ")
+	fmt.Fprintf(w, "mapping:  %s\nparams:   %s\nendpoint: %s\nrequest:  %s\n", outcome.Mapping, params, outcome.Endpoint, request)
+	if withTask && outcome.Task != nil {
+		task, _ := json.MarshalIndent(outcome.Task, "", "  ")
+		fmt.Fprintf(w, "task:     %s\n", task)
+	}
+}
+
+// runFixtures checks every fixture in a file and prints PASS or FAIL with
+// the reasons for each
+func runFixtures(w io.Writer, ct *proxy.ConfigTransformer, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fixtures []mappingFixture
+	if err := yaml.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("invalid fixtures file: %w", err)
+	}
+
+	failed := 0
+	for i, fixture := range fixtures {
+		name := fixture.Name
+		if name == "" {
+			name = fmt.Sprintf("fixture %d", i+1)
+		}
+		problems := checkFixture(ct, fixture)
+		if len(problems) == 0 {
+			fmt.Fprintf(w, "PASS  %s\n", name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "FAIL  %s\n", name)
+		for _, problem := range problems {
+			fmt.Fprintf(w, "      %s\n", problem)
+		}
+	}
+	fmt.Fprintf(w, "%d passed, %d failed\n", len(fixtures)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures failed", failed, len(fixtures))
+	}
+	return nil
+}
+
+// checkFixture runs a fixture and returns how its outcome differs from the
+// expectation
+func checkFixture(ct *proxy.ConfigTransformer, fixture mappingFixture) []string {
+	task := fixture.Task
+	if task == nil {
+		task = map[string]interface{}{
+			"id": "fixture",
+			"status": map[string]interface{}{
+				"state":   "submitted",
+				"message": map[string]interface{}{"role": "user", "parts": []interface{}{map[string]interface{}{"type": "text", "text": fixture.Text}}},
+			},
+		}
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid task: %v", err)}
+	}
+	outcome, err := testMapping(ct, data, fixture.Response)
+
+	expect := fixture.Expect
+	if expect.Unmatched || outcome.Mapping == "" {
+		if expect.Unmatched != (outcome.Mapping == "") {
+			return []string{fmt.Sprintf("expected no mapping to match, matched %q", outcome.Mapping)}
+		}
+		if expect.Unmatched {
+			return nil
+		}
+		return []string{fmt.Sprintf("no mapping matched: %v", err)}
+	}
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	if expect.Mapping != "" && outcome.Mapping != expect.Mapping {
+		problems = append(problems, fmt.Sprintf("mapping: expected %q, got %q", expect.Mapping, outcome.Mapping))
+	}
+	// Params are compared as text, as YAML and extraction may type them differently
+	for key, want := range expect.Params {
+		if got, ok := outcome.Params[key]; !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			problems = append(problems, fmt.Sprintf("params.%s: expected %v, got %v", key, want, got))
+		}
+	}
+	if expect.Endpoint != "" && outcome.Endpoint != expect.Endpoint {
+		problems = append(problems, fmt.Sprintf("endpoint: expected %q, got %q", expect.Endpoint, outcome.Endpoint))
+	}
+	status, _ := outcome.Task["status"].(map[string]interface{})
+	if state, _ := status["state"].(string); expect.State != "" && state != expect.State {
+		problems = append(problems, fmt.Sprintf("state: expected %q, got %q", expect.State, state))
+	}
+	if text := taskText(status); expect.Text != "" && !strings.Contains(text, expect.Text) {
+		problems = append(problems, fmt.Sprintf("text: expected it to contain %q, got %q", expect.Text, text))
+	}
+	return problems
+}
+
+// taskText joins the text parts of a task status message
+func taskText(status map[string]interface{}) string {
+	message, _ := status["message"].(map[string]interface{})
+	parts, _ := message["parts"].([]interface{})
+	var texts []string
+	for _, part := range parts {
+		if text, ok := part.(map[string]interface{})["text"].(string); ok {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
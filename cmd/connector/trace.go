@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/audit"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+// runTrace implements `connector trace`: it runs a sample task through each
+// transformation stage and prints their output, to debug a mapping.
+func runTrace(args []string) error {
+	fs := flag.NewFlagSet("trace", flag.ExitOnError)
+	taskFile := fs.String("task", "", "Path of a sample task or tasks/send request (JSON)")
+	configFile := fs.String("config", "connector.yaml", "Path to YAML/JSON config file")
+	responseFile := fs.String("response", "", "Path of a JSON legacy result to simulate (default: empty object)")
+	live := fs.Bool("live", false, "Call the legacy system instead of simulating its response")
+	verbose := fs.Bool("verbose", false, "Print the full output of each stage")
+	fs.Parse(args)
+
+	if *taskFile == "" {
+		return fmt.Errorf("--task is required")
+	}
+	task, err := readTraceTask(*taskFile)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromFile(*configFile)
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		return err
+	}
+
+	var opts proxy.TraceOptions
+	if *live {
+		auditLog, err := audit.New(cfg.Audit)
+		if err != nil {
+			return err
+		}
+		defer auditLog.Close()
+		adptr, err := newAdapterStack(cfg, auditLog)
+		if err != nil {
+			return err
+		}
+		defer adptr.Close()
+		opts.Execute = func(action string, params map[string]interface{}) (interface{}, error) {
+			return adapter.ExecuteTaskContext(context.Background(), adptr, action, params)
+		}
+	} else if *responseFile != "" {
+		data, err := ioutil.ReadFile(*responseFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &opts.Result); err != nil {
+			return fmt.Errorf("invalid response file: %w", err)
+		}
+	}
+
+	stages, traceErr := proxy.NewConfigTransformer(cfg).Trace(task, opts)
+	writeTrace(os.Stdout, stages, *verbose)
+	if traceErr != nil {
+		return fmt.Errorf("stage %d failed: %w", len(stages)+1, traceErr)
+	}
+	return nil
+}
+
+// readTraceTask reads a task, unwrapping the params of a tasks/send request
+func readTraceTask(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var request struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("invalid task file: %w", err)
+	}
+	if request.Method != "" && len(request.Params) > 0 {
+		return request.Params, nil
+	}
+	return data, nil
+}
+
+// writeTrace prints the stages in order: the intent match as a score table,
+// the others as compact JSON, or indented JSON when verbose
+func writeTrace(w io.Writer, stages []proxy.TraceStage, verbose bool) {
+	for i, stage := range stages {
+		fmt.Fprintf(w, "== %d. %s\n", i+1, stage.Name)
+
+		if scores, ok := stage.Data.([]proxy.MappingScore); ok {
+			tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "\tINTENT PATTERN\tMATCH\tSCORE\tWORDS")
+			for _, score := range scores {
+				marker := ""
+				if score.Selected {
+					marker = "*"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%v\t%.2f\t%v\n", marker, score.IntentPattern, score.Matched, score.Score, score.MatchedWords)
+			}
+			tw.Flush()
+			continue
+		}
+
+		var data []byte
+		if verbose {
+			data, _ = json.MarshalIndent(stage.Data, "", "  ")
+		} else {
+			data, _ = json.Marshal(stage.Data)
+		}
+		fmt.Fprintf(w, "%s\n", data)
+	}
+}
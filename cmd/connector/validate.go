@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
+	"github.com/A2AGateway/a2a-connector/internal/codec"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/tlsclient"
+)
+
+// runValidate implements `connector validate`: it checks config files offline,
+// without resolving secrets, so CI can reject broken configs before deploy.
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	configFile := fs.String("config", "connector.yaml", "Path to YAML/JSON config file; further files may follow as arguments")
+	variables := fs.Bool("variables", false, "List every ${VAR} reference with the source of its value")
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{*configFile}
+	}
+	failed := 0
+	for _, file := range files {
+		cfg, err := config.LoadOffline(file)
+		if err == nil {
+			err = config.ValidateConfig(cfg)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stdout, "%s: invalid\n%v\n", file, err)
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%s: ok (%d mappings)\n", file, len(cfg.Mappings))
+		if *variables {
+			writeVariables(os.Stdout, cfg.VariableReferences())
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d config files are invalid", failed, len(files))
+	}
+	return nil
+}
+
+// writeVariables prints the variable references of a config as a table;
+// undefined variables are left in the config as written
+func writeVariables(w io.Writer, refs []config.VariableReference) {
+	if len(refs) == 0 {
+		fmt.Fprintln(w, "  no variable references")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  VARIABLE\tPATH\tLINE\tSOURCE")
+	for _, ref := range refs {
+		source := ref.Source
+		if source == "" {
+			source = "undefined"
+		}
+		fmt.Fprintf(tw, "  ${%s}\t%s\t%d\t%s\n", ref.Name, ref.Path, ref.Line, source)
+	}
+	tw.Flush()
+}
+
+// runCheck implements `connector check`: it validates a config, builds every
+// adapter setting as the connector would at startup and, with --probe, checks
+// the legacy system is reachable.
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	configFile := fs.String("config", "connector.yaml", "Path to YAML/JSON config file")
+	probe := fs.Bool("probe", false, "Resolve secrets and probe the legacy endpoint")
+	timeout := fs.Duration("timeout", 10*time.Second, "Time limit of the probe")
+	fs.Parse(args)
+
+	load := config.LoadOffline
+	if *probe {
+		load = config.LoadFromFile
+	}
+	cfg, err := load(*configFile)
+	if err == nil {
+		err = config.ValidateConfig(cfg)
+	}
+	if !reportCheck(os.Stdout, "config", err) {
+		return fmt.Errorf("config %s is invalid", *configFile)
+	}
+
+	failed := 0
+	if !reportCheck(os.Stdout, "adapter settings", checkAdapterSettings(cfg)) {
+		failed++
+	}
+	fmt.Fprintf(os.Stdout, "ok    %s\n", compiledSummary(cfg))
+
+	if *probe {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		if !reportCheck(os.Stdout, "legacy endpoint", probeAdapter(ctx, cfg)) {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// reportCheck prints the outcome of a check and reports whether it passed
+func reportCheck(w io.Writer, name string, err error) bool {
+	if err != nil {
+		fmt.Fprintf(w, "FAIL  %s\n%v\n", name, err)
+		return false
+	}
+	fmt.Fprintf(w, "ok    %s\n", name)
+	return true
+}
+
+// checkAdapterSettings builds the settings the adapter is created with at
+// startup: retry policy, timeouts, codecs, TLS, auth and success statuses.
+// Unlike startup it makes no connection.
+func checkAdapterSettings(cfg *config.ConnectorConfig) error {
+	if _, err := resilience.RetryPolicyFromConfig(cfg.Adapter.Retry); err != nil {
+		return fmt.Errorf("invalid adapter retry: %w", err)
+	}
+	if _, err := adapter.TimeoutsFromConfig(cfg.Adapter.Timeout); err != nil {
+		return fmt.Errorf("invalid adapter timeout: %w", err)
+	}
+	for _, mapping := range cfg.Mappings {
+		if _, err := adapter.TimeoutsFromConfig(mapping.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout of mapping %q: %w", mapping.IntentPattern, err)
+		}
+	}
+	if _, err := codec.FromConfig(cfg.Adapter.Codecs); err != nil {
+		return fmt.Errorf("invalid adapter codecs: %w", err)
+	}
+	if _, err := tlsclient.New(cfg.Adapter.TLS); err != nil {
+		return fmt.Errorf("invalid adapter tls: %w", err)
+	}
+	if _, err := authclient.New(cfg.Adapter.Auth); err != nil {
+		return fmt.Errorf("invalid adapter auth: %w", err)
+	}
+	if _, err := adapter.ParseStatusRanges(cfg.Adapter.SuccessStatuses); err != nil {
+		return fmt.Errorf("invalid adapter successStatuses: %w", err)
+	}
+	return nil
+}
+
+// compiledSummary counts the patterns, templates and expressions compiled
+// when the config was loaded
+func compiledSummary(cfg *config.ConnectorConfig) string {
+	patterns, templates, exprs := 0, 0, 0
+	for _, mapping := range cfg.Mappings {
+		patterns += 1 + len(mapping.ParameterMappings)
+		if mapping.ResponseTransform.CompiledTemplate != nil {
+			templates++
+		}
+	}
+	for _, rules := range [][]config.TransformRule{cfg.Transforms.A2AToLegacy, cfg.Transforms.LegacyToA2A} {
+		for _, rule := range rules {
+			if rule.Compiled != nil {
+				patterns++
+			}
+			if rule.CompiledExpr != nil {
+				exprs++
+			}
+		}
+	}
+	return fmt.Sprintf("compiled %d patterns, %d templates and %d expressions of %d mappings", patterns, templates, exprs, len(cfg.Mappings))
+}
+
+// probeAdapter creates the adapter like at startup and pings its backend
+func probeAdapter(ctx context.Context, cfg *config.ConnectorConfig) error {
+	adptr, err := newConfiguredAdapter(cfg)
+	if err != nil {
+		return err
+	}
+	defer adptr.Close()
+	return adapter.Ping(ctx, adptr)
+}
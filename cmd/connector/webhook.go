@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/adapters/salesforce"
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/egress"
+	"github.com/A2AGateway/a2a-connector/internal/gateway"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/security"
+	"github.com/A2AGateway/a2a-connector/internal/tlsclient"
+)
+
+// webhookHandler receives the events a legacy system posts to a webhook,
+// checks their signature and forwards them to the gateway as tasks or
+// messages. Events that cannot be forwarded are answered with an error
+// status, so the legacy system can deliver them again; events with data the
+// egress policy blocks are rejected for good, as are timestamped events
+// delivered again.
+func webhookHandler(webhook config.WebhookConfig, maxRequestBody int64, egressPolicy *egress.Policy, gwClient *gateway.Client, logger *slog.Logger) http.Handler {
+	signatureHeader := webhook.SignatureHeader
+	if signatureHeader == "" {
+		signatureHeader = security.WebhookSignatureHeader
+	}
+	logger = logger.With("webhook", webhook.Name)
+	received := &receivedEvents{seen: make(map[[sha256.Size]byte]time.Time)}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var reader io.Reader = r.Body
+		if maxRequestBody > 0 {
+			reader = http.MaxBytesReader(w, r.Body, maxRequestBody)
+		}
+		body, err := ioutil.ReadAll(reader)
+		if err != nil {
+			var maxBytes *http.MaxBytesError
+			if errors.As(err, &maxBytes) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		var event map[string]interface{}
+		var eventID [sha256.Size]byte
+		if webhook.TimestampHeader != "" {
+			timestamp := r.Header.Get(webhook.TimestampHeader)
+			now := time.Now()
+			if err := security.VerifyTimestampedBodySignature(webhook.Secret, timestamp, r.Header.Get(signatureHeader), body, security.DefaultMaxClockSkew, now); err != nil {
+				logger.Warn("rejected webhook event", logging.KeyError, err)
+				http.Error(w, "invalid signature", http.StatusUnauthorized)
+				return
+			}
+			eventID = sha256.Sum256(append([]byte(timestamp+"."), body...))
+			if !received.add(eventID, now) {
+				logger.Warn("rejected webhook event delivered again")
+				http.Error(w, "event already received", http.StatusConflict)
+				return
+			}
+		} else if webhook.Secret != "" {
+			if err := security.VerifyBodySignature(webhook.Secret, r.Header.Get(signatureHeader), body); err != nil {
+				logger.Warn("rejected webhook event", logging.KeyError, err)
+				http.Error(w, "invalid signature", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		if err := json.Unmarshal(body, &event); err != nil {
+			http.Error(w, "event must be a JSON object", http.StatusBadRequest)
+			return
+		}
+		out, status, err := forwardEvent(r.Context(), &webhook, event, egressPolicy, gwClient, logger)
+		if err != nil {
+			// Events that were not forwarded may be delivered again
+			if webhook.TimestampHeader != "" && status != http.StatusUnprocessableEntity {
+				received.remove(eventID)
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		response := map[string]interface{}{"webhook": webhook.Name}
+		if id, ok := out["id"]; ok {
+			response["id"] = id
+		}
+		json.NewEncoder(w).Encode(response)
+	})
+}
+
+// receivedEvents holds the timestamped events a webhook received until
+// their timestamp expires, so each is accepted once
+type receivedEvents struct {
+	mu   sync.Mutex
+	seen map[[sha256.Size]byte]time.Time
+}
+
+// add records the event with the hash id; it returns false when the event
+// was already received
+func (e *receivedEvents) add(id [sha256.Size]byte, now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for seen, expires := range e.seen {
+		if now.After(expires) {
+			delete(e.seen, seen)
+		}
+	}
+	if _, ok := e.seen[id]; ok {
+		return false
+	}
+	// Timestamps are accepted up to the clock skew ahead of now
+	e.seen[id] = now.Add(2 * security.DefaultMaxClockSkew)
+	return true
+}
+
+// remove forgets an event, so it can be delivered again
+func (e *receivedEvents) remove(id [sha256.Size]byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.seen, id)
+}
+
+// forwardEvent applies the egress policy and the transform of a webhook to an
+// event and forwards the task or message to the gateway. Failures return the
+// HTTP status telling the sender whether to deliver the event again:
+// unprocessable events are rejected for good.
+func forwardEvent(ctx context.Context, webhook *config.WebhookConfig, event map[string]interface{}, egressPolicy *egress.Policy, gwClient *gateway.Client, logger *slog.Logger) (map[string]interface{}, int, error) {
+	violations, blocked := egressPolicy.Apply(event)
+	if len(violations) > 0 {
+		logger.Warn("egress policy violated", "violations", violations, "blocked", blocked)
+	}
+	if blocked {
+		return nil, http.StatusUnprocessableEntity, errors.New("event withheld by egress policy")
+	}
+	out, err := proxy.TransformEvent(webhook, event, time.Now())
+	if err != nil {
+		logger.Warn("webhook transform failed", logging.KeyError, err)
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	if metadata, ok := out["metadata"].(map[string]interface{}); ok && len(violations) > 0 {
+		metadata[egress.MetadataKey] = violations
+	}
+
+	if gwClient == nil {
+		return nil, http.StatusServiceUnavailable, errors.New("connector is not connected to a gateway")
+	}
+	source := "webhook:" + webhook.Name
+	if webhook.Kind == proxy.WebhookMessage {
+		err = gwClient.PushMessage(ctx, source, out)
+	} else {
+		err = gwClient.PushTask(ctx, source, out)
+	}
+	if err != nil {
+		logger.Warn("failed to forward webhook event to gateway", logging.KeyError, err)
+		return nil, http.StatusBadGateway, errors.New("failed to forward event")
+	}
+	logger.Info("forwarded webhook event to gateway")
+	return out, 0, nil
+}
+
+// newSalesforceStream subscribes a webhook to its Salesforce Streaming API
+// channels and forwards their events like posted ones. Events that cannot be
+// forwarded for now are received again; unprocessable ones are skipped.
+func newSalesforceStream(webhook config.WebhookConfig, egressPolicy *egress.Policy, gwClient *gateway.Client, logger *slog.Logger) (*salesforce.Subscriber, error) {
+	stream := webhook.Salesforce
+	client := &http.Client{}
+	tlsConfig, err := tlsclient.New(stream.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s salesforce tls: %w", webhook.Name, err)
+	}
+	if err := tlsclient.Apply(client, tlsConfig); err != nil {
+		return nil, err
+	}
+	authProvider, err := authclient.New(stream.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s salesforce auth: %w", webhook.Name, err)
+	}
+	authclient.Apply(client, authProvider)
+
+	subscriber := salesforce.NewSubscriber(stream.InstanceURL, stream.Channels, client)
+	if stream.Replay == "all" {
+		subscriber.Replay = salesforce.ReplayAll
+	}
+	subscriber.ReplayFile = stream.ReplayFile
+	logger = logger.With("webhook", webhook.Name)
+	subscriber.Handle = func(ctx context.Context, channel string, event map[string]interface{}) error {
+		_, status, err := forwardEvent(ctx, &webhook, event, egressPolicy, gwClient, logger.With("channel", channel))
+		if status == http.StatusUnprocessableEntity {
+			return nil
+		}
+		return err
+	}
+	return subscriber, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/egress"
+	"github.com/A2AGateway/a2a-connector/internal/gateway"
+)
+
+// testGateway counts the tasks pushed to it and answers with status
+func testGateway(t *testing.T, status *atomic.Int32) (*gateway.Client, *atomic.Int32) {
+	pushed := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/connectors/erp/tasks" {
+			http.NotFound(w, r)
+			return
+		}
+		pushed.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+	return gateway.NewClient(server.URL, "erp", ""), pushed
+}
+
+func signBody(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func postEvent(handler http.Handler, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/shipments", strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWebhookSignature(t *testing.T) {
+	status := &atomic.Int32{}
+	status.Store(http.StatusOK)
+	gwClient, pushed := testGateway(t, status)
+	webhook := config.WebhookConfig{Name: "shipments", Path: "/webhooks/shipments", Secret: "s3cret"}
+	handler := webhookHandler(webhook, 0, nil, gwClient, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	body := `{"order":{"id":"42"}}`
+	for name, header := range map[string]http.Header{
+		"missing signature":         nil,
+		"signature of another key":  {"X-Signature": {signBody("other", body)}},
+		"signature of another body": {"X-Signature": {signBody("s3cret", `{"order":{"id":"43"}}`)}},
+	} {
+		if rec := postEvent(handler, body, header); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for a %s, got %d", name, rec.Code)
+		}
+	}
+	if pushed.Load() != 0 {
+		t.Fatal("Expected unsigned events not to be forwarded")
+	}
+	if rec := postEvent(handler, body, http.Header{"X-Signature": {"sha256=" + signBody("s3cret", body)}}); rec.Code != http.StatusAccepted {
+		t.Errorf("Expected a signed event to be accepted, got %d %s", rec.Code, rec.Body.String())
+	}
+	if pushed.Load() != 1 {
+		t.Errorf("Expected the event to be forwarded once, got %d", pushed.Load())
+	}
+}
+
+func TestWebhookReplay(t *testing.T) {
+	status := &atomic.Int32{}
+	status.Store(http.StatusOK)
+	gwClient, pushed := testGateway(t, status)
+	webhook := config.WebhookConfig{Name: "shipments", Path: "/webhooks/shipments", Secret: "s3cret", TimestampHeader: "X-Timestamp"}
+	handler := webhookHandler(webhook, 0, nil, gwClient, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	body := `{"order":{"id":"42"}}`
+	signed := func(at time.Time) http.Header {
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		return http.Header{"X-Timestamp": {timestamp}, "X-Signature": {signBody("s3cret", timestamp+"."+body)}}
+	}
+
+	// The timestamp is required, signed and recent
+	now := time.Now()
+	for name, header := range map[string]http.Header{
+		"body-only signature": {"X-Signature": {signBody("s3cret", body)}},
+		"modified timestamp":  {"X-Timestamp": {strconv.FormatInt(now.Unix()+1, 10)}, "X-Signature": signed(now)["X-Signature"]},
+		"expired timestamp":   signed(now.Add(-10 * time.Minute)),
+		"future timestamp":    signed(now.Add(10 * time.Minute)),
+	} {
+		if rec := postEvent(handler, body, header); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for a %s, got %d", name, rec.Code)
+		}
+	}
+
+	// An event is accepted once
+	header := signed(now)
+	if rec := postEvent(handler, body, header); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected a signed event to be accepted, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := postEvent(handler, body, header); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a replayed event, got %d", rec.Code)
+	}
+	if pushed.Load() != 1 {
+		t.Errorf("Expected the event to be forwarded once, got %d", pushed.Load())
+	}
+
+	// An event the gateway did not take may be delivered again
+	status.Store(http.StatusInternalServerError)
+	header = signed(now.Add(-time.Second))
+	if rec := postEvent(handler, body, header); rec.Code != http.StatusBadGateway {
+		t.Fatalf("Expected 502 when the gateway fails, got %d", rec.Code)
+	}
+	status.Store(http.StatusOK)
+	if rec := postEvent(handler, body, header); rec.Code != http.StatusAccepted {
+		t.Errorf("Expected an event that was not forwarded to be accepted again, got %d", rec.Code)
+	}
+}
+
+func TestWebhookEgress(t *testing.T) {
+	status := &atomic.Int32{}
+	status.Store(http.StatusOK)
+	gwClient, pushed := testGateway(t, status)
+	policy := egress.New(&config.EgressConfig{Classes: []config.DataClassConfig{
+		{Name: "payroll", Fields: []string{"salary"}, Action: "block"},
+		{Name: "internal-only", Fields: []string{"margin"}},
+	}})
+	webhook := config.WebhookConfig{Name: "shipments", Path: "/webhooks/shipments"}
+	handler := webhookHandler(webhook, 0, policy, gwClient, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if rec := postEvent(handler, `{"employee":{"id":"7","salary":5000}}`, nil); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for an event of a block class, got %d", rec.Code)
+	}
+	if pushed.Load() != 0 {
+		t.Fatal("Expected a blocked event not to be forwarded")
+	}
+	if rec := postEvent(handler, `{"order":{"id":"42","margin":0.3}}`, nil); rec.Code != http.StatusAccepted {
+		t.Errorf("Expected an event with stripped data to be forwarded, got %d %s", rec.Code, rec.Body.String())
+	}
+	if pushed.Load() != 1 {
+		t.Errorf("Expected the stripped event to be forwarded, got %d", pushed.Load())
+	}
+}
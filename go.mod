@@ -3,8 +3,34 @@ module github.com/A2AGateway/a2a-connector
 go 1.21
 
 require (
+	c2sp.org/CCTV/age v0.0.0-20240306222714-3ec4d716e805
+	filippo.io/age v1.2.1
 	github.com/A2AGateway/a2a-protocol v0.0.0
+	github.com/Microsoft/go-winio v0.6.2
+	github.com/crewjam/saml v0.4.14
+	github.com/hashicorp/go-hclog v0.14.1
+	github.com/hashicorp/go-plugin v1.6.3
+	github.com/russellhaering/goxmldsig v1.3.0
+	golang.org/x/sys v0.29.0
+	google.golang.org/grpc v1.58.3
+	google.golang.org/protobuf v1.36.1
 	gopkg.in/yaml.v3 v3.0.1
 )
 
+require (
+	github.com/beevik/etree v1.1.0 // indirect
+	github.com/fatih/color v1.7.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/hashicorp/yamux v0.1.1 // indirect
+	github.com/jonboulle/clockwork v0.2.2 // indirect
+	github.com/mattermost/xml-roundtrip-validator v0.1.0 // indirect
+	github.com/mattn/go-colorable v0.1.4 // indirect
+	github.com/mattn/go-isatty v0.0.17 // indirect
+	github.com/oklog/run v1.0.0 // indirect
+	golang.org/x/crypto v0.32.0 // indirect
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
+)
+
 replace github.com/A2AGateway/a2a-protocol => ../a2a-protocol
@@ -1,5 +1,11 @@
 package adapter
 
+import (
+	"log/slog"
+
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
 // AdapterType represents the type of system being adapted
 type AdapterType string
 
@@ -32,6 +38,11 @@ type BaseAdapter struct {
 	Type        AdapterType
 	Description string
 	Config      map[string]interface{}
+
+	// Events receives connection events; nil uses DefaultConnEvents
+	Events *ConnEventLog
+
+	openConns int64
 }
 
 // NewBaseAdapter creates a new base adapter
@@ -43,3 +54,8 @@ func NewBaseAdapter(name string, adapterType AdapterType, description string, co
 		Config:      config,
 	}
 }
+
+// Logger returns the default logger tagged with the adapter name
+func (b *BaseAdapter) Logger() *slog.Logger {
+	return slog.Default().With(logging.KeyAdapter, b.Name)
+}
@@ -0,0 +1,39 @@
+package adapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/audit"
+)
+
+// AuditedAdapter records the calls to the wrapped adapter that may change its
+// backend in an audit log
+type AuditedAdapter struct {
+	Adapter
+	Name string
+	Log  *audit.Log
+}
+
+// NewAuditedAdapter wraps an adapter named name with an audit log
+func NewAuditedAdapter(inner Adapter, name string, log *audit.Log) *AuditedAdapter {
+	return &AuditedAdapter{
+		Adapter: inner,
+		Name:    name,
+		Log:     log,
+	}
+}
+
+// ExecuteTask executes the task and records it
+func (a *AuditedAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext executes the task with ctx and records it, with the
+// task and caller of ctx
+func (a *AuditedAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	start := time.Now()
+	result, err := ExecuteTaskContext(ctx, a.Adapter, action, params)
+	a.Log.Record(ctx, a.Name, action, params, time.Since(start), err)
+	return result, err
+}
@@ -0,0 +1,37 @@
+package adapter
+
+import (
+	"context"
+
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+// CircuitBreakerAdapter fails fast while the wrapped adapter's backend is down
+type CircuitBreakerAdapter struct {
+	Adapter
+	Breaker *resilience.CircuitBreaker
+}
+
+// NewCircuitBreakerAdapter wraps an adapter with a circuit breaker
+func NewCircuitBreakerAdapter(inner Adapter, breaker *resilience.CircuitBreaker) *CircuitBreakerAdapter {
+	return &CircuitBreakerAdapter{
+		Adapter: inner,
+		Breaker: breaker,
+	}
+}
+
+// ExecuteTask executes the task unless the circuit is open
+func (a *CircuitBreakerAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext executes the task with ctx unless the circuit is open
+func (a *CircuitBreakerAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := a.Breaker.Execute(func() error {
+		var err error
+		result, err = ExecuteTaskContext(ctx, a.Adapter, action, params)
+		return err
+	})
+	return result, err
+}
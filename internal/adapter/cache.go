@@ -0,0 +1,73 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+)
+
+// CacheEntry identifies where a call's result may be cached and for how long
+type CacheEntry struct {
+	Key string
+	TTL time.Duration
+}
+
+type cacheEntryKey struct{}
+
+// WithCacheEntry returns a context that enables caching of the call's result
+func WithCacheEntry(ctx context.Context, entry CacheEntry) context.Context {
+	return context.WithValue(ctx, cacheEntryKey{}, entry)
+}
+
+// CacheEntryFromContext returns the cache entry carried by ctx, if any
+func CacheEntryFromContext(ctx context.Context) (CacheEntry, bool) {
+	entry, ok := ctx.Value(cacheEntryKey{}).(CacheEntry)
+	return entry, ok && entry.Key != "" && entry.TTL > 0
+}
+
+// CachingAdapter serves repeated calls from a response cache. Only calls whose
+// context carries a CacheEntry are cached, and only successful results are stored.
+type CachingAdapter struct {
+	Adapter
+	Cache cache.Cache
+}
+
+// NewCachingAdapter wraps an adapter with a response cache
+func NewCachingAdapter(inner Adapter, c cache.Cache) *CachingAdapter {
+	return &CachingAdapter{
+		Adapter: inner,
+		Cache:   c,
+	}
+}
+
+// ExecuteTask executes the task without caching
+func (a *CachingAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext returns a cached result when available, executing the task otherwise
+func (a *CachingAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	entry, ok := CacheEntryFromContext(ctx)
+	if !ok {
+		return ExecuteTaskContext(ctx, a.Adapter, action, params)
+	}
+
+	if data, found := a.Cache.Get(entry.Key); found {
+		var result map[string]interface{}
+		if err := json.Unmarshal(data, &result); err == nil {
+			return result, nil
+		}
+		a.Cache.Delete(entry.Key)
+	}
+
+	result, err := ExecuteTaskContext(ctx, a.Adapter, action, params)
+	if err != nil {
+		return result, err
+	}
+	if data, merr := json.Marshal(result); merr == nil {
+		a.Cache.Set(entry.Key, data, entry.TTL)
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+)
+
+// ValidatorTTL is how long stored ETag and Last-Modified validators are kept
+const ValidatorTTL = 24 * time.Hour
+
+// Validators are the HTTP cache validators of a previous response
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+type conditionalKey struct{}
+
+// WithConditionalKey returns a context that makes the call conditional on the
+// validators stored under key, typically the mapping and its parameters
+func WithConditionalKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, conditionalKey{}, key)
+}
+
+// ConditionalKeyFromContext returns the validator key carried by ctx, if any
+func ConditionalKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(conditionalKey{}).(string)
+	return key, ok && key != ""
+}
+
+// UnchangedResult is the result of a conditional call answered with 304 Not Modified
+func UnchangedResult(v Validators) map[string]interface{} {
+	result := map[string]interface{}{
+		"unchanged":  true,
+		"statusCode": http.StatusNotModified,
+	}
+	if v.ETag != "" {
+		result["etag"] = v.ETag
+	}
+	if v.LastModified != "" {
+		result["lastModified"] = v.LastModified
+	}
+	return result
+}
+
+// loadValidators returns the validators stored under key
+func loadValidators(store cache.Cache, key string) (Validators, bool) {
+	var v Validators
+	if store == nil {
+		return v, false
+	}
+	data, ok := store.Get(key)
+	if !ok || json.Unmarshal(data, &v) != nil {
+		return v, false
+	}
+	return v, v.ETag != "" || v.LastModified != ""
+}
+
+// storeValidators saves the validators of a successful response under key
+func storeValidators(store cache.Cache, key string, h http.Header) {
+	if store == nil {
+		return
+	}
+	v := Validators{ETag: h.Get("ETag"), LastModified: h.Get("Last-Modified")}
+	if v.ETag == "" && v.LastModified == "" {
+		return
+	}
+	data, _ := json.Marshal(v)
+	store.Set(key, data, ValidatorTTL)
+}
+
+// setConditionalHeaders adds If-None-Match and If-Modified-Since from v
+func setConditionalHeaders(req *http.Request, v Validators) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
@@ -1,10 +1,29 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
 )
 
+// namedParamPattern matches {name} placeholders in query and statement templates
+var namedParamPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ColumnInfo describes a column of a database table
+type ColumnInfo struct {
+	Name       string
+	DataType   string
+	Nullable   bool
+	PrimaryKey bool
+}
+
 // DBAdapter adapts a database
 type DBAdapter struct {
 	BaseAdapter
@@ -12,6 +31,25 @@ type DBAdapter struct {
 	DriverName  string
 	DataSource  string
 	TablePrefix string
+
+	// Retry retries transient failures; nil runs every statement once
+	Retry *resilience.RetryPolicy
+
+	// Timeouts are the default limits for calls; only Total applies to databases
+	Timeouts Timeouts
+
+	// MaxLOBSize limits the LOBs read as streams; zero reads them whole
+	MaxLOBSize int64
+
+	// Policy limits the statements of query, execute and call and the queues
+	// of enqueue and dequeue; nil runs any
+	Policy *SQLPolicy
+
+	// QueryLimits limit the rows and time of the query action
+	QueryLimits QueryLimits
+
+	// poolSize is the number of open connections last recorded
+	poolSize int64
 }
 
 // NewDBAdapter creates a new database adapter
@@ -33,20 +71,44 @@ func (a *DBAdapter) Initialize() error {
 	}
 	
 	// Check connection
+	start := time.Now()
 	err = db.Ping()
 	if err != nil {
+		a.recordError("", "connect", err)
 		return err
 	}
 	
 	a.DB = db
+	open := db.Stats().OpenConnections
+	atomic.StoreInt64(&a.poolSize, int64(open))
+	a.recordEvent(ConnEvent{Kind: EventConnect, Detail: a.DriverName, DurationMs: milliseconds(time.Since(start)), Open: open})
 	return nil
 }
 
+// recordPool records the growth or shrinking of the connection pool since
+// the last call
+func (a *DBAdapter) recordPool() {
+	open := a.DB.Stats().OpenConnections
+	previous := atomic.SwapInt64(&a.poolSize, int64(open))
+	switch {
+	case int64(open) > previous:
+		a.recordEvent(ConnEvent{Kind: EventPoolGrow, Detail: a.DriverName, Open: open})
+	case int64(open) < previous:
+		a.recordEvent(ConnEvent{Kind: EventPoolShrink, Detail: a.DriverName, Open: open})
+	}
+}
+
 // GetCapabilities returns the capabilities of the database
 func (a *DBAdapter) GetCapabilities() (map[string]interface{}, error) {
 	// Query for tables
-	query := "SELECT table_name FROM information_schema.tables WHERE table_name LIKE ?"
-	rows, err := a.DB.Query(query, a.TablePrefix+"%")
+	query, args, err := a.bindNamed(
+		"SELECT table_name FROM information_schema.tables WHERE table_name LIKE {prefix}",
+		map[string]interface{}{"prefix": a.TablePrefix + "%"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -70,24 +132,88 @@ func (a *DBAdapter) GetCapabilities() (map[string]interface{}, error) {
 
 // ExecuteTask executes a database operation
 func (a *DBAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext executes a database operation within the adapter and per-call timeouts.
+// Connect and read limits do not apply to database calls; only the total limit does.
+func (a *DBAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	ctx, cancel, timeouts := withCallTimeouts(ctx, a.Timeouts)
+	defer cancel()
+
+	var result map[string]interface{}
+	var err error
 	switch action {
 	case "query":
-		return a.executeQuery(params)
+		result, err = a.executeQuery(ctx, params)
 	case "execute":
-		return a.executeStatement(params)
+		result, err = a.executeStatement(ctx, params)
+	case "call":
+		result, err = a.executeCall(ctx, params)
+	case "enqueue":
+		result, err = a.executeEnqueue(ctx, params)
+	case "dequeue":
+		result, err = a.executeDequeue(ctx, params)
 	default:
 		return nil, fmt.Errorf("unsupported action: %s", action)
 	}
+	a.recordPool()
+	if err != nil && isConnError(err) {
+		a.recordError("", action, err)
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, &TimeoutError{Phase: "total", Limit: timeouts.Total}
+	}
+	return result, err
 }
 
 // executeQuery executes a SELECT query
-func (a *DBAdapter) executeQuery(params map[string]interface{}) (map[string]interface{}, error) {
+func (a *DBAdapter) executeQuery(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
 	queryStr, ok := params["query"].(string)
 	if !ok {
 		return nil, fmt.Errorf("query parameter is required")
 	}
+	if err := a.Policy.CheckStatement("query", queryStr); err != nil {
+		return nil, err
+	}
+	limits := a.QueryLimits
+	if limits.InjectLimit {
+		// One row more than the limit tells a truncated result from a full one
+		queryStr = limitQuery(a.DriverName, queryStr, limits.MaxRows+1)
+	}
 	
-	rows, err := a.DB.Query(queryStr)
+	named, err := binaryArgs(argsParam(params), params["binary"])
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := a.bindNamed(queryStr, named)
+	if err != nil {
+		return nil, err
+	}
+
+	queryCtx := ctx
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+	result, err := a.readQuery(queryCtx, query, args, limits.MaxRows)
+	// The call timeouts are reported by ExecuteTaskContext
+	if err != nil && ctx.Err() == nil && queryCtx.Err() == context.DeadlineExceeded {
+		return nil, &TimeoutError{Phase: "query", Limit: limits.Timeout}
+	}
+	return result, err
+}
+
+// readQuery runs a query and reads at most maxRows rows, or every row when
+// maxRows is zero
+func (a *DBAdapter) readQuery(ctx context.Context, query string, args []interface{}, maxRows int) (map[string]interface{}, error) {
+	var rows *sql.Rows
+	err := a.Retry.DoContext(ctx, func() error {
+		var err error
+		rows, err = a.DB.QueryContext(ctx, query, args...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +224,10 @@ func (a *DBAdapter) executeQuery(params map[string]interface{}) (map[string]inte
 	if err != nil {
 		return nil, err
 	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
 	
 	// Prepare result
 	var results []map[string]interface{}
@@ -110,7 +240,12 @@ func (a *DBAdapter) executeQuery(params map[string]interface{}) (map[string]inte
 	}
 	
 	// Iterate over rows
+	truncated := false
 	for rows.Next() {
+		if maxRows > 0 && len(results) == maxRows {
+			truncated = true
+			break
+		}
 		if err := rows.Scan(valuePtrs...); err != nil {
 			return nil, err
 		}
@@ -118,30 +253,53 @@ func (a *DBAdapter) executeQuery(params map[string]interface{}) (map[string]inte
 		// Create row map
 		row := make(map[string]interface{})
 		for i, col := range columns {
-			val := values[i]
-			if b, ok := val.([]byte); ok {
-				row[col] = string(b)
-			} else {
-				row[col] = val
+			val, err := a.columnValue(col, columnTypes[i].DatabaseTypeName(), values[i])
+			if err != nil {
+				return nil, err
 			}
+			row[col] = val
 		}
 		
 		results = append(results, row)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"results": results,
-	}, nil
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	return result, nil
 }
 
 // executeStatement executes a non-SELECT statement
-func (a *DBAdapter) executeStatement(params map[string]interface{}) (map[string]interface{}, error) {
+func (a *DBAdapter) executeStatement(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
 	stmtStr, ok := params["statement"].(string)
 	if !ok {
 		return nil, fmt.Errorf("statement parameter is required")
 	}
+	if err := a.Policy.CheckStatement("execute", stmtStr); err != nil {
+		return nil, err
+	}
 	
-	result, err := a.DB.Exec(stmtStr)
+	named, err := binaryArgs(argsParam(params), params["binary"])
+	if err != nil {
+		return nil, err
+	}
+	stmt, args, err := a.bindNamed(stmtStr, named)
+	if err != nil {
+		return nil, err
+	}
+
+	var result sql.Result
+	err = a.Retry.DoContext(ctx, func() error {
+		var err error
+		result, err = a.DB.ExecContext(ctx, stmt, args...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -161,10 +319,137 @@ func (a *DBAdapter) executeStatement(params map[string]interface{}) (map[string]
 	}, nil
 }
 
+// DescribeTable returns the columns of a table, marking its primary key columns
+func (a *DBAdapter) DescribeTable(table string) ([]ColumnInfo, error) {
+	query, args, err := a.bindNamed(
+		"SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_name = {table} ORDER BY ordinal_position",
+		map[string]interface{}{"table": table},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := a.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []ColumnInfo
+	for rows.Next() {
+		var name, dataType, nullable string
+		if err := rows.Scan(&name, &dataType, &nullable); err != nil {
+			return nil, err
+		}
+		columns = append(columns, ColumnInfo{
+			Name:     name,
+			DataType: dataType,
+			Nullable: strings.EqualFold(nullable, "YES"),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+
+	keys, err := a.primaryKeys(table)
+	if err != nil {
+		return nil, err
+	}
+	for i := range columns {
+		columns[i].PrimaryKey = keys[columns[i].Name]
+	}
+
+	return columns, nil
+}
+
+// primaryKeys returns the primary key columns of a table
+func (a *DBAdapter) primaryKeys(table string) (map[string]bool, error) {
+	query, args, err := a.bindNamed(
+		"SELECT k.column_name FROM information_schema.table_constraints t "+
+			"JOIN information_schema.key_column_usage k "+
+			"ON t.constraint_name = k.constraint_name AND t.table_name = k.table_name "+
+			"WHERE t.constraint_type = 'PRIMARY KEY' AND t.table_name = {table}",
+		map[string]interface{}{"table": table},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := a.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		keys[name] = true
+	}
+
+	return keys, rows.Err()
+}
+
+// bindNamed replaces {name} placeholders with driver-specific bind parameters
+func (a *DBAdapter) bindNamed(query string, named map[string]interface{}) (string, []interface{}, error) {
+	return BindNamedParams(a.DriverName, query, named)
+}
+
+// BindNamedParams rewrites {name} placeholders into positional bind parameters for the driver.
+// Values are never interpolated into the SQL text; missing values bind as NULL.
+func BindNamedParams(driver, query string, named map[string]interface{}) (string, []interface{}, error) {
+	var args []interface{}
+	var bindErr error
+
+	bound := namedParamPattern.ReplaceAllStringFunc(query, func(match string) string {
+		name := match[1 : len(match)-1]
+		value := named[name]
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			bindErr = fmt.Errorf("parameter %s must be a scalar value", name)
+		}
+		args = append(args, value)
+		return placeholder(driver, len(args))
+	})
+	if bindErr != nil {
+		return "", nil, bindErr
+	}
+
+	return bound, args, nil
+}
+
+// placeholder returns the n-th bind parameter in the style of the driver
+func placeholder(driver string, n int) string {
+	switch strings.ToLower(driver) {
+	case "postgres", "pgx", "cockroach":
+		return "$" + strconv.Itoa(n)
+	case "sqlserver", "mssql":
+		return "@p" + strconv.Itoa(n)
+	case "oracle", "godror", "oci8":
+		return ":" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// argsParam returns the bind arguments passed under params["args"]
+func argsParam(params map[string]interface{}) map[string]interface{} {
+	args, _ := params["args"].(map[string]interface{})
+	return args
+}
+
 // Close cleans up resources
 func (a *DBAdapter) Close() error {
 	if a.DB != nil {
-		return a.DB.Close()
+		err := a.DB.Close()
+		a.recordEvent(ConnEvent{Kind: EventDisconnect, Detail: a.DriverName})
+		return err
 	}
 	return nil
 }
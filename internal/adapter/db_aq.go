@@ -0,0 +1,203 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Formats of Oracle AQ message payloads
+const (
+	AQFormatText   = "text"
+	AQFormatJSON   = "json"
+	AQFormatBinary = "binary"
+)
+
+// aqMaxPayload is the largest RAW payload PL/SQL can hold
+const aqMaxPayload = 32767
+
+// aqEnqueue enqueues a RAW message; priority, delay and expiration keep the
+// queue defaults when not given
+const aqEnqueue = `DECLARE
+  opts  DBMS_AQ.ENQUEUE_OPTIONS_T;
+  props DBMS_AQ.MESSAGE_PROPERTIES_T;
+  msgid RAW(16);
+BEGIN
+  props.correlation := {correlation};
+  props.priority := NVL({priority}, 1);
+  props.delay := NVL({delay}, DBMS_AQ.NO_DELAY);
+  props.expiration := NVL({expiration}, DBMS_AQ.NEVER);
+  DBMS_AQ.ENQUEUE(queue_name => {queue}, enqueue_options => opts,
+    message_properties => props, payload => {payload}, msgid => msgid);
+  {message_id} := RAWTOHEX(msgid);
+END;`
+
+// aqDequeue dequeues the first RAW message matching the correlation selector,
+// waiting for one up to the given seconds. An empty queue leaves the message
+// id NULL rather than raising ORA-25228.
+const aqDequeue = `DECLARE
+  opts    DBMS_AQ.DEQUEUE_OPTIONS_T;
+  props   DBMS_AQ.MESSAGE_PROPERTIES_T;
+  payload RAW(32767);
+  msgid   RAW(16);
+  no_messages EXCEPTION;
+  PRAGMA EXCEPTION_INIT(no_messages, -25228);
+BEGIN
+  opts.wait := NVL({wait}, DBMS_AQ.NO_WAIT);
+  opts.correlation := {selector};
+  opts.consumer_name := {consumer};
+  opts.navigation := DBMS_AQ.FIRST_MESSAGE;
+  BEGIN
+    DBMS_AQ.DEQUEUE(queue_name => {queue}, dequeue_options => opts,
+      message_properties => props, payload => payload, msgid => msgid);
+  EXCEPTION
+    WHEN no_messages THEN msgid := NULL;
+  END;
+  {payload} := payload;
+  {message_id} := RAWTOHEX(msgid);
+  {correlation} := props.correlation;
+  {priority} := props.priority;
+  {attempts} := props.attempts;
+END;`
+
+// executeEnqueue puts params["payload"] on the Oracle AQ queue params["queue"]
+// and returns the id of the message
+func (a *DBAdapter) executeEnqueue(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	queue, ok := params["queue"].(string)
+	if !ok || queue == "" {
+		return nil, fmt.Errorf("queue parameter is required")
+	}
+	if err := a.Policy.CheckQueue("enqueue", queue); err != nil {
+		return nil, err
+	}
+	format, _ := params["format"].(string)
+	payload, err := aqPayload(params["payload"], format)
+	if err != nil {
+		return nil, err
+	}
+
+	var messageID sql.NullString
+	named := map[string]interface{}{
+		"queue":       queue,
+		"payload":     payload,
+		"correlation": params["correlation"],
+		"priority":    params["priority"],
+		"delay":       params["delay"],
+		"expiration":  params["expiration"],
+	}
+	dests := map[string]interface{}{"message_id": &messageID}
+	if err := a.runCall(ctx, aqEnqueue, named, dests, nil); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"queue":     queue,
+		"messageId": messageID.String,
+	}, nil
+}
+
+// executeDequeue takes a message off the Oracle AQ queue params["queue"]. It
+// waits up to params["wait"] seconds for one, forever when negative, and only
+// takes messages whose correlation matches params["correlation"], which may
+// use LIKE wildcards. An empty queue returns found false.
+func (a *DBAdapter) executeDequeue(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	queue, ok := params["queue"].(string)
+	if !ok || queue == "" {
+		return nil, fmt.Errorf("queue parameter is required")
+	}
+	if err := a.Policy.CheckQueue("dequeue", queue); err != nil {
+		return nil, err
+	}
+	format, _ := params["format"].(string)
+	switch format {
+	case "", AQFormatText, AQFormatJSON, AQFormatBinary:
+	default:
+		return nil, fmt.Errorf("unsupported payload format %q, expected text, json or binary", format)
+	}
+
+	var messageID, correlation sql.NullString
+	var priority, attempts sql.NullFloat64
+	payload := make([]byte, 0, aqMaxPayload)
+	named := map[string]interface{}{
+		"queue":    queue,
+		"wait":     params["wait"],
+		"selector": params["correlation"],
+		"consumer": params["consumer"],
+	}
+	dests := map[string]interface{}{
+		"payload":     &payload,
+		"message_id":  &messageID,
+		"correlation": &correlation,
+		"priority":    &priority,
+		"attempts":    &attempts,
+	}
+	if err := a.runCall(ctx, aqDequeue, named, dests, nil); err != nil {
+		return nil, err
+	}
+
+	if !messageID.Valid || messageID.String == "" {
+		return map[string]interface{}{"queue": queue, "found": false}, nil
+	}
+	value, err := aqPayloadValue(payload, format)
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %w", messageID.String, err)
+	}
+	result := map[string]interface{}{
+		"queue":     queue,
+		"found":     true,
+		"messageId": messageID.String,
+		"payload":   value,
+		"priority":  priority.Float64,
+		"attempts":  attempts.Float64,
+	}
+	if correlation.Valid {
+		result["correlation"] = correlation.String
+	}
+	return result, nil
+}
+
+// aqPayload encodes a payload parameter as the bytes of a RAW message
+func aqPayload(v interface{}, format string) ([]byte, error) {
+	var data []byte
+	switch format {
+	case "", AQFormatText, AQFormatJSON:
+		if s, ok := v.(string); ok && format != AQFormatJSON {
+			data = []byte(s)
+			break
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode payload: %w", err)
+		}
+		data = encoded
+	case AQFormatBinary:
+		s, _ := v.(string)
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("payload is not base64: %w", err)
+		}
+		data = decoded
+	default:
+		return nil, fmt.Errorf("unsupported payload format %q, expected text, json or binary", format)
+	}
+	if len(data) > aqMaxPayload {
+		return nil, fmt.Errorf("payload of %d bytes exceeds the %d bytes of a RAW message", len(data), aqMaxPayload)
+	}
+	return data, nil
+}
+
+// aqPayloadValue decodes the bytes of a dequeued message in format
+func aqPayloadValue(data []byte, format string) (interface{}, error) {
+	switch format {
+	case AQFormatJSON:
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("payload is not JSON: %w", err)
+		}
+		return value, nil
+	case AQFormatBinary:
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,260 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Kinds of the out parameters of a call
+const (
+	OutString = "string"
+	OutNumber = "number"
+	OutBinary = "binary"
+	OutCursor = "cursor"
+)
+
+// binaryColumnTypes are the database types whose values are returned base64
+// encoded; other byte values, such as CLOBs, are returned as text
+var binaryColumnTypes = map[string]bool{
+	"BLOB": true, "RAW": true, "LONG RAW": true, "BFILE": true,
+	"BYTEA": true, "BINARY": true, "VARBINARY": true, "IMAGE": true,
+	"TINYBLOB": true, "MEDIUMBLOB": true, "LONGBLOB": true,
+}
+
+// executeCall runs a stored procedure or PL/SQL block whose out parameters,
+// named in params["out"] with their kind, are returned under "out". Cursor
+// parameters, such as an Oracle SYS_REFCURSOR, are returned as row sets of at
+// most QueryLimits.MaxRows rows, and truncated is set when one had more. The
+// call and the reading of its cursors share one connection.
+func (a *DBAdapter) executeCall(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	stmtStr, ok := params["statement"].(string)
+	if !ok {
+		return nil, fmt.Errorf("statement parameter is required")
+	}
+	if err := a.Policy.CheckCall(stmtStr); err != nil {
+		return nil, err
+	}
+
+	named, err := binaryArgs(argsParam(params), params["binary"])
+	if err != nil {
+		return nil, err
+	}
+	outs, _ := params["out"].(map[string]interface{})
+	dests := make(map[string]interface{}, len(outs))
+	for name, kind := range outs {
+		kind, _ := kind.(string)
+		dest, err := outDest(kind)
+		if err != nil {
+			return nil, fmt.Errorf("out parameter %s: %w", name, err)
+		}
+		dests[name] = dest
+	}
+
+	values := make(map[string]interface{}, len(dests))
+	truncated := false
+	err = a.runCall(ctx, stmtStr, named, dests, func() error {
+		for name, dest := range dests {
+			value, more, err := a.outValue(dest, a.QueryLimits.MaxRows)
+			if err != nil {
+				return fmt.Errorf("out parameter %s: %w", name, err)
+			}
+			values[name] = value
+			truncated = truncated || more
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"out": values,
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	return result, nil
+}
+
+// runCall executes stmt with the named arguments and the out parameters whose
+// destinations are in dests, then calls read, if not nil, on the same
+// connection
+func (a *DBAdapter) runCall(ctx context.Context, stmt string, named map[string]interface{}, dests map[string]interface{}, read func() error) error {
+	for name, dest := range dests {
+		named[name] = sql.Out{Dest: dest}
+	}
+	stmt, args, err := a.bindNamed(stmt, named)
+	if err != nil {
+		return err
+	}
+
+	conn, err := a.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = a.Retry.DoContext(ctx, func() error {
+		_, err := conn.ExecContext(ctx, stmt, args...)
+		return err
+	})
+	if err != nil || read == nil {
+		return err
+	}
+	return read()
+}
+
+// binaryArgs returns a copy of the bind arguments with those named in binary
+// decoded from base64, to be written to BLOB columns
+func binaryArgs(args map[string]interface{}, binary interface{}) (map[string]interface{}, error) {
+	named := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		named[name] = value
+	}
+	names, _ := binary.([]interface{})
+	for _, name := range names {
+		name, _ := name.(string)
+		s, ok := named[name].(string)
+		if !ok {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s is not base64: %w", name, err)
+		}
+		named[name] = data
+	}
+	return named, nil
+}
+
+// outDest returns the destination of an out parameter of kind
+func outDest(kind string) (interface{}, error) {
+	switch kind {
+	case "", OutString:
+		return new(sql.NullString), nil
+	case OutNumber:
+		return new(sql.NullFloat64), nil
+	case OutBinary:
+		return new([]byte), nil
+	case OutCursor:
+		return new(driver.Rows), nil
+	default:
+		return nil, fmt.Errorf("unsupported kind %q, expected string, number, binary or cursor", kind)
+	}
+}
+
+// outValue returns the value an out parameter destination received, reading
+// at most maxRows rows of a cursor; truncated reports a cursor with more
+func (a *DBAdapter) outValue(dest interface{}, maxRows int) (value interface{}, truncated bool, err error) {
+	switch dest := dest.(type) {
+	case *sql.NullString:
+		if !dest.Valid {
+			return nil, false, nil
+		}
+		return dest.String, false, nil
+	case *sql.NullFloat64:
+		if !dest.Valid {
+			return nil, false, nil
+		}
+		return dest.Float64, false, nil
+	case *[]byte:
+		if *dest == nil {
+			return nil, false, nil
+		}
+		return base64.StdEncoding.EncodeToString(*dest), false, nil
+	case *driver.Rows:
+		if *dest == nil {
+			return nil, false, nil
+		}
+		return a.readCursor(*dest, maxRows)
+	}
+	return nil, false, fmt.Errorf("unsupported destination %T", dest)
+}
+
+// readCursor materializes at most maxRows rows of a cursor, or every row when
+// maxRows is zero, and closes it; truncated reports a cursor with more rows
+func (a *DBAdapter) readCursor(rows driver.Rows, maxRows int) ([]map[string]interface{}, bool, error) {
+	defer rows.Close()
+
+	columns := rows.Columns()
+	types := make([]string, len(columns))
+	if typed, ok := rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		for i := range columns {
+			types[i] = typed.ColumnTypeDatabaseTypeName(i)
+		}
+	}
+
+	results := []map[string]interface{}{}
+	values := make([]driver.Value, len(columns))
+	for {
+		if err := rows.Next(values); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, false, err
+		}
+		if maxRows > 0 && len(results) == maxRows {
+			return results, true, nil
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			value, err := a.columnValue(col, types[i], values[i])
+			if err != nil {
+				return nil, false, err
+			}
+			row[col] = value
+		}
+		results = append(results, row)
+	}
+	return results, false, nil
+}
+
+// columnValue converts a scanned value for the response: binary columns are
+// base64 encoded and other byte values are text. LOBs a driver returns as
+// readers are streamed, up to MaxLOBSize bytes.
+func (a *DBAdapter) columnValue(column, dbType string, value interface{}) (interface{}, error) {
+	binary := binaryColumnTypes[strings.ToUpper(dbType)]
+	switch v := value.(type) {
+	case []byte:
+		if binary {
+			return base64.StdEncoding.EncodeToString(v), nil
+		}
+		return string(v), nil
+	case io.Reader:
+		return a.readLOB(column, v, binary)
+	}
+	return value, nil
+}
+
+// readLOB streams a LOB into its response value
+func (a *DBAdapter) readLOB(column string, r io.Reader, binary bool) (string, error) {
+	if closer, ok := r.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if a.MaxLOBSize > 0 {
+		r = io.LimitReader(r, a.MaxLOBSize+1)
+	}
+
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var encoder io.WriteCloser
+	if binary {
+		encoder = base64.NewEncoder(base64.StdEncoding, &buf)
+		w = encoder
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read LOB column %s: %w", column, err)
+	}
+	if a.MaxLOBSize > 0 && n > a.MaxLOBSize {
+		return "", fmt.Errorf("LOB column %s exceeds %d bytes", column, a.MaxLOBSize)
+	}
+	if encoder != nil {
+		encoder.Close()
+	}
+	return buf.String(), nil
+}
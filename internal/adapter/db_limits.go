@@ -0,0 +1,86 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// QueryLimits guard a database against costly queries of the query action
+type QueryLimits struct {
+	// MaxRows is the most rows a query, or a cursor of the call action,
+	// returns; zero returns every row
+	MaxRows int
+	// Timeout cancels a query running longer; zero leaves only the call timeouts
+	Timeout time.Duration
+	// InjectLimit wraps queries so the database stops after MaxRows rows
+	InjectLimit bool
+}
+
+// QueryLimitsFromConfig parses the query limits of a db adapter; a nil
+// config sets no limits
+func QueryLimitsFromConfig(cfg *config.QueryLimitsConfig) (QueryLimits, error) {
+	if cfg == nil {
+		return QueryLimits{}, nil
+	}
+	if cfg.MaxRows < 0 {
+		return QueryLimits{}, fmt.Errorf("maxRows must not be negative")
+	}
+	if cfg.InjectLimit && cfg.MaxRows == 0 {
+		return QueryLimits{}, fmt.Errorf("injectLimit requires maxRows")
+	}
+	limits := QueryLimits{MaxRows: cfg.MaxRows, InjectLimit: cfg.InjectLimit}
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return QueryLimits{}, fmt.Errorf("invalid timeout %q: %w", cfg.Timeout, err)
+		}
+		limits.Timeout = d
+	}
+	return limits, nil
+}
+
+// limitQuery returns query changed to return at most n rows in the dialect
+// of driver. The query is wrapped as a subquery on lines of its own, so a
+// trailing clause or comment cannot drop the limit; SQL Server queries with
+// an ORDER BY get an OFFSET clause instead, since SQL Server does not order
+// subqueries.
+func limitQuery(driver, query string, n int) string {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	switch driver {
+	case "oracle", "godror", "oci8":
+		return fmt.Sprintf("SELECT * FROM (\n%s\n) WHERE ROWNUM <= %d", query, n)
+	case "sqlserver", "mssql":
+		if orderedWithoutOffset(query) {
+			return fmt.Sprintf("%s\nOFFSET 0 ROWS FETCH NEXT %d ROWS ONLY", query, n)
+		}
+		return fmt.Sprintf("SELECT TOP (%d) * FROM (\n%s\n) AS limited", n, query)
+	default:
+		return fmt.Sprintf("SELECT * FROM (\n%s\n) AS limited LIMIT %d", query, n)
+	}
+}
+
+// orderedWithoutOffset reports whether query ends in an ORDER BY outside
+// parentheses and has no OFFSET clause of its own
+func orderedWithoutOffset(query string) bool {
+	tokens, err := tokenizeSQL(query)
+	if err != nil {
+		return false
+	}
+	depth, ordered := 0, false
+	for _, t := range tokens {
+		switch {
+		case t.kind == sqlPunct && t.text == "(":
+			depth++
+		case t.kind == sqlPunct && t.text == ")":
+			depth--
+		case depth == 0 && t.is("ORDER"):
+			ordered = true
+		case depth == 0 && (t.is("OFFSET") || t.is("FETCH")):
+			return false
+		}
+	}
+	return ordered
+}
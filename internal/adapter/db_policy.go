@@ -0,0 +1,639 @@
+package adapter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// SQL verbs by what the statement does
+var (
+	sqlReadVerbs  = map[string]bool{"SELECT": true}
+	sqlWriteVerbs = map[string]bool{"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true}
+	sqlDDLVerbs   = map[string]bool{"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "RENAME": true, "COMMENT": true, "GRANT": true, "REVOKE": true}
+)
+
+// sqlTableMarkers are the words followed by a table name
+var sqlTableMarkers = map[string]bool{"FROM": true, "JOIN": true, "INTO": true, "UPDATE": true, "DELETE": true, "TABLE": true, "USING": true, "TRUNCATE": true}
+
+// sqlKeywords end a list of tables or stand where a table name was expected
+var sqlKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true, "HAVING": true, "LIMIT": true,
+	"OFFSET": true, "UNION": true, "EXCEPT": true, "INTERSECT": true, "MINUS": true, "FETCH": true, "FOR": true,
+	"SET": true, "VALUES": true, "RETURNING": true, "WINDOW": true, "CONNECT": true, "START": true, "QUALIFY": true,
+	"ON": true, "USING": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "CROSS": true,
+	"OUTER": true, "NATURAL": true, "AS": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true, "AND": true,
+	"OR": true, "NOT": true, "IN": true, "EXISTS": true, "INTO": true, "BY": true, "WITH": true, "DEFAULT": true,
+	"TABLE": true, "UPDATE": true, "DELETE": true, "TRUNCATE": true,
+}
+
+// sqlClauseEnds end the tables of a FROM clause
+var sqlClauseEnds = map[string]bool{
+	"SELECT": true, "WHERE": true, "GROUP": true, "ORDER": true, "HAVING": true, "LIMIT": true, "OFFSET": true,
+	"UNION": true, "EXCEPT": true, "INTERSECT": true, "MINUS": true, "FETCH": true, "FOR": true, "SET": true,
+	"VALUES": true, "RETURNING": true, "WINDOW": true, "CONNECT": true, "START": true, "QUALIFY": true,
+}
+
+// sqlCallKeywords are the words besides sqlKeywords that a parenthesis
+// follows without calling a function
+var sqlCallKeywords = map[string]bool{
+	"OVER": true, "FILTER": true, "WITHIN": true, "ANY": true, "ALL": true, "SOME": true, "IS": true,
+	"LIKE": true, "BETWEEN": true, "DISTINCT": true, "CASE": true, "ROW": true, "ROWS": true, "RANGE": true,
+	"PARTITION": true, "KEEP": true, "PIVOT": true, "UNPIVOT": true, "CONFLICT": true, "LATERAL": true,
+	"ONLY": true, "SETS": true, "OF": true, "DO": true,
+}
+
+// sqlBuiltinFunctions are the functions and type names statements may call
+// without them being on the procedures allowlist. None of them writes.
+var sqlBuiltinFunctions = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true, "COALESCE": true, "NVL": true,
+	"NVL2": true, "NULLIF": true, "DECODE": true, "GREATEST": true, "LEAST": true, "ISNULL": true,
+	"IFNULL": true, "IIF": true, "UPPER": true, "LOWER": true, "INITCAP": true, "TRIM": true, "LTRIM": true,
+	"RTRIM": true, "SUBSTR": true, "SUBSTRING": true, "LENGTH": true, "CHAR_LENGTH": true, "INSTR": true,
+	"POSITION": true, "REPLACE": true, "CONCAT": true, "LPAD": true, "RPAD": true, "ABS": true, "ROUND": true,
+	"TRUNC": true, "FLOOR": true, "CEIL": true, "CEILING": true, "MOD": true, "POWER": true, "SQRT": true,
+	"SIGN": true, "CAST": true, "CONVERT": true, "EXTRACT": true, "TO_CHAR": true, "TO_DATE": true,
+	"TO_NUMBER": true, "TO_TIMESTAMP": true, "DATE_TRUNC": true, "ADD_MONTHS": true, "MONTHS_BETWEEN": true,
+	"LAST_DAY": true, "DATEADD": true, "DATEDIFF": true, "NOW": true, "ROW_NUMBER": true, "RANK": true,
+	"DENSE_RANK": true, "LAG": true, "LEAD": true, "FIRST_VALUE": true, "LAST_VALUE": true, "LISTAGG": true,
+	"STRING_AGG": true, "ARRAY_AGG": true, "GROUP_CONCAT": true, "GROUPING": true, "CUBE": true,
+	"ROLLUP": true, "JSON_VALUE": true, "JSON_QUERY": true, "CHAR": true, "VARCHAR": true, "VARCHAR2": true,
+	"NVARCHAR": true, "NVARCHAR2": true, "NUMBER": true, "NUMERIC": true, "DECIMAL": true, "FLOAT": true,
+	"TIMESTAMP": true, "RAW": true,
+}
+
+// SQLPolicy limits the SQL the database adapters run for the query, execute
+// and call actions, whose statements may come from agents. Statements must be
+// single and without comments; DDL is rejected unless AllowDDL is set. Empty
+// allowlists allow any verb, table, procedure or queue. With procedures,
+// statements call only those and the built-in functions. The enqueue and
+// dequeue actions call DBMS_AQ.ENQUEUE and DBMS_AQ.DEQUEUE on the queues
+// allowed. Names are matched as written in the statement, without quotes and
+// case-insensitively.
+type SQLPolicy struct {
+	Verbs          map[string]bool
+	Tables         map[string]bool
+	Procedures     map[string]bool
+	Queues         map[string]bool
+	ProceduresOnly bool
+	AllowDDL       bool
+}
+
+// SQLPolicyError reports a statement rejected by the SQL policy
+type SQLPolicyError struct {
+	Action string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *SQLPolicyError) Error() string {
+	return fmt.Sprintf("%s rejected by SQL policy: %s", e.Action, e.Reason)
+}
+
+// Details returns the reason
+func (e *SQLPolicyError) Details() map[string]interface{} {
+	return map[string]interface{}{"policy": "sql", "reason": e.Reason}
+}
+
+// HTTPStatusCode returns 403 so rejected statements are neither retried nor
+// counted as failures of the database
+func (e *SQLPolicyError) HTTPStatusCode() int {
+	return http.StatusForbidden
+}
+
+// SQLPolicyFromConfig builds the SQL policy of the adapter config; nil when
+// none is configured
+func SQLPolicyFromConfig(cfg *config.SQLPolicyConfig) (*SQLPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	p := &SQLPolicy{
+		Verbs:          make(map[string]bool, len(cfg.Verbs)),
+		Tables:         make(map[string]bool, len(cfg.Tables)),
+		Procedures:     make(map[string]bool, len(cfg.Procedures)),
+		Queues:         make(map[string]bool, len(cfg.Queues)),
+		ProceduresOnly: cfg.ProceduresOnly,
+		AllowDDL:       cfg.AllowDDL,
+	}
+	for _, verb := range cfg.Verbs {
+		verb = strings.ToUpper(verb)
+		if !sqlReadVerbs[verb] && !sqlWriteVerbs[verb] && !sqlDDLVerbs[verb] {
+			return nil, fmt.Errorf("unsupported SQL verb %q", verb)
+		}
+		p.Verbs[verb] = true
+	}
+	for _, table := range cfg.Tables {
+		p.Tables[strings.ToLower(table)] = true
+	}
+	for _, procedure := range cfg.Procedures {
+		p.Procedures[strings.ToLower(procedure)] = true
+	}
+	for _, queue := range cfg.Queues {
+		p.Queues[strings.ToLower(queue)] = true
+	}
+	return p, nil
+}
+
+// CheckStatement checks a statement of the query or execute action: query
+// runs only reads and execute only writes or, with AllowDDL, DDL
+func (p *SQLPolicy) CheckStatement(action, stmt string) error {
+	if p == nil {
+		return nil
+	}
+	reject := func(format string, args ...interface{}) error {
+		return &SQLPolicyError{Action: action, Reason: fmt.Sprintf(format, args...)}
+	}
+	if p.ProceduresOnly {
+		return reject("only stored procedure calls are allowed")
+	}
+	tokens, err := tokenizeSQL(stmt)
+	if err != nil {
+		return reject("%v", err)
+	}
+	if tokens, err = singleStatement(tokens); err != nil {
+		return reject("%v", err)
+	}
+	s, err := parseSQL(tokens)
+	if err != nil {
+		return reject("%v", err)
+	}
+
+	switch {
+	case sqlDDLVerbs[s.verb]:
+		if action != "execute" || !p.AllowDDL {
+			return reject("DDL statements are not allowed")
+		}
+	case action == "query":
+		if s.verb != "SELECT" || len(s.writes) > 0 {
+			return reject("query runs only SELECT statements that do not write")
+		}
+		if s.selectInto {
+			return reject("SELECT INTO is not allowed")
+		}
+	case !sqlWriteVerbs[s.verb]:
+		return reject("execute runs only INSERT, UPDATE, DELETE or MERGE statements")
+	}
+	for _, verb := range append([]string{s.verb}, s.writes...) {
+		if len(p.Verbs) > 0 && !p.Verbs[verb] {
+			return reject("%s statements are not allowed", verb)
+		}
+	}
+	if len(p.Tables) > 0 {
+		for _, table := range s.tables {
+			if !p.Tables[table] && !(table == "dual" && s.verb == "SELECT") {
+				return reject("table %s is not allowed", table)
+			}
+		}
+	}
+	if len(p.Procedures) > 0 {
+		for _, call := range s.calls {
+			if !p.Procedures[call] && !sqlBuiltinFunctions[strings.ToUpper(call)] {
+				return reject("function %s is not allowed", call)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckCall checks a statement of the call action, which must call a single
+// procedure: BEGIN proc(args); END; CALL proc(args) or EXEC proc args, with
+// bind parameters and literals as arguments
+func (p *SQLPolicy) CheckCall(stmt string) error {
+	if p == nil {
+		return nil
+	}
+	tokens, err := tokenizeSQL(stmt)
+	if err == nil {
+		var name string
+		if name, err = parseCall(tokens); err == nil {
+			return p.CheckProcedure(name)
+		}
+	}
+	return &SQLPolicyError{Action: "call", Reason: err.Error()}
+}
+
+// CheckProcedure checks that a procedure or function may be called
+func (p *SQLPolicy) CheckProcedure(name string) error {
+	return p.checkProcedure("call", name)
+}
+
+// CheckQueue checks the enqueue or dequeue action on an Oracle AQ queue,
+// which calls DBMS_AQ.ENQUEUE or DBMS_AQ.DEQUEUE
+func (p *SQLPolicy) CheckQueue(action, queue string) error {
+	if p == nil {
+		return nil
+	}
+	if err := p.checkProcedure(action, "DBMS_AQ."+strings.ToUpper(action)); err != nil {
+		return err
+	}
+	if len(p.Queues) > 0 && !p.Queues[strings.ToLower(queue)] {
+		return &SQLPolicyError{Action: action, Reason: fmt.Sprintf("queue %s is not allowed", queue)}
+	}
+	return nil
+}
+
+func (p *SQLPolicy) checkProcedure(action, name string) error {
+	if p == nil || len(p.Procedures) == 0 || p.Procedures[strings.ToLower(name)] {
+		return nil
+	}
+	return &SQLPolicyError{Action: action, Reason: fmt.Sprintf("procedure %s is not allowed", name)}
+}
+
+// Kinds of SQL tokens
+const (
+	sqlWord   = iota // keyword or identifier
+	sqlQuoted        // quoted identifier
+	sqlString
+	sqlNumber
+	sqlParam // {name} placeholder
+	sqlPunct
+)
+
+type sqlToken struct {
+	kind int
+	text string
+}
+
+func (t sqlToken) is(word string) bool {
+	return t.kind == sqlWord && strings.EqualFold(t.text, word)
+}
+
+// sqlOperators are the operators of two characters
+var sqlOperators = map[string]bool{"=>": true, "<=": true, ">=": true, "<>": true, "!=": true, "||": true, "::": true}
+
+// tokenizeSQL splits a statement into tokens. It rejects comments and the
+// backslash escapes and dollar quoting some databases support, so the tokens
+// are what every database parses.
+func tokenizeSQL(s string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(s[i:], "--"), strings.HasPrefix(s[i:], "/*"), r == '#':
+			return nil, fmt.Errorf("comments are not allowed")
+		case r == '\'':
+			end := i + 1
+			for {
+				j := strings.IndexAny(s[end:], `'\`)
+				if j < 0 {
+					return nil, fmt.Errorf("unterminated string literal")
+				}
+				end += j
+				if s[end] == '\\' {
+					return nil, fmt.Errorf("backslashes in string literals are not allowed")
+				}
+				if end+1 < len(s) && s[end+1] == '\'' {
+					end += 2
+					continue
+				}
+				break
+			}
+			tokens = append(tokens, sqlToken{sqlString, s[i+1 : end]})
+			i = end + 1
+		case r == '"' || r == '`' || r == '[':
+			closing := string(r)
+			if r == '[' {
+				closing = "]"
+			}
+			j := strings.Index(s[i+1:], closing)
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated quoted identifier")
+			}
+			if strings.Contains(s[i+1:i+1+j], `\`) {
+				return nil, fmt.Errorf("backslashes in quoted identifiers are not allowed")
+			}
+			tokens = append(tokens, sqlToken{sqlQuoted, s[i+1 : i+1+j]})
+			i += j + 2
+		case r == '{':
+			loc := namedParamPattern.FindStringIndex(s[i:])
+			if loc == nil || loc[0] != 0 {
+				return nil, fmt.Errorf("unexpected {")
+			}
+			tokens = append(tokens, sqlToken{sqlParam, s[i+1 : i+loc[1]-1]})
+			i += loc[1]
+		case unicode.IsLetter(r) || r == '_':
+			end := i + size
+			for end < len(s) {
+				r, size := utf8.DecodeRuneInString(s[end:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' && r != '#' {
+					break
+				}
+				end += size
+			}
+			// Oracle q'[...]' literals end at a delimiter of their choice
+			if end < len(s) && s[end] == '\'' && strings.HasSuffix(strings.ToLower(s[i:end]), "q") {
+				return nil, fmt.Errorf("alternative quoting is not allowed")
+			}
+			tokens = append(tokens, sqlToken{sqlWord, s[i:end]})
+			i = end
+		case r >= '0' && r <= '9' || r == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			end := i + 1
+			for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.' || s[end] == 'e' || s[end] == 'E') {
+				end++
+			}
+			tokens = append(tokens, sqlToken{sqlNumber, s[i:end]})
+			i = end
+		case strings.ContainsRune("(),.;=<>!+-*/%|&^~:@?", r):
+			if i+2 <= len(s) && sqlOperators[s[i:i+2]] {
+				tokens = append(tokens, sqlToken{sqlPunct, s[i : i+2]})
+				i += 2
+				continue
+			}
+			tokens = append(tokens, sqlToken{sqlPunct, string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty statement")
+	}
+	return tokens, nil
+}
+
+// singleStatement returns the tokens without a final semicolon and rejects
+// any other one
+func singleStatement(tokens []sqlToken) ([]sqlToken, error) {
+	for i, t := range tokens {
+		if t.kind == sqlPunct && t.text == ";" {
+			if i != len(tokens)-1 {
+				return nil, fmt.Errorf("multiple statements are not allowed")
+			}
+			return tokens[:i], nil
+		}
+	}
+	return tokens, nil
+}
+
+// sqlStatement is what a statement does: its verb, the verbs of the writes
+// it holds, such as a DELETE in a WITH clause, the tables it names and the
+// functions it calls
+type sqlStatement struct {
+	verb       string
+	writes     []string
+	tables     []string
+	calls      []string
+	selectInto bool
+}
+
+// sqlFrame is the state of a level of parentheses
+type sqlFrame struct {
+	query       bool // a SELECT was seen, so FROM names tables
+	inFrom      bool // in the table list of a FROM clause
+	expectTable bool
+}
+
+// parseSQL finds the verb, writes and tables of a statement
+func parseSQL(tokens []sqlToken) (sqlStatement, error) {
+	var s sqlStatement
+	start, ctes, err := skipWith(tokens)
+	if err != nil {
+		return s, err
+	}
+	if start >= len(tokens) || tokens[start].kind != sqlWord {
+		return s, fmt.Errorf("statement has no verb")
+	}
+	s.verb = strings.ToUpper(tokens[start].text)
+	if !sqlReadVerbs[s.verb] && !sqlWriteVerbs[s.verb] && !sqlDDLVerbs[s.verb] {
+		return s, fmt.Errorf("unsupported statement %s", s.verb)
+	}
+	ddl := sqlDDLVerbs[s.verb]
+
+	stack := []*sqlFrame{{query: true}}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		frame := stack[len(stack)-1]
+		word := ""
+		if t.kind == sqlWord {
+			word = strings.ToUpper(t.text)
+		}
+		previous := ""
+		if i > 0 && tokens[i-1].kind == sqlWord {
+			previous = strings.ToUpper(tokens[i-1].text)
+		}
+
+		// UPDATE also locks rows and sets columns on conflicts, and MERGE
+		// branches are part of the MERGE
+		lock := word == "UPDATE" && (previous == "FOR" || previous == "KEY" || previous == "DO")
+		if sqlWriteVerbs[word] && i != start && !lock && previous != "THEN" {
+			s.writes = append(s.writes, word)
+		}
+		if word == "INTO" && s.verb == "SELECT" && i > start && !(previous == "INSERT" || previous == "MERGE") {
+			s.selectInto = true
+		}
+
+		// A name before a parenthesis calls a function, unless it is the
+		// table of a column list or a common table expression. Column types
+		// make DDL look like calls, and AllowDDL allows any anyway.
+		if name, next, ok := sqlCall(tokens, i); ok && !ddl && !(i < start && len(stack) == 1) &&
+			!(frame.expectTable && (previous == "INTO" || previous == "TABLE")) {
+			s.calls = append(s.calls, name)
+			if !frame.expectTable {
+				i = next - 1
+				continue
+			}
+		}
+
+		switch {
+		case t.kind == sqlPunct && t.text == "(":
+			frame.expectTable = false
+			stack = append(stack, &sqlFrame{})
+		case t.kind == sqlPunct && t.text == ")":
+			if len(stack) == 1 {
+				return s, fmt.Errorf("unbalanced parentheses")
+			}
+			stack = stack[:len(stack)-1]
+		case t.kind == sqlPunct && t.text == ",":
+			frame.expectTable = frame.inFrom
+		case word == "SELECT":
+			frame.query = true
+			frame.inFrom = false
+		case frame.expectTable && (word == "ONLY" || word == "LATERAL"):
+		case frame.expectTable && (t.kind == sqlQuoted || t.kind == sqlWord && !sqlKeywords[word]):
+			name, next := sqlName(tokens, i)
+			if !ctes[name] {
+				s.tables = append(s.tables, name)
+			}
+			frame.expectTable = false
+			i = next - 1
+		case word == "FROM" || word == "JOIN":
+			if frame.query {
+				frame.expectTable = true
+				frame.inFrom = true
+			}
+		case sqlTableMarkers[word] && !lock:
+			frame.expectTable = true
+			frame.inFrom = false
+		case word == "ON" && ddl:
+			frame.expectTable = true
+		case sqlClauseEnds[word]:
+			frame.inFrom = false
+			frame.expectTable = false
+		case sqlKeywords[word]:
+			frame.expectTable = false
+		}
+	}
+	if len(stack) != 1 {
+		return s, fmt.Errorf("unbalanced parentheses")
+	}
+	return s, nil
+}
+
+// sqlName reads the possibly qualified name starting at tokens[i] and returns
+// it in lower case with the index after it
+func sqlName(tokens []sqlToken, i int) (string, int) {
+	parts := []string{strings.ToLower(tokens[i].text)}
+	i++
+	for i+1 < len(tokens) && tokens[i].kind == sqlPunct && tokens[i].text == "." &&
+		(tokens[i+1].kind == sqlWord || tokens[i+1].kind == sqlQuoted) {
+		parts = append(parts, strings.ToLower(tokens[i+1].text))
+		i += 2
+	}
+	return strings.Join(parts, "."), i
+}
+
+// sqlCall returns the function called by the name starting at tokens[i], if
+// a parenthesis follows it, with the index of the parenthesis
+func sqlCall(tokens []sqlToken, i int) (string, int, bool) {
+	t := tokens[i]
+	if t.kind != sqlWord && t.kind != sqlQuoted {
+		return "", 0, false
+	}
+	name, next := sqlName(tokens, i)
+	if next >= len(tokens) || tokens[next].kind != sqlPunct || tokens[next].text != "(" {
+		return "", 0, false
+	}
+	word := strings.ToUpper(t.text)
+	if t.kind == sqlWord && next == i+1 && (sqlKeywords[word] || sqlCallKeywords[word]) {
+		return "", 0, false
+	}
+	return name, next, true
+}
+
+// skipWith returns the index after the WITH clause of a statement, if any,
+// and the names of its common table expressions
+func skipWith(tokens []sqlToken) (int, map[string]bool, error) {
+	ctes := map[string]bool{}
+	if !tokens[0].is("WITH") {
+		return 0, ctes, nil
+	}
+	i := 1
+	if i < len(tokens) && tokens[i].is("RECURSIVE") {
+		i++
+	}
+	for {
+		if i >= len(tokens) || tokens[i].kind != sqlWord && tokens[i].kind != sqlQuoted {
+			return 0, nil, fmt.Errorf("invalid WITH clause")
+		}
+		ctes[strings.ToLower(tokens[i].text)] = true
+		i++
+		if i < len(tokens) && tokens[i].text == "(" {
+			i = skipParens(tokens, i)
+		}
+		if i >= len(tokens) || !tokens[i].is("AS") {
+			return 0, nil, fmt.Errorf("invalid WITH clause")
+		}
+		i++
+		for i < len(tokens) && (tokens[i].is("NOT") || tokens[i].is("MATERIALIZED")) {
+			i++
+		}
+		if i >= len(tokens) || tokens[i].text != "(" {
+			return 0, nil, fmt.Errorf("invalid WITH clause")
+		}
+		i = skipParens(tokens, i)
+		if i < len(tokens) && tokens[i].kind == sqlPunct && tokens[i].text == "," {
+			i++
+			continue
+		}
+		return i, ctes, nil
+	}
+}
+
+// skipParens returns the index after the parenthesis opened at tokens[i]
+func skipParens(tokens []sqlToken, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		if tokens[i].kind != sqlPunct {
+			continue
+		}
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// sqlArgWords and sqlArgPuncts are the words and punctuation allowed among
+// the arguments of a call besides bind parameters and literals
+var (
+	sqlArgWords  = map[string]bool{"NULL": true, "TRUE": true, "FALSE": true, "OUT": true, "OUTPUT": true}
+	sqlArgPuncts = map[string]bool{",": true, "=>": true, "=": true, "@": true, "-": true, "+": true}
+)
+
+// parseCall returns the procedure a call statement calls
+func parseCall(tokens []sqlToken) (string, error) {
+	invalid := fmt.Errorf("only calls of a single procedure are allowed")
+	i := 0
+	block := tokens[0].is("BEGIN")
+	switch {
+	case block, tokens[0].is("CALL"), tokens[0].is("EXEC"), tokens[0].is("EXECUTE"):
+		i++
+	default:
+		return "", invalid
+	}
+	if i >= len(tokens) || tokens[i].kind != sqlWord && tokens[i].kind != sqlQuoted {
+		return "", invalid
+	}
+	name, i := sqlName(tokens, i)
+
+	// Arguments are bind parameters, literals and named notation only
+	parens := i < len(tokens) && tokens[i].text == "("
+	if parens {
+		i++
+	}
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind == sqlPunct && (t.text == ")" || t.text == ";") {
+			break
+		}
+		switch {
+		case t.kind == sqlParam, t.kind == sqlString, t.kind == sqlNumber:
+		case t.kind == sqlPunct && sqlArgPuncts[t.text]:
+		case t.kind == sqlWord && sqlArgWords[strings.ToUpper(t.text)]:
+		case t.kind == sqlWord && i+1 < len(tokens) && (tokens[i+1].text == "=>" || tokens[i+1].text == "="):
+		default:
+			return "", fmt.Errorf("procedure arguments must be bind parameters or literals")
+		}
+	}
+	if parens {
+		if i >= len(tokens) || tokens[i].text != ")" {
+			return "", invalid
+		}
+		i++
+	}
+	if block {
+		if i+1 >= len(tokens) || tokens[i].text != ";" || !tokens[i+1].is("END") {
+			return "", invalid
+		}
+		i += 2
+	}
+	if i < len(tokens) && tokens[i].text == ";" {
+		i++
+	}
+	if i != len(tokens) {
+		return "", invalid
+	}
+	return name, nil
+}
@@ -0,0 +1,141 @@
+package adapter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// GenerateTableMappings produces read, create and update mappings for a table.
+// The generated SQL only ever references values through {name} bind placeholders.
+func GenerateTableMappings(driver, table string, columns []ColumnInfo) ([]config.MappingConfig, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s has no columns", table)
+	}
+
+	var keys, fields []ColumnInfo
+	for _, column := range columns {
+		if column.PrimaryKey {
+			keys = append(keys, column)
+		} else {
+			fields = append(fields, column)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key", table)
+	}
+
+	quotedTable := quoteIdentifier(driver, table)
+	where := make([]string, len(keys))
+	for i, key := range keys {
+		where[i] = fmt.Sprintf("%s = {%s}", quoteIdentifier(driver, key.Name), key.Name)
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	names := make([]string, len(columns))
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = quoteIdentifier(driver, column.Name)
+		values[i] = "{" + column.Name + "}"
+	}
+
+	mappings := []config.MappingConfig{
+		{
+			IntentPattern: tableIntent([]string{"get", "show", "find", "read"}, table),
+			Endpoint:      table,
+			Method:        "query",
+			Params: map[string]interface{}{
+				"query": fmt.Sprintf("SELECT %s FROM %s WHERE %s", strings.Join(names, ", "), quotedTable, whereClause),
+			},
+			ParameterMappings: columnParameters(keys),
+		},
+		{
+			IntentPattern: tableIntent([]string{"create", "add", "insert", "new"}, table),
+			Endpoint:      table,
+			Method:        "execute",
+			Params: map[string]interface{}{
+				"statement": fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quotedTable, strings.Join(names, ", "), strings.Join(values, ", ")),
+			},
+			ParameterMappings: columnParameters(columns),
+		},
+	}
+
+	if len(fields) > 0 {
+		set := make([]string, len(fields))
+		for i, field := range fields {
+			name := quoteIdentifier(driver, field.Name)
+			set[i] = fmt.Sprintf("%s = COALESCE({%s}, %s)", name, field.Name, name)
+		}
+		mappings = append(mappings, config.MappingConfig{
+			IntentPattern: tableIntent([]string{"update", "change", "modify", "edit"}, table),
+			Endpoint:      table,
+			Method:        "execute",
+			Params: map[string]interface{}{
+				"statement": fmt.Sprintf("UPDATE %s SET %s WHERE %s", quotedTable, strings.Join(set, ", "), whereClause),
+			},
+			ParameterMappings: columnParameters(append(append([]ColumnInfo{}, keys...), fields...)),
+		})
+	}
+
+	return mappings, nil
+}
+
+// tableIntent builds an intent pattern matching any of the verbs followed by the table name
+func tableIntent(verbs []string, table string) string {
+	name := strings.ToLower(table)
+	singular := strings.TrimSuffix(name, "s")
+	if singular == "" || singular == name {
+		return fmt.Sprintf("(%s).*%s", strings.Join(verbs, "|"), regexp.QuoteMeta(name))
+	}
+	return fmt.Sprintf("(%s).*%s(s)?\\b", strings.Join(verbs, "|"), regexp.QuoteMeta(singular))
+}
+
+// columnParameters maps text parameters onto bind arguments for the columns
+func columnParameters(columns []ColumnInfo) []config.ParameterMapping {
+	params := make([]config.ParameterMapping, len(columns))
+	for i, column := range columns {
+		params[i] = config.ParameterMapping{
+			Source:  "text",
+			Pattern: `(?i)\b` + regexp.QuoteMeta(column.Name) + `\s*[:=]?\s*([^\s,]+)`,
+			Target:  "args." + column.Name,
+			Type:    ParameterType(column.DataType),
+		}
+	}
+	return params
+}
+
+// ParameterType maps an SQL data type to a parameter mapping type
+func ParameterType(dataType string) string {
+	t := strings.ToLower(dataType)
+	switch {
+	case strings.Contains(t, "bool") || t == "bit":
+		return "bool"
+	case strings.Contains(t, "int") || t == "serial" || t == "bigserial":
+		return "int"
+	case strings.Contains(t, "numeric") || strings.Contains(t, "decimal") || strings.Contains(t, "real") ||
+		strings.Contains(t, "double") || strings.Contains(t, "float") || t == "number" || t == "money":
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// quoteIdentifier quotes a possibly schema-qualified identifier for the driver
+func quoteIdentifier(driver, name string) string {
+	open, close := `"`, `"`
+	switch strings.ToLower(driver) {
+	case "mysql":
+		open, close = "`", "`"
+	case "sqlserver", "mssql":
+		open, close = "[", "]"
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, close, close+close)
+		parts[i] = open + part + close
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,144 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Echo returns its inputs instead of calling a legacy system, to validate a
+// deployment end to end before it is pointed at the real system
+const Echo AdapterType = "echo"
+
+// echoParam is the param overriding the injected delay and error of a call
+const echoParam = "echo"
+
+// EchoAdapter returns the action and params of each call. Delay holds every
+// call and ErrorRate (0 to 1) fails that fraction of calls with Error; the
+// echo param of a call, e.g. {"delay": "2s", "error": "down"}, overrides them.
+type EchoAdapter struct {
+	BaseAdapter
+	Delay       time.Duration
+	ErrorRate   float64
+	Error       string
+	ErrorStatus int
+	Timeouts    Timeouts
+}
+
+// EchoError is an error injected by the echo adapter. With a StatusCode it
+// is retried and trips circuit breakers like an HTTP error of that status.
+type EchoError struct {
+	Message    string
+	StatusCode int
+}
+
+// Error implements the error interface
+func (e *EchoError) Error() string {
+	return e.Message
+}
+
+// Details marks the error as injected
+func (e *EchoError) Details() map[string]interface{} {
+	details := map[string]interface{}{"injected": true}
+	if e.StatusCode > 0 {
+		details["statusCode"] = e.StatusCode
+		details["status"] = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return details
+}
+
+// HTTPStatusCode returns the simulated status code, used to decide on retries
+func (e *EchoError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// NewEchoAdapter creates an echo adapter that neither delays nor fails calls
+func NewEchoAdapter(name string, config map[string]interface{}) *EchoAdapter {
+	base := NewBaseAdapter(name, Echo, "Echo Adapter", config)
+	return &EchoAdapter{BaseAdapter: *base}
+}
+
+// Initialize implements Adapter; there is nothing to connect to
+func (a *EchoAdapter) Initialize() error {
+	return nil
+}
+
+// GetCapabilities returns the capabilities of the echo adapter
+func (a *EchoAdapter) GetCapabilities() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"type":      "echo",
+		"delay":     a.Delay.String(),
+		"errorRate": a.ErrorRate,
+	}, nil
+}
+
+// ExecuteTask echoes a task within the adapter timeouts
+func (a *EchoAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext echoes a task after the injected delay, or fails it
+// with the injected error
+func (a *EchoAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	ctx, cancel, timeouts := withCallTimeouts(ctx, a.Timeouts)
+	defer cancel()
+
+	delay, errorRate, injected := a.Delay, a.ErrorRate, &EchoError{Message: a.Error, StatusCode: a.ErrorStatus}
+	echoed := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		echoed[key] = value
+	}
+	if override, ok := params[echoParam].(map[string]interface{}); ok {
+		delete(echoed, echoParam)
+		if value, ok := override["delay"].(string); ok {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid echo delay %q", value)
+			}
+			delay = d
+		}
+		if value, ok := override["errorRate"].(float64); ok {
+			errorRate = value
+		}
+		if value, ok := override["error"].(string); ok {
+			injected.Message, errorRate = value, 1
+		}
+		if value, ok := override["errorStatus"].(float64); ok {
+			injected.StatusCode = int(value)
+		}
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, &TimeoutError{Phase: "total", Limit: timeouts.Total}
+			}
+			return nil, ctx.Err()
+		}
+	}
+	if errorRate > 0 && rand.Float64() < errorRate {
+		if injected.Message == "" {
+			injected.Message = "injected echo error"
+		}
+		a.Logger().Debug("echo adapter injected an error", "action", action, "error", injected.Message)
+		return nil, injected
+	}
+
+	return map[string]interface{}{
+		"adapter": a.Name,
+		"action":  action,
+		"params":  echoed,
+		"delayMs": milliseconds(delay),
+	}, nil
+}
+
+// Close implements Adapter
+func (a *EchoAdapter) Close() error {
+	return nil
+}
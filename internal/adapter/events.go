@@ -0,0 +1,203 @@
+package adapter
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
+)
+
+// Connection event kinds
+const (
+	EventConnect    = "connect"
+	EventAuth       = "auth"
+	EventPoolGrow   = "pool grow"
+	EventPoolShrink = "pool shrink"
+	EventDisconnect = "disconnect"
+	EventError      = "error"
+)
+
+// DefaultConnEventLogSize is the number of connection events kept per adapter
+const DefaultConnEventLogSize = 200
+
+// DefaultConnEvents receives the connection events of adapters without their
+// own log; the admin API serves it
+var DefaultConnEvents = NewConnEventLog(DefaultConnEventLogSize)
+
+// ConnEvent is a connection lifecycle event of an adapter. Open is the
+// number of connections the adapter has open after the event, where known.
+type ConnEvent struct {
+	Time       time.Time `json:"time"`
+	Adapter    string    `json:"adapter"`
+	Kind       string    `json:"kind"`
+	Address    string    `json:"address,omitempty"`
+	DurationMs float64   `json:"durationMs,omitempty"`
+	Open       int       `json:"open,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// ConnEventLog is a rolling in-memory log of connection events per adapter
+type ConnEventLog struct {
+	mu     sync.Mutex
+	size   int
+	events map[string][]ConnEvent
+}
+
+// NewConnEventLog creates a log keeping the last size events of each adapter
+func NewConnEventLog(size int) *ConnEventLog {
+	if size <= 0 {
+		size = DefaultConnEventLogSize
+	}
+	return &ConnEventLog{size: size, events: make(map[string][]ConnEvent)}
+}
+
+// Record adds an event, dropping the oldest event of its adapter when full
+func (l *ConnEventLog) Record(event ConnEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := append(l.events[event.Adapter], event)
+	if len(events) > l.size {
+		events = append([]ConnEvent(nil), events[len(events)-l.size:]...)
+	}
+	l.events[event.Adapter] = events
+}
+
+// Events returns the events of an adapter, or of all adapters when adapter
+// is empty, newest first
+func (l *ConnEventLog) Events(adapter string) []ConnEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var events []ConnEvent
+	for name, adapterEvents := range l.events {
+		if adapter == "" || name == adapter {
+			events = append(events, adapterEvents...)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.After(events[j].Time)
+	})
+	return events
+}
+
+// Adapters returns the names of the adapters with events, sorted
+func (l *ConnEventLog) Adapters() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := make([]string, 0, len(l.events))
+	for name := range l.events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Clear removes the events of an adapter, or of all adapters when adapter is
+// empty
+func (l *ConnEventLog) Clear(adapter string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if adapter == "" {
+		l.events = make(map[string][]ConnEvent)
+		return
+	}
+	delete(l.events, adapter)
+}
+
+// recordEvent adds a connection event of the adapter to its log
+func (b *BaseAdapter) recordEvent(event ConnEvent) {
+	event.Adapter = b.Name
+	events := b.Events
+	if events == nil {
+		events = DefaultConnEvents
+	}
+	events.Record(event)
+	b.Logger().Debug("connection event", "kind", event.Kind, "address", event.Address, "open", event.Open, "detail", event.Detail, "error", event.Error)
+}
+
+// recordError records a connection-level failure; other errors, such as a
+// failing SQL statement, are not connection events
+func (b *BaseAdapter) recordError(address, detail string, err error) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	var authErr *authclient.AuthError
+	if errors.As(err, &authErr) {
+		b.recordEvent(ConnEvent{Kind: EventAuth, Address: address, Detail: "failed to obtain credentials", Error: err.Error()})
+		return
+	}
+	b.recordEvent(ConnEvent{Kind: EventError, Address: address, Detail: detail, Error: err.Error()})
+}
+
+// trackConn records the connect event of conn and returns it wrapped so that
+// closing it records the disconnect
+func (b *BaseAdapter) trackConn(conn net.Conn, address string, took time.Duration) net.Conn {
+	open := atomic.AddInt64(&b.openConns, 1)
+	b.recordEvent(ConnEvent{Kind: EventConnect, Address: address, DurationMs: milliseconds(took), Open: int(open)})
+	return &trackedConn{Conn: conn, adapter: b, address: address, opened: time.Now()}
+}
+
+// dialTracked connects to address and records the connection's events
+func (b *BaseAdapter) dialTracked(ctx context.Context, address string, timeouts Timeouts) (net.Conn, error) {
+	start := time.Now()
+	conn, err := dialTCP(ctx, address, timeouts)
+	if err != nil {
+		b.recordError(address, "connect", err)
+		return nil, err
+	}
+	return b.trackConn(conn, address, time.Since(start)), nil
+}
+
+// trackedConn records its disconnect event when it is closed
+type trackedConn struct {
+	net.Conn
+	adapter *BaseAdapter
+	address string
+	opened  time.Time
+	once    sync.Once
+}
+
+// Close closes the connection and records the disconnect once
+func (c *trackedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		open := atomic.AddInt64(&c.adapter.openConns, -1)
+		c.adapter.recordEvent(ConnEvent{Kind: EventDisconnect, Address: c.address, DurationMs: milliseconds(time.Since(c.opened)), Open: int(open)})
+	})
+	return err
+}
+
+// recordHTTPResult records transport failures and rejected credentials of an
+// HTTP call
+func (b *BaseAdapter) recordHTTPResult(req *http.Request, resp *http.Response, err error) {
+	if err != nil {
+		b.recordError(req.URL.Host, req.Method+" "+req.URL.Path, err)
+		return
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		b.recordEvent(ConnEvent{Kind: EventAuth, Address: req.URL.Host, Detail: "rejected: " + resp.Status})
+	}
+}
+
+// isConnError reports whether err is a network failure rather than an error
+// reported by the legacy system
+func isConnError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
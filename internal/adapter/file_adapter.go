@@ -1,10 +1,12 @@
 package adapter
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // FileAdapter adapts a file system
@@ -71,7 +73,10 @@ func (a *FileAdapter) readFile(params map[string]interface{}) (map[string]interf
 		return nil, fmt.Errorf("filename parameter is required")
 	}
 	
-	path := filepath.Join(a.BasePath, filename)
+	path, err := a.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
 	content, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -89,13 +94,25 @@ func (a *FileAdapter) writeFile(params map[string]interface{}) (map[string]inter
 		return nil, fmt.Errorf("filename parameter is required")
 	}
 	
-	content, ok := params["content"].(string)
-	if !ok {
-		return nil, fmt.Errorf("content parameter is required")
+	// Binary content, such as an uploaded file, is passed as base64
+	var content []byte
+	if encoded, ok := params["contentBase64"].(string); ok {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("contentBase64 parameter is not valid base64: %w", err)
+		}
+		content = decoded
+	} else if text, ok := params["content"].(string); ok {
+		content = []byte(text)
+	} else {
+		return nil, fmt.Errorf("content or contentBase64 parameter is required")
 	}
 	
-	path := filepath.Join(a.BasePath, filename)
-	err := ioutil.WriteFile(path, []byte(content), 0644)
+	path, err := a.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(path, content, 0644)
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +129,11 @@ func (a *FileAdapter) deleteFile(params map[string]interface{}) (map[string]inte
 		return nil, fmt.Errorf("filename parameter is required")
 	}
 	
-	path := filepath.Join(a.BasePath, filename)
-	err := os.Remove(path)
+	path, err := a.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Remove(path)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +148,10 @@ func (a *FileAdapter) listFiles(params map[string]interface{}) (map[string]inter
     dir := a.BasePath
     
     if dirParam, ok := params["directory"].(string); ok {
-        dir = filepath.Join(a.BasePath, dirParam)
+        var err error
+        if dir, err = a.resolve(dirParam); err != nil {
+            return nil, err
+        }
     }
     
     files, err := ioutil.ReadDir(dir)
@@ -151,4 +174,19 @@ func (a *FileAdapter) listFiles(params map[string]interface{}) (map[string]inter
         "files": fileList,
     }, nil
 }
-    
+
+// resolve returns the path of name under the base path; names that lead
+// outside of it are rejected since they come from task params
+func (a *FileAdapter) resolve(name string) (string, error) {
+	path := filepath.Join(a.BasePath, name)
+	rel, err := filepath.Rel(a.BasePath, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is outside of the base path", name)
+	}
+	return path, nil
+}
+
+// Close implements Adapter
+func (a *FileAdapter) Close() error {
+	return nil
+}
@@ -0,0 +1,305 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/charset"
+	"github.com/A2AGateway/a2a-connector/internal/codec"
+	"github.com/A2AGateway/a2a-connector/internal/xmlmap"
+)
+
+// DetailedError is implemented by errors that carry structured details
+// which should be surfaced to the A2A side alongside the error message
+type DetailedError interface {
+	error
+	Details() map[string]interface{}
+}
+
+// HTTPError is returned when a legacy system responds with a status code
+// outside the configured success ranges
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URL        string
+	Body       map[string]interface{}
+}
+
+// Error implements the error interface
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s %s returned HTTP %s", e.Method, e.URL, e.Status)
+}
+
+// Details returns the status code and parsed response body
+func (e *HTTPError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"statusCode": e.StatusCode,
+		"status":     e.Status,
+		"body":       e.Body,
+	}
+}
+
+// HTTPStatusCode returns the response status code, used to decide on retries
+func (e *HTTPError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// BodyTooLargeError is returned when a body exceeds a configured size limit
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+// Error implements the error interface
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("body exceeds the limit of %d bytes", e.Limit)
+}
+
+// Details returns the limit
+func (e *BodyTooLargeError) Details() map[string]interface{} {
+	return map[string]interface{}{"limit": e.Limit}
+}
+
+// HTTPStatusCode returns 413 so oversized responses are neither retried nor
+// counted as failures of the legacy system
+func (e *BodyTooLargeError) HTTPStatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// readLimited reads r up to limit bytes and fails with a BodyTooLargeError
+// when it holds more; zero means no limit
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err == nil && int64(len(data)) > limit {
+		return nil, &BodyTooLargeError{Limit: limit}
+	}
+	return data, err
+}
+
+// StatusRange is an inclusive range of HTTP status codes
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+// DefaultSuccessStatuses treats all 2xx responses as successful
+var DefaultSuccessStatuses = []StatusRange{{Min: 200, Max: 299}}
+
+// ParseStatusRanges parses status ranges like "200-299", "304", or "2xx"
+func ParseStatusRanges(specs []string) ([]StatusRange, error) {
+	var ranges []StatusRange
+	for _, spec := range specs {
+		spec = strings.ToLower(strings.TrimSpace(spec))
+
+		switch {
+		case len(spec) == 3 && strings.HasSuffix(spec, "xx"):
+			class, err := strconv.Atoi(spec[:1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid status range: %s", spec)
+			}
+			ranges = append(ranges, StatusRange{Min: class * 100, Max: class*100 + 99})
+		case strings.Contains(spec, "-"):
+			parts := strings.SplitN(spec, "-", 2)
+			min, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+			max, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err1 != nil || err2 != nil || min > max {
+				return nil, fmt.Errorf("invalid status range: %s", spec)
+			}
+			ranges = append(ranges, StatusRange{Min: min, Max: max})
+		default:
+			code, err := strconv.Atoi(spec)
+			if err != nil {
+				return nil, fmt.Errorf("invalid status code: %s", spec)
+			}
+			ranges = append(ranges, StatusRange{Min: code, Max: code})
+		}
+	}
+	return ranges, nil
+}
+
+// statusInRanges reports whether a status code is within any of the ranges
+func statusInRanges(code int, ranges []StatusRange) bool {
+	for _, r := range ranges {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// Response formats that override the content type of a response
+const (
+	FormatJSON = "json"
+	FormatXML  = "xml"
+	FormatForm = "form"
+	FormatText = "text"
+)
+
+// formatMediaTypes are the media types of the response formats
+var formatMediaTypes = map[string]string{
+	FormatJSON: "application/json",
+	FormatXML:  "application/xml",
+	FormatForm: "application/x-www-form-urlencoded",
+	FormatText: "text/plain",
+}
+
+// FormatMediaType returns the media type of a response format, or "" for an
+// unknown format
+func FormatMediaType(format string) string {
+	return formatMediaTypes[format]
+}
+
+// parseResponse reads an HTTP response body according to its content type,
+// or according to format when set, for legacy systems that send a wrong or
+// no content type.
+//
+// JSON objects are returned as-is and other JSON values under "data". XML is
+// converted to a map, form-encoded bodies to a map of their fields, text/* is
+// returned under "text", and anything else is base64-encoded under
+// "contentBase64".
+//
+// Text bodies are converted to UTF-8 first, using charsetOverride when set
+// and otherwise the declared or detected charset. Content types with a codec
+// in codecs are decoded by that codec as-is. Bodies over maxBody bytes fail
+// with a BodyTooLargeError; zero means no limit.
+func parseResponse(resp *http.Response, charsetOverride, format string, codecs *codec.Registry, maxBody int64) (map[string]interface{}, error) {
+	body, err := readLimited(resp.Body, maxBody)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	mediaType, mediaParams, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if forced := FormatMediaType(format); forced != "" {
+		mediaType = forced
+	}
+	if c, ok := codecs.Lookup(mediaType); ok && mediaType != "" {
+		result, err := c.Decode(body)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding %s response: %w", mediaType, err)
+		}
+		return result, nil
+	}
+	isXML := mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+	isForm := mediaType == "application/x-www-form-urlencoded"
+	if mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") ||
+		isXML || isForm || strings.HasPrefix(mediaType, "text/") {
+		body, err = toUTF8(body, mediaParams["charset"], charsetOverride, isXML)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			if mediaType == "" {
+				// No content type and not JSON: treat as text
+				return map[string]interface{}{"text": string(body)}, nil
+			}
+			return nil, fmt.Errorf("error decoding JSON response: %w", err)
+		}
+		if obj, ok := value.(map[string]interface{}); ok {
+			return obj, nil
+		}
+		return map[string]interface{}{"data": value}, nil
+
+	case isXML:
+		return xmlmap.Decode(bytes.NewReader(body))
+
+	case isForm:
+		values, err := url.ParseQuery(strings.TrimSpace(string(body)))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding form response: %w", err)
+		}
+		result := make(map[string]interface{}, len(values))
+		for key, list := range values {
+			if len(list) == 1 {
+				result[key] = list[0]
+				continue
+			}
+			items := make([]interface{}, len(list))
+			for i, item := range list {
+				items[i] = item
+			}
+			result[key] = items
+		}
+		return result, nil
+
+	case strings.HasPrefix(mediaType, "text/"):
+		return map[string]interface{}{"text": string(body)}, nil
+
+	default:
+		return map[string]interface{}{
+			"contentType":   mediaType,
+			"contentBase64": base64.StdEncoding.EncodeToString(body),
+		}, nil
+	}
+}
+
+// toUTF8 converts a text body to UTF-8. An override other than "auto" wins;
+// otherwise the declared charset (or XML declaration) and content decide.
+func toUTF8(body []byte, declared, override string, isXML bool) ([]byte, error) {
+	if isXML && declared == "" {
+		declared = charset.XMLDeclaredEncoding(body)
+	}
+
+	name := override
+	if name == "" || strings.EqualFold(name, charset.Auto) {
+		name = charset.Detect(body, declared)
+	}
+	converted, err := charset.ToUTF8(body, name)
+	if err != nil {
+		return nil, err
+	}
+	if isXML {
+		converted = charset.RewriteXMLDeclaration(converted)
+	}
+	return converted, nil
+}
+
+type charsetKey struct{}
+
+// WithCharset returns a context that overrides the charset of the call's response
+func WithCharset(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, charsetKey{}, name)
+}
+
+type formatKey struct{}
+
+// WithResponseFormat returns a context that makes the call's response be
+// parsed as format (json, xml, form or text) whatever its content type
+func WithResponseFormat(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, formatKey{}, format)
+}
+
+// responseFormat returns the response format from ctx, if any
+func responseFormat(ctx context.Context) string {
+	format, _ := ctx.Value(formatKey{}).(string)
+	return format
+}
+
+// responseCharset returns the charset override from ctx, falling back to def
+func responseCharset(ctx context.Context, def string) string {
+	if name, ok := ctx.Value(charsetKey{}).(string); ok && name != "" {
+		return name
+	}
+	return def
+}
@@ -0,0 +1,37 @@
+package adapter
+
+import (
+	"context"
+
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+// LimitedAdapter caps the call rate and concurrency toward the wrapped adapter's backend
+type LimitedAdapter struct {
+	Adapter
+	Limiter *resilience.Limiter
+}
+
+// NewLimitedAdapter wraps an adapter with a rate and concurrency limiter
+func NewLimitedAdapter(inner Adapter, limiter *resilience.Limiter) *LimitedAdapter {
+	return &LimitedAdapter{
+		Adapter: inner,
+		Limiter: limiter,
+	}
+}
+
+// ExecuteTask executes the task once the limiter admits it
+func (a *LimitedAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext executes the task with ctx once the limiter admits it
+func (a *LimitedAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	release, err := a.Limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return ExecuteTaskContext(ctx, a.Adapter, action, params)
+}
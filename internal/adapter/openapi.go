@@ -0,0 +1,390 @@
+package adapter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPISpec is the subset of an OpenAPI 3 document used by the REST adapter
+type OpenAPISpec struct {
+	OpenAPI    string                      `yaml:"openapi" json:"openapi"`
+	Info       OpenAPIInfo                 `yaml:"info" json:"info"`
+	Servers    []OpenAPIServer             `yaml:"servers" json:"servers,omitempty"`
+	Paths      map[string]*OpenAPIPathItem `yaml:"paths" json:"paths"`
+	Components OpenAPIComponents           `yaml:"components" json:"components,omitempty"`
+}
+
+// OpenAPIInfo holds the document metadata
+type OpenAPIInfo struct {
+	Title   string `yaml:"title" json:"title"`
+	Version string `yaml:"version" json:"version"`
+}
+
+// OpenAPIServer describes a server the API is available on
+type OpenAPIServer struct {
+	URL string `yaml:"url" json:"url"`
+}
+
+// OpenAPIComponents holds reusable schemas and parameters
+type OpenAPIComponents struct {
+	Schemas    map[string]*OpenAPISchema    `yaml:"schemas" json:"schemas,omitempty"`
+	Parameters map[string]*OpenAPIParameter `yaml:"parameters" json:"parameters,omitempty"`
+}
+
+// OpenAPIPathItem holds the operations available on a single path
+type OpenAPIPathItem struct {
+	Parameters []*OpenAPIParameter `yaml:"parameters" json:"parameters,omitempty"`
+	Get        *OpenAPIOperation   `yaml:"get" json:"get,omitempty"`
+	Put        *OpenAPIOperation   `yaml:"put" json:"put,omitempty"`
+	Post       *OpenAPIOperation   `yaml:"post" json:"post,omitempty"`
+	Delete     *OpenAPIOperation   `yaml:"delete" json:"delete,omitempty"`
+	Patch      *OpenAPIOperation   `yaml:"patch" json:"patch,omitempty"`
+	Head       *OpenAPIOperation   `yaml:"head" json:"head,omitempty"`
+	Options    *OpenAPIOperation   `yaml:"options" json:"options,omitempty"`
+}
+
+// OpenAPIOperation describes a single API operation
+type OpenAPIOperation struct {
+	OperationID string              `yaml:"operationId" json:"operationId"`
+	Summary     string              `yaml:"summary" json:"summary,omitempty"`
+	Description string              `yaml:"description" json:"description,omitempty"`
+	Parameters  []*OpenAPIParameter `yaml:"parameters" json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody `yaml:"requestBody" json:"requestBody,omitempty"`
+
+	// Method and Path are filled in when the spec is indexed
+	Method string `yaml:"-" json:"-"`
+	Path   string `yaml:"-" json:"-"`
+}
+
+// OpenAPIParameter describes a path, query, or header parameter
+type OpenAPIParameter struct {
+	Ref      string         `yaml:"$ref" json:"$ref,omitempty"`
+	Name     string         `yaml:"name" json:"name"`
+	In       string         `yaml:"in" json:"in"`
+	Required bool           `yaml:"required" json:"required,omitempty"`
+	Schema   *OpenAPISchema `yaml:"schema" json:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody describes the body of an operation
+type OpenAPIRequestBody struct {
+	Required bool                        `yaml:"required" json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `yaml:"content" json:"content,omitempty"`
+}
+
+// OpenAPIMediaType holds the schema for a content type
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `yaml:"schema" json:"schema,omitempty"`
+}
+
+// OpenAPISchema is the subset of JSON Schema used for parameter validation
+type OpenAPISchema struct {
+	Ref        string                    `yaml:"$ref" json:"$ref,omitempty"`
+	Type       string                    `yaml:"type" json:"type,omitempty"`
+	Format     string                    `yaml:"format" json:"format,omitempty"`
+	Enum       []interface{}             `yaml:"enum" json:"enum,omitempty"`
+	Required   []string                  `yaml:"required" json:"required,omitempty"`
+	Properties map[string]*OpenAPISchema `yaml:"properties" json:"properties,omitempty"`
+	Items      *OpenAPISchema            `yaml:"items" json:"items,omitempty"`
+}
+
+// LoadOpenAPISpec loads an OpenAPI 3 document in YAML or JSON format from a URL or file
+func LoadOpenAPISpec(source string) (*OpenAPISpec, error) {
+	var data []byte
+	var err error
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching OpenAPI spec: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			return nil, fmt.Errorf("error fetching OpenAPI spec: HTTP %d", resp.StatusCode)
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading OpenAPI spec: %w", err)
+		}
+	} else {
+		data, err = ioutil.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("error reading OpenAPI spec: %w", err)
+		}
+	}
+
+	return ParseOpenAPISpec(data)
+}
+
+// ParseOpenAPISpec parses an OpenAPI 3 document (JSON is valid YAML, so both are accepted)
+func ParseOpenAPISpec(data []byte) (*OpenAPISpec, error) {
+	var spec OpenAPISpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("error parsing OpenAPI spec: %w", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version: %q, only 3.x is supported", spec.OpenAPI)
+	}
+	return &spec, nil
+}
+
+// Operations returns all operations that have an operationId, sorted by operationId
+func (s *OpenAPISpec) Operations() []*OpenAPIOperation {
+	var operations []*OpenAPIOperation
+
+	for path, item := range s.Paths {
+		if item == nil {
+			continue
+		}
+
+		methods := map[string]*OpenAPIOperation{
+			http.MethodGet:     item.Get,
+			http.MethodPut:     item.Put,
+			http.MethodPost:    item.Post,
+			http.MethodDelete:  item.Delete,
+			http.MethodPatch:   item.Patch,
+			http.MethodHead:    item.Head,
+			http.MethodOptions: item.Options,
+		}
+		for method, op := range methods {
+			if op == nil || op.OperationID == "" {
+				continue
+			}
+			op.Method = method
+			op.Path = path
+
+			// Merge path-level parameters that the operation does not override
+			for _, param := range item.Parameters {
+				param = s.resolveParameter(param)
+				if op.findParameter(param.Name, param.In) == nil {
+					op.Parameters = append(op.Parameters, param)
+				}
+			}
+			for i, param := range op.Parameters {
+				op.Parameters[i] = s.resolveParameter(param)
+			}
+
+			operations = append(operations, op)
+		}
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].OperationID < operations[j].OperationID
+	})
+	return operations
+}
+
+// findParameter finds a parameter by name and location
+func (o *OpenAPIOperation) findParameter(name, in string) *OpenAPIParameter {
+	for _, param := range o.Parameters {
+		if param.Name == name && param.In == in {
+			return param
+		}
+	}
+	return nil
+}
+
+// bodySchema returns the JSON request body schema of the operation, if any
+func (o *OpenAPIOperation) bodySchema() *OpenAPISchema {
+	if o.RequestBody == nil {
+		return nil
+	}
+	if media, ok := o.RequestBody.Content["application/json"]; ok {
+		return media.Schema
+	}
+	for _, media := range o.RequestBody.Content {
+		return media.Schema
+	}
+	return nil
+}
+
+// resolveParameter resolves a #/components/parameters reference
+func (s *OpenAPISpec) resolveParameter(param *OpenAPIParameter) *OpenAPIParameter {
+	if param == nil || param.Ref == "" {
+		return param
+	}
+	name := strings.TrimPrefix(param.Ref, "#/components/parameters/")
+	if resolved, ok := s.Components.Parameters[name]; ok {
+		return resolved
+	}
+	return param
+}
+
+// resolveSchema resolves a #/components/schemas reference
+func (s *OpenAPISpec) resolveSchema(schema *OpenAPISchema) *OpenAPISchema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 10; depth++ {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := s.Components.Schemas[name]
+		if !ok {
+			return schema
+		}
+		schema = resolved
+	}
+	return schema
+}
+
+// validateValue validates a value against a schema
+func (s *OpenAPISpec) validateValue(schema *OpenAPISchema, value interface{}, path string) error {
+	schema = s.resolveSchema(schema)
+	if schema == nil || value == nil {
+		return nil
+	}
+
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, allowed := range schema.Enum {
+			if fmt.Sprintf("%v", allowed) == fmt.Sprintf("%v", value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of %v", path, schema.Enum)
+		}
+	}
+
+	switch schema.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s must be a string", path)
+		}
+	case "integer":
+		switch v := value.(type) {
+		case int, int64:
+		case float64:
+			if v != float64(int64(v)) {
+				return fmt.Errorf("%s must be an integer", path)
+			}
+		case string:
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				return fmt.Errorf("%s must be an integer", path)
+			}
+		default:
+			return fmt.Errorf("%s must be an integer", path)
+		}
+	case "number":
+		switch v := value.(type) {
+		case int, int64, float64:
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return fmt.Errorf("%s must be a number", path)
+			}
+		default:
+			return fmt.Errorf("%s must be a number", path)
+		}
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+		case string:
+			if _, err := strconv.ParseBool(v); err != nil {
+				return fmt.Errorf("%s must be a boolean", path)
+			}
+		default:
+			return fmt.Errorf("%s must be a boolean", path)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an array", path)
+		}
+		for i, item := range items {
+			if err := s.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an object", path)
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s.%s is required", path, name)
+			}
+		}
+		for name, propSchema := range schema.Properties {
+			if err := s.validateValue(propSchema, obj[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// GenerateMappings creates mapping configurations for every operation in the spec.
+// The operationId becomes the mapping method so the REST adapter can dispatch on it,
+// and path/query parameters are extracted from text of the form "name: value".
+func GenerateMappings(spec *OpenAPISpec) []config.MappingConfig {
+	var mappings []config.MappingConfig
+
+	for _, op := range spec.Operations() {
+		mapping := config.MappingConfig{
+			IntentPattern: IntentPattern(op.OperationID),
+			Endpoint:      op.Path,
+			Method:        op.OperationID,
+		}
+
+		for _, param := range op.Parameters {
+			if param.In != "path" && param.In != "query" {
+				continue
+			}
+			mapping.ParameterMappings = append(mapping.ParameterMappings, config.ParameterMapping{
+				Source:  "text",
+				Pattern: `(?i)` + regexp.QuoteMeta(param.Name) + `\s*[:=]?\s*([^\s,]+)`,
+				Target:  param.Name,
+			})
+		}
+
+		mappings = append(mappings, mapping)
+	}
+
+	return mappings
+}
+
+// IntentPattern derives an intent regular expression from an identifier,
+// e.g. getCustomerById becomes "get.*customer.*by.*id"
+func IntentPattern(id string) string {
+	return strings.Join(splitIdentifier(id), ".*")
+}
+
+// splitIdentifier splits a camelCase or snake_case identifier into lower-case words
+func splitIdentifier(id string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	runes := []rune(id)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.' || unicode.IsSpace(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	return words
+}
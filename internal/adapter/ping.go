@@ -0,0 +1,111 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Pinger is implemented by adapters that can check their backend is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks the adapter's backend when the adapter supports it; adapters
+// without a probe are assumed ready
+func Ping(ctx context.Context, a Adapter) error {
+	if pinger, ok := a.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Ping checks the database connection
+func (a *DBAdapter) Ping(ctx context.Context) error {
+	if a.DB == nil {
+		return fmt.Errorf("database %s is not initialized", a.Name)
+	}
+	return a.DB.PingContext(ctx)
+}
+
+// Ping sends a HEAD request to the base URL; server errors mean not ready
+func (a *RESTAdapter) Ping(ctx context.Context) error {
+	return pingURL(ctx, a.HTTPClient, a.BaseURL, a.Headers)
+}
+
+// Ping sends a HEAD request to the SOAP endpoint; server errors mean not ready
+func (a *SOAPAdapter) Ping(ctx context.Context) error {
+	return pingURL(ctx, a.HTTPClient, a.SOAPEndpoint, nil)
+}
+
+// Ping opens a connection to the service
+func (a *TCPAdapter) Ping(ctx context.Context) error {
+	conn, err := a.dial(ctx, a.Timeouts)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Ping opens a connection to the telnet service
+func (a *TelnetAdapter) Ping(ctx context.Context) error {
+	conn, err := dialTCP(ctx, a.Address, a.Timeouts)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Ping checks the base path is accessible
+func (a *FileAdapter) Ping(ctx context.Context) error {
+	_, err := os.Stat(a.BasePath)
+	return err
+}
+
+// Ping checks the wrapped adapter's backend, even while the circuit is open
+func (a *CircuitBreakerAdapter) Ping(ctx context.Context) error {
+	return Ping(ctx, a.Adapter)
+}
+
+// Ping checks the wrapped adapter's backend without taking a limiter slot
+func (a *LimitedAdapter) Ping(ctx context.Context) error {
+	return Ping(ctx, a.Adapter)
+}
+
+// Ping checks the wrapped adapter's backend
+func (a *CachingAdapter) Ping(ctx context.Context) error {
+	return Ping(ctx, a.Adapter)
+}
+
+// Ping checks the wrapped adapter's backend
+func (a *AuditedAdapter) Ping(ctx context.Context) error {
+	return Ping(ctx, a.Adapter)
+}
+
+// pingURL sends a HEAD request to url. Any HTTP response below 500 counts as
+// reachable, since many legacy systems answer HEAD on their root with 404 or 405.
+func pingURL(ctx context.Context, client *http.Client, url string, headers map[string]string) error {
+	if url == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating ping request: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error pinging %s: %w", url, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("ping %s returned %s", url, resp.Status)
+	}
+	return nil
+}
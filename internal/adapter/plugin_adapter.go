@@ -0,0 +1,431 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/adapters/plugin"
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Plugin adapts legacy systems through an adapter binary run as a plugin
+const Plugin AdapterType = "plugin"
+
+// defaultPluginStartTimeout limits how long a plugin takes to complete its
+// handshake, and then to initialize
+const defaultPluginStartTimeout = 10 * time.Second
+
+// pluginStopTimeout is how long a plugin has to exit once closed
+const pluginStopTimeout = 5 * time.Second
+
+// Restarts of a plugin that keeps exiting wait DefaultPluginRestartBackoff,
+// doubled on each exit up to maxPluginRestartBackoff
+const (
+	DefaultPluginRestartBackoff = time.Second
+	maxPluginRestartBackoff     = time.Minute
+)
+
+// PluginAdapter runs an adapter binary, written in any language, and forwards
+// the adapter calls to it. Plugins are started with hashicorp/go-plugin and
+// serve the Adapter service of the plugin package over gRPC: Initialize with
+// the name and config of the adapter, GetCapabilities, and ExecuteTask with
+// the action and params of a task. The output of the plugin is logged. A
+// plugin that exits, or whose connection is lost, is started again on the
+// next call.
+type PluginAdapter struct {
+	BaseAdapter
+	Command string
+	Args    []string
+	// Env holds KEY=value entries added to the connector's environment
+	Env          []string
+	StartTimeout time.Duration
+	// RestartBackoff is the delay before the second restart of a plugin
+	// that keeps exiting; the first restart is immediate
+	RestartBackoff time.Duration
+
+	// Timeouts are the default limits for calls; mappings may override them
+	Timeouts Timeouts
+
+	// mu guards the state below; plugins are started without holding it
+	mu        sync.Mutex
+	running   bool
+	process   *pluginProcess
+	starting  chan struct{}
+	restarts  int
+	restartAt time.Time
+}
+
+// pluginProcess is a started plugin and the connection to it
+type pluginProcess struct {
+	cmd     *exec.Cmd
+	client  *goplugin.Client
+	adapter plugin.AdapterClient
+	address string
+	started time.Time
+}
+
+// exited reports whether the process exited
+func (p *pluginProcess) exited() bool {
+	return p.client.Exited()
+}
+
+// kill stops the process and waits until it exited
+func (p *pluginProcess) kill() {
+	p.cmd.Process.Kill()
+	p.client.Kill()
+}
+
+// NewPluginAdapter creates an adapter running command as a plugin; config is
+// passed to the plugin when it is initialized
+func NewPluginAdapter(name, command string, args []string, config map[string]interface{}) *PluginAdapter {
+	base := NewBaseAdapter(name, Plugin, "Plugin Adapter", config)
+	return &PluginAdapter{
+		BaseAdapter:    *base,
+		Command:        command,
+		Args:           args,
+		RestartBackoff: DefaultPluginRestartBackoff,
+	}
+}
+
+// Initialize starts the plugin, connects to its Adapter service over gRPC and
+// initializes it. A plugin that fails to start is stopped.
+func (a *PluginAdapter) Initialize() error {
+	a.mu.Lock()
+	if a.running {
+		a.mu.Unlock()
+		return nil
+	}
+	a.running, a.restarts, a.restartAt = true, 0, time.Time{}
+	a.mu.Unlock()
+
+	if _, err := a.connect(context.Background()); err != nil {
+		a.mu.Lock()
+		a.running = false
+		a.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// start starts the plugin process and initializes it; the handshake and
+// Initialize are each limited by StartTimeout
+func (a *PluginAdapter) start() (*pluginProcess, error) {
+	timeout := a.StartTimeout
+	if timeout <= 0 {
+		timeout = defaultPluginStartTimeout
+	}
+	cmd := exec.Command(a.Command, a.Args...)
+	cmd.Env = append(os.Environ(), a.Env...)
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  plugin.Handshake,
+		Plugins:          goplugin.PluginSet{plugin.Name: &plugin.GRPCPlugin{}},
+		Cmd:              cmd,
+		SkipHostEnv:      true,
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+		StartTimeout:     timeout,
+		Stderr:           &pluginOutput{adapter: a, stream: "stderr"},
+		SyncStdout:       &pluginOutput{adapter: a, stream: "stdout"},
+		Logger:           a.pluginLogger(),
+	})
+	protocol, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("failed to start plugin %s: %w", a.Command, err)
+	}
+	dispensed, err := protocol.Dispense(plugin.Name)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("plugin %s: %w", a.Command, err)
+	}
+	process := &pluginProcess{cmd: cmd, client: client, adapter: dispensed.(plugin.AdapterClient), started: time.Now()}
+	if reattach := client.ReattachConfig(); reattach != nil && reattach.Addr != nil {
+		process.address = reattach.Addr.String()
+	}
+
+	config, err := toStruct(a.Config)
+	if err != nil {
+		process.kill()
+		return nil, fmt.Errorf("plugin %s: invalid config: %w", a.Command, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := process.adapter.Initialize(ctx, &plugin.InitializeRequest{Name: a.Name, Config: config}); err != nil {
+		process.kill()
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("plugin %s did not initialize within %s", a.Command, timeout)
+		}
+		return nil, fmt.Errorf("plugin %s failed to initialize: %s", a.Command, status.Convert(err).Message())
+	}
+	return process, nil
+}
+
+// pluginOutput logs the lines a plugin writes to one of its outputs
+type pluginOutput struct {
+	adapter *PluginAdapter
+	stream  string
+	line    []byte
+}
+
+func (o *pluginOutput) Write(p []byte) (int, error) {
+	o.line = append(o.line, p...)
+	for {
+		i := bytes.IndexByte(o.line, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		o.adapter.Logger().Info("plugin output", "command", o.adapter.Command, "stream", o.stream, "line", string(o.line[:i]))
+		o.line = o.line[i+1:]
+	}
+}
+
+// pluginLogName is the name of the logger go-plugin logs to; it logs the
+// output of a plugin again to loggers named after it
+const pluginLogName = "plugin"
+
+// pluginLogger returns the logger go-plugin logs to, whose own messages of
+// level info and above go to the logger of the adapter
+func (a *PluginAdapter) pluginLogger() hclog.Logger {
+	logger := hclog.NewInterceptLogger(&hclog.LoggerOptions{Name: pluginLogName, Output: io.Discard})
+	logger.RegisterSink(pluginLog{adapter: a})
+	return logger
+}
+
+// pluginLog forwards the log of go-plugin
+type pluginLog struct {
+	adapter *PluginAdapter
+}
+
+func (l pluginLog) Accept(name string, level hclog.Level, msg string, args ...interface{}) {
+	if name != pluginLogName {
+		// Output of the plugin, which pluginOutput logs
+		return
+	}
+	var slogLevel slog.Level
+	switch level {
+	case hclog.Info:
+		slogLevel = slog.LevelInfo
+	case hclog.Warn:
+		slogLevel = slog.LevelWarn
+	case hclog.Error:
+		slogLevel = slog.LevelError
+	default:
+		return
+	}
+	l.adapter.Logger().Log(context.Background(), slogLevel, msg, append([]interface{}{"command", l.adapter.Command}, args...)...)
+}
+
+// toStruct converts a JSON object, such as the params of a task, to a Struct
+func toStruct(m map[string]interface{}) (*structpb.Struct, error) {
+	result := &structpb.Struct{}
+	if len(m) == 0 {
+		return result, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return result, protojson.Unmarshal(data, result)
+}
+
+// connect returns the running plugin process, starting it again when it
+// exited. The first restart is immediate; while the plugin keeps exiting,
+// restarts wait RestartBackoff, doubled on each exit up to a minute, and calls
+// fail in between. The delay is reset once a plugin ran for a minute. One
+// call starts the plugin while the others wait for it until ctx is done.
+func (a *PluginAdapter) connect(ctx context.Context) (*pluginProcess, error) {
+	a.mu.Lock()
+	for a.starting != nil {
+		starting := a.starting
+		a.mu.Unlock()
+		select {
+		case <-starting:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		a.mu.Lock()
+	}
+	if !a.running {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("plugin %s is not running", a.Command)
+	}
+	if process := a.process; process != nil {
+		if !process.exited() {
+			a.mu.Unlock()
+			return process, nil
+		}
+		process.kill()
+		a.process = nil
+		if time.Since(process.started) >= maxPluginRestartBackoff {
+			a.restarts = 0
+		}
+		a.delayRestart()
+		a.Logger().Warn("plugin exited, restarting it", "command", a.Command, "delay", time.Until(a.restartAt).Round(time.Millisecond))
+	}
+	if wait := time.Until(a.restartAt); wait > 0 {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("plugin %s exited, restarting in %s", a.Command, wait.Round(time.Millisecond))
+	}
+	starting := make(chan struct{})
+	a.starting = starting
+	a.mu.Unlock()
+
+	process, err := a.start()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.starting = nil
+	close(starting)
+	switch {
+	case err != nil:
+		a.delayRestart()
+		return nil, err
+	case !a.running:
+		// Closed while starting
+		process.kill()
+		return nil, fmt.Errorf("plugin %s is not running", a.Command)
+	}
+	a.process = process
+	return process, nil
+}
+
+// delayRestart sets when the plugin may be started again after its latest exit
+func (a *PluginAdapter) delayRestart() {
+	var delay time.Duration
+	if a.restarts > 0 {
+		delay = a.RestartBackoff
+		if delay <= 0 {
+			delay = DefaultPluginRestartBackoff
+		}
+		for i := 1; i < a.restarts && delay < maxPluginRestartBackoff; i++ {
+			delay *= 2
+		}
+		if delay > maxPluginRestartBackoff {
+			delay = maxPluginRestartBackoff
+		}
+	}
+	a.restarts++
+	a.restartAt = time.Now().Add(delay)
+}
+
+// call runs a method of the plugin, giving up when ctx is done. A plugin
+// whose connection is lost is killed, so the next call starts it again.
+func (a *PluginAdapter) call(ctx context.Context, method func(ctx context.Context, client plugin.AdapterClient) error) error {
+	process, err := a.connect(ctx)
+	if err != nil {
+		return err
+	}
+	err = method(ctx, process.adapter)
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	}
+	result := status.Convert(err)
+	switch result.Code() {
+	case codes.Unavailable:
+		a.recordError(process.address, "exchange", err)
+		process.kill()
+		return fmt.Errorf("lost the connection to plugin %s: %s", a.Command, result.Message())
+	case codes.DeadlineExceeded:
+		// The plugin may see the deadline of ctx pass first
+		return context.DeadlineExceeded
+	}
+	return errors.New(result.Message())
+}
+
+// GetCapabilities returns the capabilities the plugin reports, of type plugin
+// unless it names its own
+func (a *PluginAdapter) GetCapabilities() (map[string]interface{}, error) {
+	var capabilities map[string]interface{}
+	err := a.call(context.Background(), func(ctx context.Context, client plugin.AdapterClient) error {
+		resp, err := client.GetCapabilities(ctx, &plugin.GetCapabilitiesRequest{})
+		if err == nil {
+			capabilities = resp.GetCapabilities().AsMap()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := capabilities["type"]; !ok {
+		capabilities["type"] = string(Plugin)
+	}
+	return capabilities, nil
+}
+
+// ExecuteTask executes a task within the adapter timeouts
+func (a *PluginAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext executes a task in the plugin. The deadline of ctx is
+// passed to the plugin, which should give up on the call when it passes.
+func (a *PluginAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	ctx, cancel, timeouts := withCallTimeouts(ctx, a.Timeouts)
+	defer cancel()
+
+	request := &plugin.ExecuteTaskRequest{Action: action}
+	var err error
+	if request.Params, err = toStruct(params); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+	var result map[string]interface{}
+	err = a.call(ctx, func(ctx context.Context, client plugin.AdapterClient) error {
+		resp, err := client.ExecuteTask(ctx, request)
+		if err == nil {
+			result = resp.GetResult().AsMap()
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, &TimeoutError{Phase: "total", Limit: timeouts.Total}
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Close stops the plugin through go-plugin, which asks it to exit; one still
+// running after 5 seconds is killed. A plugin being started is stopped once
+// its start finished.
+func (a *PluginAdapter) Close() error {
+	a.mu.Lock()
+	process, starting := a.process, a.starting
+	a.running, a.process = false, nil
+	a.mu.Unlock()
+	if starting != nil {
+		<-starting
+	}
+	if process == nil {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		process.client.Kill()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(pluginStopTimeout):
+		a.Logger().Warn("plugin did not exit in time, killing it", "command", a.Command)
+		process.kill()
+	}
+	return nil
+}
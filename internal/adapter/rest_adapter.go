@@ -2,9 +2,25 @@ package adapter
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+	"github.com/A2AGateway/a2a-connector/internal/codec"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/tlsclient"
+	"github.com/A2AGateway/a2a-connector/internal/xmlmap"
 )
 
 // RESTAdapter adapts a REST API
@@ -13,27 +29,104 @@ type RESTAdapter struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	Headers    map[string]string
+
+	// SuccessStatuses are the status codes treated as success (default 2xx).
+	// Other responses are returned as *HTTPError.
+	SuccessStatuses []StatusRange
+
+	// Retry retries transient failures; nil sends every request once
+	Retry *resilience.RetryPolicy
+
+	// Timeouts are the default limits for calls; mappings may override them
+	Timeouts Timeouts
+
+	// Charset overrides the charset of responses; empty or "auto" detects it
+	Charset string
+
+	// MaxResponseBody limits the size of response bodies; zero means no limit
+	MaxResponseBody int64
+
+	// Codecs decode responses and encode bodies of proprietary content
+	// types; nil uses codec.Default. A call selects a request codec with
+	// the contentType param.
+	Codecs *codec.Registry
+
+	// Validators stores ETag/Last-Modified per conditional call key; nil
+	// disables conditional requests
+	Validators cache.Cache
+
+	// OpenAPISource is an optional URL or file path of an OpenAPI 3 spec.
+	// When set, operations are derived from the spec and actions are operationIds.
+	OpenAPISource string
+	Spec          *OpenAPISpec
+	operations    map[string]*OpenAPIOperation
 }
 
 // NewRESTAdapter creates a new REST adapter
 func NewRESTAdapter(name, baseURL string, headers map[string]string, config map[string]interface{}) *RESTAdapter {
 	base := NewBaseAdapter(name, REST, "REST API Adapter", config)
-	return &RESTAdapter{
+	a := &RESTAdapter{
 		BaseAdapter: *base,
 		BaseURL:     baseURL,
-		HTTPClient:  &http.Client{},
 		Headers:     headers,
 	}
+	a.HTTPClient = newHTTPClient(&a.BaseAdapter)
+	return a
 }
 
 // Initialize sets up the REST adapter
 func (a *RESTAdapter) Initialize() error {
-	// TODO: Validate base URL and set up auth if needed
+	if a.OpenAPISource != "" {
+		spec, err := LoadOpenAPISpec(a.OpenAPISource)
+		if err != nil {
+			return err
+		}
+		a.SetSpec(spec)
+	}
 	return nil
 }
 
+// SetSpec sets the OpenAPI spec used to derive operations
+func (a *RESTAdapter) SetSpec(spec *OpenAPISpec) {
+	a.Spec = spec
+	a.operations = make(map[string]*OpenAPIOperation)
+	for _, op := range spec.Operations() {
+		a.operations[op.OperationID] = op
+	}
+
+	// Fall back to the first server in the spec if no base URL is configured
+	if a.BaseURL == "" && len(spec.Servers) > 0 {
+		a.BaseURL = strings.TrimSuffix(spec.Servers[0].URL, "/")
+	}
+}
+
 // GetCapabilities returns the capabilities of the REST API
 func (a *RESTAdapter) GetCapabilities() (map[string]interface{}, error) {
+	if a.Spec != nil {
+		ids := make([]string, 0, len(a.operations))
+		for id := range a.operations {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		operations := make([]map[string]interface{}, 0, len(ids))
+		for _, id := range ids {
+			op := a.operations[id]
+			operations = append(operations, map[string]interface{}{
+				"operationId": op.OperationID,
+				"method":      op.Method,
+				"path":        op.Path,
+				"summary":     op.Summary,
+			})
+		}
+		return map[string]interface{}{
+			"type":       "rest",
+			"api":        a.Spec.Info.Title,
+			"version":    a.Spec.Info.Version,
+			"operations": operations,
+		}, nil
+	}
+
 	// TODO: Query API for capabilities or return static capabilities
 	return map[string]interface{}{
 		"type":    "rest",
@@ -41,63 +134,481 @@ func (a *RESTAdapter) GetCapabilities() (map[string]interface{}, error) {
 	}, nil
 }
 
-// ExecuteTask executes a REST request
+// ExecuteTask executes a REST request.
+//
+// With an OpenAPI spec the action is an operationId. Otherwise the action is the
+// endpoint path and may contain {name} placeholders, which are filled from
+// params["pathParams"] or from top-level params. Recognized params:
+//   - method: HTTP method (default GET)
+//   - query: map of query parameters (values may be lists)
+//   - headers: map of per-call headers; an empty value removes the header
+//   - body: JSON request body
+//   - form: map encoded as application/x-www-form-urlencoded
+//   - xml: map in the shape of xmlmap.Decode encoded as application/xml
+//   - textBody: string sent as is, as text/plain unless contentType is set
+//   - multipart: {"fields": map, "files": [{"field", "filename", "content" or "contentBase64", "contentType"}]}
 func (a *RESTAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
-	// Parse action to determine HTTP method and endpoint
-	method := "GET"
-	endpoint := action
-	
-	if m, ok := params["method"].(string); ok {
-		method = m
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext executes a task within the adapter and per-call timeouts
+func (a *RESTAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	ctx, cancel, timeouts := withCallTimeouts(ctx, a.Timeouts)
+	defer cancel()
+
+	result, err := a.execute(ctx, action, params)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, &TimeoutError{Phase: "total", Limit: timeouts.Total}
 	}
-	
-	url := fmt.Sprintf("%s%s", a.BaseURL, endpoint)
-	
-	var req *http.Request
-	var err error
-	
-	if method == "GET" {
-		req, err = http.NewRequest(method, url, nil)
-	} else {
-		// Prepare request body for non-GET requests
-		body, err := json.Marshal(params["body"])
+	return result, err
+}
+
+// execute builds and sends the request for an action
+func (a *RESTAdapter) execute(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	if op, ok := a.operations[action]; ok {
+		return a.executeOperation(ctx, op, params)
+	}
+
+	req, err := a.buildRequest(action, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Per-call headers
+	headers := make(map[string]string)
+	if callHeaders, ok := params["headers"].(map[string]interface{}); ok {
+		for key, value := range callHeaders {
+			headers[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	return a.do(ctx, req, headers)
+}
+
+// buildRequest builds an HTTP request from an action and its params
+func (a *RESTAdapter) buildRequest(action string, params map[string]interface{}) (*http.Request, error) {
+	method := http.MethodGet
+	if m, ok := params["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+
+	// Render path template
+	pathParams, _ := params["pathParams"].(map[string]interface{})
+	endpoint, err := renderPathTemplate(action, pathParams, params)
+	if err != nil {
+		return nil, err
+	}
+
+	targetURL := a.BaseURL + endpoint
+	if query, ok := params["query"].(map[string]interface{}); ok && len(query) > 0 {
+		separator := "?"
+		if strings.Contains(targetURL, "?") {
+			separator = "&"
+		}
+		targetURL += separator + encodeQuery(query).Encode()
+	}
+
+	// Build the body
+	var body io.Reader
+	contentType := ""
+
+	switch {
+	case params["multipart"] != nil:
+		multipartParams, ok := params["multipart"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("multipart parameter must be an object")
+		}
+		buf, ct, err := encodeMultipart(multipartParams)
+		if err != nil {
+			return nil, err
+		}
+		body, contentType = buf, ct
+	case params["form"] != nil:
+		form, ok := params["form"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("form parameter must be an object")
+		}
+		body = strings.NewReader(encodeQuery(form).Encode())
+		contentType = "application/x-www-form-urlencoded"
+	case params["xml"] != nil:
+		doc, ok := params["xml"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("xml parameter must be an object")
+		}
+		data, err := xmlmap.Encode(doc)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/xml"
+	case params["textBody"] != nil:
+		text, ok := params["textBody"].(string)
+		if !ok {
+			return nil, fmt.Errorf("textBody parameter must be a string")
+		}
+		body = strings.NewReader(text)
+		contentType = "text/plain; charset=utf-8"
+		if ct, ok := params["contentType"].(string); ok && ct != "" {
+			contentType = ct
+		}
+	case params["contentType"] != nil && method != http.MethodGet && method != http.MethodHead:
+		contentType, _ = params["contentType"].(string)
+		c, ok := a.codecs().Lookup(contentType)
+		if !ok {
+			return nil, fmt.Errorf("no codec registered for content type %q", contentType)
+		}
+		value, _ := params["body"].(map[string]interface{})
+		data, err := c.Encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s body: %w", contentType, err)
+		}
+		body = bytes.NewReader(data)
+	case method != http.MethodGet && method != http.MethodHead:
+		// Prepare JSON request body for non-GET requests
+		data, err := json.Marshal(params["body"])
 		if err != nil {
 			return nil, err
 		}
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(body))
+		body = bytes.NewBuffer(data)
+		contentType = "application/json"
 	}
-	
+
+	req, err := http.NewRequest(method, targetURL, body)
 	if err != nil {
 		return nil, err
 	}
-	
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// renderPathTemplate replaces {name} placeholders with URL-escaped values
+func renderPathTemplate(template string, pathParams, params map[string]interface{}) (string, error) {
+	result := template
+	for {
+		start := strings.Index(result, "{")
+		if start == -1 {
+			return result, nil
+		}
+		end := strings.Index(result[start:], "}")
+		if end == -1 {
+			return "", fmt.Errorf("unterminated placeholder in path: %s", template)
+		}
+		end += start
+
+		name := result[start+1 : end]
+		value, ok := pathParams[name]
+		if !ok {
+			value, ok = params[name]
+		}
+		if !ok || value == nil {
+			return "", fmt.Errorf("missing value for path parameter %s", name)
+		}
+
+		result = result[:start] + url.PathEscape(fmt.Sprintf("%v", value)) + result[end+1:]
+	}
+}
+
+// encodeQuery converts a params map to URL values, expanding lists into repeated keys
+func encodeQuery(params map[string]interface{}) url.Values {
+	values := url.Values{}
+	for key, value := range params {
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				values.Add(key, fmt.Sprintf("%v", item))
+			}
+		case []string:
+			for _, item := range v {
+				values.Add(key, item)
+			}
+		case nil:
+			values.Set(key, "")
+		default:
+			values.Set(key, fmt.Sprintf("%v", v))
+		}
+	}
+	return values
+}
+
+// encodeMultipart builds a multipart/form-data body from fields and files
+func encodeMultipart(params map[string]interface{}) (*bytes.Buffer, string, error) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+
+	if fields, ok := params["fields"].(map[string]interface{}); ok {
+		for key, value := range fields {
+			if err := writer.WriteField(key, fmt.Sprintf("%v", value)); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	files, _ := params["files"].([]interface{})
+	for i, f := range files {
+		file, ok := f.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("multipart file %d must be an object", i)
+		}
+
+		field, _ := file["field"].(string)
+		if field == "" {
+			field = "file"
+		}
+		filename, _ := file["filename"].(string)
+		if filename == "" {
+			return nil, "", fmt.Errorf("multipart file %d is missing filename", i)
+		}
+
+		var content []byte
+		if encoded, ok := file["contentBase64"].(string); ok {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, "", fmt.Errorf("multipart file %s has invalid base64 content: %w", filename, err)
+			}
+			content = decoded
+		} else if text, ok := file["content"].(string); ok {
+			content = []byte(text)
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(field), escapeQuotes(filename)))
+		if ct, ok := file["contentType"].(string); ok && ct != "" {
+			header.Set("Content-Type", ct)
+		} else {
+			header.Set("Content-Type", "application/octet-stream")
+		}
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf, writer.FormDataContentType(), nil
+}
+
+// escapeQuotes escapes a value for use in a Content-Disposition header
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
+
+// executeOperation executes an OpenAPI operation, validating params against its schemas
+func (a *RESTAdapter) executeOperation(ctx context.Context, op *OpenAPIOperation, params map[string]interface{}) (map[string]interface{}, error) {
+	path := op.Path
+	query := url.Values{}
+	headers := make(map[string]string)
+	used := make(map[string]bool)
+
+	for _, param := range op.Parameters {
+		value, ok := params[param.Name]
+		if !ok || value == nil {
+			if param.Required {
+				return nil, fmt.Errorf("parameter %s is required for operation %s", param.Name, op.OperationID)
+			}
+			continue
+		}
+		if err := a.Spec.validateValue(param.Schema, value, param.Name); err != nil {
+			return nil, fmt.Errorf("invalid parameter for operation %s: %w", op.OperationID, err)
+		}
+		used[param.Name] = true
+
+		strValue := fmt.Sprintf("%v", value)
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(strValue))
+		case "query":
+			query.Set(param.Name, strValue)
+		case "header":
+			headers[param.Name] = strValue
+		}
+	}
+
+	var body []byte
+	if op.RequestBody != nil {
+		// Use an explicit body if given, otherwise the params not consumed above
+		bodyValue, ok := params["body"]
+		if !ok {
+			remaining := make(map[string]interface{})
+			for key, value := range params {
+				if !used[key] {
+					remaining[key] = value
+				}
+			}
+			if len(remaining) > 0 {
+				bodyValue = remaining
+			}
+		}
+
+		if bodyValue == nil {
+			if op.RequestBody.Required {
+				return nil, fmt.Errorf("request body is required for operation %s", op.OperationID)
+			}
+		} else {
+			if err := a.Spec.validateValue(op.bodySchema(), bodyValue, "body"); err != nil {
+				return nil, fmt.Errorf("invalid request body for operation %s: %w", op.OperationID, err)
+			}
+			var err error
+			body, err = json.Marshal(bodyValue)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	targetURL := a.BaseURL + path
+	if len(query) > 0 {
+		targetURL += "?" + query.Encode()
+	}
+
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequest(op.Method, targetURL, bytes.NewBuffer(body))
+	} else {
+		req, err = http.NewRequest(op.Method, targetURL, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return a.do(ctx, req, headers)
+}
+
+// do sends a request with the adapter headers and parses the response.
+// Per-call headers override the adapter defaults; empty ones remove them.
+func (a *RESTAdapter) do(ctx context.Context, req *http.Request, headers map[string]string) (map[string]interface{}, error) {
+	req = req.WithContext(ctx)
+
+
 	// Set headers
 	for key, value := range a.Headers {
 		req.Header.Set(key, value)
 	}
+	for key, value := range headers {
+		if value == "" {
+			req.Header.Del(key)
+			continue
+		}
+		req.Header.Set(key, value)
+	}
 	
 	// Set content type if not already set
 	if req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
 	
-	// Execute request
+	// Execute request, retrying transient failures
+	var result map[string]interface{}
+	attempt := 0
+	err := a.Retry.DoContext(ctx, func() error {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		attempt++
+
+		var err error
+		result, err = a.send(attemptReq)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// send executes a single request and parses the response
+func (a *RESTAdapter) send(req *http.Request) (result map[string]interface{}, err error) {
+	timeouts, _ := TimeoutsFromContext(req.Context())
+	req, done := withReadTimeout(req, timeouts.Read)
+	defer func() { err = done(err) }()
+
+	// Make reads conditional on the validators of the previous response
+	conditionalKey, conditional := ConditionalKeyFromContext(req.Context())
+	conditional = conditional && a.Validators != nil && (req.Method == http.MethodGet || req.Method == http.MethodHead)
+	var validators Validators
+	if conditional {
+		var found bool
+		if validators, found = loadValidators(a.Validators, conditionalKey); found {
+			req = req.Clone(req.Context())
+			setConditionalHeaders(req, validators)
+		}
+	}
+
 	resp, err := a.HTTPClient.Do(req)
+	a.recordHTTPResult(req, resp, err)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	
-	// Parse response
-	var result map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&result)
+
+	if conditional && resp.StatusCode == http.StatusNotModified {
+		return UnchangedResult(validators), nil
+	}
+
+	// Parse response based on its content type
+	result, err = parseResponse(resp, responseCharset(req.Context(), a.Charset), responseFormat(req.Context()), a.codecs(), a.MaxResponseBody)
+
+	successStatuses := a.SuccessStatuses
+	if len(successStatuses) == 0 {
+		successStatuses = DefaultSuccessStatuses
+	}
+	if !statusInRanges(resp.StatusCode, successStatuses) {
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Body:       result,
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	
+	if conditional {
+		storeValidators(a.Validators, conditionalKey, resp.Header)
+	}
+
 	return result, nil
 }
 
+// codecs returns the codec registry of the adapter
+func (a *RESTAdapter) codecs() *codec.Registry {
+	if a.Codecs != nil {
+		return a.Codecs
+	}
+	return codec.Default
+}
+
+// SetTLSConfig sets the TLS used toward the legacy system, e.g. a private CA
+// or a client certificate; nil keeps the default
+func (a *RESTAdapter) SetTLSConfig(tlsConfig *tls.Config) error {
+	return tlsclient.Apply(a.HTTPClient, tlsConfig)
+}
+
+// SetAuth adds credentials from p to every call, e.g. OAuth2 access tokens;
+// call it after SetTLSConfig
+func (a *RESTAdapter) SetAuth(p authclient.Provider) {
+	authclient.Apply(a.HTTPClient, p)
+}
+
 // Close cleans up resources
 func (a *RESTAdapter) Close() error {
 	// Nothing to clean up for HTTP client
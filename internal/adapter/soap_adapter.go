@@ -2,10 +2,19 @@ package adapter
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"sort"
 	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/tlsclient"
 )
 
 // SOAPAdapter adapts a SOAP service
@@ -15,37 +24,121 @@ type SOAPAdapter struct {
 	SOAPEndpoint string
 	HTTPClient  *http.Client
 	Namespace   string
+	Operations  []string
+
+	// Retry retries transient failures; nil sends every request once
+	Retry *resilience.RetryPolicy
+
+	// Timeouts are the default limits for calls; mappings may override them
+	Timeouts Timeouts
+
+	// Charset overrides the charset of responses; empty or "auto" detects it
+	Charset string
+
+	// MaxResponseBody limits the size of response bodies; zero means no limit
+	MaxResponseBody int64
 }
 
 // NewSOAPAdapter creates a new SOAP adapter
 func NewSOAPAdapter(name, wsdlURL, soapEndpoint, namespace string, config map[string]interface{}) *SOAPAdapter {
 	base := NewBaseAdapter(name, SOAP, "SOAP Service Adapter", config)
-	return &SOAPAdapter{
+	a := &SOAPAdapter{
 		BaseAdapter:  *base,
 		WSDLURL:      wsdlURL,
 		SOAPEndpoint: soapEndpoint,
-		HTTPClient:   &http.Client{},
 		Namespace:    namespace,
 	}
+	a.HTTPClient = newHTTPClient(&a.BaseAdapter)
+	return a
 }
 
 // Initialize sets up the SOAP adapter
 func (a *SOAPAdapter) Initialize() error {
-	// TODO: Parse WSDL to get operations
+	if a.WSDLURL == "" {
+		return nil
+	}
+
+	// Parse WSDL to get operations
+	resp, err := a.HTTPClient.Get(a.WSDLURL)
+	if err != nil {
+		return fmt.Errorf("error fetching WSDL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("error fetching WSDL: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading WSDL: %w", err)
+	}
+
+	wsdl, err := ParseWSDL(data)
+	if err != nil {
+		return err
+	}
+	a.Operations = wsdl.Operations
+	if a.Namespace == "" {
+		a.Namespace = wsdl.TargetNamespace
+	}
 	return nil
 }
 
 // GetCapabilities returns the capabilities of the SOAP service
 func (a *SOAPAdapter) GetCapabilities() (map[string]interface{}, error) {
-	// TODO: Return operations from WSDL
 	return map[string]interface{}{
 		"type":       "soap",
-		"operations": []string{"operation1", "operation2"},
+		"operations": a.Operations,
 	}, nil
 }
 
+// WSDLInfo is the information extracted from a WSDL document
+type WSDLInfo struct {
+	TargetNamespace string
+	Operations      []string
+}
+
+// ParseWSDL extracts the target namespace and operation names from a WSDL 1.1 document
+func ParseWSDL(data []byte) (*WSDLInfo, error) {
+	var definitions struct {
+		TargetNamespace string `xml:"targetNamespace,attr"`
+		PortTypes       []struct {
+			Operations []struct {
+				Name string `xml:"name,attr"`
+			} `xml:"operation"`
+		} `xml:"portType"`
+	}
+
+	if err := xml.Unmarshal(data, &definitions); err != nil {
+		return nil, fmt.Errorf("error parsing WSDL: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	info := &WSDLInfo{TargetNamespace: definitions.TargetNamespace}
+	for _, portType := range definitions.PortTypes {
+		for _, op := range portType.Operations {
+			if op.Name != "" && !seen[op.Name] {
+				seen[op.Name] = true
+				info.Operations = append(info.Operations, op.Name)
+			}
+		}
+	}
+	sort.Strings(info.Operations)
+
+	return info, nil
+}
+
 // ExecuteTask executes a SOAP request
 func (a *SOAPAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext executes a SOAP request within the adapter and per-call timeouts
+func (a *SOAPAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	ctx, cancel, timeouts := withCallTimeouts(ctx, a.Timeouts)
+	defer cancel()
+
 	// Create SOAP envelope
 	soapEnvelope := fmt.Sprintf(`
 		<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ns="%s">
@@ -58,11 +151,36 @@ func (a *SOAPAdapter) ExecuteTask(action string, params map[string]interface{})
 		</soapenv:Envelope>
 	`, a.Namespace, action, a.paramsToXML(params), action)
 	
+	var body []byte
+	err := a.Retry.DoContext(ctx, func() error {
+		var err error
+		body, err = a.send(ctx, action, soapEnvelope)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &TimeoutError{Phase: "total", Limit: timeouts.Total}
+		}
+		return nil, err
+	}
+	
+	// TODO: Parse XML response to map
+	return map[string]interface{}{
+		"raw_response": string(body),
+	}, nil
+}
+
+// send posts a SOAP envelope and returns the response body
+func (a *SOAPAdapter) send(ctx context.Context, action, soapEnvelope string) (body []byte, err error) {
 	// Create request
-	req, err := http.NewRequest("POST", a.SOAPEndpoint, bytes.NewBufferString(soapEnvelope))
+	req, err := http.NewRequestWithContext(ctx, "POST", a.SOAPEndpoint, bytes.NewBufferString(soapEnvelope))
 	if err != nil {
 		return nil, err
 	}
+
+	timeouts, _ := TimeoutsFromContext(ctx)
+	req, done := withReadTimeout(req, timeouts.Read)
+	defer func() { err = done(err) }()
 	
 	// Set headers
 	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
@@ -70,21 +188,36 @@ func (a *SOAPAdapter) ExecuteTask(action string, params map[string]interface{})
 	
 	// Execute request
 	resp, err := a.HTTPClient.Do(req)
+	a.recordHTTPResult(req, resp, err)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	
-	// Read response
-	body, err := ioutil.ReadAll(resp.Body)
+	// Read response and convert it to UTF-8
+	body, err = readLimited(resp.Body, a.MaxResponseBody)
 	if err != nil {
 		return nil, err
 	}
-	
-	// TODO: Parse XML response to map
-	return map[string]interface{}{
-		"raw_response": string(body),
-	}, nil
+	_, mediaParams, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	body, err = toUTF8(body, mediaParams["charset"], responseCharset(ctx, a.Charset), true)
+	if err != nil {
+		return nil, err
+	}
+
+	// SOAP faults arrive as HTTP 500 and are returned as the response; other
+	// error statuses are reported so transient ones can be retried
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusInternalServerError {
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Body:       map[string]interface{}{"text": string(body)},
+		}
+	}
+
+	return body, nil
 }
 
 // paramsToXML converts a map to XML
@@ -98,6 +231,18 @@ func (a *SOAPAdapter) paramsToXML(params map[string]interface{}) string {
 	return result.String()
 }
 
+// SetTLSConfig sets the TLS used toward the legacy system, e.g. a private CA
+// or a client certificate; nil keeps the default
+func (a *SOAPAdapter) SetTLSConfig(tlsConfig *tls.Config) error {
+	return tlsclient.Apply(a.HTTPClient, tlsConfig)
+}
+
+// SetAuth adds credentials from p to every call, e.g. OAuth2 access tokens;
+// call it after SetTLSConfig
+func (a *SOAPAdapter) SetAuth(p authclient.Provider) {
+	authclient.Apply(a.HTTPClient, p)
+}
+
 // Close cleans up resources
 func (a *SOAPAdapter) Close() error {
 	// Nothing to clean up
@@ -0,0 +1,330 @@
+package adapter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/codec"
+)
+
+// TCP adapts legacy services speaking a bespoke protocol over raw TCP
+const TCP AdapterType = "tcp"
+
+// Framing modes of socket messages
+const (
+	FramingLength    = "length"
+	FramingDelimiter = "delimiter"
+	FramingFixed     = "fixed"
+)
+
+// maxFrameSize rejects length prefixes that would exhaust memory
+const maxFrameSize = 16 << 20
+
+// Framing splits a TCP stream into messages
+type Framing struct {
+	// Mode is length (big-endian length prefix), delimiter or fixed
+	Mode string
+	// LengthBytes is the size of the length prefix: 1, 2 or 4
+	LengthBytes int
+	// Delimiter terminates each message
+	Delimiter []byte
+	// RecordSize is the size of every message in fixed mode
+	RecordSize int
+}
+
+// WriteFrame writes payload as one message
+func (f Framing) WriteFrame(w io.Writer, payload []byte) error {
+	var frame []byte
+	switch f.Mode {
+	case FramingLength:
+		if uint64(len(payload)) >= uint64(1)<<(8*uint(f.LengthBytes)) {
+			return fmt.Errorf("message of %d bytes exceeds the %d-byte length prefix", len(payload), f.LengthBytes)
+		}
+		prefix := make([]byte, 4)
+		binary.BigEndian.PutUint32(prefix, uint32(len(payload)))
+		frame = append(prefix[4-f.LengthBytes:], payload...)
+	case FramingDelimiter:
+		if bytes.Contains(payload, f.Delimiter) {
+			return fmt.Errorf("message contains the delimiter")
+		}
+		frame = append(append([]byte(nil), payload...), f.Delimiter...)
+	case FramingFixed:
+		if len(payload) != f.RecordSize {
+			return fmt.Errorf("message of %d bytes does not match the record size %d", len(payload), f.RecordSize)
+		}
+		frame = payload
+	default:
+		return fmt.Errorf("unsupported framing %q", f.Mode)
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadFrame reads one message
+func (f Framing) ReadFrame(r *bufio.Reader) ([]byte, error) {
+	switch f.Mode {
+	case FramingLength:
+		prefix := make([]byte, 4)
+		if _, err := io.ReadFull(r, prefix[4-f.LengthBytes:]); err != nil {
+			return nil, err
+		}
+		size := binary.BigEndian.Uint32(prefix)
+		if size > maxFrameSize {
+			return nil, fmt.Errorf("message of %d bytes exceeds the limit", size)
+		}
+		payload := make([]byte, size)
+		_, err := io.ReadFull(r, payload)
+		return payload, err
+	case FramingDelimiter:
+		var payload []byte
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			payload = append(payload, b)
+			if bytes.HasSuffix(payload, f.Delimiter) {
+				return payload[:len(payload)-len(f.Delimiter)], nil
+			}
+			if len(payload) > maxFrameSize {
+				return nil, fmt.Errorf("message exceeds the limit without a delimiter")
+			}
+		}
+	case FramingFixed:
+		payload := make([]byte, f.RecordSize)
+		_, err := io.ReadFull(r, payload)
+		return payload, err
+	default:
+		return nil, fmt.Errorf("unsupported framing %q", f.Mode)
+	}
+}
+
+// TCPAdapter sends each task as one request message and reads one response
+// message. Messages are encoded by Codec, JSON when nil.
+type TCPAdapter struct {
+	BaseAdapter
+	Address string
+	Framing Framing
+	Codec   codec.Codec
+
+	// Timeouts are the default limits for calls; mappings may override them
+	Timeouts Timeouts
+
+	// Persistent keeps one connection open for all calls, which are then
+	// sent one at a time. Otherwise each call uses its own connection.
+	Persistent bool
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewTCPAdapter creates a new TCP socket adapter
+func NewTCPAdapter(name, address string, framing Framing, config map[string]interface{}) *TCPAdapter {
+	base := NewBaseAdapter(name, TCP, "TCP Socket Adapter", config)
+	return &TCPAdapter{
+		BaseAdapter: *base,
+		Address:     address,
+		Framing:     framing,
+	}
+}
+
+// Initialize checks the framing settings
+func (a *TCPAdapter) Initialize() error {
+	switch a.Framing.Mode {
+	case FramingLength:
+		if a.Framing.LengthBytes == 0 {
+			a.Framing.LengthBytes = 4
+		}
+		if a.Framing.LengthBytes != 1 && a.Framing.LengthBytes != 2 && a.Framing.LengthBytes != 4 {
+			return fmt.Errorf("length prefix must be 1, 2 or 4 bytes")
+		}
+	case FramingDelimiter:
+		if len(a.Framing.Delimiter) == 0 {
+			a.Framing.Delimiter = []byte("\n")
+		}
+	case FramingFixed:
+		if a.Framing.RecordSize <= 0 {
+			return fmt.Errorf("fixed framing requires a record size")
+		}
+	default:
+		return fmt.Errorf("unsupported framing %q", a.Framing.Mode)
+	}
+	return nil
+}
+
+// GetCapabilities returns the capabilities of the socket adapter
+func (a *TCPAdapter) GetCapabilities() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"type":       "tcp",
+		"address":    a.Address,
+		"framing":    a.Framing.Mode,
+		"persistent": a.Persistent,
+	}, nil
+}
+
+// ExecuteTask executes a task within the adapter timeouts
+func (a *TCPAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext sends the body param (or all params) as the request
+// message and decodes the response message
+func (a *TCPAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	ctx, cancel, timeouts := withCallTimeouts(ctx, a.Timeouts)
+	defer cancel()
+
+	message, ok := params["body"].(map[string]interface{})
+	if !ok {
+		message = params
+	}
+	payload, err := a.encode(message)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	response, err := a.roundTrip(ctx, payload, timeouts)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &TimeoutError{Phase: "total", Limit: timeouts.Total}
+		}
+		return nil, err
+	}
+	result, err := a.decode(response)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	return result, nil
+}
+
+// roundTrip writes a request message and reads the response. A persistent
+// connection that turns out to be broken is replaced once.
+func (a *TCPAdapter) roundTrip(ctx context.Context, payload []byte, timeouts Timeouts) ([]byte, error) {
+	if !a.Persistent {
+		conn, err := a.dial(ctx, timeouts)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		response, err := a.exchange(ctx, conn, bufio.NewReader(conn), payload, timeouts)
+		if err != nil && isConnError(err) {
+			a.recordError(a.Address, "exchange", err)
+		}
+		return response, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for attempt := 0; ; attempt++ {
+		reused := a.conn != nil
+		if !reused {
+			conn, err := a.dial(ctx, timeouts)
+			if err != nil {
+				return nil, err
+			}
+			a.conn, a.reader = conn, bufio.NewReader(conn)
+		}
+
+		response, err := a.exchange(ctx, a.conn, a.reader, payload, timeouts)
+		if err == nil {
+			return response, nil
+		}
+		// The stream position is unknown after a failure
+		if isConnError(err) {
+			a.recordError(a.Address, "exchange", err)
+		}
+		a.conn.Close()
+		a.conn, a.reader = nil, nil
+		if !reused || attempt > 0 || ctx.Err() != nil || isTimeout(err) {
+			return nil, err
+		}
+	}
+}
+
+// exchange sends one request message and reads one response message on conn
+func (a *TCPAdapter) exchange(ctx context.Context, conn net.Conn, reader *bufio.Reader, payload []byte, timeouts Timeouts) ([]byte, error) {
+	deadline, _ := ctx.Deadline()
+	conn.SetWriteDeadline(deadline)
+	if err := a.Framing.WriteFrame(conn, payload); err != nil {
+		return nil, err
+	}
+
+	readDeadline := deadline
+	if timeouts.Read > 0 {
+		if limit := time.Now().Add(timeouts.Read); readDeadline.IsZero() || limit.Before(readDeadline) {
+			readDeadline = limit
+		}
+	}
+	conn.SetReadDeadline(readDeadline)
+
+	// Unblock the read when the context is canceled
+	stop := context.AfterFunc(ctx, func() { conn.SetReadDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	response, err := a.Framing.ReadFrame(reader)
+	if err != nil && isTimeout(err) && timeouts.Read > 0 && ctx.Err() == nil {
+		return nil, &TimeoutError{Phase: "read", Limit: timeouts.Read}
+	}
+	return response, err
+}
+
+func (a *TCPAdapter) dial(ctx context.Context, timeouts Timeouts) (net.Conn, error) {
+	return a.dialTracked(ctx, a.Address, timeouts)
+}
+
+// dialTCP connects to address within the connect timeout
+func dialTCP(ctx context.Context, address string, timeouts Timeouts) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeouts.Connect, KeepAlive: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil && isTimeout(err) && timeouts.Connect > 0 && ctx.Err() == nil {
+		return nil, &TimeoutError{Phase: "connect", Limit: timeouts.Connect}
+	}
+	return conn, err
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (a *TCPAdapter) encode(message map[string]interface{}) ([]byte, error) {
+	if a.Codec != nil {
+		return a.Codec.Encode(message)
+	}
+	return json.Marshal(message)
+}
+
+func (a *TCPAdapter) decode(payload []byte) (map[string]interface{}, error) {
+	if a.Codec != nil {
+		return a.Codec.Decode(payload)
+	}
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return nil, err
+	}
+	if obj, ok := value.(map[string]interface{}); ok {
+		return obj, nil
+	}
+	return map[string]interface{}{"data": value}, nil
+}
+
+// Close closes the persistent connection
+func (a *TCPAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conn == nil {
+		return nil
+	}
+	err := a.conn.Close()
+	a.conn, a.reader = nil, nil
+	return err
+}
@@ -0,0 +1,402 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Telnet drives legacy applications that are only reachable through
+// interactive telnet menus
+const Telnet AdapterType = "telnet"
+
+// defaultExpectTimeout limits waiting for output when no read timeout is set,
+// so a script that expects the wrong text does not hang the task
+const defaultExpectTimeout = 30 * time.Second
+
+// Telnet protocol bytes (RFC 854) and the options the adapter accepts
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+
+	telnetOptEcho = 1
+	telnetOptSGA  = 3
+)
+
+// ansiPattern matches terminal escape sequences used to draw menus
+var ansiPattern = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|[()][0-9A-Za-z]|[=>78])`)
+
+// TelnetStep sends a line and waits for output matching Expect, or the
+// prompt when Expect is nil. The output received before the match is stored
+// in the task result under Capture.
+type TelnetStep struct {
+	Send    string
+	Expect  *regexp.Regexp
+	Capture string
+}
+
+// CompileTelnetSteps compiles configured expect/send steps
+func CompileTelnetSteps(cfgs []config.TelnetStepConfig) ([]TelnetStep, error) {
+	steps := make([]TelnetStep, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		step := TelnetStep{Send: cfg.Send, Capture: cfg.Capture}
+		if cfg.Expect != "" {
+			re, err := regexp.Compile(cfg.Expect)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: invalid expect pattern: %w", i, err)
+			}
+			step.Expect = re
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// TelnetAdapter runs a scripted telnet session per task: it logs in, runs
+// the mapping's script (the "script" param) or sends the action as a single
+// command, and returns the output captured along the way
+type TelnetAdapter struct {
+	BaseAdapter
+	Address string
+	// Prompt marks that the application waits for input
+	Prompt *regexp.Regexp
+	// Login runs after connecting, before every script
+	Login []TelnetStep
+	// Logout is sent before disconnecting, e.g. "exit"
+	Logout string
+	// LineEnding is appended to each line sent, "\r\n" by default
+	LineEnding string
+
+	// Timeouts are the default limits for calls; Read limits each wait for
+	// expected output
+	Timeouts Timeouts
+}
+
+// NewTelnetAdapter creates a new telnet adapter
+func NewTelnetAdapter(name, address string, prompt *regexp.Regexp, config map[string]interface{}) *TelnetAdapter {
+	base := NewBaseAdapter(name, Telnet, "Telnet Adapter", config)
+	return &TelnetAdapter{
+		BaseAdapter: *base,
+		Address:     address,
+		Prompt:      prompt,
+		LineEnding:  "\r\n",
+	}
+}
+
+// Initialize checks the adapter can detect the prompt
+func (a *TelnetAdapter) Initialize() error {
+	if a.Prompt == nil {
+		return fmt.Errorf("telnet adapter requires a prompt pattern")
+	}
+	return nil
+}
+
+// GetCapabilities returns the capabilities of the telnet adapter
+func (a *TelnetAdapter) GetCapabilities() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"type":    "telnet",
+		"address": a.Address,
+	}, nil
+}
+
+// ExecuteTask executes a task within the adapter timeouts
+func (a *TelnetAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteTaskContext(context.Background(), action, params)
+}
+
+// ExecuteTaskContext runs one telnet session. {name} placeholders in the
+// script are replaced with params. The result holds the output of the last
+// step under "output" and captured outputs under their names.
+func (a *TelnetAdapter) ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	ctx, cancel, timeouts := withCallTimeouts(ctx, a.Timeouts)
+	defer cancel()
+
+	script, err := a.script(action, params)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := a.dialTracked(ctx, a.Address, timeouts)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &TimeoutError{Phase: "total", Limit: timeouts.Total}
+		}
+		return nil, err
+	}
+	defer conn.Close()
+
+	session := &telnetSession{ctx: ctx, conn: conn, adapter: a, timeouts: timeouts, answered: make(map[[2]byte]bool)}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	result := make(map[string]interface{})
+	if _, err := session.run(a.Login, result); err != nil {
+		a.recordEvent(ConnEvent{Kind: EventAuth, Address: a.Address, Detail: "login failed", Error: err.Error()})
+		return nil, a.sessionError(ctx, "login", err, timeouts)
+	}
+	if len(a.Login) > 0 {
+		a.recordEvent(ConnEvent{Kind: EventAuth, Address: a.Address, Detail: "logged in"})
+	}
+	output, err := session.run(script, result)
+	if err != nil {
+		if isConnError(err) {
+			a.recordError(a.Address, "script", err)
+		}
+		return nil, a.sessionError(ctx, "script", err, timeouts)
+	}
+	if a.Logout != "" {
+		session.send(a.Logout)
+	}
+
+	result["output"] = output
+	return result, nil
+}
+
+// Close is a no-op; each task uses its own session
+func (a *TelnetAdapter) Close() error {
+	return nil
+}
+
+// script returns the steps of a task with placeholders replaced
+func (a *TelnetAdapter) script(action string, params map[string]interface{}) ([]TelnetStep, error) {
+	raw, ok := params["script"].([]interface{})
+	if !ok {
+		if action == "" {
+			return nil, fmt.Errorf("telnet task requires a script or a command")
+		}
+		return []TelnetStep{{Send: action}}, nil
+	}
+
+	cfgs := make([]config.TelnetStepConfig, 0, len(raw))
+	for i, item := range raw {
+		step, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("script step %d must be an object", i)
+		}
+		var cfg config.TelnetStepConfig
+		cfg.Send, _ = step["send"].(string)
+		cfg.Expect, _ = step["expect"].(string)
+		cfg.Capture, _ = step["capture"].(string)
+
+		var missing string
+		cfg.Send = namedParamPattern.ReplaceAllStringFunc(cfg.Send, func(placeholder string) string {
+			name := placeholder[1 : len(placeholder)-1]
+			value, ok := params[name]
+			if !ok || value == nil {
+				missing = name
+				return placeholder
+			}
+			return fmt.Sprint(value)
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("script step %d: missing value for %s", i, missing)
+		}
+		cfgs = append(cfgs, cfg)
+	}
+	return CompileTelnetSteps(cfgs)
+}
+
+// sessionError reports the total timeout when the call ran out of time
+func (a *TelnetAdapter) sessionError(ctx context.Context, phase string, err error, timeouts Timeouts) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return &TimeoutError{Phase: "total", Limit: timeouts.Total}
+	}
+	return fmt.Errorf("telnet %s failed: %w", phase, err)
+}
+
+// telnetSession is one connection; it answers option negotiation and keeps
+// the output not yet consumed by an expect
+type telnetSession struct {
+	ctx      context.Context
+	conn     net.Conn
+	adapter  *TelnetAdapter
+	timeouts Timeouts
+
+	pending  []byte
+	state    int
+	command  byte
+	answered map[[2]byte]bool
+}
+
+// Parser states for telnet commands embedded in the output
+const (
+	telnetStateData = iota
+	telnetStateIAC
+	telnetStateOption
+	telnetStateSub
+	telnetStateSubIAC
+)
+
+// run executes steps and returns the output of the last one
+func (s *telnetSession) run(steps []TelnetStep, result map[string]interface{}) (string, error) {
+	var output string
+	for i, step := range steps {
+		if step.Send != "" {
+			if err := s.send(step.Send); err != nil {
+				return "", fmt.Errorf("step %d: %w", i, err)
+			}
+		}
+		expect := step.Expect
+		if expect == nil {
+			expect = s.adapter.Prompt
+		}
+		if expect == nil {
+			continue
+		}
+		var err error
+		output, err = s.expect(expect)
+		if err != nil {
+			return "", fmt.Errorf("step %d: %w", i, err)
+		}
+		output = stripEcho(output, step.Send)
+		if step.Capture != "" {
+			result[step.Capture] = output
+		}
+	}
+	return output, nil
+}
+
+// send writes a line; text already ending in a line break is sent as is,
+// so "\r" presses Enter
+func (s *telnetSession) send(text string) error {
+	if !strings.HasSuffix(text, "\r") && !strings.HasSuffix(text, "\n") {
+		text += s.adapter.LineEnding
+	}
+	// A literal 0xFF byte must be doubled so it is not read as a command
+	data := []byte(strings.ReplaceAll(text, "\xff", "\xff\xff"))
+	_, err := s.conn.Write(data)
+	return err
+}
+
+// expect reads until the output matches re and returns the text before the match
+func (s *telnetSession) expect(re *regexp.Regexp) (string, error) {
+	limit := s.timeouts.Read
+	if limit <= 0 {
+		limit = defaultExpectTimeout
+	}
+	deadline := time.Now().Add(limit)
+	if d, ok := s.ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := s.ctx.Err(); err != nil {
+		return "", err
+	}
+	s.conn.SetReadDeadline(deadline)
+
+	buf := make([]byte, 4096)
+	for {
+		text := cleanTelnetOutput(s.pending)
+		if loc := re.FindStringIndex(text); loc != nil {
+			s.pending = []byte(text[loc[1]:])
+			return text[:loc[0]], nil
+		}
+
+		n, err := s.conn.Read(buf)
+		s.receive(buf[:n])
+		if err != nil {
+			if isTimeout(err) {
+				err = &TimeoutError{Phase: "read", Limit: limit}
+			}
+			return "", fmt.Errorf("waiting for %q (last output %q): %w", re, tail(text, 80), err)
+		}
+	}
+}
+
+// receive appends data to the pending output and answers telnet commands.
+// Echo and suppress-go-ahead are accepted, every other option refused.
+func (s *telnetSession) receive(data []byte) {
+	for _, b := range data {
+		switch s.state {
+		case telnetStateData:
+			if b == telnetIAC {
+				s.state = telnetStateIAC
+			} else if b != 0 {
+				s.pending = append(s.pending, b)
+			}
+		case telnetStateIAC:
+			switch b {
+			case telnetIAC:
+				s.pending = append(s.pending, b)
+				s.state = telnetStateData
+			case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+				s.command = b
+				s.state = telnetStateOption
+			case telnetSB:
+				s.state = telnetStateSub
+			default:
+				s.state = telnetStateData
+			}
+		case telnetStateOption:
+			s.negotiate(s.command, b)
+			s.state = telnetStateData
+		case telnetStateSub:
+			if b == telnetIAC {
+				s.state = telnetStateSubIAC
+			}
+		case telnetStateSubIAC:
+			if b == telnetSE {
+				s.state = telnetStateData
+			} else {
+				s.state = telnetStateSub
+			}
+		}
+	}
+}
+
+func (s *telnetSession) negotiate(command, option byte) {
+	var reply byte
+	switch command {
+	case telnetWILL:
+		reply = telnetDONT
+		if option == telnetOptEcho || option == telnetOptSGA {
+			reply = telnetDO
+		}
+	case telnetDO:
+		reply = telnetWONT
+		if option == telnetOptSGA {
+			reply = telnetWILL
+		}
+	default:
+		return
+	}
+	// Answer each request once so the peers do not loop
+	key := [2]byte{command, option}
+	if s.answered[key] {
+		return
+	}
+	s.answered[key] = true
+	s.conn.Write([]byte{telnetIAC, reply, option})
+}
+
+// cleanTelnetOutput removes terminal escapes and normalizes line breaks
+func cleanTelnetOutput(data []byte) string {
+	text := ansiPattern.ReplaceAllString(string(data), "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "")
+}
+
+// stripEcho removes the echoed input line from the start of output
+func stripEcho(output, sent string) string {
+	output = strings.TrimLeft(output, "\n")
+	if sent = strings.TrimRight(sent, "\r\n"); sent != "" && strings.HasPrefix(output, sent) {
+		output = output[len(sent):]
+	}
+	return strings.TrimSpace(output)
+}
+
+func tail(s string, n int) string {
+	if len(s) > n {
+		return s[len(s)-n:]
+	}
+	return s
+}
@@ -0,0 +1,195 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// ContextExecutor is implemented by adapters that honor context deadlines and timeouts
+type ContextExecutor interface {
+	ExecuteTaskContext(ctx context.Context, action string, params map[string]interface{}) (map[string]interface{}, error)
+}
+
+// ExecuteTaskContext runs a task with ctx when the adapter supports it,
+// falling back to ExecuteTask otherwise
+func ExecuteTaskContext(ctx context.Context, a Adapter, action string, params map[string]interface{}) (map[string]interface{}, error) {
+	if executor, ok := a.(ContextExecutor); ok {
+		return executor.ExecuteTaskContext(ctx, action, params)
+	}
+	return a.ExecuteTask(action, params)
+}
+
+// Timeouts limits the phases of a call to a legacy system; zero means no limit
+type Timeouts struct {
+	// Connect limits establishing a connection
+	Connect time.Duration
+	// Read limits waiting for the response once the request is sent
+	Read time.Duration
+	// Total limits the whole call, including retries
+	Total time.Duration
+}
+
+// Merge returns t with the non-zero values of override applied
+func (t Timeouts) Merge(override Timeouts) Timeouts {
+	if override.Connect > 0 {
+		t.Connect = override.Connect
+	}
+	if override.Read > 0 {
+		t.Read = override.Read
+	}
+	if override.Total > 0 {
+		t.Total = override.Total
+	}
+	return t
+}
+
+// TimeoutsFromConfig parses timeout durations from config
+func TimeoutsFromConfig(cfg config.TimeoutConfig) (Timeouts, error) {
+	var t Timeouts
+	for _, field := range []struct {
+		value string
+		dst   *time.Duration
+	}{
+		{cfg.Connect, &t.Connect},
+		{cfg.Read, &t.Read},
+		{cfg.Total, &t.Total},
+	} {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			return Timeouts{}, fmt.Errorf("invalid timeout %q: %w", field.value, err)
+		}
+		*field.dst = d
+	}
+	return t, nil
+}
+
+type timeoutsKey struct{}
+
+// WithTimeouts returns a context carrying per-call timeouts, e.g. from a mapping
+func WithTimeouts(ctx context.Context, t Timeouts) context.Context {
+	return context.WithValue(ctx, timeoutsKey{}, t)
+}
+
+// TimeoutsFromContext returns the per-call timeouts carried by ctx
+func TimeoutsFromContext(ctx context.Context) (Timeouts, bool) {
+	t, ok := ctx.Value(timeoutsKey{}).(Timeouts)
+	return t, ok
+}
+
+// withCallTimeouts resolves the timeouts of a call and applies the total limit to ctx
+func withCallTimeouts(ctx context.Context, defaults Timeouts) (context.Context, context.CancelFunc, Timeouts) {
+	timeouts := defaults
+	if override, ok := TimeoutsFromContext(ctx); ok {
+		timeouts = timeouts.Merge(override)
+	}
+	ctx = WithTimeouts(ctx, timeouts)
+
+	if timeouts.Total > 0 {
+		ctx, cancel := context.WithTimeout(ctx, timeouts.Total)
+		return ctx, cancel, timeouts
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel, timeouts
+}
+
+// TimeoutError reports that a phase of a call exceeded its limit
+type TimeoutError struct {
+	Phase string
+	Limit time.Duration
+}
+
+// Error implements the error interface
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timeout after %s", e.Phase, e.Limit)
+}
+
+// Timeout marks the error as a timeout so it is treated like network timeouts
+func (e *TimeoutError) Timeout() bool { return true }
+
+// Temporary implements net.Error
+func (e *TimeoutError) Temporary() bool { return true }
+
+// newHTTPClient creates an HTTP client whose dialer honors the connect
+// timeout carried by the request context and records the connect and
+// disconnect events of b
+func newHTTPClient(b *BaseAdapter) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if t, ok := TimeoutsFromContext(ctx); ok && t.Connect > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, t.Connect)
+			defer cancel()
+
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil && ctx.Err() == context.DeadlineExceeded {
+				return nil, &TimeoutError{Phase: "connect", Limit: t.Connect}
+			}
+			return conn, err
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	// Each new connection grows the transport's pool and is recorded
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return b.trackConn(conn, addr, time.Since(start)), nil
+	}
+	return &http.Client{Transport: transport}
+}
+
+// withReadTimeout cancels the request when no response arrives within limit
+// after it was written. The returned function must be called once the
+// response has been consumed; it reports the read timeout if it fired.
+func withReadTimeout(req *http.Request, limit time.Duration) (*http.Request, func(error) error) {
+	if limit <= 0 {
+		return req, func(err error) error { return err }
+	}
+
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timeoutErr := &TimeoutError{Phase: "read", Limit: limit}
+
+	var mu sync.Mutex
+	var timer *time.Timer
+	stop := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			timer = time.AfterFunc(limit, func() { cancel(timeoutErr) })
+		},
+		GotFirstResponseByte: stop,
+	}
+
+	done := func(err error) error {
+		stop()
+		if err != nil && context.Cause(ctx) == timeoutErr {
+			err = timeoutErr
+		}
+		cancel(nil)
+		return err
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(ctx, trace)), done
+}
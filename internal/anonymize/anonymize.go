@@ -0,0 +1,229 @@
+// Package anonymize replaces personal data in recorded traffic and task
+// fixtures with fake data of the same shape, so production-shaped test data
+// can be shared with development teams
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	mathrand "math/rand"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+)
+
+// Rule kinds
+const (
+	KindFake   = "fake"
+	KindEmail  = "email"
+	KindName   = "name"
+	KindRedact = "redact"
+)
+
+// EmailDomain is the domain of fake email addresses, reserved for examples
+const EmailDomain = "example.com"
+
+var firstNames = []string{
+	"Alex", "Blair", "Casey", "Dana", "Eden", "Finley", "Gray", "Harper", "Indy", "Jordan",
+	"Kai", "Logan", "Morgan", "Noel", "Oakley", "Parker", "Quinn", "Riley", "Sage", "Taylor",
+}
+
+var lastNames = []string{
+	"Abbott", "Baker", "Carter", "Dawson", "Ellis", "Foster", "Garcia", "Hughes", "Ingram", "Jensen",
+	"Keller", "Lambert", "Moreno", "Nolan", "Owens", "Porter", "Reyes", "Sutton", "Turner", "Walsh",
+}
+
+// Anonymizer applies the anonymization rules of a config
+type Anonymizer struct {
+	rules []config.AnonymizeRule
+	key   []byte
+}
+
+// New creates an anonymizer from config; it returns nil when there are no
+// rules. Without a seed the fake data differs between anonymizers.
+func New(cfg *config.AnonymizeConfig) *Anonymizer {
+	if cfg == nil || len(cfg.Rules) == 0 {
+		return nil
+	}
+	a := &Anonymizer{rules: cfg.Rules, key: []byte(cfg.Seed)}
+	if cfg.Seed == "" {
+		a.key = make([]byte, 32)
+		rand.Read(a.key)
+	}
+	return a
+}
+
+// Value returns a copy of a decoded JSON or YAML document with the rules
+// applied; a nil anonymizer returns v unchanged
+func (a *Anonymizer) Value(v interface{}) interface{} {
+	if a == nil {
+		return v
+	}
+	return a.walk(nil, v, nil)
+}
+
+// JSON anonymizes any value that encodes to JSON, such as log entries, and
+// returns the anonymized document
+func (a *Anonymizer) JSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return a.Value(doc), nil
+}
+
+// walk anonymizes v found at path; matched holds the field rules that
+// matched a parent, which apply to everything below it
+func (a *Anonymizer) walk(path []string, v interface{}, matched []config.AnonymizeRule) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			childPath := append(path[:len(path):len(path)], key)
+			result[key] = a.walk(childPath, child, append(matched[:len(matched):len(matched)], a.fieldRules(childPath)...))
+		}
+		return result
+	case []interface{}:
+		// Array elements are at the path of the array
+		result := make([]interface{}, len(value))
+		for i, child := range value {
+			result[i] = a.walk(path, child, matched)
+		}
+		return result
+	case string:
+		return a.apply(value, matched)
+	case int:
+		return a.number(strconv.Itoa(value), matched, func(s string) (interface{}, error) { return strconv.Atoi(s) })
+	case float64:
+		return a.number(strconv.FormatFloat(value, 'f', -1, 64), matched, func(s string) (interface{}, error) { return strconv.ParseFloat(s, 64) })
+	}
+	return v
+}
+
+// fieldRules returns the rules whose field matches the end of path
+func (a *Anonymizer) fieldRules(path []string) []config.AnonymizeRule {
+	var rules []config.AnonymizeRule
+	for _, rule := range a.rules {
+		if rule.Field != "" && matchPath(strings.Split(rule.Field, "."), path) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// matchPath reports whether path ends with the segments of field
+func matchPath(field, path []string) bool {
+	if len(field) > len(path) {
+		return false
+	}
+	path = path[len(path)-len(field):]
+	for i, segment := range field {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// apply replaces s, or the matches of the rule pattern within it, for the
+// field rules that matched and for rules without a field
+func (a *Anonymizer) apply(s string, matched []config.AnonymizeRule) string {
+	for _, rule := range matched {
+		s = a.replace(s, rule)
+	}
+	for _, rule := range a.rules {
+		if rule.Field == "" {
+			s = a.replace(s, rule)
+		}
+	}
+	return s
+}
+
+func (a *Anonymizer) replace(s string, rule config.AnonymizeRule) string {
+	if rule.Compiled == nil {
+		return a.fake(s, rule.Kind)
+	}
+	return rule.Compiled.ReplaceAllStringFunc(s, func(match string) string {
+		return a.fake(match, rule.Kind)
+	})
+}
+
+// number anonymizes a number as its text and keeps it a number when the fake
+// data is one, e.g. after a fake rule
+func (a *Anonymizer) number(s string, matched []config.AnonymizeRule, parse func(string) (interface{}, error)) interface{} {
+	original, _ := parse(s)
+	faked := a.apply(s, matched)
+	if faked == s {
+		return original
+	}
+	if n, err := parse(faked); err == nil {
+		return n
+	}
+	return faked
+}
+
+// fake returns the fake data of kind for s; the same s always gives the
+// same fake data
+func (a *Anonymizer) fake(s, kind string) string {
+	if s == "" {
+		return s
+	}
+	rng := a.rng(kind, s)
+	switch kind {
+	case KindRedact:
+		return secrets.Mask
+	case KindEmail:
+		local := s
+		if at := strings.LastIndex(s, "@"); at >= 0 {
+			local = s[:at]
+		}
+		return strings.ToLower(preserveFormat(local, rng)) + "@" + EmailDomain
+	case KindName:
+		name := firstNames[rng.Intn(len(firstNames))]
+		if len(strings.Fields(s)) > 1 {
+			name += " " + lastNames[rng.Intn(len(lastNames))]
+		}
+		return name
+	}
+	return preserveFormat(s, rng)
+}
+
+// rng returns a random source seeded by the keyed hash of the value
+func (a *Anonymizer) rng(kind, s string) *mathrand.Rand {
+	mac := hmac.New(sha256.New, a.key)
+	fmt.Fprintf(mac, "%s\x00%s", kind, s)
+	return mathrand.New(mathrand.NewSource(int64(binary.BigEndian.Uint64(mac.Sum(nil)))))
+}
+
+// preserveFormat replaces digits with digits and letters with letters of the
+// same case, keeping separators, so phone numbers, IDs and dates keep their
+// shape. A leading non-zero digit stays non-zero so numbers keep their length.
+func preserveFormat(s string, rng *mathrand.Rand) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '1' && r <= '9' && i == 0:
+			b.WriteByte(byte('1' + rng.Intn(9)))
+		case unicode.IsDigit(r):
+			b.WriteByte(byte('0' + rng.Intn(10)))
+		case unicode.IsUpper(r):
+			b.WriteByte(byte('A' + rng.Intn(26)))
+		case unicode.IsLetter(r):
+			b.WriteByte(byte('a' + rng.Intn(26)))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
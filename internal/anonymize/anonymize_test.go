@@ -0,0 +1,82 @@
+package anonymize_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/anonymize"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+)
+
+func TestAnonymizer(t *testing.T) {
+	cfg := &config.AnonymizeConfig{
+		Seed: "s3cret-seed",
+		Rules: []config.AnonymizeRule{
+			{Field: "customer.name", Kind: "name"},
+			{Field: "email", Kind: "email"},
+			{Field: "*.phone"},
+			{Field: "card", Kind: "redact"},
+			{Field: "accountId"},
+			{Pattern: `\b\d{3}-\d{2}-\d{4}\b`},
+		},
+	}
+	if err := (&config.ConnectorConfig{Anonymize: cfg}).Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	anon := anonymize.New(cfg)
+
+	doc := map[string]interface{}{
+		"customer": map[string]interface{}{
+			"name":  "Ada Lovelace",
+			"email": "ada.lovelace@corp.io",
+			"phone": "+44 (20) 7946-0958",
+		},
+		"orders": []interface{}{
+			map[string]interface{}{"email": "ada.lovelace@corp.io", "card": "4111 1111 1111 1111", "accountId": 8675309.0},
+		},
+		"text":   "my SSN is 123-45-6789, please update",
+		"status": "active",
+	}
+	got := anon.Value(doc).(map[string]interface{})
+	customer := got["customer"].(map[string]interface{})
+	order := got["orders"].([]interface{})[0].(map[string]interface{})
+
+	if name := customer["name"].(string); name == "Ada Lovelace" || len(strings.Fields(name)) != 2 {
+		t.Errorf("Expected a fake full name, got %q", name)
+	}
+	email := customer["email"].(string)
+	if !regexp.MustCompile(`^[a-z]{3}\.[a-z]{8}@example\.com$`).MatchString(email) {
+		t.Errorf("Expected a format-preserving fake email, got %q", email)
+	}
+	// The same value is replaced the same way, wherever it occurs
+	if order["email"] != email {
+		t.Errorf("Expected %q, got %q", email, order["email"])
+	}
+	if phone := customer["phone"].(string); phone == "+44 (20) 7946-0958" || !regexp.MustCompile(`^\+\d\d \(\d\d\) \d{4}-\d{4}$`).MatchString(phone) {
+		t.Errorf("Expected a fake phone number of the same format, got %q", phone)
+	}
+	if order["card"] != secrets.Mask {
+		t.Errorf("Expected the card to be redacted, got %v", order["card"])
+	}
+	if id, ok := order["accountId"].(float64); !ok || id == 8675309 || id < 1000000 || id > 9999999 {
+		t.Errorf("Expected a fake seven digit number, got %v", order["accountId"])
+	}
+	text := got["text"].(string)
+	if strings.Contains(text, "123-45-6789") || !regexp.MustCompile(`^my SSN is \d{3}-\d{2}-\d{4}, please update$`).MatchString(text) {
+		t.Errorf("Expected the pattern to be replaced in the text, got %q", text)
+	}
+	if got["status"] != "active" || doc["text"] != "my SSN is 123-45-6789, please update" {
+		t.Error("Expected other values and the original document to be unchanged")
+	}
+
+	// Another seed gives other fake data; no rules, no anonymizer
+	cfg.Seed = "other"
+	if other := anonymize.New(cfg).Value(doc).(map[string]interface{}); other["customer"].(map[string]interface{})["email"] == email {
+		t.Error("Expected different fake data for another seed")
+	}
+	if anonymize.New(&config.AnonymizeConfig{}) != nil {
+		t.Error("Expected no anonymizer without rules")
+	}
+}
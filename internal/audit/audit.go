@@ -0,0 +1,236 @@
+// Package audit keeps an append-only trail of the calls that may change a
+// legacy system of record: which adapter action ran with which params, for
+// which task and caller, and how it ended.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+	"github.com/A2AGateway/a2a-connector/internal/security"
+)
+
+// Outcomes of an audited call
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// DefaultReadActions are the actions that do not change the legacy system:
+// REST methods and the read actions of the db and file adapters
+var DefaultReadActions = []string{"GET", "HEAD", "OPTIONS", "query", "read", "list"}
+
+// Record is an audited call to a legacy system
+type Record struct {
+	Timestamp  time.Time   `json:"timestamp"`
+	Adapter    string      `json:"adapter"`
+	Action     string      `json:"action"`
+	Method     string      `json:"method,omitempty"`
+	Endpoint   string      `json:"endpoint,omitempty"`
+	Params     interface{} `json:"params,omitempty"`
+	TaskID     string      `json:"taskId,omitempty"`
+	MappingID  string      `json:"mappingId,omitempty"`
+	Caller     string      `json:"caller,omitempty"`
+	AuthMethod string      `json:"authMethod,omitempty"`
+	Outcome    string      `json:"outcome"`
+	Error      string      `json:"error,omitempty"`
+	LatencyMs  float64     `json:"latencyMs"`
+}
+
+// Sink stores audit records
+type Sink interface {
+	Write(record Record) error
+	Close() error
+}
+
+// Log records the calls that are not reads to its sinks
+type Log struct {
+	sinks  []Sink
+	reads  map[string]bool
+	redact map[string]bool
+
+	// Now can be replaced in tests
+	Now func() time.Time
+}
+
+// New creates the audit log configured in cfg; it returns nil (disabled) when
+// cfg is nil
+func New(cfg *config.AuditConfig) (*Log, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	var sinks []Sink
+	if cfg.File != "" {
+		sink, err := NewFileSink(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.Syslog != nil {
+		sink, err := NewSyslogSink(*cfg.Syslog)
+		if err != nil {
+			for _, s := range sinks {
+				s.Close()
+			}
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	readActions := cfg.ReadActions
+	if len(readActions) == 0 {
+		readActions = DefaultReadActions
+	}
+	return NewLog(readActions, cfg.Redact, sinks...), nil
+}
+
+// NewLog creates an audit log writing to sinks that skips readActions and
+// masks the params named in redact
+func NewLog(readActions, redact []string, sinks ...Sink) *Log {
+	l := &Log{sinks: sinks, reads: make(map[string]bool), redact: make(map[string]bool), Now: time.Now}
+	for _, action := range readActions {
+		l.reads[strings.ToLower(action)] = true
+	}
+	for _, name := range redact {
+		l.redact[strings.ToLower(name)] = true
+	}
+	return l
+}
+
+// Audited reports whether a call with action and params is recorded: its
+// method param, or its action when it has none, is not a read
+func (l *Log) Audited(action string, params map[string]interface{}) bool {
+	if l == nil {
+		return false
+	}
+	return !l.reads[strings.ToLower(callMethod(action, params))]
+}
+
+// Record writes a call to the sinks unless it is a read. Calls are recorded
+// after they ran, with err as their outcome; sink failures are logged.
+func (l *Log) Record(ctx context.Context, adapterName, action string, params map[string]interface{}, latency time.Duration, err error) {
+	if !l.Audited(action, params) {
+		return
+	}
+
+	record := Record{
+		Timestamp: l.Now().UTC(),
+		Adapter:   adapterName,
+		Action:    action,
+		Params:    l.redactParams(params),
+		Outcome:   OutcomeSuccess,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if method := callMethod(action, params); method != action {
+		record.Method = method
+	}
+	if task, ok := ctx.Value(taskKey{}).(Task); ok {
+		record.TaskID = task.ID
+		record.MappingID = task.MappingID
+		record.Endpoint = task.Endpoint
+	}
+	if principal, ok := security.PrincipalFromContext(ctx); ok {
+		record.Caller = principal.Subject
+		record.AuthMethod = principal.Method
+	}
+	if err != nil {
+		record.Outcome = OutcomeError
+		record.Error = secrets.Redact(err.Error())
+	}
+
+	for _, sink := range l.sinks {
+		if err := sink.Write(record); err != nil {
+			slog.Warn("failed to write audit record", "action", action, logging.KeyTaskID, record.TaskID, logging.KeyError, err)
+		}
+	}
+}
+
+// Close closes the sinks
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	var first error
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// redactParams returns a copy of params with secrets, credentials and the
+// configured params masked
+func (l *Log) redactParams(params map[string]interface{}) interface{} {
+	if len(params) == 0 {
+		return nil
+	}
+	// Round-tripping through JSON gives RedactValue the types it walks
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Sprintf("unencodable params: %v", err)
+	}
+	var value interface{}
+	json.Unmarshal(data, &value)
+	return secrets.RedactValue(l.mask(value))
+}
+
+// mask replaces the values of the configured params at any depth
+func (l *Log) mask(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range v {
+			if l.redact[strings.ToLower(key)] {
+				v[key] = secrets.Mask
+			} else {
+				v[key] = l.mask(elem)
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = l.mask(elem)
+		}
+	}
+	return value
+}
+
+// callMethod is the method param of a call, which REST mappings set, or its
+// action
+func callMethod(action string, params map[string]interface{}) string {
+	if method, ok := params["method"].(string); ok && method != "" {
+		return method
+	}
+	return action
+}
+
+// Task identifies the task a call runs for
+type Task struct {
+	ID        string
+	MappingID string
+	Endpoint  string
+}
+
+// taskKey carries the Task in a context
+type taskKey struct{}
+
+// WithTask returns a context whose calls are recorded for task
+func WithTask(ctx context.Context, task Task) context.Context {
+	return context.WithValue(ctx, taskKey{}, task)
+}
+
+// WithEndpoint returns a context whose calls are recorded with endpoint, such
+// as that of a step, for the task of ctx
+func WithEndpoint(ctx context.Context, endpoint string) context.Context {
+	task, _ := ctx.Value(taskKey{}).(Task)
+	task.Endpoint = endpoint
+	return WithTask(ctx, task)
+}
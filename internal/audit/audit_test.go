@@ -0,0 +1,135 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/audit"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/security"
+)
+
+// failingAdapter fails every call
+type failingAdapter struct{}
+
+func (failingAdapter) Initialize() error { return nil }
+func (failingAdapter) Close() error      { return nil }
+
+func (failingAdapter) GetCapabilities() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (failingAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return nil, errors.New("legacy system unavailable")
+}
+
+func TestAuditFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, err := audit.New(&config.AuditConfig{File: path, Redact: []string{"iban"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Now = func() time.Time { return time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC) }
+
+	ctx := security.WithPrincipal(context.Background(), &security.Principal{Subject: "agent-7", Method: "jwt"})
+	ctx = audit.WithTask(ctx, audit.Task{ID: "task-1", MappingID: "pay.*invoice", Endpoint: "/payments"})
+	audited := adapter.NewAuditedAdapter(failingAdapter{}, "erp", log)
+
+	adapter.ExecuteTaskContext(ctx, audited, "GET", map[string]interface{}{"id": "1"})
+	adapter.ExecuteTaskContext(ctx, audited, "/payments", map[string]interface{}{"method": "get"})
+	adapter.ExecuteTaskContext(ctx, audited, "POST", map[string]interface{}{
+		"body":     map[string]interface{}{"iban": "DE89370400440532013000", "amount": 120},
+		"password": "hunter2",
+	})
+	adapter.ExecuteTaskContext(audit.WithEndpoint(ctx, "/ledger"), audited, "execute", nil)
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected the 2 calls that are not reads, got %v", records)
+	}
+
+	post := records[0]
+	for key, want := range map[string]interface{}{
+		"timestamp":  "2026-10-16T09:00:00Z",
+		"adapter":    "erp",
+		"action":     "POST",
+		"endpoint":   "/payments",
+		"taskId":     "task-1",
+		"mappingId":  "pay.*invoice",
+		"caller":     "agent-7",
+		"authMethod": "jwt",
+		"outcome":    audit.OutcomeError,
+		"error":      "legacy system unavailable",
+	} {
+		if post[key] != want {
+			t.Errorf("Expected %s %v, got %v", key, want, post[key])
+		}
+	}
+	params, _ := json.Marshal(post["params"])
+	if strings.Contains(string(params), "DE89") || strings.Contains(string(params), "hunter2") || !strings.Contains(string(params), `"amount":120`) {
+		t.Errorf("Expected iban and password to be masked, got %s", params)
+	}
+	if records[1]["action"] != "execute" || records[1]["endpoint"] != "/ledger" {
+		t.Errorf("Unexpected record: %v", records[1])
+	}
+}
+
+func TestAuditSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log, err := audit.New(&config.AuditConfig{
+		Syslog:      &config.SyslogConfig{Address: conn.LocalAddr().String(), Facility: "auth"},
+		ReadActions: []string{"inquire"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	log.Record(context.Background(), "mainframe", "inquire", nil, time.Millisecond, nil)
+	log.Record(context.Background(), "mainframe", "update", nil, 1500*time.Microsecond, nil)
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// auth (4) * 8 + notice (5)
+	if !strings.HasPrefix(msg, "<37>1 ") || !strings.Contains(msg, " a2a-connector ") || !strings.Contains(msg, " audit - {") {
+		t.Errorf("Unexpected syslog message %q", msg)
+	}
+	if !strings.Contains(msg, `"action":"update"`) || !strings.Contains(msg, `"latencyMs":1.5`) {
+		t.Errorf("Expected the update call, got %q", msg)
+	}
+}
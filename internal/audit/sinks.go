@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// FileSink appends records as JSON lines to a file, synced after each one so
+// a record is on disk before the task answers
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it readable by the owner
+// only
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends a record
+func (s *FileSink) Write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Defaults of the syslog sink
+const (
+	DefaultSyslogNetwork  = "udp"
+	DefaultSyslogSocket   = "/dev/log"
+	DefaultSyslogFacility = "local0"
+	DefaultSyslogTag      = "a2a-connector"
+)
+
+// Syslog severities of successful and failed calls
+const (
+	severityWarning = 4
+	severityNotice  = 5
+)
+
+// SyslogSink sends records as RFC 5424 messages with the JSON record as
+// message, framed by octet counting over tcp. The connection is opened again
+// when a write fails.
+type SyslogSink struct {
+	network  string
+	address  string
+	facility int
+	tag      string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink connects to the syslog server of cfg
+func NewSyslogSink(cfg config.SyslogConfig) (*SyslogSink, error) {
+	s := &SyslogSink{
+		network: cfg.Network,
+		address: cfg.Address,
+		tag:     cfg.Tag,
+	}
+	if s.network == "" {
+		s.network = DefaultSyslogNetwork
+	}
+	if s.address == "" && s.network == "unixgram" {
+		s.address = DefaultSyslogSocket
+	}
+	if s.tag == "" {
+		s.tag = DefaultSyslogTag
+	}
+	facility := cfg.Facility
+	if facility == "" {
+		facility = DefaultSyslogFacility
+	}
+	s.facility = -1
+	for code, name := range config.SyslogFacilities {
+		if name == facility {
+			s.facility = code
+		}
+	}
+	if s.facility < 0 {
+		return nil, fmt.Errorf("unsupported syslog facility %q", facility)
+	}
+	s.hostname, _ = os.Hostname()
+	if s.hostname == "" {
+		s.hostname = "-"
+	}
+
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SyslogSink) connect() error {
+	conn, err := net.DialTimeout(s.network, s.address, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog at %s: %w", s.address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Write sends a record, reconnecting once when the connection failed
+func (s *SyslogSink) Write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	severity := severityNotice
+	if record.Outcome != OutcomeSuccess {
+		severity = severityWarning
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d audit - %s",
+		s.facility*8+severity, record.Timestamp.Format(time.RFC3339Nano), s.hostname, s.tag, os.Getpid(), data)
+	if s.network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if _, err = s.conn.Write([]byte(msg)); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	if err := s.connect(); err != nil {
+		return err
+	}
+	_, err = s.conn.Write([]byte(msg))
+	return err
+}
+
+// Close closes the connection
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,144 @@
+// Package authclient authenticates outbound calls to legacy systems
+package authclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// DefaultAPIKeyHeader carries the token of apikey auth when keyName is empty
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// Provider adds credentials to outbound requests
+type Provider interface {
+	Authorize(req *http.Request) error
+}
+
+// Invalidator is implemented by providers with cached credentials that can be
+// dropped after the legacy system rejected them
+type Invalidator interface {
+	Invalidate()
+}
+
+// New creates a provider from config; it returns nil when no auth is configured
+func New(cfg config.AuthConfig) (Provider, error) {
+	switch strings.ToLower(cfg.Type) {
+	case "", "none":
+		return nil, nil
+	case "basic":
+		return Basic{Username: cfg.Username, Password: cfg.Password}, nil
+	case "bearer":
+		return Header{Name: "Authorization", Value: "Bearer " + cfg.Token}, nil
+	case "apikey":
+		name := cfg.KeyName
+		if name == "" {
+			name = DefaultAPIKeyHeader
+		}
+		return Header{Name: name, Value: cfg.Token}, nil
+	case "oauth2":
+		if cfg.OAuth2 == nil {
+			return nil, fmt.Errorf("oauth2 auth requires oauth2 settings")
+		}
+		return NewTokenSource(*cfg.OAuth2), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q", cfg.Type)
+	}
+}
+
+// Basic sends HTTP basic credentials
+type Basic struct {
+	Username string
+	Password string
+}
+
+// Authorize implements Provider
+func (b Basic) Authorize(req *http.Request) error {
+	req.SetBasicAuth(b.Username, b.Password)
+	return nil
+}
+
+// Header sends a static header, e.g. a bearer token or API key
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Authorize implements Provider
+func (h Header) Authorize(req *http.Request) error {
+	req.Header.Set(h.Name, h.Value)
+	return nil
+}
+
+// AuthError is returned when a provider cannot obtain credentials, e.g. the
+// token endpoint is down
+type AuthError struct {
+	Err error
+}
+
+// Error implements the error interface
+func (e *AuthError) Error() string {
+	return "failed to authorize request: " + e.Err.Error()
+}
+
+// Unwrap returns the provider error
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// Transport adds credentials to each request. A 401 response makes cached
+// credentials be dropped and the request be sent once more with fresh ones.
+type Transport struct {
+	Base     http.RoundTripper
+	Provider Provider
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := t.send(base, req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	invalidator, ok := t.Provider.(Invalidator)
+	if !ok || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, nil
+	}
+
+	invalidator.Invalidate()
+	resp.Body.Close()
+	retry := req
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry = req.Clone(req.Context())
+		retry.Body = body
+	}
+	return t.send(base, retry)
+}
+
+func (t *Transport) send(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	authorized := req.Clone(req.Context())
+	if err := t.Provider.Authorize(authorized); err != nil {
+		return nil, &AuthError{Err: err}
+	}
+	return base.RoundTrip(authorized)
+}
+
+// Apply wraps the client's transport so requests carry credentials from p;
+// nil p leaves the client unchanged. TLS settings must be applied first.
+func Apply(client *http.Client, p Provider) {
+	if p == nil {
+		return
+	}
+	client.Transport = &Transport{Base: client.Transport, Provider: p}
+}
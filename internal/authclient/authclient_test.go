@@ -0,0 +1,136 @@
+package authclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/authclient"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func TestStaticProviders(t *testing.T) {
+	for _, tc := range []struct {
+		auth   config.AuthConfig
+		header string
+		want   string
+	}{
+		{config.AuthConfig{Type: "basic", Username: "erp", Password: "pw"}, "Authorization", "Basic ZXJwOnB3"},
+		{config.AuthConfig{Type: "bearer", Token: "t0k"}, "Authorization", "Bearer t0k"},
+		{config.AuthConfig{Type: "apikey", KeyName: "X-Erp-Key", Token: "k3y"}, "X-Erp-Key", "k3y"},
+	} {
+		p, err := authclient.New(tc.auth)
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", tc.auth.Type, err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		p.Authorize(req)
+		if got := req.Header.Get(tc.header); got != tc.want {
+			t.Errorf("%s: expected %s %q, got %q", tc.auth.Type, tc.header, tc.want, got)
+		}
+	}
+
+	if p, err := authclient.New(config.AuthConfig{}); p != nil || err != nil {
+		t.Errorf("Expected no provider without auth, got %v %v", p, err)
+	}
+	if _, err := authclient.New(config.AuthConfig{Type: "kerberos"}); err == nil {
+		t.Error("Expected unsupported auth type to be rejected")
+	}
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	var issued int32
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		id, secret, _ := r.BasicAuth()
+		if id != "connector" || secret != "s3cret" || r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "orders.read" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		n := atomic.AddInt32(&issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token-` + string(rune('0'+n)) + `","token_type":"bearer","expires_in":300}`))
+	}))
+	defer idp.Close()
+
+	// The legacy system accepts only the newest token, as if older ones were revoked
+	legacy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := "Bearer token-" + string(rune('0'+atomic.LoadInt32(&issued)))
+		if r.Header.Get("Authorization") != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer legacy.Close()
+
+	source := authclient.NewTokenSource(config.OAuth2Config{
+		TokenURL: idp.URL, ClientID: "connector", ClientSecret: "s3cret", Scopes: []string{"orders.read"},
+	})
+	now := time.Now()
+	source.Now = func() time.Time { return now }
+
+	rest := adapter.NewRESTAdapter("legacy", legacy.URL, nil, nil)
+	rest.SetAuth(source)
+	call := func() {
+		t.Helper()
+		if _, err := rest.ExecuteTask("/orders", nil); err != nil {
+			t.Fatalf("Call failed: %v", err)
+		}
+	}
+
+	call()
+	call()
+	if issued != 1 {
+		t.Errorf("Expected the token to be cached, got %d token requests", issued)
+	}
+
+	// Tokens are refreshed shortly before they expire
+	now = now.Add(290 * time.Second)
+	call()
+	if issued != 2 {
+		t.Errorf("Expected a refresh before expiry, got %d token requests", issued)
+	}
+
+	// A revoked token is dropped and the call retried once with a new one
+	atomic.AddInt32(&issued, 1)
+	call()
+	if issued != 4 {
+		t.Errorf("Expected a new token after 401, got %d token requests", issued)
+	}
+
+	bad := authclient.NewTokenSource(config.OAuth2Config{TokenURL: idp.URL, ClientID: "connector", ClientSecret: "wrong"})
+	if _, _, err := bad.Token(httptest.NewRequest(http.MethodGet, "/", nil).Context()); err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("Expected the token endpoint error, got %v", err)
+	}
+}
+
+func TestOAuth2RefreshTokenRotation(t *testing.T) {
+	var seen []string
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		seen = append(seen, r.Form.Get("refresh_token"))
+		w.Write([]byte(`{"access_token":"access","expires_in":"60","refresh_token":"rotated-` + r.Form.Get("refresh_token") + `"}`))
+	}))
+	defer idp.Close()
+
+	source := authclient.NewTokenSource(config.OAuth2Config{
+		TokenURL: idp.URL, GrantType: "refresh_token", ClientID: "connector", RefreshToken: "initial",
+	})
+	ctx := httptest.NewRequest(http.MethodGet, "/", nil).Context()
+	for i := 0; i < 2; i++ {
+		token, tokenType, err := source.Token(ctx)
+		if err != nil || token != "access" || tokenType != "Bearer" {
+			t.Fatalf("Unexpected token %q %q %v", token, tokenType, err)
+		}
+		source.Invalidate()
+	}
+	if len(seen) != 2 || seen[0] != "initial" || seen[1] != "rotated-initial" {
+		t.Errorf("Expected the rotated refresh token to be used, got %v", seen)
+	}
+}
@@ -0,0 +1,166 @@
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// expiryLeeway refreshes tokens this long before they expire, so a token
+// does not run out while a request is in flight
+const expiryLeeway = 30 * time.Second
+
+// defaultTokenLifetime is assumed when the token response has no expires_in
+const defaultTokenLifetime = time.Hour
+
+// TokenSource obtains OAuth2 access tokens with the client_credentials or
+// refresh_token grant and caches them until shortly before they expire
+type TokenSource struct {
+	TokenURL     string
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Params       map[string]string
+	HTTPClient   *http.Client
+
+	// Now can be replaced in tests
+	Now func() time.Time
+
+	mu           sync.Mutex
+	refreshToken string
+	accessToken  string
+	tokenType    string
+	expiry       time.Time
+}
+
+// NewTokenSource creates a token source from config
+func NewTokenSource(cfg config.OAuth2Config) *TokenSource {
+	grantType := cfg.GrantType
+	if grantType == "" {
+		grantType = "client_credentials"
+	}
+	return &TokenSource{
+		TokenURL:     cfg.TokenURL,
+		GrantType:    grantType,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Scopes:       cfg.Scopes,
+		Params:       cfg.Params,
+		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
+		Now:          time.Now,
+		refreshToken: cfg.RefreshToken,
+	}
+}
+
+// Authorize implements Provider
+func (s *TokenSource) Authorize(req *http.Request) error {
+	token, tokenType, err := s.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", tokenType+" "+token)
+	return nil
+}
+
+// Invalidate drops the cached access token, e.g. after it was revoked
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessToken = ""
+}
+
+// Token returns a valid access token and its type, fetching a new one when
+// the cached token is missing or about to expire
+func (s *TokenSource) Token(ctx context.Context) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && s.Now().Add(expiryLeeway).Before(s.expiry) {
+		return s.accessToken, s.tokenType, nil
+	}
+	if err := s.fetch(ctx); err != nil {
+		return "", "", err
+	}
+	return s.accessToken, s.tokenType, nil
+}
+
+// tokenResponse is the token endpoint response of RFC 6749 section 5
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	RefreshToken     string      `json:"refresh_token"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// fetch requests a new access token; the caller holds s.mu
+func (s *TokenSource) fetch(ctx context.Context) error {
+	form := url.Values{"grant_type": {s.GrantType}}
+	if s.GrantType == "refresh_token" {
+		form.Set("refresh_token", s.refreshToken)
+	}
+	if len(s.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.Scopes, " "))
+	}
+	for key, value := range s.Params {
+		form.Set(key, value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.ClientID), url.QueryEscape(s.ClientSecret))
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("oauth2 token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("oauth2 token request failed: %w", err)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("invalid oauth2 token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		if token.Error != "" {
+			return fmt.Errorf("oauth2 token request failed: %s %s", token.Error, token.ErrorDescription)
+		}
+		return fmt.Errorf("oauth2 token request failed: %s", resp.Status)
+	}
+
+	lifetime := defaultTokenLifetime
+	if seconds, err := token.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		lifetime = time.Duration(seconds) * time.Second
+	}
+	s.accessToken = token.AccessToken
+	s.tokenType = "Bearer"
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		s.tokenType = token.TokenType
+	}
+	s.expiry = s.Now().Add(lifetime)
+	// Servers may rotate refresh tokens; the old one stops working
+	if token.RefreshToken != "" && s.GrantType == "refresh_token" {
+		s.refreshToken = token.RefreshToken
+	}
+	slog.Debug("obtained oauth2 access token", "tokenUrl", s.TokenURL, "expiresIn", lifetime)
+	return nil
+}
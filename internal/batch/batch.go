@@ -0,0 +1,324 @@
+// Package batch splits batch files sent with a task, such as a CSV export of
+// 10,000 orders, into one task per record or chunk of records
+package batch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// MetadataKey is the task metadata key holding the position of a chunk task
+// in its batch
+const MetadataKey = "batch"
+
+// Splitter splits the file of a batch task into chunk tasks
+type Splitter struct {
+	// Format is used when the file's MIME type and name do not tell
+	Format      string
+	ChunkSize   int
+	MaxRecords  int
+	StopOnError bool
+}
+
+// FromConfig returns the splitter of a batch config, or nil when batching
+// is disabled
+func FromConfig(cfg *config.BatchConfig) *Splitter {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	s := &Splitter{Format: cfg.Format, ChunkSize: cfg.ChunkSize, MaxRecords: cfg.MaxRecords, StopOnError: cfg.StopOnError}
+	if s.ChunkSize <= 0 {
+		s.ChunkSize = 1
+	}
+	return s
+}
+
+// File is a file part of a task
+type File struct {
+	Name     string
+	MimeType string
+	Data     []byte
+}
+
+// FindFile returns the first file part of a task message with inline bytes,
+// or nil when there is none
+func FindFile(task map[string]interface{}) (*File, error) {
+	status, _ := task["status"].(map[string]interface{})
+	message, _ := status["message"].(map[string]interface{})
+	parts, _ := message["parts"].([]interface{})
+	for _, part := range parts {
+		partMap, _ := part.(map[string]interface{})
+		file, _ := partMap["file"].(map[string]interface{})
+		if partMap["type"] != "file" || file == nil {
+			continue
+		}
+		encoded, _ := file["bytes"].(string)
+		if encoded == "" {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file bytes: %w", err)
+		}
+		name, _ := file["name"].(string)
+		mimeType, _ := file["mimeType"].(string)
+		return &File{Name: name, MimeType: mimeType, Data: data}, nil
+	}
+	return nil, nil
+}
+
+// Detect returns the format of a file from its MIME type or extension, or
+// fallback when neither is known
+func Detect(file *File, fallback string) string {
+	mimeType := strings.ToLower(strings.TrimSpace(strings.Split(file.MimeType, ";")[0]))
+	switch {
+	case strings.HasSuffix(mimeType, "json") || strings.HasSuffix(mimeType, "jsonl"):
+		return "json"
+	case strings.HasSuffix(mimeType, "csv"):
+		return "csv"
+	case strings.HasSuffix(mimeType, "yaml"):
+		return "yaml"
+	}
+	switch strings.ToLower(path.Ext(file.Name)) {
+	case ".json", ".ndjson", ".jsonl":
+		return "json"
+	case ".csv":
+		return "csv"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".txt":
+		return "lines"
+	}
+	if fallback == "" && mimeType == "text/plain" {
+		return "lines"
+	}
+	return fallback
+}
+
+// Records parses the records of a batch file:
+//   - json: the elements of an array, or each value of a stream of values
+//     such as newline-delimited JSON
+//   - csv: each row after the header row, keyed by column name
+//   - yaml: each document, or the elements of a document that is a list
+//   - lines: each non-empty line
+func Records(data []byte, format string) ([]interface{}, error) {
+	var records []interface{}
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var value interface{}
+			if err := decoder.Decode(&value); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("record %d: %w", len(records)+1, err)
+			}
+			records = appendRecords(records, value)
+		}
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var value interface{}
+			if err := decoder.Decode(&value); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("document %d: %w", len(records)+1, err)
+			}
+			if value != nil {
+				records = appendRecords(records, value)
+			}
+		}
+	case "csv":
+		reader := csv.NewReader(bytes.NewReader(data))
+		header, err := reader.Read()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			record := make(map[string]interface{}, len(header))
+			for i, column := range header {
+				if i < len(row) {
+					record[column] = row[i]
+				}
+			}
+			records = append(records, record)
+		}
+	case "lines":
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+				records = append(records, line)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported batch format %q", format)
+	}
+	return records, nil
+}
+
+func appendRecords(records []interface{}, value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return append(records, list...)
+	}
+	return append(records, value)
+}
+
+// Split returns the chunk tasks of a task carrying a batch file, in order,
+// or nil when the task has no file. Each chunk task repeats the task's
+// message text, so it matches the same mapping, and holds its records in
+// the metadata: metadata.record with a chunk size of 1, metadata.records
+// otherwise. metadata.batch gives its position in the batch.
+func (s *Splitter) Split(task map[string]interface{}) ([]map[string]interface{}, error) {
+	file, err := FindFile(task)
+	if file == nil || err != nil {
+		return nil, err
+	}
+	format := Detect(file, s.Format)
+	if format == "" {
+		return nil, fmt.Errorf("cannot tell the format of batch file %q; set batch.format", file.Name)
+	}
+	records, err := Records(file.Data, format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s batch file: %w", format, err)
+	}
+	if s.MaxRecords > 0 && len(records) > s.MaxRecords {
+		return nil, fmt.Errorf("batch file has %d records, more than the limit of %d", len(records), s.MaxRecords)
+	}
+
+	batchID, _ := task["id"].(string)
+	total := (len(records) + s.ChunkSize - 1) / s.ChunkSize
+	tasks := make([]map[string]interface{}, 0, total)
+	for offset := 0; offset < len(records); offset += s.ChunkSize {
+		chunk := records[offset:min(offset+s.ChunkSize, len(records))]
+		sequence := len(tasks) + 1
+		tasks = append(tasks, chunkTask(task, chunk, s.ChunkSize == 1, map[string]interface{}{
+			"id":           batchID,
+			"sequence":     sequence,
+			"total":        total,
+			"recordOffset": offset,
+			"recordCount":  len(chunk),
+			"last":         sequence == total,
+		}))
+	}
+	return tasks, nil
+}
+
+// chunkTask builds the task of one chunk from the batch task: the same text
+// parts and metadata, without the file
+func chunkTask(task map[string]interface{}, records []interface{}, single bool, position map[string]interface{}) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	if parent, ok := task["metadata"].(map[string]interface{}); ok {
+		for key, value := range parent {
+			metadata[key] = value
+		}
+	}
+	metadata[MetadataKey] = position
+	if single {
+		metadata["record"] = records[0]
+	} else {
+		metadata["records"] = records
+	}
+
+	status, _ := task["status"].(map[string]interface{})
+	message, _ := status["message"].(map[string]interface{})
+	parts, _ := message["parts"].([]interface{})
+	var textParts []interface{}
+	for _, part := range parts {
+		if partMap, ok := part.(map[string]interface{}); ok && partMap["type"] == "text" {
+			textParts = append(textParts, part)
+		}
+	}
+	role, _ := message["role"].(string)
+
+	return map[string]interface{}{
+		"id":       fmt.Sprintf("%v-%d", position["id"], position["sequence"]),
+		"metadata": metadata,
+		"status": map[string]interface{}{
+			"state":   "submitted",
+			"message": map[string]interface{}{"role": role, "parts": textParts},
+		},
+	}
+}
+
+// Failure is a chunk task that failed
+type Failure struct {
+	Sequence     int    `json:"sequence"`
+	RecordOffset int    `json:"recordOffset"`
+	Error        string `json:"error"`
+}
+
+// Summary counts the outcome of the chunk tasks of a batch
+type Summary struct {
+	BatchID   string    `json:"batchId"`
+	Records   int       `json:"records"`
+	Tasks     int       `json:"tasks"`
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+	Skipped   int       `json:"skipped,omitempty"`
+	Failures  []Failure `json:"failures,omitempty"`
+}
+
+// Run executes the chunk tasks in order. With StopOnError the tasks after
+// the first failure are skipped.
+func (s *Splitter) Run(batchID string, tasks []map[string]interface{}, execute func(task map[string]interface{}) error) *Summary {
+	summary := &Summary{BatchID: batchID, Tasks: len(tasks)}
+	for _, task := range tasks {
+		position := task["metadata"].(map[string]interface{})[MetadataKey].(map[string]interface{})
+		summary.Records += position["recordCount"].(int)
+		if s.StopOnError && summary.Failed > 0 {
+			summary.Skipped++
+			continue
+		}
+		if err := execute(task); err != nil {
+			summary.Failed++
+			summary.Failures = append(summary.Failures, Failure{
+				Sequence:     position["sequence"].(int),
+				RecordOffset: position["recordOffset"].(int),
+				Error:        err.Error(),
+			})
+			continue
+		}
+		summary.Succeeded++
+	}
+	return summary
+}
+
+// Task returns the completion summary task of a batch with the given text.
+// It fails when any chunk task failed.
+func (s *Summary) Task(text string) map[string]interface{} {
+	state := "completed"
+	if s.Failed > 0 {
+		state = "failed"
+	}
+	return map[string]interface{}{
+		"id":       s.BatchID,
+		"metadata": map[string]interface{}{MetadataKey: map[string]interface{}{"id": s.BatchID, "summary": true}},
+		"status": map[string]interface{}{
+			"state": state,
+			"message": map[string]interface{}{
+				"role": "agent",
+				"parts": []interface{}{
+					map[string]interface{}{"type": "text", "text": text},
+					map[string]interface{}{"type": "data", "data": s},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,138 @@
+package batch_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/batch"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func TestRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		data     string
+		expected string
+	}{
+		{"json array", "json", `[{"sku":"A"},{"sku":"B"}]`, "[map[sku:A] map[sku:B]]"},
+		{"ndjson", "json", "{\"sku\":\"A\"}\n{\"sku\":\"B\"}\n", "[map[sku:A] map[sku:B]]"},
+		{"csv", "csv", "sku,qty\nA,1\nB,2\n", "[map[qty:1 sku:A] map[qty:2 sku:B]]"},
+		{"yaml documents", "yaml", "sku: A\n---\nsku: B\n", "[map[sku:A] map[sku:B]]"},
+		{"yaml list", "yaml", "- sku: A\n- sku: B\n", "[map[sku:A] map[sku:B]]"},
+		{"lines", "lines", "A\r\n\nB\n", "[A B]"},
+	}
+	for _, tt := range tests {
+		records, err := batch.Records([]byte(tt.data), tt.format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if got := fmt.Sprint(records); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+
+	if _, err := batch.Records([]byte("{\"sku\":\"A\"}\n{broken\n"), "json"); err == nil || !strings.Contains(err.Error(), "record 2") {
+		t.Errorf("Expected the broken record in the error, got %v", err)
+	}
+}
+
+func batchTask(name, mimeType, data string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":       "batch-1",
+		"metadata": map[string]interface{}{"tenant": "acme"},
+		"status": map[string]interface{}{
+			"state": "submitted",
+			"message": map[string]interface{}{
+				"role": "user",
+				"parts": []interface{}{
+					map[string]interface{}{"type": "text", "text": "import orders"},
+					map[string]interface{}{"type": "file", "file": map[string]interface{}{
+						"name":     name,
+						"mimeType": mimeType,
+						"bytes":    base64.StdEncoding.EncodeToString([]byte(data)),
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestSplit(t *testing.T) {
+	splitter := batch.FromConfig(&config.BatchConfig{Enabled: true, ChunkSize: 2})
+	tasks, err := splitter.Split(batchTask("orders.csv", "", "sku\nA\nB\nC\n"))
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 chunk tasks, got %d", len(tasks))
+	}
+
+	last := tasks[1]
+	metadata := last["metadata"].(map[string]interface{})
+	position := metadata[batch.MetadataKey].(map[string]interface{})
+	if last["id"] != "batch-1-2" || position["sequence"] != 2 || position["total"] != 2 || position["recordOffset"] != 2 || position["last"] != true {
+		t.Errorf("Unexpected chunk task %v", last)
+	}
+	if fmt.Sprint(metadata["records"]) != "[map[sku:C]]" || metadata["tenant"] != "acme" {
+		t.Errorf("Expected the records and the batch metadata, got %v", metadata)
+	}
+	parts := last["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{})
+	if len(parts) != 1 || parts[0].(map[string]interface{})["text"] != "import orders" {
+		t.Errorf("Expected only the text part, got %v", parts)
+	}
+
+	// Tasks without a file are not batches
+	task := batchTask("", "", "")
+	if tasks, err := splitter.Split(task); tasks != nil || err != nil {
+		t.Errorf("Expected no chunk tasks, got %v, %v", tasks, err)
+	}
+
+	splitter.MaxRecords = 2
+	if _, err := splitter.Split(batchTask("orders.csv", "", "sku\nA\nB\nC\n")); err == nil {
+		t.Error("Expected an error for a batch over maxRecords")
+	}
+	if _, err := splitter.Split(batchTask("orders.bin", "application/octet-stream", "x")); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	splitter := batch.FromConfig(&config.BatchConfig{Enabled: true})
+	tasks, err := splitter.Split(batchTask("orders.jsonl", "application/x-ndjson", "{\"sku\":\"A\"}\n{\"sku\":\"B\"}\n{\"sku\":\"C\"}\n"))
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+
+	var order []string
+	execute := func(task map[string]interface{}) error {
+		record := task["metadata"].(map[string]interface{})["record"].(map[string]interface{})
+		order = append(order, record["sku"].(string))
+		if record["sku"] == "B" {
+			return errors.New("unknown sku")
+		}
+		return nil
+	}
+	summary := splitter.Run("batch-1", tasks, execute)
+	if strings.Join(order, "") != "ABC" {
+		t.Errorf("Expected the records in order, got %v", order)
+	}
+	if summary.Records != 3 || summary.Succeeded != 2 || summary.Failed != 1 || summary.Failures[0].Sequence != 2 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	task := summary.Task("done")
+	if state := task["status"].(map[string]interface{})["state"]; state != "failed" {
+		t.Errorf("Expected a failed summary task, got %v", state)
+	}
+
+	// Stop on error skips the rest of the batch
+	order = nil
+	splitter.StopOnError = true
+	summary = splitter.Run("batch-1", tasks, execute)
+	if strings.Join(order, "") != "AB" || summary.Skipped != 1 || summary.Records != 3 {
+		t.Errorf("Expected the last chunk skipped, got %v, %+v", order, summary)
+	}
+}
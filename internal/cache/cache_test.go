@@ -0,0 +1,46 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func TestLRU(t *testing.T) {
+	c := cache.NewLRU(2)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Now = func() time.Time { return now }
+
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("b", []byte("2"), time.Minute)
+
+	// Reading "a" makes "b" the least recently used entry
+	if v, ok := c.Get("a"); !ok || string(v) != "1" {
+		t.Fatalf("Expected a=1, got %q, %v", v, ok)
+	}
+	c.Set("c", []byte("3"), time.Minute)
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", c.Len())
+	}
+
+	// Entries expire after their TTL
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected a to have expired")
+	}
+
+	c.Set("d", []byte("4"), time.Minute)
+	c.Delete("d")
+	if _, ok := c.Get("d"); ok {
+		t.Error("Expected d to be deleted")
+	}
+
+	if _, err := cache.New(config.CacheConfig{Backend: "redis"}); err == nil {
+		t.Error("Expected unsupported backend to be rejected")
+	}
+}
@@ -0,0 +1,146 @@
+// Package charset converts legacy single-byte encodings to UTF-8
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Canonical charset names
+const (
+	UTF8        = "utf-8"
+	ISO88591    = "iso-8859-1"
+	ISO885915   = "iso-8859-15"
+	Windows1252 = "windows-1252"
+)
+
+// Auto detects the charset from the declaration and content
+const Auto = "auto"
+
+// aliases maps common labels to canonical names
+var aliases = map[string]string{
+	"utf-8":        UTF8,
+	"utf8":         UTF8,
+	"iso-8859-1":   ISO88591,
+	"iso8859-1":    ISO88591,
+	"iso_8859-1":   ISO88591,
+	"latin1":       ISO88591,
+	"latin-1":      ISO88591,
+	"l1":           ISO88591,
+	"us-ascii":     UTF8,
+	"ascii":        UTF8,
+	"iso-8859-15":  ISO885915,
+	"iso8859-15":   ISO885915,
+	"latin-9":      ISO885915,
+	"latin9":       ISO885915,
+	"windows-1252": Windows1252,
+	"cp1252":       Windows1252,
+	"x-cp1252":     Windows1252,
+}
+
+// windows1252 maps bytes 0x80-0x9F to runes; zero entries are undefined
+var windows1252 = [32]rune{
+	0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+	0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+}
+
+// iso885915 lists the bytes where ISO-8859-15 differs from ISO-8859-1
+var iso885915 = map[byte]rune{
+	0xA4: 0x20AC, 0xA6: 0x0160, 0xA8: 0x0161, 0xB4: 0x017D,
+	0xB8: 0x017E, 0xBC: 0x0152, 0xBD: 0x0153, 0xBE: 0x0178,
+}
+
+// xmlEncodingPattern matches the encoding of an XML declaration
+var xmlEncodingPattern = regexp.MustCompile(`^(\s*<\?xml[^>]*?encoding\s*=\s*["'])([^"']+)(["'])`)
+
+// Normalize returns the canonical name of a charset label, or "" if unsupported
+func Normalize(label string) string {
+	return aliases[strings.ToLower(strings.TrimSpace(label))]
+}
+
+// Supported reports whether a label names a supported charset or "auto"
+func Supported(label string) bool {
+	return label == "" || strings.EqualFold(label, Auto) || Normalize(label) != ""
+}
+
+// Detect picks the charset of data. A declared non-UTF-8 charset is trusted;
+// otherwise valid UTF-8 is kept and anything else is treated as Windows-1252,
+// the usual encoding of legacy systems that omit or misstate it.
+func Detect(data []byte, declared string) string {
+	if name := Normalize(declared); name != "" && name != UTF8 {
+		return name
+	}
+	if utf8.Valid(data) {
+		return UTF8
+	}
+	return Windows1252
+}
+
+// ToUTF8 converts data from the named charset to UTF-8
+func ToUTF8(data []byte, name string) ([]byte, error) {
+	canonical := Normalize(name)
+	if canonical == "" {
+		return nil, fmt.Errorf("unsupported charset %q", name)
+	}
+	if canonical == UTF8 {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data) + len(data)/4)
+	for _, b := range data {
+		buf.WriteRune(decodeByte(b, canonical))
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeByte decodes one byte of a single-byte charset
+func decodeByte(b byte, canonical string) rune {
+	switch {
+	case b < 0x80:
+		return rune(b)
+	case canonical == Windows1252 && b < 0xA0:
+		if r := windows1252[b-0x80]; r != 0 {
+			return r
+		}
+	case canonical == ISO885915:
+		if r, ok := iso885915[b]; ok {
+			return r
+		}
+	}
+	return rune(b)
+}
+
+// XMLDeclaredEncoding returns the encoding named in an XML declaration, if any
+func XMLDeclaredEncoding(data []byte) string {
+	if m := xmlEncodingPattern.FindSubmatch(data); m != nil {
+		return string(m[2])
+	}
+	return ""
+}
+
+// RewriteXMLDeclaration marks converted XML as UTF-8 so parsers don't decode it twice
+func RewriteXMLDeclaration(data []byte) []byte {
+	return xmlEncodingPattern.ReplaceAll(data, []byte("${1}UTF-8${3}"))
+}
+
+// NewReader converts input from the labelled charset to UTF-8; it matches
+// xml.Decoder.CharsetReader
+func NewReader(label string, input io.Reader) (io.Reader, error) {
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := ToUTF8(data, label)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(converted), nil
+}
@@ -0,0 +1,65 @@
+package charset_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/charset"
+)
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		charset  string
+		expected string
+	}{
+		{"latin1", []byte("M\xfcller"), "ISO-8859-1", "Müller"},
+		{"windows-1252 quotes and euro", []byte("\x93100 \x80\x94"), "cp1252", "“100 €”"},
+		{"latin9 euro", []byte("5 \xa4"), "latin9", "5 €"},
+		{"utf-8 unchanged", []byte("Müller"), "utf-8", "Müller"},
+	}
+	for _, tt := range tests {
+		got, err := charset.ToUTF8(tt.input, tt.charset)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	if _, err := charset.ToUTF8([]byte("x"), "ebcdic"); err == nil {
+		t.Error("Expected unsupported charset to be rejected")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	if got := charset.Detect([]byte("Müller"), ""); got != charset.UTF8 {
+		t.Errorf("Expected valid UTF-8 to be kept, got %s", got)
+	}
+	// Legacy systems often claim UTF-8 while sending Windows-1252
+	if got := charset.Detect([]byte("M\xfcller"), "utf-8"); got != charset.Windows1252 {
+		t.Errorf("Expected invalid UTF-8 to fall back to windows-1252, got %s", got)
+	}
+	if got := charset.Detect([]byte("M\xfcller"), "latin1"); got != charset.ISO88591 {
+		t.Errorf("Expected declared charset to be trusted, got %s", got)
+	}
+}
+
+func TestXMLDeclaration(t *testing.T) {
+	doc := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><name>M\xfcller</name>")
+	if got := charset.XMLDeclaredEncoding(doc); got != "ISO-8859-1" {
+		t.Errorf("Expected declared encoding, got %q", got)
+	}
+
+	converted, _ := charset.ToUTF8(doc, charset.XMLDeclaredEncoding(doc))
+	converted = charset.RewriteXMLDeclaration(converted)
+
+	var name string
+	decoder := xml.NewDecoder(bytes.NewReader(converted))
+	if err := decoder.Decode(&name); err != nil || name != "Müller" {
+		t.Errorf("Expected converted XML to parse as UTF-8, got %q, %v", name, err)
+	}
+}
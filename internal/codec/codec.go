@@ -0,0 +1,92 @@
+// Package codec lets proprietary legacy payload formats, e.g. fixed-length
+// binary records, be decoded and encoded by codecs registered per content type
+package codec
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Codec converts between a legacy payload format and task data
+type Codec interface {
+	Decode(data []byte) (map[string]interface{}, error)
+	Encode(value map[string]interface{}) ([]byte, error)
+}
+
+// Registry maps content types to codecs
+type Registry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+// Default is the registry adapters use unless given their own. Custom builds
+// register their codecs here, e.g. from an init function.
+var Default = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{codecs: make(map[string]Codec)}
+}
+
+// Register adds a codec for a content type, replacing any earlier one
+func (r *Registry) Register(contentType string, c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codecs[mediaType(contentType)] = c
+}
+
+// Lookup returns the codec for a content type; parameters such as charset are ignored
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.codecs[mediaType(contentType)]
+	return c, ok
+}
+
+// Clone returns a copy of the registry
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	clone := NewRegistry()
+	for contentType, c := range r.codecs {
+		clone.codecs[contentType] = c
+	}
+	return clone
+}
+
+// Register adds a codec to the default registry
+func Register(contentType string, c Codec) {
+	Default.Register(contentType, c)
+}
+
+// FromConfig returns the codecs of the default registry plus the configured ones
+func FromConfig(cfgs []config.CodecConfig) (*Registry, error) {
+	registry := Default.Clone()
+	for _, cfg := range cfgs {
+		switch cfg.Type {
+		case "fixed":
+			c, err := NewFixedWidth(cfg.Fields)
+			if err != nil {
+				return nil, fmt.Errorf("codec %s: %w", cfg.ContentType, err)
+			}
+			registry.Register(cfg.ContentType, c)
+		default:
+			return nil, fmt.Errorf("codec %s has unsupported type %q", cfg.ContentType, cfg.Type)
+		}
+	}
+	return registry, nil
+}
+
+func mediaType(contentType string) string {
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
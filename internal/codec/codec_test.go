@@ -0,0 +1,120 @@
+package codec_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/codec"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+var orderLayout = []config.FixedFieldConfig{
+	{Name: "id", Length: 6, Type: "int"},
+	{Name: "customer", Length: 10},
+	{Name: "total", Length: 8, Type: "decimal"},
+}
+
+func TestFixedWidth(t *testing.T) {
+	c, err := codec.NewFixedWidth(orderLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.RecordLength() != 24 {
+		t.Errorf("Expected record length 24, got %d", c.RecordLength())
+	}
+
+	data, err := c.Encode(map[string]interface{}{"id": 42.0, "customer": "ACME", "total": 19.5})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if string(data) != "000042ACME      000019.5" {
+		t.Errorf("Unexpected record %q", data)
+	}
+
+	record, err := c.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if record["id"] != int64(42) || record["customer"] != "ACME" || record["total"] != 19.5 {
+		t.Errorf("Unexpected record %v", record)
+	}
+
+	list, err := c.Decode([]byte("000001A         00000001\n000002B         00000002\n"))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if records, _ := list["records"].([]interface{}); len(records) != 2 {
+		t.Errorf("Expected 2 records, got %v", list)
+	}
+
+	if _, err := c.Decode([]byte("000001A")); err == nil {
+		t.Error("Expected a truncated record to be rejected")
+	}
+	if _, err := c.Encode(map[string]interface{}{"customer": "A VERY LONG NAME"}); err == nil {
+		t.Error("Expected an oversized value to be rejected")
+	}
+}
+
+// upperCodec stands in for a user-supplied codec
+type upperCodec struct{}
+
+func (upperCodec) Decode(data []byte) (map[string]interface{}, error) {
+	return map[string]interface{}{"value": strings.ToLower(string(data))}, nil
+}
+
+func (upperCodec) Encode(value map[string]interface{}) ([]byte, error) {
+	return []byte(strings.ToUpper(value["value"].(string))), nil
+}
+
+func TestRESTAdapterCodecs(t *testing.T) {
+	var received string
+	legacy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = r.Header.Get("Content-Type") + " " + string(body)
+		if r.URL.Path == "/upper" {
+			w.Header().Set("Content-Type", "application/x-upper")
+			w.Write(body)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-orders; charset=ebcdic")
+		w.Write([]byte("000042ACME      000019.5"))
+	}))
+	defer legacy.Close()
+
+	registry, err := codec.FromConfig([]config.CodecConfig{{ContentType: "application/x-orders", Type: "fixed", Fields: orderLayout}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.Register("application/x-upper", upperCodec{})
+
+	rest := adapter.NewRESTAdapter("legacy", legacy.URL, nil, nil)
+	rest.Codecs = registry
+
+	result, err := rest.ExecuteTask("/orders/42", nil)
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if result["customer"] != "ACME" || result["id"] != int64(42) {
+		t.Errorf("Expected the fixed-width response to be decoded, got %v", result)
+	}
+
+	result, err = rest.ExecuteTask("/upper", map[string]interface{}{
+		"method":      "POST",
+		"contentType": "application/x-upper",
+		"body":        map[string]interface{}{"value": "hello"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if received != "application/x-upper HELLO" || result["value"] != "hello" {
+		t.Errorf("Expected the body to go through the codec both ways, sent %q got %v", received, result)
+	}
+
+	if _, err := codec.FromConfig([]config.CodecConfig{{ContentType: "application/x-orders", Type: "cobol"}}); err == nil {
+		t.Error("Expected an unsupported codec type to be rejected")
+	}
+}
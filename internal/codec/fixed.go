@@ -0,0 +1,134 @@
+package codec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// FixedWidth decodes records of fixed-length fields, as used by many
+// mainframe and socket protocols. Text fields are space padded on the right,
+// numbers zero padded on the left. A payload of several records is decoded
+// under "records".
+type FixedWidth struct {
+	Fields []config.FixedFieldConfig
+	length int
+}
+
+// NewFixedWidth creates a fixed-width codec for the given record layout
+func NewFixedWidth(fields []config.FixedFieldConfig) (*FixedWidth, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fixed-width codec requires fields")
+	}
+	c := &FixedWidth{Fields: fields}
+	for _, field := range fields {
+		if field.Length <= 0 {
+			return nil, fmt.Errorf("field %s requires a positive length", field.Name)
+		}
+		switch field.Type {
+		case "", "string", "int", "decimal":
+		default:
+			return nil, fmt.Errorf("field %s has unsupported type %q", field.Name, field.Type)
+		}
+		c.length += field.Length
+	}
+	return c, nil
+}
+
+// RecordLength returns the length of one record in bytes
+func (c *FixedWidth) RecordLength() int {
+	return c.length
+}
+
+// Decode implements Codec
+func (c *FixedWidth) Decode(data []byte) (map[string]interface{}, error) {
+	data = []byte(strings.TrimRight(string(data), "\r\n"))
+	if len(data) == c.length {
+		return c.decodeRecord(data)
+	}
+
+	// Several records, optionally separated by line breaks
+	var records []interface{}
+	for len(data) > 0 {
+		if len(data) < c.length {
+			return nil, fmt.Errorf("fixed-width payload has a truncated record of %d bytes, expected %d", len(data), c.length)
+		}
+		record, err := c.decodeRecord(data[:c.length])
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(records), err)
+		}
+		records = append(records, record)
+		data = []byte(strings.TrimLeft(string(data[c.length:]), "\r\n"))
+	}
+	return map[string]interface{}{"records": records}, nil
+}
+
+func (c *FixedWidth) decodeRecord(data []byte) (map[string]interface{}, error) {
+	record := make(map[string]interface{}, len(c.Fields))
+	offset := 0
+	for _, field := range c.Fields {
+		raw := strings.TrimSpace(string(data[offset : offset+field.Length]))
+		offset += field.Length
+
+		switch field.Type {
+		case "int":
+			if raw == "" {
+				record[field.Name] = 0
+				continue
+			}
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: invalid number %q", field.Name, raw)
+			}
+			record[field.Name] = n
+		case "decimal":
+			if raw == "" {
+				record[field.Name] = 0.0
+				continue
+			}
+			f, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: invalid number %q", field.Name, raw)
+			}
+			record[field.Name] = f
+		default:
+			record[field.Name] = raw
+		}
+	}
+	return record, nil
+}
+
+// Encode implements Codec; values that do not fit their field are rejected
+func (c *FixedWidth) Encode(value map[string]interface{}) ([]byte, error) {
+	var b strings.Builder
+	for _, field := range c.Fields {
+		var text string
+		if v, ok := value[field.Name]; ok && v != nil {
+			text = fmt.Sprint(v)
+		}
+		if len(text) > field.Length {
+			return nil, fmt.Errorf("field %s: %q exceeds %d bytes", field.Name, text, field.Length)
+		}
+
+		padding := strings.Repeat(" ", field.Length-len(text))
+		switch field.Type {
+		case "int", "decimal":
+			if text == "" {
+				text = "0"
+			}
+			negative := strings.HasPrefix(text, "-")
+			digits := strings.TrimPrefix(text, "-")
+			zeros := strings.Repeat("0", field.Length-len(text))
+			if negative {
+				b.WriteString("-" + zeros + digits)
+			} else {
+				b.WriteString(zeros + digits)
+			}
+		default:
+			b.WriteString(text + padding)
+		}
+	}
+	return []byte(b.String()), nil
+}
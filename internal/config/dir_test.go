@@ -0,0 +1,68 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func TestConfigDirectory(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"00-adapter.yaml": `
+adapter:
+  type: rest
+  name: crm
+  baseUrl: http://localhost:8081
+`,
+		"customers.yaml": `
+adapter:
+  name: ignored
+mappings:
+  - intentPattern: "get.*customer"
+    endpoint: "/customers/{id}"
+    method: GET
+    when: "metadata.region == 'eu'"
+  - intentPattern: "get.*customer"
+    endpoint: "/customers/{id}"
+    method: GET
+`,
+		"orders.json":         `{"mappings": [{"intentPattern": "list.*orders", "endpoint": "/orders", "method": "GET"}]}`,
+		".remote-orders.json": `not a config`,
+		"notes.txt":           "not a config",
+		"archive/old.yaml":    "adapter: {type: soap}\n",
+	})
+
+	cfg, err := config.LoadFromFile(dir)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.Adapter.Name != "crm" || cfg.Adapter.Type != "rest" {
+		t.Errorf("Expected the first file's adapter, got %+v", cfg.Adapter)
+	}
+	var patterns []string
+	for _, mapping := range cfg.Mappings {
+		patterns = append(patterns, mapping.IntentPattern)
+	}
+	if strings.Join(patterns, ",") != "get.*customer,get.*customer,list.*orders" {
+		t.Errorf("Expected the mappings in file name order, got %v", patterns)
+	}
+}
+
+func TestConfigDirectoryErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"adapter.yaml": "adapter: {type: rest, baseUrl: http://localhost}\nmappings:\n  - intentPattern: x\n    endpoint: /x\n    method: GET\n",
+		"sales.yaml":   "mappings:\n  - intentPattern: y\n    endpoint: /y\n    method: GET\n  - intentPattern: x\n    endpoint: /x2\n    method: GET\n",
+	})
+	_, err := config.LoadFromFile(dir)
+	var schemaErr *config.SchemaError
+	if !errors.As(err, &schemaErr) || !strings.Contains(err.Error(), "mappings[2].intentPattern") ||
+		!strings.Contains(err.Error(), "sales.yaml line 5") || !strings.Contains(err.Error(), `mapping "x" is already defined in`) {
+		t.Errorf("Expected the duplicate mapping with its file and line, got %v", err)
+	}
+
+	if _, err := config.LoadFromFile(writeFiles(t, map[string]string{"README.md": "config"})); err == nil {
+		t.Error("Expected a directory without config files to be rejected")
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+	"github.com/A2AGateway/a2a-connector/internal/sops"
+)
+
+// readConfigFile reads a config file and returns it with the extension of
+// its format. Files encrypted with SOPS or age are decrypted in memory and
+// never written to disk decrypted: age files are named after their format
+// with .age appended, and decrypted SOPS files are YAML.
+func readConfigFile(path string) ([]byte, string, bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", false, err
+	}
+	ext := filepath.Ext(strings.TrimSuffix(strings.ToLower(path), ".age"))
+	if !sops.IsEncrypted(data) {
+		return data, ext, false, nil
+	}
+	if !sops.IsAge(data) {
+		ext = ".yaml"
+	}
+
+	keys, err := sops.KeysFromEnv()
+	if err != nil {
+		return nil, "", false, err
+	}
+	resolver := secrets.NewResolver(secrets.Options{})
+	keys.KMS = resolver.DecryptKMS
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+	decrypted, err := sops.Decrypt(ctx, data, keys)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("cannot decrypt %s: %v", path, err)
+	}
+	return decrypted, ext, true, nil
+}
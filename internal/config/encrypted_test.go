@@ -0,0 +1,66 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// encryptedAdapter is "adapter: {type: rest, baseUrl: http://localhost:8081}"
+// encrypted with age for ageIdentity
+const (
+	ageIdentity      = "AGE-SECRET-KEY-1ZP5FN0SFSUX7PY8DZJ2FH4ENQP4L936CF5E72WHQS3DCMVSR7P4SRJR3CD"
+	encryptedAdapter = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBHRW5QYWZZTWFONk1vNVFN
+a3UxVXVHREUySGxpQ0k0WFdkeXpHRzdLcXp3Ci9LNmsya2l0dHBLSVlQWEFFV0ZK
+bUlDSVR2V3RxRHQ0cUdHZHFVeWNwcncKLS0tIDNoMm5zWjdYQmxiYWEwNHlHcWJO
+bndRbGRrZ0s5RUZlM1VBQkVkUFAyMDAKAfUV4i1n1J0Tveq5/Dm02MtucIGol7+k
+AZ/YPaI6bcJFgG72qP8CyGhWN1FD8sfOPn6vmTkLZreQdC92zksKqNCG8zo1Czxl
+ppND1UQjhfCV4AaMVGcX
+-----END AGE ENCRYPTED FILE-----
+`
+)
+
+func TestEncryptedConfig(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"adapter.yaml.age": encryptedAdapter,
+		"orders.yaml":      "mappings:\n  - intentPattern: list.*orders\n    endpoint: /orders\n    method: GET\n",
+	})
+	t.Setenv("SOPS_AGE_KEY", ageIdentity)
+
+	cfg, err := config.LoadFromFile(filepath.Join(dir, "adapter.yaml.age"))
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.Adapter.Type != "rest" || cfg.Adapter.BaseURL != "http://localhost:8081" {
+		t.Errorf("Unexpected adapter %+v", cfg.Adapter)
+	}
+	out := filepath.Join(dir, "plain.yaml")
+	if err := config.SaveToFile(cfg, out); err == nil || !strings.Contains(err.Error(), "plain text") {
+		t.Errorf("Expected saving a decrypted config to be refused, got %v", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("Expected no decrypted file on disk")
+	}
+
+	// Encrypted files of a directory are merged like the others
+	cfg, err = config.LoadFromFile(dir)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.Adapter.Type != "rest" || len(cfg.Mappings) != 1 {
+		t.Errorf("Expected the encrypted adapter and the plain mappings, got %+v", cfg)
+	}
+	if err := config.SaveToFile(cfg, out); err == nil {
+		t.Error("Expected saving a config merged from an encrypted file to be refused")
+	}
+
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(dir, "missing.txt"))
+	if _, err := config.LoadFromFile(filepath.Join(dir, "adapter.yaml.age")); err == nil {
+		t.Error("Expected an encrypted config without a key to fail")
+	}
+}
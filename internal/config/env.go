@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvOverridePrefix starts environment variables that override config keys.
+// Path segments are separated by a double underscore and match the YAML keys
+// case-insensitively, e.g. A2A__ADAPTER__BASEURL or A2A__MAPPINGS__0__TIMEOUT__TOTAL.
+const EnvOverridePrefix = "A2A__"
+
+// envPathSeparator separates the config path segments of an override variable
+const envPathSeparator = "__"
+
+// ApplyEnvOverrides sets the config keys named by A2A__ variables in environ
+// (KEY=value entries, as returned by os.Environ). Variables are applied in
+// path order, list indexes compared as numbers, so list items are created
+// before later ones.
+func ApplyEnvOverrides(config *ConnectorConfig, environ []string) error {
+	type override struct {
+		name  string
+		path  []string
+		value string
+	}
+	var overrides []override
+	for _, env := range environ {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], EnvOverridePrefix) {
+			continue
+		}
+		path := strings.Split(strings.TrimPrefix(parts[0], EnvOverridePrefix), envPathSeparator)
+		overrides = append(overrides, override{parts[0], path, parts[1]})
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return lessPath(overrides[i].path, overrides[j].path)
+	})
+
+	for _, o := range overrides {
+		if err := setPath(reflect.ValueOf(config).Elem(), o.path, o.value); err != nil {
+			return fmt.Errorf("invalid override %s: %v", o.name, err)
+		}
+	}
+	return nil
+}
+
+// lessPath orders paths segment by segment, comparing numeric segments as numbers
+func lessPath(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		x, errX := strconv.Atoi(a[i])
+		y, errY := strconv.Atoi(b[i])
+		if errX == nil && errY == nil {
+			return x < y
+		}
+		return a[i] < b[i]
+	}
+	return len(a) < len(b)
+}
+
+// setPath assigns value to the field, map entry or list item at path below v
+func setPath(v reflect.Value, path []string, value string) error {
+	if len(path) == 0 {
+		return setScalar(v, value)
+	}
+	segment := path[0]
+	if segment == "" {
+		return fmt.Errorf("empty path segment")
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setPath(v.Elem(), path, value)
+
+	case reflect.Struct:
+		field, ok := fieldByKey(v, segment)
+		if !ok {
+			return fmt.Errorf("unknown key %q", segment)
+		}
+		return setPath(field, path[1:], value)
+
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		// Map keys are user-defined names, so they are used as written
+		key := reflect.ValueOf(segment).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setPath(elem, path[1:], value); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+
+	case reflect.Slice:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 {
+			return fmt.Errorf("list index %q is not a number", segment)
+		}
+		if index > v.Len() {
+			return fmt.Errorf("list index %d skips items (list has %d)", index, v.Len())
+		}
+		if index == v.Len() {
+			v.Set(reflect.Append(v, reflect.New(v.Type().Elem()).Elem()))
+		}
+		return setPath(v.Index(index), path[1:], value)
+
+	case reflect.Interface:
+		// Free-form values such as mapping params take nested maps of strings
+		inner := map[string]interface{}{}
+		if existing, ok := v.Interface().(map[string]interface{}); ok {
+			inner = existing
+		}
+		m := reflect.ValueOf(inner)
+		if err := setPath(m, path, value); err != nil {
+			return err
+		}
+		v.Set(m)
+		return nil
+
+	default:
+		return fmt.Errorf("key %q cannot have nested keys", segment)
+	}
+}
+
+// fieldByKey finds the struct field whose YAML key matches segment,
+// ignoring case and underscores
+func fieldByKey(v reflect.Value, segment string) (reflect.Value, bool) {
+	want := strings.ToLower(strings.ReplaceAll(segment, "_", ""))
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if key == "-" || field.PkgPath != "" {
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+		if strings.ToLower(key) == want {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setScalar parses value into v; lists of scalars take comma-separated values
+func setScalar(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(value))
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setScalar(v.Elem(), value)
+	case reflect.Slice:
+		var items []string
+		if value != "" {
+			items = strings.Split(value, ",")
+		}
+		list := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setScalar(list.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+	default:
+		return fmt.Errorf("a %s cannot be set from a string", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter:  config.AdapterConfig{Type: "rest", BaseURL: "http://localhost:8081"},
+		Mappings: []config.MappingConfig{{IntentPattern: "get.*order", Endpoint: "/orders", Method: "GET"}},
+	}
+
+	err := config.ApplyEnvOverrides(cfg, []string{
+		"A2A__ADAPTER__BASEURL=https://erp.internal",
+		"A2A__ADAPTER__RETRY__MAXATTEMPTS=5",
+		"A2A__ADAPTER__HEADERS__X-Tenant=acme",
+		"A2A__ADAPTER__SUCCESS_STATUSES=2xx, 404",
+		"A2A__MAPPINGS__0__TIMEOUT__TOTAL=30s",
+		"A2A__MAPPINGS__1__INTENTPATTERN=list.*order",
+		"A2A__MAPPINGS__1__PARAMS__filter__status=open",
+		"A2A__METRICS__MAXLABELVALUES=20",
+		"A2A_API_KEY=ignored",
+		"PATH=/usr/bin",
+	})
+	if err != nil {
+		t.Fatalf("ApplyEnvOverrides failed: %v", err)
+	}
+
+	if cfg.Adapter.BaseURL != "https://erp.internal" || cfg.Adapter.Retry == nil || cfg.Adapter.Retry.MaxAttempts != 5 {
+		t.Errorf("Unexpected adapter: %+v", cfg.Adapter)
+	}
+	if cfg.Adapter.Headers["X-Tenant"] != "acme" {
+		t.Errorf("Expected map keys as written, got %v", cfg.Adapter.Headers)
+	}
+	if statuses := cfg.Adapter.SuccessStatuses; len(statuses) != 2 || statuses[1] != "404" {
+		t.Errorf("Expected comma-separated list, got %v", statuses)
+	}
+	if len(cfg.Mappings) != 2 || cfg.Mappings[0].Timeout.Total != "30s" || cfg.Mappings[1].IntentPattern != "list.*order" {
+		t.Fatalf("Unexpected mappings: %+v", cfg.Mappings)
+	}
+	if filter, ok := cfg.Mappings[1].Params["filter"].(map[string]interface{}); !ok || filter["status"] != "open" {
+		t.Errorf("Unexpected params: %v", cfg.Mappings[1].Params)
+	}
+	if cfg.Metrics.MaxLabelValues != 20 {
+		t.Errorf("Expected maxLabelValues 20, got %d", cfg.Metrics.MaxLabelValues)
+	}
+
+	for _, env := range []string{
+		"A2A__ADAPTER__NOSUCHKEY=x",
+		"A2A__MAPPINGS__5__METHOD=GET",
+		"A2A__ADAPTER__RETRY__MAXATTEMPTS=many",
+	} {
+		if err := config.ApplyEnvOverrides(cfg, []string{env}); err == nil {
+			t.Errorf("Expected %s to be rejected", env)
+		}
+	}
+}
+
+func TestLoadFromFileEnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(templatedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("A2A__ADAPTER__BASEURL", "https://crm.internal")
+	defer os.Unsetenv("A2A__ADAPTER__BASEURL")
+
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.Adapter.BaseURL != "https://crm.internal" {
+		t.Errorf("Expected overridden baseUrl, got %s", cfg.Adapter.BaseURL)
+	}
+}
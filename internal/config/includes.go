@@ -0,0 +1,265 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includedFile is a config file pulled in by include, parsed
+type includedFile struct {
+	path      string
+	root      *yaml.Node
+	encrypted bool
+}
+
+// Files returns the files the config was read from: the config file, or
+// those of a config directory, and the files they include
+func (c *ConnectorConfig) Files() []string {
+	return c.files
+}
+
+// loadIncludes reads the files listed under include in root, and those they
+// include in turn, relative to the file that lists them. Each file is read
+// once; a file that includes itself through others is an error.
+func loadIncludes(path string, root *yaml.Node) ([]includedFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []includedFile
+	seen := map[string]bool{abs: true}
+	if err := collectIncludes(abs, root, []string{abs}, seen, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func collectIncludes(path string, root *yaml.Node, stack []string, seen map[string]bool, files *[]includedFile) error {
+	patterns, err := includePatterns(root)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid include %q: %v", path, pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return fmt.Errorf("%s: included file %s does not exist", path, pattern)
+		}
+		for _, match := range matches {
+			for i, p := range stack {
+				if p == match {
+					cycle := append(append([]string(nil), stack[i:]...), match)
+					return fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+				}
+			}
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+
+			data, _, encrypted, err := readConfigFile(match)
+			if err != nil {
+				return fmt.Errorf("error reading included file: %v", err)
+			}
+			var included yaml.Node
+			if err := yaml.Unmarshal(data, &included); err != nil {
+				return fmt.Errorf("error parsing included file %s: %v", match, err)
+			}
+			*files = append(*files, includedFile{path: match, root: &included, encrypted: encrypted})
+			if err := collectIncludes(match, &included, append(stack, match), seen, files); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// includePatterns returns the include list of a parsed file
+func includePatterns(root *yaml.Node) ([]string, error) {
+	value := mappingValue(documentNode(root), "include")
+	if value == nil {
+		return nil, nil
+	}
+	var patterns []string
+	if err := value.Decode(&patterns); err != nil {
+		return nil, fmt.Errorf("include must be a list of files")
+	}
+	return patterns, nil
+}
+
+// mergeIncluded merges an included file into root: keys root lacks are
+// added, mappings are merged key by key and lists are appended, so values
+// set in root win and its list items come first
+func mergeIncluded(root, included *yaml.Node) {
+	root, included = documentNode(root), documentNode(included)
+	if root.Kind != yaml.MappingNode || included.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(included.Content); i += 2 {
+		key, value := included.Content[i], included.Content[i+1]
+		if key.Value == "include" {
+			continue
+		}
+		existing := mappingValue(root, key.Value)
+		switch {
+		case existing == nil:
+			root.Content = append(root.Content, key, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeIncluded(existing, value)
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			existing.Content = append(existing.Content, value.Content...)
+		}
+	}
+}
+
+// duplicateMappings reports the mappings of a file with the intent pattern
+// and condition of a mapping in an earlier file, at their index once merged.
+// Repeats within a file are left to its author.
+func duplicateMappings(files []includedFile) []FieldError {
+	type definition struct {
+		path string
+		line int
+	}
+	var problems []FieldError
+	defined := make(map[string]definition)
+	index := 0
+	for _, file := range files {
+		mappings := mappingValue(documentNode(file.root), "mappings")
+		if mappings == nil || mappings.Kind != yaml.SequenceNode {
+			continue
+		}
+		own := make(map[string]bool)
+		for _, mapping := range mappings.Content {
+			i := index
+			index++
+			pattern := mappingValue(mapping, "intentPattern")
+			if pattern == nil {
+				continue
+			}
+			key := pattern.Value
+			if when := mappingValue(mapping, "when"); when != nil {
+				key += "\x00" + when.Value
+			}
+			first, ok := defined[key]
+			if ok && !own[key] {
+				problems = append(problems, FieldError{
+					Path:    fmt.Sprintf("mappings[%d].intentPattern", i),
+					File:    file.path,
+					Line:    pattern.Line,
+					Message: fmt.Sprintf("mapping %q is already defined in %s line %d", pattern.Value, first.path, first.line),
+				})
+				continue
+			}
+			if !ok {
+				defined[key] = definition{path: file.path, line: pattern.Line}
+			}
+			own[key] = true
+		}
+	}
+	return problems
+}
+
+// documentNode returns the top node of a parsed file
+func documentNode(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	return node
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// ExpandFragments adds the named fragments a mapping refers to: the
+// parameter mappings of its parameterSets before its own, the headers of its
+// headerSets under its own, and the response transform of its transformSet
+// under the fields it sets itself. The references are cleared once expanded.
+func (c *ConnectorConfig) ExpandFragments() error {
+	for i := range c.Mappings {
+		mapping := &c.Mappings[i]
+
+		var params []ParameterMapping
+		for _, name := range mapping.ParameterSets {
+			set, ok := c.ParameterSets[name]
+			if !ok {
+				return fmt.Errorf("mapping %d uses unknown parameter set %q", i, name)
+			}
+			params = append(params, set...)
+		}
+		if len(params) > 0 {
+			mapping.ParameterMappings = append(params, mapping.ParameterMappings...)
+		}
+
+		if len(mapping.HeaderSets) > 0 {
+			headers := make(map[string]string)
+			for _, name := range mapping.HeaderSets {
+				set, ok := c.HeaderSets[name]
+				if !ok {
+					return fmt.Errorf("mapping %d uses unknown header set %q", i, name)
+				}
+				for key, value := range set {
+					headers[key] = value
+				}
+			}
+			for key, value := range mapping.Headers {
+				headers[key] = value
+			}
+			mapping.Headers = headers
+		}
+
+		if name := mapping.TransformSet; name != "" {
+			set, ok := c.TransformSets[name]
+			if !ok {
+				return fmt.Errorf("mapping %d uses unknown transform set %q", i, name)
+			}
+			transform := copyTransform(set)
+			overlayFields(reflect.ValueOf(&transform).Elem(), reflect.ValueOf(&mapping.ResponseTransform).Elem())
+			mapping.ResponseTransform = transform
+		}
+
+		mapping.ParameterSets, mapping.HeaderSets, mapping.TransformSet = nil, nil, ""
+	}
+	return nil
+}
+
+// copyTransform deep-copies a transform set so mappings don't share its
+// slices and maps
+func copyTransform(set ResponseTransform) ResponseTransform {
+	var transform ResponseTransform
+	if data, err := yaml.Marshal(set); err == nil {
+		yaml.Unmarshal(data, &transform)
+	}
+	return transform
+}
+
+// overlayFields copies the exported fields that are set on src onto dst
+func overlayFields(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Type().Field(i)
+		if field.PkgPath != "" || field.Tag.Get("yaml") == "-" {
+			continue
+		}
+		if !src.Field(i).IsZero() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestIncludesAndFragments(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": `
+include: [shared/*.yaml]
+adapter:
+  type: rest
+  name: crm
+  baseUrl: http://localhost:8081
+headerSets:
+  tracing:
+    X-Source: a2a
+mappings:
+  - intentPattern: "get.*customer"
+    endpoint: "/customers/{id}"
+    method: GET
+    parameterSets: [customer-id]
+    parameterMappings:
+      - source: metadata.region
+        target: region
+    headerSets: [erp, tracing]
+    headers:
+      X-Client: connector
+    transformSet: customer
+    responseTransform:
+      statusPath: result.state
+`,
+		"shared/fragments.yaml": `
+include: [../more/orders.yaml]
+adapter:
+  name: ignored
+parameterSets:
+  customer-id:
+    - source: text
+      pattern: "customer (\\d+)"
+      target: id
+      type: int
+headerSets:
+  erp:
+    X-Client: erp
+    X-Tenant: "42"
+transformSets:
+  customer:
+    template: "Customer: {{.result.name}}"
+    statusPath: result.status
+`,
+		"more/orders.yaml": `
+mappings:
+  - intentPattern: "list.*orders"
+    endpoint: "/orders"
+    method: GET
+    headerSets: [erp]
+`,
+	})
+
+	cfg, err := config.LoadFromFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.Adapter.Name != "crm" || len(cfg.Mappings) != 2 || cfg.Mappings[1].IntentPattern != "list.*orders" {
+		t.Fatalf("Expected the included mappings after the file's own, got %+v", cfg.Mappings)
+	}
+
+	customer := cfg.Mappings[0]
+	if len(customer.ParameterMappings) != 2 || customer.ParameterMappings[0].Target != "id" || customer.ParameterMappings[1].Target != "region" {
+		t.Errorf("Expected the parameter set before the mapping's own, got %+v", customer.ParameterMappings)
+	}
+	if customer.Headers["X-Client"] != "connector" || customer.Headers["X-Tenant"] != "42" || customer.Headers["X-Source"] != "a2a" {
+		t.Errorf("Unexpected headers %v", customer.Headers)
+	}
+	if customer.ResponseTransform.StatusPath != "result.state" || customer.ResponseTransform.CompiledTemplate == nil {
+		t.Errorf("Expected the transform set under the mapping's fields, got %+v", customer.ResponseTransform)
+	}
+	if orders := cfg.Mappings[1]; orders.Headers["X-Tenant"] != "42" {
+		t.Errorf("Expected the header set in the included mapping, got %v", orders.Headers)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "include: [b.yaml]\nadapter: {type: rest, baseUrl: http://localhost}\n",
+		"b.yaml": "include: [a.yaml]\n",
+	})
+	_, err := config.LoadFromFile(filepath.Join(dir, "a.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Expected an include cycle, got %v", err)
+	}
+
+	dir = writeFiles(t, map[string]string{
+		"config.yaml": "include: [shared.yaml]\nadapter: {type: rest, baseUrl: http://localhost}\nmappings:\n  - intentPattern: x\n    parameterSets: [missing]\n",
+		"shared.yaml": "mappings:\n  - intentPattern: y\n",
+	})
+	if _, err := config.LoadFromFile(filepath.Join(dir, "config.yaml")); err == nil || !strings.Contains(err.Error(), `unknown parameter set "missing"`) {
+		t.Errorf("Expected an unknown set to be rejected, got %v", err)
+	}
+
+	dir = writeFiles(t, map[string]string{
+		"config.yaml": "include: [shared.yaml]\nadapter: {type: rest, baseUrl: http://localhost}\n",
+		"shared.yaml": "mappings:\n  - intentPattern: y\n    method: GET\n    endpiont: /y\n",
+	})
+	_, err = config.LoadFromFile(filepath.Join(dir, "config.yaml"))
+	var schemaErr *config.SchemaError
+	if !errors.As(err, &schemaErr) || !strings.Contains(err.Error(), "shared.yaml line 4") {
+		t.Errorf("Expected the unknown key with its file and line, got %v", err)
+	}
+}
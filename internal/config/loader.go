@@ -1,26 +1,108 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/A2AGateway/a2a-connector/internal/charset"
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+	"github.com/A2AGateway/a2a-connector/internal/schedule"
 	"gopkg.in/yaml.v3"
 )
 
 // LoadFromFile loads configuration from a file in YAML or JSON format
 func LoadFromFile(filePath string) (*ConnectorConfig, error) {
-	data, err := ioutil.ReadFile(filePath)
+	return load(filePath, true)
+}
+
+// LoadOffline loads configuration like LoadFromFile but leaves vault:, awssm:
+// and file: references unresolved, to check a config without access to its
+// secrets
+func LoadOffline(filePath string) (*ConnectorConfig, error) {
+	return load(filePath, false)
+}
+
+func load(filePath string, resolve bool) (*ConnectorConfig, error) {
+	var config ConnectorConfig
+	var err error
+	if info, statErr := os.Stat(filePath); statErr == nil && info.IsDir() {
+		config, err = parseDir(filePath)
+	} else {
+		config, err = parseFile(filePath)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error reading config file: %v", err)
+		return nil, err
+	}
+
+	// Apply A2A__ environment overrides of individual keys
+	if err := ApplyEnvOverrides(&config, os.Environ()); err != nil {
+		return nil, fmt.Errorf("error applying environment overrides: %v", err)
+	}
+
+	// Instantiate mapping templates
+	if err := config.ExpandMappingTemplates(); err != nil {
+		return nil, fmt.Errorf("error expanding mapping templates: %v", err)
+	}
+
+	// Add the parameter, header and transform sets mappings refer to
+	if err := config.ExpandFragments(); err != nil {
+		return nil, fmt.Errorf("error expanding fragments: %v", err)
+	}
+
+	// Process environment variables
+	processEnvironmentVariables(&config)
+
+	// Resolve variable references; in strict mode a reference to an
+	// undefined variable fails the load
+	if err := config.ResolveVariables(); err != nil {
+		return nil, err
+	}
+	if config.StrictVariables {
+		if err := config.UnresolvedVariables(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Replace vault:, awssm: and file: references with the secrets
+	if resolve {
+		if err := resolveSecrets(&config); err != nil {
+			return nil, fmt.Errorf("error resolving secrets: %v", err)
+		}
+		registerRedactions(&config)
+	} else {
+		config.Secrets.Refs = secretReferences(&config)
+	}
+
+	// Report every unknown key and invalid value with its path and line
+	if err := validateSchema(&config); err != nil {
+		return nil, err
+	}
+
+	// Compile regular expressions and templates
+	if err := config.Compile(); err != nil {
+		return nil, fmt.Errorf("error compiling regular expressions: %v", err)
 	}
 
-	// Determine file type based on extension
-	ext := strings.ToLower(filepath.Ext(filePath))
+	return &config, nil
+}
+
+// parseFile parses a config file and the files it includes
+func parseFile(filePath string) (ConnectorConfig, error) {
 	var config ConnectorConfig
+	// Encrypted files are decrypted in memory; the file type is based on the extension
+	data, ext, encrypted, err := readConfigFile(filePath)
+	if err != nil {
+		return config, fmt.Errorf("error reading config file: %v", err)
+	}
 
 	switch ext {
 	case ".yaml", ".yml":
@@ -28,25 +110,199 @@ func LoadFromFile(filePath string) (*ConnectorConfig, error) {
 	case ".json":
 		err = json.Unmarshal(data, &config)
 	default:
-		return nil, fmt.Errorf("unsupported file format: %s. Please use .yaml, .yml, or .json", ext)
+		return config, fmt.Errorf("unsupported file format: %s. Please use .yaml, .yml, or .json", ext)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("error parsing config file: %v", err)
+		return config, fmt.Errorf("error parsing config file: %v", err)
 	}
 
-	// Process environment variables
-	processEnvironmentVariables(&config)
+	// Record the line of every key and report unknown keys. JSON is parsed
+	// as YAML here; files the YAML parser rejects are only checked for values.
+	if lines, problems, err := parseSchema(data); err == nil {
+		config.lines, config.keyProblems = lines, problems
+	}
 
-	// Resolve variable references
-	config.ResolveVariables()
+	// Merge the files listed under include; values and list items of this
+	// file come first
+	if len(config.Include) > 0 {
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return config, fmt.Errorf("error parsing config file: %v", err)
+		}
+		included, err := loadIncludes(filePath, &root)
+		if err != nil {
+			return config, fmt.Errorf("error including config files: %v", err)
+		}
+		files := append([]includedFile{{path: filePath, root: &root, encrypted: encrypted}}, included...)
+		return mergeFiles(files, config.lines, config.keyProblems)
+	}
+	config.encrypted = encrypted
+	config.files = []string{filePath}
+	return config, nil
+}
 
-	// Compile regular expressions and templates
-	if err := config.Compile(); err != nil {
-		return nil, fmt.Errorf("error compiling regular expressions: %v", err)
+// parseDir parses the .yaml, .yml and .json files of a config directory,
+// with or without .age appended, in name order, with the files each includes
+// after it, and merges them like included files: the first file setting a
+// value wins and list items are appended in file order. Hidden files are
+// skipped.
+func parseDir(dir string) (ConnectorConfig, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ConnectorConfig{}, fmt.Errorf("error reading config directory: %v", err)
+	}
+	var paths []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		switch filepath.Ext(strings.TrimSuffix(strings.ToLower(name), ".age")) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		path, err := filepath.Abs(filepath.Join(dir, name))
+		if err != nil {
+			return ConnectorConfig{}, err
+		}
+		paths = append(paths, path)
+		seen[path] = true
+	}
+	if len(paths) == 0 {
+		return ConnectorConfig{}, fmt.Errorf("config directory %s has no .yaml, .yml or .json files", dir)
 	}
 
-	return &config, nil
+	var files []includedFile
+	for _, path := range paths {
+		data, _, encrypted, err := readConfigFile(path)
+		if err != nil {
+			return ConnectorConfig{}, fmt.Errorf("error reading config file: %v", err)
+		}
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return ConnectorConfig{}, fmt.Errorf("error parsing config file %s: %v", path, err)
+		}
+		files = append(files, includedFile{path: path, root: &root, encrypted: encrypted})
+		if err := collectIncludes(path, &root, []string{path}, seen, &files); err != nil {
+			return ConnectorConfig{}, fmt.Errorf("error including config files: %v", err)
+		}
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	return mergeFiles(append([]includedFile{{root: root}}, files...), nil, nil)
+}
+
+// mergeFiles merges files into the first one, whose key lines and problems
+// are given, and decodes the result. The keys of the other files are checked
+// here and a mapping repeated from another file is reported.
+func mergeFiles(files []includedFile, lines map[string]int, problems []FieldError) (ConnectorConfig, error) {
+	for _, file := range files[1:] {
+		_, fileProblems := checkKeys(file.root)
+		for _, problem := range fileProblems {
+			problem.File = file.path
+			problems = append(problems, problem)
+		}
+	}
+	problems = append(problems, duplicateMappings(files)...)
+	encrypted := files[0].encrypted
+	for _, file := range files[1:] {
+		mergeIncluded(files[0].root, file.root)
+		encrypted = encrypted || file.encrypted
+	}
+
+	config := ConnectorConfig{lines: lines, keyProblems: problems, encrypted: encrypted}
+	if err := files[0].root.Decode(&config); err != nil {
+		return config, fmt.Errorf("error parsing included config files: %v", err)
+	}
+	config.Include = nil
+	for _, file := range files {
+		if file.path != "" {
+			config.files = append(config.files, file.path)
+		}
+	}
+	return config, nil
+}
+
+// SaveToFile writes configuration to a file in YAML or JSON format.
+// Empty fields are omitted so the output stays readable.
+func SaveToFile(config *ConnectorConfig, filePath string) error {
+	if config.encrypted {
+		return fmt.Errorf("config was decrypted from an encrypted file; refusing to write it in plain text")
+	}
+	data, err := Marshal(config, filepath.Ext(filePath))
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filePath, data, 0600); err != nil {
+		return fmt.Errorf("error writing config file: %v", err)
+	}
+	return nil
+}
+
+// Marshal encodes configuration as YAML or JSON depending on the file extension
+func Marshal(config *ConnectorConfig, ext string) ([]byte, error) {
+	// Encode as JSON first so the omitempty rules of the json tags apply
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding config: %v", err)
+	}
+
+	switch strings.ToLower(ext) {
+	case ".json":
+		return append(data, '\n'), nil
+	case ".yaml", ".yml":
+		// JSON is valid YAML; decoding into a node keeps the key order
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("error encoding config: %v", err)
+		}
+		resetNodeStyle(&node)
+		pruneEmptyNodes(&node)
+
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&node); err != nil {
+			return nil, fmt.Errorf("error encoding config: %v", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported file format: %s. Please use .yaml, .yml, or .json", ext)
+	}
+}
+
+// resetNodeStyle switches JSON flow style and quoting to plain block YAML
+func resetNodeStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetNodeStyle(child)
+	}
+}
+
+// pruneEmptyNodes removes keys whose values are empty strings or empty objects
+func pruneEmptyNodes(node *yaml.Node) {
+	for _, child := range node.Content {
+		pruneEmptyNodes(child)
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	var content []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		if value.Kind == yaml.MappingNode && len(value.Content) == 0 {
+			continue
+		}
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!str" && value.Value == "" {
+			continue
+		}
+		content = append(content, node.Content[i], value)
+	}
+	node.Content = content
 }
 
 // processEnvironmentVariables loads environment variables into the configuration
@@ -54,6 +310,7 @@ func processEnvironmentVariables(config *ConnectorConfig) {
 	if config.Variables == nil {
 		config.Variables = make(map[string]string)
 	}
+	config.variableSources = make(map[string]string)
 
 	// Load environment variables
 	for _, env := range os.Environ() {
@@ -63,6 +320,7 @@ func processEnvironmentVariables(config *ConnectorConfig) {
 			if strings.HasPrefix(parts[0], "A2A_") ||
 				strings.HasPrefix(parts[0], "CONNECTOR_") {
 				config.Variables[parts[0]] = parts[1]
+				config.variableSources[parts[0]] = VariableSourceEnvironment
 			}
 		}
 	}
@@ -70,13 +328,303 @@ func processEnvironmentVariables(config *ConnectorConfig) {
 
 // ValidateConfig validates that the configuration is complete and usable
 func ValidateConfig(config *ConnectorConfig) error {
+	if err := validateSchema(config); err != nil {
+		return err
+	}
+
 	// Validate adapter configuration
 	if config.Adapter.Type == "" {
 		return fmt.Errorf("adapter type is required")
 	}
-	if config.Adapter.BaseURL == "" {
+	if config.Adapter.Type == "db" {
+		if config.Adapter.Driver == "" || config.Adapter.DSN == "" {
+			return fmt.Errorf("adapter driver and dsn are required for db adapters")
+		}
+	} else if config.Adapter.Type == "file" {
+		if config.Adapter.BasePath == "" {
+			return fmt.Errorf("adapter basePath is required for file adapters")
+		}
+	} else if config.Adapter.Type == "tcp" {
+		if err := validateSocket(config.Adapter); err != nil {
+			return fmt.Errorf("adapter socket: %v", err)
+		}
+	} else if config.Adapter.Type == "telnet" {
+		if err := validateTelnet(config.Adapter.Telnet); err != nil {
+			return fmt.Errorf("adapter telnet: %v", err)
+		}
+	} else if config.Adapter.Type == "echo" {
+		if err := validateEcho(config.Adapter.Echo); err != nil {
+			return fmt.Errorf("adapter echo: %v", err)
+		}
+	} else if config.Adapter.Type == "plugin" {
+		if config.Adapter.Plugin == "" {
+			return fmt.Errorf("adapter plugin is required for plugin adapters")
+		}
+		if config.Plugin(config.Adapter.Plugin) == nil {
+			return fmt.Errorf("adapter plugin %q is not defined in plugins", config.Adapter.Plugin)
+		}
+	} else if config.Adapter.BaseURL == "" && config.Adapter.OpenAPI == "" {
 		return fmt.Errorf("adapter baseUrl is required")
 	}
+	if retry := config.Adapter.Retry; retry != nil {
+		if retry.MaxAttempts < 0 {
+			return fmt.Errorf("adapter retry maxAttempts must not be negative")
+		}
+		for _, d := range []string{retry.InitialBackoff, retry.MaxBackoff} {
+			if d == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(d); err != nil {
+				return fmt.Errorf("adapter retry has invalid duration %q: %v", d, err)
+			}
+		}
+		if retry.Jitter < 0 || retry.Jitter > 1 {
+			return fmt.Errorf("adapter retry jitter must be between 0 and 1")
+		}
+	}
+
+	if err := validateTimeout(config.Adapter.Timeout); err != nil {
+		return fmt.Errorf("adapter timeout: %v", err)
+	}
+	if _, _, err := config.BodyLimits.Sizes(); err != nil {
+		return fmt.Errorf("bodyLimits: %v", err)
+	}
+	if !charset.Supported(config.Adapter.Charset) {
+		return fmt.Errorf("adapter has unsupported charset %q", config.Adapter.Charset)
+	}
+	if tls := config.Adapter.TLS; tls != nil && (tls.CertFile == "") != (tls.KeyFile == "") {
+		return fmt.Errorf("adapter tls requires both certFile and keyFile for client certificates")
+	}
+	for _, codec := range config.Adapter.Codecs {
+		if codec.ContentType == "" {
+			return fmt.Errorf("adapter codec requires a contentType")
+		}
+		if codec.Type != "fixed" {
+			return fmt.Errorf("adapter codec %s has unsupported type %q", codec.ContentType, codec.Type)
+		}
+	}
+	if auth := config.Adapter.Auth; auth.Type == "oauth2" {
+		if auth.OAuth2 == nil || auth.OAuth2.TokenURL == "" || auth.OAuth2.ClientID == "" {
+			return fmt.Errorf("adapter oauth2 auth requires tokenUrl and clientId")
+		}
+		switch auth.OAuth2.GrantType {
+		case "", "client_credentials":
+		case "refresh_token":
+			if auth.OAuth2.RefreshToken == "" {
+				return fmt.Errorf("adapter oauth2 refresh_token grant requires a refreshToken")
+			}
+		default:
+			return fmt.Errorf("adapter oauth2 has unsupported grantType %q", auth.OAuth2.GrantType)
+		}
+	}
+	if limits := config.Adapter.Limits; limits != nil {
+		if limits.RatePerSecond < 0 || limits.Burst < 0 || limits.MaxConcurrent < 0 {
+			return fmt.Errorf("adapter limits must not be negative")
+		}
+		switch limits.Overflow {
+		case "", "queue", "reject":
+		default:
+			return fmt.Errorf("adapter limits has unsupported overflow %q", limits.Overflow)
+		}
+		if limits.MaxWait != "" {
+			if _, err := time.ParseDuration(limits.MaxWait); err != nil {
+				return fmt.Errorf("adapter limits has invalid maxWait %q: %v", limits.MaxWait, err)
+			}
+		}
+	}
+	if policy := config.Adapter.SQLPolicy; policy != nil {
+		for _, verb := range policy.Verbs {
+			if !contains(SQLVerbs, strings.ToUpper(verb)) {
+				return fmt.Errorf("adapter sqlPolicy has unsupported verb %q", verb)
+			}
+		}
+	}
+	if limits := config.Adapter.QueryLimits; limits != nil {
+		if limits.MaxRows < 0 {
+			return fmt.Errorf("adapter queryLimits maxRows must not be negative")
+		}
+		if limits.InjectLimit && limits.MaxRows == 0 {
+			return fmt.Errorf("adapter queryLimits injectLimit requires maxRows")
+		}
+		if limits.Timeout != "" {
+			if _, err := time.ParseDuration(limits.Timeout); err != nil {
+				return fmt.Errorf("adapter queryLimits has invalid timeout %q: %v", limits.Timeout, err)
+			}
+		}
+	}
+	if workers := config.Workers; workers != nil {
+		if workers.Size <= 0 {
+			return fmt.Errorf("workers size must be positive")
+		}
+		if workers.QueueSize < 0 {
+			return fmt.Errorf("workers queueSize must not be negative")
+		}
+	}
+	if cb := config.CircuitBreaker; cb != nil && cb.CoolDown != "" {
+		if _, err := time.ParseDuration(cb.CoolDown); err != nil {
+			return fmt.Errorf("circuitBreaker has invalid coolDown %q: %v", cb.CoolDown, err)
+		}
+	}
+
+	switch config.Integrity.Mode {
+	case "", "digest":
+	case "jws":
+		if config.Integrity.Secret == "" {
+			return fmt.Errorf("integrity secret is required for jws mode")
+		}
+	default:
+		return fmt.Errorf("unsupported integrity mode %q", config.Integrity.Mode)
+	}
+
+	switch strings.ToLower(config.Logging.Level) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		return fmt.Errorf("unsupported logging level %q", config.Logging.Level)
+	}
+	switch strings.ToLower(config.Logging.Format) {
+	case "", "console", "text", "json":
+	default:
+		return fmt.Errorf("unsupported logging format %q", config.Logging.Format)
+	}
+
+	for _, d := range []string{config.Soak.Interval, config.Soak.Duration, config.Soak.Window} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("soak has invalid duration %q: %v", d, err)
+		}
+	}
+
+	for _, d := range []string{config.Readiness.Timeout, config.Readiness.CacheTTL} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("readiness has invalid duration %q: %v", d, err)
+		}
+	}
+
+	k8s := config.Kubernetes
+	for _, d := range []string{k8s.ReloadInterval, k8s.DrainDelay, k8s.ShutdownTimeout} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("kubernetes has invalid duration %q: %v", d, err)
+		}
+	}
+	if le := k8s.LeaderElection; le != nil {
+		if le.LeaseName == "" {
+			return fmt.Errorf("kubernetes leaderElection requires a leaseName")
+		}
+		for _, d := range []string{le.LeaseDuration, le.RenewInterval} {
+			if d == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(d); err != nil {
+				return fmt.Errorf("kubernetes leaderElection has invalid duration %q: %v", d, err)
+			}
+		}
+	}
+
+	names, listeners := config.Server.AllListeners()
+	for i, listener := range listeners {
+		name := names[i]
+		if i >= len(Planes) {
+			if len(listener.Planes) == 0 {
+				return fmt.Errorf("server %s requires planes", name)
+			}
+			for _, plane := range listener.Planes {
+				if !contains(Planes, plane) {
+					return fmt.Errorf("server %s has unsupported plane %q, expected one of %s", name, plane, strings.Join(Planes, ", "))
+				}
+			}
+			if listener.Address == "" {
+				return fmt.Errorf("server %s requires an address", name)
+			}
+		} else if len(listener.Planes) > 0 {
+			return fmt.Errorf("server %s does not take planes; they are set on server.listeners", name)
+		}
+		if tls := listener.TLS; tls != nil {
+			if tls.CertFile == "" || tls.KeyFile == "" {
+				return fmt.Errorf("server %s tls requires certFile and keyFile", name)
+			}
+			switch tls.MinVersion {
+			case "", "1.2", "1.3":
+			default:
+				return fmt.Errorf("server %s tls has unsupported minVersion %q", name, tls.MinVersion)
+			}
+			switch tls.ClientAuth {
+			case "", "require", "optional":
+			default:
+				return fmt.Errorf("server %s tls has unsupported clientAuth %q", name, tls.ClientAuth)
+			}
+			if (tls.ClientAuth != "" || len(tls.ClientNames) > 0) && tls.ClientCAFile == "" {
+				return fmt.Errorf("server %s tls requires clientCAFile for client authentication", name)
+			}
+		}
+		switch listener.Auth.Type {
+		case "", "none":
+		case "basic":
+			if listener.Auth.Username == "" || listener.Auth.Password == "" {
+				return fmt.Errorf("server %s basic auth requires username and password", name)
+			}
+		case "bearer":
+			if listener.Auth.Token == "" {
+				return fmt.Errorf("server %s bearer auth requires a token", name)
+			}
+		default:
+			return fmt.Errorf("server %s has unsupported auth type %q", name, listener.Auth.Type)
+		}
+		if err := validateSSO(name, *listener); err != nil {
+			return err
+		}
+	}
+
+	if config.Secrets.RefreshInterval != "" {
+		if _, err := time.ParseDuration(config.Secrets.RefreshInterval); err != nil {
+			return fmt.Errorf("secrets has invalid refreshInterval %q: %v", config.Secrets.RefreshInterval, err)
+		}
+	}
+
+	for i, key := range config.Security.APIKeys {
+		if key.Key == "" {
+			return fmt.Errorf("security apiKey %d is missing key", i)
+		}
+	}
+	for i, key := range config.Security.HMACKeys {
+		if key.KeyID == "" || key.Secret == "" {
+			return fmt.Errorf("security hmacKey %d requires keyId and secret", i)
+		}
+	}
+	if config.Security.MaxClockSkew != "" {
+		if _, err := time.ParseDuration(config.Security.MaxClockSkew); err != nil {
+			return fmt.Errorf("security has invalid maxClockSkew %q: %v", config.Security.MaxClockSkew, err)
+		}
+	}
+	if jwt := config.Security.JWT; jwt != nil && (jwt.Issuer == "" || jwt.JWKSURL == "") {
+		return fmt.Errorf("security jwt requires issuer and jwksUrl")
+	}
+	if err := validateAuthorization(config.Security); err != nil {
+		return err
+	}
+
+	switch config.Cache.Backend {
+	case "", "memory":
+	default:
+		return fmt.Errorf("unsupported cache backend %q", config.Cache.Backend)
+	}
+	switch config.Sessions.Backend {
+	case "", "memory":
+	default:
+		return fmt.Errorf("unsupported sessions backend %q", config.Sessions.Backend)
+	}
+	if config.Sessions.TTL != "" {
+		if d, err := time.ParseDuration(config.Sessions.TTL); err != nil || d <= 0 {
+			return fmt.Errorf("sessions has invalid ttl %q", config.Sessions.TTL)
+		}
+	}
 
 	// Validate mappings
 	if len(config.Mappings) == 0 {
@@ -86,13 +634,715 @@ func ValidateConfig(config *ConnectorConfig) error {
 		if mapping.IntentPattern == "" {
 			return fmt.Errorf("mapping %d is missing intentPattern", i)
 		}
-		if mapping.Endpoint == "" {
-			return fmt.Errorf("mapping %d is missing endpoint", i)
+		if len(mapping.Steps) > 0 && mapping.FanOut != nil {
+			return fmt.Errorf("mapping %d cannot have both steps and fanOut", i)
 		}
-		if mapping.Method == "" {
+		if mapping.Async != nil && (len(mapping.Steps) > 0 || mapping.FanOut != nil) {
+			return fmt.Errorf("mapping %d async cannot be used with steps or fanOut", i)
+		}
+		if err := validateAsync(mapping); err != nil {
+			return fmt.Errorf("mapping %d async %v", i, err)
+		}
+		if mapping.RequestTemplate != "" && (len(mapping.Steps) > 0 || mapping.FanOut != nil) {
+			return fmt.Errorf("mapping %d requestTemplate cannot be used with steps or fanOut", i)
+		}
+		if len(mapping.Steps) > 0 {
+			if err := validateSteps(mapping, mapping.Steps); err != nil {
+				return fmt.Errorf("mapping %d %v", i, err)
+			}
+		} else if fanOut := mapping.FanOut; fanOut != nil {
+			if len(fanOut.Branches) == 0 {
+				return fmt.Errorf("mapping %d fanOut requires branches", i)
+			}
+			switch fanOut.Policy {
+			case "", "partial", "all":
+			default:
+				return fmt.Errorf("mapping %d fanOut has unsupported policy %q, expected partial or all", i, fanOut.Policy)
+			}
+			if err := validateSteps(mapping, fanOut.Branches); err != nil {
+				return fmt.Errorf("mapping %d fanOut %v", i, err)
+			}
+		} else if mapping.Endpoint == "" {
+			return fmt.Errorf("mapping %d is missing endpoint", i)
+		} else if mapping.Method == "" {
 			return fmt.Errorf("mapping %d is missing method", i)
 		}
+		if err := validateTimeout(mapping.Timeout); err != nil {
+			return fmt.Errorf("mapping %d timeout: %v", i, err)
+		}
+		if !charset.Supported(mapping.Charset) {
+			return fmt.Errorf("mapping %d has unsupported charset %q", i, mapping.Charset)
+		}
+		for _, format := range []struct{ field, value string }{{"requestFormat", mapping.RequestFormat}, {"responseFormat", mapping.ResponseFormat}} {
+			switch format.value {
+			case "", "json", "xml", "form", "text":
+			default:
+				return fmt.Errorf("mapping %d has unsupported %s %q, expected json, xml, form or text", i, format.field, format.value)
+			}
+		}
+		if c := mapping.Cache; c != nil && c.Enabled {
+			if c.TTL == "" {
+				return fmt.Errorf("mapping %d cache requires a ttl", i)
+			}
+			if _, err := time.ParseDuration(c.TTL); err != nil {
+				return fmt.Errorf("mapping %d cache has invalid ttl %q: %v", i, c.TTL, err)
+			}
+		}
+		if mapping.TTL != "" {
+			if d, err := time.ParseDuration(mapping.TTL); err != nil || d <= 0 {
+				return fmt.Errorf("mapping %d has invalid ttl %q", i, mapping.TTL)
+			}
+		}
+		for j, param := range mapping.ParameterMappings {
+			switch param.Type {
+			case "", "string", "int", "integer", "number", "float", "bool", "boolean":
+			default:
+				return fmt.Errorf("mapping %d parameter %d has unsupported type %q", i, j, param.Type)
+			}
+		}
+		for j, rule := range mapping.Input {
+			if err := validateInputRule(rule); err != nil {
+				return fmt.Errorf("mapping %d input %d %v", i, j, err)
+			}
+		}
+		for j, rule := range mapping.Files {
+			if err := validateFileRule(rule); err != nil {
+				return fmt.Errorf("mapping %d file rule %d %v", i, j, err)
+			}
+		}
+		for j, artifact := range mapping.ResponseTransform.Artifacts {
+			if err := validateArtifact(artifact); err != nil {
+				return fmt.Errorf("mapping %d artifact %d %v", i, j, err)
+			}
+		}
+		for j, rule := range mapping.ResponseTransform.Mask {
+			if err := validateMaskRule(rule); err != nil {
+				return fmt.Errorf("mapping %d mask rule %d %v", i, j, err)
+			}
+		}
+		if err := validateSummary(mapping.ResponseTransform.Summary); err != nil {
+			return fmt.Errorf("mapping %d summary %v", i, err)
+		}
 	}
 
+	if err := validateIntent(config.Intent); err != nil {
+		return err
+	}
+	if err := validateAnonymize(config.Anonymize); err != nil {
+		return err
+	}
+	if err := validateEgress(config.Egress); err != nil {
+		return err
+	}
+	if err := validateDictionary(config.Dictionary); err != nil {
+		return err
+	}
+	if err := validateDeadLetter(config.DeadLetter); err != nil {
+		return err
+	}
+	if err := validateAudit(config.Audit); err != nil {
+		return err
+	}
+	if err := validateSchedules(config.Schedules); err != nil {
+		return err
+	}
+	if err := validateWebhooks(config.Webhooks); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateSteps checks the steps or fan-out branches of a mapping; each needs
+// a unique name to reference its result and a method of its own or of the
+// mapping
+func validateSteps(mapping MappingConfig, steps []StepConfig) error {
+	names := make(map[string]bool)
+	for j, step := range steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d is missing name", j)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("step %d has duplicate name %q", j, step.Name)
+		}
+		names[step.Name] = true
+		if step.Endpoint == "" {
+			return fmt.Errorf("step %s is missing endpoint", step.Name)
+		}
+		if step.Method == "" && mapping.Method == "" {
+			return fmt.Errorf("step %s is missing method", step.Name)
+		}
+		if step.Timeout != "" {
+			if d, err := time.ParseDuration(step.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("step %s has invalid timeout %q", step.Name, step.Timeout)
+			}
+		}
+	}
+	return nil
+}
+
+// validateAsync checks the job polling of a mapping; the poll and result
+// calls need an endpoint and a method of their own or of the mapping
+func validateAsync(mapping MappingConfig) error {
+	async := mapping.Async
+	if async == nil {
+		return nil
+	}
+	if async.JobID == "" {
+		return fmt.Errorf("requires jobId")
+	}
+	if async.Done == "" {
+		return fmt.Errorf("requires done")
+	}
+	for _, name := range []string{"poll", "result"} {
+		call := &async.Poll
+		if name == "result" {
+			if call = async.Result; call == nil {
+				continue
+			}
+		}
+		if call.Endpoint == "" {
+			return fmt.Errorf("%s is missing endpoint", name)
+		}
+		if call.Method == "" && mapping.Method == "" {
+			return fmt.Errorf("%s is missing method", name)
+		}
+		if call.Timeout != "" {
+			if d, err := time.ParseDuration(call.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("%s has invalid timeout %q", name, call.Timeout)
+			}
+		}
+	}
+	if async.Interval != "" {
+		if d, err := time.ParseDuration(async.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("has invalid interval %q", async.Interval)
+		}
+	}
+	if async.Timeout != "" {
+		if d, err := time.ParseDuration(async.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("has invalid timeout %q", async.Timeout)
+		}
+	}
+	return nil
+}
+
+// validateIntent checks the intent classifier settings
+func validateIntent(intent *IntentConfig) error {
+	if intent == nil {
+		return nil
+	}
+	switch intent.Mode {
+	case "", "regex", "llm", "hybrid":
+	default:
+		return fmt.Errorf("unsupported intent mode %q, expected regex, llm or hybrid", intent.Mode)
+	}
+	switch intent.Classifier {
+	case "", "llm":
+		if intent.Mode != "" && intent.Mode != "regex" && intent.Endpoint == "" {
+			return fmt.Errorf("intent classifier llm requires an endpoint")
+		}
+	case "similarity":
+	default:
+		return fmt.Errorf("unsupported intent classifier %q, expected llm or similarity", intent.Classifier)
+	}
+	if intent.Timeout != "" {
+		if d, err := time.ParseDuration(intent.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("intent has invalid timeout %q", intent.Timeout)
+		}
+	}
+	if intent.MinConfidence < 0 || intent.MinConfidence > 1 {
+		return fmt.Errorf("intent minConfidence must be between 0 and 1")
+	}
+	return nil
+}
+
+// validateInputRule checks that an input rule names its param and prompt
+func validateInputRule(rule InputRule) error {
+	if rule.Param == "" {
+		return fmt.Errorf("requires a param")
+	}
+	if rule.Prompt == "" {
+		return fmt.Errorf("requires a prompt")
+	}
+	switch rule.Type {
+	case "", "string", "int", "integer", "number", "float", "bool", "boolean":
+	default:
+		return fmt.Errorf("has unsupported type %q", rule.Type)
+	}
+	if rule.TTL != "" {
+		if d, err := time.ParseDuration(rule.TTL); err != nil || d <= 0 {
+			return fmt.Errorf("has invalid ttl %q", rule.TTL)
+		}
+	}
+	return nil
+}
+
+// validateFileRule checks the target and encoding of a file rule
+func validateFileRule(rule FileRule) error {
+	if rule.Target == "" {
+		return fmt.Errorf("requires a target")
+	}
+	if _, err := path.Match(rule.MimeType, ""); err != nil {
+		return fmt.Errorf("has invalid mimeType pattern %q", rule.MimeType)
+	}
+	switch rule.Encoding {
+	case "", "base64", "text":
+	default:
+		return fmt.Errorf("has unsupported encoding %q, expected base64 or text", rule.Encoding)
+	}
+	if rule.Target == "multipart" && (rule.Encoding != "" || rule.NameTarget != "" || rule.MimeTypeTarget != "") {
+		return fmt.Errorf("with a multipart target takes no encoding, nameTarget or mimeTypeTarget")
+	}
+	if rule.Target != "multipart" && rule.Field != "" {
+		return fmt.Errorf("field only applies to a multipart target")
+	}
+	return nil
+}
+
+// validateArtifact checks the type and encoding of a response artifact
+func validateArtifact(artifact ArtifactConfig) error {
+	if artifact.Source == "" {
+		return fmt.Errorf("requires a source")
+	}
+	switch artifact.Type {
+	case "", "data", "text":
+		if artifact.Encoding != "" {
+			return fmt.Errorf("encoding only applies to file artifacts")
+		}
+	case "file":
+		switch artifact.Encoding {
+		case "", "base64", "text", "uri":
+		default:
+			return fmt.Errorf("has unsupported encoding %q, expected base64, text or uri", artifact.Encoding)
+		}
+	default:
+		return fmt.Errorf("has unsupported type %q, expected data, text or file", artifact.Type)
+	}
+	return nil
+}
+
+// validateMaskRule checks that a mask rule selects values and has a supported
+// action
+func validateMaskRule(rule MaskRule) error {
+	if rule.Field == "" && rule.Pattern == "" {
+		return fmt.Errorf("requires field or pattern")
+	}
+	switch rule.Action {
+	case "", "redact", "partial", "hash":
+	case "drop":
+		if rule.Field == "" {
+			return fmt.Errorf("requires field for action drop")
+		}
+	default:
+		return fmt.Errorf("has unsupported action %q, expected redact, partial, hash or drop", rule.Action)
+	}
+	if rule.Keep < 0 {
+		return fmt.Errorf("has negative keep")
+	}
+	return nil
+}
+
+// validateSummary checks the summarizer of a response transform
+func validateSummary(summary *SummaryConfig) error {
+	if summary == nil {
+		return nil
+	}
+	switch summary.Summarizer {
+	case "", "template":
+	case "llm":
+		if summary.Endpoint == "" {
+			return fmt.Errorf("requires an endpoint for summarizer llm")
+		}
+	default:
+		return fmt.Errorf("has unsupported summarizer %q, expected template or llm", summary.Summarizer)
+	}
+	if summary.Timeout != "" {
+		if d, err := time.ParseDuration(summary.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("has invalid timeout %q", summary.Timeout)
+		}
+	}
+	if summary.MaxRows < 0 {
+		return fmt.Errorf("has negative maxRows")
+	}
+	return nil
+}
+
+// validateAnonymize checks the anonymization rules
+func validateAnonymize(anonymize *AnonymizeConfig) error {
+	if anonymize == nil {
+		return nil
+	}
+	for i, rule := range anonymize.Rules {
+		if rule.Field == "" && rule.Pattern == "" {
+			return fmt.Errorf("anonymize rule %d requires a field or pattern", i)
+		}
+		switch rule.Kind {
+		case "", "fake", "email", "name", "redact":
+		default:
+			return fmt.Errorf("anonymize rule %d has unsupported kind %q, expected fake, email, name or redact", i, rule.Kind)
+		}
+	}
+	return nil
+}
+
+// validateEgress checks that data classes have unique names, select values and
+// have a supported action
+func validateEgress(egress *EgressConfig) error {
+	if egress == nil {
+		return nil
+	}
+	names := make(map[string]bool)
+	for i, class := range egress.Classes {
+		if class.Name == "" {
+			return fmt.Errorf("egress class %d is missing name", i)
+		}
+		if names[class.Name] {
+			return fmt.Errorf("duplicate egress class %q", class.Name)
+		}
+		names[class.Name] = true
+		if len(class.Fields) == 0 && len(class.Patterns) == 0 {
+			return fmt.Errorf("egress class %s requires fields or patterns", class.Name)
+		}
+		switch class.Action {
+		case "", "strip", "block":
+		default:
+			return fmt.Errorf("egress class %s has unsupported action %q, expected strip or block", class.Name, class.Action)
+		}
+	}
+	return nil
+}
+
+// validateDictionary checks that dictionary entries name both fields once
+// and convert between compatible units
+func validateDictionary(dictionary []FieldDefinition) error {
+	legacyNames, fieldNames := make(map[string]bool), make(map[string]bool)
+	for i, def := range dictionary {
+		if def.Legacy == "" || def.Field == "" {
+			return fmt.Errorf("dictionary entry %d requires legacy and field", i)
+		}
+		if legacyNames[def.Legacy] {
+			return fmt.Errorf("duplicate dictionary legacy field %q", def.Legacy)
+		}
+		if fieldNames[def.Field] {
+			return fmt.Errorf("duplicate dictionary field %q", def.Field)
+		}
+		legacyNames[def.Legacy], fieldNames[def.Field] = true, true
+		switch def.Type {
+		case "", "string", "int", "number", "bool":
+		default:
+			return fmt.Errorf("dictionary field %s has unsupported type %q, expected string, int, number or bool", def.Field, def.Type)
+		}
+		if (def.Unit == "") != (def.LegacyUnit == "") {
+			return fmt.Errorf("dictionary field %s requires both unit and legacyUnit", def.Field)
+		}
+		if def.Unit != "" {
+			if _, err := expr.ConvertUnit(0, def.LegacyUnit, def.Unit); err != nil {
+				return fmt.Errorf("dictionary field %s: %v", def.Field, err)
+			}
+			if def.Scale != 0 {
+				return fmt.Errorf("dictionary field %s cannot combine scale with units", def.Field)
+			}
+		}
+		canonical := make(map[string]bool)
+		for _, value := range def.Values {
+			if canonical[value] {
+				return fmt.Errorf("dictionary field %s maps several codes to %q", def.Field, value)
+			}
+			canonical[value] = true
+		}
+	}
+	return nil
+}
+
+// validateDeadLetter checks the dead-letter backend and its settings
+func validateDeadLetter(deadLetter *DeadLetterConfig) error {
+	if deadLetter == nil {
+		return nil
+	}
+	switch deadLetter.Backend {
+	case "", "memory":
+	case "file":
+		if deadLetter.Directory == "" {
+			return fmt.Errorf("deadLetter backend file requires directory")
+		}
+	case "http":
+		if deadLetter.URL == "" {
+			return fmt.Errorf("deadLetter backend http requires url")
+		}
+	default:
+		return fmt.Errorf("unsupported deadLetter backend %q, expected memory, file or http", deadLetter.Backend)
+	}
+	if deadLetter.Timeout != "" {
+		if d, err := time.ParseDuration(deadLetter.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("deadLetter has invalid timeout %q", deadLetter.Timeout)
+		}
+	}
+	if deadLetter.MaxEntries < 0 {
+		return fmt.Errorf("deadLetter maxEntries must not be negative")
+	}
+	return nil
+}
+
+// validateAudit checks that the audit log has a sink and a valid syslog
+// network and facility
+func validateAudit(audit *AuditConfig) error {
+	if audit == nil {
+		return nil
+	}
+	if audit.File == "" && audit.Syslog == nil {
+		return fmt.Errorf("audit requires file or syslog")
+	}
+	if syslog := audit.Syslog; syslog != nil {
+		switch syslog.Network {
+		case "", "udp", "tcp":
+			if syslog.Address == "" {
+				return fmt.Errorf("audit syslog requires address")
+			}
+		case "unixgram":
+		default:
+			return fmt.Errorf("unsupported audit syslog network %q, expected udp, tcp or unixgram", syslog.Network)
+		}
+		if syslog.Facility != "" && !contains(SyslogFacilities, syslog.Facility) {
+			return fmt.Errorf("unsupported audit syslog facility %q", syslog.Facility)
+		}
+	}
+	return nil
+}
+
+// validateAuthorization checks that authorization has an authentication
+// method to take the caller's scopes and roles from, and that each action rule
+// names actions and what they require
+func validateAuthorization(security SecurityConfig) error {
+	authz := security.Authorization
+	if authz == nil {
+		return nil
+	}
+	if len(security.APIKeys) == 0 && len(security.HMACKeys) == 0 && security.JWT == nil {
+		return fmt.Errorf("security authorization requires apiKeys, hmacKeys or jwt")
+	}
+	for i, rule := range authz.Actions {
+		if len(rule.Actions) == 0 {
+			return fmt.Errorf("security authorization action rule %d is missing actions", i)
+		}
+		if len(rule.Scopes) == 0 && len(rule.Roles) == 0 {
+			return fmt.Errorf("security authorization action rule %d requires scopes or roles", i)
+		}
+	}
+	return nil
+}
+
+// validateSchedules checks that schedules have unique names, a task text and
+// a valid cron spec and time zone
+func validateSchedules(schedules []ScheduleConfig) error {
+	names := make(map[string]bool)
+	for i, sched := range schedules {
+		if sched.Name == "" {
+			return fmt.Errorf("schedule %d is missing name", i)
+		}
+		if names[sched.Name] {
+			return fmt.Errorf("schedule %d has duplicate name %q", i, sched.Name)
+		}
+		names[sched.Name] = true
+		if sched.Text == "" {
+			return fmt.Errorf("schedule %q is missing text", sched.Name)
+		}
+		loc, err := time.LoadLocation(sched.Timezone)
+		if err != nil {
+			return fmt.Errorf("schedule %q has invalid timezone %q", sched.Name, sched.Timezone)
+		}
+		if _, err := schedule.ParseCron(sched.Cron, loc); err != nil {
+			return fmt.Errorf("schedule %q has invalid cron: %v", sched.Name, err)
+		}
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// reservedPaths are served by the connector itself and cannot be webhook paths
+var reservedPaths = []string{"/a2a", "/events", "/.well-known", "/admin", "/health", "/healthz", "/readyz", "/metrics"}
+
+// validateWebhooks checks that webhooks have unique names, paths the
+// connector does not serve itself and a supported kind
+func validateWebhooks(webhooks []WebhookConfig) error {
+	names := make(map[string]bool)
+	paths := make(map[string]bool)
+	for i, webhook := range webhooks {
+		if webhook.Name == "" {
+			return fmt.Errorf("webhook %d is missing name", i)
+		}
+		if names[webhook.Name] {
+			return fmt.Errorf("webhook %d has duplicate name %q", i, webhook.Name)
+		}
+		names[webhook.Name] = true
+		if webhook.Salesforce != nil {
+			if webhook.Path != "" {
+				return fmt.Errorf("webhook %q receives Salesforce events and cannot have a path", webhook.Name)
+			}
+			if webhook.Salesforce.InstanceURL == "" || len(webhook.Salesforce.Channels) == 0 {
+				return fmt.Errorf("webhook %q salesforce requires instanceUrl and channels", webhook.Name)
+			}
+			if err := validateWebhookKind(webhook); err != nil {
+				return err
+			}
+			continue
+		}
+		if !strings.HasPrefix(webhook.Path, "/") || webhook.Path == "/" {
+			return fmt.Errorf("webhook %q path must start with / and name a resource", webhook.Name)
+		}
+		for _, reserved := range reservedPaths {
+			if webhook.Path == reserved || strings.HasPrefix(webhook.Path, reserved+"/") {
+				return fmt.Errorf("webhook %q path %q is reserved by the connector", webhook.Name, webhook.Path)
+			}
+		}
+		if paths[webhook.Path] {
+			return fmt.Errorf("webhook %q has duplicate path %q", webhook.Name, webhook.Path)
+		}
+		if webhook.TimestampHeader != "" && webhook.Secret == "" {
+			return fmt.Errorf("webhook %q timestampHeader requires secret", webhook.Name)
+		}
+		paths[webhook.Path] = true
+		if err := validateWebhookKind(webhook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateWebhookKind(webhook WebhookConfig) error {
+	switch webhook.Kind {
+	case "", "task", "message":
+		return nil
+	}
+	return fmt.Errorf("webhook %q has unsupported kind %q, expected task or message", webhook.Name, webhook.Kind)
+}
+
+// validateSSO checks the single sign-on settings of a listener; only the
+// admin plane supports them
+func validateSSO(name string, listener ListenerConfig) error {
+	sso := listener.SSO
+	if sso == nil {
+		return nil
+	}
+	if planes := listener.ServedPlanes(name); len(planes) != 1 || planes[0] != "admin" {
+		return fmt.Errorf("server %s does not support sso; only the admin plane does", name)
+	}
+	if listener.Auth.Type != "" && listener.Auth.Type != "none" {
+		return fmt.Errorf("server %s sso replaces auth; remove auth type %q", name, listener.Auth.Type)
+	}
+	if saml := sso.SAML; saml != nil {
+		if sso.Issuer != "" || sso.ClientID != "" || sso.RedirectURL != "" {
+			return fmt.Errorf("server %s sso uses either OpenID Connect or saml; remove issuer, clientId and redirectUrl", name)
+		}
+		if saml.IDPMetadataURL == "" || saml.ACSURL == "" {
+			return fmt.Errorf("server %s sso saml requires idpMetadataUrl and acsUrl", name)
+		}
+		if u, err := url.Parse(saml.ACSURL); err != nil || !u.IsAbs() || !strings.HasSuffix(u.Path, "/admin/saml/acs") {
+			return fmt.Errorf("server %s sso saml acsUrl must be the absolute URL of /admin/saml/acs", name)
+		}
+		if (saml.Certificate == "") != (saml.PrivateKey == "") {
+			return fmt.Errorf("server %s sso saml requires both certificate and privateKey, or neither", name)
+		}
+	} else if sso.Issuer == "" || sso.ClientID == "" || sso.RedirectURL == "" {
+		return fmt.Errorf("server %s sso requires issuer, clientId and redirectUrl", name)
+	}
+	if len(sso.GroupRoles) == 0 {
+		return fmt.Errorf("server %s sso requires groupRoles", name)
+	}
+	for group, role := range sso.GroupRoles {
+		if role != "admin" && role != "viewer" {
+			return fmt.Errorf("server %s sso maps group %q to unsupported role %q, expected admin or viewer", name, group, role)
+		}
+	}
+	if sso.SessionTTL != "" {
+		if _, err := time.ParseDuration(sso.SessionTTL); err != nil {
+			return fmt.Errorf("server %s sso has invalid sessionTtl %q: %v", name, sso.SessionTTL, err)
+		}
+	}
+	return nil
+}
+
+// validateTimeout checks that the timeout durations parse
+func validateTimeout(timeout TimeoutConfig) error {
+	for _, d := range []string{timeout.Connect, timeout.Read, timeout.Total} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("invalid duration %q: %v", d, err)
+		}
+	}
+	return nil
+}
+
+// validateSocket checks the socket settings of a tcp adapter
+func validateSocket(adapter AdapterConfig) error {
+	socket := adapter.Socket
+	if socket == nil || socket.Address == "" {
+		return fmt.Errorf("address is required")
+	}
+	if socket.ContentType != "" {
+		found := false
+		for _, codec := range adapter.Codecs {
+			found = found || codec.ContentType == socket.ContentType
+		}
+		if !found {
+			return fmt.Errorf("contentType %s has no configured codec", socket.ContentType)
+		}
+	}
+	switch socket.Framing {
+	case "length":
+		switch socket.LengthBytes {
+		case 0, 1, 2, 4:
+		default:
+			return fmt.Errorf("lengthBytes must be 1, 2 or 4")
+		}
+	case "delimiter":
+	case "fixed":
+		if socket.RecordSize < 0 || socket.RecordSize == 0 && socket.ContentType == "" {
+			return fmt.Errorf("fixed framing requires a recordSize or a fixed-width contentType")
+		}
+	default:
+		return fmt.Errorf("unsupported framing %q", socket.Framing)
+	}
+	return nil
+}
+
+// validateEcho checks the injected delay and errors of an echo adapter
+func validateEcho(echo *EchoConfig) error {
+	if echo == nil {
+		return nil
+	}
+	if echo.Delay != "" {
+		if d, err := time.ParseDuration(echo.Delay); err != nil || d < 0 {
+			return fmt.Errorf("invalid delay %q", echo.Delay)
+		}
+	}
+	if echo.ErrorRate < 0 || echo.ErrorRate > 1 {
+		return fmt.Errorf("errorRate must be between 0 and 1")
+	}
+	if echo.ErrorStatus != 0 && (echo.ErrorStatus < 400 || echo.ErrorStatus > 599) {
+		return fmt.Errorf("errorStatus must be an HTTP error status")
+	}
+	return nil
+}
+
+// validateTelnet checks the session settings of a telnet adapter
+func validateTelnet(telnet *TelnetConfig) error {
+	if telnet == nil || telnet.Address == "" {
+		return fmt.Errorf("address is required")
+	}
+	if telnet.Prompt == "" {
+		return fmt.Errorf("prompt is required")
+	}
+	patterns := []string{telnet.Prompt}
+	for _, step := range telnet.Login {
+		if step.Expect != "" {
+			patterns = append(patterns, step.Expect)
+		}
+	}
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// typesSource is the source of the config types; their doc comments describe
+// the generated schema and example
+//
+//go:embed types.go
+var typesSource []byte
+
+// jsonSchemaDialect is the JSON Schema version of the generated schema
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// enumValues are the supported values of enumerated fields, by type and key
+var enumValues = map[string][]string{
+	"AdapterConfig.type":           AdapterTypes,
+	"SQLPolicyConfig.verbs":        SQLVerbs,
+	"BatchConfig.format":           batchFormats,
+	"IntentConfig.mode":            intentModes,
+	"IntentConfig.classifier":      intentClassifiers,
+	"MappingConfig.requestFormat":  bodyFormats,
+	"MappingConfig.responseFormat": bodyFormats,
+	"FanOutConfig.policy":          fanOutPolicies,
+	"FileRule.encoding":            fileEncodings,
+	"ArtifactConfig.type":          artifactTypes,
+	"ArtifactConfig.encoding":      artifactEncodings,
+	"DeadLetterConfig.backend":     deadLetterBackends,
+	"WebhookConfig.kind":           webhookKinds,
+	"SalesforceStream.replay":      salesforceReplays,
+	"AnonymizeRule.kind":           anonymizeKinds,
+	"MaskRule.action":              maskActions,
+	"FieldDefinition.type":         fieldTypes,
+	"SummaryConfig.summarizer":     summarizers,
+	"DataClassConfig.action":       egressActions,
+	"ListenerConfig.planes":        Planes,
+	"SyslogConfig.network":         syslogNetworks,
+	"SyslogConfig.facility":        SyslogFacilities,
+}
+
+// configField is a field of a config type as it appears in config files
+type configField struct {
+	key  string
+	t    reflect.Type
+	enum []string
+}
+
+// configFields returns the fields of a config struct that config files set
+func configFields(t reflect.Type) []configField {
+	var fields []configField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if field.PkgPath != "" || key == "-" || key == "" {
+			continue
+		}
+		fields = append(fields, configField{key: key, t: field.Type, enum: enumValues[t.Name()+"."+key]})
+	}
+	return fields
+}
+
+// typeDocs returns the doc comment of each config type, on one line
+func typeDocs() map[string]string {
+	docs := make(map[string]string)
+	file, err := parser.ParseFile(token.NewFileSet(), "types.go", typesSource, parser.ParseComments)
+	if err != nil {
+		return docs
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.TypeSpec)
+			doc := spec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if doc != nil {
+				docs[spec.Name.Name] = strings.Join(strings.Fields(doc.Text()), " ")
+			}
+		}
+	}
+	return docs
+}
+
+// JSONSchema returns the JSON Schema of config files, generated from the
+// config types: a definition per type, described by its doc comment, with the
+// supported values of enumerated fields. Keys starting with x- and merge keys
+// are allowed everywhere for YAML anchors.
+func JSONSchema() map[string]interface{} {
+	docs := typeDocs()
+	defs := make(map[string]interface{})
+	root := typeSchema(reflect.TypeOf(ConnectorConfig{}), defs, docs)
+	schema := map[string]interface{}{
+		"$schema": jsonSchemaDialect,
+		"title":   "A2A connector config",
+		"$defs":   defs,
+	}
+	for key, value := range root {
+		schema[key] = value
+	}
+	return schema
+}
+
+// typeSchema returns the schema of a Go type; structs are added to defs and
+// referenced
+func typeSchema(t reflect.Type, defs map[string]interface{}, docs map[string]string) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+		if _, ok := defs[t.Name()]; ok {
+			return ref
+		}
+		// Recursive types refer to the definition being built
+		defs[t.Name()] = nil
+		properties := make(map[string]interface{})
+		for _, field := range configFields(t) {
+			property := typeSchema(field.t, defs, docs)
+			if field.enum != nil {
+				if items, ok := property["items"].(map[string]interface{}); ok {
+					items["enum"] = field.enum
+				} else {
+					property["enum"] = field.enum
+				}
+			}
+			properties[field.key] = property
+		}
+		def := map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"patternProperties":    map[string]interface{}{"^x-": map[string]interface{}{}, "^<<$": map[string]interface{}{}},
+			"additionalProperties": false,
+		}
+		if doc := docs[t.Name()]; doc != "" {
+			def["description"] = doc
+		}
+		defs[t.Name()] = def
+		return ref
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), defs, docs)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs, docs)}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// interface{} holds any value, e.g. schedule metadata
+		return map[string]interface{}{}
+	}
+}
+
+// ExampleYAML returns a config file with every field of the config types and
+// an empty value, each section commented with the doc comment of its type and
+// enumerated fields with their supported values
+func ExampleYAML() string {
+	docs := typeDocs()
+	var buf bytes.Buffer
+	t := reflect.TypeOf(ConnectorConfig{})
+	writeComment(&buf, "", docs[t.Name()])
+	writeExample(&buf, t, "", docs, map[string]bool{t.Name(): true})
+	return buf.String()
+}
+
+// writeExample writes the fields of struct t at indent. Types already being
+// written further up, i.e. recursive ones, are left empty.
+func writeExample(buf *bytes.Buffer, t reflect.Type, indent string, docs map[string]string, open map[string]bool) {
+	for _, field := range configFields(t) {
+		ft := field.t
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		elem := ft
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map {
+			elem = ft.Elem()
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+		}
+		if elem.Kind() != reflect.Struct {
+			fmt.Fprintf(buf, "%s%s: %s", indent, field.key, exampleValue(ft))
+			if field.enum != nil {
+				fmt.Fprintf(buf, "  # %s", strings.Join(field.enum, ", "))
+			}
+			buf.WriteString("\n")
+			continue
+		}
+
+		if indent == "" {
+			buf.WriteString("\n")
+		}
+		writeComment(buf, indent, docs[elem.Name()])
+		if open[elem.Name()] {
+			empty := "{}"
+			if ft.Kind() == reflect.Slice {
+				empty = "[]"
+			}
+			fmt.Fprintf(buf, "%s%s: %s  # %s, see above\n", indent, field.key, empty, elem.Name())
+			continue
+		}
+		open[elem.Name()] = true
+		fmt.Fprintf(buf, "%s%s:\n", indent, field.key)
+		switch ft.Kind() {
+		case reflect.Slice:
+			// The first field of the item goes on the line of its dash
+			var item bytes.Buffer
+			writeExample(&item, elem, indent+"    ", docs, open)
+			buf.WriteString(indent + "  - " + strings.TrimPrefix(item.String(), indent+"    "))
+		case reflect.Map:
+			fmt.Fprintf(buf, "%s  name:\n", indent)
+			writeExample(buf, elem, indent+"    ", docs, open)
+		default:
+			writeExample(buf, elem, indent+"  ", docs, open)
+		}
+		delete(open, elem.Name())
+	}
+}
+
+// exampleValue is the empty value of a field of a non-struct type
+func exampleValue(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "[]"
+	case reflect.Map, reflect.Interface:
+		return "{}"
+	case reflect.Bool:
+		return "false"
+	case reflect.String:
+		return `""`
+	default:
+		return "0"
+	}
+}
+
+// writeComment writes doc as YAML comment lines of at most 80 columns
+func writeComment(buf *bytes.Buffer, indent, doc string) {
+	line := indent + "#"
+	for _, word := range strings.Fields(doc) {
+		if len(line)+1+len(word) > 80 && line != indent+"#" {
+			buf.WriteString(line + "\n")
+			line = indent + "#"
+		}
+		line += " " + word
+	}
+	if line != indent+"#" {
+		buf.WriteString(line + "\n")
+	}
+}
@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func TestExampleYAMLDecodes(t *testing.T) {
+	example := config.ExampleYAML()
+	decoder := yaml.NewDecoder(bytes.NewBufferString(example))
+	decoder.KnownFields(true)
+	var cfg config.ConnectorConfig
+	if err := decoder.Decode(&cfg); err != nil {
+		t.Fatalf("example does not decode: %v\n%s", err, example)
+	}
+	if cfg.Adapter.Type != "" || len(cfg.Mappings) != 1 {
+		t.Errorf("unexpected example: %+v", cfg)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := json.Marshal(config.JSONSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Ref  string `json:"$ref"`
+		Defs map[string]struct {
+			Description string `json:"description"`
+			Properties  map[string]struct {
+				Type  string   `json:"type"`
+				Ref   string   `json:"$ref"`
+				Enum  []string `json:"enum"`
+				Items struct {
+					Ref  string   `json:"$ref"`
+					Enum []string `json:"enum"`
+				} `json:"items"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Ref != "#/$defs/ConnectorConfig" {
+		t.Errorf("root refers to %q", schema.Ref)
+	}
+	adapter := schema.Defs["AdapterConfig"]
+	if adapter.Description == "" {
+		t.Error("AdapterConfig has no description")
+	}
+	if got := adapter.Properties["type"].Enum; len(got) != len(config.AdapterTypes) {
+		t.Errorf("adapter.type enum = %v", got)
+	}
+	if got := schema.Defs["ConnectorConfig"].Properties["mappings"].Items.Ref; got != "#/$defs/MappingConfig" {
+		t.Errorf("mappings items refer to %q", got)
+	}
+	if got := schema.Defs["ListenerConfig"].Properties["planes"].Items.Enum; len(got) != len(config.Planes) {
+		t.Errorf("listener planes enum = %v", got)
+	}
+	for name, def := range schema.Defs {
+		for key, property := range def.Properties {
+			if property.Ref != "" {
+				if _, ok := schema.Defs[property.Ref[len("#/$defs/"):]]; !ok {
+					t.Errorf("%s.%s refers to missing %s", name, key, property.Ref)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,889 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+	"github.com/A2AGateway/a2a-connector/internal/jsonpath"
+	"github.com/A2AGateway/a2a-connector/internal/schedule"
+	"github.com/A2AGateway/a2a-connector/internal/tmplfunc"
+	"gopkg.in/yaml.v3"
+)
+
+// AdapterTypes are the supported values of adapter.type
+var AdapterTypes = []string{"rest", "soap", "db", "file", "tcp", "telnet", "echo", "plugin"}
+
+// SQLVerbs are the supported values of adapter.sqlPolicy.verbs
+var SQLVerbs = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME", "COMMENT", "GRANT", "REVOKE"}
+
+// batchFormats are the supported values of batch.format
+var batchFormats = []string{"json", "csv", "yaml", "lines"}
+
+// intentModes and intentClassifiers are the supported intent settings
+var (
+	intentModes       = []string{"regex", "llm", "hybrid"}
+	intentClassifiers = []string{"llm", "similarity"}
+)
+
+// bodyFormats are the supported values of mappings[].requestFormat and
+// responseFormat
+var bodyFormats = []string{"json", "xml", "form", "text"}
+
+// fanOutPolicies are the supported values of mappings[].fanOut.policy
+var fanOutPolicies = []string{"partial", "all"}
+
+// fileEncodings are the supported values of mappings[].files[].encoding
+var fileEncodings = []string{"base64", "text"}
+
+// artifactTypes and artifactEncodings are the supported values of
+// mappings[].responseTransform.artifacts[].type and encoding
+var (
+	artifactTypes     = []string{"data", "text", "file"}
+	artifactEncodings = []string{"base64", "text", "uri"}
+)
+
+// deadLetterBackends are the supported values of deadLetter.backend
+var deadLetterBackends = []string{"memory", "file", "http"}
+
+// syslogNetworks are the supported values of audit.syslog.network
+var syslogNetworks = []string{"udp", "tcp", "unixgram"}
+
+// SyslogFacilities are the supported values of audit.syslog.facility, in the
+// order of their RFC 5424 codes
+var SyslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "audit", "alert", "clock",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// webhookKinds are the supported values of webhooks[].kind
+var webhookKinds = []string{"task", "message"}
+
+// salesforceReplays are the supported values of webhooks[].salesforce.replay
+var salesforceReplays = []string{"new", "all"}
+
+// salesforceChannels are the prefixes of the Streaming API channels
+var salesforceChannels = []string{"/data/", "/event/", "/topic/"}
+
+// anonymizeKinds are the supported values of anonymize.rules[].kind
+var anonymizeKinds = []string{"fake", "email", "name", "redact"}
+
+// egressActions are the supported values of egress.classes[].action
+var egressActions = []string{"strip", "block"}
+
+// maskActions are the supported values of mappings[].responseTransform.mask[].action
+var maskActions = []string{"redact", "partial", "hash", "drop"}
+
+// fieldTypes are the supported values of dictionary[].type
+var fieldTypes = []string{"string", "int", "number", "bool"}
+
+// summarizers are the supported values of mappings[].responseTransform.summary.summarizer
+var summarizers = []string{"template", "llm"}
+
+// authFields lists the credential fields each auth type uses; setting any
+// other one is a mistake, e.g. a token on basic auth
+var authFields = map[string][]string{
+	"basic":  {"username", "password"},
+	"bearer": {"token"},
+	"apikey": {"token", "keyName"},
+	"oauth2": {"oauth2"},
+}
+
+// FieldError is a problem with the config value at a YAML path such as
+// mappings[2].intentPattern. Line is 0 when the value is not in the file.
+// File is set for keys of included files, whose line it is.
+type FieldError struct {
+	Path    string
+	File    string
+	Line    int
+	Message string
+}
+
+// Error implements the error interface
+func (e FieldError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s (%s line %d): %s", e.Path, e.File, e.Line, e.Message)
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("%s (line %d): %s", e.Path, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// SchemaError lists every problem found in a config, so all of them can be
+// fixed at once
+type SchemaError struct {
+	Problems []FieldError
+}
+
+// Error implements the error interface
+func (e *SchemaError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		lines[i] = "  " + problem.Error()
+	}
+	return fmt.Sprintf("config has %d problem(s):\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+// schemaChecker collects problems and knows the line of each path in the file
+type schemaChecker struct {
+	lines    map[string]int
+	problems []FieldError
+}
+
+func (s *schemaChecker) add(path, format string, args ...interface{}) {
+	// Missing values are reported at the closest parent in the file
+	line := 0
+	for p := path; p != ""; p = parentPath(p) {
+		if l, ok := s.lines[p]; ok {
+			line = l
+			break
+		}
+	}
+	s.problems = append(s.problems, FieldError{Path: path, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (s *schemaChecker) err() error {
+	if len(s.problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(s.problems, func(i, j int) bool {
+		if s.problems[i].Line == 0 || s.problems[j].Line == 0 {
+			return s.problems[j].Line == 0 && s.problems[i].Line != 0
+		}
+		return s.problems[i].Line < s.problems[j].Line
+	})
+	return &SchemaError{Problems: s.problems}
+}
+
+func parentPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i > 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// checkFields walks a parsed config file along the config types, records the
+// line of every path and reports keys that match no field
+func (s *schemaChecker) checkFields(node *yaml.Node, t reflect.Type, path string) {
+	for node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if field.PkgPath != "" || name == "-" || name == "" {
+				continue
+			}
+			fields[name] = field.Type
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				// Merge keys pull in the fields of anchored mappings
+				merged := []*yaml.Node{value}
+				if value.Kind == yaml.SequenceNode {
+					merged = value.Content
+				}
+				for _, m := range merged {
+					s.checkFields(m, t, path)
+				}
+				continue
+			}
+			child := joinPath(path, key.Value)
+			fieldType, ok := fields[key.Value]
+			if !ok && strings.HasPrefix(key.Value, "x-") {
+				// Extension keys hold YAML anchors for reuse elsewhere
+				continue
+			}
+			if !ok {
+				s.lines[child] = key.Line
+				if suggestion := closestName(key.Value, fields); suggestion != "" {
+					s.add(child, "unknown field %q, did you mean %q?", key.Value, suggestion)
+				} else {
+					s.add(child, "unknown field %q", key.Value)
+				}
+				continue
+			}
+			s.lines[child] = key.Line
+			s.checkFields(value, fieldType, child)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			child := joinPath(path, node.Content[i].Value)
+			s.lines[child] = node.Content[i].Line
+			s.checkFields(node.Content[i+1], t.Elem(), child)
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			s.lines[child] = item.Line
+			s.checkFields(item, t.Elem(), child)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// closestName suggests the field a misspelled key was probably meant to be
+func closestName(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+		if d := editDistance(strings.ToLower(name), strings.ToLower(key)); d < bestDistance || d == bestDistance && name < best {
+			best, bestDistance = name, d
+		}
+	}
+	if bestDistance > 2 {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// checkValues reports invalid values: unsupported enums, patterns that do not
+// compile, response templates that do not parse and conflicting auth fields
+func (s *schemaChecker) checkValues(c *ConnectorConfig) {
+	switch {
+	case c.Adapter.Type == "":
+		s.add("adapter.type", "is required, one of %s", strings.Join(AdapterTypes, ", "))
+	case !contains(AdapterTypes, c.Adapter.Type):
+		s.add("adapter.type", "unsupported value %q, expected one of %s", c.Adapter.Type, strings.Join(AdapterTypes, ", "))
+	}
+	s.checkAuth("adapter.auth", c.Adapter.Auth, []string{"basic", "bearer", "apikey", "oauth2"})
+	names, listeners := c.Server.AllListeners()
+	for i, name := range names {
+		if i >= len(Planes) {
+			if len(listeners[i].Planes) == 0 {
+				s.add("server."+name+".planes", "is required, one or more of %s", strings.Join(Planes, ", "))
+			}
+			for j, plane := range listeners[i].Planes {
+				if !contains(Planes, plane) {
+					s.add(fmt.Sprintf("server.%s.planes[%d]", name, j), "unsupported value %q, expected one of %s", plane, strings.Join(Planes, ", "))
+				}
+			}
+			if listeners[i].Address == "" {
+				s.add("server."+name+".address", "is required")
+			}
+		} else if len(listeners[i].Planes) > 0 {
+			s.add("server."+name+".planes", "is only used by server.listeners")
+		}
+		s.checkAuth("server."+name+".auth", listeners[i].Auth, []string{"basic", "bearer"})
+		if sso := listeners[i].SSO; sso != nil {
+			if planes := listeners[i].ServedPlanes(name); len(planes) != 1 || planes[0] != "admin" {
+				s.add("server."+name+".sso", "is only supported on the admin plane")
+			}
+			for group, role := range sso.GroupRoles {
+				if role != "admin" && role != "viewer" {
+					s.add("server."+name+".sso.groupRoles."+group, "unsupported role %q, expected admin or viewer", role)
+				}
+			}
+		}
+	}
+	if telnet := c.Adapter.Telnet; telnet != nil {
+		s.checkPattern("adapter.telnet.prompt", telnet.Prompt)
+		for i, step := range telnet.Login {
+			s.checkPattern(fmt.Sprintf("adapter.telnet.login[%d].expect", i), step.Expect)
+		}
+	}
+	if c.Adapter.Plugin != "" && c.Adapter.Type != "plugin" {
+		s.add("adapter.plugin", "is only used by plugin adapters")
+	}
+	pluginNames := make(map[string]bool)
+	for i, plugin := range c.Plugins {
+		path := fmt.Sprintf("plugins[%d]", i)
+		if plugin.Name == "" {
+			s.add(path+".name", "is required")
+		} else if pluginNames[plugin.Name] {
+			s.add(path+".name", "duplicate plugin %q", plugin.Name)
+		}
+		pluginNames[plugin.Name] = true
+		if plugin.Command == "" {
+			s.add(path+".command", "is required")
+		}
+		if plugin.StartTimeout != "" {
+			if d, err := time.ParseDuration(plugin.StartTimeout); err != nil || d <= 0 {
+				s.add(path+".startTimeout", "invalid duration %q", plugin.StartTimeout)
+			}
+		}
+	}
+	if c.Adapter.QueryLimits != nil && c.Adapter.Type != "db" {
+		s.add("adapter.queryLimits", "is only used by db adapters")
+	}
+	if policy := c.Adapter.SQLPolicy; policy != nil {
+		if c.Adapter.Type != "db" {
+			s.add("adapter.sqlPolicy", "is only used by db adapters")
+		}
+		for i, verb := range policy.Verbs {
+			if !contains(SQLVerbs, strings.ToUpper(verb)) {
+				s.add(fmt.Sprintf("adapter.sqlPolicy.verbs[%d]", i), "unsupported value %q, expected one of %s", verb, strings.Join(SQLVerbs, ", "))
+			}
+		}
+	}
+
+	if batch := c.Batch; batch != nil {
+		if batch.Format != "" && !contains(batchFormats, batch.Format) {
+			s.add("batch.format", "unsupported value %q, expected one of %s", batch.Format, strings.Join(batchFormats, ", "))
+		}
+		if batch.ChunkSize < 0 {
+			s.add("batch.chunkSize", "must not be negative")
+		}
+		if batch.MaxRecords < 0 {
+			s.add("batch.maxRecords", "must not be negative")
+		}
+	}
+
+	if intent := c.Intent; intent != nil {
+		if intent.Mode != "" && !contains(intentModes, intent.Mode) {
+			s.add("intent.mode", "unsupported value %q, expected one of %s", intent.Mode, strings.Join(intentModes, ", "))
+		}
+		if intent.Classifier != "" && !contains(intentClassifiers, intent.Classifier) {
+			s.add("intent.classifier", "unsupported value %q, expected one of %s", intent.Classifier, strings.Join(intentClassifiers, ", "))
+		}
+	}
+
+	if workers := c.Workers; workers != nil {
+		if workers.Size <= 0 {
+			s.add("workers.size", "must be positive")
+		}
+		if workers.QueueSize < 0 {
+			s.add("workers.queueSize", "must not be negative")
+		}
+	}
+
+	if deadLetter := c.DeadLetter; deadLetter != nil {
+		if deadLetter.Backend != "" && !contains(deadLetterBackends, deadLetter.Backend) {
+			s.add("deadLetter.backend", "unsupported value %q, expected one of %s", deadLetter.Backend, strings.Join(deadLetterBackends, ", "))
+		}
+		if deadLetter.Backend == "file" && deadLetter.Directory == "" {
+			s.add("deadLetter.directory", "is required for backend file")
+		}
+		if deadLetter.Backend == "http" && deadLetter.URL == "" {
+			s.add("deadLetter.url", "is required for backend http")
+		}
+		if deadLetter.MaxEntries < 0 {
+			s.add("deadLetter.maxEntries", "must not be negative")
+		}
+	}
+
+	if authz := c.Security.Authorization; authz != nil {
+		if len(c.Security.APIKeys) == 0 && len(c.Security.HMACKeys) == 0 && c.Security.JWT == nil {
+			s.add("security.authorization", "requires apiKeys, hmacKeys or jwt")
+		}
+		for i, rule := range authz.Actions {
+			path := fmt.Sprintf("security.authorization.actions[%d]", i)
+			if len(rule.Actions) == 0 {
+				s.add(path+".actions", "is required")
+			}
+			if len(rule.Scopes) == 0 && len(rule.Roles) == 0 {
+				s.add(path, "requires scopes or roles")
+			}
+		}
+	}
+
+	if audit := c.Audit; audit != nil {
+		if audit.File == "" && audit.Syslog == nil {
+			s.add("audit", "requires file or syslog")
+		}
+		if syslog := audit.Syslog; syslog != nil {
+			if syslog.Network != "" && !contains(syslogNetworks, syslog.Network) {
+				s.add("audit.syslog.network", "unsupported value %q, expected one of %s", syslog.Network, strings.Join(syslogNetworks, ", "))
+			}
+			if syslog.Network != "unixgram" && syslog.Address == "" {
+				s.add("audit.syslog.address", "is required unless network is unixgram")
+			}
+			if syslog.Facility != "" && !contains(SyslogFacilities, syslog.Facility) {
+				s.add("audit.syslog.facility", "unsupported value %q, expected one of %s", syslog.Facility, strings.Join(SyslogFacilities, ", "))
+			}
+		}
+	}
+
+	for i, sched := range c.Schedules {
+		path := fmt.Sprintf("schedules[%d]", i)
+		if sched.Name == "" {
+			s.add(path+".name", "is required")
+		}
+		if sched.Text == "" {
+			s.add(path+".text", "is required")
+		}
+		loc, err := time.LoadLocation(sched.Timezone)
+		if err != nil {
+			s.add(path+".timezone", "unknown time zone %q", sched.Timezone)
+		}
+		if _, err := schedule.ParseCron(sched.Cron, loc); err != nil {
+			s.add(path+".cron", "invalid cron: %v", err)
+		}
+	}
+
+	for i, webhook := range c.Webhooks {
+		path := fmt.Sprintf("webhooks[%d]", i)
+		if webhook.Name == "" {
+			s.add(path+".name", "is required")
+		}
+		if stream := webhook.Salesforce; stream != nil {
+			if webhook.Path != "" {
+				s.add(path+".path", "cannot be combined with salesforce, whose events are not posted")
+			}
+			if u, err := url.Parse(stream.InstanceURL); err != nil || u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+				s.add(path+".salesforce.instanceUrl", "is required and must be an http(s) URL")
+			}
+			s.checkAuth(path+".salesforce.auth", stream.Auth, []string{"bearer", "oauth2"})
+			if len(stream.Channels) == 0 {
+				s.add(path+".salesforce.channels", "is required")
+			}
+			for j, channel := range stream.Channels {
+				if !hasAnyPrefix(channel, salesforceChannels) || strings.TrimRight(channel, "/") != channel {
+					s.add(fmt.Sprintf("%s.salesforce.channels[%d]", path, j), "must name a channel under %s", strings.Join(salesforceChannels, ", "))
+				}
+			}
+			if stream.Replay != "" && !contains(salesforceReplays, stream.Replay) {
+				s.add(path+".salesforce.replay", "unsupported value %q, expected one of %s", stream.Replay, strings.Join(salesforceReplays, ", "))
+			}
+		} else if !strings.HasPrefix(webhook.Path, "/") {
+			s.add(path+".path", "is required and must start with /")
+		}
+		if webhook.TimestampHeader != "" && webhook.Secret == "" {
+			s.add(path+".timestampHeader", "requires secret, as only signed timestamps are checked")
+		}
+		if webhook.Kind != "" && !contains(webhookKinds, webhook.Kind) {
+			s.add(path+".kind", "unsupported value %q, expected one of %s", webhook.Kind, strings.Join(webhookKinds, ", "))
+		}
+		if webhook.Text != "" {
+			if _, err := template.New("webhook").Funcs(tmplfunc.FuncMap()).Parse(webhook.Text); err != nil {
+				s.add(path+".text", "invalid template: %v", err)
+			}
+		}
+		for j, rule := range webhook.LegacyToA2A {
+			s.checkRule(fmt.Sprintf("%s.legacyToA2a[%d]", path, j), rule)
+		}
+	}
+
+	if anonymize := c.Anonymize; anonymize != nil {
+		for i, rule := range anonymize.Rules {
+			path := fmt.Sprintf("anonymize.rules[%d]", i)
+			if rule.Kind != "" && !contains(anonymizeKinds, rule.Kind) {
+				s.add(path+".kind", "unsupported value %q, expected one of %s", rule.Kind, strings.Join(anonymizeKinds, ", "))
+			}
+			s.checkPattern(path+".pattern", rule.Pattern)
+		}
+	}
+
+	legacyNames, fieldNames := make(map[string]bool), make(map[string]bool)
+	for i, def := range c.Dictionary {
+		path := fmt.Sprintf("dictionary[%d]", i)
+		if def.Legacy == "" {
+			s.add(path+".legacy", "is required")
+		} else if legacyNames[def.Legacy] {
+			s.add(path+".legacy", "duplicate legacy field %q", def.Legacy)
+		}
+		legacyNames[def.Legacy] = true
+		if def.Field == "" {
+			s.add(path+".field", "is required")
+		} else if fieldNames[def.Field] {
+			s.add(path+".field", "duplicate field %q", def.Field)
+		}
+		fieldNames[def.Field] = true
+		if def.Type != "" && !contains(fieldTypes, def.Type) {
+			s.add(path+".type", "unsupported value %q, expected one of %s", def.Type, strings.Join(fieldTypes, ", "))
+		}
+		if (def.Unit == "") != (def.LegacyUnit == "") {
+			s.add(path, "requires both unit and legacyUnit")
+		} else if def.Unit != "" {
+			if _, err := expr.ConvertUnit(0, def.LegacyUnit, def.Unit); err != nil {
+				s.add(path+".unit", "%v", err)
+			}
+			if def.Scale != 0 {
+				s.add(path+".scale", "cannot be combined with units")
+			}
+		}
+		canonical := make(map[string]bool)
+		for _, value := range def.Values {
+			if canonical[value] {
+				s.add(path+".values", "maps several codes to %q", value)
+			}
+			canonical[value] = true
+		}
+	}
+
+	if egress := c.Egress; egress != nil {
+		names := make(map[string]bool)
+		for i, class := range egress.Classes {
+			path := fmt.Sprintf("egress.classes[%d]", i)
+			if class.Name == "" {
+				s.add(path+".name", "is required")
+			} else if names[class.Name] {
+				s.add(path+".name", "duplicate class %q", class.Name)
+			}
+			names[class.Name] = true
+			if len(class.Fields) == 0 && len(class.Patterns) == 0 {
+				s.add(path, "requires fields or patterns")
+			}
+			if class.Action != "" && !contains(egressActions, class.Action) {
+				s.add(path+".action", "unsupported value %q, expected one of %s", class.Action, strings.Join(egressActions, ", "))
+			}
+			for j, pattern := range class.Patterns {
+				s.checkPattern(fmt.Sprintf("%s.patterns[%d]", path, j), pattern)
+			}
+		}
+	}
+
+	for i, mapping := range c.Mappings {
+		path := fmt.Sprintf("mappings[%d]", i)
+		if mapping.IntentPattern == "" {
+			s.add(path+".intentPattern", "is required")
+		}
+		s.checkPattern(path+".intentPattern", strings.ToLower(mapping.IntentPattern))
+		if mapping.When != "" {
+			if _, err := expr.Parse(mapping.When); err != nil {
+				s.add(path+".when", "invalid expression: %v", err)
+			}
+		}
+		for j, param := range mapping.ParameterMappings {
+			paramPath := fmt.Sprintf("%s.parameterMappings[%d]", path, j)
+			s.checkPattern(paramPath+".pattern", param.Pattern)
+			if param.Source != "text" {
+				s.checkPath(paramPath+".source", param.Source)
+			}
+			s.checkPath(paramPath+".target", param.Target)
+		}
+		for target, source := range mapping.ResponseTransform.Mappings {
+			s.checkPath(path+".responseTransform.mappings."+target, target)
+			s.checkPath(path+".responseTransform.mappings."+target, source)
+		}
+		for name, source := range mapping.Session {
+			s.checkPath(path+".session."+name, source)
+		}
+		for j, rule := range mapping.Input {
+			inputPath := fmt.Sprintf("%s.input[%d]", path, j)
+			if rule.Param == "" {
+				s.add(inputPath+".param", "is required")
+			}
+			s.checkPath(inputPath+".param", rule.Param)
+			if rule.Prompt == "" {
+				s.add(inputPath+".prompt", "is required")
+			} else if _, err := template.New("prompt").Funcs(tmplfunc.FuncMap()).Parse(rule.Prompt); err != nil {
+				s.add(inputPath+".prompt", "invalid template: %v", err)
+			}
+			if rule.When != "" {
+				if _, err := expr.Parse(rule.When); err != nil {
+					s.add(inputPath+".when", "invalid expression: %v", err)
+				}
+			}
+			s.checkPattern(inputPath+".pattern", rule.Pattern)
+		}
+		for j, rule := range mapping.Files {
+			rulePath := fmt.Sprintf("%s.files[%d]", path, j)
+			if rule.Target == "" {
+				s.add(rulePath+".target", "is required, multipart or a param path")
+			} else if rule.Target != "multipart" {
+				s.checkPath(rulePath+".target", rule.Target)
+			}
+			s.checkPath(rulePath+".nameTarget", rule.NameTarget)
+			s.checkPath(rulePath+".mimeTypeTarget", rule.MimeTypeTarget)
+			if rule.Encoding != "" && !contains(fileEncodings, rule.Encoding) {
+				s.add(rulePath+".encoding", "unsupported value %q, expected one of %s", rule.Encoding, strings.Join(fileEncodings, ", "))
+			}
+		}
+		for j, artifact := range mapping.ResponseTransform.Artifacts {
+			artifactPath := fmt.Sprintf("%s.responseTransform.artifacts[%d]", path, j)
+			if artifact.Source == "" {
+				s.add(artifactPath+".source", "is required")
+			}
+			s.checkPath(artifactPath+".source", artifact.Source)
+			if artifact.Type != "" && !contains(artifactTypes, artifact.Type) {
+				s.add(artifactPath+".type", "unsupported value %q, expected one of %s", artifact.Type, strings.Join(artifactTypes, ", "))
+			}
+			if artifact.Encoding != "" && !contains(artifactEncodings, artifact.Encoding) {
+				s.add(artifactPath+".encoding", "unsupported value %q, expected one of %s", artifact.Encoding, strings.Join(artifactEncodings, ", "))
+			}
+			for field, value := range map[string]string{"name": artifact.Name, "fileName": artifact.FileName, "mimeType": artifact.MimeType} {
+				if _, err := template.New("artifact").Funcs(tmplfunc.FuncMap()).Parse(value); err != nil {
+					s.add(artifactPath+"."+field, "invalid template: %v", err)
+				}
+			}
+		}
+		for j, rule := range mapping.ResponseTransform.Mask {
+			rulePath := fmt.Sprintf("%s.responseTransform.mask[%d]", path, j)
+			if rule.Field == "" && rule.Pattern == "" {
+				s.add(rulePath, "requires field or pattern")
+			}
+			if rule.Action != "" && !contains(maskActions, rule.Action) {
+				s.add(rulePath+".action", "unsupported value %q, expected one of %s", rule.Action, strings.Join(maskActions, ", "))
+			}
+			if rule.Action == "drop" && rule.Field == "" {
+				s.add(rulePath+".field", "is required for action drop")
+			}
+			if rule.Keep < 0 {
+				s.add(rulePath+".keep", "must not be negative")
+			}
+			s.checkPattern(rulePath+".pattern", rule.Pattern)
+		}
+		for name, value := range mapping.Headers {
+			if _, err := template.New("header").Funcs(tmplfunc.FuncMap()).Parse(value); err != nil {
+				s.add(path+".headers."+name, "invalid template: %v", err)
+			}
+		}
+		if tmpl := mapping.RequestTemplate; tmpl != "" {
+			if _, err := template.New("request").Funcs(tmplfunc.FuncMap()).Parse(tmpl); err != nil {
+				s.add(path+".requestTemplate", "invalid template: %v", err)
+			}
+		}
+		if tmpl := mapping.ResponseTransform.Template; tmpl != "" {
+			if _, err := template.New("response").Funcs(tmplfunc.FuncMap()).Parse(tmpl); err != nil {
+				s.add(path+".responseTransform.template", "invalid template: %v", err)
+			}
+		}
+		if summary := mapping.ResponseTransform.Summary; summary != nil {
+			summaryPath := path + ".responseTransform.summary"
+			s.checkPath(summaryPath+".source", summary.Source)
+			if summary.Summarizer != "" && !contains(summarizers, summary.Summarizer) {
+				s.add(summaryPath+".summarizer", "unsupported value %q, expected one of %s", summary.Summarizer, strings.Join(summarizers, ", "))
+			}
+			if summary.Summarizer == "llm" && summary.Endpoint == "" {
+				s.add(summaryPath+".endpoint", "is required for summarizer llm")
+			}
+			if summary.MaxRows < 0 {
+				s.add(summaryPath+".maxRows", "must not be negative")
+			}
+			if summary.Template != "" {
+				if _, err := template.New("summary").Funcs(tmplfunc.FuncMap()).Parse(summary.Template); err != nil {
+					s.add(summaryPath+".template", "invalid template: %v", err)
+				}
+			}
+		}
+		if pagination := mapping.Pagination; pagination != nil {
+			s.checkPagination(path+".pagination", pagination)
+		}
+		if mapping.RequestFormat != "" && !contains(bodyFormats, mapping.RequestFormat) {
+			s.add(path+".requestFormat", "unsupported value %q, expected one of %s", mapping.RequestFormat, strings.Join(bodyFormats, ", "))
+		}
+		if mapping.ResponseFormat != "" && !contains(bodyFormats, mapping.ResponseFormat) {
+			s.add(path+".responseFormat", "unsupported value %q, expected one of %s", mapping.ResponseFormat, strings.Join(bodyFormats, ", "))
+		}
+		steps, stepsPath := mapping.Steps, path+".steps"
+		if mapping.FanOut != nil {
+			steps, stepsPath = mapping.FanOut.Branches, path+".fanOut.branches"
+			if mapping.FanOut.Policy != "" && !contains(fanOutPolicies, mapping.FanOut.Policy) {
+				s.add(path+".fanOut.policy", "unsupported value %q, expected one of %s", mapping.FanOut.Policy, strings.Join(fanOutPolicies, ", "))
+			}
+		}
+		if async := mapping.Async; async != nil {
+			s.checkPath(path+".async.jobId", async.JobID)
+			if async.Done == "" {
+				s.add(path+".async.done", "is required")
+			} else if _, err := expr.Parse(async.Done); err != nil {
+				s.add(path+".async.done", "invalid expression: %v", err)
+			}
+			if async.Failed != "" {
+				if _, err := expr.Parse(async.Failed); err != nil {
+					s.add(path+".async.failed", "invalid expression: %v", err)
+				}
+			}
+		}
+		for j, step := range steps {
+			stepPath := fmt.Sprintf("%s[%d]", stepsPath, j)
+			if step.Name == "" {
+				s.add(stepPath+".name", "is required")
+			}
+			if step.When != "" {
+				if _, err := expr.Parse(step.When); err != nil {
+					s.add(stepPath+".when", "invalid expression: %v", err)
+				}
+			}
+		}
+	}
+	for i, rule := range c.Transforms.A2AToLegacy {
+		s.checkRule(fmt.Sprintf("transforms.a2aToLegacy[%d]", i), rule)
+	}
+	for i, rule := range c.Transforms.LegacyToA2A {
+		s.checkRule(fmt.Sprintf("transforms.legacyToA2a[%d]", i), rule)
+	}
+}
+
+// checkRule reports transform rules without a value or target and
+// expressions that do not parse
+func (s *schemaChecker) checkRule(path string, rule TransformRule) {
+	if rule.Target == "" {
+		s.add(path+".target", "is required")
+	}
+	if rule.Source == "" && rule.Expr == "" {
+		s.add(path+".source", "is required unless expr is set")
+	}
+	s.checkPath(path+".source", rule.Source)
+	s.checkPath(path+".target", rule.Target)
+	s.checkPattern(path+".regex", rule.Regex)
+	if rule.Expr != "" {
+		if _, err := expr.Parse(rule.Expr); err != nil {
+			s.add(path+".expr", "invalid expression: %v", err)
+		}
+	}
+}
+
+// checkPagination reports paging settings that cannot produce a next page
+func (s *schemaChecker) checkPagination(path string, pagination *PaginationConfig) {
+	switch {
+	case pagination.MaxItems < 0:
+		s.add(path+".maxItems", "must not be negative")
+	case pagination.MaxItems > 0 && pagination.ItemsPath == "":
+		s.add(path+".itemsPath", "is required when maxItems is set")
+	case pagination.MaxItems == 0 && pagination.NextPath == "":
+		s.add(path, "requires maxItems or nextPath")
+	}
+	if (pagination.NextPath == "") != (pagination.Param == "") {
+		s.add(path+".param", "nextPath and param must be set together")
+	}
+	s.checkPath(path+".itemsPath", pagination.ItemsPath)
+	s.checkPath(path+".nextPath", pagination.NextPath)
+	s.checkPath(path+".param", pagination.Param)
+}
+
+// checkPath reports field paths that do not parse
+func (s *schemaChecker) checkPath(path, fieldPath string) {
+	if fieldPath == "" {
+		return
+	}
+	if _, err := jsonpath.Parse(fieldPath); err != nil {
+		s.add(path, "invalid path: %v", err)
+	}
+}
+
+func (s *schemaChecker) checkPattern(path, pattern string) {
+	if pattern == "" {
+		return
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		s.add(path, "invalid regular expression: %v", err)
+	}
+}
+
+// checkAuth reports unsupported auth types and credentials the type does not use
+func (s *schemaChecker) checkAuth(path string, auth AuthConfig, types []string) {
+	set := map[string]bool{
+		"username": auth.Username != "",
+		"password": auth.Password != "",
+		"token":    auth.Token != "",
+		"keyName":  auth.KeyName != "",
+		"oauth2":   auth.OAuth2 != nil,
+	}
+	authType := strings.ToLower(auth.Type)
+	if authType == "" {
+		for _, field := range []string{"username", "password", "token", "keyName", "oauth2"} {
+			if set[field] {
+				s.add(path+".type", "is required when %s is set, one of %s", field, strings.Join(types, ", "))
+				return
+			}
+		}
+		return
+	}
+	if !contains(types, authType) {
+		s.add(path+".type", "unsupported value %q, expected one of %s", auth.Type, strings.Join(types, ", "))
+		return
+	}
+	for _, field := range []string{"username", "password", "token", "keyName", "oauth2"} {
+		if set[field] && !contains(authFields[authType], field) {
+			s.add(path+"."+field, "cannot be combined with %s auth, which uses %s", authType, strings.Join(authFields[authType], " and "))
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) && len(value) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSchema checks the keys of a config file against the config types and
+// returns the line of each path for later problem reports
+func parseSchema(data []byte) (map[string]int, []FieldError, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	lines, problems := checkKeys(&root)
+	return lines, problems, nil
+}
+
+// checkKeys checks the keys of a parsed config file against the config types
+func checkKeys(root *yaml.Node) (map[string]int, []FieldError) {
+	s := &schemaChecker{lines: make(map[string]int)}
+	s.checkFields(root, reflect.TypeOf(ConnectorConfig{}), "")
+	return s.lines, s.problems
+}
+
+// validateSchema reports every unknown key found when the file was loaded and
+// every invalid value, with its path and line
+func validateSchema(c *ConnectorConfig) error {
+	s := &schemaChecker{lines: c.lines, problems: append([]FieldError(nil), c.keyProblems...)}
+	if s.lines == nil {
+		s.lines = make(map[string]int)
+	}
+	s.checkValues(c)
+	return s.err()
+}
@@ -0,0 +1,140 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+const invalidConfig = `x-base: &base
+  method: GET
+base: 1
+adapter:
+  type: ftp
+  name: crm
+  baseUrl: http://localhost:8081
+  auth:
+    type: basic
+    username: erp
+    token: abc
+  timout:
+    read: 5s
+mappings:
+  - intentPattern: "get.*customer"
+    endpiont: /customers
+    <<: *base
+  - intentPattern: "get.*(order"
+    responseTransform:
+      template: "{{.result.name"
+`
+
+func TestSchemaErrorsReportPathAndLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(invalidConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := config.LoadFromFile(path)
+	var schemaErr *config.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Expected a schema error, got %v", err)
+	}
+
+	want := []struct {
+		path    string
+		line    int
+		message string
+	}{
+		{"base", 3, `unknown field "base"`},
+		{"adapter.type", 5, `unsupported value "ftp"`},
+		{"adapter.auth.token", 11, "cannot be combined with basic auth"},
+		{"adapter.timout", 12, `did you mean "timeout"?`},
+		{"mappings[0].endpiont", 16, `did you mean "endpoint"?`},
+		{"mappings[1].intentPattern", 18, "invalid regular expression"},
+		{"mappings[1].responseTransform.template", 20, "invalid template"},
+	}
+	if len(schemaErr.Problems) != len(want) {
+		t.Fatalf("Expected %d problems, got %v", len(want), err)
+	}
+	for i, w := range want {
+		got := schemaErr.Problems[i]
+		if got.Path != w.path || got.Line != w.line || !strings.Contains(got.Message, w.message) {
+			t.Errorf("Problem %d: expected %s (line %d): %s, got %v", i, w.path, w.line, w.message, got)
+		}
+	}
+}
+
+func TestValidateConfigReportsMissingType(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter:  config.AdapterConfig{BaseURL: "http://localhost:8081"},
+		Mappings: []config.MappingConfig{{IntentPattern: "status"}},
+	}
+	err := config.ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "adapter.type: is required") {
+		t.Fatalf("Expected missing adapter type, got %v", err)
+	}
+}
+
+func TestValidateConfigChecksListeners(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter:  config.AdapterConfig{Type: "rest", BaseURL: "http://localhost:8081"},
+		Mappings: []config.MappingConfig{{IntentPattern: "status", Endpoint: "/status", Method: "GET"}},
+		Server: config.ServerConfig{
+			Listeners: []config.ListenerConfig{
+				{Planes: []string{"data", "admin"}, Address: ":8443", Auth: config.AuthConfig{Type: "bearer", Token: "s3cret"}},
+			},
+		},
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+
+	cfg.Server.Listeners = append(cfg.Server.Listeners, config.ListenerConfig{Planes: []string{"events"}})
+	err := config.ValidateConfig(cfg)
+	var schemaErr *config.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Expected a schema error, got %v", err)
+	}
+	paths := []string{}
+	for _, problem := range schemaErr.Problems {
+		paths = append(paths, problem.Path)
+	}
+	if strings.Join(paths, " ") != "server.listeners[1].planes[0] server.listeners[1].address" {
+		t.Errorf("Unexpected problems %v", err)
+	}
+}
+
+func TestValidateConfigChecksPlugins(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter:  config.AdapterConfig{Type: "plugin", Plugin: "mainframe"},
+		Mappings: []config.MappingConfig{{IntentPattern: "status", Endpoint: "/status", Method: "STATUS"}},
+		Plugins: []config.PluginConfig{
+			{Name: "mainframe", Command: "/opt/plugins/cics", StartTimeout: "30s"},
+		},
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected a valid plugin config, got %v", err)
+	}
+
+	cfg.Adapter.Plugin = "sap"
+	if err := config.ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), `adapter plugin "sap" is not defined`) {
+		t.Errorf("Expected an undefined plugin to be rejected, got %v", err)
+	}
+
+	cfg.Adapter.Plugin = "mainframe"
+	cfg.Plugins = append(cfg.Plugins, config.PluginConfig{Name: "mainframe", StartTimeout: "soon"})
+	err := config.ValidateConfig(cfg)
+	for _, want := range []string{
+		`plugins[1].name: duplicate plugin "mainframe"`,
+		"plugins[1].command: is required",
+		`plugins[1].startTimeout: invalid duration "soon"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+)
+
+// secretsTimeout limits how long resolving all secrets of a config may take
+const secretsTimeout = 30 * time.Second
+
+// Options returns the backend options of the secret resolver
+func (c SecretsConfig) Options() secrets.Options {
+	var opts secrets.Options
+	if c.Vault != nil {
+		opts.VaultAddress = c.Vault.Address
+		opts.VaultTokenFile = c.Vault.TokenFile
+		opts.VaultNamespace = c.Vault.Namespace
+	}
+	if c.AWS != nil {
+		opts.AWSRegion = c.AWS.Region
+		opts.AWSEndpoint = c.AWS.Endpoint
+	}
+	return opts
+}
+
+// resolveSecrets replaces every config value that is a secret reference with
+// the secret and records the references in config.Secrets.Refs
+func resolveSecrets(config *ConnectorConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+
+	resolver := secrets.NewResolver(config.Secrets.Options())
+	seen := make(map[string]bool)
+	var refs []string
+	err := walkStrings(reflect.ValueOf(config).Elem(), func(value string) (string, error) {
+		if !secrets.IsReference(value) {
+			return value, nil
+		}
+		if !seen[value] {
+			seen[value] = true
+			refs = append(refs, value)
+		}
+		return resolver.Resolve(ctx, value)
+	})
+	config.Secrets.Refs = refs
+	return err
+}
+
+// secretReferences returns the secret references of a config that was not
+// resolved, in config order
+func secretReferences(config *ConnectorConfig) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	walkStrings(reflect.ValueOf(config).Elem(), func(value string) (string, error) {
+		if secrets.IsReference(value) && !seen[value] {
+			seen[value] = true
+			refs = append(refs, value)
+		}
+		return value, nil
+	})
+	return refs
+}
+
+// registerRedactions marks the credentials set directly in the config, so
+// they are masked in logs and task errors like resolved secrets
+func registerRedactions(config *ConnectorConfig) {
+	values := []string{
+		config.Adapter.Auth.Password,
+		config.Adapter.Auth.Token,
+		config.Integrity.Secret,
+	}
+	if oauth := config.Adapter.Auth.OAuth2; oauth != nil {
+		values = append(values, oauth.ClientSecret, oauth.RefreshToken)
+	}
+	if u, err := url.Parse(config.Adapter.DSN); err == nil && u.User != nil {
+		password, _ := u.User.Password()
+		values = append(values, password)
+	}
+	for name, value := range config.Adapter.Headers {
+		if secrets.SensitiveKey(name) {
+			values = append(values, value)
+		}
+	}
+	for _, key := range config.Security.APIKeys {
+		values = append(values, key.Key)
+	}
+	for _, key := range config.Security.HMACKeys {
+		values = append(values, key.Secret)
+	}
+	for _, webhook := range config.Webhooks {
+		values = append(values, webhook.Secret)
+		if stream := webhook.Salesforce; stream != nil {
+			values = append(values, stream.Auth.Password, stream.Auth.Token)
+			if oauth := stream.Auth.OAuth2; oauth != nil {
+				values = append(values, oauth.ClientSecret, oauth.RefreshToken)
+			}
+		}
+	}
+	_, listeners := config.Server.AllListeners()
+	for _, listener := range listeners {
+		values = append(values, listener.Auth.Password, listener.Auth.Token)
+		if sso := listener.SSO; sso != nil {
+			values = append(values, sso.ClientSecret, sso.SessionSecret)
+			if sso.SAML != nil {
+				values = append(values, sso.SAML.PrivateKey)
+			}
+		}
+	}
+	if intent := config.Intent; intent != nil {
+		values = append(values, intent.APIKey)
+	}
+	for _, plugin := range config.Plugins {
+		for name, value := range plugin.Env {
+			if secrets.SensitiveKey(name) {
+				values = append(values, value)
+			}
+		}
+	}
+	if config.Anonymize != nil {
+		values = append(values, config.Anonymize.Seed)
+	}
+	for _, value := range values {
+		secrets.AddRedaction(value)
+	}
+}
+
+// walkStrings calls fn for every string below v and stores the result
+func walkStrings(v reflect.Value, fn func(string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.String:
+		value, err := fn(v.String())
+		if err != nil {
+			return err
+		}
+		if v.CanSet() && value != v.String() {
+			v.SetString(value)
+		}
+
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return walkStrings(v.Elem(), fn)
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" || strings.Split(field.Tag.Get("yaml"), ",")[0] == "-" {
+				continue
+			}
+			if err := walkStrings(v.Field(i), fn); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkStrings(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		// Map values are not addressable, so they are updated through a copy
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			if err := walkStrings(elem, fn); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if s, ok := v.Interface().(string); ok {
+			value, err := fn(s)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(value))
+			return nil
+		}
+		return walkStrings(v.Elem(), fn)
+	}
+	return nil
+}
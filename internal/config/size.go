@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxRequestBody is the size limit of task requests when none is configured
+const DefaultMaxRequestBody = 10 << 20
+
+// sizeUnits are the suffixes of sizes, longest first
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size in bytes with an optional KB, MB or GB suffix, e.g. 512KB
+func ParseSize(s string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * multiplier, nil
+}
+
+// Sizes returns the request and response limits in bytes; zero means no limit
+func (b BodyLimitsConfig) Sizes() (request, response int64, err error) {
+	request = DefaultMaxRequestBody
+	if b.MaxRequest != "" {
+		if request, err = ParseSize(b.MaxRequest); err != nil {
+			return 0, 0, err
+		}
+	}
+	if b.MaxResponse != "" {
+		if response, err = ParseSize(b.MaxResponse); err != nil {
+			return 0, 0, err
+		}
+	}
+	return request, response, nil
+}
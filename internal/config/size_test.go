@@ -0,0 +1,42 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func TestParseSize(t *testing.T) {
+	for input, want := range map[string]int64{
+		"0":      0,
+		"512":    512,
+		"512B":   512,
+		"64KB":   64 << 10,
+		"10 MB":  10 << 20,
+		"2gb":    2 << 30,
+		" 1MB  ": 1 << 20,
+	} {
+		if got, err := config.ParseSize(input); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "MB", "-1KB", "1.5MB", "10TB"} {
+		if _, err := config.ParseSize(input); err == nil {
+			t.Errorf("Expected ParseSize(%q) to fail", input)
+		}
+	}
+}
+
+func TestBodyLimitsSizes(t *testing.T) {
+	request, response, err := config.BodyLimitsConfig{}.Sizes()
+	if err != nil || request != config.DefaultMaxRequestBody || response != 0 {
+		t.Errorf("Unexpected defaults %d, %d, %v", request, response, err)
+	}
+	request, response, err = config.BodyLimitsConfig{MaxRequest: "0", MaxResponse: "50MB"}.Sizes()
+	if err != nil || request != 0 || response != 50<<20 {
+		t.Errorf("Unexpected sizes %d, %d, %v", request, response, err)
+	}
+	if _, _, err := (config.BodyLimitsConfig{MaxResponse: "lots"}).Sizes(); err == nil {
+		t.Error("Expected an invalid size to fail")
+	}
+}
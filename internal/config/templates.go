@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateParamPattern matches %{name} placeholders in mapping templates
+var templateParamPattern = regexp.MustCompile(`%\{([A-Za-z0-9_]+)\}`)
+
+// ExpandMappingTemplates replaces mappings that `use` a template with the
+// template instantiated with the mapping's `with` parameters. Fields set
+// directly on the mapping override the ones from the template.
+func (c *ConnectorConfig) ExpandMappingTemplates() error {
+	for i := range c.Mappings {
+		mapping := &c.Mappings[i]
+		if mapping.Use == "" {
+			continue
+		}
+
+		tmpl, ok := c.MappingTemplates[mapping.Use]
+		if !ok {
+			return fmt.Errorf("mapping %d uses unknown template %q", i, mapping.Use)
+		}
+
+		expanded, err := instantiateTemplate(tmpl, mapping.With)
+		if err != nil {
+			return fmt.Errorf("mapping %d: template %q: %v", i, mapping.Use, err)
+		}
+
+		overlayMapping(expanded, mapping)
+		*mapping = *expanded
+	}
+
+	return nil
+}
+
+// instantiateTemplate substitutes %{name} placeholders in every string field of a template
+func instantiateTemplate(tmpl MappingConfig, params map[string]string) (*MappingConfig, error) {
+	// Deep copy the template so instances don't share slices and maps
+	data, err := yaml.Marshal(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	var result MappingConfig
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	missing := make(map[string]bool)
+	substituteStrings(reflect.ValueOf(&result).Elem(), func(s string) string {
+		return templateParamPattern.ReplaceAllStringFunc(s, func(match string) string {
+			name := templateParamPattern.FindStringSubmatch(match)[1]
+			value, ok := params[name]
+			if !ok {
+				missing[name] = true
+				return match
+			}
+			return value
+		})
+	})
+
+	if len(missing) > 0 {
+		var names []string
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("missing parameters: %s", strings.Join(names, ", "))
+	}
+	return &result, nil
+}
+
+// substituteStrings applies fn to every settable string reachable from v
+func substituteStrings(v reflect.Value, fn func(string) string) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(fn(v.String()))
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			substituteStrings(v.Elem(), fn)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				substituteStrings(v.Field(i), fn)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			substituteStrings(v.Index(i), fn)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			elem := v.MapIndex(key)
+			if elem.Kind() == reflect.String {
+				v.SetMapIndex(key, reflect.ValueOf(fn(elem.String())).Convert(elem.Type()))
+			} else if elem.Kind() == reflect.Interface || elem.Kind() == reflect.Map || elem.Kind() == reflect.Slice {
+				copied := reflect.New(elem.Type()).Elem()
+				copied.Set(elem)
+				substituteStrings(copied, fn)
+				v.SetMapIndex(key, copied)
+			}
+		}
+	}
+}
+
+// overlayMapping copies the fields that are set on src onto dst
+func overlayMapping(dst, src *MappingConfig) {
+	dstValue := reflect.ValueOf(dst).Elem()
+	srcValue := reflect.ValueOf(src).Elem()
+
+	for i := 0; i < srcValue.NumField(); i++ {
+		field := srcValue.Type().Field(i)
+		if field.Tag.Get("yaml") == "-" || field.Name == "Use" || field.Name == "With" {
+			continue
+		}
+		if !srcValue.Field(i).IsZero() {
+			dstValue.Field(i).Set(srcValue.Field(i))
+		}
+	}
+}
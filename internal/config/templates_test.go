@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+const templatedConfig = `
+adapter:
+  type: rest
+  name: crm
+  baseUrl: http://localhost:8081
+mappingTemplates:
+  get-entity:
+    intentPattern: "get.*%{entity}"
+    endpoint: "/api/%{path}/{id}"
+    method: GET
+    parameterMappings:
+      - source: text
+        pattern: "%{entity} (\\d+)"
+        target: id
+    responseTransform:
+      template: "%{label}: {{.result.name}}"
+mappings:
+  - use: get-entity
+    with: {entity: customer, path: customers, label: Customer}
+  - use: get-entity
+    with: {entity: order, path: orders, label: Order}
+    method: POST
+`
+
+func TestMappingTemplates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(templatedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	if len(cfg.Mappings) != 2 {
+		t.Fatalf("Expected 2 mappings, got %d", len(cfg.Mappings))
+	}
+
+	customer := cfg.Mappings[0]
+	if customer.IntentPattern != "get.*customer" || customer.Endpoint != "/api/customers/{id}" || customer.Method != "GET" {
+		t.Errorf("Unexpected customer mapping: %+v", customer)
+	}
+	if customer.ParameterMappings[0].Pattern != `customer (\d+)` {
+		t.Errorf("Unexpected parameter pattern: %s", customer.ParameterMappings[0].Pattern)
+	}
+	if customer.ResponseTransform.CompiledTemplate == nil || customer.ResponseTransform.Template != "Customer: {{.result.name}}" {
+		t.Errorf("Unexpected response template: %s", customer.ResponseTransform.Template)
+	}
+
+	order := cfg.Mappings[1]
+	if order.Endpoint != "/api/orders/{id}" || order.Method != "POST" {
+		t.Errorf("Unexpected order mapping: %+v", order)
+	}
+	if order.ParameterMappings[0].Pattern != `order (\d+)` {
+		t.Errorf("Template instances share parameter mappings: %s", order.ParameterMappings[0].Pattern)
+	}
+}
+
+func TestMappingTemplateErrors(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		MappingTemplates: map[string]config.MappingConfig{
+			"tmpl": {IntentPattern: "%{entity}", Endpoint: "/%{path}"},
+		},
+		Mappings: []config.MappingConfig{{Use: "tmpl", With: map[string]string{"entity": "x"}}},
+	}
+	if err := cfg.ExpandMappingTemplates(); err == nil {
+		t.Error("Expected error for missing template parameter")
+	}
+
+	cfg.Mappings = []config.MappingConfig{{Use: "unknown"}}
+	if err := cfg.ExpandMappingTemplates(); err == nil {
+		t.Error("Expected error for unknown template")
+	}
+}
@@ -2,46 +2,873 @@
 package config
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"text/template"
+
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+	"github.com/A2AGateway/a2a-connector/internal/tmplfunc"
 )
 
 // ConnectorConfig represents the full configuration for a connector
 type ConnectorConfig struct {
-	Adapter    AdapterConfig     `yaml:"adapter" json:"adapter"`
-	Mappings   []MappingConfig   `yaml:"mappings" json:"mappings"`
-	Transforms TransformConfig   `yaml:"transforms" json:"transforms"`
-	Variables  map[string]string `yaml:"variables" json:"variables,omitempty"`
+	Include          []string                     `yaml:"include" json:"include,omitempty"`
+	Adapter          AdapterConfig                `yaml:"adapter" json:"adapter"`
+	Mappings         []MappingConfig              `yaml:"mappings" json:"mappings"`
+	MappingTemplates map[string]MappingConfig     `yaml:"mappingTemplates" json:"mappingTemplates,omitempty"`
+	ParameterSets    map[string][]ParameterMapping `yaml:"parameterSets" json:"parameterSets,omitempty"`
+	HeaderSets       map[string]map[string]string `yaml:"headerSets" json:"headerSets,omitempty"`
+	TransformSets    map[string]ResponseTransform `yaml:"transformSets" json:"transformSets,omitempty"`
+	Transforms       TransformConfig              `yaml:"transforms" json:"transforms"`
+	Dictionary       []FieldDefinition            `yaml:"dictionary" json:"dictionary,omitempty"`
+	Variables        map[string]string            `yaml:"variables" json:"variables,omitempty"`
+	StrictVariables  bool                         `yaml:"strictVariables" json:"strictVariables,omitempty"`
+	Locale           string                       `yaml:"locale" json:"locale,omitempty"`
+	Messages         map[string]map[string]string `yaml:"messages" json:"messages,omitempty"`
+	CircuitBreaker   *CircuitBreakerConfig        `yaml:"circuitBreaker" json:"circuitBreaker,omitempty"`
+	Metrics          MetricsConfig                `yaml:"metrics" json:"metrics,omitempty"`
+	Integrity        IntegrityConfig              `yaml:"integrity" json:"integrity,omitempty"`
+	Cache            CacheConfig                  `yaml:"cache" json:"cache,omitempty"`
+	Logging          LoggingConfig                `yaml:"logging" json:"logging,omitempty"`
+	Soak             SoakConfig                   `yaml:"soak" json:"soak,omitempty"`
+	Server           ServerConfig                 `yaml:"server" json:"server,omitempty"`
+	Readiness        ReadinessConfig              `yaml:"readiness" json:"readiness,omitempty"`
+	Kubernetes       KubernetesConfig             `yaml:"kubernetes" json:"kubernetes,omitempty"`
+	Security         SecurityConfig               `yaml:"security" json:"security,omitempty"`
+	Secrets          SecretsConfig                `yaml:"secrets" json:"secrets,omitempty"`
+	Batch            *BatchConfig                 `yaml:"batch" json:"batch,omitempty"`
+	Intent           *IntentConfig                `yaml:"intent" json:"intent,omitempty"`
+	Anonymize        *AnonymizeConfig             `yaml:"anonymize" json:"anonymize,omitempty"`
+	Egress           *EgressConfig                `yaml:"egress" json:"egress,omitempty"`
+	BodyLimits       BodyLimitsConfig             `yaml:"bodyLimits" json:"bodyLimits,omitempty"`
+	Workers          *WorkersConfig               `yaml:"workers" json:"workers,omitempty"`
+	Sessions         SessionConfig                `yaml:"sessions" json:"sessions,omitempty"`
+	DeadLetter       *DeadLetterConfig            `yaml:"deadLetter" json:"deadLetter,omitempty"`
+	Audit            *AuditConfig                 `yaml:"audit" json:"audit,omitempty"`
+	Schedules        []ScheduleConfig             `yaml:"schedules" json:"schedules,omitempty"`
+	Webhooks         []WebhookConfig              `yaml:"webhooks" json:"webhooks,omitempty"`
+	Plugins          []PluginConfig               `yaml:"plugins" json:"plugins,omitempty"`
+
+	// lines maps YAML paths to their line in the loaded file, and keyProblems
+	// holds the unknown keys found there; both are empty for configs built in code
+	lines       map[string]int
+	keyProblems []FieldError
+	// variableSources records the variables taken from the environment, and
+	// variableRefs the ${NAME} references found when resolving variables
+	variableSources map[string]string
+	variableRefs    []VariableReference
+	// encrypted is set when the config was decrypted from an encrypted file
+	encrypted bool
+	// files are the files the config was read from
+	files []string
 }
 
 // AdapterConfig represents the configuration for a specific adapter
 type AdapterConfig struct {
-	Type    string            `yaml:"type" json:"type"`
-	Name    string            `yaml:"name" json:"name"`
-	BaseURL string            `yaml:"baseUrl" json:"baseUrl"`
-	Auth    AuthConfig        `yaml:"auth" json:"auth,omitempty"`
-	Headers map[string]string `yaml:"headers" json:"headers,omitempty"`
+	Type             string            `yaml:"type" json:"type"`
+	Name             string            `yaml:"name" json:"name"`
+	BaseURL          string            `yaml:"baseUrl" json:"baseUrl,omitempty"`
+	Driver           string            `yaml:"driver" json:"driver,omitempty"`
+	DSN              string            `yaml:"dsn" json:"dsn,omitempty"`
+	Auth             AuthConfig        `yaml:"auth" json:"auth,omitempty"`
+	Headers          map[string]string `yaml:"headers" json:"headers,omitempty"`
+	OpenAPI          string            `yaml:"openapi" json:"openapi,omitempty"`
+	WSDL             string            `yaml:"wsdl" json:"wsdl,omitempty"`
+	Namespace        string            `yaml:"namespace" json:"namespace,omitempty"`
+	BasePath         string            `yaml:"basePath" json:"basePath,omitempty"`
+	GenerateMappings bool              `yaml:"generateMappings" json:"generateMappings,omitempty"`
+	SuccessStatuses  []string          `yaml:"successStatuses" json:"successStatuses,omitempty"`
+	Retry            *RetryConfig      `yaml:"retry" json:"retry,omitempty"`
+	Timeout          TimeoutConfig     `yaml:"timeout" json:"timeout,omitempty"`
+	Limits           *LimitsConfig     `yaml:"limits" json:"limits,omitempty"`
+	Charset          string            `yaml:"charset" json:"charset,omitempty"`
+	TLS              *ClientTLSConfig  `yaml:"tls" json:"tls,omitempty"`
+	Codecs           []CodecConfig     `yaml:"codecs" json:"codecs,omitempty"`
+	Socket           *SocketConfig     `yaml:"socket" json:"socket,omitempty"`
+	Telnet           *TelnetConfig     `yaml:"telnet" json:"telnet,omitempty"`
+	Echo             *EchoConfig       `yaml:"echo" json:"echo,omitempty"`
+	Plugin           string            `yaml:"plugin" json:"plugin,omitempty"`
+	SQLPolicy        *SQLPolicyConfig  `yaml:"sqlPolicy" json:"sqlPolicy,omitempty"`
+	QueryLimits      *QueryLimitsConfig `yaml:"queryLimits" json:"queryLimits,omitempty"`
+}
+
+// QueryLimitsConfig guards the database of a db adapter against costly
+// queries of the query method: at most maxRows rows are returned, marked
+// truncated when more match, and a query running longer than timeout is
+// cancelled. With injectLimit the query is wrapped so that the database
+// itself stops after maxRows rows.
+type QueryLimitsConfig struct {
+	MaxRows     int    `yaml:"maxRows" json:"maxRows,omitempty"`
+	Timeout     string `yaml:"timeout" json:"timeout,omitempty"`
+	InjectLimit bool   `yaml:"injectLimit" json:"injectLimit,omitempty"`
+}
+
+// SQLPolicyConfig limits the SQL a db adapter runs, since the query, execute
+// and call actions take statements that may come from agents. Statements must
+// be single and without comments, query runs only SELECTs and execute only
+// writes; DDL is rejected unless allowDDL is set. Verbs, tables and
+// procedures allowlist what statements do, touch and call when set, functions
+// other than the built-ins included, and with proceduresOnly only calls are
+// accepted. Queues allowlist the Oracle AQ queues of enqueue and dequeue,
+// which count as calls of DBMS_AQ.ENQUEUE and DBMS_AQ.DEQUEUE.
+type SQLPolicyConfig struct {
+	Verbs          []string `yaml:"verbs" json:"verbs,omitempty"`
+	Tables         []string `yaml:"tables" json:"tables,omitempty"`
+	Procedures     []string `yaml:"procedures" json:"procedures,omitempty"`
+	Queues         []string `yaml:"queues" json:"queues,omitempty"`
+	ProceduresOnly bool     `yaml:"proceduresOnly" json:"proceduresOnly,omitempty"`
+	AllowDDL       bool     `yaml:"allowDDL" json:"allowDDL,omitempty"`
+}
+
+// EchoConfig configures the echo adapter, which returns the action and params
+// of each call instead of calling a legacy system. Delay holds every call and
+// ErrorRate (0 to 1) fails that fraction of calls with Error, reported as
+// HTTP status ErrorStatus when set.
+type EchoConfig struct {
+	Delay       string  `yaml:"delay" json:"delay,omitempty"`
+	ErrorRate   float64 `yaml:"errorRate" json:"errorRate,omitempty"`
+	Error       string  `yaml:"error" json:"error,omitempty"`
+	ErrorStatus int     `yaml:"errorStatus" json:"errorStatus,omitempty"`
+}
+
+// PluginConfig is an adapter shipped as a separate binary, in any language,
+// used by a plugin adapter naming it. Command is started with Args and with
+// Env added to the connector's environment; it must complete the go-plugin
+// handshake and serve the Adapter service of adapters/plugin within
+// StartTimeout (10s by default). Config is passed to the plugin when it is
+// initialized.
+type PluginConfig struct {
+	Name         string                 `yaml:"name" json:"name"`
+	Command      string                 `yaml:"command" json:"command"`
+	Args         []string               `yaml:"args" json:"args,omitempty"`
+	Env          map[string]string      `yaml:"env" json:"env,omitempty"`
+	Config       map[string]interface{} `yaml:"config" json:"config,omitempty"`
+	StartTimeout string                 `yaml:"startTimeout" json:"startTimeout,omitempty"`
+}
+
+// Plugin returns the plugin named name, or nil
+func (c *ConnectorConfig) Plugin(name string) *PluginConfig {
+	for i := range c.Plugins {
+		if c.Plugins[i].Name == name {
+			return &c.Plugins[i]
+		}
+	}
+	return nil
+}
+
+// TelnetConfig configures the telnet adapter. Prompt is a regular expression
+// marking that the application waits for input; login steps run after
+// connecting and logout is sent before disconnecting.
+type TelnetConfig struct {
+	Address    string             `yaml:"address" json:"address"`
+	Prompt     string             `yaml:"prompt" json:"prompt"`
+	LineEnding string             `yaml:"lineEnding" json:"lineEnding,omitempty"`
+	Login      []TelnetStepConfig `yaml:"login" json:"login,omitempty"`
+	Logout     string             `yaml:"logout" json:"logout,omitempty"`
+}
+
+// TelnetStepConfig sends a line, then waits for output matching expect (the
+// prompt by default) and stores the output before it under capture
+type TelnetStepConfig struct {
+	Send    string `yaml:"send" json:"send,omitempty"`
+	Expect  string `yaml:"expect" json:"expect,omitempty"`
+	Capture string `yaml:"capture" json:"capture,omitempty"`
+}
+
+// SocketConfig configures the tcp adapter. Framing is length (big-endian
+// length prefix of lengthBytes: 1, 2 or 4, default 4), delimiter (default
+// "\n") or fixed (recordSize bytes, default the codec's record length).
+// Messages are JSON unless contentType names a configured codec.
+type SocketConfig struct {
+	Address     string `yaml:"address" json:"address"`
+	Framing     string `yaml:"framing" json:"framing"`
+	LengthBytes int    `yaml:"lengthBytes" json:"lengthBytes,omitempty"`
+	Delimiter   string `yaml:"delimiter" json:"delimiter,omitempty"`
+	RecordSize  int    `yaml:"recordSize" json:"recordSize,omitempty"`
+	ContentType string `yaml:"contentType" json:"contentType,omitempty"`
+	Persistent  bool   `yaml:"persistent" json:"persistent,omitempty"`
+}
+
+// CodecConfig decodes and encodes a proprietary content type with a built-in
+// codec. The only type is "fixed": records of fixed-length fields.
+type CodecConfig struct {
+	ContentType string             `yaml:"contentType" json:"contentType"`
+	Type        string             `yaml:"type" json:"type"`
+	Fields      []FixedFieldConfig `yaml:"fields" json:"fields,omitempty"`
+}
+
+// FixedFieldConfig is a field of a fixed-width record. Type is string
+// (default), int or decimal.
+type FixedFieldConfig struct {
+	Name   string `yaml:"name" json:"name"`
+	Length int    `yaml:"length" json:"length"`
+	Type   string `yaml:"type" json:"type,omitempty"`
+}
+
+// ClientTLSConfig sets the TLS used toward the legacy system: a CA bundle for
+// private CAs, a client certificate for mutual TLS and, for testing only,
+// skipping certificate verification
+type ClientTLSConfig struct {
+	CAFile             string `yaml:"caFile" json:"caFile,omitempty"`
+	CertFile           string `yaml:"certFile" json:"certFile,omitempty"`
+	KeyFile            string `yaml:"keyFile" json:"keyFile,omitempty"`
+	ServerName         string `yaml:"serverName" json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify" json:"insecureSkipVerify,omitempty"`
+}
+
+// TimeoutConfig limits how long calls to the legacy system may take.
+// Durations use Go syntax; empty values leave the limit unset.
+type TimeoutConfig struct {
+	Connect string `yaml:"connect" json:"connect,omitempty"`
+	Read    string `yaml:"read" json:"read,omitempty"`
+	Total   string `yaml:"total" json:"total,omitempty"`
+}
+
+// RetryConfig configures retries of transient legacy failures.
+// Durations use Go syntax, e.g. "200ms" or "2s".
+type RetryConfig struct {
+	MaxAttempts       int      `yaml:"maxAttempts" json:"maxAttempts,omitempty"`
+	InitialBackoff    string   `yaml:"initialBackoff" json:"initialBackoff,omitempty"`
+	MaxBackoff        string   `yaml:"maxBackoff" json:"maxBackoff,omitempty"`
+	Multiplier        float64  `yaml:"multiplier" json:"multiplier,omitempty"`
+	Jitter            float64  `yaml:"jitter" json:"jitter,omitempty"`
+	RetryableStatuses []int    `yaml:"retryableStatuses" json:"retryableStatuses,omitempty"`
+	RetryableErrors   []string `yaml:"retryableErrors" json:"retryableErrors,omitempty"`
+}
+
+// LimitsConfig caps the call rate and concurrency toward the legacy system.
+// Overflow is "queue" (wait up to MaxWait) or "reject" (fail with a retry-after hint).
+type LimitsConfig struct {
+	RatePerSecond float64 `yaml:"ratePerSecond" json:"ratePerSecond,omitempty"`
+	Burst         int     `yaml:"burst" json:"burst,omitempty"`
+	MaxConcurrent int     `yaml:"maxConcurrent" json:"maxConcurrent,omitempty"`
+	Overflow      string  `yaml:"overflow" json:"overflow,omitempty"`
+	MaxWait       string  `yaml:"maxWait" json:"maxWait,omitempty"`
+}
+
+// BodyLimitsConfig caps the size of task requests the connector accepts
+// (DefaultMaxRequestBody when empty, "0" for no limit) and of responses it
+// reads from the legacy system (no limit when empty). Sizes are bytes with an
+// optional KB, MB or GB suffix (powers of 1024).
+type BodyLimitsConfig struct {
+	MaxRequest  string `yaml:"maxRequest" json:"maxRequest,omitempty"`
+	MaxResponse string `yaml:"maxResponse" json:"maxResponse,omitempty"`
+}
+
+// WorkersConfig bounds the tasks the connector executes at once to Size.
+// Up to QueueSize more tasks wait for a worker; further tasks are rejected
+// with a busy error telling the caller when to retry.
+type WorkersConfig struct {
+	Size      int `yaml:"size" json:"size"`
+	QueueSize int `yaml:"queueSize" json:"queueSize,omitempty"`
+}
+
+// CircuitBreakerConfig configures failing fast while the legacy system is down
+type CircuitBreakerConfig struct {
+	FailureThreshold int    `yaml:"failureThreshold" json:"failureThreshold,omitempty"`
+	CoolDown         string `yaml:"coolDown" json:"coolDown,omitempty"`
+	HalfOpenRequests int    `yaml:"halfOpenRequests" json:"halfOpenRequests,omitempty"`
+}
+
+// MetricsConfig configures which task metadata keys become metric labels and audit fields
+type MetricsConfig struct {
+	Labels         []string `yaml:"labels" json:"labels,omitempty"`
+	MaxLabelValues int      `yaml:"maxLabelValues" json:"maxLabelValues,omitempty"`
+}
+
+// IntegrityConfig configures checksums or signatures on task payloads exchanged with the SaaS.
+// Mode is "digest" (SHA-256 Content-Digest header) or "jws" (detached HS256 JWS).
+type IntegrityConfig struct {
+	Mode     string `yaml:"mode" json:"mode,omitempty"`
+	Required bool   `yaml:"required" json:"required,omitempty"`
+	Secret   string `yaml:"secret" json:"secret,omitempty"`
+}
+
+// ServerConfig places task traffic, the admin API, metrics/health and the
+// webhooks of legacy systems on separate listeners. Admin, metrics and
+// webhooks share the data listener when their address is empty. Listeners
+// serve their Planes on further addresses, e.g. the data plane with mutual
+// TLS for the gateway and without for agents in the same cluster.
+type ServerConfig struct {
+	Data      ListenerConfig   `yaml:"data" json:"data,omitempty"`
+	Admin     ListenerConfig   `yaml:"admin" json:"admin,omitempty"`
+	Metrics   ListenerConfig   `yaml:"metrics" json:"metrics,omitempty"`
+	Webhooks  ListenerConfig   `yaml:"webhooks" json:"webhooks,omitempty"`
+	Listeners []ListenerConfig `yaml:"listeners" json:"listeners,omitempty"`
+}
+
+// Planes served by the connector's listeners
+var Planes = []string{"data", "admin", "metrics", "webhooks"}
+
+// ListenerConfig configures one HTTP listener. Address is host:port, or :port
+// to listen on all interfaces. Auth type is "basic" or "bearer". SSO signs
+// users of the admin plane in with OpenID Connect instead of static credentials.
+// Planes names the planes of a listener in server.listeners.
+type ListenerConfig struct {
+	Planes  []string   `yaml:"planes" json:"planes,omitempty"`
+	Address string     `yaml:"address" json:"address,omitempty"`
+	TLS     *TLSConfig `yaml:"tls" json:"tls,omitempty"`
+	Auth    AuthConfig `yaml:"auth" json:"auth,omitempty"`
+	SSO     *SSOConfig `yaml:"sso" json:"sso,omitempty"`
+}
+
+// AllListeners returns the listener of each plane followed by those of
+// server.listeners, keyed by their path below server
+func (s *ServerConfig) AllListeners() ([]string, []*ListenerConfig) {
+	names := append([]string{}, Planes...)
+	listeners := []*ListenerConfig{&s.Data, &s.Admin, &s.Metrics, &s.Webhooks}
+	for i := range s.Listeners {
+		names = append(names, fmt.Sprintf("listeners[%d]", i))
+		listeners = append(listeners, &s.Listeners[i])
+	}
+	return names, listeners
+}
+
+// ServedPlanes returns the planes a listener serves: Planes for those of
+// server.listeners, or the plane it is configured for
+func (l *ListenerConfig) ServedPlanes(name string) []string {
+	if len(l.Planes) > 0 {
+		return l.Planes
+	}
+	return []string{name}
+}
+
+// SSOConfig signs admin users in with the OpenID Connect authorization code
+// flow, or with SAML when SAML is set, and maps their identity provider groups
+// to admin roles: admin (full access) or viewer (read only). SessionSecret
+// signs session cookies; without it sessions do not survive a restart and are
+// not shared between replicas.
+type SSOConfig struct {
+	Issuer        string            `yaml:"issuer" json:"issuer,omitempty"`
+	ClientID      string            `yaml:"clientId" json:"clientId,omitempty"`
+	ClientSecret  string            `yaml:"clientSecret" json:"clientSecret,omitempty"`
+	RedirectURL   string            `yaml:"redirectUrl" json:"redirectUrl,omitempty"`
+	Scopes        []string          `yaml:"scopes" json:"scopes,omitempty"`
+	GroupsClaim   string            `yaml:"groupsClaim" json:"groupsClaim,omitempty"`
+	SAML          *SAMLConfig       `yaml:"saml" json:"saml,omitempty"`
+	GroupRoles    map[string]string `yaml:"groupRoles" json:"groupRoles"`
+	SessionSecret string            `yaml:"sessionSecret" json:"sessionSecret,omitempty"`
+	SessionTTL    string            `yaml:"sessionTtl" json:"sessionTtl,omitempty"`
+}
+
+// SAMLConfig signs admin users in with SAML 2.0 instead of OpenID Connect.
+// The connector is a service provider whose metadata is served next to
+// ACSURL, the public URL of /admin/saml/acs, and whose entity ID is the
+// metadata URL unless EntityID is set. The identity provider described by
+// IDPMetadataURL must sign its assertions; GroupsAttribute names the assertion
+// attribute holding the user's groups, by name or friendly name. With a PEM
+// Certificate and RSA PrivateKey, sign-in requests are signed and encrypted
+// assertions accepted.
+type SAMLConfig struct {
+	IDPMetadataURL  string `yaml:"idpMetadataUrl" json:"idpMetadataUrl"`
+	ACSURL          string `yaml:"acsUrl" json:"acsUrl"`
+	EntityID        string `yaml:"entityId" json:"entityId,omitempty"`
+	GroupsAttribute string `yaml:"groupsAttribute" json:"groupsAttribute,omitempty"`
+	Certificate     string `yaml:"certificate" json:"certificate,omitempty"`
+	PrivateKey      string `yaml:"privateKey" json:"privateKey,omitempty"`
+}
+
+// TLSConfig enables TLS on a listener. ClientCAFile enables mutual TLS;
+// ClientAuth is require (default) or optional, and ClientNames restricts the
+// accepted client certificate names.
+type TLSConfig struct {
+	CertFile     string   `yaml:"certFile" json:"certFile"`
+	KeyFile      string   `yaml:"keyFile" json:"keyFile"`
+	MinVersion   string   `yaml:"minVersion" json:"minVersion,omitempty"`
+	CipherSuites []string `yaml:"cipherSuites" json:"cipherSuites,omitempty"`
+	ClientCAFile string   `yaml:"clientCAFile" json:"clientCAFile,omitempty"`
+	ClientAuth   string   `yaml:"clientAuth" json:"clientAuth,omitempty"`
+	ClientNames  []string `yaml:"clientNames" json:"clientNames,omitempty"`
+}
+
+// LoggingConfig sets the log level (debug, info, warn, error) and format (console or json)
+type LoggingConfig struct {
+	Level  string `yaml:"level" json:"level,omitempty"`
+	Format string `yaml:"format" json:"format,omitempty"`
+}
+
+// ReadinessConfig sets the timeout and result cache of the /readyz backend probe
+type ReadinessConfig struct {
+	Timeout  string `yaml:"timeout" json:"timeout,omitempty"`
+	CacheTTL string `yaml:"cacheTTL" json:"cacheTTL,omitempty"`
+}
+
+// SecretsConfig sets up the backends of vault:, awssm: and file: references
+// in config values, and how often referenced secrets are checked for rotation
+type SecretsConfig struct {
+	RefreshInterval string            `yaml:"refreshInterval" json:"refreshInterval,omitempty"`
+	Vault           *VaultConfig      `yaml:"vault" json:"vault,omitempty"`
+	AWS             *AWSSecretsConfig `yaml:"aws" json:"aws,omitempty"`
+	// Refs are the references of the config, resolved when it was loaded
+	// unless it was loaded offline
+	Refs []string `yaml:"-" json:"-"`
+}
+
+// VaultConfig locates a Vault server; empty values fall back to VAULT_ADDR,
+// VAULT_TOKEN and VAULT_NAMESPACE
+type VaultConfig struct {
+	Address   string `yaml:"address" json:"address,omitempty"`
+	TokenFile string `yaml:"tokenFile" json:"tokenFile,omitempty"`
+	Namespace string `yaml:"namespace" json:"namespace,omitempty"`
+}
+
+// AWSSecretsConfig selects the AWS Secrets Manager region; credentials come
+// from the standard AWS environment variables
+type AWSSecretsConfig struct {
+	Region   string `yaml:"region" json:"region,omitempty"`
+	Endpoint string `yaml:"endpoint" json:"endpoint,omitempty"`
+}
+
+// SecurityConfig authenticates inbound task requests with API keys, HMAC
+// request signatures or JWTs. Requests are open when no method is configured.
+type SecurityConfig struct {
+	APIKeyHeader  string               `yaml:"apiKeyHeader" json:"apiKeyHeader,omitempty"`
+	APIKeys       []APIKeyConfig       `yaml:"apiKeys" json:"apiKeys,omitempty"`
+	HMACKeys      []HMACKeyConfig      `yaml:"hmacKeys" json:"hmacKeys,omitempty"`
+	MaxClockSkew  string               `yaml:"maxClockSkew" json:"maxClockSkew,omitempty"`
+	JWT           *JWTConfig           `yaml:"jwt" json:"jwt,omitempty"`
+	Authorization *AuthorizationConfig `yaml:"authorization" json:"authorization,omitempty"`
+}
+
+// APIKeyConfig is a static API key and the scopes and roles it grants
+type APIKeyConfig struct {
+	Name   string   `yaml:"name" json:"name"`
+	Key    string   `yaml:"key" json:"key"`
+	Scopes []string `yaml:"scopes" json:"scopes,omitempty"`
+	Roles  []string `yaml:"roles" json:"roles,omitempty"`
+}
+
+// HMACKeyConfig is a shared secret for signed requests and the scopes and
+// roles it grants
+type HMACKeyConfig struct {
+	KeyID  string   `yaml:"keyId" json:"keyId"`
+	Secret string   `yaml:"secret" json:"secret"`
+	Scopes []string `yaml:"scopes" json:"scopes,omitempty"`
+	Roles  []string `yaml:"roles" json:"roles,omitempty"`
+}
+
+// JWTConfig validates bearer JWTs against the issuer's JWKS. ScopeClaim
+// defaults to "scope" (space-separated) and also accepts "scp" arrays.
+// RoleClaim defaults to "roles" and may be a dotted path such as
+// "realm_access.roles".
+type JWTConfig struct {
+	Issuer     string `yaml:"issuer" json:"issuer"`
+	Audience   string `yaml:"audience" json:"audience,omitempty"`
+	JWKSURL    string `yaml:"jwksUrl" json:"jwksUrl"`
+	ScopeClaim string `yaml:"scopeClaim" json:"scopeClaim,omitempty"`
+	RoleClaim  string `yaml:"roleClaim" json:"roleClaim,omitempty"`
+}
+
+// AuthorizationConfig decides which authenticated callers may run a task.
+// Besides the scopes and roles of its mapping, a task needs those of each
+// rule in Actions that names one of the legacy actions it calls. With
+// DenyByDefault, tasks whose mapping and actions require neither are denied.
+// Denials are always logged; LogDecisions logs the tasks allowed as well.
+type AuthorizationConfig struct {
+	DenyByDefault bool               `yaml:"denyByDefault" json:"denyByDefault,omitempty"`
+	LogDecisions  bool               `yaml:"logDecisions" json:"logDecisions,omitempty"`
+	Actions       []ActionRuleConfig `yaml:"actions" json:"actions,omitempty"`
+}
+
+// ActionRuleConfig requires every one of Scopes and one of Roles for the tasks
+// calling any of Actions, such as the POST and DELETE methods of a REST system
+// or the execute action of a database; actions match case-insensitively
+type ActionRuleConfig struct {
+	Actions []string `yaml:"actions" json:"actions"`
+	Scopes  []string `yaml:"scopes" json:"scopes,omitempty"`
+	Roles   []string `yaml:"roles" json:"roles,omitempty"`
+}
+
+// KubernetesConfig enables config reload from a mounted ConfigMap, sets the
+// drain behavior on SIGTERM and optionally elects a leader among replicas
+type KubernetesConfig struct {
+	Reload          bool                  `yaml:"reload" json:"reload,omitempty"`
+	ReloadInterval  string                `yaml:"reloadInterval" json:"reloadInterval,omitempty"`
+	DrainDelay      string                `yaml:"drainDelay" json:"drainDelay,omitempty"`
+	ShutdownTimeout string                `yaml:"shutdownTimeout" json:"shutdownTimeout,omitempty"`
+	LeaderElection  *LeaderElectionConfig `yaml:"leaderElection" json:"leaderElection,omitempty"`
+}
+
+// LeaderElectionConfig names the Lease used to elect one replica for singleton work
+type LeaderElectionConfig struct {
+	LeaseName     string `yaml:"leaseName" json:"leaseName"`
+	Namespace     string `yaml:"namespace" json:"namespace,omitempty"`
+	LeaseDuration string `yaml:"leaseDuration" json:"leaseDuration,omitempty"`
+	RenewInterval string `yaml:"renewInterval" json:"renewInterval,omitempty"`
+}
+
+// SoakConfig configures the long-running soak mode used before go-live
+type SoakConfig struct {
+	Probes            []SoakProbe `yaml:"probes" json:"probes,omitempty"`
+	Interval          string      `yaml:"interval" json:"interval,omitempty"`
+	Duration          string      `yaml:"duration" json:"duration,omitempty"`
+	Window            string      `yaml:"window" json:"window,omitempty"`
+	MaxErrorRate      float64     `yaml:"maxErrorRate" json:"maxErrorRate,omitempty"`
+	MaxMemoryGrowthMB float64     `yaml:"maxMemoryGrowthMB" json:"maxMemoryGrowthMB,omitempty"`
+}
+
+// SoakProbe is a task text executed repeatedly during a soak run
+type SoakProbe struct {
+	Name string `yaml:"name" json:"name,omitempty"`
+	Text string `yaml:"text" json:"text"`
+}
+
+// CacheConfig selects the backend for cached legacy responses
+type CacheConfig struct {
+	Backend    string `yaml:"backend" json:"backend,omitempty"`
+	MaxEntries int    `yaml:"maxEntries" json:"maxEntries,omitempty"`
+}
+
+// SessionConfig configures the store of session variables, which mappings
+// set from their tasks and later tasks of the same A2A session read. Variables
+// expire TTL (1h by default) after their session last set one. Backend and
+// MaxEntries select the store like those of the response cache.
+type SessionConfig struct {
+	TTL        string `yaml:"ttl" json:"ttl,omitempty"`
+	Backend    string `yaml:"backend" json:"backend,omitempty"`
+	MaxEntries int    `yaml:"maxEntries" json:"maxEntries,omitempty"`
+}
+
+// DeadLetterConfig keeps the tasks that failed in a transform or the legacy
+// system, with their legacy request and error, so they can be replayed from
+// the admin API once the mapping or backend is fixed. Backend memory (the
+// default) keeps the last MaxEntries (1000 by default); file keeps them as
+// files in Directory, which survive restarts; http also posts each one to
+// URL, such as the HTTP ingest of an external queue, with Headers, within
+// Timeout (10s by default).
+type DeadLetterConfig struct {
+	Backend    string            `yaml:"backend" json:"backend,omitempty"`
+	Directory  string            `yaml:"directory" json:"directory,omitempty"`
+	URL        string            `yaml:"url" json:"url,omitempty"`
+	Headers    map[string]string `yaml:"headers" json:"headers,omitempty"`
+	Timeout    string            `yaml:"timeout" json:"timeout,omitempty"`
+	MaxEntries int               `yaml:"maxEntries" json:"maxEntries,omitempty"`
+}
+
+// AuditConfig records every call that may change the legacy system, with
+// the adapter, action, params, task, caller, outcome and latency, to File
+// (appended as JSON lines) and/or Syslog. Calls whose action, or method param,
+// is one of ReadActions (case-insensitive; by default GET, HEAD, OPTIONS,
+// query, read and list) are not recorded. Params named in Redact are masked
+// along with secrets and credentials.
+type AuditConfig struct {
+	File        string        `yaml:"file" json:"file,omitempty"`
+	Syslog      *SyslogConfig `yaml:"syslog" json:"syslog,omitempty"`
+	ReadActions []string      `yaml:"readActions" json:"readActions,omitempty"`
+	Redact      []string      `yaml:"redact" json:"redact,omitempty"`
+}
+
+// SyslogConfig sends audit records to a syslog server as RFC 5424 messages.
+// Network is udp (the default), tcp or unixgram; Address is host:port, or the
+// socket path for unixgram (/dev/log by default). Facility defaults to local0;
+// Tag is the app name, a2a-connector by default.
+type SyslogConfig struct {
+	Network  string `yaml:"network" json:"network,omitempty"`
+	Address  string `yaml:"address" json:"address,omitempty"`
+	Facility string `yaml:"facility" json:"facility,omitempty"`
+	Tag      string `yaml:"tag" json:"tag,omitempty"`
+}
+
+// ScheduleConfig starts a task on a cron schedule, such as an hourly sync of
+// new legacy records. The task has Text as its message and Metadata as its
+// metadata, so it runs the mapping that matches them like a task from the
+// gateway, and its result is pushed to the gateway. Cron is five fields
+// (minute, hour, day of month, month, day of week), a macro such as @hourly,
+// or @every followed by a duration; it is evaluated in Timezone, UTC by
+// default.
+type ScheduleConfig struct {
+	Name     string                 `yaml:"name" json:"name"`
+	Cron     string                 `yaml:"cron" json:"cron"`
+	Timezone string                 `yaml:"timezone" json:"timezone,omitempty"`
+	Text     string                 `yaml:"text" json:"text"`
+	Metadata map[string]interface{} `yaml:"metadata" json:"metadata,omitempty"`
+}
+
+// WebhookConfig receives the events a legacy system posts to Path and forwards
+// them to the gateway as A2A tasks or, with Kind message, as messages. With
+// Secret the body must be signed with its hex HMAC-SHA256 in SignatureHeader
+// (X-Signature by default), optionally prefixed with sha256=. With
+// TimestampHeader the signature covers the Unix timestamp in that header and
+// the body joined by a dot, and each event is accepted once and only within
+// 5 minutes of its timestamp. The message has
+// Text, a template over the event, and the event as data; the LegacyToA2A
+// rules then copy event fields to the task or message, such as its id. With
+// Salesforce the events come from Streaming API channels instead of Path.
+type WebhookConfig struct {
+	Name             string             `yaml:"name" json:"name"`
+	Path             string             `yaml:"path" json:"path"`
+	Secret           string             `yaml:"secret" json:"secret,omitempty"`
+	SignatureHeader  string             `yaml:"signatureHeader" json:"signatureHeader,omitempty"`
+	TimestampHeader  string             `yaml:"timestampHeader" json:"timestampHeader,omitempty"`
+	Kind             string             `yaml:"kind" json:"kind,omitempty"`
+	Text             string             `yaml:"text" json:"text,omitempty"`
+	LegacyToA2A      []TransformRule    `yaml:"legacyToA2a" json:"legacyToA2a,omitempty"`
+	Salesforce       *SalesforceStream  `yaml:"salesforce" json:"salesforce,omitempty"`
+	CompiledTemplate *template.Template `yaml:"-" json:"-"`
+}
+
+// SalesforceStream subscribes a webhook to Salesforce Streaming API channels:
+// /data/ for Change Data Capture, /event/ for platform events and /topic/ for
+// PushTopics. Channels start at Replay, new (default) or all retained events,
+// until ReplayFile holds the replay ID of their last forwarded event.
+type SalesforceStream struct {
+	InstanceURL string           `yaml:"instanceUrl" json:"instanceUrl"`
+	Auth        AuthConfig       `yaml:"auth" json:"auth"`
+	TLS         *ClientTLSConfig `yaml:"tls" json:"tls,omitempty"`
+	Channels    []string         `yaml:"channels" json:"channels"`
+	Replay      string           `yaml:"replay" json:"replay,omitempty"`
+	ReplayFile  string           `yaml:"replayFile" json:"replayFile,omitempty"`
+}
+
+// MappingCacheConfig enables response caching for a mapping. Key is a template
+// of {param} placeholders; an empty key uses all extracted parameters.
+type MappingCacheConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	TTL     string `yaml:"ttl" json:"ttl,omitempty"`
+	Key     string `yaml:"key" json:"key,omitempty"`
+}
+
+// BatchConfig splits a file sent with a task into one task per record or
+// chunk of records. Format (json, csv, yaml or lines) applies when the file's
+// MIME type and name do not tell.
+type BatchConfig struct {
+	Enabled     bool   `yaml:"enabled" json:"enabled"`
+	Format      string `yaml:"format" json:"format,omitempty"`
+	ChunkSize   int    `yaml:"chunkSize" json:"chunkSize,omitempty"`
+	MaxRecords  int    `yaml:"maxRecords" json:"maxRecords,omitempty"`
+	StopOnError bool   `yaml:"stopOnError" json:"stopOnError,omitempty"`
+}
+
+// IntentConfig selects how tasks are matched to mappings. Mode regex (the
+// default) uses intent patterns only; llm asks the classifier first and falls
+// back to the patterns; hybrid uses the patterns first and asks the
+// classifier when none matches. Classifier llm (the default) calls an
+// OpenAI-compatible chat completions Endpoint, which also extracts the slots
+// of parameter mappings; similarity compares the task text with mapping
+// descriptions locally. Choices below MinConfidence are ignored.
+type IntentConfig struct {
+	Mode          string  `yaml:"mode" json:"mode,omitempty"`
+	Classifier    string  `yaml:"classifier" json:"classifier,omitempty"`
+	Endpoint      string  `yaml:"endpoint" json:"endpoint,omitempty"`
+	Model         string  `yaml:"model" json:"model,omitempty"`
+	APIKey        string  `yaml:"apiKey" json:"apiKey,omitempty"`
+	Timeout       string  `yaml:"timeout" json:"timeout,omitempty"`
+	MinConfidence float64 `yaml:"minConfidence" json:"minConfidence,omitempty"`
+}
+
+// AnonymizeConfig replaces personal data with fake data when recorded traffic
+// or task fixtures are exported. Seed keys the fake data, so a value is
+// replaced the same way in every export made with the same seed.
+type AnonymizeConfig struct {
+	Seed  string          `yaml:"seed" json:"seed,omitempty"`
+	Rules []AnonymizeRule `yaml:"rules" json:"rules,omitempty"`
+}
+
+// AnonymizeRule selects the values whose key path ends with Field, where *
+// matches any key, and the parts of strings that match Pattern; with both,
+// only the matches within the field. Kind fake (the default) keeps the
+// format, replacing letters and digits; email and name make up values of
+// that kind; redact masks them.
+type AnonymizeRule struct {
+	Field    string         `yaml:"field" json:"field,omitempty"`
+	Pattern  string         `yaml:"pattern" json:"pattern,omitempty"`
+	Kind     string         `yaml:"kind" json:"kind,omitempty"`
+	Compiled *regexp.Regexp `yaml:"-" json:"-"`
+}
+
+// EgressConfig keeps classes of data from leaving the connector toward agents,
+// whatever mapping or webhook returns them
+type EgressConfig struct {
+	Classes []DataClassConfig `yaml:"classes" json:"classes"`
+}
+
+// DataClassConfig is a class of data, such as internal-only fields or data
+// that must stay in its region: the values whose key path ends with one of
+// Fields, where * matches any key, and the parts of strings that match one of
+// Patterns. Action strip (the default) removes them from responses and events;
+// block withholds the whole response or event.
+type DataClassConfig struct {
+	Name     string           `yaml:"name" json:"name"`
+	Fields   []string         `yaml:"fields" json:"fields,omitempty"`
+	Patterns []string         `yaml:"patterns" json:"patterns,omitempty"`
+	Action   string           `yaml:"action" json:"action,omitempty"`
+	Compiled []*regexp.Regexp `yaml:"-" json:"-"`
+}
+
+// PaginationConfig splits large results into pages an agent fetches with a
+// continuation token. ItemsPath and MaxItems truncate a result list; NextPath
+// is the legacy cursor of the next page, sent back in the Param parameter.
+type PaginationConfig struct {
+	ItemsPath string `yaml:"itemsPath" json:"itemsPath,omitempty"`
+	MaxItems  int    `yaml:"maxItems" json:"maxItems,omitempty"`
+	NextPath  string `yaml:"nextPath" json:"nextPath,omitempty"`
+	Param     string `yaml:"param" json:"param,omitempty"`
 }
 
 // AuthConfig represents authentication configuration
 type AuthConfig struct {
-	Type     string `yaml:"type" json:"type"`
-	Username string `yaml:"username" json:"username,omitempty"`
-	Password string `yaml:"password" json:"password,omitempty"`
-	Token    string `yaml:"token" json:"token,omitempty"`
-	KeyName  string `yaml:"keyName" json:"keyName,omitempty"`
+	Type     string        `yaml:"type" json:"type"`
+	Username string        `yaml:"username" json:"username,omitempty"`
+	Password string        `yaml:"password" json:"password,omitempty"`
+	Token    string        `yaml:"token" json:"token,omitempty"`
+	KeyName  string        `yaml:"keyName" json:"keyName,omitempty"`
+	OAuth2   *OAuth2Config `yaml:"oauth2" json:"oauth2,omitempty"`
 }
 
-// MappingConfig represents a mapping between A2A tasks and legacy endpoints
+// OAuth2Config obtains access tokens for outbound calls with the
+// client_credentials (default) or refresh_token grant
+type OAuth2Config struct {
+	TokenURL     string            `yaml:"tokenUrl" json:"tokenUrl"`
+	GrantType    string            `yaml:"grantType" json:"grantType,omitempty"`
+	ClientID     string            `yaml:"clientId" json:"clientId"`
+	ClientSecret string            `yaml:"clientSecret" json:"clientSecret,omitempty"`
+	RefreshToken string            `yaml:"refreshToken" json:"refreshToken,omitempty"`
+	Scopes       []string          `yaml:"scopes" json:"scopes,omitempty"`
+	Params       map[string]string `yaml:"params" json:"params,omitempty"`
+}
+
+// MappingConfig represents a mapping between A2A tasks and legacy endpoints.
+// Priority orders its tasks when they queue for the legacy system, higher
+// first, and TTL is how long after submission a task may still be executed.
+// Mappings are matched by ascending Order, then in file order; When is an
+// expression over the task that must also hold. Fallback mappings are only
+// tried when no other mapping matches. Description tells intent classifiers
+// what the mapping does. Steps replace the single legacy call of Endpoint and
+// Method with several calls in order, FanOut with several calls in parallel;
+// Async polls the job the legacy call starts until it is done.
+// RequestFormat (json, xml, form or text) encodes the body param of the
+// legacy request; ResponseFormat parses the legacy response whatever its
+// content type. RequestTemplate renders the whole request body instead, from
+// .task, .text and .params, sent with the media type of RequestFormat. Files
+// place the files of the task's FileParts in the legacy request. Input asks
+// the agent for more information instead of completing the task. Session
+// stores variables in the session of the task when it completes, by name,
+// from paths in the legacy response such as result.id or params.name.
 type MappingConfig struct {
-	IntentPattern     string              `yaml:"intentPattern" json:"intentPattern"`
-	Endpoint          string              `yaml:"endpoint" json:"endpoint"`
-	Method            string              `yaml:"method" json:"method"`
-	ParameterMappings []ParameterMapping  `yaml:"parameterMappings" json:"parameterMappings,omitempty"`
-	ResponseTransform ResponseTransform   `yaml:"responseTransform" json:"responseTransform,omitempty"`
-	CompiledPattern   *regexp.Regexp      `yaml:"-" json:"-"`
-	CompiledTemplate  *template.Template  `yaml:"-" json:"-"`
+	Use               string                 `yaml:"use" json:"use,omitempty"`
+	Description       string                 `yaml:"description" json:"description,omitempty"`
+	With              map[string]string      `yaml:"with" json:"with,omitempty"`
+	IntentPattern     string                 `yaml:"intentPattern" json:"intentPattern"`
+	Endpoint          string                 `yaml:"endpoint" json:"endpoint"`
+	Method            string                 `yaml:"method" json:"method"`
+	Params            map[string]interface{} `yaml:"params" json:"params,omitempty"`
+	Headers           map[string]string      `yaml:"headers" json:"headers,omitempty"`
+	HeaderSets        []string               `yaml:"headerSets" json:"headerSets,omitempty"`
+	Files             []FileRule             `yaml:"files" json:"files,omitempty"`
+	Input             []InputRule            `yaml:"input" json:"input,omitempty"`
+	Session           map[string]string      `yaml:"session" json:"session,omitempty"`
+	ParameterMappings []ParameterMapping     `yaml:"parameterMappings" json:"parameterMappings,omitempty"`
+	ParameterSets     []string               `yaml:"parameterSets" json:"parameterSets,omitempty"`
+	ResponseTransform ResponseTransform      `yaml:"responseTransform" json:"responseTransform,omitempty"`
+	TransformSet      string                 `yaml:"transformSet" json:"transformSet,omitempty"`
+	Timeout           TimeoutConfig          `yaml:"timeout" json:"timeout,omitempty"`
+	Cache             *MappingCacheConfig    `yaml:"cache" json:"cache,omitempty"`
+	Conditional       bool                   `yaml:"conditional" json:"conditional,omitempty"`
+	Charset           string                 `yaml:"charset" json:"charset,omitempty"`
+	RequestFormat     string                 `yaml:"requestFormat" json:"requestFormat,omitempty"`
+	ResponseFormat    string                 `yaml:"responseFormat" json:"responseFormat,omitempty"`
+	RequestTemplate   string                 `yaml:"requestTemplate" json:"requestTemplate,omitempty"`
+	Scopes            []string               `yaml:"scopes" json:"scopes,omitempty"`
+	Roles             []string               `yaml:"roles" json:"roles,omitempty"`
+	Pagination        *PaginationConfig      `yaml:"pagination" json:"pagination,omitempty"`
+	Priority          int                    `yaml:"priority" json:"priority,omitempty"`
+	TTL               string                 `yaml:"ttl" json:"ttl,omitempty"`
+	When              string                 `yaml:"when" json:"when,omitempty"`
+	Order             int                    `yaml:"order" json:"order,omitempty"`
+	Fallback          bool                   `yaml:"fallback" json:"fallback,omitempty"`
+	Steps             []StepConfig           `yaml:"steps" json:"steps,omitempty"`
+	FanOut            *FanOutConfig          `yaml:"fanOut" json:"fanOut,omitempty"`
+	Async             *AsyncConfig           `yaml:"async" json:"async,omitempty"`
+	CompiledPattern   *regexp.Regexp         `yaml:"-" json:"-"`
+	CompiledWhen      *expr.Expr             `yaml:"-" json:"-"`
+	CompiledTemplate  *template.Template     `yaml:"-" json:"-"`
+	CompiledRequest   *template.Template     `yaml:"-" json:"-"`
+}
+
+// FileRule places files of the task in the legacy request. Each file goes to
+// the first rule whose MimeType pattern (e.g. image/*; empty matches all)
+// matches it. A Target of multipart adds every matching file to the multipart
+// body of a REST call under Field; other targets are param paths that receive
+// the content of the first matching file, base64 encoded unless Encoding is
+// text, and NameTarget and MimeTypeTarget its name and media type. Files
+// given by uri are fetched, but only from AllowedHosts.
+type FileRule struct {
+	MimeType       string   `yaml:"mimeType" json:"mimeType,omitempty"`
+	Target         string   `yaml:"target" json:"target"`
+	Field          string   `yaml:"field" json:"field,omitempty"`
+	Encoding       string   `yaml:"encoding" json:"encoding,omitempty"`
+	NameTarget     string   `yaml:"nameTarget" json:"nameTarget,omitempty"`
+	MimeTypeTarget string   `yaml:"mimeTypeTarget" json:"mimeTypeTarget,omitempty"`
+	Required       bool     `yaml:"required" json:"required,omitempty"`
+	AllowedHosts   []string `yaml:"allowedHosts" json:"allowedHosts,omitempty"`
+}
+
+// InputRule returns a task as input-required with Prompt instead of
+// completing it. Without When the task asks before the legacy call when Param
+// is missing from the params; with When, an expression over the legacy
+// response such as result.matches > 1, it asks after the call. Prompt is a
+// template over the legacy response and .params. The next message of the
+// task is the answer: the first group of Pattern in its text, or the whole
+// text, converted to Type, is set at Param in the original params and the
+// mapping runs again. A task waits for its answer for TTL, 30m by default.
+type InputRule struct {
+	Param           string             `yaml:"param" json:"param"`
+	When            string             `yaml:"when" json:"when,omitempty"`
+	Prompt          string             `yaml:"prompt" json:"prompt"`
+	Pattern         string             `yaml:"pattern" json:"pattern,omitempty"`
+	Type            string             `yaml:"type" json:"type,omitempty"`
+	TTL             string             `yaml:"ttl" json:"ttl,omitempty"`
+	CompiledWhen    *expr.Expr         `yaml:"-" json:"-"`
+	CompiledPattern *regexp.Regexp     `yaml:"-" json:"-"`
+	CompiledPrompt  *template.Template `yaml:"-" json:"-"`
+}
+
+// StepConfig is one legacy call of a multi-step mapping. Endpoint and the
+// string values of Params are templates over .params, the params of the
+// task, and .steps, the results of the earlier steps by name; without Params
+// the step gets the params of the task. When is an expression over the same
+// fields that must hold for the step to run, and Timeout limits its call. A
+// failing step aborts the mapping unless ContinueOnError is set.
+type StepConfig struct {
+	Name            string                 `yaml:"name" json:"name"`
+	Endpoint        string                 `yaml:"endpoint" json:"endpoint"`
+	Method          string                 `yaml:"method" json:"method,omitempty"`
+	Params          map[string]interface{} `yaml:"params" json:"params,omitempty"`
+	When            string                 `yaml:"when" json:"when,omitempty"`
+	Timeout         string                 `yaml:"timeout" json:"timeout,omitempty"`
+	ContinueOnError bool                   `yaml:"continueOnError" json:"continueOnError,omitempty"`
+}
+
+// FanOutConfig calls its branches in parallel and combines their results,
+// one data part per branch. Branches are steps that can only reference
+// .params. Policy partial (the default) completes the task when any branch
+// succeeds and reports the failed ones; all fails it when any branch fails.
+type FanOutConfig struct {
+	Branches []StepConfig `yaml:"branches" json:"branches"`
+	Policy   string       `yaml:"policy" json:"policy,omitempty"`
+}
+
+// AsyncConfig polls the job that the legacy call of a mapping starts, for
+// legacy systems that answer with a job ID and a status URL. JobID is the path
+// of the job ID in the result of the call. Poll is the call that checks the
+// job, made every Interval (5s by default) until Done holds, Failed holds or
+// Timeout (10m by default) passes; its endpoint and params are templates over
+// .params, .job (the job ID) and .start (the result of the first call), and
+// Done and Failed are expressions over the same fields and .result, the
+// result of the poll. Result fetches the result of a finished job with the
+// same fields; without it the last poll result is the result of the task.
+// Progress is a template over the same fields for the working status update
+// streamed after each poll.
+type AsyncConfig struct {
+	JobID    string      `yaml:"jobId" json:"jobId"`
+	Poll     StepConfig  `yaml:"poll" json:"poll"`
+	Interval string      `yaml:"interval" json:"interval,omitempty"`
+	Timeout  string      `yaml:"timeout" json:"timeout,omitempty"`
+	Done     string      `yaml:"done" json:"done"`
+	Failed   string      `yaml:"failed" json:"failed,omitempty"`
+	Result   *StepConfig `yaml:"result" json:"result,omitempty"`
+	Progress string      `yaml:"progress" json:"progress,omitempty"`
 }
 
 // ParameterMapping represents how to extract parameters from A2A tasks
@@ -50,6 +877,7 @@ type ParameterMapping struct {
 	Pattern  string         `yaml:"pattern" json:"pattern"`
 	Target   string         `yaml:"target" json:"target"`
 	Default  string         `yaml:"default" json:"default,omitempty"`
+	Type     string         `yaml:"type" json:"type,omitempty"`
 	Compiled *regexp.Regexp `yaml:"-" json:"-"`
 }
 
@@ -59,22 +887,100 @@ type ResponseTransform struct {
 	Mappings        map[string]string  `yaml:"mappings" json:"mappings,omitempty"`
 	StatusPath      string             `yaml:"statusPath" json:"statusPath,omitempty"`
 	ErrorPath       string             `yaml:"errorPath" json:"errorPath,omitempty"`
+	Artifacts       []ArtifactConfig   `yaml:"artifacts" json:"artifacts,omitempty"`
+	Mask            []MaskRule         `yaml:"mask" json:"mask,omitempty"`
+	Summary         *SummaryConfig     `yaml:"summary" json:"summary,omitempty"`
 	CompiledTemplate *template.Template `yaml:"-" json:"-"`
 }
 
+// SummaryConfig adds a text part summarizing a tabular result next to its
+// data. Source is the path of the rows, a list of objects; by default the
+// first such list in the result. Summarizer template (the default) reports
+// the row count, the most frequent values of KeyColumns and the total,
+// minimum, maximum and average of the numeric Aggregates columns, rendered by
+// Template when set. Summarizer llm sends these figures and the first MaxRows
+// rows (20 by default) to an OpenAI-compatible chat completions Endpoint,
+// instructed by Prompt, and falls back to the template when the call fails.
+type SummaryConfig struct {
+	Source           string             `yaml:"source" json:"source,omitempty"`
+	Summarizer       string             `yaml:"summarizer" json:"summarizer,omitempty"`
+	KeyColumns       []string           `yaml:"keyColumns" json:"keyColumns,omitempty"`
+	Aggregates       []string           `yaml:"aggregates" json:"aggregates,omitempty"`
+	Template         string             `yaml:"template" json:"template,omitempty"`
+	Endpoint         string             `yaml:"endpoint" json:"endpoint,omitempty"`
+	Model            string             `yaml:"model" json:"model,omitempty"`
+	APIKey           string             `yaml:"apiKey" json:"apiKey,omitempty"`
+	Prompt           string             `yaml:"prompt" json:"prompt,omitempty"`
+	Timeout          string             `yaml:"timeout" json:"timeout,omitempty"`
+	MaxRows          int                `yaml:"maxRows" json:"maxRows,omitempty"`
+	CompiledTemplate *template.Template `yaml:"-" json:"-"`
+}
+
+// MaskRule hides personal data in the legacy response before it becomes the
+// parts and artifacts of a task. It selects the values whose key path ends
+// with Field, where * matches any key, and the parts of strings that match
+// Pattern; with both, only the matches within the field. Action redact (the
+// default) replaces them with a mask, partial keeps their last Keep
+// characters (4 by default), hash replaces them with a short hash so equal
+// values stay comparable, and drop removes the field.
+type MaskRule struct {
+	Field    string         `yaml:"field" json:"field,omitempty"`
+	Pattern  string         `yaml:"pattern" json:"pattern,omitempty"`
+	Action   string         `yaml:"action" json:"action,omitempty"`
+	Keep     int            `yaml:"keep" json:"keep,omitempty"`
+	Compiled *regexp.Regexp `yaml:"-" json:"-"`
+}
+
+// ArtifactConfig turns a field of the legacy response into an artifact of the
+// task. Type data (the default) puts the value in a data part, text in a text
+// part and file in a file part, whose content is base64 in the response
+// unless Encoding is text (raw content) or uri (a link to the file). Name,
+// FileName and MimeType are templates over the legacy response.
+type ArtifactConfig struct {
+	Name        string `yaml:"name" json:"name,omitempty"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Source      string `yaml:"source" json:"source"`
+	Type        string `yaml:"type" json:"type,omitempty"`
+	MimeType    string `yaml:"mimeType" json:"mimeType,omitempty"`
+	FileName    string `yaml:"fileName" json:"fileName,omitempty"`
+	Encoding    string `yaml:"encoding" json:"encoding,omitempty"`
+}
+
 // TransformConfig defines global transformation rules
 type TransformConfig struct {
 	A2AToLegacy  []TransformRule `yaml:"a2aToLegacy" json:"a2aToLegacy,omitempty"`
 	LegacyToA2A  []TransformRule `yaml:"legacyToA2a" json:"legacyToA2a,omitempty"`
 }
 
-// TransformRule defines a single transformation rule
+// FieldDefinition maps a field of the legacy system to its canonical name in
+// tasks, for every mapping. Responses rename Legacy keys to Field wherever
+// they appear in the result, and parameters whose target name is Field are
+// sent as Legacy. Values are converted on the way: Type (string, int, number
+// or bool) parses legacy text, Values maps legacy codes to canonical values
+// and back, LegacyUnit and Unit convert numbers between the units of the
+// convert expression function, and Scale multiplies legacy numbers (for
+// example 0.01 for amounts in cents).
+type FieldDefinition struct {
+	Legacy     string            `yaml:"legacy" json:"legacy"`
+	Field      string            `yaml:"field" json:"field"`
+	Type       string            `yaml:"type" json:"type,omitempty"`
+	Values     map[string]string `yaml:"values" json:"values,omitempty"`
+	LegacyUnit string            `yaml:"legacyUnit" json:"legacyUnit,omitempty"`
+	Unit       string            `yaml:"unit" json:"unit,omitempty"`
+	Scale      float64           `yaml:"scale" json:"scale,omitempty"`
+}
+
+// TransformRule defines a single transformation rule. Expr computes the value
+// from other fields instead of copying Source; within it, value is the Source
+// value.
 type TransformRule struct {
-	Source   string         `yaml:"source" json:"source"`
-	Target   string         `yaml:"target" json:"target"`
-	Regex    string         `yaml:"regex" json:"regex,omitempty"`
-	Template string         `yaml:"template" json:"template,omitempty"`
-	Compiled *regexp.Regexp `yaml:"-" json:"-"`
+	Source       string         `yaml:"source" json:"source"`
+	Target       string         `yaml:"target" json:"target"`
+	Regex        string         `yaml:"regex" json:"regex,omitempty"`
+	Template     string         `yaml:"template" json:"template,omitempty"`
+	Expr         string         `yaml:"expr" json:"expr,omitempty"`
+	Compiled     *regexp.Regexp `yaml:"-" json:"-"`
+	CompiledExpr *expr.Expr     `yaml:"-" json:"-"`
 }
 
 // Compile compiles all regular expressions and templates in the configuration
@@ -87,6 +993,14 @@ func (c *ConnectorConfig) Compile() error {
 		}
 		c.Mappings[i].CompiledPattern = pattern
 
+		if c.Mappings[i].When != "" {
+			when, err := expr.Parse(c.Mappings[i].When)
+			if err != nil {
+				return err
+			}
+			c.Mappings[i].CompiledWhen = when
+		}
+
 		for j := range c.Mappings[i].ParameterMappings {
 			pattern, err := regexp.Compile(c.Mappings[i].ParameterMappings[j].Pattern)
 			if err != nil {
@@ -95,13 +1009,103 @@ func (c *ConnectorConfig) Compile() error {
 			c.Mappings[i].ParameterMappings[j].Compiled = pattern
 		}
 
+		// Steps are rendered when they run; parsing them here reports errors on load
+		steps := c.Mappings[i].Steps
+		if c.Mappings[i].FanOut != nil {
+			steps = append(steps[:len(steps):len(steps)], c.Mappings[i].FanOut.Branches...)
+		}
+		if async := c.Mappings[i].Async; async != nil {
+			steps = append(steps[:len(steps):len(steps)], async.Poll)
+			if async.Result != nil {
+				steps = append(steps, *async.Result)
+			}
+			for _, e := range []string{async.Done, async.Failed} {
+				if e == "" {
+					continue
+				}
+				if _, err := expr.Parse(e); err != nil {
+					return err
+				}
+			}
+			if _, err := template.New("progress").Funcs(tmplfunc.FuncMap()).Parse(async.Progress); err != nil {
+				return err
+			}
+		}
+		for _, step := range steps {
+			if step.When != "" {
+				if _, err := expr.Parse(step.When); err != nil {
+					return err
+				}
+			}
+			if _, err := template.New("step").Funcs(tmplfunc.FuncMap()).Parse(step.Endpoint); err != nil {
+				return err
+			}
+		}
+
+		for j := range c.Mappings[i].Input {
+			rule := &c.Mappings[i].Input[j]
+			if rule.When != "" {
+				when, err := expr.Parse(rule.When)
+				if err != nil {
+					return err
+				}
+				rule.CompiledWhen = when
+			}
+			if rule.Pattern != "" {
+				pattern, err := regexp.Compile(rule.Pattern)
+				if err != nil {
+					return err
+				}
+				rule.CompiledPattern = pattern
+			}
+			prompt, err := template.New("prompt").Funcs(tmplfunc.FuncMap()).Parse(rule.Prompt)
+			if err != nil {
+				return err
+			}
+			rule.CompiledPrompt = prompt
+		}
+
+		// Headers are rendered per task; parsing them here reports errors on load
+		for _, value := range c.Mappings[i].Headers {
+			if _, err := template.New("header").Funcs(tmplfunc.FuncMap()).Parse(value); err != nil {
+				return err
+			}
+		}
+
+		if c.Mappings[i].RequestTemplate != "" {
+			tmpl, err := template.New("request").Funcs(tmplfunc.FuncMap()).Parse(c.Mappings[i].RequestTemplate)
+			if err != nil {
+				return err
+			}
+			c.Mappings[i].CompiledRequest = tmpl
+		}
+
 		if c.Mappings[i].ResponseTransform.Template != "" {
-			tmpl, err := template.New("response").Parse(c.Mappings[i].ResponseTransform.Template)
+			tmpl, err := template.New("response").Funcs(tmplfunc.FuncMap()).Parse(c.Mappings[i].ResponseTransform.Template)
 			if err != nil {
 				return err
 			}
 			c.Mappings[i].ResponseTransform.CompiledTemplate = tmpl
 		}
+
+		if summary := c.Mappings[i].ResponseTransform.Summary; summary != nil && summary.Template != "" {
+			tmpl, err := template.New("summary").Funcs(tmplfunc.FuncMap()).Parse(summary.Template)
+			if err != nil {
+				return err
+			}
+			summary.CompiledTemplate = tmpl
+		}
+
+		for j := range c.Mappings[i].ResponseTransform.Mask {
+			rule := &c.Mappings[i].ResponseTransform.Mask[j]
+			if rule.Pattern != "" {
+				pattern, err := regexp.Compile(rule.Pattern)
+				if err != nil {
+					return err
+				}
+				rule.Compiled = pattern
+			}
+		}
 	}
 
 	// Compile transform rules
@@ -113,6 +1117,39 @@ func (c *ConnectorConfig) Compile() error {
 			}
 			c.Transforms.A2AToLegacy[i].Compiled = pattern
 		}
+		if c.Transforms.A2AToLegacy[i].Expr != "" {
+			compiled, err := expr.Parse(c.Transforms.A2AToLegacy[i].Expr)
+			if err != nil {
+				return err
+			}
+			c.Transforms.A2AToLegacy[i].CompiledExpr = compiled
+		}
+	}
+
+	if c.Anonymize != nil {
+		for i := range c.Anonymize.Rules {
+			if c.Anonymize.Rules[i].Pattern != "" {
+				pattern, err := regexp.Compile(c.Anonymize.Rules[i].Pattern)
+				if err != nil {
+					return err
+				}
+				c.Anonymize.Rules[i].Compiled = pattern
+			}
+		}
+	}
+
+	if c.Egress != nil {
+		for i := range c.Egress.Classes {
+			class := &c.Egress.Classes[i]
+			class.Compiled = nil
+			for _, p := range class.Patterns {
+				pattern, err := regexp.Compile(p)
+				if err != nil {
+					return err
+				}
+				class.Compiled = append(class.Compiled, pattern)
+			}
+		}
 	}
 
 	for i := range c.Transforms.LegacyToA2A {
@@ -123,30 +1160,41 @@ func (c *ConnectorConfig) Compile() error {
 			}
 			c.Transforms.LegacyToA2A[i].Compiled = pattern
 		}
+		if c.Transforms.LegacyToA2A[i].Expr != "" {
+			compiled, err := expr.Parse(c.Transforms.LegacyToA2A[i].Expr)
+			if err != nil {
+				return err
+			}
+			c.Transforms.LegacyToA2A[i].CompiledExpr = compiled
+		}
 	}
 
-	return nil
-}
-
-// ResolveVariables replaces variable placeholders in config strings
-func (c *ConnectorConfig) ResolveVariables() {
-	// Resolve variables in various fields
-	c.Adapter.BaseURL = resolveVariablesInString(c.Adapter.BaseURL, c.Variables)
-	c.Adapter.Auth.Username = resolveVariablesInString(c.Adapter.Auth.Username, c.Variables)
-	c.Adapter.Auth.Password = resolveVariablesInString(c.Adapter.Auth.Password, c.Variables)
-	c.Adapter.Auth.Token = resolveVariablesInString(c.Adapter.Auth.Token, c.Variables)
-
-	// Resolve variables in headers
-	for key, value := range c.Adapter.Headers {
-		c.Adapter.Headers[key] = resolveVariablesInString(value, c.Variables)
+	for i := range c.Webhooks {
+		webhook := &c.Webhooks[i]
+		if webhook.Text != "" {
+			tmpl, err := template.New("webhook").Funcs(tmplfunc.FuncMap()).Parse(webhook.Text)
+			if err != nil {
+				return err
+			}
+			webhook.CompiledTemplate = tmpl
+		}
+		for j := range webhook.LegacyToA2A {
+			if webhook.LegacyToA2A[j].Regex != "" {
+				pattern, err := regexp.Compile(webhook.LegacyToA2A[j].Regex)
+				if err != nil {
+					return err
+				}
+				webhook.LegacyToA2A[j].Compiled = pattern
+			}
+			if webhook.LegacyToA2A[j].Expr != "" {
+				compiled, err := expr.Parse(webhook.LegacyToA2A[j].Expr)
+				if err != nil {
+					return err
+				}
+				webhook.LegacyToA2A[j].CompiledExpr = compiled
+			}
+		}
 	}
-}
 
-// resolveVariablesInString replaces ${VAR} with the actual variable value
-func resolveVariablesInString(s string, vars map[string]string) string {
-	result := s
-	for k, v := range vars {
-		result = strings.ReplaceAll(result, "${"+k+"}", v)
-	}
-	return result
-}
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sources of variable values
+const (
+	VariableSourceConfig      = "variables"
+	VariableSourceEnvironment = "environment"
+	VariableSourceDefault     = "default"
+)
+
+// VariableReference is a ${NAME} reference in a config value. Source is
+// where the value came from: the variables section, the environment or the
+// reference's default, or empty when the variable is not defined and the
+// reference was left as is.
+type VariableReference struct {
+	Name   string
+	Path   string
+	Line   int
+	Source string
+}
+
+// VariableReferences returns the ${NAME} references resolved when the config
+// was loaded, in config order
+func (c *ConnectorConfig) VariableReferences() []VariableReference {
+	return c.variableRefs
+}
+
+// UnresolvedVariables reports every reference to an undefined variable with
+// its path and line, or returns nil
+func (c *ConnectorConfig) UnresolvedVariables() error {
+	var problems []FieldError
+	for _, ref := range c.variableRefs {
+		if ref.Source == "" {
+			problems = append(problems, FieldError{Path: ref.Path, Line: ref.Line, Message: fmt.Sprintf("variable ${%s} is not defined", ref.Name)})
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &SchemaError{Problems: problems}
+}
+
+// ResolveVariables replaces ${NAME} and ${NAME:-default} references in
+// config strings. Variables of the variables section may reference others;
+// a variable that references itself through others is an error.
+func (c *ConnectorConfig) ResolveVariables() error {
+	c.variableRefs = nil
+	r := &variableResolver{c: c, expanded: make(map[string]bool)}
+
+	// Expand the variables section first, so references between variables
+	// are reported once
+	variables := make([]string, 0, len(c.Variables))
+	for name := range c.Variables {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+	for _, name := range variables {
+		r.value(name)
+	}
+
+	// Resolve variables in various fields
+	r.resolve("adapter.baseUrl", &c.Adapter.BaseURL)
+	if c.Adapter.Socket != nil {
+		r.resolve("adapter.socket.address", &c.Adapter.Socket.Address)
+	}
+	if telnet := c.Adapter.Telnet; telnet != nil {
+		r.resolve("adapter.telnet.address", &telnet.Address)
+		for i := range telnet.Login {
+			r.resolve(fmt.Sprintf("adapter.telnet.login[%d].send", i), &telnet.Login[i].Send)
+		}
+	}
+	r.resolve("adapter.dsn", &c.Adapter.DSN)
+	r.resolveAuth("adapter.auth", &c.Adapter.Auth)
+	r.resolveMap("adapter.headers", c.Adapter.Headers)
+	r.resolve("integrity.secret", &c.Integrity.Secret)
+	for i := range c.Security.APIKeys {
+		r.resolve(fmt.Sprintf("security.apiKeys[%d].key", i), &c.Security.APIKeys[i].Key)
+	}
+	for i := range c.Security.HMACKeys {
+		r.resolve(fmt.Sprintf("security.hmacKeys[%d].secret", i), &c.Security.HMACKeys[i].Secret)
+	}
+	for i := range c.Webhooks {
+		path := fmt.Sprintf("webhooks[%d]", i)
+		r.resolve(path+".secret", &c.Webhooks[i].Secret)
+		if stream := c.Webhooks[i].Salesforce; stream != nil {
+			r.resolve(path+".salesforce.instanceUrl", &stream.InstanceURL)
+			r.resolveAuth(path+".salesforce.auth", &stream.Auth)
+		}
+	}
+	names, listeners := c.Server.AllListeners()
+	for i, listener := range listeners {
+		path := "server." + names[i]
+		r.resolveAuth(path+".auth", &listener.Auth)
+		if sso := listener.SSO; sso != nil {
+			r.resolve(path+".sso.clientId", &sso.ClientID)
+			r.resolve(path+".sso.clientSecret", &sso.ClientSecret)
+			r.resolve(path+".sso.sessionSecret", &sso.SessionSecret)
+			if saml := sso.SAML; saml != nil {
+				r.resolve(path+".sso.saml.idpMetadataUrl", &saml.IDPMetadataURL)
+				r.resolve(path+".sso.saml.certificate", &saml.Certificate)
+				r.resolve(path+".sso.saml.privateKey", &saml.PrivateKey)
+			}
+		}
+	}
+
+	if intent := c.Intent; intent != nil {
+		r.resolve("intent.endpoint", &intent.Endpoint)
+		r.resolve("intent.apiKey", &intent.APIKey)
+	}
+	if c.Anonymize != nil {
+		r.resolve("anonymize.seed", &c.Anonymize.Seed)
+	}
+	if c.DeadLetter != nil {
+		r.resolve("deadLetter.url", &c.DeadLetter.URL)
+		r.resolveMap("deadLetter.headers", c.DeadLetter.Headers)
+	}
+	for i := range c.Plugins {
+		plugin := &c.Plugins[i]
+		path := fmt.Sprintf("plugins[%d]", i)
+		r.resolve(path+".command", &plugin.Command)
+		for j := range plugin.Args {
+			r.resolve(fmt.Sprintf("%s.args[%d]", path, j), &plugin.Args[j])
+		}
+		r.resolveMap(path+".env", plugin.Env)
+	}
+
+	// Resolve variables in the requests and responses of mappings
+	for i := range c.Mappings {
+		mapping := &c.Mappings[i]
+		path := fmt.Sprintf("mappings[%d]", i)
+		r.resolve(path+".endpoint", &mapping.Endpoint)
+		r.resolveMap(path+".headers", mapping.Headers)
+		params := make([]string, 0, len(mapping.Params))
+		for key := range mapping.Params {
+			params = append(params, key)
+		}
+		sort.Strings(params)
+		for _, key := range params {
+			if value, ok := mapping.Params[key].(string); ok {
+				r.resolve(path+".params."+key, &value)
+				mapping.Params[key] = value
+			}
+		}
+		r.resolve(path+".requestTemplate", &mapping.RequestTemplate)
+		r.resolve(path+".responseTransform.template", &mapping.ResponseTransform.Template)
+		if summary := mapping.ResponseTransform.Summary; summary != nil {
+			r.resolve(path+".responseTransform.summary.template", &summary.Template)
+			r.resolve(path+".responseTransform.summary.endpoint", &summary.Endpoint)
+			r.resolve(path+".responseTransform.summary.apiKey", &summary.APIKey)
+		}
+	}
+	return r.err
+}
+
+// variableResolver expands variable references, each variable of the
+// variables section once
+type variableResolver struct {
+	c        *ConnectorConfig
+	expanded map[string]bool
+	stack    []string
+	err      error
+}
+
+func (r *variableResolver) resolveAuth(path string, auth *AuthConfig) {
+	r.resolve(path+".username", &auth.Username)
+	r.resolve(path+".password", &auth.Password)
+	r.resolve(path+".token", &auth.Token)
+	if oauth := auth.OAuth2; oauth != nil {
+		r.resolve(path+".oauth2.clientId", &oauth.ClientID)
+		r.resolve(path+".oauth2.clientSecret", &oauth.ClientSecret)
+		r.resolve(path+".oauth2.refreshToken", &oauth.RefreshToken)
+	}
+}
+
+func (r *variableResolver) resolveMap(path string, values map[string]string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := values[key]
+		r.resolve(path+"."+key, &value)
+		values[key] = value
+	}
+}
+
+func (r *variableResolver) resolve(path string, value *string) {
+	*value = r.expand(path, *value)
+}
+
+// value returns a variable, with the references in its value expanded when
+// it comes from the variables section; environment values are taken as is
+func (r *variableResolver) value(name string) (string, bool) {
+	value, ok := r.c.Variables[name]
+	if !ok || r.expanded[name] || r.c.variableSources[name] != "" {
+		return value, ok
+	}
+	for i, n := range r.stack {
+		if n == name {
+			if r.err == nil {
+				cycle := append(append([]string(nil), r.stack[i:]...), name)
+				r.err = fmt.Errorf("variable cycle: %s", strings.Join(cycle, " -> "))
+			}
+			return value, ok
+		}
+	}
+	r.stack = append(r.stack, name)
+	value = r.expand("variables."+name, value)
+	r.stack = r.stack[:len(r.stack)-1]
+	r.c.Variables[name] = value
+	r.expanded[name] = true
+	return value, true
+}
+
+// expand replaces the references in s, the value at path, and records them.
+// A variable that is undefined or empty takes its default, which may hold
+// references itself; an undefined variable without one is kept as written.
+// $${ escapes a literal ${.
+func (r *variableResolver) expand(path, s string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "$")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		if strings.HasPrefix(s, "$${") {
+			b.WriteString("${")
+			s = s[3:]
+			continue
+		}
+		end := referenceEnd(s)
+		if end < 0 {
+			b.WriteString("$")
+			s = s[1:]
+			continue
+		}
+		ref, body := s[:end+1], s[2:end]
+		s = s[end+1:]
+
+		name, fallback, hasDefault := body, "", false
+		if j := strings.Index(body, ":-"); j >= 0 {
+			name, fallback, hasDefault = body[:j], body[j+2:], true
+		}
+		reference := VariableReference{Name: name, Path: path, Line: r.c.lineOf(path)}
+		value, ok := r.value(name)
+		switch {
+		case ok && (value != "" || !hasDefault):
+			reference.Source = VariableSourceConfig
+			if source := r.c.variableSources[name]; source != "" {
+				reference.Source = source
+			}
+			r.c.variableRefs = append(r.c.variableRefs, reference)
+			b.WriteString(value)
+		case hasDefault:
+			reference.Source = VariableSourceDefault
+			r.c.variableRefs = append(r.c.variableRefs, reference)
+			b.WriteString(r.expand(path, fallback))
+		default:
+			r.c.variableRefs = append(r.c.variableRefs, reference)
+			b.WriteString(ref)
+		}
+	}
+}
+
+// referenceEnd returns the index of the brace closing the reference s
+// starts with, counting nested references, or -1
+func referenceEnd(s string) int {
+	if !strings.HasPrefix(s, "${") {
+		return -1
+	}
+	depth := 0
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '{' && s[i-1] == '$':
+			depth++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// lineOf returns the line of path in the loaded file, or that of its closest
+// parent there
+func (c *ConnectorConfig) lineOf(path string) int {
+	for p := path; p != ""; p = parentPath(p) {
+		if line, ok := c.lines[p]; ok {
+			return line
+		}
+	}
+	return 0
+}
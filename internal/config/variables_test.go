@@ -0,0 +1,119 @@
+package config_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+const variablesConfig = `
+adapter:
+  type: rest
+  baseUrl: "https://${ERP_HOST}/api"
+  auth:
+    type: basic
+    username: "${ERP_USER}"
+    password: "${A2A_TEST_PASSWORD}"
+variables:
+  ERP_USER: svc
+mappings:
+  - intentPattern: x
+    endpoint: /x
+    method: GET
+`
+
+func TestVariableReferences(t *testing.T) {
+	t.Setenv("A2A_TEST_PASSWORD", "secret")
+	dir := writeFiles(t, map[string]string{"config.yaml": variablesConfig})
+
+	cfg, err := config.LoadOffline(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadOffline failed: %v", err)
+	}
+	if cfg.Adapter.BaseURL != "https://${ERP_HOST}/api" || cfg.Adapter.Auth.Username != "svc" || cfg.Adapter.Auth.Password != "secret" {
+		t.Errorf("Unexpected resolution: %q %q %q", cfg.Adapter.BaseURL, cfg.Adapter.Auth.Username, cfg.Adapter.Auth.Password)
+	}
+
+	want := []config.VariableReference{
+		{Name: "ERP_HOST", Path: "adapter.baseUrl", Line: 4},
+		{Name: "ERP_USER", Path: "adapter.auth.username", Line: 7, Source: config.VariableSourceConfig},
+		{Name: "A2A_TEST_PASSWORD", Path: "adapter.auth.password", Line: 8, Source: config.VariableSourceEnvironment},
+	}
+	refs := cfg.VariableReferences()
+	if len(refs) != len(want) {
+		t.Fatalf("Expected %d references, got %+v", len(want), refs)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], refs[i])
+		}
+	}
+}
+
+func TestStrictVariables(t *testing.T) {
+	t.Setenv("A2A_TEST_PASSWORD", "secret")
+	dir := writeFiles(t, map[string]string{"config.yaml": variablesConfig + "strictVariables: true\n"})
+
+	_, err := config.LoadOffline(filepath.Join(dir, "config.yaml"))
+	var schemaErr *config.SchemaError
+	if !errors.As(err, &schemaErr) || len(schemaErr.Problems) != 1 ||
+		!strings.Contains(err.Error(), "adapter.baseUrl (line 4): variable ${ERP_HOST} is not defined") {
+		t.Fatalf("Expected the undefined variable to fail the load, got %v", err)
+	}
+
+	t.Setenv("A2A__VARIABLES__ERP_HOST", "erp.internal")
+	if _, err := config.LoadOffline(filepath.Join(dir, "config.yaml")); err != nil {
+		t.Errorf("Expected every variable to resolve, got %v", err)
+	}
+}
+
+func TestVariableDefaultsAndNesting(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"config.yaml": `
+strictVariables: true
+variables:
+  HOST: "erp.${A2A_TEST_DOMAIN:-internal}"
+  URL: "https://${HOST}:${PORT:-8443}"
+  TENANT: ""
+adapter:
+  type: rest
+  baseUrl: "${URL}/api"
+mappings:
+  - intentPattern: x
+    endpoint: "${PREFIX:-/v1}/orders"
+    method: GET
+    headers:
+      X-Tenant: "${TENANT:-${A2A_TEST_TENANT:-acme}}"
+    responseTransform:
+      template: "Order {{.result.id}} at ${HOST}, not $${HOST}"
+`})
+
+	cfg, err := config.LoadOffline(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadOffline failed: %v", err)
+	}
+	if cfg.Adapter.BaseURL != "https://erp.internal:8443/api" {
+		t.Errorf("Expected nested variables and defaults, got %q", cfg.Adapter.BaseURL)
+	}
+	mapping := cfg.Mappings[0]
+	if mapping.Endpoint != "/v1/orders" || mapping.Headers["X-Tenant"] != "acme" {
+		t.Errorf("Expected defaults in the mapping, got %q and %v", mapping.Endpoint, mapping.Headers)
+	}
+	if mapping.ResponseTransform.Template != "Order {{.result.id}} at erp.internal, not ${HOST}" {
+		t.Errorf("Expected the variable and the escaped reference in the template, got %q", mapping.ResponseTransform.Template)
+	}
+
+	dir = writeFiles(t, map[string]string{"config.yaml": `
+variables:
+  A: "${B}"
+  B: "x${A}"
+adapter:
+  type: rest
+  baseUrl: "${A}"
+`})
+	if _, err := config.LoadOffline(filepath.Join(dir, "config.yaml")); err == nil || !strings.Contains(err.Error(), "variable cycle: A -> B -> A") {
+		t.Errorf("Expected a variable cycle, got %v", err)
+	}
+}
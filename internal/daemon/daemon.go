@@ -0,0 +1,86 @@
+// Package daemon reports the connector's state to the service manager
+// supervising it: systemd through sd_notify, or the Windows service control
+// manager
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// ServiceName is the name the connector runs under as a Windows service
+const ServiceName = "a2a-connector"
+
+// Supervisor tells the service manager when the connector is ready and when it
+// stops, and passes on stop requests of the Windows service control manager.
+// When the connector runs in a terminal or a container its methods do nothing.
+type Supervisor struct {
+	service *service
+}
+
+// Start connects to the Windows service control manager when the connector
+// runs as a service; systemd needs no setup
+func Start() (*Supervisor, error) {
+	svc, err := startService(ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	return &Supervisor{service: svc}, nil
+}
+
+// Ready reports that the connector is listening and serving tasks, with
+// status as the state shown by systemctl status
+func (s *Supervisor) Ready(status string) {
+	if _, err := Notify("READY=1", "STATUS="+status); err != nil {
+		slog.Warn("failed to report readiness to systemd", logging.KeyError, err)
+	}
+	s.service.running()
+}
+
+// Stopping reports that the connector is draining and about to exit
+func (s *Supervisor) Stopping() {
+	if _, err := Notify("STOPPING=1"); err != nil {
+		slog.Warn("failed to report shutdown to systemd", logging.KeyError, err)
+	}
+	s.service.stopPending()
+}
+
+// Stopped reports that the connector has stopped; it is the last call
+func (s *Supervisor) Stopped() {
+	s.service.stopped()
+}
+
+// StopRequested is closed when the Windows service control manager asks the
+// connector to stop, e.g. on system shutdown; it is never closed otherwise
+func (s *Supervisor) StopRequested() <-chan struct{} {
+	return s.service.stopRequested()
+}
+
+// RunWatchdog sends keepalives to the systemd watchdog until ctx is done,
+// so systemd restarts the connector when it hangs
+func (s *Supervisor) RunWatchdog(ctx context.Context) {
+	interval, err := WatchdogInterval()
+	if err != nil {
+		slog.Warn("systemd watchdog disabled", logging.KeyError, err)
+		return
+	}
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := Notify("WATCHDOG=1"); err != nil {
+				slog.Warn("systemd watchdog keepalive failed", logging.KeyError, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// notifySocketEnv names the datagram socket systemd listens on for the state
+// of Type=notify services
+const notifySocketEnv = "NOTIFY_SOCKET"
+
+// Notify sends state lines such as READY=1 to systemd; it reports false when
+// the connector does not run under systemd with notifications enabled
+func Notify(state ...string) (bool, error) {
+	path := os.Getenv(notifySocketEnv)
+	if path == "" {
+		return false, nil
+	}
+	// Abstract socket names start with @, which stands for a leading NUL
+	if strings.HasPrefix(path, "@") {
+		path = "\x00" + path[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to systemd notify socket: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(strings.Join(state, "\n"))); err != nil {
+		return false, fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns how often systemd expects a WATCHDOG=1 keepalive,
+// half the WatchdogSec of the unit, or zero when the watchdog is disabled or
+// meant for another process
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC %q", usec)
+	}
+	return time.Duration(n) * time.Microsecond / 2, nil
+}
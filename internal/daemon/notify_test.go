@@ -0,0 +1,60 @@
+package daemon_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/daemon"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := daemon.Notify("READY=1"); sent || err != nil {
+		t.Fatalf("Expected no notification outside systemd, got %v, %v", sent, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("Unix datagram sockets not available: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	if sent, err := daemon.Notify("READY=1", "STATUS=listening on :8082"); !sent || err != nil {
+		t.Fatalf("Expected the notification to be sent, got %v, %v", sent, err)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != "READY=1\nSTATUS=listening on :8082" {
+		t.Errorf("Unexpected notification %q", got)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "20000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	if interval, err := daemon.WatchdogInterval(); err != nil || interval != 10*time.Second {
+		t.Errorf("Expected half the watchdog timeout, got %v, %v", interval, err)
+	}
+
+	// The watchdog of another process is not ours to ping
+	t.Setenv("WATCHDOG_PID", "1")
+	if interval, _ := daemon.WatchdogInterval(); interval != 0 {
+		t.Errorf("Expected no keepalives for another process, got %v", interval)
+	}
+
+	t.Setenv("WATCHDOG_PID", "")
+	t.Setenv("WATCHDOG_USEC", "soon")
+	if _, err := daemon.WatchdogInterval(); err == nil {
+		t.Error("Expected an invalid timeout to be rejected")
+	}
+}
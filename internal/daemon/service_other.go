@@ -0,0 +1,18 @@
+//go:build !windows
+
+package daemon
+
+// service is the Windows service state; other platforms have none
+type service struct{}
+
+func startService(name string) (*service, error) {
+	return nil, nil
+}
+
+func (s *service) running()     {}
+func (s *service) stopPending() {}
+func (s *service) stopped()     {}
+
+func (s *service) stopRequested() <-chan struct{} {
+	return nil
+}
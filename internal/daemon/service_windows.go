@@ -0,0 +1,136 @@
+//go:build windows
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// pendingWaitHint is how long the service control manager waits for the next
+// status while the connector starts or stops, in milliseconds
+const pendingWaitHint = 30000
+
+// service is the connector running as a Windows service. svc.Run calls
+// Execute on a thread of its own, which reports the states the connector
+// goes through and turns stop and shutdown controls into a stop request.
+type service struct {
+	started  chan struct{}
+	states   chan svc.State
+	finished chan struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// startService runs the service dispatcher when the service control manager
+// started the connector, and returns nil otherwise
+func startService(name string) (*service, error) {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect a Windows service: %w", err)
+	}
+	if !isService {
+		// Started from a console rather than by the service control manager
+		return nil, nil
+	}
+	s := &service{
+		started:  make(chan struct{}),
+		states:   make(chan svc.State),
+		finished: make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+
+	// The dispatcher blocks its thread until the service stops
+	dispatched := make(chan error, 1)
+	go func() {
+		err := svc.Run(name, s)
+		close(s.finished)
+		dispatched <- err
+	}()
+
+	select {
+	case <-s.started:
+		return s, nil
+	case err := <-dispatched:
+		if err == nil {
+			err = errors.New("dispatcher returned before the service started")
+		}
+		return nil, fmt.Errorf("failed to run as a Windows service: %w", err)
+	}
+}
+
+// Execute reports the states of the connector until it stopped
+func (s *service) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- status(svc.StartPending)
+	close(s.started)
+	for {
+		select {
+		case state := <-s.states:
+			if state == svc.Stopped {
+				// svc.Run reports the stopped state once Execute returns
+				return false, 0
+			}
+			changes <- status(state)
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Stop, svc.Shutdown:
+				changes <- status(svc.StopPending)
+				s.stopOnce.Do(func() { close(s.stop) })
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			}
+		}
+	}
+}
+
+// status is the status of a state; stop and shutdown controls are accepted
+// while the connector runs
+func status(state svc.State) svc.Status {
+	switch state {
+	case svc.Running:
+		return svc.Status{State: state, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	case svc.StartPending, svc.StopPending:
+		return svc.Status{State: state, CheckPoint: 1, WaitHint: pendingWaitHint}
+	}
+	return svc.Status{State: state}
+}
+
+// setState reports a state, unless the service already stopped
+func (s *service) setState(state svc.State) {
+	select {
+	case s.states <- state:
+	case <-s.finished:
+	}
+}
+
+func (s *service) running() {
+	if s != nil {
+		s.setState(svc.Running)
+	}
+}
+
+func (s *service) stopPending() {
+	if s != nil {
+		s.setState(svc.StopPending)
+	}
+}
+
+// stopped reports the stopped state and waits until the service control
+// manager has it
+func (s *service) stopped() {
+	if s != nil {
+		s.setState(svc.Stopped)
+		<-s.finished
+	}
+}
+
+func (s *service) stopRequested() <-chan struct{} {
+	if s == nil {
+		return nil
+	}
+	return s.stop
+}
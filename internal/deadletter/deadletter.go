@@ -0,0 +1,293 @@
+// Package deadletter keeps the tasks that failed in a transform or the legacy
+// system, so they can be inspected and replayed once the cause is fixed.
+package deadletter
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// Stages at which a task can fail
+const (
+	StageRequestTransform  = "request-transform"
+	StageBackend           = "backend"
+	StageResponseTransform = "response-transform"
+)
+
+// DefaultMaxEntries is the number of entries kept when the config sets none
+const DefaultMaxEntries = 1000
+
+// DefaultTimeout limits posting an entry to the http backend
+const DefaultTimeout = 10 * time.Second
+
+// ErrNotFound is returned for entries that are not in the queue
+var ErrNotFound = errors.New("dead-letter entry not found")
+
+// Entry is a failed task with what the connector made of it
+type Entry struct {
+	ID           string          `json:"id"`
+	Timestamp    time.Time       `json:"timestamp"`
+	TaskID       string          `json:"taskId,omitempty"`
+	MappingID    string          `json:"mappingId,omitempty"`
+	Stage        string          `json:"stage"`
+	Task         json.RawMessage `json:"task"`
+	Request      json.RawMessage `json:"request,omitempty"`
+	Error        string          `json:"error"`
+	ErrorDetails interface{}     `json:"errorDetails,omitempty"`
+	Replays      int             `json:"replays,omitempty"`
+	LastReplay   *time.Time      `json:"lastReplay,omitempty"`
+}
+
+// Store persists the entries of a queue
+type Store interface {
+	// Put adds or replaces an entry
+	Put(entry Entry) error
+	// List returns all entries in any order
+	List() ([]Entry, error)
+	// Get returns the entry with id, or ErrNotFound
+	Get(id string) (Entry, error)
+	// Delete removes the entry with id
+	Delete(id string) error
+}
+
+// Queue records failed tasks in a store and replays them
+type Queue struct {
+	store   Store
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	// Now can be replaced in tests
+	Now func() time.Time
+}
+
+// New creates the queue configured in cfg; it returns nil (disabled) when cfg
+// is nil
+func New(cfg *config.DeadLetterConfig) (*Queue, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	q := &Queue{Now: time.Now}
+	switch cfg.Backend {
+	case "", "memory":
+		q.store = NewMemoryStore(maxEntries)
+	case "file":
+		store, err := NewFileStore(cfg.Directory, maxEntries)
+		if err != nil {
+			return nil, err
+		}
+		q.store = store
+	case "http":
+		timeout := DefaultTimeout
+		if cfg.Timeout != "" {
+			d, err := time.ParseDuration(cfg.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q: %w", cfg.Timeout, err)
+			}
+			timeout = d
+		}
+		q.store = NewMemoryStore(maxEntries)
+		q.url = cfg.URL
+		q.headers = cfg.Headers
+		q.client = &http.Client{Timeout: timeout}
+	default:
+		return nil, fmt.Errorf("unsupported dead-letter backend %q", cfg.Backend)
+	}
+	return q, nil
+}
+
+// NewQueue creates a queue on a store
+func NewQueue(store Store) *Queue {
+	return &Queue{store: store, Now: time.Now}
+}
+
+// replayKey carries the replay in progress in a context
+type replayKey struct{}
+
+// replay is an entry being replayed; failed is set when it fails again
+type replay struct {
+	entry  Entry
+	failed bool
+}
+
+// Add records a failed task. A task failing again while it is replayed
+// updates its entry instead of adding one.
+func (q *Queue) Add(ctx context.Context, entry Entry) {
+	if q == nil {
+		return
+	}
+
+	now := q.Now()
+	forward := false
+	if r, ok := ctx.Value(replayKey{}).(*replay); ok {
+		r.failed = true
+		entry.ID = r.entry.ID
+		entry.Timestamp = r.entry.Timestamp
+		entry.Task = r.entry.Task
+		entry.Replays = r.entry.Replays + 1
+		entry.LastReplay = &now
+	} else {
+		entry.ID = newID(now)
+		entry.Timestamp = now
+		forward = q.url != ""
+	}
+
+	if err := q.store.Put(entry); err != nil {
+		slog.Error("failed to store dead-letter entry", logging.KeyTaskID, entry.TaskID, logging.KeyError, err)
+	}
+	if forward {
+		go q.forward(entry)
+	}
+}
+
+// forward posts an entry to the external queue of the http backend
+func (q *Queue) forward(entry Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		slog.Error("failed to forward dead-letter entry", logging.KeyTaskID, entry.TaskID, logging.KeyError, err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, q.url, bytes.NewReader(data))
+	if err != nil {
+		slog.Error("failed to forward dead-letter entry", logging.KeyTaskID, entry.TaskID, logging.KeyError, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range q.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := q.client.Do(req)
+	if err != nil {
+		slog.Error("failed to forward dead-letter entry", logging.KeyTaskID, entry.TaskID, logging.KeyError, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		slog.Error("dead-letter queue rejected entry", logging.KeyTaskID, entry.TaskID, "status", resp.StatusCode)
+	}
+}
+
+// Entries returns the entries, newest first
+func (q *Queue) Entries() ([]Entry, error) {
+	if q == nil {
+		return nil, nil
+	}
+	entries, err := q.store.List()
+	if err != nil {
+		return nil, err
+	}
+	sortNewestFirst(entries)
+	return entries, nil
+}
+
+// Get returns the entry with id
+func (q *Queue) Get(id string) (Entry, error) {
+	if q == nil {
+		return Entry{}, ErrNotFound
+	}
+	return q.store.Get(id)
+}
+
+// Delete removes the entry with id
+func (q *Queue) Delete(id string) error {
+	if q == nil {
+		return ErrNotFound
+	}
+	if _, err := q.store.Get(id); err != nil {
+		return err
+	}
+	return q.store.Delete(id)
+}
+
+// Clear removes all entries
+func (q *Queue) Clear() error {
+	entries, err := q.Entries()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := q.store.Delete(entry.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunFunc runs a task again and returns its result, which may be an error
+// response, and an error when the task failed again. Failures it records in
+// the queue of ctx update the replayed entry.
+type RunFunc func(ctx context.Context, task json.RawMessage) (interface{}, error)
+
+// ReplayResult is the outcome of replaying an entry
+type ReplayResult struct {
+	ID        string      `json:"id"`
+	Succeeded bool        `json:"succeeded"`
+	Result    interface{} `json:"result,omitempty"`
+	// Entry is the updated entry of a task that failed again
+	Entry *Entry `json:"entry,omitempty"`
+}
+
+// Replay runs the task of an entry again. The entry is removed when the task
+// succeeds, and updated with the new error when it fails again.
+func (q *Queue) Replay(ctx context.Context, id string, run RunFunc) (ReplayResult, error) {
+	entry, err := q.Get(id)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+
+	r := &replay{entry: entry}
+	value, runErr := run(context.WithValue(ctx, replayKey{}, r), entry.Task)
+	result := ReplayResult{ID: id, Result: value}
+	if runErr != nil || r.failed {
+		// Failures the task did not record still count as a replay
+		if !r.failed {
+			now := q.Now()
+			entry.Replays++
+			entry.LastReplay = &now
+			entry.Error = runErr.Error()
+			if err := q.store.Put(entry); err != nil {
+				return result, err
+			}
+		}
+		if updated, err := q.store.Get(id); err == nil {
+			result.Entry = &updated
+		}
+		return result, nil
+	}
+	result.Succeeded = true
+	return result, q.store.Delete(id)
+}
+
+// newID returns an ID that sorts entries by the time they were added
+func newID(now time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return now.UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(b)
+}
+
+// sortNewestFirst orders entries by the time they were added, newest first
+func sortNewestFirst(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].Timestamp.After(entries[j].Timestamp)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+}
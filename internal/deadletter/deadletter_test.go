@@ -0,0 +1,132 @@
+package deadletter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/deadletter"
+)
+
+func TestQueueReplay(t *testing.T) {
+	for _, backend := range []string{"memory", "file"} {
+		t.Run(backend, func(t *testing.T) {
+			q, err := deadletter.New(&config.DeadLetterConfig{Backend: backend, Directory: t.TempDir()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			q.Now = func() time.Time { now = now.Add(time.Second); return now }
+
+			ctx := context.Background()
+			q.Add(ctx, deadletter.Entry{TaskID: "t1", Stage: deadletter.StageBackend, Task: json.RawMessage(`{"id":"t1"}`), Error: "connection refused"})
+			q.Add(ctx, deadletter.Entry{TaskID: "t2", Stage: deadletter.StageRequestTransform, Task: json.RawMessage(`{"id":"t2"}`), Error: "bad template"})
+
+			entries, err := q.Entries()
+			if err != nil || len(entries) != 2 || entries[0].TaskID != "t2" {
+				t.Fatalf("Expected both entries newest first, got %v, %v", entries, err)
+			}
+
+			// A task failing again updates its entry
+			failing := func(ctx context.Context, task json.RawMessage) (interface{}, error) {
+				q.Add(ctx, deadletter.Entry{TaskID: "t1", Stage: deadletter.StageBackend, Error: "timeout"})
+				return nil, errors.New("timeout")
+			}
+			id := entries[1].ID
+			result, err := q.Replay(ctx, id, failing)
+			if err != nil || result.Succeeded || result.Entry == nil {
+				t.Fatalf("Expected a failed replay, got %+v, %v", result, err)
+			}
+			if e := result.Entry; e.Replays != 1 || e.Error != "timeout" || string(e.Task) != `{"id":"t1"}` || e.LastReplay == nil {
+				t.Errorf("Unexpected updated entry %+v", e)
+			}
+			if entries, _ := q.Entries(); len(entries) != 2 {
+				t.Errorf("Expected no new entry, got %d entries", len(entries))
+			}
+
+			// A task that succeeds leaves the queue
+			var replayed string
+			succeeding := func(ctx context.Context, task json.RawMessage) (interface{}, error) {
+				replayed = string(task)
+				return "done", nil
+			}
+			if result, err := q.Replay(ctx, id, succeeding); err != nil || !result.Succeeded || replayed != `{"id":"t1"}` {
+				t.Fatalf("Expected a successful replay of the task, got %+v, %v", result, err)
+			}
+			if _, err := q.Get(id); !errors.Is(err, deadletter.ErrNotFound) {
+				t.Errorf("Expected the entry to be removed, got %v", err)
+			}
+
+			if err := q.Clear(); err != nil {
+				t.Fatal(err)
+			}
+			if entries, _ := q.Entries(); len(entries) != 0 {
+				t.Errorf("Expected no entries after clear, got %v", entries)
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	q, err := deadletter.New(&config.DeadLetterConfig{Backend: "file", Directory: dir, MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		q.Add(context.Background(), deadletter.Entry{TaskID: fmt.Sprintf("t%d", i), Task: json.RawMessage(`{}`)})
+	}
+
+	// Entries survive a restart, and the oldest ones beyond maxEntries are removed
+	q, _ = deadletter.New(&config.DeadLetterConfig{Backend: "file", Directory: dir, MaxEntries: 2})
+	entries, err := q.Entries()
+	if err != nil || len(entries) != 2 || entries[0].TaskID != "t3" || entries[1].TaskID != "t2" {
+		t.Fatalf("Expected the two newest entries, got %v, %v", entries, err)
+	}
+
+	// IDs cannot name files outside the directory
+	if _, err := q.Get("../secret"); !errors.Is(err, deadletter.ErrNotFound) {
+		t.Errorf("Expected an invalid ID not to be found, got %v", err)
+	}
+}
+
+func TestHTTPBackend(t *testing.T) {
+	received := make(chan deadletter.Entry, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Expected the configured headers, got %v", r.Header)
+		}
+		var entry deadletter.Entry
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &entry)
+		received <- entry
+	}))
+	defer server.Close()
+
+	q, err := deadletter.New(&config.DeadLetterConfig{Backend: "http", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Add(context.Background(), deadletter.Entry{TaskID: "t1", Task: json.RawMessage(`{"id":"t1"}`), Error: "boom"})
+
+	select {
+	case entry := <-received:
+		if entry.TaskID != "t1" || entry.Error != "boom" || entry.ID == "" {
+			t.Errorf("Unexpected forwarded entry %+v", entry)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the entry to be posted to the queue")
+	}
+
+	// Entries are kept for the admin API too
+	if entries, _ := q.Entries(); len(entries) != 1 {
+		t.Errorf("Expected the entry to be kept, got %v", entries)
+	}
+}
@@ -0,0 +1,216 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// idPattern matches the IDs of entries, so IDs from the admin API cannot
+// name files outside the directory of a file store
+var idPattern = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}\.[0-9]{9}-[0-9a-f]+$`)
+
+// MemoryStore keeps up to MaxEntries entries in memory, dropping the oldest
+// when full
+type MemoryStore struct {
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore creates a memory store holding up to maxEntries entries
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{MaxEntries: maxEntries, entries: make(map[string]Entry)}
+}
+
+// Put adds or replaces an entry
+func (s *MemoryStore) Put(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.ID] = entry
+	if s.MaxEntries > 0 && len(s.entries) > s.MaxEntries {
+		oldest := ""
+		for id := range s.entries {
+			if oldest == "" || id < oldest {
+				oldest = id
+			}
+		}
+		delete(s.entries, oldest)
+	}
+	return nil
+}
+
+// List returns all entries
+func (s *MemoryStore) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Get returns the entry with id
+func (s *MemoryStore) Get(id string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return Entry{}, ErrNotFound
+	}
+	return entry, nil
+}
+
+// Delete removes the entry with id
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, id)
+	return nil
+}
+
+// FileStore keeps each entry as a JSON file in a directory, so entries
+// survive restarts; beyond MaxEntries the oldest files are removed
+type FileStore struct {
+	Dir        string
+	MaxEntries int
+
+	mu sync.Mutex
+}
+
+// NewFileStore creates a file store in dir, creating the directory if needed
+func NewFileStore(dir string, maxEntries int) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	return &FileStore{Dir: dir, MaxEntries: maxEntries}, nil
+}
+
+// path returns the file of the entry with id
+func (s *FileStore) path(id string) (string, error) {
+	if !idPattern.MatchString(id) {
+		return "", ErrNotFound
+	}
+	return filepath.Join(s.Dir, id+".json"), nil
+}
+
+// Put writes an entry to its file; the file is replaced atomically so a
+// crash never leaves half an entry
+func (s *FileStore) Put(entry Entry) error {
+	path, err := s.path(entry.ID)
+	if err != nil {
+		return fmt.Errorf("invalid entry id %q", entry.ID)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return s.trim()
+}
+
+// trim removes the oldest files beyond MaxEntries; IDs sort by age
+func (s *FileStore) trim() error {
+	if s.MaxEntries <= 0 {
+		return nil
+	}
+	ids, err := s.ids()
+	if err != nil {
+		return err
+	}
+	for len(ids) > s.MaxEntries {
+		if err := os.Remove(filepath.Join(s.Dir, ids[0]+".json")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
+
+// ids returns the IDs of the entries in the directory, oldest first
+func (s *FileStore) ids() ([]string, error) {
+	files, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, file := range files {
+		id := strings.TrimSuffix(file.Name(), ".json")
+		if !file.IsDir() && id != file.Name() && idPattern.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// List returns all entries; files that cannot be read are skipped
+func (s *FileStore) List() ([]Entry, error) {
+	s.mu.Lock()
+	ids, err := s.ids()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		if entry, err := s.Get(id); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Get reads the entry with id
+func (s *FileStore) Get(id string) (Entry, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return Entry{}, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Entry{}, ErrNotFound
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("invalid dead-letter entry %s: %w", id, err)
+	}
+	return entry, nil
+}
+
+// Delete removes the file of the entry with id
+func (s *FileStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
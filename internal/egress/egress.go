@@ -0,0 +1,192 @@
+// Package egress keeps configured classes of data, such as internal-only
+// fields, from leaving the connector toward agents: it strips them from
+// responses and events, or withholds those that carry them.
+package egress
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Actions of a data class
+const (
+	ActionStrip = "strip"
+	ActionBlock = "block"
+)
+
+// MetadataKey carries the violations found in a response or event in the
+// metadata of its task or message
+const MetadataKey = "egressViolations"
+
+// Withheld replaces the parts of strings a strip class matches
+const Withheld = "[WITHHELD]"
+
+// Violation is data of a class found on its way out, where it was found and
+// what was done about it
+type Violation struct {
+	Class  string `json:"class"`
+	Path   string `json:"path"`
+	Action string `json:"action"`
+}
+
+// Policy applies the data classes of a config
+type Policy struct {
+	classes []config.DataClassConfig
+}
+
+// New creates the policy of cfg; it returns nil, which lets everything
+// through, when cfg has no classes
+func New(cfg *config.EgressConfig) *Policy {
+	if cfg == nil || len(cfg.Classes) == 0 {
+		return nil
+	}
+	return &Policy{classes: cfg.Classes}
+}
+
+// Apply strips the data of strip classes from doc, in place, except below the
+// keys in skip, such as the meta the connector added. It returns what it found
+// and whether a block class was found, in which case doc must not be sent.
+func (p *Policy) Apply(doc map[string]interface{}, skip ...string) ([]Violation, bool) {
+	if p == nil {
+		return nil, false
+	}
+	w := &walker{policy: p, seen: make(map[Violation]bool)}
+	for key, value := range doc {
+		if contains(skip, key) {
+			continue
+		}
+		if w.field([]string{key}) {
+			delete(doc, key)
+			continue
+		}
+		doc[key] = w.walk([]string{key}, value)
+	}
+	sort.Slice(w.violations, func(i, j int) bool {
+		a, b := w.violations[i], w.violations[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Class < b.Class
+	})
+	return w.violations, w.blocked
+}
+
+// BlockedClasses returns the names of the block classes among violations
+func BlockedClasses(violations []Violation) []string {
+	var classes []string
+	for _, v := range violations {
+		if v.Action == ActionBlock && !contains(classes, v.Class) {
+			classes = append(classes, v.Class)
+		}
+	}
+	return classes
+}
+
+// walker collects the violations of one document
+type walker struct {
+	policy     *Policy
+	violations []Violation
+	seen       map[Violation]bool
+	blocked    bool
+}
+
+// walk strips the strip classes from v found at path
+func (w *walker) walk(path []string, v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			childPath := append(path[:len(path):len(path)], key)
+			if w.field(childPath) {
+				delete(value, key)
+				continue
+			}
+			value[key] = w.walk(childPath, child)
+		}
+	case []interface{}:
+		// Array elements are at the path of the array
+		for i, child := range value {
+			value[i] = w.walk(path, child)
+		}
+	case string:
+		return w.text(path, value)
+	}
+	return v
+}
+
+// field reports whether the value at path belongs to a strip class and is to
+// be removed; block classes are recorded
+func (w *walker) field(path []string) bool {
+	strip := false
+	for _, class := range w.policy.classes {
+		for _, field := range class.Fields {
+			if matchPath(strings.Split(field, "."), path) {
+				w.record(class, path)
+				strip = strip || action(class) == ActionStrip
+				break
+			}
+		}
+	}
+	return strip
+}
+
+// text replaces the matches of strip class patterns in s; matches of block
+// classes are recorded
+func (w *walker) text(path []string, s string) string {
+	for _, class := range w.policy.classes {
+		for _, pattern := range class.Compiled {
+			if !pattern.MatchString(s) {
+				continue
+			}
+			w.record(class, path)
+			if action(class) == ActionStrip {
+				s = pattern.ReplaceAllString(s, Withheld)
+			}
+		}
+	}
+	return s
+}
+
+func (w *walker) record(class config.DataClassConfig, path []string) {
+	v := Violation{Class: class.Name, Path: strings.Join(path, "."), Action: action(class)}
+	if v.Action == ActionBlock {
+		w.blocked = true
+	}
+	if !w.seen[v] {
+		w.seen[v] = true
+		w.violations = append(w.violations, v)
+	}
+}
+
+// action is the action of a class, strip by default
+func action(class config.DataClassConfig) string {
+	if class.Action == "" {
+		return ActionStrip
+	}
+	return class.Action
+}
+
+// matchPath reports whether path ends with the segments of field, where *
+// matches any key
+func matchPath(field, path []string) bool {
+	if len(field) > len(path) {
+		return false
+	}
+	path = path[len(path)-len(field):]
+	for i, segment := range field {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package egress_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/egress"
+)
+
+func policy(t *testing.T, classes ...config.DataClassConfig) *egress.Policy {
+	cfg := &config.ConnectorConfig{Egress: &config.EgressConfig{Classes: classes}}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	return egress.New(cfg.Egress)
+}
+
+func TestStrip(t *testing.T) {
+	p := policy(t,
+		config.DataClassConfig{Name: "internal-only", Fields: []string{"costPrice", "audit.*"}},
+		config.DataClassConfig{Name: "eu-resident", Patterns: []string{`\bDE\d{20}\b`}},
+	)
+	doc := map[string]interface{}{
+		"result": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"sku": "A-1", "price": 10.0, "costPrice": 6.0},
+				map[string]interface{}{"sku": "B-2", "price": 20.0, "costPrice": 11.0},
+			},
+			"audit": map[string]interface{}{"createdBy": "jdoe"},
+			"note":  "Pay to DE89370400440532013000 by Friday",
+		},
+		"meta": map[string]interface{}{"costPrice": "kept"},
+	}
+
+	violations, blocked := p.Apply(doc, "meta")
+	if blocked {
+		t.Error("Expected strip classes not to block")
+	}
+	want := []egress.Violation{
+		{Class: "internal-only", Path: "result.audit.createdBy", Action: egress.ActionStrip},
+		{Class: "internal-only", Path: "result.items.costPrice", Action: egress.ActionStrip},
+		{Class: "eu-resident", Path: "result.note", Action: egress.ActionStrip},
+	}
+	if !reflect.DeepEqual(violations, want) {
+		t.Errorf("Unexpected violations %v", violations)
+	}
+
+	result := doc["result"].(map[string]interface{})
+	for _, item := range result["items"].([]interface{}) {
+		if _, ok := item.(map[string]interface{})["costPrice"]; ok {
+			t.Errorf("Expected costPrice to be stripped from %v", item)
+		}
+	}
+	if audit := result["audit"].(map[string]interface{}); len(audit) != 0 {
+		t.Errorf("Expected audit fields to be stripped, got %v", audit)
+	}
+	if result["note"] != "Pay to "+egress.Withheld+" by Friday" {
+		t.Errorf("Expected the IBAN to be withheld, got %v", result["note"])
+	}
+	if doc["meta"].(map[string]interface{})["costPrice"] != "kept" {
+		t.Error("Expected skipped keys to be left alone")
+	}
+}
+
+func TestBlock(t *testing.T) {
+	p := policy(t, config.DataClassConfig{Name: "secret", Fields: []string{"salary"}, Action: egress.ActionBlock})
+
+	violations, blocked := p.Apply(map[string]interface{}{"result": map[string]interface{}{"salary": 90000}})
+	if !blocked || len(violations) != 1 || violations[0].Action != egress.ActionBlock {
+		t.Errorf("Expected the response to be blocked, got %v %v", violations, blocked)
+	}
+	if classes := egress.BlockedClasses(violations); !reflect.DeepEqual(classes, []string{"secret"}) {
+		t.Errorf("Unexpected blocked classes %v", classes)
+	}
+
+	if violations, blocked := p.Apply(map[string]interface{}{"result": map[string]interface{}{"name": "Ada"}}); blocked || len(violations) != 0 {
+		t.Errorf("Expected clean responses to pass, got %v %v", violations, blocked)
+	}
+	var disabled *egress.Policy
+	if violations, blocked := disabled.Apply(map[string]interface{}{"salary": 1}); blocked || violations != nil {
+		t.Error("Expected a nil policy to let everything through")
+	}
+}
@@ -0,0 +1,544 @@
+// Package expr evaluates the expressions of computed fields: arithmetic,
+// string concatenation, comparisons, conditionals and unit conversion over
+// the fields of a document
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Lookup returns the value of a dotted field path, or nil when it is missing
+type Lookup func(path string) interface{}
+
+// Expr is a parsed expression
+type Expr struct {
+	source string
+	root   node
+}
+
+// String returns the expression source
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression, resolving field paths with lookup. Numbers
+// are returned as float64.
+func (e *Expr) Eval(lookup Lookup) (interface{}, error) {
+	value, err := e.root.eval(lookup)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.source, err)
+	}
+	return value, nil
+}
+
+// Match evaluates the expression as a condition. Expressions that cannot be
+// evaluated, e.g. comparing a missing field, do not match.
+func (e *Expr) Match(lookup Lookup) bool {
+	value, err := e.Eval(lookup)
+	return err == nil && truthy(value)
+}
+
+// Parse parses an expression such as
+//
+//	qty * price
+//	first + ' ' + last
+//	status == 'A' ? 'active' : 'inactive'
+//	round(convert(weight, 'lb', 'kg'), 2)
+func Parse(source string) (*Expr, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+	return &Expr{source: source, root: root}, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators are matched longest first
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", ","}
+
+func lex(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(source) && source[i+1] >= '0' && source[i+1] <= '9':
+			start := i
+			for i < len(source) && (source[i] >= '0' && source[i] <= '9' || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, source[start:i], start})
+		case c == '\'' || c == '"':
+			start := i
+			var text strings.Builder
+			for i++; ; i++ {
+				if i >= len(source) {
+					return nil, fmt.Errorf("unterminated string at offset %d", start)
+				}
+				if source[i] == '\\' && i+1 < len(source) {
+					i++
+				} else if rune(source[i]) == c {
+					i++
+					break
+				}
+				text.WriteByte(source[i])
+			}
+			tokens = append(tokens, token{tokString, text.String(), start})
+		case c == '_' || c == '@' || c == '$' || unicode.IsLetter(c):
+			// @ and $ start the paths of JSONPath filters
+			start := i
+			for i++; i < len(source) && (source[i] == '_' || source[i] == '.' || unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i]))); {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, source[start:i], start})
+		default:
+			matched := ""
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					matched = op
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{tokOp, matched, i})
+			i += len(matched)
+		}
+	}
+	return append(tokens, token{tokEOF, "end of expression", len(source)}), nil
+}
+
+// parser is a recursive descent parser; each method parses one precedence
+// level, from the conditional operator down to literals and calls
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		return fmt.Errorf("expected %q at offset %d, found %q", op, tok.pos, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) conditional() (node, error) {
+	cond, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return cond, nil
+	}
+	then, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+	return conditionalNode{cond, then, otherwise}, nil
+}
+
+// precedence lists the binary operators from loosest to tightest binding
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) binary(level int) (node, error) {
+	if level == len(precedence) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(precedence[level]...)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op, left, right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if op, ok := p.accept("-", "!"); ok {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op, operand}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", tok.text, tok.pos)
+		}
+		return literalNode{n}, nil
+	case tokString:
+		return literalNode{tok.text}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		case "null":
+			return literalNode{nil}, nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.call(tok)
+		}
+		return fieldNode{tok.text}, nil
+	case tokOp:
+		if tok.text == "(" {
+			inner, err := p.conditional()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+}
+
+func (p *parser) call(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at offset %d", name.text, name.pos)
+	}
+	var args []node
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.conditional()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) < fn.minArgs || fn.maxArgs >= 0 && len(args) > fn.maxArgs {
+		return nil, fmt.Errorf("wrong number of arguments to %s at offset %d", name.text, name.pos)
+	}
+	return callNode{name.text, fn.call, args}, nil
+}
+
+type node interface {
+	eval(lookup Lookup) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(Lookup) (interface{}, error) {
+	return n.value, nil
+}
+
+type fieldNode struct {
+	path string
+}
+
+func (n fieldNode) eval(lookup Lookup) (interface{}, error) {
+	return lookup(n.path), nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n unaryNode) eval(lookup Lookup) (interface{}, error) {
+	value, err := n.operand.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !truthy(value), nil
+	}
+	x, err := toNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	return -x, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n binaryNode) eval(lookup Lookup) (interface{}, error) {
+	left, err := n.left.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	// Logical operators short-circuit
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+	}
+	right, err := n.right.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return truthy(right), nil
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "+":
+		// Strings concatenate, everything else adds
+		_, leftString := left.(string)
+		_, rightString := right.(string)
+		if leftString || rightString {
+			if left == nil || right == nil {
+				return nil, fmt.Errorf("missing value in concatenation")
+			}
+			return format(left) + format(right), nil
+		}
+	}
+
+	x, err := toNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	y, err := toNumber(right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	}
+	if y == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	if n.op == "%" {
+		return math.Mod(x, y), nil
+	}
+	return x / y, nil
+}
+
+type conditionalNode struct {
+	cond, then, otherwise node
+}
+
+func (n conditionalNode) eval(lookup Lookup) (interface{}, error) {
+	cond, err := n.cond.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(cond) {
+		return n.then.eval(lookup)
+	}
+	return n.otherwise.eval(lookup)
+}
+
+type callNode struct {
+	name string
+	fn   func(args []interface{}) (interface{}, error)
+	args []node
+}
+
+func (n callNode) eval(lookup Lookup) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(lookup)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	value, err := n.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return value, nil
+}
+
+// toNumber converts numbers and numeric strings, as many legacy systems
+// return amounts as text
+func toNumber(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return n, nil
+		}
+		return 0, fmt.Errorf("%q is not a number", v)
+	case nil:
+		return 0, fmt.Errorf("missing value")
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
+
+func format(value interface{}) string {
+	if n, ok := value.(float64); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case int:
+		return v != 0
+	}
+	return true
+}
+
+func equal(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	_, leftString := left.(string)
+	_, rightString := right.(string)
+	if !leftString || !rightString {
+		x, errX := toNumber(left)
+		y, errY := toNumber(right)
+		if errX == nil && errY == nil {
+			return x == y
+		}
+	}
+	return format(left) == format(right)
+}
+
+func compare(op string, left, right interface{}) (bool, error) {
+	var c int
+	x, errX := toNumber(left)
+	y, errY := toNumber(right)
+	switch {
+	case errX == nil && errY == nil:
+		if x < y {
+			c = -1
+		} else if x > y {
+			c = 1
+		}
+	case left == nil || right == nil:
+		return false, fmt.Errorf("missing value in comparison")
+	default:
+		c = strings.Compare(format(left), format(right))
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
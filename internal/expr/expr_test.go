@@ -0,0 +1,101 @@
+package expr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+)
+
+func TestEval(t *testing.T) {
+	fields := map[string]interface{}{
+		"qty":         3.0,
+		"price":       "19.90",
+		"first":       "Ada",
+		"last":        "Lovelace",
+		"status":      "A",
+		"weight":      10.0,
+		"temp":        212.0,
+		"order.id":    "O-1",
+		"order.count": 0.0,
+	}
+	lookup := func(path string) interface{} { return fields[path] }
+
+	tests := []struct {
+		expr     string
+		expected interface{}
+	}{
+		{"qty * price", 59.7},
+		{"first + ' ' + last", "Ada Lovelace"},
+		{"'#' + order.id + '-' + qty", "#O-1-3"},
+		{"status == 'A' ? 'active' : 'inactive'", "active"},
+		{"qty > 2 && !(order.count > 0)", true},
+		{"-qty + 10 % 4 * 2", 1.0},
+		{"round(convert(weight, 'lb', 'kg'), 2)", 4.54},
+		{"convert(temp, 'F', 'C')", 100.0},
+		{"coalesce(nickname, first)", "Ada"},
+		{"upper(last) + max(1, qty, 2)", "LOVELACE3"},
+		{"missing == null", true},
+		{"contains(first + last, 'love')", true},
+	}
+	for _, tt := range tests {
+		e, err := expr.Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error %v", tt.expr, err)
+		}
+		got, err := e.Eval(lookup)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.expr, err)
+		}
+		if n, ok := got.(float64); ok {
+			if want, ok := tt.expected.(float64); ok && n-want < 1e-9 && want-n < 1e-9 {
+				continue
+			}
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.expr, tt.expected, got)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	lookup := func(path string) interface{} {
+		return map[string]interface{}{"qty": 2.0, "name": "x"}[path]
+	}
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"qty * missing", "missing value"},
+		{"qty / 0", "division by zero"},
+		{"name * 2", "not a number"},
+		{"convert(qty, 'kg', 'm')", "cannot convert mass to length"},
+	}
+	for _, tt := range tests {
+		e, err := expr.Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error %v", tt.expr, err)
+		}
+		if _, err := e.Eval(lookup); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.expr, tt.err, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"qty *":          "unexpected",
+		"(qty":           `expected ")"`,
+		"sqrt(qty)":      `unknown function "sqrt"`,
+		"round()":        "wrong number of arguments",
+		"a ? b":          `expected ":"`,
+		"'unterminated":  "unterminated string",
+		"qty $ 2":        "unexpected",
+		"status == 'A'x": "unexpected",
+	}
+	for source, want := range tests {
+		if _, err := expr.Parse(source); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error containing %q, got %v", source, want, err)
+		}
+	}
+}
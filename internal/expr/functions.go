@@ -0,0 +1,163 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type function struct {
+	minArgs, maxArgs int // maxArgs -1 is variadic
+	call             func(args []interface{}) (interface{}, error)
+}
+
+// functions are the functions expressions can call
+var functions = map[string]function{
+	"round":    {1, 2, round},
+	"floor":    {1, 1, numeric(math.Floor)},
+	"ceil":     {1, 1, numeric(math.Ceil)},
+	"abs":      {1, 1, numeric(math.Abs)},
+	"min":      {1, -1, extreme(math.Min)},
+	"max":      {1, -1, extreme(math.Max)},
+	"number":   {1, 1, numeric(func(x float64) float64 { return x })},
+	"string":   {1, 1, text(func(s string) string { return s })},
+	"upper":    {1, 1, text(strings.ToUpper)},
+	"lower":    {1, 1, text(strings.ToLower)},
+	"trim":     {1, 1, text(strings.TrimSpace)},
+	"coalesce": {1, -1, coalesce},
+	"contains": {2, 2, contains},
+	"convert":  {3, 3, convert},
+}
+
+func numeric(f func(float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		x, err := toNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(x), nil
+	}
+}
+
+func text(f func(string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		return f(format(args[0])), nil
+	}
+}
+
+func extreme(f func(x, y float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		result, err := toNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, arg := range args[1:] {
+			x, err := toNumber(arg)
+			if err != nil {
+				return nil, err
+			}
+			result = f(result, x)
+		}
+		return result, nil
+	}
+}
+
+// round rounds to the given number of decimal places, 0 by default
+func round(args []interface{}) (interface{}, error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	places := 0.0
+	if len(args) > 1 {
+		if places, err = toNumber(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	scale := math.Pow(10, math.Trunc(places))
+	return math.Round(x*scale) / scale, nil
+}
+
+// coalesce returns the first argument that is neither missing nor empty
+func coalesce(args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil && arg != "" {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+// contains reports whether the first argument contains the second, ignoring
+// case, e.g. contains(text, 'urgent')
+func contains(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return false, nil
+	}
+	return strings.Contains(strings.ToLower(format(args[0])), strings.ToLower(format(args[1]))), nil
+}
+
+// unit is a unit of measure, converted through the base unit of its dimension
+type unit struct {
+	dimension string
+	factor    float64 // base units per unit
+	offset    float64 // added after scaling, for temperatures
+}
+
+var units = map[string]unit{
+	"mm": {"length", 0.001, 0},
+	"cm": {"length", 0.01, 0},
+	"m":  {"length", 1, 0},
+	"km": {"length", 1000, 0},
+	"in": {"length", 0.0254, 0},
+	"ft": {"length", 0.3048, 0},
+	"yd": {"length", 0.9144, 0},
+	"mi": {"length", 1609.344, 0},
+
+	"mg": {"mass", 0.000001, 0},
+	"g":  {"mass", 0.001, 0},
+	"kg": {"mass", 1, 0},
+	"t":  {"mass", 1000, 0},
+	"oz": {"mass", 0.028349523125, 0},
+	"lb": {"mass", 0.45359237, 0},
+
+	"ml":  {"volume", 0.001, 0},
+	"l":   {"volume", 1, 0},
+	"m3":  {"volume", 1000, 0},
+	"gal": {"volume", 3.785411784, 0},
+
+	"c": {"temperature", 1, 0},
+	"k": {"temperature", 1, -273.15},
+	"f": {"temperature", 5.0 / 9, -160.0 / 9},
+}
+
+// convert converts a quantity between units of the same dimension, e.g.
+// convert(weight, 'lb', 'kg')
+func convert(args []interface{}) (interface{}, error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return ConvertUnit(x, format(args[1]), format(args[2]))
+}
+
+// ConvertUnit converts x between units of the same dimension; unit names
+// are case-insensitive
+func ConvertUnit(x float64, fromUnit, toUnit string) (float64, error) {
+	from, ok := units[strings.ToLower(fromUnit)]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %v", fromUnit)
+	}
+	to, ok := units[strings.ToLower(toUnit)]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %v", toUnit)
+	}
+	if from.dimension != to.dimension {
+		return 0, fmt.Errorf("cannot convert %s to %s", from.dimension, to.dimension)
+	}
+	base := x*from.factor + from.offset
+	return (base - to.offset) / to.factor, nil
+}
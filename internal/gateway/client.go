@@ -3,12 +3,19 @@ package gateway
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/A2AGateway/a2a-connector/internal/logging"
 	a2a "github.com/A2AGateway/a2a-protocol"
 )
 
@@ -18,6 +25,25 @@ type Client struct {
 	connectorID  string
 	connectorURL string
 	httpClient   *http.Client
+
+	// Status returns the health sent with each heartbeat; heartbeats have no
+	// body when it is nil
+	Status func(ctx context.Context) Status
+
+	mu           sync.Mutex
+	card         *a2a.AgentCard
+	registered   bool
+	deregistered bool
+}
+
+// Status is the health of the connector reported with each heartbeat
+type Status struct {
+	Ready         bool               `json:"ready"`
+	Error         string             `json:"error,omitempty"`
+	Leader        bool               `json:"leader"`
+	UptimeSeconds int64              `json:"uptimeSeconds"`
+	Tasks         map[string]float64 `json:"tasks,omitempty"`
+	Timestamp     time.Time          `json:"timestamp"`
 }
 
 // NewClient creates a new gateway client.
@@ -34,8 +60,13 @@ func NewClient(gatewayURL, connectorID, connectorURL string) *Client {
 
 // Register posts the agent card to the gateway's connector registration endpoint.
 // Returns nil if the gateway doesn't yet implement the endpoint (404) so the
-// connector can still run in standalone mode.
+// connector can still run in standalone mode. A failed registration is
+// retried with the next heartbeat.
 func (c *Client) Register(card *a2a.AgentCard) error {
+	c.mu.Lock()
+	c.card = card
+	c.mu.Unlock()
+
 	data, err := json.Marshal(card)
 	if err != nil {
 		return fmt.Errorf("marshal agent card: %w", err)
@@ -49,24 +80,66 @@ func (c *Client) Register(card *a2a.AgentCard) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		log.Printf("[gateway] Registration endpoint not found at %s — connector running in standalone mode", url)
+		slog.Warn("gateway registration endpoint not found; connector running in standalone mode", "url", url)
+		c.setRegistered(true)
 		return nil
 	}
 	if resp.StatusCode >= 400 {
 		return fmt.Errorf("gateway registration returned HTTP %d", resp.StatusCode)
 	}
 
+	c.setRegistered(true)
 	return nil
 }
 
+// Registered reports whether the connector is registered with the gateway
+func (c *Client) Registered() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.registered
+}
+
+func (c *Client) setRegistered(registered bool) {
+	c.mu.Lock()
+	c.registered = registered
+	c.mu.Unlock()
+}
+
 // Heartbeat sends a keepalive ping to the gateway so it knows this connector
-// is still online. Silently ignores 404 (gateway not yet implementing heartbeat).
-func (c *Client) Heartbeat() error {
+// is still online, with the health Status returns. A connector that is not
+// registered registers first. Silently ignores 404 (gateway not yet
+// implementing heartbeat).
+func (c *Client) Heartbeat(ctx context.Context) error {
+	c.mu.Lock()
+	card, registered, deregistered := c.card, c.registered, c.deregistered
+	c.mu.Unlock()
+	if deregistered {
+		return nil
+	}
+	if !registered && card != nil {
+		if err := c.Register(card); err != nil {
+			return err
+		}
+		slog.Info("registered connector with gateway", "gateway", c.gatewayURL)
+	}
+
+	var body io.Reader
+	if c.Status != nil {
+		data, err := json.Marshal(c.Status(ctx))
+		if err != nil {
+			return fmt.Errorf("marshal status: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
 	url := fmt.Sprintf("%s/api/v1/connectors/%s/heartbeat", c.gatewayURL, c.connectorID)
-	req, err := http.NewRequest(http.MethodPut, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
 	if err != nil {
 		return err
 	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -93,10 +166,213 @@ func (c *Client) StartHeartbeat(ctx context.Context, interval time.Duration) {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				if err := c.Heartbeat(); err != nil {
-					log.Printf("[gateway] heartbeat failed: %v", err)
+				if err := c.Heartbeat(ctx); err != nil {
+					slog.Warn("gateway heartbeat failed", logging.KeyError, err)
 				}
 			}
 		}
 	}()
 }
+
+// Deregister removes the connector from the gateway on shutdown, so the
+// gateway stops routing tasks to it without waiting for heartbeats to stop;
+// no heartbeats are sent after it. Silently ignores 404 (gateway not yet
+// implementing deregistration, or the connector was never registered).
+func (c *Client) Deregister(ctx context.Context) error {
+	c.mu.Lock()
+	c.registered = false
+	c.deregistered = true
+	c.mu.Unlock()
+
+	url := fmt.Sprintf("%s/api/v1/connectors/%s", c.gatewayURL, c.connectorID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("DELETE %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("gateway deregistration returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PushTask posts a task the connector started itself, such as one run by a
+// schedule, to the gateway's connector task endpoint. Like Register it returns
+// nil if the gateway doesn't yet implement the endpoint (404).
+func (c *Client) PushTask(ctx context.Context, source string, task interface{}) error {
+	return c.push(ctx, "tasks", map[string]interface{}{"source": source, "task": task})
+}
+
+// PushMessage posts a message the connector started itself, such as one made
+// of a legacy webhook event, to the gateway's connector message endpoint
+func (c *Client) PushMessage(ctx context.Context, source string, message interface{}) error {
+	return c.push(ctx, "messages", map[string]interface{}{"source": source, "message": message})
+}
+
+// push posts body to the connector endpoint of the gateway named by kind
+func (c *Client) push(ctx context.Context, kind string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", kind, err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/connectors/%s/%s", c.gatewayURL, c.connectorID, kind)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		slog.Warn("gateway endpoint not found; "+kind+" not pushed", "url", url)
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("gateway %s push returned HTTP %d", kind, resp.StatusCode)
+	}
+	return nil
+}
+
+// RemoteConfig is a connector config served by the gateway, and its version
+type RemoteConfig struct {
+	Data    []byte
+	Version string
+}
+
+// ConfigStatus reports to the gateway whether a config version was applied
+type ConfigStatus struct {
+	Version   string    `json:"version"`
+	Applied   bool      `json:"applied"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FetchConfig gets the config of the connector from the gateway. It returns
+// nil when the gateway has no version newer than version. The gateway may
+// hold the request for up to wait until a new version is published; held
+// reports that it did, so the caller can ask again right away. The version
+// is the ETag of the response, or the SHA-256 of the config without one.
+func (c *Client) FetchConfig(ctx context.Context, version string, wait time.Duration) (config *RemoteConfig, held bool, err error) {
+	url := fmt.Sprintf("%s/api/v1/connectors/%s/config", c.gatewayURL, c.connectorID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/yaml, application/json")
+	if version != "" {
+		req.Header.Set("If-None-Match", version)
+	}
+	if wait > 0 {
+		req.Header.Set("Prefer", fmt.Sprintf("wait=%d", int(wait.Seconds())))
+	}
+
+	// A held request outlasts the timeout of the other calls
+	client := &http.Client{Transport: c.httpClient.Transport, Timeout: c.httpClient.Timeout + wait}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("GET %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	held = strings.Contains(resp.Header.Get("Preference-Applied"), "wait")
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, held, nil
+	case resp.StatusCode >= 400:
+		return nil, false, fmt.Errorf("gateway config returned HTTP %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("GET %s: %w", url, err)
+	}
+	config = &RemoteConfig{Data: data, Version: resp.Header.Get("ETag")}
+	if config.Version == "" {
+		sum := sha256.Sum256(data)
+		config.Version = hex.EncodeToString(sum[:])
+	}
+	return config, held, nil
+}
+
+// ReportConfigStatus tells the gateway whether a config version was applied.
+// Silently ignores 404 (gateway not yet implementing config status).
+func (c *Client) ReportConfigStatus(ctx context.Context, status ConfigStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("marshal config status: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/connectors/%s/config/status", c.gatewayURL, c.connectorID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("config status returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WatchConfig polls the gateway for new versions of the config every
+// interval, or right away again when the gateway held the request, until ctx
+// is cancelled. Each new version is passed to apply and its outcome reported
+// to the gateway; a version that fails to apply is not tried again.
+func (c *Client) WatchConfig(ctx context.Context, version string, interval time.Duration, apply func(*RemoteConfig) error) {
+	for {
+		config, held, err := c.FetchConfig(ctx, version, interval)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("failed to fetch config from gateway", logging.KeyError, err)
+		case config != nil:
+			status := ConfigStatus{Version: config.Version, Applied: true, Timestamp: time.Now()}
+			if err := apply(config); err != nil {
+				status.Applied = false
+				status.Error = err.Error()
+			}
+			version = config.Version
+			if err := c.ReportConfigStatus(ctx, status); err != nil {
+				slog.Warn("failed to report config status to gateway", logging.KeyError, err)
+			}
+		}
+
+		if err == nil && held {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
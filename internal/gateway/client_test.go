@@ -0,0 +1,148 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/gateway"
+	a2a "github.com/A2AGateway/a2a-protocol"
+)
+
+func TestRegistrationAndHeartbeat(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	var status gateway.Status
+	failRegister := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/api/v1/connectors/register" && failRegister:
+			failRegister = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &status)
+		}
+	}))
+	defer server.Close()
+
+	c := gateway.NewClient(server.URL, "erp", "http://connector:8082")
+	c.Status = func(ctx context.Context) gateway.Status {
+		return gateway.Status{Ready: true, Leader: true, Tasks: map[string]float64{"success": 3}, Timestamp: time.Now()}
+	}
+	if err := c.Register(&a2a.AgentCard{Name: "erp"}); err == nil || c.Registered() {
+		t.Fatal("Expected the first registration to fail")
+	}
+
+	// The next heartbeat registers again and reports the status
+	ctx := context.Background()
+	if err := c.Heartbeat(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Registered() {
+		t.Error("Expected the heartbeat to register the connector")
+	}
+	if !status.Ready || !status.Leader || status.Tasks["success"] != 3 {
+		t.Errorf("Unexpected heartbeat status %+v", status)
+	}
+
+	// No heartbeats are sent after deregistration
+	if err := c.Deregister(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Heartbeat(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{
+		"POST /api/v1/connectors/register",
+		"POST /api/v1/connectors/register",
+		"PUT /api/v1/connectors/erp/heartbeat",
+		"DELETE /api/v1/connectors/erp",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected call %d to be %s, got %s", i, expected[i], calls[i])
+		}
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	var mu sync.Mutex
+	versions := []string{`"v1"`, `"v2"`}
+	var statuses []gateway.ConfigStatus
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPost {
+			var status gateway.ConfigStatus
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &status)
+			statuses = append(statuses, status)
+			return
+		}
+		if r.Header.Get("Prefer") != "wait=1" {
+			t.Errorf("Expected the client to ask for a long poll, got %q", r.Header.Get("Prefer"))
+		}
+		// The gateway holds requests for the current version
+		w.Header().Set("Preference-Applied", "wait=1")
+		if len(versions) == 0 || r.Header.Get("If-None-Match") == versions[0] {
+			if len(versions) > 0 {
+				versions = versions[1:]
+			}
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", versions[0])
+		w.Write([]byte("adapter:\n  type: " + versions[0] + "\n"))
+	}))
+	defer server.Close()
+
+	c := gateway.NewClient(server.URL, "erp", "http://connector:8082")
+	var applied []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.WatchConfig(ctx, "", time.Second, func(config *gateway.RemoteConfig) error {
+			applied = append(applied, config.Version)
+			if config.Version == `"v2"` {
+				return errors.New("invalid adapter type")
+			}
+			return nil
+		})
+	}()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		mu.Lock()
+		reported := len(statuses)
+		mu.Unlock()
+		if reported == 2 {
+			break
+		}
+	}
+	cancel()
+	<-done
+
+	if len(applied) != 2 || applied[0] != `"v1"` {
+		t.Fatalf("Expected both versions to be applied, got %v", applied)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(statuses) != 2 || !statuses[0].Applied || statuses[1].Applied || statuses[1].Error != "invalid adapter type" {
+		t.Errorf("Expected v1 to be reported applied and v2 failed, got %+v", statuses)
+	}
+}
@@ -0,0 +1,140 @@
+// Package health serves liveness and readiness endpoints for orchestrators
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Defaults for readiness probes
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// ProbeFunc checks that the legacy backend is reachable
+type ProbeFunc func(ctx context.Context) error
+
+// Status is the result of a readiness check
+type Status struct {
+	Ready     bool      `json:"ready"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+	LatencyMS int64     `json:"latencyMs"`
+}
+
+// Checker runs the readiness probe at most once per CacheTTL so frequent
+// orchestrator polls do not load the backend
+type Checker struct {
+	Probe    ProbeFunc
+	Timeout  time.Duration
+	CacheTTL time.Duration
+
+	// Now can be replaced in tests
+	Now func() time.Time
+
+	mu       sync.Mutex
+	last     Status
+	cached   bool
+	draining bool
+}
+
+// NewChecker creates a readiness checker with the default timeout and cache TTL
+func NewChecker(probe ProbeFunc) *Checker {
+	return &Checker{
+		Probe:    probe,
+		Timeout:  DefaultTimeout,
+		CacheTTL: DefaultCacheTTL,
+		Now:      time.Now,
+	}
+}
+
+// CheckerFromConfig creates a readiness checker, applying durations from config
+func CheckerFromConfig(probe ProbeFunc, cfg config.ReadinessConfig) (*Checker, error) {
+	c := NewChecker(probe)
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid readiness timeout: %w", err)
+		}
+		c.Timeout = d
+	}
+	if cfg.CacheTTL != "" {
+		d, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid readiness cacheTTL: %w", err)
+		}
+		c.CacheTTL = d
+	}
+	return c, nil
+}
+
+// SetDraining makes readiness fail without probing, so the orchestrator stops
+// routing traffic while in-flight tasks finish before shutdown
+func (c *Checker) SetDraining(draining bool) {
+	c.mu.Lock()
+	c.draining = draining
+	c.mu.Unlock()
+}
+
+// Check returns the cached status when it is fresh, probing the backend otherwise.
+// Concurrent callers share one probe.
+func (c *Checker) Check(ctx context.Context) Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.Now()
+	if c.draining {
+		return Status{Ready: false, Error: "draining", CheckedAt: now}
+	}
+	if c.cached && now.Sub(c.last.CheckedAt) < c.CacheTTL {
+		return c.last
+	}
+
+	status := Status{Ready: true, CheckedAt: now}
+	if c.Probe != nil {
+		if c.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+		}
+		start := time.Now()
+		if err := c.Probe(ctx); err != nil {
+			status.Ready = false
+			status.Error = err.Error()
+		}
+		status.LatencyMS = time.Since(start).Milliseconds()
+	}
+
+	c.last = status
+	c.cached = true
+	return status
+}
+
+// LivenessHandler reports that the process is up and serving requests
+func LivenessHandler(connectorID string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok", "connector": connectorID})
+	})
+}
+
+// ReadinessHandler reports whether the backend is reachable; it responds 503
+// when the probe fails so traffic is routed elsewhere
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := c.Check(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+}
@@ -0,0 +1,98 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/health"
+)
+
+func TestReadinessCachesProbeResult(t *testing.T) {
+	now := time.Unix(0, 0)
+	calls := 0
+	probeErr := errors.New("connection refused")
+
+	checker := health.NewChecker(func(ctx context.Context) error {
+		calls++
+		return probeErr
+	})
+	checker.Now = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 while the backend is down, got %d", rec.Code)
+	}
+	var status health.Status
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if status.Ready || status.Error != "connection refused" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	// Polls within the cache TTL reuse the last result
+	probeErr = nil
+	now = now.Add(time.Second)
+	if checker.Check(context.Background()).Ready || calls != 1 {
+		t.Errorf("Expected cached failure after 1 probe, got %d probes", calls)
+	}
+
+	now = now.Add(health.DefaultCacheTTL)
+	if !checker.Check(context.Background()).Ready || calls != 2 {
+		t.Errorf("Expected fresh success after 2 probes, got %d probes", calls)
+	}
+
+	// A draining connector reports not ready without probing
+	checker.SetDraining(true)
+	if status := checker.Check(context.Background()); status.Ready || calls != 2 {
+		t.Errorf("Expected draining status without a probe, got %+v after %d probes", status, calls)
+	}
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	checker := health.NewChecker(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	checker.Timeout = 10 * time.Millisecond
+
+	if status := checker.Check(context.Background()); status.Ready {
+		t.Error("Expected a hanging probe to time out as not ready")
+	}
+}
+
+func TestRESTAdapterPing(t *testing.T) {
+	status := http.StatusMethodNotAllowed
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected HEAD, got %s", r.Method)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("legacy", server.URL, nil, nil)
+	wrapped := adapter.NewLimitedAdapter(rest, nil)
+
+	// Client errors still prove the backend is reachable
+	if err := adapter.Ping(context.Background(), wrapped); err != nil {
+		t.Errorf("Expected ping to succeed on %d, got %v", status, err)
+	}
+
+	status = http.StatusBadGateway
+	if err := adapter.Ping(context.Background(), wrapped); err == nil {
+		t.Error("Expected ping to fail on 502")
+	}
+
+	server.Close()
+	if err := adapter.Ping(context.Background(), rest); err == nil {
+		t.Error("Expected ping to fail when the backend is unreachable")
+	}
+}
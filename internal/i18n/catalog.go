@@ -0,0 +1,255 @@
+// Package i18n provides a message catalog for operator- and agent-facing strings
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Message keys used by the connector
+const (
+	MsgMethodNotAllowed    = "method_not_allowed"
+	MsgReadBodyFailed      = "read_body_failed"
+	MsgInvalidJSON         = "invalid_json"
+	MsgMethodNotFound      = "method_not_found"
+	MsgInvalidParams       = "invalid_params"
+	MsgRequestTransform    = "request_transform_failed"
+	MsgBadLegacyRequest    = "bad_legacy_request"
+	MsgResponseTransform   = "response_transform_failed"
+	MsgNoMatchingMapping   = "no_matching_mapping"
+	MsgStatusLine          = "status_line"
+	MsgErrorLine           = "error_line"
+	MsgUnchanged           = "unchanged"
+	MsgForbidden           = "forbidden"
+	MsgMoreResults         = "more_results"
+	MsgBatchSummary        = "batch_summary"
+	MsgInvalidContinuation = "invalid_continuation"
+	MsgExpired             = "expired"
+	MsgBodyTooLarge        = "body_too_large"
+	MsgJobRunning          = "job_running"
+	MsgBusy                = "busy"
+	MsgWithheld            = "withheld"
+)
+
+// DefaultLocale is used when neither the task nor the connector specify a locale
+const DefaultLocale = "en"
+
+// builtinMessages contains the translations shipped with the connector
+var builtinMessages = map[string]map[string]string{
+	"en": {
+		MsgMethodNotAllowed:    "Method Not Allowed",
+		MsgReadBodyFailed:      "Failed to read request body",
+		MsgInvalidJSON:         "Invalid JSON",
+		MsgMethodNotFound:      "Method not found",
+		MsgInvalidParams:       "Failed to parse params",
+		MsgRequestTransform:    "Request transform failed",
+		MsgBadLegacyRequest:    "Bad legacy request format",
+		MsgResponseTransform:   "Response transform failed",
+		MsgNoMatchingMapping:   "no matching mapping found for text: %s",
+		MsgStatusLine:          "Status: %s",
+		MsgErrorLine:           "Error: %s",
+		MsgUnchanged:           "Unchanged since the last request",
+		MsgForbidden:           "Not authorized for this request",
+		MsgMoreResults:         "More results available; send the continuationToken from the task metadata to continue",
+		MsgBatchSummary:        "Processed %d records in %d tasks: %d succeeded, %d failed, %d skipped",
+		MsgExpired:             "Expired before execution; the task was not sent to the legacy system",
+		MsgInvalidContinuation: "Invalid or expired continuation token",
+		MsgBodyTooLarge:        "Request body too large",
+		MsgJobRunning:          "Job %s is running; checked %d times",
+		MsgBusy:                "Too many tasks in progress; retry later",
+		MsgWithheld:            "Response withheld: it contains %s data that may not leave the connector",
+	},
+	"de": {
+		MsgMethodNotAllowed:    "Methode nicht erlaubt",
+		MsgReadBodyFailed:      "Anfrage konnte nicht gelesen werden",
+		MsgInvalidJSON:         "Ungültiges JSON",
+		MsgMethodNotFound:      "Methode nicht gefunden",
+		MsgInvalidParams:       "Parameter konnten nicht verarbeitet werden",
+		MsgRequestTransform:    "Umwandlung der Anfrage fehlgeschlagen",
+		MsgBadLegacyRequest:    "Ungültiges Format der Altsystem-Anfrage",
+		MsgResponseTransform:   "Umwandlung der Antwort fehlgeschlagen",
+		MsgNoMatchingMapping:   "keine passende Zuordnung für den Text gefunden: %s",
+		MsgStatusLine:          "Status: %s",
+		MsgErrorLine:           "Fehler: %s",
+		MsgUnchanged:           "Seit der letzten Anfrage unverändert",
+		MsgForbidden:           "Für diese Anfrage nicht berechtigt",
+		MsgMoreResults:         "Weitere Ergebnisse verfügbar; zum Fortfahren das continuationToken aus den Task-Metadaten senden",
+		MsgBatchSummary:        "%d Datensätze in %d Tasks verarbeitet: %d erfolgreich, %d fehlgeschlagen, %d übersprungen",
+		MsgExpired:             "Vor der Ausführung abgelaufen; der Task wurde nicht an das Altsystem gesendet",
+		MsgInvalidContinuation: "Ungültiges oder abgelaufenes Fortsetzungstoken",
+		MsgBodyTooLarge:        "Anfrage ist zu groß",
+		MsgJobRunning:          "Auftrag %s läuft; %d-mal geprüft",
+		MsgBusy:                "Zu viele Tasks in Bearbeitung; später erneut versuchen",
+		MsgWithheld:            "Antwort zurückgehalten: sie enthält %s-Daten, die den Connector nicht verlassen dürfen",
+	},
+	"fr": {
+		MsgMethodNotAllowed:    "Méthode non autorisée",
+		MsgReadBodyFailed:      "Impossible de lire le corps de la requête",
+		MsgInvalidJSON:         "JSON invalide",
+		MsgMethodNotFound:      "Méthode introuvable",
+		MsgInvalidParams:       "Impossible d'analyser les paramètres",
+		MsgRequestTransform:    "Échec de la transformation de la requête",
+		MsgBadLegacyRequest:    "Format de requête du système existant invalide",
+		MsgResponseTransform:   "Échec de la transformation de la réponse",
+		MsgNoMatchingMapping:   "aucune correspondance trouvée pour le texte : %s",
+		MsgStatusLine:          "Statut : %s",
+		MsgErrorLine:           "Erreur : %s",
+		MsgUnchanged:           "Inchangé depuis la dernière requête",
+		MsgForbidden:           "Non autorisé pour cette requête",
+		MsgMoreResults:         "D'autres résultats sont disponibles ; envoyez le continuationToken des métadonnées de la tâche pour continuer",
+		MsgBatchSummary:        "%d enregistrements traités en %d tâches : %d réussies, %d échouées, %d ignorées",
+		MsgExpired:             "Expirée avant exécution ; la tâche n'a pas été envoyée au système existant",
+		MsgInvalidContinuation: "Jeton de continuation invalide ou expiré",
+		MsgBodyTooLarge:        "Corps de la requête trop volumineux",
+		MsgJobRunning:          "La tâche %s est en cours ; vérifiée %d fois",
+		MsgBusy:                "Trop de tâches en cours ; réessayez plus tard",
+		MsgWithheld:            "Réponse retenue : elle contient des données %s qui ne peuvent pas quitter le connecteur",
+	},
+	"es": {
+		MsgMethodNotAllowed:    "Método no permitido",
+		MsgReadBodyFailed:      "No se pudo leer el cuerpo de la solicitud",
+		MsgInvalidJSON:         "JSON no válido",
+		MsgMethodNotFound:      "Método no encontrado",
+		MsgInvalidParams:       "No se pudieron analizar los parámetros",
+		MsgRequestTransform:    "Error al transformar la solicitud",
+		MsgBadLegacyRequest:    "Formato de solicitud heredada no válido",
+		MsgResponseTransform:   "Error al transformar la respuesta",
+		MsgNoMatchingMapping:   "no se encontró ninguna asignación para el texto: %s",
+		MsgStatusLine:          "Estado: %s",
+		MsgErrorLine:           "Error: %s",
+		MsgUnchanged:           "Sin cambios desde la última solicitud",
+		MsgForbidden:           "No autorizado para esta solicitud",
+		MsgMoreResults:         "Hay más resultados; envíe el continuationToken de los metadatos de la tarea para continuar",
+		MsgBatchSummary:        "%d registros procesados en %d tareas: %d correctas, %d fallidas, %d omitidas",
+		MsgExpired:             "Caducada antes de la ejecución; la tarea no se envió al sistema heredado",
+		MsgInvalidContinuation: "Token de continuación no válido o caducado",
+		MsgBodyTooLarge:        "El cuerpo de la solicitud es demasiado grande",
+		MsgJobRunning:          "El trabajo %s está en curso; comprobado %d veces",
+		MsgBusy:                "Demasiadas tareas en curso; vuelva a intentarlo más tarde",
+		MsgWithheld:            "Respuesta retenida: contiene datos %s que no pueden salir del conector",
+	},
+}
+
+// Catalog resolves message keys to localized strings
+type Catalog struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]string
+}
+
+// NewCatalog creates a catalog preloaded with the built-in translations
+func NewCatalog(defaultLocale string) *Catalog {
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+
+	c := &Catalog{
+		defaultLocale: normalize(defaultLocale),
+		messages:      make(map[string]map[string]string),
+	}
+	for locale, msgs := range builtinMessages {
+		c.AddMessages(locale, msgs)
+	}
+	return c
+}
+
+// DefaultLocale returns the connector-wide locale of the catalog
+func (c *Catalog) DefaultLocale() string {
+	return c.defaultLocale
+}
+
+// AddMessages adds or overrides messages for a locale
+func (c *Catalog) AddMessages(locale string, msgs map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locale = normalize(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]string)
+	}
+	for key, value := range msgs {
+		c.messages[locale][key] = value
+	}
+}
+
+// T returns the message for key in the given locale, formatted with args.
+// Lookup falls back from the exact locale to its base language, then to the
+// catalog's default locale, then to English, and finally to the key itself.
+func (c *Catalog) T(locale, key string, args ...interface{}) string {
+	format := c.lookup(locale, key)
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// lookup finds the unformatted message for key
+func (c *Catalog) lookup(locale, key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candidate := range c.candidates(locale) {
+		if msgs, ok := c.messages[candidate]; ok {
+			if msg, ok := msgs[key]; ok {
+				return msg
+			}
+		}
+	}
+	return key
+}
+
+// candidates returns the locales to try in order
+func (c *Catalog) candidates(locale string) []string {
+	var result []string
+	if locale != "" {
+		locale = normalize(locale)
+		result = append(result, locale)
+		if base := baseLanguage(locale); base != locale {
+			result = append(result, base)
+		}
+	}
+	result = append(result, c.defaultLocale, baseLanguage(c.defaultLocale), DefaultLocale)
+	return result
+}
+
+// ParseAcceptLanguage returns the preferred locale from an Accept-Language header
+func ParseAcceptLanguage(header string) string {
+	best := ""
+	bestQ := -1.0
+	for _, entry := range strings.Split(header, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		tag := entry
+		q := 1.0
+		if idx := strings.Index(entry, ";"); idx != -1 {
+			tag = strings.TrimSpace(entry[:idx])
+			if _, err := fmt.Sscanf(strings.TrimSpace(entry[idx+1:]), "q=%g", &q); err != nil {
+				q = 0
+			}
+		}
+		if tag == "*" {
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return normalize(best)
+}
+
+// normalize lower-cases a locale and uses "-" as separator (de_CH -> de-ch)
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// baseLanguage returns the language part of a locale (de-ch -> de)
+func baseLanguage(locale string) string {
+	if idx := strings.Index(locale, "-"); idx != -1 {
+		return locale[:idx]
+	}
+	return locale
+}
@@ -0,0 +1,61 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+)
+
+func TestCatalogFallback(t *testing.T) {
+	catalog := i18n.NewCatalog("de")
+
+	// Exact locale
+	if msg := catalog.T("fr", i18n.MsgInvalidJSON); msg != "JSON invalide" {
+		t.Errorf("Expected French message, got '%s'", msg)
+	}
+
+	// Region falls back to base language
+	if msg := catalog.T("es_MX", i18n.MsgMethodNotFound); msg != "Método no encontrado" {
+		t.Errorf("Expected Spanish message, got '%s'", msg)
+	}
+
+	// Unknown locale falls back to the connector default
+	if msg := catalog.T("ja", i18n.MsgInvalidJSON); msg != "Ungültiges JSON" {
+		t.Errorf("Expected German message, got '%s'", msg)
+	}
+
+	// Unknown key returns the key itself
+	if msg := catalog.T("en", "missing_key"); msg != "missing_key" {
+		t.Errorf("Expected key, got '%s'", msg)
+	}
+}
+
+func TestCatalogOverridesAndFormatting(t *testing.T) {
+	catalog := i18n.NewCatalog("")
+	catalog.AddMessages("nl", map[string]string{
+		i18n.MsgErrorLine: "Fout: %s",
+	})
+
+	if msg := catalog.T("nl-BE", i18n.MsgErrorLine, "timeout"); msg != "Fout: timeout" {
+		t.Errorf("Expected overridden message, got '%s'", msg)
+	}
+	if msg := catalog.T("nl", i18n.MsgStatusLine, "ok"); msg != "Status: ok" {
+		t.Errorf("Expected English fallback, got '%s'", msg)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":                         "",
+		"de-CH":                    "de-ch",
+		"en;q=0.5, fr-FR;q=0.9, *": "fr-fr",
+		"es, en;q=0.8":             "es",
+		"invalid;q=abc, it;q=0.1":  "it",
+	}
+
+	for header, expected := range tests {
+		if got := i18n.ParseAcceptLanguage(header); got != expected {
+			t.Errorf("ParseAcceptLanguage(%q): expected '%s', got '%s'", header, expected, got)
+		}
+	}
+}
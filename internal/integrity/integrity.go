@@ -0,0 +1,203 @@
+// Package integrity checksums and signs task payloads exchanged with the SaaS
+package integrity
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+const (
+	// DigestHeader carries the SHA-256 digest of the body (RFC 9530)
+	DigestHeader = "Content-Digest"
+	// SignatureHeader carries a detached JWS over the body
+	SignatureHeader = "X-A2A-Signature"
+)
+
+// Modes supported by the verifier
+const (
+	ModeDigest = "digest"
+	ModeJWS    = "jws"
+)
+
+// jwsHeader is the protected header of the detached JWS
+var jwsHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256"}`))
+
+// ErrMissing is returned when a required checksum or signature is absent
+var ErrMissing = errors.New("payload checksum or signature missing")
+
+// Verifier adds and checks payload checksums or signatures
+type Verifier struct {
+	Mode     string
+	Required bool
+	Secret   []byte
+}
+
+// New creates a verifier from config; an empty mode returns nil (disabled)
+func New(cfg config.IntegrityConfig) (*Verifier, error) {
+	switch cfg.Mode {
+	case "":
+		return nil, nil
+	case ModeDigest:
+	case ModeJWS:
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("integrity secret is required for jws mode")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported integrity mode %q", cfg.Mode)
+	}
+
+	return &Verifier{Mode: cfg.Mode, Required: cfg.Required, Secret: []byte(cfg.Secret)}, nil
+}
+
+// Sign sets the checksum and, in jws mode, the signature headers for body
+func (v *Verifier) Sign(h http.Header, body []byte) {
+	h.Set(DigestHeader, Digest(body))
+	if v.Mode == ModeJWS {
+		h.Set(SignatureHeader, v.signature(body))
+	}
+}
+
+// Verify checks the headers against body. Payloads without headers are
+// accepted unless the verifier is Required.
+func (v *Verifier) Verify(h http.Header, body []byte) error {
+	digest := h.Get(DigestHeader)
+	signature := h.Get(SignatureHeader)
+
+	if v.Mode == ModeJWS && signature == "" && v.Required {
+		return ErrMissing
+	}
+	if digest == "" && signature == "" {
+		if v.Required {
+			return ErrMissing
+		}
+		return nil
+	}
+
+	if digest != "" {
+		if err := verifyDigest(digest, body); err != nil {
+			return err
+		}
+	}
+	if signature != "" && v.Mode == ModeJWS {
+		if err := v.verifySignature(signature, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Digest returns the Content-Digest header value for body
+func Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+// verifyDigest checks a Content-Digest header; only sha-256 is understood
+func verifyDigest(header string, body []byte) error {
+	for _, entry := range strings.Split(header, ",") {
+		entry = strings.TrimSpace(entry)
+		if !strings.HasPrefix(strings.ToLower(entry), "sha-256=") {
+			continue
+		}
+		if !hmac.Equal([]byte(entry[len("sha-256="):]), []byte(Digest(body)[len("sha-256="):])) {
+			return fmt.Errorf("payload digest mismatch")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported digest algorithm in %q", header)
+}
+
+// signature returns a detached HS256 JWS (header..signature) over body
+func (v *Verifier) signature(body []byte) string {
+	return jwsHeader + ".." + v.sign(jwsHeader, body)
+}
+
+func (v *Verifier) sign(header string, body []byte) string {
+	mac := hmac.New(sha256.New, v.Secret)
+	mac.Write([]byte(header + "." + base64.RawURLEncoding.EncodeToString(body)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignature checks a detached JWS over body
+func (v *Verifier) verifySignature(jws string, body []byte) error {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return fmt.Errorf("malformed detached JWS signature")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed JWS header: %w", err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return fmt.Errorf("malformed JWS header: %w", err)
+	}
+	if header.Alg != "HS256" {
+		return fmt.Errorf("unsupported JWS algorithm %q", header.Alg)
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(v.sign(parts[0], body))) {
+		return fmt.Errorf("payload signature mismatch")
+	}
+	return nil
+}
+
+// Middleware verifies request payloads and signs response payloads.
+// A nil verifier returns next unchanged.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	if v == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		if len(body) > 0 || r.Method == http.MethodPost {
+			if err := v.Verify(r.Header, body); err != nil {
+				http.Error(w, "payload integrity check failed: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		// Buffer the response so its checksum can be sent as a header
+		rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+		v.Sign(w.Header(), rec.body.Bytes())
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+// bufferedResponse captures a handler's response
+type bufferedResponse struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
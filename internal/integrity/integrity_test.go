@@ -0,0 +1,91 @@
+package integrity_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/integrity"
+)
+
+func TestDigestAndSignature(t *testing.T) {
+	v, err := integrity.New(config.IntegrityConfig{Mode: "jws", Required: true, Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	body := []byte(`{"id":"task-1"}`)
+	h := make(http.Header)
+	v.Sign(h, body)
+
+	if !strings.HasPrefix(h.Get(integrity.DigestHeader), "sha-256=:") {
+		t.Errorf("Unexpected digest header: %q", h.Get(integrity.DigestHeader))
+	}
+	if parts := strings.Split(h.Get(integrity.SignatureHeader), "."); len(parts) != 3 || parts[1] != "" {
+		t.Errorf("Expected a detached JWS, got %q", h.Get(integrity.SignatureHeader))
+	}
+	if err := v.Verify(h, body); err != nil {
+		t.Fatalf("Expected signed payload to verify, got %v", err)
+	}
+
+	// Tampered payloads fail
+	if err := v.Verify(h, []byte(`{"id":"task-2"}`)); err == nil {
+		t.Error("Expected tampered payload to fail verification")
+	}
+
+	// A signature made with another secret fails even with a matching digest
+	other, _ := integrity.New(config.IntegrityConfig{Mode: "jws", Secret: "other"})
+	forged := make(http.Header)
+	other.Sign(forged, body)
+	if err := v.Verify(forged, body); err == nil {
+		t.Error("Expected signature with wrong secret to fail")
+	}
+
+	// Required mode rejects unsigned payloads
+	if err := v.Verify(make(http.Header), body); err != integrity.ErrMissing {
+		t.Errorf("Expected ErrMissing, got %v", err)
+	}
+
+	// Optional digest mode accepts unsigned payloads but checks present digests
+	d, _ := integrity.New(config.IntegrityConfig{Mode: "digest"})
+	if err := d.Verify(make(http.Header), body); err != nil {
+		t.Errorf("Expected unsigned payload to be accepted, got %v", err)
+	}
+	bad := http.Header{integrity.DigestHeader: []string{integrity.Digest([]byte("other"))}}
+	if err := d.Verify(bad, body); err == nil {
+		t.Error("Expected digest mismatch")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	v, _ := integrity.New(config.IntegrityConfig{Mode: "digest", Required: true})
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+
+	body := `{"id":"task-1"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(integrity.DigestHeader, integrity.Digest([]byte(body)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get(integrity.DigestHeader); got != integrity.Digest(rec.Body.Bytes()) {
+		t.Errorf("Response digest %q does not match body", got)
+	}
+
+	// Unsigned requests are rejected in required mode
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unsigned request, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,119 @@
+// Package intent chooses the mapping of a task with a classifier instead of,
+// or in addition to, regular expression intent patterns: a language model
+// behind an OpenAI-compatible endpoint, or a local similarity measure between
+// the task text and mapping descriptions
+package intent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Matching modes
+const (
+	ModeRegex  = "regex"
+	ModeLLM    = "llm"
+	ModeHybrid = "hybrid"
+)
+
+// DefaultTimeout limits a classification by default
+const DefaultTimeout = 10 * time.Second
+
+// Default minimum confidences; similarity scores are lower than the
+// confidence a language model reports for the same match
+const (
+	DefaultMinConfidence           = 0.5
+	DefaultSimilarityMinConfidence = 0.3
+)
+
+// Candidate is a mapping the classifier may choose. Slots are the parameters
+// to extract from the text.
+type Candidate struct {
+	ID            int      `json:"id"`
+	Description   string   `json:"description,omitempty"`
+	IntentPattern string   `json:"intentPattern"`
+	Slots         []string `json:"slots,omitempty"`
+}
+
+// Result is the candidate a classifier chose, with the slot values it
+// extracted; ID is -1 when no candidate fits
+type Result struct {
+	ID         int                    `json:"mapping"`
+	Confidence float64                `json:"confidence"`
+	Slots      map[string]interface{} `json:"slots,omitempty"`
+}
+
+// Classifier chooses the candidate that fits a task text
+type Classifier interface {
+	Classify(ctx context.Context, text string, candidates []Candidate) (*Result, error)
+}
+
+// Router asks a classifier for the mapping of a task
+type Router struct {
+	Mode          string
+	Classifier    Classifier
+	MinConfidence float64
+	Timeout       time.Duration
+}
+
+// New creates a router from config; it returns nil when tasks are matched by
+// intent patterns only
+func New(cfg *config.IntentConfig) (*Router, error) {
+	if cfg == nil || cfg.Mode == "" || cfg.Mode == ModeRegex {
+		return nil, nil
+	}
+
+	r := &Router{Mode: cfg.Mode, MinConfidence: cfg.MinConfidence, Timeout: DefaultTimeout}
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intent timeout: %w", err)
+		}
+		r.Timeout = d
+	}
+	switch cfg.Classifier {
+	case "", "llm":
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("intent classifier llm requires an endpoint")
+		}
+		r.Classifier = &LLM{Endpoint: cfg.Endpoint, Model: cfg.Model, APIKey: cfg.APIKey}
+		if r.MinConfidence == 0 {
+			r.MinConfidence = DefaultMinConfidence
+		}
+	case "similarity":
+		r.Classifier = Similarity{}
+		if r.MinConfidence == 0 {
+			r.MinConfidence = DefaultSimilarityMinConfidence
+		}
+	default:
+		return nil, fmt.Errorf("unsupported intent classifier %q", cfg.Classifier)
+	}
+	return r, nil
+}
+
+// Route classifies text within the timeout. It returns nil when no candidate
+// fits with at least the minimum confidence.
+func (r *Router) Route(text string, candidates []Candidate) (*Result, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.Timeout)
+	defer cancel()
+
+	result, err := r.Classifier.Classify(ctx, text, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || result.ID < 0 || result.Confidence < r.MinConfidence {
+		return nil, nil
+	}
+	for _, candidate := range candidates {
+		if candidate.ID == result.ID {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("classifier chose unknown mapping %d", result.ID)
+}
@@ -0,0 +1,95 @@
+package intent_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/intent"
+)
+
+var candidates = []intent.Candidate{
+	{ID: 0, Description: "Look up the shipping status of an order", IntentPattern: `order\s+status`, Slots: []string{"orderId"}},
+	{ID: 1, Description: "Find a customer account by name or email", IntentPattern: `find\s+customer`},
+	{ID: 3, Description: "Refund a payment to the customer", IntentPattern: `refund`},
+}
+
+func TestSimilarity(t *testing.T) {
+	router, err := intent.New(&config.IntentConfig{Mode: "hybrid", Classifier: "similarity"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"where are my orders, has it shipped?", 0},
+		{"search the accounts for jane@example.com", 1},
+		{"I want my payment back", 3},
+	}
+	for _, tt := range tests {
+		result, err := router.Route(tt.text, candidates)
+		if err != nil || result == nil {
+			t.Errorf("%s: expected mapping %d, got %v %v", tt.text, tt.expected, result, err)
+			continue
+		}
+		if result.ID != tt.expected {
+			t.Errorf("%s: expected mapping %d, got %d", tt.text, tt.expected, result.ID)
+		}
+	}
+
+	// Unrelated text stays below the minimum confidence
+	if result, _ := router.Route("tell me a joke", candidates); result != nil {
+		t.Errorf("Expected no mapping, got %+v", result)
+	}
+}
+
+func TestLLM(t *testing.T) {
+	var reply string
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer k" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		prompt = body.Messages[0].Content
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": reply}}},
+		})
+	}))
+	defer server.Close()
+
+	router, err := intent.New(&config.IntentConfig{Mode: "llm", Endpoint: server.URL, APIKey: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reply = "```json\n{\"mapping\": 0, \"confidence\": 0.8, \"slots\": {\"orderId\": \"A-17\"}}\n```"
+	result, err := router.Route("where is A-17", candidates)
+	if err != nil || result == nil || result.ID != 0 || result.Slots["orderId"] != "A-17" {
+		t.Fatalf("Unexpected result %+v %v", result, err)
+	}
+	if !strings.Contains(prompt, `"slots":["orderId"]`) {
+		t.Errorf("Expected the candidates in the prompt, got %s", prompt)
+	}
+
+	// Unsure choices are ignored, unknown ones are errors
+	reply = `{"mapping": 1, "confidence": 0.2}`
+	if result, err := router.Route("hmm", candidates); result != nil || err != nil {
+		t.Errorf("Expected no mapping, got %+v %v", result, err)
+	}
+	reply = `{"mapping": 2, "confidence": 0.9}`
+	if _, err := router.Route("hmm", candidates); err == nil {
+		t.Error("Expected an error for an unknown mapping")
+	}
+}
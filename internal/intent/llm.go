@@ -0,0 +1,101 @@
+package intent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// systemPrompt instructs the model; the candidates follow it as JSON
+const systemPrompt = `You route requests to backend operations. Choose the operation from the JSON list below that fits the user's request and extract the values of its slots from the request.
+Reply with only a JSON object: {"mapping": <id of the operation, or -1 if none fits>, "confidence": <number from 0 to 1>, "slots": {<slot name>: <value>}}. Omit slots the request does not mention.
+Operations: `
+
+// LLM classifies with a language model behind an OpenAI-compatible chat
+// completions endpoint
+type LLM struct {
+	Endpoint   string
+	Model      string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// Classify implements Classifier
+func (c *LLM) Classify(ctx context.Context, text string, candidates []Candidate) (*Result, error) {
+	operations, err := json.Marshal(candidates)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"model":           c.Model,
+		"temperature":     0,
+		"response_format": map[string]string{"type": "json_object"},
+		"messages": []map[string]string{
+			{"role": "system", "content": systemPrompt + string(operations)},
+			{"role": "user", "content": text},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("intent classifier request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("intent classifier returned %s", resp.Status)
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return nil, fmt.Errorf("failed to decode intent classifier response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("intent classifier returned no choices")
+	}
+	return parseResult(completion.Choices[0].Message.Content)
+}
+
+// parseResult decodes the model's reply, which may be wrapped in a Markdown
+// code fence
+func parseResult(content string) (*Result, error) {
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "```") {
+		content = strings.TrimPrefix(content, "```json")
+		content = strings.TrimPrefix(content, "```")
+		content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+	}
+	result := &Result{ID: -1}
+	if err := json.Unmarshal([]byte(content), result); err != nil {
+		return nil, fmt.Errorf("invalid intent classifier reply %q: %w", content, err)
+	}
+	return result, nil
+}
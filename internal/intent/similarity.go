@@ -0,0 +1,106 @@
+package intent
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// wordPattern finds the words of a text or intent pattern
+var wordPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+// escapePattern finds escape sequences such as \b or \s in an intent pattern
+var escapePattern = regexp.MustCompile(`\\.`)
+
+// stopWords are too common to tell mappings apart
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true, "to": true,
+	"for": true, "in": true, "on": true, "at": true, "by": true, "with": true, "from": true,
+	"is": true, "are": true, "be": true, "it": true, "this": true, "that": true, "my": true,
+	"me": true, "i": true, "we": true, "our": true, "you": true, "your": true, "please": true,
+	"can": true, "could": true, "would": true, "what": true, "which": true,
+}
+
+// Similarity classifies locally by the TF-IDF cosine similarity between the
+// task text and each candidate's description and intent pattern words. It
+// does not extract slots.
+type Similarity struct{}
+
+// Classify implements Classifier
+func (Similarity) Classify(ctx context.Context, text string, candidates []Candidate) (*Result, error) {
+	docs := make([]map[string]float64, len(candidates))
+	frequency := make(map[string]int)
+	for i, candidate := range candidates {
+		pattern := escapePattern.ReplaceAllString(strings.ToLower(candidate.IntentPattern), " ")
+		docs[i] = termCounts(candidate.Description + " " + pattern)
+		for term := range docs[i] {
+			frequency[term]++
+		}
+	}
+	idf := make(map[string]float64, len(frequency))
+	for term, n := range frequency {
+		idf[term] = math.Log(1 + float64(len(candidates))/float64(n))
+	}
+
+	query := weigh(termCounts(text), idf)
+	best := &Result{ID: -1}
+	for i, doc := range docs {
+		if score := cosine(query, weigh(doc, idf)); score > best.Confidence {
+			best = &Result{ID: candidates[i].ID, Confidence: score}
+		}
+	}
+	return best, nil
+}
+
+// termCounts counts the words of text, without stop words and with plural
+// endings removed
+func termCounts(text string) map[string]float64 {
+	counts := make(map[string]float64)
+	for _, word := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if stopWords[word] {
+			continue
+		}
+		counts[stem(word)]++
+	}
+	return counts
+}
+
+// stem removes plural endings so "orders" matches "order"
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// weigh multiplies term counts by their inverse document frequency. Terms no
+// candidate has are left out, so names and numbers in the text do not lower
+// the score.
+func weigh(counts map[string]float64, idf map[string]float64) map[string]float64 {
+	weights := make(map[string]float64, len(counts))
+	for term, n := range counts {
+		if w, ok := idf[term]; ok {
+			weights[term] = n * w
+		}
+	}
+	return weights
+}
+
+func cosine(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, w := range a {
+		dot += w * b[term]
+		normA += w * w
+	}
+	for _, w := range b {
+		normB += w * w
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,463 @@
+// Package jsonpath reads and writes values of JSON documents by path. Paths
+// are dot paths extended with a subset of JSONPath:
+//
+//	result.customer.name          keys
+//	result.items[0].id            array index, negative from the end
+//	result.items[*].id            every element
+//	result.items[1:3]             slice
+//	result['odd.key']             quoted key
+//	result.items[?(@.type == 'X' && @.qty > 1)].id
+//	                              elements matching a filter expression
+//	result.items[?(@.type == 'X')].id.first()
+//	                              first(), last() and length() of the matches
+//
+// A path may start with $. Paths with a wildcard, slice or filter match
+// several values and return them as a list.
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+)
+
+type segmentKind int
+
+const (
+	segmentKey segmentKind = iota
+	segmentIndex
+	segmentWildcard
+	segmentSlice
+	segmentFilter
+	segmentFunction
+)
+
+type segment struct {
+	kind       segmentKind
+	key        string // key or function name
+	index      int
+	start, end *int
+	filter     *expr.Expr
+}
+
+// Path is a parsed path
+type Path struct {
+	source   string
+	segments []segment
+}
+
+// String returns the path source
+func (p *Path) String() string {
+	return p.source
+}
+
+// Definite reports whether the path matches at most one value
+func (p *Path) Definite() bool {
+	for _, seg := range p.segments {
+		switch seg.kind {
+		case segmentWildcard, segmentSlice, segmentFilter:
+			return false
+		}
+	}
+	return true
+}
+
+// functions are applied to the matches of a path and must come last
+var functions = map[string]bool{"first": true, "last": true, "length": true}
+
+// Parse parses a path
+func Parse(source string) (*Path, error) {
+	s := strings.TrimPrefix(source, "$")
+	p := &Path{source: source}
+	if s == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	for i := 0; i < len(s); {
+		if len(p.segments) > 0 && p.segments[len(p.segments)-1].kind == segmentFunction {
+			return nil, fmt.Errorf("%s: %s() must come last", source, p.segments[len(p.segments)-1].key)
+		}
+		switch {
+		case s[i] == '[':
+			seg, n, err := parseBracket(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", source, err)
+			}
+			p.segments = append(p.segments, seg)
+			i += n
+		case s[i] == '.' && (i == 0 || i+1 < len(s) && s[i+1] != '.'):
+			i++
+			fallthrough
+		default:
+			if s[i] == '.' {
+				return nil, fmt.Errorf("%s: recursive descent (..) is not supported", source)
+			}
+			end := i
+			for end < len(s) && s[end] != '.' && s[end] != '[' {
+				end++
+			}
+			name := s[i:end]
+			switch {
+			case name == "":
+				return nil, fmt.Errorf("%s: empty key at offset %d", source, i)
+			case name == "*":
+				p.segments = append(p.segments, segment{kind: segmentWildcard})
+			case strings.HasSuffix(name, "()") && functions[strings.TrimSuffix(name, "()")]:
+				p.segments = append(p.segments, segment{kind: segmentFunction, key: strings.TrimSuffix(name, "()")})
+			default:
+				p.segments = append(p.segments, segment{kind: segmentKey, key: name})
+			}
+			i = end
+		}
+	}
+	return p, nil
+}
+
+// parseBracket parses a [...] segment and returns its length
+func parseBracket(s string) (segment, int, error) {
+	// Find the closing bracket outside quotes and parentheses
+	depth, quote := 0, byte(0)
+	end := -1
+	for i := 1; i < len(s) && end < 0; i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ']' && depth == 0:
+			end = i
+		}
+	}
+	if end < 0 {
+		return segment{}, 0, fmt.Errorf("unterminated [")
+	}
+	inner := strings.TrimSpace(s[1:end])
+
+	switch {
+	case inner == "*":
+		return segment{kind: segmentWildcard}, end + 1, nil
+	case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+		return segment{kind: segmentKey, key: inner[1 : len(inner)-1]}, end + 1, nil
+	case strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")"):
+		filter, err := expr.Parse(inner[2 : len(inner)-1])
+		if err != nil {
+			return segment{}, 0, fmt.Errorf("invalid filter: %v", err)
+		}
+		return segment{kind: segmentFilter, filter: filter}, end + 1, nil
+	case strings.Contains(inner, ":"):
+		bounds := strings.SplitN(inner, ":", 2)
+		seg := segment{kind: segmentSlice}
+		for i, bound := range bounds {
+			if bound = strings.TrimSpace(bound); bound == "" {
+				continue
+			}
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return segment{}, 0, fmt.Errorf("invalid slice [%s]", inner)
+			}
+			if i == 0 {
+				seg.start = &n
+			} else {
+				seg.end = &n
+			}
+		}
+		return seg, end + 1, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return segment{}, 0, fmt.Errorf("invalid index [%s]", inner)
+	}
+	return segment{kind: segmentIndex, index: n}, end + 1, nil
+}
+
+// Get returns the value at the path, or nil when there is none. Paths that
+// match several values return the list of matches, or nil when none match.
+func (p *Path) Get(data interface{}) interface{} {
+	nodes := []interface{}{data}
+	for _, seg := range p.segments {
+		if seg.kind == segmentFunction {
+			return applyFunction(seg.key, nodes, p.Definite())
+		}
+		var next []interface{}
+		for _, node := range nodes {
+			next = append(next, children(node, seg, data)...)
+		}
+		nodes = next
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+	if p.Definite() {
+		return nodes[0]
+	}
+	return nodes
+}
+
+// children returns the values a segment selects in a node
+func children(node interface{}, seg segment, root interface{}) []interface{} {
+	if m, ok := node.(map[string]string); ok {
+		node = stringMap(m)
+	}
+	switch seg.kind {
+	case segmentKey:
+		if m, ok := node.(map[string]interface{}); ok {
+			if value, ok := m[seg.key]; ok {
+				return []interface{}{value}
+			}
+		}
+	case segmentIndex:
+		if list, ok := node.([]interface{}); ok {
+			if i, ok := resolveIndex(seg.index, len(list)); ok {
+				return []interface{}{list[i]}
+			}
+		}
+	case segmentWildcard:
+		switch v := node.(type) {
+		case []interface{}:
+			return v
+		case map[string]interface{}:
+			// Map values in key order, so results are stable
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			values := make([]interface{}, len(keys))
+			for i, key := range keys {
+				values[i] = v[key]
+			}
+			return values
+		}
+	case segmentSlice:
+		if list, ok := node.([]interface{}); ok {
+			start, end := sliceBounds(seg, len(list))
+			return list[start:end]
+		}
+	case segmentFilter:
+		var matches []interface{}
+		candidates, ok := node.([]interface{})
+		if !ok {
+			candidates = []interface{}{node}
+		}
+		for _, candidate := range candidates {
+			if matchFilter(seg.filter, candidate, root) {
+				matches = append(matches, candidate)
+			}
+		}
+		return matches
+	}
+	return nil
+}
+
+// matchFilter evaluates a filter with @ as the element and $ as the
+// document; other names are keys of the element. Filters that cannot be
+// evaluated, e.g. comparing a missing field, do not match.
+func matchFilter(filter *expr.Expr, element, root interface{}) bool {
+	value, err := filter.Eval(func(path string) interface{} {
+		switch {
+		case path == "@":
+			return element
+		case path == "$":
+			return root
+		case strings.HasPrefix(path, "$."):
+			return Get(root, path[2:])
+		}
+		return Get(element, strings.TrimPrefix(path, "@."))
+	})
+	if err != nil {
+		return false
+	}
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	}
+	return true
+}
+
+func applyFunction(name string, nodes []interface{}, definite bool) interface{} {
+	// A definite path applies the function to the list it points to
+	if definite && len(nodes) == 1 {
+		if list, ok := nodes[0].([]interface{}); ok {
+			nodes = list
+		} else if name == "length" {
+			switch v := nodes[0].(type) {
+			case map[string]interface{}:
+				return float64(len(v))
+			case string:
+				return float64(len(v))
+			}
+		}
+	}
+	switch name {
+	case "length":
+		return float64(len(nodes))
+	case "first":
+		if len(nodes) > 0 {
+			return nodes[0]
+		}
+	case "last":
+		if len(nodes) > 0 {
+			return nodes[len(nodes)-1]
+		}
+	}
+	return nil
+}
+
+func resolveIndex(i, n int) (int, bool) {
+	if i < 0 {
+		i += n
+	}
+	return i, i >= 0 && i < n
+}
+
+func sliceBounds(seg segment, n int) (int, int) {
+	start, end := 0, n
+	if seg.start != nil {
+		start = *seg.start
+	}
+	if seg.end != nil {
+		end = *seg.end
+	}
+	if start < 0 {
+		start += n
+	}
+	if end < 0 {
+		end += n
+	}
+	start = max(0, min(start, n))
+	end = max(start, min(end, n))
+	return start, end
+}
+
+func stringMap(m map[string]string) map[string]interface{} {
+	converted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		converted[k] = v
+	}
+	return converted
+}
+
+// Set sets the value at the path in data, creating missing objects along
+// the way and growing lists to reach an index. Wildcards, slices and
+// filters set the value in every match.
+func (p *Path) Set(data map[string]interface{}, value interface{}) error {
+	for _, seg := range p.segments {
+		if seg.kind == segmentFunction {
+			return fmt.Errorf("%s: cannot set the result of %s()", p.source, seg.key)
+		}
+	}
+	if len(p.segments) > 0 && p.segments[0].kind == segmentIndex {
+		return fmt.Errorf("%s: cannot index the document", p.source)
+	}
+	set(data, p.segments, value, data)
+	return nil
+}
+
+// set returns node with the value set at the path below it; maps are
+// updated in place, lists may be replaced when they grow
+func set(node interface{}, segments []segment, value interface{}, root interface{}) interface{} {
+	if len(segments) == 0 {
+		return value
+	}
+	seg, rest := segments[0], segments[1:]
+	if m, ok := node.(map[string]string); ok {
+		node = stringMap(m)
+	}
+
+	switch seg.kind {
+	case segmentKey:
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			m = make(map[string]interface{})
+		}
+		m[seg.key] = set(m[seg.key], rest, value, root)
+		return m
+	case segmentIndex:
+		list, _ := node.([]interface{})
+		i := seg.index
+		if i < 0 {
+			if i += len(list); i < 0 {
+				return node
+			}
+		}
+		for len(list) <= i {
+			list = append(list, nil)
+		}
+		list[i] = set(list[i], rest, value, root)
+		return list
+	}
+
+	// Wildcards, slices and filters update existing elements only
+	switch v := node.(type) {
+	case []interface{}:
+		start, end := 0, len(v)
+		if seg.kind == segmentSlice {
+			start, end = sliceBounds(seg, len(v))
+		}
+		for i := start; i < end; i++ {
+			if seg.kind != segmentFilter || matchFilter(seg.filter, v[i], root) {
+				v[i] = set(v[i], rest, value, root)
+			}
+		}
+	case map[string]interface{}:
+		if seg.kind == segmentWildcard {
+			for key, child := range v {
+				v[key] = set(child, rest, value, root)
+			}
+		} else if seg.kind == segmentFilter && matchFilter(seg.filter, v, root) {
+			return set(v, rest, value, root)
+		}
+	}
+	return node
+}
+
+// cache holds parsed paths, as the same few paths are used for every task
+var cache sync.Map
+
+func cached(path string) (*Path, error) {
+	if p, ok := cache.Load(path); ok {
+		return p.(*Path), nil
+	}
+	p, err := Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	cache.Store(path, p)
+	return p, nil
+}
+
+// Get returns the value at a path in data, or nil when the path is invalid
+// or matches nothing
+func Get(data interface{}, path string) interface{} {
+	p, err := cached(path)
+	if err != nil {
+		return nil
+	}
+	return p.Get(data)
+}
+
+// Set sets the value at a path in data; invalid paths are ignored
+func Set(data map[string]interface{}, path string, value interface{}) {
+	if p, err := cached(path); err == nil {
+		p.Set(data, value)
+	}
+}
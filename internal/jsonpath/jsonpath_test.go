@@ -0,0 +1,86 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/jsonpath"
+)
+
+func document(t *testing.T) map[string]interface{} {
+	var doc map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"result": {
+			"customer": {"name": "Acme", "odd.key": "dotted"},
+			"items": [
+				{"id": "A1", "type": "book", "qty": 1},
+				{"id": "B2", "type": "pen", "qty": 5},
+				{"id": "C3", "type": "book", "qty": 3}
+			]
+		}
+	}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"result.customer.name", "Acme"},
+		{"$.result.customer.name", "Acme"},
+		{"result.customer['odd.key']", "dotted"},
+		{"result.items[0].id", "A1"},
+		{"result.items[-1].id", "C3"},
+		{"result.items[*].id", "[A1 B2 C3]"},
+		{"result.items[1:].id", "[B2 C3]"},
+		{"result.items[?(@.type == 'book')].id", "[A1 C3]"},
+		{"result.items[?(@.type == 'book' && qty > 2)].id", "[C3]"},
+		{"result.items[?(@.type == 'book')].id.first()", "A1"},
+		{"result.items[?(@.type == 'book')].last()", "map[id:C3 qty:3 type:book]"},
+		{"result.items.length()", "3"},
+		{"result.items[?(@.missing > 1)]", "<nil>"},
+		{"result.items[7].id", "<nil>"},
+		{"result.missing.name", "<nil>"},
+	}
+	doc := document(t)
+	for _, tt := range tests {
+		if got := fmt.Sprint(jsonpath.Get(doc, tt.path)); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.path, tt.expected, got)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	doc := document(t)
+	jsonpath.Set(doc, "result.items[?(@.type == 'book')].shelf", "B")
+	jsonpath.Set(doc, "result.items[1].qty", 6)
+	jsonpath.Set(doc, "order.lines[1].sku", "X")
+
+	if got := fmt.Sprint(jsonpath.Get(doc, "result.items[*].shelf")); got != "[B B]" {
+		t.Errorf("Expected the shelf set on the books, got %s", got)
+	}
+	if got := jsonpath.Get(doc, "result.items[1].qty"); got != 6 {
+		t.Errorf("Expected the quantity replaced, got %v", got)
+	}
+	if got := fmt.Sprint(doc["order"]); got != "map[lines:[<nil> map[sku:X]]]" {
+		t.Errorf("Expected the list grown to the index, got %s", got)
+	}
+
+	path, _ := jsonpath.Parse("result.items.length()")
+	if err := path.Set(doc, 1); err == nil {
+		t.Error("Expected an error setting a function result")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, path := range []string{"", "result..name", "items[", "items[x]", "items[?(@.qty >)]", "items.first().id"} {
+		if _, err := jsonpath.Parse(path); err == nil {
+			t.Errorf("%q: expected a parse error", path)
+		}
+	}
+}
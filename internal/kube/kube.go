@@ -0,0 +1,175 @@
+// Package kube supports running the connector on Kubernetes: pod metadata
+// from the downward API, ConfigMap reload and leader election
+package kube
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// Defaults for running on Kubernetes
+const (
+	DefaultReloadInterval  = 10 * time.Second
+	DefaultDrainDelay      = 5 * time.Second
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+// Settings are the parsed durations of the kubernetes config section
+type Settings struct {
+	ReloadInterval  time.Duration
+	DrainDelay      time.Duration
+	ShutdownTimeout time.Duration
+}
+
+// SettingsFromConfig parses the kubernetes config section. The drain delay
+// defaults to DefaultDrainDelay inside a cluster and to zero elsewhere.
+func SettingsFromConfig(cfg config.KubernetesConfig) (Settings, error) {
+	s := Settings{
+		ReloadInterval:  DefaultReloadInterval,
+		ShutdownTimeout: DefaultShutdownTimeout,
+	}
+	if InCluster() {
+		s.DrainDelay = DefaultDrainDelay
+	}
+	for _, field := range []struct {
+		value string
+		dst   *time.Duration
+	}{
+		{cfg.ReloadInterval, &s.ReloadInterval},
+		{cfg.DrainDelay, &s.DrainDelay},
+		{cfg.ShutdownTimeout, &s.ShutdownTimeout},
+	} {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			return Settings{}, fmt.Errorf("invalid duration %q: %w", field.value, err)
+		}
+		*field.dst = d
+	}
+	return s, nil
+}
+
+// Pod describes the pod the connector runs in, as exposed through the
+// downward API in the POD_NAME, POD_NAMESPACE, NODE_NAME and POD_IP variables
+type Pod struct {
+	Name      string
+	Namespace string
+	Node      string
+	IP        string
+}
+
+// PodFromEnv reads the pod metadata from the environment
+func PodFromEnv() Pod {
+	return Pod{
+		Name:      os.Getenv("POD_NAME"),
+		Namespace: os.Getenv("POD_NAMESPACE"),
+		Node:      os.Getenv("NODE_NAME"),
+		IP:        os.Getenv("POD_IP"),
+	}
+}
+
+// LogAttrs returns the pod fields to add to every log line; empty when not on Kubernetes
+func (p Pod) LogAttrs() []any {
+	var args []any
+	if p.Name != "" {
+		args = append(args, logging.KeyPod, p.Name)
+	}
+	if p.Namespace != "" {
+		args = append(args, logging.KeyNamespace, p.Namespace)
+	}
+	if p.Node != "" {
+		args = append(args, logging.KeyNode, p.Node)
+	}
+	return args
+}
+
+// Identity returns the name identifying this replica, falling back to the host name
+func (p Pod) Identity() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	host, _ := os.Hostname()
+	return host
+}
+
+// InCluster reports whether the process runs inside a Kubernetes pod
+func InCluster() bool {
+	return os.Getenv("KUBERNETES_SERVICE_HOST") != ""
+}
+
+// WatchFile calls onChange whenever the content of path, a file or a config
+// directory, changes, checking every interval until ctx is done. Content is
+// compared rather than mtimes because ConfigMap volumes are updated by
+// swapping a symlink.
+func WatchFile(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	last, _ := fileSum(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sum, err := fileSum(path)
+		if err != nil || sum == last {
+			// A missing file is usually a symlink swap in progress
+			continue
+		}
+		last = sum
+		onChange()
+	}
+}
+
+// fileSum hashes the content of path; a directory is hashed over the names
+// and content of the files in it, leaving out hidden ones such as the
+// ..data link of a ConfigMap volume
+func fileSum(path string) ([sha256.Size]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	if !info.IsDir() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		return sha256.Sum256(data), nil
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	h := sha256.New()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(h, "%s\x00%x\n", name, sum)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
@@ -0,0 +1,185 @@
+package kube_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/kube"
+)
+
+// fakeLeaseAPI stores a single Lease and enforces resourceVersion checks
+type fakeLeaseAPI struct {
+	mu      sync.Mutex
+	lease   map[string]interface{}
+	version int
+}
+
+func (f *fakeLeaseAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var in map[string]interface{}
+	if r.Method != http.MethodGet {
+		json.NewDecoder(r.Body).Decode(&in)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if f.lease == nil {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+	case http.MethodPost:
+		if f.lease != nil {
+			http.Error(w, "exists", http.StatusConflict)
+			return
+		}
+		f.lease = in
+	case http.MethodPut:
+		meta := in["metadata"].(map[string]interface{})
+		if meta["resourceVersion"] != strconv.Itoa(f.version) {
+			http.Error(w, "conflict", http.StatusConflict)
+			return
+		}
+		f.lease = in
+	}
+	if r.Method != http.MethodGet {
+		f.version++
+		f.lease["metadata"].(map[string]interface{})["resourceVersion"] = strconv.Itoa(f.version)
+	}
+	json.NewEncoder(w).Encode(f.lease)
+}
+
+func (f *fakeLeaseAPI) holder() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lease["spec"].(map[string]interface{})["holderIdentity"].(string)
+}
+
+func TestLeaderElection(t *testing.T) {
+	api := &fakeLeaseAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	now := time.Now()
+	client := &kube.Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	cfg := &config.LeaderElectionConfig{LeaseName: "connector", Namespace: "legacy", LeaseDuration: "15s", RenewInterval: "5s"}
+
+	newElector := func(identity string) *kube.LeaderElector {
+		e, err := kube.NewLeaderElector(client, identity, cfg)
+		if err != nil {
+			t.Fatalf("Failed to create elector: %v", err)
+		}
+		e.Now = func() time.Time { return now }
+		return e
+	}
+	a, b := newElector("pod-a"), newElector("pod-b")
+
+	var changes []bool
+	a.OnChange = func(leader bool) { changes = append(changes, leader) }
+
+	if !a.TryAcquireOrRenew(context.Background()) {
+		t.Fatal("Expected first replica to create the lease and lead")
+	}
+	if b.TryAcquireOrRenew(context.Background()) || b.IsLeader() {
+		t.Error("Expected second replica to follow while the lease is valid")
+	}
+	if !a.TryAcquireOrRenew(context.Background()) || api.holder() != "pod-a" {
+		t.Error("Expected the leader to renew its lease")
+	}
+
+	// The follower takes over once the leader stops renewing
+	now = now.Add(20 * time.Second)
+	if !b.TryAcquireOrRenew(context.Background()) || api.holder() != "pod-b" {
+		t.Fatalf("Expected takeover of an expired lease, holder is %q", api.holder())
+	}
+	if a.TryAcquireOrRenew(context.Background()) {
+		t.Error("Expected the old leader to step down")
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("Unexpected leadership changes: %v", changes)
+	}
+
+	// Without election every replica runs singleton work
+	var none *kube.LeaderElector
+	if !none.IsLeader() {
+		t.Error("Expected a nil elector to lead")
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "connector.yaml")
+	if err := ioutil.WriteFile(path, []byte("locale: en\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go kube.WatchFile(ctx, path, 10*time.Millisecond, func() { changed <- struct{}{} })
+
+	// Rewriting the same content is not a change
+	time.Sleep(30 * time.Millisecond)
+	os.WriteFile(path, []byte("locale: en\n"), 0600)
+	select {
+	case <-changed:
+		t.Fatal("Expected unchanged content to be ignored")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	os.WriteFile(path, []byte("locale: de\n"), 0600)
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a content change to be reported")
+	}
+}
+
+func TestWatchDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "adapter.yaml"), []byte("locale: en\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go kube.WatchFile(ctx, dir, 10*time.Millisecond, func() { changed <- struct{}{} })
+
+	// Hidden files such as staged configs are not part of the config
+	time.Sleep(30 * time.Millisecond)
+	os.WriteFile(filepath.Join(dir, ".remote-adapter.yaml"), []byte("locale: de\n"), 0600)
+	select {
+	case <-changed:
+		t.Fatal("Expected hidden files to be ignored")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	os.WriteFile(filepath.Join(dir, "mappings.yaml"), []byte("mappings: []\n"), 0600)
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a new file to be reported")
+	}
+}
+
+func TestPodLogAttrs(t *testing.T) {
+	pod := kube.Pod{Name: "connector-0", Namespace: "legacy"}
+	attrs := pod.LogAttrs()
+	if len(attrs) != 4 || attrs[0] != "pod" || attrs[1] != "connector-0" || attrs[3] != "legacy" {
+		t.Errorf("Unexpected log attributes: %v", attrs)
+	}
+	if attrs := (kube.Pod{}).LogAttrs(); len(attrs) != 0 {
+		t.Errorf("Expected no attributes outside Kubernetes, got %v", attrs)
+	}
+}
@@ -0,0 +1,374 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// Service account files mounted into every pod
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenFile         = serviceAccountDir + "/token"
+	caFile            = serviceAccountDir + "/ca.crt"
+	namespaceFile     = serviceAccountDir + "/namespace"
+)
+
+// Defaults for leader election
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewInterval = 5 * time.Second
+)
+
+// microTime is the timestamp format of Lease fields
+const microTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// Client is a minimal Kubernetes API client for Lease objects
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	// TokenFile is re-read on every request since projected tokens rotate;
+	// empty sends no credentials
+	TokenFile string
+}
+
+// InClusterClient creates a client from the pod's service account
+func InClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running inside a Kubernetes cluster")
+	}
+
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	return &Client{
+		BaseURL: "https://" + net.JoinHostPort(host, port),
+		HTTPClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+		},
+		TokenFile: tokenFile,
+	}, nil
+}
+
+// Namespace returns the namespace of the pod's service account, or POD_NAMESPACE
+func Namespace() string {
+	if data, err := ioutil.ReadFile(namespaceFile); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	return os.Getenv("POD_NAMESPACE")
+}
+
+// lease is the subset of a coordination.k8s.io/v1 Lease used for election
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int    `json:"leaseTransitions"`
+}
+
+// errConflict means another replica updated the lease first
+var errConflict = errors.New("lease was updated concurrently")
+
+// errNotLeader means another replica holds a valid lease
+var errNotLeader = errors.New("lease is held by another replica")
+
+func (c *Client) leaseURL(namespace, name string) string {
+	url := c.BaseURL + "/apis/coordination.k8s.io/v1/namespaces/" + namespace + "/leases"
+	if name != "" {
+		url += "/" + name
+	}
+	return url
+}
+
+// do sends a request and decodes the lease in the response; a nil lease and
+// nil error mean the lease does not exist
+func (c *Client) do(ctx context.Context, method, url string, in *lease) (*lease, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.TokenFile != "" {
+		token, err := ioutil.ReadFile(c.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case resp.StatusCode == http.StatusConflict:
+		return nil, errConflict
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("%s %s returned %s: %s", method, url, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var out lease
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode lease: %w", err)
+	}
+	return &out, nil
+}
+
+// LeaderElector elects one replica as leader through a Lease object, so
+// singleton work such as polling triggers runs only once per deployment
+type LeaderElector struct {
+	Client        *Client
+	Namespace     string
+	LeaseName     string
+	Identity      string
+	LeaseDuration time.Duration
+	RenewInterval time.Duration
+
+	// OnChange, if set, is called when this replica gains or loses leadership
+	OnChange func(leader bool)
+
+	// Now can be replaced in tests
+	Now func() time.Time
+
+	mu        sync.Mutex
+	leader    bool
+	lastRenew time.Time
+}
+
+// NewLeaderElector creates an elector from config; nil config disables election
+func NewLeaderElector(client *Client, identity string, cfg *config.LeaderElectionConfig) (*LeaderElector, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	e := &LeaderElector{
+		Client:        client,
+		Namespace:     cfg.Namespace,
+		LeaseName:     cfg.LeaseName,
+		Identity:      identity,
+		LeaseDuration: DefaultLeaseDuration,
+		RenewInterval: DefaultRenewInterval,
+		Now:           time.Now,
+	}
+	if e.Namespace == "" {
+		e.Namespace = Namespace()
+	}
+	if cfg.LeaseDuration != "" {
+		d, err := time.ParseDuration(cfg.LeaseDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leaseDuration: %w", err)
+		}
+		e.LeaseDuration = d
+	}
+	if cfg.RenewInterval != "" {
+		d, err := time.ParseDuration(cfg.RenewInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid renewInterval: %w", err)
+		}
+		e.RenewInterval = d
+	}
+	if e.RenewInterval >= e.LeaseDuration {
+		return nil, fmt.Errorf("renewInterval must be shorter than leaseDuration")
+	}
+	return e, nil
+}
+
+// IsLeader reports whether this replica currently holds the lease. A nil
+// elector is always the leader, so singleton work runs without election.
+func (e *LeaderElector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Run tries to acquire or renew the lease every RenewInterval until ctx is
+// done, then releases it so another replica can take over without waiting
+func (e *LeaderElector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.RenewInterval)
+	defer ticker.Stop()
+	for {
+		e.TryAcquireOrRenew(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryAcquireOrRenew runs one election round and returns whether this replica leads.
+// Leadership is kept through API errors until the lease would have expired.
+func (e *LeaderElector) TryAcquireOrRenew(ctx context.Context) bool {
+	err := e.tryAcquireOrRenew(ctx)
+
+	e.mu.Lock()
+	now := e.Now()
+	leader := e.leader
+	switch {
+	case err == nil:
+		leader = true
+		e.lastRenew = now
+	case err == errNotLeader:
+		leader = false
+	default:
+		leader = leader && now.Sub(e.lastRenew) < e.LeaseDuration
+	}
+	changed := leader != e.leader
+	e.leader = leader
+	e.mu.Unlock()
+
+	if err != nil && err != errNotLeader {
+		slog.Warn("leader election round failed", "lease", e.LeaseName, logging.KeyError, err)
+	}
+	if changed && e.OnChange != nil {
+		e.OnChange(leader)
+	}
+	return leader
+}
+
+func (e *LeaderElector) tryAcquireOrRenew(ctx context.Context) error {
+	now := e.Now()
+	current, err := e.Client.do(ctx, http.MethodGet, e.Client.leaseURL(e.Namespace, e.LeaseName), nil)
+	if err != nil {
+		return err
+	}
+
+	if current == nil {
+		_, err := e.Client.do(ctx, http.MethodPost, e.Client.leaseURL(e.Namespace, ""), &lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   leaseMetadata{Name: e.LeaseName, Namespace: e.Namespace},
+			Spec: leaseSpec{
+				HolderIdentity:       e.Identity,
+				LeaseDurationSeconds: e.leaseSeconds(),
+				AcquireTime:          now.UTC().Format(microTime),
+				RenewTime:            now.UTC().Format(microTime),
+			},
+		})
+		if err == errConflict {
+			return errNotLeader
+		}
+		return err
+	}
+
+	spec := &current.Spec
+	if spec.HolderIdentity != e.Identity && spec.HolderIdentity != "" && !expired(spec, now) {
+		return errNotLeader
+	}
+	if spec.HolderIdentity != e.Identity {
+		spec.HolderIdentity = e.Identity
+		spec.AcquireTime = now.UTC().Format(microTime)
+		spec.LeaseTransitions++
+	}
+	spec.LeaseDurationSeconds = e.leaseSeconds()
+	spec.RenewTime = now.UTC().Format(microTime)
+
+	// The resourceVersion makes the update fail if another replica won the race
+	_, err = e.Client.do(ctx, http.MethodPut, e.Client.leaseURL(e.Namespace, e.LeaseName), current)
+	if err == errConflict {
+		return errNotLeader
+	}
+	return err
+}
+
+// release gives up the lease on shutdown by clearing the holder
+func (e *LeaderElector) release() {
+	if !e.IsLeader() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.RenewInterval)
+	defer cancel()
+	current, err := e.Client.do(ctx, http.MethodGet, e.Client.leaseURL(e.Namespace, e.LeaseName), nil)
+	if err == nil && current != nil && current.Spec.HolderIdentity == e.Identity {
+		current.Spec.HolderIdentity = ""
+		e.Client.do(ctx, http.MethodPut, e.Client.leaseURL(e.Namespace, e.LeaseName), current)
+	}
+
+	e.mu.Lock()
+	e.leader = false
+	e.mu.Unlock()
+	if e.OnChange != nil {
+		e.OnChange(false)
+	}
+}
+
+func (e *LeaderElector) leaseSeconds() int {
+	seconds := int(e.LeaseDuration / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// expired reports whether the holder failed to renew the lease in time
+func expired(spec *leaseSpec, now time.Time) bool {
+	renew, err := time.Parse(microTime, spec.RenewTime)
+	if err != nil {
+		if renew, err = time.Parse(time.RFC3339, spec.RenewTime); err != nil {
+			return true
+		}
+	}
+	return now.After(renew.Add(time.Duration(spec.LeaseDurationSeconds) * time.Second))
+}
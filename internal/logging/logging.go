@@ -0,0 +1,134 @@
+// Package logging configures the structured logger shared by the connector
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+)
+
+// Output formats
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// Field names used to correlate a task across transform, proxy and adapter
+const (
+	KeyConnectorID = "connector_id"
+	KeyTaskID      = "task_id"
+	KeyMappingID   = "mapping_id"
+	KeyAdapter     = "adapter"
+	KeyLatency     = "latency_ms"
+	KeyError       = "error"
+)
+
+// Field names for the Kubernetes pod the connector runs in
+const (
+	KeyPod       = "pod"
+	KeyNamespace = "namespace"
+	KeyNode      = "node"
+)
+
+// ParseLevel parses debug, info, warn or error; empty means info
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unsupported log level %q", level)
+	}
+}
+
+// New creates a logger writing to w in the configured level and format
+func New(w io.Writer, cfg config.LoggingConfig) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactSecrets}
+
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatConsole, "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %q", cfg.Format)
+	}
+}
+
+// redactSecrets masks secret values and credential patterns in string, error
+// and Stringer attributes, and the whole value of attributes named like a
+// credential, e.g. "password" or "authorization"
+func redactSecrets(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && secrets.SensitiveKey(a.Key) {
+		a.Value = slog.StringValue(secrets.Mask)
+		return a
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(secrets.Redact(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(secrets.Redact(v.Error()))
+		case fmt.Stringer:
+			a.Value = slog.StringValue(secrets.Redact(v.String()))
+		}
+	}
+	return a
+}
+
+// Setup creates a logger on stderr with the given fields and makes it the
+// default, so the standard log package writes through it as well
+func Setup(cfg config.LoggingConfig, args ...any) (*slog.Logger, error) {
+	logger, err := New(os.Stderr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	logger = logger.With(args...)
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+type loggerKey struct{}
+
+// WithContext returns a context carrying logger
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Latency returns the latency field for d in milliseconds
+func Latency(d time.Duration) slog.Attr {
+	return slog.Float64(KeyLatency, float64(d.Microseconds())/1000)
+}
+
+// Middleware makes logger available to handlers through the request context
+func Middleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithContext(r.Context(), logger)))
+	})
+}
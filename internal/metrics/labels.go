@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// DefaultMaxLabelValues bounds the distinct values tracked per metadata label
+const DefaultMaxLabelValues = 50
+
+// OverflowLabelValue replaces label values beyond the cardinality limit
+const OverflowLabelValue = "other"
+
+// invalidLabelChars matches characters not allowed in Prometheus label names
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// MetadataLabels turns configured task metadata keys into metric labels.
+// Each label tracks at most MaxValues distinct values; further values are
+// reported as OverflowLabelValue so a free-form field cannot explode the
+// number of series.
+type MetadataLabels struct {
+	Keys      []string
+	MaxValues int
+
+	mu   sync.Mutex
+	seen []map[string]bool
+}
+
+// NewMetadataLabels creates labels for the metadata keys; keys may be dotted paths
+func NewMetadataLabels(keys []string, maxValues int) *MetadataLabels {
+	if maxValues <= 0 {
+		maxValues = DefaultMaxLabelValues
+	}
+	seen := make([]map[string]bool, len(keys))
+	for i := range seen {
+		seen[i] = make(map[string]bool)
+	}
+	return &MetadataLabels{Keys: keys, MaxValues: maxValues, seen: seen}
+}
+
+// Names returns the metric label names for the keys
+func (l *MetadataLabels) Names() []string {
+	if l == nil {
+		return nil
+	}
+	names := make([]string, len(l.Keys))
+	for i, key := range l.Keys {
+		names[i] = LabelName(key)
+	}
+	return names
+}
+
+// Values returns the label values found in the task metadata, in key order
+func (l *MetadataLabels) Values(metadata map[string]interface{}) []string {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	values := make([]string, len(l.Keys))
+	for i, key := range l.Keys {
+		value := lookup(metadata, key)
+		if value == "" {
+			continue
+		}
+		if !l.seen[i][value] {
+			if len(l.seen[i]) >= l.MaxValues {
+				value = OverflowLabelValue
+			} else {
+				l.seen[i][value] = true
+			}
+		}
+		values[i] = value
+	}
+	return values
+}
+
+// Fields returns the label values keyed by label name, for audit records
+func (l *MetadataLabels) Fields(values []string) map[string]string {
+	if l == nil || len(values) == 0 {
+		return nil
+	}
+	fields := make(map[string]string)
+	for i, name := range l.Names() {
+		if values[i] != "" {
+			fields[name] = values[i]
+		}
+	}
+	return fields
+}
+
+// LabelName converts a metadata key into a valid metric label name
+func LabelName(key string) string {
+	name := invalidLabelChars.ReplaceAllString(key, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// lookup returns the metadata value at a dotted path as a string
+func lookup(metadata map[string]interface{}, path string) string {
+	var current interface{} = metadata
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = m[part]
+	}
+	if current == nil {
+		return ""
+	}
+	switch current.(type) {
+	case map[string]interface{}, []interface{}:
+		return ""
+	}
+	return fmt.Sprintf("%v", current)
+}
+
+// TaskMetrics records task counts and latencies broken down by mapping,
+// outcome, and the configured metadata labels
+type TaskMetrics struct {
+	Labels   *MetadataLabels
+	Tasks    *CounterVec
+	Duration *HistogramVec
+}
+
+// NewTaskMetrics registers the task metrics on the registry
+func NewTaskMetrics(registry *Registry, cfg config.MetricsConfig) *TaskMetrics {
+	labels := NewMetadataLabels(cfg.Labels, cfg.MaxLabelValues)
+	names := append([]string{"mapping", "status"}, labels.Names()...)
+
+	return &TaskMetrics{
+		Labels:   labels,
+		Tasks:    registry.NewCounterVec("connector_tasks_total", "Tasks handled by the connector.", names),
+		Duration: registry.NewHistogramVec("connector_task_duration_seconds", "Time spent executing tasks against the legacy system.", names, nil),
+	}
+}
+
+// Observe records a finished task; labels are the values returned by Labels.Values
+func (m *TaskMetrics) Observe(mapping, status string, labels []string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	labelValues := append([]string{mapping, status}, labels...)
+	m.Tasks.Inc(labelValues...)
+	m.Duration.Observe(duration.Seconds(), labelValues...)
+}
+
+// Totals returns the number of tasks handled per status
+func (m *TaskMetrics) Totals() map[string]float64 {
+	if m == nil {
+		return nil
+	}
+	return m.Tasks.SumBy("status")
+}
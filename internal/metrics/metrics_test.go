@@ -0,0 +1,84 @@
+package metrics_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/metrics"
+)
+
+func TestTaskMetricsLabels(t *testing.T) {
+	registry := metrics.NewRegistry()
+	tm := metrics.NewTaskMetrics(registry, config.MetricsConfig{
+		Labels:         []string{"department", "agent.name"},
+		MaxLabelValues: 2,
+	})
+
+	metadata := map[string]interface{}{
+		"department": "finance",
+		"agent":      map[string]interface{}{"name": "Billing Bot"},
+	}
+	labels := tm.Labels.Values(metadata)
+	if len(labels) != 2 || labels[0] != "finance" || labels[1] != "Billing Bot" {
+		t.Fatalf("Unexpected label values: %v", labels)
+	}
+	tm.Observe("get.*invoice", "success", labels, 120*time.Millisecond)
+
+	fields := tm.Labels.Fields(labels)
+	if fields["department"] != "finance" || fields["agent_name"] != "Billing Bot" {
+		t.Errorf("Unexpected audit fields: %v", fields)
+	}
+
+	// The cardinality guard folds new values into "other"
+	tm.Labels.Values(map[string]interface{}{"department": "sales"})
+	labels = tm.Labels.Values(map[string]interface{}{"department": "legal"})
+	if labels[0] != metrics.OverflowLabelValue {
+		t.Errorf("Expected overflow value, got %q", labels[0])
+	}
+	if labels = tm.Labels.Values(map[string]interface{}{"department": "finance"}); labels[0] != "finance" {
+		t.Errorf("Expected known value to be kept, got %q", labels[0])
+	}
+
+	// Missing metadata yields empty label values
+	labels = tm.Labels.Values(nil)
+	tm.Observe("get.*invoice", "error", labels, time.Second)
+
+	var buf bytes.Buffer
+	registry.Write(&buf)
+	out := buf.String()
+
+	for _, expected := range []string{
+		"# TYPE connector_tasks_total counter",
+		`connector_tasks_total{mapping="get.*invoice",status="success",department="finance",agent_name="Billing Bot"} 1`,
+		`connector_tasks_total{mapping="get.*invoice",status="error",department="",agent_name=""} 1`,
+		`connector_task_duration_seconds_bucket{mapping="get.*invoice",status="success",department="finance",agent_name="Billing Bot",le="0.25"} 1`,
+		`connector_task_duration_seconds_bucket{mapping="get.*invoice",status="success",department="finance",agent_name="Billing Bot",le="0.1"} 0`,
+		`connector_task_duration_seconds_count{mapping="get.*invoice",status="error",department="",agent_name=""} 1`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected output to contain %q\n%s", expected, out)
+		}
+	}
+
+	tm.Observe("list.*invoices", "success", labels, time.Second)
+	if totals := tm.Totals(); len(totals) != 2 || totals["success"] != 2 || totals["error"] != 1 {
+		t.Errorf("Unexpected totals %v", totals)
+	}
+}
+
+func TestLabelName(t *testing.T) {
+	tests := map[string]string{
+		"department":  "department",
+		"agent.name":  "agent_name",
+		"cost-center": "cost_center",
+		"1st":         "_1st",
+	}
+	for key, expected := range tests {
+		if got := metrics.LabelName(key); got != expected {
+			t.Errorf("LabelName(%q) = %q, want %q", key, got, expected)
+		}
+	}
+}
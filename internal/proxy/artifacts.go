@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// base64Encodings are the encodings accepted for file content, so legacy
+// systems that use URL-safe or unpadded base64 need no transform
+var base64Encodings = []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding}
+
+// buildArtifacts builds the artifacts of a task from the fields of the legacy
+// response named by the artifact configs. Artifacts whose source is missing
+// are left out; file content that is not valid base64 is logged and left out.
+func buildArtifacts(artifacts []config.ArtifactConfig, legacyResponse map[string]interface{}) []interface{} {
+	var result []interface{}
+	for _, artifact := range artifacts {
+		value := getValueByPath(legacyResponse, artifact.Source)
+		if value == nil {
+			continue
+		}
+		part, err := artifactPart(artifact, value, legacyResponse)
+		if err != nil {
+			slog.Warn("failed to build artifact", "source", artifact.Source, logging.KeyError, err)
+			continue
+		}
+
+		built := map[string]interface{}{
+			"parts": []interface{}{part},
+			"index": len(result),
+		}
+		if name := renderArtifactField(artifact.Name, legacyResponse); name != "" {
+			built["name"] = name
+		}
+		if artifact.Description != "" {
+			built["description"] = artifact.Description
+		}
+		result = append(result, built)
+	}
+	return result
+}
+
+// artifactPart builds the part holding the value of an artifact
+func artifactPart(artifact config.ArtifactConfig, value interface{}, legacyResponse map[string]interface{}) (map[string]interface{}, error) {
+	switch artifact.Type {
+	case "text":
+		return map[string]interface{}{"type": "text", "text": stringValue(value)}, nil
+	case "file":
+		file := make(map[string]interface{})
+		if name := renderArtifactField(artifact.FileName, legacyResponse); name != "" {
+			file["name"] = name
+		}
+		if mimeType := renderArtifactField(artifact.MimeType, legacyResponse); mimeType != "" {
+			file["mimeType"] = mimeType
+		}
+		switch artifact.Encoding {
+		case "uri":
+			file["uri"] = stringValue(value)
+		case "text":
+			file["bytes"] = base64.StdEncoding.EncodeToString([]byte(stringValue(value)))
+		default:
+			content, err := decodeBase64(value)
+			if err != nil {
+				return nil, err
+			}
+			file["bytes"] = base64.StdEncoding.EncodeToString(content)
+		}
+		return map[string]interface{}{"type": "file", "file": file}, nil
+	}
+
+	// Data parts hold an object; other values are wrapped in one
+	data, ok := value.(map[string]interface{})
+	if !ok {
+		data = map[string]interface{}{"value": value}
+	}
+	return map[string]interface{}{"type": "data", "data": data}, nil
+}
+
+// decodeBase64 decodes base64 file content in any of the common alphabets
+func decodeBase64(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("file content is a %T, not a base64 string", value)
+	}
+	for _, encoding := range base64Encodings {
+		if content, err := encoding.DecodeString(s); err == nil {
+			return content, nil
+		}
+	}
+	return nil, fmt.Errorf("file content is not valid base64")
+}
+
+// stringValue returns strings as is and other values as JSON
+func stringValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// renderArtifactField renders a name or media type template over the legacy
+// response; a template that fails to render is used as is
+func renderArtifactField(text string, legacyResponse map[string]interface{}) string {
+	rendered, err := renderTemplate(text, legacyResponse)
+	if err != nil {
+		return text
+	}
+	return rendered
+}
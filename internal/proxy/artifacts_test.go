@@ -0,0 +1,68 @@
+package proxy_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestResponseArtifacts(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `get invoice`,
+			Endpoint:      "/invoices/42",
+			Method:        "GET",
+			ResponseTransform: config.ResponseTransform{
+				Artifacts: []config.ArtifactConfig{
+					{Name: "invoice", Source: "result.pdf", Type: "file", FileName: "invoice-{{.result.number}}.pdf", MimeType: "application/pdf"},
+					{Name: "summary", Source: "result.totals"},
+					{Name: "notes", Source: "result.notes", Type: "file", Encoding: "text", MimeType: "text/plain"},
+					{Name: "scan", Source: "result.scanUrl", Type: "file", Encoding: "uri"},
+					{Name: "missing", Source: "result.attachment", Type: "file"},
+				},
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	pdf := []byte("%PDF-1.4 invoice")
+	_, task := roundTrip(t, ct, `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"get invoice"}]}}}`, map[string]interface{}{
+		"number":  "INV-42",
+		"pdf":     base64.URLEncoding.EncodeToString(pdf),
+		"totals":  map[string]interface{}{"net": 100, "gross": 119},
+		"notes":   "Paid in full",
+		"scanUrl": "https://erp.example.com/scans/42",
+	})
+
+	artifacts, _ := task["artifacts"].([]interface{})
+	if len(artifacts) != 4 {
+		t.Fatalf("Expected 4 artifacts, got %v", task["artifacts"])
+	}
+	part := func(i int) map[string]interface{} {
+		return artifacts[i].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})
+	}
+
+	invoice := artifacts[0].(map[string]interface{})
+	file := part(0)["file"].(map[string]interface{})
+	if invoice["name"] != "invoice" || part(0)["type"] != "file" || file["name"] != "invoice-INV-42.pdf" || file["mimeType"] != "application/pdf" {
+		t.Errorf("Unexpected file artifact %v", invoice)
+	}
+	if file["bytes"] != base64.StdEncoding.EncodeToString(pdf) {
+		t.Errorf("Expected the file content in standard base64, got %v", file["bytes"])
+	}
+	if data := part(1)["data"].(map[string]interface{}); part(1)["type"] != "data" || data["gross"] != float64(119) {
+		t.Errorf("Unexpected data artifact %v", part(1))
+	}
+	if bytes := part(2)["file"].(map[string]interface{})["bytes"]; bytes != base64.StdEncoding.EncodeToString([]byte("Paid in full")) {
+		t.Errorf("Expected text content to be encoded, got %v", bytes)
+	}
+	if uri := part(3)["file"].(map[string]interface{})["uri"]; uri != "https://erp.example.com/scans/42" {
+		t.Errorf("Expected a file link, got %v", part(3))
+	}
+}
@@ -0,0 +1,225 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+)
+
+// asyncMetaKey is the legacy request meta key holding the job polling of an
+// async mapping; after the job ran it holds its ID, polls and outcome
+const asyncMetaKey = "async"
+
+// Defaults of the job polling of async mappings
+const (
+	DefaultPollInterval = 5 * time.Second
+	DefaultJobTimeout   = 10 * time.Minute
+)
+
+// Job outcomes
+const (
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobTimedOut = "timeout"
+)
+
+// JobError is returned when the job of an async mapping fails, times out or
+// cannot be polled
+type JobError struct {
+	JobID   string
+	Polls   int
+	Outcome string
+	Err     error
+}
+
+func (e *JobError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("job %s: %v", e.JobID, e.Err)
+	case e.Outcome == JobTimedOut:
+		return fmt.Sprintf("job %s did not finish after %d polls", e.JobID, e.Polls)
+	}
+	return fmt.Sprintf("job %s failed", e.JobID)
+}
+
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+// Details implements adapter.DetailedError with the job and its polls
+func (e *JobError) Details() map[string]interface{} {
+	details := map[string]interface{}{"jobId": e.JobID, "polls": e.Polls}
+	if e.Outcome != "" {
+		details["outcome"] = e.Outcome
+	}
+	return details
+}
+
+// JobProgress is the state of a job when it started and after each poll;
+// the next poll is Interval later
+type JobProgress struct {
+	JobID    string
+	Polls    int
+	Interval time.Duration
+	Result   map[string]interface{}
+	// Text is the rendered progress template of the mapping, if any
+	Text string
+}
+
+// ProgressFunc receives the progress of the job of a task when it started
+// and after each poll
+type ProgressFunc func(JobProgress)
+
+// progressKey carries the ProgressFunc of a task in a context
+type progressKey struct{}
+
+// WithProgress returns a context whose async tasks report their progress to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// asyncMeta returns the job polling of a mapping for the legacy request
+// meta; calls without a method use the method of the mapping
+func asyncMeta(mapping *config.MappingConfig) config.AsyncConfig {
+	async := *mapping.Async
+	calls := []config.StepConfig{async.Poll}
+	if async.Result != nil {
+		calls = append(calls, *async.Result)
+	}
+	calls = stepsMeta(mapping, calls)
+	async.Poll = calls[0]
+	if async.Poll.Name == "" {
+		async.Poll.Name = "poll"
+	}
+	if async.Result != nil {
+		async.Result = &calls[1]
+		if async.Result.Name == "" {
+			async.Result.Name = "result"
+		}
+	}
+	return async
+}
+
+// HasAsync reports whether a legacy request meta holds the job polling of an
+// async mapping
+func HasAsync(meta map[string]interface{}) bool {
+	return meta[asyncMetaKey] != nil
+}
+
+// RunAsync makes the legacy call of an async mapping, which starts a job, and
+// polls the job as the meta says until it is done, then returns the result of
+// the job. A job that fails or times out returns a JobError. Meta is updated
+// with the job ID, the number of polls and the outcome.
+func RunAsync(ctx context.Context, meta map[string]interface{}, action string, params map[string]interface{}, exec StepExecutor) (map[string]interface{}, error) {
+	var async config.AsyncConfig
+	data, _ := json.Marshal(meta[asyncMetaKey])
+	if err := json.Unmarshal(data, &async); err != nil {
+		return nil, fmt.Errorf("invalid async: %w", err)
+	}
+	interval, timeout := DefaultPollInterval, DefaultJobTimeout
+	if d, err := time.ParseDuration(async.Interval); err == nil {
+		interval = d
+	}
+	if d, err := time.ParseDuration(async.Timeout); err == nil {
+		timeout = d
+	}
+	done, err := parseOptionalExpr(async.Done)
+	if err != nil {
+		return nil, err
+	}
+	failed, err := parseOptionalExpr(async.Failed)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := exec(ctx, StepCall{Name: "start", Action: action, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	id := getValueByPath(start, async.JobID)
+	if id == nil {
+		return start, fmt.Errorf("legacy response has no job ID at %s", async.JobID)
+	}
+	jobID := stringValue(id)
+	status := map[string]interface{}{"jobId": jobID, "polls": 0}
+	defer func() { meta[asyncMetaKey] = status }()
+
+	scope := map[string]interface{}{"params": params, "job": jobID, "start": start}
+	lookup := func(path string) interface{} { return getValueByPath(scope, path) }
+	progress, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	report := func(polls int, result map[string]interface{}) {
+		if progress == nil {
+			return
+		}
+		p := JobProgress{JobID: jobID, Polls: polls, Interval: interval, Result: result}
+		// The progress template describes poll results; the start of the job
+		// gets the default text
+		if async.Progress != "" && polls > 0 {
+			p.Text, _ = renderTemplate(async.Progress, scope)
+		}
+		progress(p)
+	}
+	report(0, start)
+
+	polls := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, &JobError{JobID: jobID, Polls: polls, Err: ctx.Err()}
+		case <-deadline.C:
+			status["outcome"] = JobTimedOut
+			return nil, &JobError{JobID: jobID, Polls: polls, Outcome: JobTimedOut}
+		case <-time.After(interval):
+		}
+
+		call, err := renderStep(async.Poll, scope)
+		if err != nil {
+			return nil, &JobError{JobID: jobID, Polls: polls, Err: err}
+		}
+		result, err := call.run(ctx, exec)
+		polls++
+		status["polls"] = polls
+		if err != nil {
+			return nil, &JobError{JobID: jobID, Polls: polls, Err: err}
+		}
+		scope["result"] = result
+
+		if failed != nil && failed.Match(lookup) {
+			status["outcome"] = JobFailed
+			return result, &JobError{JobID: jobID, Polls: polls, Outcome: JobFailed}
+		}
+		if done == nil || done.Match(lookup) {
+			break
+		}
+		report(polls, result)
+	}
+	status["outcome"] = JobDone
+
+	if async.Result == nil {
+		return scope["result"].(map[string]interface{}), nil
+	}
+	call, err := renderStep(*async.Result, scope)
+	if err != nil {
+		return nil, &JobError{JobID: jobID, Polls: polls, Err: err}
+	}
+	result, err := call.run(ctx, exec)
+	if err != nil {
+		return nil, &JobError{JobID: jobID, Polls: polls, Err: err}
+	}
+	return result, nil
+}
+
+// parseOptionalExpr parses an expression; an empty one is nil
+func parseOptionalExpr(text string) (*expr.Expr, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return expr.Parse(text)
+}
@@ -0,0 +1,105 @@
+package proxy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestRunAsync(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `run report`,
+			Endpoint:      "/reports",
+			Method:        "POST",
+			Async: &config.AsyncConfig{
+				JobID:    "job.id",
+				Poll:     config.StepConfig{Endpoint: "/jobs/{{.job}}", Method: "GET"},
+				Interval: "1ms",
+				Timeout:  "50ms",
+				Done:     "result.state == 'done'",
+				Failed:   "result.state == 'error'",
+				Result:   &config.StepConfig{Endpoint: "/jobs/{{.job}}/output"},
+				Progress: "{{.result.percent}}% done",
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected a valid config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	run := func(states ...string) (map[string]interface{}, map[string]interface{}, []proxy.StepCall, []proxy.JobProgress, error) {
+		request := transformTask(t, ct, textTask("t1", "run report"))
+		meta := request["meta"].(map[string]interface{})
+		if !proxy.HasAsync(meta) {
+			t.Fatal("Expected the job polling in the request meta")
+		}
+
+		var calls []proxy.StepCall
+		var progress []proxy.JobProgress
+		ctx := proxy.WithProgress(context.Background(), func(p proxy.JobProgress) { progress = append(progress, p) })
+		result, err := proxy.RunAsync(ctx, meta, request["action"].(string), request["params"].(map[string]interface{}), func(ctx context.Context, call proxy.StepCall) (map[string]interface{}, error) {
+			calls = append(calls, call)
+			switch call.Name {
+			case "start":
+				return map[string]interface{}{"job": map[string]interface{}{"id": "J7"}}, nil
+			case "poll":
+				state := states[0]
+				if len(states) > 1 {
+					states = states[1:]
+				}
+				if state == "unreachable" {
+					return nil, errors.New("connection refused")
+				}
+				return map[string]interface{}{"state": state, "percent": 50.0}, nil
+			}
+			return map[string]interface{}{"rows": 3.0}, nil
+		})
+		return result, meta, calls, progress, err
+	}
+
+	// The job is polled until done, then its result fetched
+	result, meta, calls, progress, err := run("running", "running", "done")
+	if err != nil {
+		t.Fatalf("Expected the job to finish: %v", err)
+	}
+	if result["rows"] != 3.0 {
+		t.Errorf("Expected the fetched result, got %v", result)
+	}
+	if len(calls) != 5 || calls[1].Endpoint != "/jobs/J7" || calls[1].Action != "GET" || calls[4].Endpoint != "/jobs/J7/output" || calls[4].Action != "POST" {
+		t.Errorf("Unexpected calls %+v", calls)
+	}
+	if len(progress) != 3 || progress[0].Polls != 0 || progress[2].Polls != 2 || progress[2].Text != "50% done" {
+		t.Errorf("Unexpected progress %+v", progress)
+	}
+	data, _ := json.Marshal(meta["async"])
+	if string(data) != `{"jobId":"J7","outcome":"done","polls":3}` {
+		t.Errorf("Unexpected job meta %s", data)
+	}
+
+	// A failed job fails the task with its details
+	_, _, _, _, err = run("running", "error")
+	var jobErr *proxy.JobError
+	if !errors.As(err, &jobErr) || jobErr.Outcome != proxy.JobFailed || jobErr.Polls != 2 {
+		t.Errorf("Expected the job to fail, got %v", err)
+	}
+
+	// So does a job that does not finish in time, or cannot be polled
+	_, _, _, _, err = run("running")
+	if !errors.As(err, &jobErr) || jobErr.Outcome != proxy.JobTimedOut {
+		t.Errorf("Expected the job to time out, got %v", err)
+	}
+	_, _, _, _, err = run("unreachable")
+	if !errors.As(err, &jobErr) || jobErr.Err == nil {
+		t.Errorf("Expected the poll error, got %v", err)
+	}
+}
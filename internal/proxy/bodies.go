@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+)
+
+// StreamFunc transforms a body as it is read, so bodies too large to buffer
+// can be transformed; the returned reader is read once
+type StreamFunc func(io.Reader) io.Reader
+
+// binaryMediaTypes are media types passed through untransformed; binaryMediaPrefixes
+// match whole families of them
+var (
+	binaryMediaTypes = map[string]bool{
+		"application/octet-stream": true,
+		"application/pdf":          true,
+		"application/zip":          true,
+		"application/gzip":         true,
+		"application/x-gzip":       true,
+		"application/x-tar":        true,
+	}
+	binaryMediaPrefixes = []string{"image/", "audio/", "video/", "font/"}
+)
+
+// IsBinary reports whether a body with the Content-Type of header is binary
+// content, such as a file download, that the transforms pass through
+func IsBinary(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	if binaryMediaTypes[mediaType] {
+		return true
+	}
+	for _, prefix := range binaryMediaPrefixes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedReader fails with an adapter.BodyTooLargeError once more than limit
+// bytes were read, unlike io.LimitReader, which ends the body silently
+type limitedReader struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+func newLimitedReader(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return &limitedReader{r: r, limit: limit, remaining: limit}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, &adapter.BodyTooLargeError{Limit: l.limit}
+	}
+	// Reading one byte past the limit tells a body of exactly limit bytes
+	// from a larger one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), &adapter.BodyTooLargeError{Limit: l.limit}
+	}
+	return n, err
+}
+
+// streamBody is a transformed body that closes its source
+type streamBody struct {
+	io.Reader
+	src io.Closer
+}
+
+func (b *streamBody) Close() error {
+	return b.src.Close()
+}
+
+// readBody buffers body up to limit bytes and closes it. A larger body is
+// instead returned as rest, a reader yielding the whole of it.
+func readBody(body io.ReadCloser, limit int64) (data []byte, rest io.ReadCloser, err error) {
+	if limit <= 0 {
+		data, err = ioutil.ReadAll(body)
+		body.Close()
+		return data, nil, err
+	}
+	data, err = ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil || int64(len(data)) <= limit {
+		body.Close()
+		return data, nil, err
+	}
+	return nil, &streamBody{Reader: io.MultiReader(bytes.NewReader(data), body), src: body}, nil
+}
+
+// streamRequest replaces the request body with its stream transform
+func (t *Transformer) streamRequest(req *http.Request) {
+	req.Body = &streamBody{Reader: t.requestStream(newLimitedReader(req.Body, t.maxBodySize)), src: req.Body}
+	req.ContentLength = -1
+	req.Header.Del("Content-Length")
+}
+
+// streamResponse replaces the response body with its stream transform
+func (t *Transformer) streamResponse(resp *http.Response) {
+	resp.Body = &streamBody{Reader: t.responseStream(newLimitedReader(resp.Body, t.maxBodySize)), src: resp.Body}
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+}
@@ -0,0 +1,123 @@
+package proxy_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+// upperReader upper-cases a body as it is read
+type upperReader struct{ r io.Reader }
+
+func (u upperReader) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	copy(p, bytes.ToUpper(p[:n]))
+	return n, err
+}
+
+func newResponse(contentType string, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
+
+func TestTransformerLargeBodies(t *testing.T) {
+	large := bytes.Repeat([]byte("record,"), 64*1024)
+
+	transformer := proxy.NewTransformer()
+	transformer.SetMaxBodySize(1024)
+	transformer.SetResponseTransform(func(data []byte) ([]byte, error) {
+		return nil, errors.New("not JSON")
+	})
+
+	// Binary downloads pass through untouched, whatever their size
+	resp := newResponse("application/octet-stream", large)
+	if err := transformer.TransformResponse(resp); err != nil {
+		t.Fatalf("Expected a binary body to pass through: %v", err)
+	}
+	if data, _ := ioutil.ReadAll(resp.Body); !bytes.Equal(data, large) || resp.ContentLength != int64(len(large)) {
+		t.Errorf("Expected the binary body unchanged, got %d bytes", len(data))
+	}
+
+	// Other bodies over the limit are not buffered for the transform
+	var tooLarge *adapter.BodyTooLargeError
+	if err := transformer.TransformResponse(newResponse("application/json", large)); !errors.As(err, &tooLarge) || tooLarge.Limit != 1024 {
+		t.Errorf("Expected a body too large error, got %v", err)
+	}
+
+	// Request bodies over the limit are forwarded untransformed
+	transformer.SetRequestTransform(func(data []byte) ([]byte, error) {
+		return []byte("transformed"), nil
+	})
+	req, _ := http.NewRequest(http.MethodPost, "http://legacy/upload", bytes.NewReader(large))
+	transformer.TransformRequest(req)
+	if data, _ := ioutil.ReadAll(req.Body); !bytes.Equal(data, large) {
+		t.Errorf("Expected the whole request body forwarded, got %d bytes", len(data))
+	}
+
+	// Stream transforms apply as the body is read and fail past the limit
+	transformer.SetResponseStreamTransform(func(r io.Reader) io.Reader { return upperReader{r} })
+	resp = newResponse("text/csv", []byte("id,name\n1,widget\n"))
+	if err := transformer.TransformResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadAll(resp.Body); string(data) != "ID,NAME\n1,WIDGET\n" || resp.ContentLength != -1 {
+		t.Errorf("Unexpected streamed body %q", data)
+	}
+	resp = newResponse("text/csv", large)
+	transformer.TransformResponse(resp)
+	data, err := ioutil.ReadAll(resp.Body)
+	if !errors.As(err, &tooLarge) || len(data) != 1024 || !strings.HasPrefix(string(data), "RECORD,") {
+		t.Errorf("Expected the stream to stop at the limit, got %d bytes and %v", len(data), err)
+	}
+}
+
+func TestProxyMaxRequestBody(t *testing.T) {
+	var calls int
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		ioutil.ReadAll(r.Body)
+	}))
+	defer backend.Close()
+
+	p, err := proxy.NewProxy(backend.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.MaxRequestBody = 1024
+	p.Breaker = resilience.NewCircuitBreaker("legacy")
+	p.Breaker.FailureThreshold = 1
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(make([]byte, 512))))
+	if rec.Code != http.StatusOK || calls != 1 {
+		t.Fatalf("Expected a body under the limit to be forwarded, got %d", rec.Code)
+	}
+
+	// Bodies over the limit are rejected whether or not their size is declared
+	for _, body := range []io.Reader{bytes.NewReader(make([]byte, 2048)), io.MultiReader(bytes.NewReader(make([]byte, 2048)))} {
+		rec = httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload", body))
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected 413, got %d: %s", rec.Code, rec.Body)
+		}
+	}
+	if calls > 2 {
+		t.Errorf("Expected a body with a declared size over the limit not to be forwarded, got %d calls", calls)
+	}
+	if p.Breaker.State() != resilience.StateClosed {
+		t.Error("Expected rejected bodies not to count against the breaker")
+	}
+}
@@ -4,18 +4,41 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/egress"
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+	"github.com/A2AGateway/a2a-connector/internal/intent"
+	"github.com/A2AGateway/a2a-connector/internal/jsonpath"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
 	a2a "github.com/A2AGateway/a2a-protocol"
 )
 
+// cacheKeyPattern matches {param} placeholders in cache key templates
+var cacheKeyPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
 // ConfigTransformer is a transformer that uses configuration to transform requests and responses
 type ConfigTransformer struct {
 	Config     *config.ConnectorConfig
+	Messages   *i18n.Catalog
+	Unmatched  *UnmatchedLog
+	Coverage   *Coverage
+	Egress     *egress.Policy
+	Intent     *intent.Router
+	Tasks      *TaskStore
+	Sessions   *SessionStore
+	// FileClient fetches the uri of FileParts; nil uses a client with a
+	// 30 second timeout
+	FileClient *http.Client
 	Transformer
 }
 
@@ -23,9 +46,29 @@ type ConfigTransformer struct {
 func NewConfigTransformer(cfg *config.ConnectorConfig) *ConfigTransformer {
 	t := &ConfigTransformer{
 		Config:     cfg,
+		Messages:   NewCatalog(cfg),
+		Unmatched:  NewUnmatchedLog(DefaultUnmatchedLogSize),
+		Coverage:   NewCoverage(cfg.Mappings, DefaultMaxUnmatchedIntents),
+		Egress:     egress.New(cfg.Egress),
+		Tasks:      NewTaskStore(nil),
 		Transformer: *NewTransformer(),
 	}
 
+	// The intent config is validated on load; an invalid one matches by patterns only
+	router, err := intent.New(cfg.Intent)
+	if err != nil {
+		slog.Warn("invalid intent config; matching by intent patterns only", logging.KeyError, err)
+	}
+	t.Intent = router
+
+	// The sessions config is validated on load; an invalid one keeps sessions in memory
+	sessions, err := NewSessionStoreFromConfig(cfg.Sessions)
+	if err != nil {
+		slog.Warn("invalid sessions config; keeping sessions in memory", logging.KeyError, err)
+		sessions = NewSessionStore(nil, 0)
+	}
+	t.Sessions = sessions
+
 	// Set up transformation functions
 	t.SetRequestTransform(t.transformRequest)
 	t.SetResponseTransform(t.transformResponse)
@@ -38,31 +81,63 @@ func NewConfigTransformer(cfg *config.ConnectorConfig) *ConfigTransformer {
 	return t
 }
 
+// NewCatalog creates a message catalog using the locale and message overrides from configuration
+func NewCatalog(cfg *config.ConnectorConfig) *i18n.Catalog {
+	catalog := i18n.NewCatalog(cfg.Locale)
+	for locale, msgs := range cfg.Messages {
+		catalog.AddMessages(locale, msgs)
+	}
+	return catalog
+}
+
 // transformRequest transforms an A2A task to a legacy request format
 func (t *ConfigTransformer) transformRequest(data []byte) ([]byte, error) {
 	// Parse A2A task from JSON
 	var taskMap map[string]interface{}
 	if err := json.Unmarshal(data, &taskMap); err != nil {
-		log.Printf("Error unmarshaling A2A task: %v", err)
+		slog.Warn("failed to unmarshal A2A task", logging.KeyError, err)
 		return nil, err
 	}
 
-	// Extract text from the message parts
-	text, err := extractTextFromTask(taskMap)
+	// Resolve the locale requested by the task
+	locale := GetTaskLocale(taskMap)
+
+	// Variables set by earlier tasks of the session are read as session.<name>
+	sessionID := t.loadSession(taskMap)
+
+	// A continuation token resumes a paged mapping with its original params,
+	// and the answer to a task that asked for input resumes its mapping;
+	// other tasks are matched by their text
+	page, err := t.resumePage(taskMap, locale)
 	if err != nil {
 		return nil, err
 	}
+	var mappingConfig *config.MappingConfig
+	var params map[string]interface{}
+	if page != nil {
+		mappingConfig, params = page.mapping, page.request()
+	} else if mappingConfig, params = t.resumeInput(taskMap); mappingConfig == nil {
+		if mappingConfig, params, err = t.matchTask(taskMap, locale); err != nil {
+			return nil, err
+		}
+	}
 
-	// Find matching mapping configuration
-	mappingConfig, err := t.findMatchingMapping(text)
-	if err != nil {
-		return nil, err
+	// Files of the task, per-mapping headers, then the body in the format of
+	// the legacy endpoint
+	if err := t.placeFiles(mappingConfig.Files, taskMap, params); err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", mappingConfig.IntentPattern, err)
+	}
+	input, inputParams := t.requestInput(mappingConfig, taskMap, params)
+	if err := mappingHeaders(mappingConfig.Headers, taskMap, params); err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", mappingConfig.IntentPattern, err)
+	}
+	if err := formatRequest(mappingConfig, taskMap, params); err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", mappingConfig.IntentPattern, err)
 	}
 
-	// Extract parameters from the task
-	params, err := t.extractParameters(mappingConfig, taskMap, text)
+	endpoint, err := renderEndpoint(mappingConfig.Endpoint, params)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("mapping %s: %w", mappingConfig.IntentPattern, err)
 	}
 
 	// Get task ID for tracking
@@ -75,10 +150,71 @@ func (t *ConfigTransformer) transformRequest(data []byte) ([]byte, error) {
 		"meta": map[string]interface{}{
 			"taskId":     taskID,
 			"timestamp":  time.Now().Format(time.RFC3339),
-			"endpoint":   renderEndpoint(mappingConfig.Endpoint, params),
+			"endpoint":   endpoint,
 			"mappingId":  mappingConfig.IntentPattern,
 		},
 	}
+	if locale != "" {
+		legacyRequest["meta"].(map[string]interface{})["locale"] = locale
+	}
+	if sessionID != "" {
+		legacyRequest["meta"].(map[string]interface{})[sessionMetaKey] = sessionID
+	}
+	if timeout := mappingConfig.Timeout; timeout != (config.TimeoutConfig{}) {
+		legacyRequest["meta"].(map[string]interface{})["timeout"] = map[string]interface{}{
+			"connect": timeout.Connect,
+			"read":    timeout.Read,
+			"total":   timeout.Total,
+		}
+	}
+	if c := mappingConfig.Cache; c != nil && c.Enabled {
+		legacyRequest["meta"].(map[string]interface{})["cache"] = map[string]interface{}{
+			"key": mappingConfig.IntentPattern + "|" + renderCacheKey(c.Key, params),
+			"ttl": c.TTL,
+		}
+	}
+
+	if mappingConfig.Charset != "" {
+		legacyRequest["meta"].(map[string]interface{})["charset"] = mappingConfig.Charset
+	}
+	if mappingConfig.ResponseFormat != "" {
+		legacyRequest["meta"].(map[string]interface{})[responseFormatMetaKey] = mappingConfig.ResponseFormat
+	}
+	if mappingConfig.Conditional {
+		legacyRequest["meta"].(map[string]interface{})["conditional"] = mappingConfig.IntentPattern + "|" + renderCacheKey("", params)
+	}
+	if len(mappingConfig.Scopes) > 0 {
+		legacyRequest["meta"].(map[string]interface{})["scopes"] = mappingConfig.Scopes
+	}
+	if len(mappingConfig.Roles) > 0 {
+		legacyRequest["meta"].(map[string]interface{})["roles"] = mappingConfig.Roles
+	}
+	legacyRequest["meta"].(map[string]interface{})["actions"] = mappingActions(mappingConfig)
+	if mappingConfig.Pagination != nil {
+		legacyRequest["meta"].(map[string]interface{})[pageMetaKey] = page.meta(params)
+	}
+	if len(mappingConfig.Steps) > 0 {
+		legacyRequest["meta"].(map[string]interface{})[stepsMetaKey] = stepsMeta(mappingConfig, mappingConfig.Steps)
+	}
+	if mappingConfig.FanOut != nil {
+		legacyRequest["meta"].(map[string]interface{})[fanOutMetaKey] = fanOutMeta(mappingConfig)
+	}
+	if mappingConfig.Async != nil {
+		legacyRequest["meta"].(map[string]interface{})[asyncMetaKey] = asyncMeta(mappingConfig)
+	}
+	if input != nil {
+		legacyRequest["meta"].(map[string]interface{})[inputMetaKey] = input
+	}
+	if inputParams != nil {
+		legacyRequest["meta"].(map[string]interface{})[inputParamsMetaKey] = inputParams
+	}
+	queue, err := queueMeta(mappingConfig, taskMap)
+	if err != nil {
+		return nil, err
+	}
+	if queue != nil {
+		legacyRequest["meta"].(map[string]interface{})[queueMetaKey] = queue
+	}
 
 	// Apply global transformation rules
 	for _, rule := range t.Config.Transforms.A2AToLegacy {
@@ -88,12 +224,31 @@ func (t *ConfigTransformer) transformRequest(data []byte) ([]byte, error) {
 	return json.Marshal(legacyRequest)
 }
 
+// mappingActions returns the legacy actions a mapping calls: its method and
+// those of its steps and fan-out branches
+func mappingActions(mapping *config.MappingConfig) []string {
+	calls := []config.StepConfig{{Method: mapping.Method}}
+	calls = append(calls, stepsMeta(mapping, mapping.Steps)...)
+	if mapping.FanOut != nil {
+		calls = append(calls, stepsMeta(mapping, mapping.FanOut.Branches)...)
+	}
+	var actions []string
+	seen := make(map[string]bool)
+	for _, call := range calls {
+		if call.Method != "" && !seen[call.Method] {
+			seen[call.Method] = true
+			actions = append(actions, call.Method)
+		}
+	}
+	return actions
+}
+
 // transformResponse transforms a legacy response to an A2A task
 func (t *ConfigTransformer) transformResponse(data []byte) ([]byte, error) {
 	// Parse legacy response
 	var legacyResponse map[string]interface{}
 	if err := json.Unmarshal(data, &legacyResponse); err != nil {
-		log.Printf("Error unmarshaling legacy response: %v", err)
+		slog.Warn("failed to unmarshal legacy response", logging.KeyError, err)
 		return nil, err
 	}
 
@@ -113,19 +268,39 @@ func (t *ConfigTransformer) transformResponse(data []byte) ([]byte, error) {
 		}
 	}
 
+	// Get the locale carried over from the request
+	locale := ""
+	if meta, ok := legacyResponse["meta"].(map[string]interface{}); ok {
+		locale, _ = meta["locale"].(string)
+	}
+
 	// Find mapping config
+	var mappingConfig *config.MappingConfig
 	var responseTransform config.ResponseTransform
-	for _, mapping := range t.Config.Mappings {
+	for i, mapping := range t.Config.Mappings {
 		if mapping.IntentPattern == mappingID {
+			mappingConfig = &t.Config.Mappings[i]
 			responseTransform = mapping.ResponseTransform
 			break
 		}
 	}
 
+	// Legacy fields of the dictionary get their canonical names and values
+	// before anything reads the result
+	if result, ok := legacyResponse["result"]; ok {
+		legacyResponse["result"] = canonicalFields(t.Config.Dictionary, result)
+	}
+
 	// Determine task state
 	taskState := string(a2a.TaskStateCompleted)
 	if status, ok := legacyResponse["status"].(string); ok {
-		if status != "success" {
+		switch status {
+		case "success":
+		case string(a2a.TaskStateSubmitted), string(a2a.TaskStateWorking):
+			// Legacy systems that process asynchronously, like SAP IDocs,
+			// report work that has not finished yet
+			taskState = status
+		default:
 			taskState = string(a2a.TaskStateFailed)
 		}
 	}
@@ -133,11 +308,52 @@ func (t *ConfigTransformer) transformResponse(data []byte) ([]byte, error) {
 		taskState = string(a2a.TaskStateFailed)
 	}
 
+	// Tasks that asked for input before the legacy call, or whose response
+	// calls for it, wait for the answer of the agent
+	meta, _ := legacyResponse["meta"].(map[string]interface{})
+	input, _ := meta[inputMetaKey].(map[string]interface{})
+	if legacyResponse["status"] == StatusInputRequired {
+		taskState = string(a2a.TaskStateInputRequired)
+	} else if inputParams, ok := meta[inputParamsMetaKey].(map[string]interface{}); ok && taskState == string(a2a.TaskStateCompleted) && mappingConfig != nil {
+		if input = t.responseInput(mappingConfig, taskID, inputParams, legacyResponse); input != nil {
+			meta[inputMetaKey] = input
+			taskState = string(a2a.TaskStateInputRequired)
+		}
+	}
+	delete(meta, inputParamsMetaKey)
+
+	// Completed tasks set the session variables of their mapping
+	sessionID, _ := meta[sessionMetaKey].(string)
+	if taskState == string(a2a.TaskStateCompleted) {
+		t.saveSession(mappingConfig, sessionID, legacyResponse)
+	}
+
+	// Mask personal data before it reaches the parts and artifacts; sessions
+	// above keep the values for the next tasks
+	maskResponse(responseTransform.Mask, legacyResponse)
+
+	// Data the egress policy keeps in the connector is stripped; responses
+	// with data of a block class fail without their content
+	if withheld := t.applyEgress(legacyResponse, taskID, mappingID, locale); withheld != nil {
+		legacyResponse = withheld
+		responseTransform = config.ResponseTransform{}
+		input = nil
+		taskState = string(a2a.TaskStateFailed)
+	}
+
+	// Truncate paged results and issue a continuation token
+	more := t.paginate(mappingID, legacyResponse) && taskState == string(a2a.TaskStateCompleted)
+
 	// Build parts array
 	parts := []map[string]interface{}{}
 
-	// Add text part if we have a template
-	if responseTransform.Template != "" && responseTransform.CompiledTemplate != nil {
+	// Add text part with the prompt for input, or with the template
+	if input != nil {
+		parts = append(parts, map[string]interface{}{
+			"type": "text",
+			"text": input["prompt"],
+		})
+	} else if responseTransform.Template != "" && responseTransform.CompiledTemplate != nil {
 		var buf bytes.Buffer
 		if err := responseTransform.CompiledTemplate.Execute(&buf, legacyResponse); err == nil {
 			textPart := map[string]interface{}{
@@ -150,10 +366,16 @@ func (t *ConfigTransformer) transformResponse(data []byte) ([]byte, error) {
 		// Default text response
 		textContent := ""
 		if status, ok := legacyResponse["status"].(string); ok {
-			textContent += "Status: " + status + "\n"
+			textContent += t.Messages.T(locale, i18n.MsgStatusLine, status) + "\n"
 		}
 		if error, ok := legacyResponse["error"].(string); ok && error != "" {
-			textContent += "Error: " + error + "\n"
+			textContent += t.Messages.T(locale, i18n.MsgErrorLine, error) + "\n"
+		}
+		if result, ok := legacyResponse["result"].(map[string]interface{}); ok && result["unchanged"] == true {
+			textContent += t.Messages.T(locale, i18n.MsgUnchanged) + "\n"
+		}
+		if more {
+			textContent += t.Messages.T(locale, i18n.MsgMoreResults) + "\n"
 		}
 		
 		if textContent != "" {
@@ -164,14 +386,37 @@ func (t *ConfigTransformer) transformResponse(data []byte) ([]byte, error) {
 		}
 	}
 
-	// Add data part with the result
-	if result, ok := legacyResponse["result"].(map[string]interface{}); ok {
+	// Add a text part summarizing tabular results next to their data
+	if responseTransform.Summary != nil && input == nil && taskState == string(a2a.TaskStateCompleted) {
+		if part := summaryPart(responseTransform.Summary, legacyResponse); part != nil {
+			parts = append(parts, part)
+		}
+	}
+
+	// Add data part with the result, or with the fields picked by the mappings;
+	// fan-out mappings get a data part per branch
+	if len(responseTransform.Mappings) > 0 {
+		parts = append(parts, map[string]interface{}{
+			"type": "data",
+			"data": mapResponse(responseTransform.Mappings, legacyResponse),
+		})
+	} else if branchParts, ok := fanOutParts(legacyResponse); ok {
+		parts = append(parts, branchParts...)
+	} else if result, ok := legacyResponse["result"].(map[string]interface{}); ok {
 		parts = append(parts, map[string]interface{}{
 			"type": "data",
 			"data": result,
 		})
 	}
 
+	// Add structured error details, e.g. the HTTP status of a failed legacy call
+	if details, ok := legacyResponse["errorDetails"].(map[string]interface{}); ok {
+		parts = append(parts, map[string]interface{}{
+			"type": "data",
+			"data": map[string]interface{}{"error": details},
+		})
+	}
+
 	// Create a message with the parts
 	message := map[string]interface{}{
 		"role":  "agent",
@@ -187,6 +432,14 @@ func (t *ConfigTransformer) transformResponse(data []byte) ([]byte, error) {
 			"timestamp": time.Now().Format(time.RFC3339),
 		},
 	}
+	if sessionID != "" {
+		task["sessionId"] = sessionID
+	}
+
+	// Add artifacts, such as generated documents, built from response fields
+	if artifacts := buildArtifacts(responseTransform.Artifacts, legacyResponse); len(artifacts) > 0 {
+		task["artifacts"] = artifacts
+	}
 
 	// Add metadata from the legacy response
 	if meta, ok := legacyResponse["meta"].(map[string]interface{}); ok {
@@ -201,53 +454,295 @@ func (t *ConfigTransformer) transformResponse(data []byte) ([]byte, error) {
 	return json.Marshal(task)
 }
 
-// findMatchingMapping finds the mapping configuration that matches the text
-func (t *ConfigTransformer) findMatchingMapping(text string) (*config.MappingConfig, error) {
-	text = strings.ToLower(text)
-	
+// matchTask finds the mapping matching the task text and extracts its params
+func (t *ConfigTransformer) matchTask(taskMap map[string]interface{}, locale string) (*config.MappingConfig, map[string]interface{}, error) {
+	// Extract text from the message parts
+	text, err := extractTextFromTask(taskMap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Find matching mapping configuration
+	mappingConfig, slots, err := t.findMatchingMapping(taskMap, text)
+	if err != nil {
+		t.Coverage.Miss(text)
+		nearMisses := findNearMisses(t.Config.Mappings, text)
+		t.Unmatched.Add(UnmatchedIntent{
+			TaskID:     getTaskID(taskMap),
+			Text:       snippet(text),
+			Timestamp:  time.Now(),
+			NearMisses: nearMisses,
+		})
+		return nil, nil, &NoMatchError{
+			Message:          t.Messages.T(locale, i18n.MsgNoMatchingMapping, strings.ToLower(text)),
+			Text:             snippet(text),
+			NearMisses:       nearMisses,
+			ConditionsNotMet: t.conditionsNotMet(taskMap, text),
+		}
+	}
+
+	t.Coverage.Hit(mappingConfig.IntentPattern)
+
+	// Extract parameters from the task
+	params, err := t.extractParameters(mappingConfig, taskMap, text, slots)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mappingConfig, params, nil
+}
+
+// NoMatchError is returned when no mapping matches a task and no fallback
+// mapping applies
+type NoMatchError struct {
+	Message    string
+	Text       string
+	NearMisses []NearMiss
+	// ConditionsNotMet are the mappings whose intent pattern matched but
+	// whose when condition did not hold
+	ConditionsNotMet []string
+}
+
+// Error implements the error interface
+func (e *NoMatchError) Error() string {
+	return e.Message
+}
+
+// Details returns the closest mappings for error reporting
+func (e *NoMatchError) Details() map[string]interface{} {
+	details := map[string]interface{}{
+		"reason": "no matching mapping",
+		"text":   e.Text,
+	}
+	if len(e.NearMisses) > 0 {
+		details["nearMisses"] = e.NearMisses
+	}
+	if len(e.ConditionsNotMet) > 0 {
+		details["conditionsNotMet"] = e.ConditionsNotMet
+	}
+	return details
+}
+
+// findMatchingMapping finds the mapping configuration that matches the task,
+// with the slots an intent classifier extracted. Mappings are tried by
+// ascending order, then in file order; fallback mappings are tried the same
+// way when no other mapping matches. The intent mode decides whether the
+// classifier is asked before or after the patterns.
+func (t *ConfigTransformer) findMatchingMapping(taskMap map[string]interface{}, text string) (*config.MappingConfig, map[string]interface{}, error) {
+	if t.Intent != nil && t.Intent.Mode == intent.ModeLLM {
+		if mapping, slots := t.classify(taskMap, text); mapping != nil {
+			return mapping, slots, nil
+		}
+	}
+	mappings := t.orderedMappings()
+	for _, mapping := range mappings {
+		if !mapping.Fallback && t.mappingMatches(mapping, taskMap, text) {
+			return mapping, nil, nil
+		}
+	}
+	if t.Intent != nil && t.Intent.Mode == intent.ModeHybrid {
+		if mapping, slots := t.classify(taskMap, text); mapping != nil {
+			return mapping, slots, nil
+		}
+	}
+	for _, mapping := range mappings {
+		if mapping.Fallback && t.mappingMatches(mapping, taskMap, text) {
+			return mapping, nil, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("no matching mapping found for text: %s", strings.ToLower(text))
+}
+
+// orderedMappings returns the mappings sorted by their order, keeping the
+// file order among equal orders
+func (t *ConfigTransformer) orderedMappings() []*config.MappingConfig {
+	mappings := make([]*config.MappingConfig, len(t.Config.Mappings))
+	for i := range t.Config.Mappings {
+		mappings[i] = &t.Config.Mappings[i]
+	}
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return mappings[i].Order < mappings[j].Order
+	})
+	return mappings
+}
+
+// mappingMatches reports whether the intent pattern of a mapping matches the
+// text and its when condition holds for the task
+func (t *ConfigTransformer) mappingMatches(mapping *config.MappingConfig, taskMap map[string]interface{}, text string) bool {
+	if mapping.CompiledPattern == nil || !mapping.CompiledPattern.MatchString(strings.ToLower(text)) {
+		return false
+	}
+	return mapping.CompiledWhen == nil || mapping.CompiledWhen.Match(taskLookup(taskMap, text))
+}
+
+// classify asks the intent classifier for the mapping of the task among the
+// mappings that are not fallbacks and whose when condition holds. It returns
+// nil when the classifier is unsure or fails, so matching falls back to the
+// intent patterns.
+func (t *ConfigTransformer) classify(taskMap map[string]interface{}, text string) (*config.MappingConfig, map[string]interface{}) {
+	var candidates []intent.Candidate
 	for i := range t.Config.Mappings {
 		mapping := &t.Config.Mappings[i]
-		if mapping.CompiledPattern != nil && mapping.CompiledPattern.MatchString(text) {
-			return mapping, nil
+		if mapping.Fallback || (mapping.CompiledWhen != nil && !mapping.CompiledWhen.Match(taskLookup(taskMap, text))) {
+			continue
 		}
+		candidate := intent.Candidate{ID: i, Description: mapping.Description, IntentPattern: mapping.IntentPattern}
+		for _, paramMapping := range mapping.ParameterMappings {
+			if paramMapping.Source == "text" {
+				candidate.Slots = append(candidate.Slots, paramMapping.Target)
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	result, err := t.Intent.Route(text, candidates)
+	if err != nil {
+		slog.Warn("intent classification failed; matching by intent patterns", logging.KeyError, err)
+		return nil, nil
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return &t.Config.Mappings[result.ID], result.Slots
+}
+
+// conditionsNotMet lists the mappings whose intent pattern matches the text
+// but whose when condition does not hold for the task
+func (t *ConfigTransformer) conditionsNotMet(taskMap map[string]interface{}, text string) []string {
+	var patterns []string
+	for i := range t.Config.Mappings {
+		mapping := &t.Config.Mappings[i]
+		if mapping.CompiledWhen != nil && mapping.CompiledPattern != nil &&
+			mapping.CompiledPattern.MatchString(strings.ToLower(text)) && !t.mappingMatches(mapping, taskMap, text) {
+			patterns = append(patterns, mapping.IntentPattern)
+		}
+	}
+	return patterns
+}
+
+// taskLookup resolves the fields of when conditions: text is the task text,
+// other paths are fields of the task such as metadata.region
+func taskLookup(taskMap map[string]interface{}, text string) expr.Lookup {
+	return func(path string) interface{} {
+		if path == "text" {
+			return text
+		}
+		return getValueByPath(taskMap, path)
 	}
-	
-	return nil, fmt.Errorf("no matching mapping found for text: %s", text)
 }
 
 // extractParameters extracts parameters from the task using parameter mappings
-func (t *ConfigTransformer) extractParameters(mapping *config.MappingConfig, taskMap map[string]interface{}, text string) (map[string]interface{}, error) {
+func (t *ConfigTransformer) extractParameters(mapping *config.MappingConfig, taskMap map[string]interface{}, text string, slots map[string]interface{}) (map[string]interface{}, error) {
 	params := make(map[string]interface{})
-	
+
+	// Start from the static params of the mapping
+	for key, value := range mapping.Params {
+		params[key] = copyValue(value)
+	}
+
 	// Apply parameter mappings
 	for _, paramMapping := range mapping.ParameterMappings {
+		var value interface{}
+
 		if paramMapping.Source == "text" {
 			if paramMapping.Compiled != nil && paramMapping.Compiled.MatchString(text) {
 				matches := paramMapping.Compiled.FindStringSubmatch(text)
 				if len(matches) > 1 {
-					// Extract captured group and set in params
-					setValue(params, paramMapping.Target, matches[1])
+					// Extract captured group
+					value = matches[1]
 				}
-			} else if paramMapping.Default != "" {
-				// Use default value if no match
-				setValue(params, paramMapping.Target, paramMapping.Default)
+			}
+			// Slots extracted by the intent classifier fill what the pattern missed
+			if value == nil && slots[paramMapping.Target] != nil {
+				value = slots[paramMapping.Target]
 			}
 		} else {
 			// Extract value from task using path
-			value := getValueByPath(taskMap, paramMapping.Source)
-			if value != nil {
-				setValue(params, paramMapping.Target, value)
-			} else if paramMapping.Default != "" {
-				setValue(params, paramMapping.Target, paramMapping.Default)
+			value = getValueByPath(taskMap, paramMapping.Source)
+		}
+
+		if value == nil {
+			if paramMapping.Default == "" {
+				continue
 			}
+			// Use default value if no match
+			value = paramMapping.Default
+		}
+
+		// Fields of the dictionary are sent under their legacy name and value
+		target, def := legacyTarget(t.Config.Dictionary, paramMapping.Target)
+		typ := paramMapping.Type
+		if typ == "" && def != nil {
+			typ = def.Type
+		}
+		converted, err := convertParameter(value, typ)
+		if err == nil && def != nil {
+			converted, err = legacyValue(def, converted)
 		}
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %v", paramMapping.Target, err)
+		}
+		setValue(params, target, converted)
 	}
-	
+
 	return params, nil
 }
 
 // Helper functions
 
+// convertParameter converts an extracted value to the type declared on the parameter mapping
+func convertParameter(value interface{}, typ string) (interface{}, error) {
+	str, isString := value.(string)
+	if !isString {
+		return value, nil
+	}
+
+	switch typ {
+	case "", "string":
+		return str, nil
+	case "int", "integer":
+		n, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", str)
+		}
+		return n, nil
+	case "number", "float":
+		f, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", str)
+		}
+		return f, nil
+	case "bool", "boolean":
+		b, err := strconv.ParseBool(strings.TrimSpace(str))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", str)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %q", typ)
+	}
+}
+
+// copyValue deep-copies maps and slices so static mapping params are never mutated
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
 // extractTextFromTask extracts text from the message parts
 func extractTextFromTask(taskMap map[string]interface{}) (string, error) {
 	text := ""
@@ -291,6 +786,16 @@ func extractTextFromTask(taskMap map[string]interface{}) (string, error) {
 	return text, nil
 }
 
+// GetTaskLocale returns the locale requested in the task metadata, if any
+func GetTaskLocale(taskMap map[string]interface{}) string {
+	if metadata, ok := taskMap["metadata"].(map[string]interface{}); ok {
+		if locale, ok := metadata["locale"].(string); ok {
+			return locale
+		}
+	}
+	return ""
+}
+
 // getTaskID gets the task ID from the task map
 func getTaskID(taskMap map[string]interface{}) string {
 	if id, ok := taskMap["id"].(string); ok {
@@ -299,98 +804,113 @@ func getTaskID(taskMap map[string]interface{}) string {
 	return fmt.Sprintf("task-%d", time.Now().Unix())
 }
 
-// renderEndpoint renders the endpoint with parameter values
-func renderEndpoint(endpoint string, params map[string]interface{}) string {
-	result := endpoint
-	
-	// Replace {param} placeholders
-	re := regexp.MustCompile(`\{([^}]+)\}`)
-	matches := re.FindAllStringSubmatch(endpoint, -1)
-	
-	for _, match := range matches {
-		if len(match) > 1 {
-			paramName := match[1]
-			placeholder := "{" + paramName + "}"
-			
-			// Find param value
-			if value, ok := params[paramName].(string); ok {
-				result = strings.ReplaceAll(result, placeholder, value)
-			} else if valueNum, ok := params[paramName].(float64); ok {
-				result = strings.ReplaceAll(result, placeholder, fmt.Sprintf("%v", valueNum))
-			} else if valueBool, ok := params[paramName].(bool); ok {
-				result = strings.ReplaceAll(result, placeholder, fmt.Sprintf("%v", valueBool))
-			}
+// renderEndpoint renders the endpoint with parameter values. Values come
+// from the text of the task, so they are escaped for the part of the endpoint
+// they are placed in and cannot add path segments, query parameters or a
+// fragment; values that would make a dot segment are rejected.
+func renderEndpoint(endpoint string, params map[string]interface{}) (string, error) {
+	query := strings.Index(endpoint, "?")
+	if query == -1 {
+		query = len(endpoint)
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, match := range cacheKeyPattern.FindAllStringSubmatchIndex(endpoint, -1) {
+		result.WriteString(endpoint[last:match[0]])
+		last = match[1]
+
+		var value string
+		switch v := params[endpoint[match[2]:match[3]]].(type) {
+		case string:
+			value = v
+		case float64, bool:
+			value = fmt.Sprintf("%v", v)
+		default:
+			// Placeholders without a value are left as they are
+			result.WriteString(endpoint[match[0]:match[1]])
+			continue
+		}
+		if match[0] < query {
+			result.WriteString(url.PathEscape(value))
+		} else {
+			result.WriteString(url.QueryEscape(value))
 		}
 	}
-	
-	return result
-}
+	result.WriteString(endpoint[last:])
 
-// getValueByPath gets a value from a nested map using a dot-notation path
-func getValueByPath(data map[string]interface{}, path string) interface{} {
-	parts := strings.Split(path, ".")
-	current := data
-	
-	for i, part := range parts {
-		if i == len(parts)-1 {
-			return current[part]
-		}
-		
-		if nextMap, ok := current[part].(map[string]interface{}); ok {
-			current = nextMap
-		} else if nextMap, ok := current[part].(map[string]string); ok {
-			// Convert map[string]string to map[string]interface{}
-			converted := make(map[string]interface{})
-			for k, v := range nextMap {
-				converted[k] = v
-			}
-			current = converted
-		} else {
-			return nil
+	rendered := result.String()
+	path, _, _ := strings.Cut(rendered, "?")
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("endpoint %s: parameter values cannot make a dot segment", endpoint)
 		}
 	}
-	
-	return nil
+	return rendered, nil
 }
 
-// setValue sets a value in a nested map using a dot-notation path
-func setValue(data map[string]interface{}, path string, value interface{}) {
-	parts := strings.Split(path, ".")
-	current := data
-	
-	for i, part := range parts {
-		if i == len(parts)-1 {
-			current[part] = value
-			return
-		}
-		
-		if _, ok := current[part]; !ok {
-			current[part] = make(map[string]interface{})
+// renderCacheKey renders a cache key template with parameter values; an empty
+// template keys on all parameters
+func renderCacheKey(key string, params map[string]interface{}) string {
+	if key == "" {
+		data, _ := json.Marshal(params)
+		return string(data)
+	}
+
+	return cacheKeyPattern.ReplaceAllStringFunc(key, func(match string) string {
+		value := getValueByPath(params, match[1:len(match)-1])
+		if value == nil {
+			return ""
 		}
-		
-		if nextMap, ok := current[part].(map[string]interface{}); ok {
-			current = nextMap
-		} else {
-			// Convert to map if possible
-			if converted, ok := current[part].(map[string]string); ok {
-				newMap := make(map[string]interface{})
-				for k, v := range converted {
-					newMap[k] = v
-				}
-				current[part] = newMap
-				current = newMap
-			} else {
-				// Cannot traverse further, reset the path
-				current[part] = make(map[string]interface{})
-				current = current[part].(map[string]interface{})
-			}
+		return fmt.Sprint(value)
+	})
+}
+
+// mapResponse builds the data of a response from mappings of target paths
+// to source paths in the legacy response; missing sources are left out
+func mapResponse(mappings map[string]string, legacyResponse map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(mappings))
+	for target, source := range mappings {
+		if value := getValueByPath(legacyResponse, source); value != nil {
+			setValue(data, target, value)
 		}
 	}
+	return data
+}
+
+// getValueByPath gets a value from a nested map using a dot-notation path,
+// which may use JSONPath indexes, wildcards and filters (see package jsonpath)
+func getValueByPath(data map[string]interface{}, path string) interface{} {
+	return jsonpath.Get(data, path)
+}
+
+// setValue sets a value in a nested map using a dot-notation or JSONPath path
+func setValue(data map[string]interface{}, path string, value interface{}) {
+	jsonpath.Set(data, path, value)
 }
 
 // applyTransformRule applies a transformation rule to convert between data formats
 func applyTransformRule(rule config.TransformRule, source, target map[string]interface{}) {
-	sourceValue := getValueByPath(source, rule.Source)
+	var sourceValue interface{}
+	if rule.Source != "" {
+		sourceValue = getValueByPath(source, rule.Source)
+	}
+
+	// Compute the value from other fields if an expression is provided; rules
+	// whose fields are missing are skipped like rules without a source value
+	if rule.CompiledExpr != nil {
+		value := sourceValue
+		computed, err := rule.CompiledExpr.Eval(func(path string) interface{} {
+			if path == "value" && rule.Source != "" {
+				return value
+			}
+			return getValueByPath(source, path)
+		})
+		if err != nil {
+			return
+		}
+		sourceValue = computed
+	}
 	if sourceValue == nil {
 		return
 	}
@@ -0,0 +1,130 @@
+package proxy_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestConditionalMappings(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{
+			{IntentPattern: `order`, Endpoint: "/orders", Method: "GET"},
+			{IntentPattern: `order`, Endpoint: "/eu/orders", Method: "GET", When: "metadata.region == 'eu'", Order: -1},
+			{IntentPattern: `refund`, Endpoint: "/refunds", Method: "POST", When: "metadata.tier == 'gold' && contains(text, 'urgent')"},
+			{IntentPattern: `.*`, Endpoint: "/helpdesk", Method: "POST", Fallback: true, When: "metadata.region == 'eu'"},
+			{IntentPattern: `.*`, Endpoint: "/inbox", Method: "POST", Fallback: true},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	endpoint := func(text, metadata string) (string, error) {
+		data, err := ct.TransformRequestData([]byte(`{"id":"t1","metadata":` + metadata + `,"status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"` + text + `"}]}}}`))
+		if err != nil {
+			return "", err
+		}
+		var request map[string]interface{}
+		json.Unmarshal(data, &request)
+		return request["meta"].(map[string]interface{})["endpoint"].(string), nil
+	}
+
+	tests := []struct {
+		text     string
+		metadata string
+		expected string
+	}{
+		// A lower order is tried first, but only where its condition holds
+		{"show my order", `{"region":"eu"}`, "/eu/orders"},
+		{"show my order", `{"region":"us"}`, "/orders"},
+		{"urgent refund please", `{"tier":"gold"}`, "/refunds"},
+		// Fallbacks are tried in order when nothing else matches
+		{"refund please", `{"tier":"gold","region":"eu"}`, "/helpdesk"},
+		{"hello", `{}`, "/inbox"},
+	}
+	for _, tt := range tests {
+		got, err := endpoint(tt.text, tt.metadata)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.text, tt.metadata, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s %s: expected %s, got %s", tt.text, tt.metadata, tt.expected, got)
+		}
+	}
+
+	// Without a fallback the error names the mappings whose conditions failed
+	cfg.Mappings = cfg.Mappings[:3]
+	ct = proxy.NewConfigTransformer(cfg)
+	_, err := endpoint("refund please", `{"tier":"silver"}`)
+	var noMatch *proxy.NoMatchError
+	if !errors.As(err, &noMatch) {
+		t.Fatalf("Expected a NoMatchError, got %v", err)
+	}
+	details := noMatch.Details()
+	if notMet, _ := details["conditionsNotMet"].([]string); len(notMet) != 1 || notMet[0] != "refund" {
+		t.Errorf("Unexpected details %v", details)
+	}
+}
+
+func TestIntentClassification(t *testing.T) {
+	reply := `{"mapping":1,"confidence":0.9,"slots":{"orderId":"A-17"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reply == "" {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	defer server.Close()
+
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Intent:  &config.IntentConfig{Mode: "llm", Endpoint: server.URL},
+		Mappings: []config.MappingConfig{
+			{IntentPattern: `customer`, Endpoint: "/customers", Method: "GET"},
+			{IntentPattern: `order\s+(\S+)`, Description: "Look up an order", Endpoint: "/orders", Method: "GET",
+				ParameterMappings: []config.ParameterMapping{{Source: "text", Pattern: `order\s+(\S+)`, Target: "orderId"}}},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	request := func(text string) map[string]interface{} {
+		data, err := ct.TransformRequestData([]byte(`{"id":"t1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"` + text + `"}]}}}`))
+		if err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		var request map[string]interface{}
+		json.Unmarshal(data, &request)
+		return request
+	}
+
+	// The classifier chooses the mapping and fills the slot the pattern misses
+	req := request("where is my parcel A-17?")
+	if endpoint := req["meta"].(map[string]interface{})["endpoint"]; endpoint != "/orders" {
+		t.Errorf("Expected /orders, got %v", endpoint)
+	}
+	if params := req["params"].(map[string]interface{}); params["orderId"] != "A-17" {
+		t.Errorf("Expected the orderId slot, got %v", params)
+	}
+
+	// A failing classifier falls back to the intent patterns
+	reply = ""
+	req = request("show customer 5")
+	if endpoint := req["meta"].(map[string]interface{})["endpoint"]; endpoint != "/customers" {
+		t.Errorf("Expected /customers, got %v", endpoint)
+	}
+}
@@ -0,0 +1,246 @@
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// DefaultMaxUnmatchedIntents is the number of distinct unmatched intents counted
+const DefaultMaxUnmatchedIntents = 500
+
+// maxIntentSampleLength limits how much of an unmatched text is kept as sample
+const maxIntentSampleLength = 60
+
+// intentNumberPattern finds numbers, such as IDs and amounts, in task texts
+var intentNumberPattern = regexp.MustCompile(`[0-9]+`)
+
+// Coverage counts the tasks each mapping matched and groups the texts that
+// matched none, so config authors see which requests the config fails to
+// route. Unmatched texts are normalized, with numbers replaced by #, and
+// identified by a hash; only a truncated sample of each is kept.
+type Coverage struct {
+	mu         sync.Mutex
+	since      time.Time
+	mappings   []string
+	hits       map[string]*MappingHits
+	unmatched  map[string]*UnmatchedIntentCount
+	matched    int64
+	missed     int64
+	maxIntents int
+}
+
+// MappingHits is the number of tasks a mapping matched
+type MappingHits struct {
+	MappingID string     `json:"mappingId"`
+	Hits      int64      `json:"hits"`
+	LastHit   *time.Time `json:"lastHit,omitempty"`
+}
+
+// UnmatchedIntentCount is a normalized text that matched no mapping and how
+// often it was sent
+type UnmatchedIntentCount struct {
+	Hash      string    `json:"hash"`
+	Sample    string    `json:"sample"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// CoverageReport is the per-mapping hit counts, unused mappings included, and
+// the most frequent unmatched intents
+type CoverageReport struct {
+	Since            time.Time              `json:"since"`
+	Matched          int64                  `json:"matched"`
+	Unmatched        int64                  `json:"unmatched"`
+	Coverage         float64                `json:"coverage"`
+	Mappings         []MappingHits          `json:"mappings"`
+	UnmatchedIntents []UnmatchedIntentCount `json:"unmatchedIntents"`
+}
+
+// NewCoverage creates a coverage counter for the mappings, counting up to
+// maxIntents distinct unmatched intents
+func NewCoverage(mappings []config.MappingConfig, maxIntents int) *Coverage {
+	if maxIntents <= 0 {
+		maxIntents = DefaultMaxUnmatchedIntents
+	}
+	c := &Coverage{maxIntents: maxIntents}
+	c.Reset()
+	c.SetMappings(mappings)
+	return c
+}
+
+// SetMappings replaces the reported mappings after a reload; counts of
+// mappings that remain are kept
+func (c *Coverage) SetMappings(mappings []config.MappingConfig) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mappings = make([]string, 0, len(mappings))
+	hits := make(map[string]*MappingHits, len(mappings))
+	for _, mapping := range mappings {
+		id := mapping.IntentPattern
+		if _, ok := hits[id]; ok {
+			continue
+		}
+		c.mappings = append(c.mappings, id)
+		if h, ok := c.hits[id]; ok {
+			hits[id] = h
+		} else {
+			hits[id] = &MappingHits{MappingID: id}
+		}
+	}
+	c.hits = hits
+}
+
+// Hit counts a task matched by the mapping with the given ID
+func (c *Coverage) Hit(mappingID string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.matched++
+	h, ok := c.hits[mappingID]
+	if !ok {
+		h = &MappingHits{MappingID: mappingID}
+		c.hits[mappingID] = h
+		c.mappings = append(c.mappings, mappingID)
+	}
+	h.Hits++
+	h.LastHit = &now
+}
+
+// Miss counts a task text that matched no mapping. When maxIntents distinct
+// intents are counted, the least frequent one makes room.
+func (c *Coverage) Miss(text string) {
+	if c == nil {
+		return
+	}
+	normalized := normalizeIntent(text)
+	sum := sha256.Sum256([]byte(normalized))
+	hash := hex.EncodeToString(sum[:8])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.missed++
+	if intent, ok := c.unmatched[hash]; ok {
+		intent.Count++
+		intent.LastSeen = now
+		return
+	}
+	if len(c.unmatched) >= c.maxIntents {
+		c.evictIntent()
+	}
+	c.unmatched[hash] = &UnmatchedIntentCount{
+		Hash:      hash,
+		Sample:    truncateIntent(normalized),
+		Count:     1,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+}
+
+// evictIntent removes the least frequent unmatched intent, the least
+// recently seen of equals
+func (c *Coverage) evictIntent() {
+	var victim *UnmatchedIntentCount
+	for _, intent := range c.unmatched {
+		if victim == nil || intent.Count < victim.Count ||
+			(intent.Count == victim.Count && intent.LastSeen.Before(victim.LastSeen)) {
+			victim = intent
+		}
+	}
+	if victim != nil {
+		delete(c.unmatched, victim.Hash)
+	}
+}
+
+// Report returns the hit count of each mapping, most used first and unused
+// ones in config order last, and up to limit unmatched intents, most
+// frequent first; a limit of zero or less returns all of them
+func (c *Coverage) Report(limit int) CoverageReport {
+	report := CoverageReport{Mappings: []MappingHits{}, UnmatchedIntents: []UnmatchedIntentCount{}}
+	if c == nil {
+		return report
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report.Since = c.since
+	report.Matched = c.matched
+	report.Unmatched = c.missed
+	if total := c.matched + c.missed; total > 0 {
+		report.Coverage = float64(c.matched) / float64(total)
+	}
+	for _, id := range c.mappings {
+		report.Mappings = append(report.Mappings, *c.hits[id])
+	}
+	sort.SliceStable(report.Mappings, func(i, j int) bool {
+		return report.Mappings[i].Hits > report.Mappings[j].Hits
+	})
+
+	for _, intent := range c.unmatched {
+		report.UnmatchedIntents = append(report.UnmatchedIntents, *intent)
+	}
+	sort.Slice(report.UnmatchedIntents, func(i, j int) bool {
+		a, b := report.UnmatchedIntents[i], report.UnmatchedIntents[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.LastSeen.After(b.LastSeen)
+	})
+	if limit > 0 && len(report.UnmatchedIntents) > limit {
+		report.UnmatchedIntents = report.UnmatchedIntents[:limit]
+	}
+	return report
+}
+
+// Reset clears all counts
+func (c *Coverage) Reset() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.since = time.Now()
+	c.matched, c.missed = 0, 0
+	c.unmatched = make(map[string]*UnmatchedIntentCount)
+	for _, id := range c.mappings {
+		c.hits[id] = &MappingHits{MappingID: id}
+	}
+}
+
+// normalizeIntent lowercases text, collapses whitespace and replaces numbers
+// with #, so requests that differ only in IDs or amounts count as one intent
+func normalizeIntent(text string) string {
+	text = strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	return intentNumberPattern.ReplaceAllString(text, "#")
+}
+
+// truncateIntent shortens a normalized text to its sample
+func truncateIntent(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxIntentSampleLength {
+		return text
+	}
+	return string(runes[:maxIntentSampleLength]) + "..."
+}
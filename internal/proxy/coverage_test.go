@@ -0,0 +1,100 @@
+package proxy_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestCoverageReport(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{
+			{IntentPattern: `get.*customer`, Endpoint: "/customers", Method: "GET"},
+			{IntentPattern: `create.*order`, Endpoint: "/orders", Method: "POST"},
+			{IntentPattern: `cancel.*order`, Endpoint: "/orders", Method: "DELETE"},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+
+	ct := proxy.NewConfigTransformer(cfg)
+	texts := []string{
+		"get customer 1",
+		"get customer 2",
+		"create order",
+		"Refund order 123",
+		"refund  ORDER 456",
+		"track parcel 9",
+	}
+	for i, text := range texts {
+		task := fmt.Sprintf(`{"id":"task-%d","status":{"message":{"role":"user","parts":[{"type":"text","text":%q}]}}}`, i, text)
+		ct.TransformRequestData([]byte(task))
+	}
+
+	report := ct.Coverage.Report(1)
+	if report.Matched != 3 || report.Unmatched != 3 || report.Coverage != 0.5 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	var got []string
+	for _, m := range report.Mappings {
+		got = append(got, fmt.Sprintf("%s=%d", m.MappingID, m.Hits))
+	}
+	if want := "get.*customer=2 create.*order=1 cancel.*order=0"; strings.Join(got, " ") != want {
+		t.Errorf("Expected mapping hits %q, got %q", want, strings.Join(got, " "))
+	}
+
+	// Texts differing in case, spacing and numbers count as one intent
+	if len(report.UnmatchedIntents) != 1 {
+		t.Fatalf("Expected the top unmatched intent only, got %+v", report.UnmatchedIntents)
+	}
+	top := report.UnmatchedIntents[0]
+	if top.Sample != "refund order #" || top.Count != 2 || top.Hash == "" {
+		t.Errorf("Unexpected top unmatched intent: %+v", top)
+	}
+	if all := ct.Coverage.Report(0); len(all.UnmatchedIntents) != 2 {
+		t.Errorf("Expected 2 unmatched intents, got %+v", all.UnmatchedIntents)
+	}
+
+	ct.Coverage.Reset()
+	report = ct.Coverage.Report(0)
+	if report.Matched != 0 || len(report.UnmatchedIntents) != 0 || len(report.Mappings) != 3 {
+		t.Errorf("Expected reset counts, got %+v", report)
+	}
+}
+
+func TestCoverageLimitsIntents(t *testing.T) {
+	coverage := proxy.NewCoverage(nil, 2)
+	coverage.Miss("first")
+	coverage.Miss("first")
+	coverage.Miss("second")
+	coverage.Miss("third")
+	coverage.Miss(strings.Repeat("long ", 50))
+
+	report := coverage.Report(0)
+	if len(report.UnmatchedIntents) != 2 || report.Unmatched != 5 {
+		t.Fatalf("Expected 2 of 5 unmatched intents, got %+v", report)
+	}
+	if report.UnmatchedIntents[0].Sample != "first" {
+		t.Errorf("Expected the most frequent intent to be kept, got %+v", report.UnmatchedIntents)
+	}
+	if sample := report.UnmatchedIntents[1].Sample; !strings.HasSuffix(sample, "...") || len(sample) > 70 {
+		t.Errorf("Expected a truncated sample, got %q", sample)
+	}
+}
+
+func TestCoverageKeepsHitsOnReload(t *testing.T) {
+	coverage := proxy.NewCoverage([]config.MappingConfig{{IntentPattern: "a"}, {IntentPattern: "b"}}, 0)
+	coverage.Hit("a")
+	coverage.Hit("b")
+	coverage.SetMappings([]config.MappingConfig{{IntentPattern: "c"}, {IntentPattern: "a"}})
+
+	report := coverage.Report(0)
+	if len(report.Mappings) != 2 || report.Mappings[0].MappingID != "a" || report.Mappings[0].Hits != 1 || report.Mappings[0].LastHit == nil {
+		t.Errorf("Expected the hits of a to be kept and b to be dropped, got %+v", report.Mappings)
+	}
+}
@@ -0,0 +1,151 @@
+package proxy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+)
+
+// canonicalFields renames the legacy fields of the dictionary to their
+// canonical names in value, at any depth, and converts their values
+func canonicalFields(dictionary []config.FieldDefinition, value interface{}) interface{} {
+	if len(dictionary) == 0 {
+		return value
+	}
+	byLegacy := make(map[string]*config.FieldDefinition, len(dictionary))
+	for i := range dictionary {
+		byLegacy[dictionary[i].Legacy] = &dictionary[i]
+	}
+	return renameFields(byLegacy, value)
+}
+
+func renameFields(byLegacy map[string]*config.FieldDefinition, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if def, ok := byLegacy[key]; ok {
+				renamed[def.Field] = canonicalValue(def, item)
+				continue
+			}
+			if _, taken := renamed[key]; !taken {
+				renamed[key] = renameFields(byLegacy, item)
+			}
+		}
+		return renamed
+	case []interface{}:
+		for i, item := range v {
+			v[i] = renameFields(byLegacy, item)
+		}
+		return v
+	}
+	return value
+}
+
+// canonicalValue converts a legacy value of a dictionary field; values that
+// do not convert are kept
+func canonicalValue(def *config.FieldDefinition, value interface{}) interface{} {
+	if code, ok := scalarText(value); ok {
+		if canonical, ok := def.Values[code]; ok {
+			return canonical
+		}
+	}
+	if s, ok := value.(string); ok && def.Type != "" {
+		if converted, err := convertParameter(s, def.Type); err == nil {
+			value = converted
+		}
+	}
+	n, ok := numberValue(value)
+	switch {
+	case !ok:
+	case def.Unit != "":
+		if converted, err := expr.ConvertUnit(n, def.LegacyUnit, def.Unit); err == nil {
+			return roundScaled(converted)
+		}
+	case def.Scale != 0:
+		return roundScaled(n * def.Scale)
+	}
+	return value
+}
+
+// legacyTarget returns the target of a parameter with its last name replaced
+// by the legacy name when the dictionary defines it
+func legacyTarget(dictionary []config.FieldDefinition, target string) (string, *config.FieldDefinition) {
+	prefix, name := "", target
+	if i := strings.LastIndex(target, "."); i >= 0 {
+		prefix, name = target[:i+1], target[i+1:]
+	}
+	for i := range dictionary {
+		if dictionary[i].Field == name {
+			return prefix + dictionary[i].Legacy, &dictionary[i]
+		}
+	}
+	return target, nil
+}
+
+// legacyValue converts a parameter value of a dictionary field to the value
+// the legacy system expects
+func legacyValue(def *config.FieldDefinition, value interface{}) (interface{}, error) {
+	if text, ok := scalarText(value); ok {
+		for code, canonical := range def.Values {
+			if canonical == text {
+				return code, nil
+			}
+		}
+	}
+	if def.Unit == "" && def.Scale == 0 {
+		return value, nil
+	}
+	n, ok := numberValue(value)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a number", value)
+	}
+	if def.Unit != "" {
+		converted, err := expr.ConvertUnit(n, def.Unit, def.LegacyUnit)
+		return roundScaled(converted), err
+	}
+	return roundScaled(n / def.Scale), nil
+}
+
+// scalarText returns strings, numbers and booleans as text
+func scalarText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case int:
+		return strconv.Itoa(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// numberValue returns value as a number; numeric strings count as numbers
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// roundScaled rounds away the floating point error of scaling and unit
+// conversion, such as 1999 * 0.01 giving 19.990000000000002
+func roundScaled(n float64) float64 {
+	return math.Round(n*1e9) / 1e9
+}
@@ -0,0 +1,62 @@
+package proxy_test
+
+import (
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestFieldDictionary(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Dictionary: []config.FieldDefinition{
+			{Legacy: "CUST_STAT", Field: "status", Values: map[string]string{"01": "active", "02": "inactive"}},
+			{Legacy: "WGT_LB", Field: "weightKg", Type: "number", LegacyUnit: "lb", Unit: "kg"},
+			{Legacy: "AMT_CENTS", Field: "amount", Type: "int", Scale: 0.01},
+		},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `(active|inactive) shipments over`,
+			Endpoint:      "/shipments",
+			Method:        "GET",
+			ParameterMappings: []config.ParameterMapping{
+				{Source: "text", Pattern: `(active|inactive)`, Target: "query.status"},
+				{Source: "text", Pattern: `over ([\d.]+) ?kg`, Target: "weightKg"},
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	request, task := roundTrip(t, ct, `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"inactive shipments over 4.5359237 kg"}]}}}`, map[string]interface{}{
+		"shipments": []interface{}{
+			map[string]interface{}{"ID": "S1", "CUST_STAT": "01", "WGT_LB": "22", "AMT_CENTS": "1999"},
+			map[string]interface{}{"ID": "S2", "CUST_STAT": "09", "WGT_LB": 11.0, "AMT_CENTS": 250},
+		},
+	})
+
+	params := request["params"].(map[string]interface{})
+	if query := params["query"].(map[string]interface{}); query["CUST_STAT"] != "02" {
+		t.Errorf("Expected the legacy status code, got %v", params)
+	}
+	if params["WGT_LB"] != float64(10) || params["weightKg"] != nil {
+		t.Errorf("Expected the weight in pounds under its legacy name, got %v", params)
+	}
+
+	var data map[string]interface{}
+	for _, p := range task["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{}) {
+		if part := p.(map[string]interface{}); part["type"] == "data" {
+			data = part["data"].(map[string]interface{})
+		}
+	}
+	shipments := data["shipments"].([]interface{})
+	first, second := shipments[0].(map[string]interface{}), shipments[1].(map[string]interface{})
+	if first["status"] != "active" || first["weightKg"] != 9.97903214 || first["amount"] != 19.99 || first["ID"] != "S1" || first["CUST_STAT"] != nil {
+		t.Errorf("Unexpected canonical fields %v", first)
+	}
+	if second["status"] != "09" || second["weightKg"] != 4.98951607 || second["amount"] != 2.5 {
+		t.Errorf("Expected unknown codes to be kept, got %v", second)
+	}
+}
@@ -0,0 +1,38 @@
+package proxy
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/egress"
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// applyEgress strips the data classes the egress policy keeps in the connector
+// from a legacy response and records what it found in the meta. For a
+// response with data of a block class it returns the error response to send
+// instead, and nil otherwise.
+func (t *ConfigTransformer) applyEgress(legacyResponse map[string]interface{}, taskID, mappingID, locale string) map[string]interface{} {
+	violations, blocked := t.Egress.Apply(legacyResponse, "meta")
+	if len(violations) == 0 {
+		return nil
+	}
+	slog.Warn("egress policy violated", logging.KeyTaskID, taskID, logging.KeyMappingID, mappingID, "violations", violations, "blocked", blocked)
+
+	meta, ok := legacyResponse["meta"].(map[string]interface{})
+	if !ok {
+		meta = make(map[string]interface{})
+	}
+	meta[egress.MetadataKey] = violations
+	legacyResponse["meta"] = meta
+	if !blocked {
+		return nil
+	}
+	classes := strings.Join(egress.BlockedClasses(violations), ", ")
+	return map[string]interface{}{
+		"status": "error",
+		"error":  t.Messages.T(locale, i18n.MsgWithheld, classes),
+		"meta":   meta,
+	}
+}
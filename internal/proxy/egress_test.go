@@ -0,0 +1,54 @@
+package proxy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestResponseEgress(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `show employee`,
+			Endpoint:      "/employees/42",
+			Method:        "GET",
+		}},
+		Egress: &config.EgressConfig{Classes: []config.DataClassConfig{
+			{Name: "internal-only", Fields: []string{"costCenter"}},
+			{Name: "payroll", Fields: []string{"salary"}, Action: "block"},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+	task := `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"show employee"}]}}}`
+
+	_, out := roundTrip(t, ct, task, map[string]interface{}{"name": "Ada", "costCenter": "CC-7"})
+	status := out["status"].(map[string]interface{})
+	if status["state"] != "completed" {
+		t.Errorf("Expected stripped responses to complete, got %v", status["state"])
+	}
+	parts := status["message"].(map[string]interface{})["parts"].([]interface{})
+	if data := parts[len(parts)-1].(map[string]interface{})["data"].(map[string]interface{}); data["costCenter"] != nil || data["name"] != "Ada" {
+		t.Errorf("Expected costCenter to be stripped, got %v", data)
+	}
+	violations, _ := out["metadata"].(map[string]interface{})["egressViolations"].([]interface{})
+	if len(violations) != 1 || violations[0].(map[string]interface{})["path"] != "result.costCenter" {
+		t.Errorf("Expected the violation in the task metadata, got %v", out["metadata"])
+	}
+
+	_, out = roundTrip(t, ct, task, map[string]interface{}{"name": "Ada", "salary": 90000})
+	status = out["status"].(map[string]interface{})
+	if status["state"] != "failed" {
+		t.Errorf("Expected blocked responses to fail, got %v", status["state"])
+	}
+	for _, part := range status["message"].(map[string]interface{})["parts"].([]interface{}) {
+		if part.(map[string]interface{})["type"] == "data" || strings.Contains(part.(map[string]interface{})["text"].(string), "90000") {
+			t.Errorf("Expected the blocked response to be withheld, got %v", part)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+)
+
+// fanOutMetaKey is the legacy request meta key holding the branches of a
+// fan-out mapping; after they run it holds what each branch did
+const fanOutMetaKey = "fanOut"
+
+// Fan-out policies
+const (
+	FanOutPartial = "partial"
+	FanOutAll     = "all"
+)
+
+// FanOutError is returned when the branches that failed make a fan-out
+// mapping fail under its policy
+type FanOutError struct {
+	Policy string
+	Failed map[string]string
+}
+
+func (e *FanOutError) Error() string {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("fan-out branches failed: %s", strings.Join(names, ", "))
+}
+
+// Details implements adapter.DetailedError with the error of each failed
+// branch
+func (e *FanOutError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"policy":         e.Policy,
+		"failedBranches": e.Failed,
+	}
+}
+
+// fanOutMeta returns the branches and policy of a fan-out mapping for the
+// legacy request meta
+func fanOutMeta(mapping *config.MappingConfig) map[string]interface{} {
+	policy := mapping.FanOut.Policy
+	if policy == "" {
+		policy = FanOutPartial
+	}
+	return map[string]interface{}{
+		"policy":   policy,
+		"branches": stepsMeta(mapping, mapping.FanOut.Branches),
+	}
+}
+
+// HasFanOut reports whether a legacy request meta holds the branches of a
+// fan-out mapping
+func HasFanOut(meta map[string]interface{}) bool {
+	return meta[fanOutMetaKey] != nil
+}
+
+// RunFanOut calls the branches in the legacy request meta in parallel, each
+// within its timeout, and returns their results by branch name. Branches
+// whose when condition does not hold are skipped. Whether failed branches
+// fail the mapping depends on the policy; the results of the others are
+// returned either way. Meta is updated with the outcome of each branch.
+func RunFanOut(ctx context.Context, meta map[string]interface{}, params map[string]interface{}, exec StepExecutor) (map[string]interface{}, error) {
+	var fanOut struct {
+		Policy   string              `json:"policy"`
+		Branches []config.StepConfig `json:"branches"`
+	}
+	data, _ := json.Marshal(meta[fanOutMetaKey])
+	if err := json.Unmarshal(data, &fanOut); err != nil {
+		return nil, fmt.Errorf("invalid fan-out: %w", err)
+	}
+	scope := map[string]interface{}{"params": params}
+	lookup := func(path string) interface{} { return getValueByPath(scope, path) }
+
+	outcomes := make([]map[string]interface{}, len(fanOut.Branches))
+	results := make([]map[string]interface{}, len(fanOut.Branches))
+	errs := make([]error, len(fanOut.Branches))
+	var wg sync.WaitGroup
+	for i, branch := range fanOut.Branches {
+		outcomes[i] = map[string]interface{}{"name": branch.Name}
+		if branch.When != "" {
+			when, err := expr.Parse(branch.When)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			if !when.Match(lookup) {
+				outcomes[i]["status"] = StepSkipped
+				continue
+			}
+		}
+		call, err := renderStep(branch, scope)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		outcomes[i]["endpoint"] = call.Endpoint
+
+		wg.Add(1)
+		go func(i int, call StepCall) {
+			defer wg.Done()
+			start := time.Now()
+			results[i], errs[i] = call.run(ctx, exec)
+			outcomes[i]["durationMs"] = float64(time.Since(start).Microseconds()) / 1000
+		}(i, call)
+	}
+	wg.Wait()
+
+	combined := make(map[string]interface{})
+	failed := make(map[string]string)
+	for i, branch := range fanOut.Branches {
+		switch {
+		case errs[i] != nil:
+			outcomes[i]["status"] = StepFailed
+			outcomes[i]["error"] = errs[i].Error()
+			failed[branch.Name] = errs[i].Error()
+		case outcomes[i]["status"] == nil:
+			outcomes[i]["status"] = StepSucceeded
+			combined[branch.Name] = results[i]
+		}
+	}
+	branches := make([]interface{}, len(outcomes))
+	for i, outcome := range outcomes {
+		branches[i] = outcome
+	}
+	meta[fanOutMetaKey] = branches
+
+	if len(failed) > 0 && (fanOut.Policy == FanOutAll || len(combined) == 0) {
+		return combined, &FanOutError{Policy: fanOut.Policy, Failed: failed}
+	}
+	return combined, nil
+}
+
+// fanOutParts returns a data part per branch of a fan-out response, in branch
+// order, with the branch name and status as part metadata
+func fanOutParts(legacyResponse map[string]interface{}) ([]map[string]interface{}, bool) {
+	meta, _ := legacyResponse["meta"].(map[string]interface{})
+	branches, ok := meta[fanOutMetaKey].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	result, _ := legacyResponse["result"].(map[string]interface{})
+
+	var parts []map[string]interface{}
+	for _, branch := range branches {
+		outcome, _ := branch.(map[string]interface{})
+		name, _ := outcome["name"].(string)
+		status, _ := outcome["status"].(string)
+		if status == StepSkipped {
+			continue
+		}
+		data := result[name]
+		if status == StepFailed {
+			data = map[string]interface{}{"error": outcome["error"]}
+		}
+		parts = append(parts, map[string]interface{}{
+			"type":     "data",
+			"data":     data,
+			"metadata": map[string]interface{}{"source": name, "status": status},
+		})
+	}
+	return parts, true
+}
@@ -0,0 +1,132 @@
+package proxy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestRunFanOut(t *testing.T) {
+	newTransformer := func(policy string) *proxy.ConfigTransformer {
+		cfg := &config.ConnectorConfig{
+			Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+			Mappings: []config.MappingConfig{{
+				IntentPattern: `customer overview`,
+				Method:        "GET",
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Pattern: `customer overview (\w+)`, Target: "id"},
+				},
+				FanOut: &config.FanOutConfig{
+					Policy: policy,
+					Branches: []config.StepConfig{
+						{Name: "profile", Endpoint: "/customers/{id}"},
+						{Name: "orders", Endpoint: "/orders?customer={id}"},
+						{Name: "billing", Endpoint: "/billing/{{.params.id}}", Timeout: "50ms"},
+						{Name: "loyalty", Endpoint: "/loyalty/{id}", When: "params.id == 'vip'"},
+					},
+				},
+			}},
+		}
+		if err := cfg.Compile(); err != nil {
+			t.Fatalf("Failed to compile config: %v", err)
+		}
+		if err := config.ValidateConfig(cfg); err != nil {
+			t.Fatalf("Expected a valid config: %v", err)
+		}
+		return proxy.NewConfigTransformer(cfg)
+	}
+
+	run := func(ct *proxy.ConfigTransformer) (map[string]interface{}, map[string]interface{}, []proxy.StepCall, error) {
+		data, err := ct.TransformRequestData([]byte(`{"id":"t1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"customer overview c42"}]}}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var request map[string]interface{}
+		json.Unmarshal(data, &request)
+		meta := request["meta"].(map[string]interface{})
+		if !proxy.HasFanOut(meta) || proxy.HasSteps(meta) {
+			t.Fatal("Expected the fan-out branches in the request meta")
+		}
+
+		// profile and orders only return once both were called, so they have
+		// to run in parallel; billing is slower than its timeout
+		var mu sync.Mutex
+		var calls []proxy.StepCall
+		var both sync.WaitGroup
+		both.Add(2)
+		results, err := proxy.RunFanOut(context.Background(), meta, request["params"].(map[string]interface{}), func(ctx context.Context, call proxy.StepCall) (map[string]interface{}, error) {
+			mu.Lock()
+			calls = append(calls, call)
+			mu.Unlock()
+			switch call.Name {
+			case "profile", "orders":
+				both.Done()
+				done := make(chan struct{})
+				go func() { both.Wait(); close(done) }()
+				select {
+				case <-done:
+				case <-time.After(time.Second):
+					return nil, errors.New("branches ran sequentially")
+				}
+				return map[string]interface{}{"endpoint": call.Endpoint}, nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		return results, meta, calls, err
+	}
+
+	results, meta, calls, err := run(newTransformer(""))
+	if err != nil {
+		t.Fatalf("Expected the partial policy to tolerate the billing timeout: %v", err)
+	}
+	if len(calls) != 3 || len(results) != 2 {
+		t.Fatalf("Unexpected calls %+v and results %v", calls, results)
+	}
+	if orders, _ := results["orders"].(map[string]interface{}); orders["endpoint"] != "/orders?customer=c42" {
+		t.Errorf("Unexpected orders result %v", results["orders"])
+	}
+	outcomes := meta["fanOut"].([]interface{})
+	billing := outcomes[2].(map[string]interface{})
+	if billing["status"] != proxy.StepFailed || billing["endpoint"] != "/billing/c42" || billing["error"] != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected billing to time out, got %v", billing)
+	}
+	if loyalty := outcomes[3].(map[string]interface{}); loyalty["status"] != proxy.StepSkipped {
+		t.Errorf("Expected loyalty to be skipped, got %v", loyalty)
+	}
+
+	// The task has a data part per branch that was called
+	response, _ := json.Marshal(map[string]interface{}{"status": "success", "result": results, "meta": meta})
+	data, err := newTransformer("").TransformResponseData(response)
+	if err != nil {
+		t.Fatalf("TransformResponseData: %v", err)
+	}
+	var task map[string]interface{}
+	json.Unmarshal(data, &task)
+	sources := map[string]interface{}{}
+	for _, part := range task["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{}) {
+		part := part.(map[string]interface{})
+		if metadata, ok := part["metadata"].(map[string]interface{}); ok {
+			sources[metadata["source"].(string)] = part["data"]
+		}
+	}
+	if len(sources) != 3 || sources["profile"].(map[string]interface{})["endpoint"] != "/customers/c42" || sources["billing"].(map[string]interface{})["error"] == nil {
+		t.Errorf("Expected a data part per source, got %v", sources)
+	}
+
+	// The all policy fails the task when any branch fails
+	results, _, _, err = run(newTransformer("all"))
+	var fanOutErr *proxy.FanOutError
+	if !errors.As(err, &fanOutErr) || len(results) != 2 {
+		t.Fatalf("Expected a fan-out error, got %v with %v", err, results)
+	}
+	if failed := fanOutErr.Details()["failedBranches"].(map[string]string); len(failed) != 1 || failed["billing"] == "" {
+		t.Errorf("Unexpected details %v", fanOutErr.Details())
+	}
+}
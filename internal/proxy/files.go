@@ -0,0 +1,202 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// fileFetchTimeout limits fetching the uri of a FilePart when the transformer
+// has no FileClient
+const fileFetchTimeout = 30 * time.Second
+
+// taskFile is a file of a FilePart of a task, with either its content as
+// base64 or a uri
+type taskFile struct {
+	name     string
+	mimeType string
+	bytes    string
+	uri      string
+}
+
+// taskFiles returns the files of the FileParts of the task message
+func taskFiles(taskMap map[string]interface{}) []taskFile {
+	status, _ := taskMap["status"].(map[string]interface{})
+	message, _ := status["message"].(map[string]interface{})
+	parts, _ := message["parts"].([]interface{})
+
+	var files []taskFile
+	for _, part := range parts {
+		partMap, _ := part.(map[string]interface{})
+		if partMap["type"] != "file" {
+			continue
+		}
+		file, ok := partMap["file"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		f := taskFile{}
+		f.name, _ = file["name"].(string)
+		f.mimeType, _ = file["mimeType"].(string)
+		f.bytes, _ = file["bytes"].(string)
+		f.uri, _ = file["uri"].(string)
+		// Rules match files without a media type by their extension
+		if f.mimeType == "" {
+			f.mimeType, _, _ = mime.ParseMediaType(mime.TypeByExtension(path.Ext(f.name)))
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// placeFiles puts the files of the task in params as the file rules of the
+// mapping say. Each file goes to the first rule matching its media type; a
+// required rule without a file fails the task.
+func (t *ConfigTransformer) placeFiles(rules []config.FileRule, taskMap map[string]interface{}, params map[string]interface{}) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	files := taskFiles(taskMap)
+	placed := make([]bool, len(files))
+
+	for _, rule := range rules {
+		var matched []int
+		for i, file := range files {
+			if placed[i] {
+				continue
+			}
+			if ok, _ := path.Match(rule.MimeType, file.mimeType); ok || rule.MimeType == "" {
+				matched = append(matched, i)
+			}
+		}
+		if len(matched) == 0 {
+			if rule.Required {
+				return fmt.Errorf("task has no file for %s", rule.Target)
+			}
+			continue
+		}
+		if rule.Target != "multipart" {
+			matched = matched[:1]
+		}
+
+		for _, i := range matched {
+			placed[i] = true
+			file := files[i]
+			content, mimeType, err := t.fileContent(rule, file)
+			if err != nil {
+				return fmt.Errorf("file %s: %w", file.name, err)
+			}
+			if rule.Target == "multipart" {
+				addMultipartFile(params, rule.Field, file.name, mimeType, content)
+				continue
+			}
+
+			if rule.Encoding == "text" {
+				setValue(params, rule.Target, string(content))
+			} else {
+				setValue(params, rule.Target, base64.StdEncoding.EncodeToString(content))
+			}
+			if rule.NameTarget != "" {
+				setValue(params, rule.NameTarget, file.name)
+			}
+			if rule.MimeTypeTarget != "" {
+				setValue(params, rule.MimeTypeTarget, mimeType)
+			}
+		}
+	}
+	return nil
+}
+
+// addMultipartFile adds a file to the multipart body of a REST call
+func addMultipartFile(params map[string]interface{}, field, name, mimeType string, content []byte) {
+	multipart, _ := params["multipart"].(map[string]interface{})
+	if multipart == nil {
+		multipart = make(map[string]interface{})
+		params["multipart"] = multipart
+	}
+	if field == "" {
+		field = "file"
+	}
+	if name == "" {
+		name = field
+	}
+	files, _ := multipart["files"].([]interface{})
+	multipart["files"] = append(files, map[string]interface{}{
+		"field":         field,
+		"filename":      name,
+		"contentType":   mimeType,
+		"contentBase64": base64.StdEncoding.EncodeToString(content),
+	})
+}
+
+// fileContent returns the content and media type of a file, fetching it when
+// the task only gives its uri
+func (t *ConfigTransformer) fileContent(rule config.FileRule, file taskFile) ([]byte, string, error) {
+	if file.bytes != "" || file.uri == "" {
+		content, err := decodeBase64(file.bytes)
+		return content, file.mimeType, err
+	}
+
+	u, err := url.Parse(file.uri)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, "", fmt.Errorf("unsupported uri %q", file.uri)
+	}
+	if !hostAllowed(rule.AllowedHosts, u.Hostname()) {
+		return nil, "", fmt.Errorf("host %s is not in allowedHosts", u.Hostname())
+	}
+
+	// Redirects must stay on the allowed hosts too
+	client := t.FileClient
+	if client == nil {
+		client = &http.Client{
+			Timeout: fileFetchTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 10 {
+					return fmt.Errorf("stopped after 10 redirects")
+				}
+				if !hostAllowed(rule.AllowedHosts, req.URL.Hostname()) {
+					return fmt.Errorf("redirect to host %s is not in allowedHosts", req.URL.Hostname())
+				}
+				return nil
+			},
+		}
+	}
+	resp, err := client.Get(file.uri)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", fmt.Errorf("fetching %s returned HTTP %s", u.Redacted(), resp.Status)
+	}
+
+	// Fetched files count against the request body limit
+	limit, _, _ := t.Config.BodyLimits.Sizes()
+	content, err := ioutil.ReadAll(newLimitedReader(resp.Body, limit))
+	if err != nil {
+		return nil, "", err
+	}
+	mimeType := file.mimeType
+	if mimeType == "" {
+		mimeType = resp.Header.Get("Content-Type")
+	}
+	return content, mimeType, nil
+}
+
+// hostAllowed reports whether host is one of the allowed hosts
+func hostAllowed(allowed []string, host string) bool {
+	for _, h := range allowed {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package proxy_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func fileTask(parts ...string) string {
+	return `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"upload invoice"},` + strings.Join(parts, ",") + `]}}}`
+}
+
+func TestTaskFiles(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("fetched scan"))
+	}))
+	defer files.Close()
+	filesURL, _ := url.Parse(files.URL)
+
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `upload invoice`,
+			Endpoint:      "/invoices",
+			Method:        "POST",
+			Files: []config.FileRule{
+				{MimeType: "application/pdf", Target: "multipart", Field: "document", Required: true},
+				{MimeType: "image/*", Target: "body.scan", Encoding: "text", NameTarget: "body.scanName", MimeTypeTarget: "body.scanType", AllowedHosts: []string{filesURL.Hostname()}},
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	pdf := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4"))
+	data, err := ct.TransformRequestData([]byte(fileTask(
+		`{"type":"file","file":{"name":"invoice.pdf","mimeType":"application/pdf","bytes":"`+pdf+`"}}`,
+		`{"type":"file","file":{"name":"scan.png","uri":"`+files.URL+`/scan.png"}}`,
+	)))
+	if err != nil {
+		t.Fatalf("TransformRequestData: %v", err)
+	}
+	var request struct {
+		Params struct {
+			Multipart struct {
+				Files []map[string]interface{} `json:"files"`
+			} `json:"multipart"`
+			Body map[string]interface{} `json:"body"`
+		} `json:"params"`
+	}
+	json.Unmarshal(data, &request)
+
+	uploads := request.Params.Multipart.Files
+	if len(uploads) != 1 || uploads[0]["field"] != "document" || uploads[0]["filename"] != "invoice.pdf" || uploads[0]["contentBase64"] != pdf {
+		t.Errorf("Unexpected multipart files %v", uploads)
+	}
+	if body := request.Params.Body; body["scan"] != "fetched scan" || body["scanName"] != "scan.png" || body["scanType"] != "image/png" {
+		t.Errorf("Expected the fetched file in the body, got %v", body)
+	}
+
+	// Required files must be present, and uris are only fetched from allowed hosts
+	if _, err := ct.TransformRequestData([]byte(fileTask(`{"type":"file","file":{"name":"a.txt","mimeType":"text/plain","bytes":""}}`))); err == nil {
+		t.Error("Expected a task without the required file to fail")
+	}
+	if _, err := ct.TransformRequestData([]byte(fileTask(
+		`{"type":"file","file":{"name":"invoice.pdf","mimeType":"application/pdf","bytes":"`+pdf+`"}}`,
+		`{"type":"file","file":{"name":"scan.png","mimeType":"image/png","uri":"http://metadata.internal/scan.png"}}`,
+	))); err == nil || !strings.Contains(err.Error(), "allowedHosts") {
+		t.Errorf("Expected a uri on another host to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// responseFormatMetaKey is the legacy request meta key holding the format the
+// legacy response is parsed in
+const responseFormatMetaKey = "responseFormat"
+
+// xmlRootElement wraps XML bodies that do not name their own root element
+const xmlRootElement = "request"
+
+// mediaTypes are the media types of the body formats
+var mediaTypes = map[string]string{
+	"json": "application/json",
+	"xml":  "application/xml",
+	"form": "application/x-www-form-urlencoded",
+	"text": "text/plain",
+}
+
+// formatRequest moves the body param into the param the REST adapter encodes
+// in the request format of the mapping, or renders the body with the request
+// template of the mapping, and asks for responses in its response format with
+// an Accept header
+func formatRequest(mapping *config.MappingConfig, taskMap map[string]interface{}, params map[string]interface{}) error {
+	if mapping.CompiledRequest != nil {
+		text, _ := extractTextFromTask(taskMap)
+		var buf bytes.Buffer
+		if err := mapping.CompiledRequest.Execute(&buf, map[string]interface{}{"task": taskMap, "text": text, "params": params}); err != nil {
+			return fmt.Errorf("request template: %w", err)
+		}
+		format := mapping.RequestFormat
+		if format == "" {
+			format = "json"
+		}
+		delete(params, "body")
+		params["textBody"] = strings.ReplaceAll(buf.String(), "<no value>", "")
+		params["contentType"] = mediaTypes[format]
+	} else if body, ok := params["body"]; ok && body != nil {
+		switch mapping.RequestFormat {
+		case "xml":
+			doc, ok := body.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("xml body must be an object, got %T", body)
+			}
+			if !hasXMLRoot(doc) {
+				doc = map[string]interface{}{xmlRootElement: doc}
+			}
+			params["xml"] = doc
+			delete(params, "body")
+		case "form":
+			if _, ok := body.(map[string]interface{}); !ok {
+				return fmt.Errorf("form body must be an object, got %T", body)
+			}
+			params["form"] = body
+			delete(params, "body")
+		case "text":
+			text, ok := body.(string)
+			if !ok {
+				data, err := json.Marshal(body)
+				if err != nil {
+					return err
+				}
+				text = string(data)
+			}
+			params["textBody"] = text
+			delete(params, "body")
+		}
+	}
+
+	if accept, ok := mediaTypes[mapping.ResponseFormat]; ok {
+		headers, _ := params["headers"].(map[string]interface{})
+		if headers == nil {
+			headers = make(map[string]interface{})
+			params["headers"] = headers
+		}
+		if headers["Accept"] == nil {
+			headers["Accept"] = accept
+		}
+	}
+	return nil
+}
+
+// hasXMLRoot reports whether an XML body is a single element holding the
+// rest of the document
+func hasXMLRoot(doc map[string]interface{}) bool {
+	if len(doc) != 1 {
+		return false
+	}
+	for _, value := range doc {
+		_, isMap := value.(map[string]interface{})
+		return isMap
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package proxy_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestBodyFormats(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{
+			{
+				IntentPattern:  `create order`,
+				Endpoint:       "/orders",
+				Method:         "POST",
+				RequestFormat:  "xml",
+				ResponseFormat: "xml",
+				Params:         map[string]interface{}{"method": "POST"},
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Pattern: `for (\w+)`, Target: "body.customer"},
+					{Source: "text", Pattern: `(\d+) units`, Target: "body.qty", Type: "int"},
+				},
+			},
+			{
+				IntentPattern: `cancel order`,
+				Endpoint:      "/soap",
+				Method:        "POST",
+				RequestFormat: "xml",
+				RequestTemplate: `<Envelope><Body><Cancel id="{{.params.id}}" task="{{.task.id}}">` +
+					`<Reason>{{.text | xml}}</Reason><Note>{{.params.note}}</Note></Cancel></Body></Envelope>`,
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Pattern: `cancel order (\d+)`, Target: "id"},
+				},
+			},
+			{
+				IntentPattern: `send note`,
+				Endpoint:      "/notes",
+				Method:        "POST",
+				RequestFormat: "text",
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Pattern: `send note (.+)`, Target: "body"},
+				},
+			},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected a valid config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	request := func(text string) (map[string]interface{}, map[string]interface{}) {
+		data, err := ct.TransformRequestData([]byte(`{"id":"t1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"` + text + `"}]}}}`))
+		if err != nil {
+			t.Fatalf("TransformRequestData: %v", err)
+		}
+		var req map[string]interface{}
+		json.Unmarshal(data, &req)
+		return req["params"].(map[string]interface{}), req["meta"].(map[string]interface{})
+	}
+
+	// Bodies without a root element are wrapped in one
+	params, meta := request("create order for acme 3 units")
+	doc, _ := params["xml"].(map[string]interface{})
+	order, _ := doc["request"].(map[string]interface{})
+	if params["body"] != nil || order["customer"] != "acme" || order["qty"] != 3.0 {
+		t.Errorf("Expected the body as an XML document, got %v", params)
+	}
+	if meta["responseFormat"] != "xml" || params["headers"].(map[string]interface{})["Accept"] != "application/xml" {
+		t.Errorf("Expected XML responses to be requested, got %v %v", meta, params["headers"])
+	}
+
+	params, meta = request("send note call back tomorrow")
+	if params["textBody"] != "call back tomorrow" || params["body"] != nil || meta["responseFormat"] != nil {
+		t.Errorf("Expected a text body, got %v", params)
+	}
+
+	// Request templates render the whole body from the task and params
+	params, _ = request("cancel order 42 & refund")
+	if params["textBody"] != `<Envelope><Body><Cancel id="42" task="t1"><Reason>cancel order 42 &amp; refund</Reason><Note></Note></Cancel></Body></Envelope>` ||
+		params["contentType"] != "application/xml" {
+		t.Errorf("Unexpected templated body %v", params)
+	}
+
+	cfg.Mappings[2].ResponseFormat = "yaml"
+	if err := config.ValidateConfig(cfg); err == nil {
+		t.Error("Expected an unsupported response format to be rejected")
+	}
+}
@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// mappingHeaders renders the headers of the mapping into the per-call
+// headers of params, overriding static ones. Values are templates over
+// .params, .metadata (the task metadata) and .task; a value that renders
+// empty is kept empty, which removes the header from the call, so headers
+// can be set conditionally and adapter defaults dropped per mapping.
+func mappingHeaders(headers map[string]string, taskMap map[string]interface{}, params map[string]interface{}) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	metadata, _ := taskMap["metadata"].(map[string]interface{})
+	scope := map[string]interface{}{"params": params, "metadata": metadata, "task": taskMap}
+
+	callHeaders, _ := params["headers"].(map[string]interface{})
+	if callHeaders == nil {
+		callHeaders = make(map[string]interface{})
+	}
+	for name, value := range headers {
+		rendered, err := renderTemplate(value, scope)
+		if err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+		callHeaders[name] = rendered
+	}
+	params["headers"] = callHeaders
+	return nil
+}
+
+// setHeaders applies the per-call headers of a legacy request to a
+// forwarded request; empty values remove the header
+func setHeaders(header http.Header, headers map[string]interface{}) {
+	for name, value := range headers {
+		if s := fmt.Sprint(value); value != nil && s != "" {
+			header.Set(name, s)
+		} else {
+			header.Del(name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package proxy_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestProxyMappingHeaders(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Length") != "" && r.Header.Get("Content-Length") != strconv.Itoa(len(body)) {
+			t.Errorf("Content-Length %s does not match the body of %d bytes", r.Header.Get("Content-Length"), len(body))
+		}
+		requests <- r
+		w.Header().Set("Server", "legacy/1.0")
+		w.Write([]byte(`{"status":"success","result":{}}`))
+	}))
+	defer backend.Close()
+
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: backend.URL, Headers: map[string]string{"Authorization": "Bearer default"}},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `create order`,
+			Endpoint:      "/orders",
+			Method:        "POST",
+			Headers: map[string]string{
+				"X-Tenant":      "{{.metadata.tenant}}",
+				"X-Channel":     `{{if eq .metadata.channel "phone"}}voice{{end}}`,
+				"Authorization": "",
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	transformer := proxy.NewConfigTransformer(cfg)
+	transformer.RemoveRequestHeader("Cookie")
+	transformer.RemoveResponseHeader("Server")
+	p, err := proxy.NewProxy(backend.URL, &transformer.Transformer)
+	if err != nil {
+		t.Fatalf("Failed to create proxy: %v", err)
+	}
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	send := func(channel string) (*http.Request, *http.Response) {
+		task := `{"id":"t1","metadata":{"tenant":"acme","channel":"` + channel + `"},"status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"create order"}]}}}`
+		req, _ := http.NewRequest(http.MethodPost, front.URL, strings.NewReader(task))
+		req.Header.Set("Cookie", "session=abc")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return <-requests, resp
+	}
+
+	got, resp := send("phone")
+	if got.Header.Get("X-Tenant") != "acme" || got.Header.Get("X-Channel") != "voice" {
+		t.Errorf("Expected the headers rendered from the task metadata, got %v", got.Header)
+	}
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if _, ok := got.Header[name]; ok {
+			t.Errorf("Expected %s to be removed, got %v", name, got.Header)
+		}
+	}
+	if resp.Header.Get("Server") != "" {
+		t.Errorf("Expected the Server response header to be removed, got %v", resp.Header)
+	}
+
+	// Headers that render empty are not sent
+	if got, _ := send("web"); got.Header.Get("X-Tenant") != "acme" || got.Header["X-Channel"] != nil {
+		t.Errorf("Expected no channel header, got %v", got.Header)
+	}
+}
@@ -0,0 +1,182 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// StatusInputRequired is the legacy response status of a task that asked for
+// input before its legacy call
+const StatusInputRequired = "input-required"
+
+// inputMetaKey carries the param and prompt of a task that asks for input;
+// it is part of the task metadata
+const inputMetaKey = "input"
+
+// inputParamsMetaKey carries the params of a mapping that may ask for input
+// after its legacy call, before they are formatted, to resume the task with;
+// it is removed before the task is returned
+const inputParamsMetaKey = "inputParams"
+
+// DefaultInputTTL is how long a task waits for the input it asked for
+const DefaultInputTTL = 30 * time.Minute
+
+// TaskStore keeps the tasks that wait for input, so the next message of a
+// task resumes it with the mapping and params it had
+type TaskStore struct {
+	backend cache.Cache
+}
+
+// NewTaskStore creates a task store on a cache backend; nil keeps the tasks
+// in memory
+func NewTaskStore(backend cache.Cache) *TaskStore {
+	if backend == nil {
+		backend = cache.NewLRU(0)
+	}
+	return &TaskStore{backend: backend}
+}
+
+// pendingTask is a task waiting for the answer to an input rule of its mapping
+type pendingTask struct {
+	Mapping string                 `json:"mapping"`
+	Params  map[string]interface{} `json:"params"`
+	Rule    int                    `json:"rule"`
+}
+
+// put stores a task waiting for input
+func (s *TaskStore) put(taskID string, task pendingTask, ttl time.Duration) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		slog.Warn("failed to store task waiting for input", logging.KeyTaskID, taskID, logging.KeyError, err)
+		return
+	}
+	s.backend.Set(taskID, data, ttl)
+}
+
+// take removes and returns the task waiting for input under taskID
+func (s *TaskStore) take(taskID string) (pendingTask, bool) {
+	var task pendingTask
+	data, ok := s.backend.Get(taskID)
+	if !ok {
+		return task, false
+	}
+	s.backend.Delete(taskID)
+	return task, json.Unmarshal(data, &task) == nil
+}
+
+// AwaitsInput reports whether the legacy request of a task asks for input
+// instead of calling the legacy system
+func AwaitsInput(meta map[string]interface{}) bool {
+	_, ok := meta[inputMetaKey]
+	return ok
+}
+
+// resumeInput returns the mapping and params of a task that waited for input,
+// with the answer in its text set at the param of the input rule. It returns
+// a nil mapping for tasks that are not waiting. An answer that does not match
+// the pattern or type of the rule is left out, so the task asks again.
+func (t *ConfigTransformer) resumeInput(taskMap map[string]interface{}) (*config.MappingConfig, map[string]interface{}) {
+	taskID, _ := taskMap["id"].(string)
+	if taskID == "" {
+		return nil, nil
+	}
+	pending, ok := t.Tasks.take(taskID)
+	if !ok {
+		return nil, nil
+	}
+	var mapping *config.MappingConfig
+	for i := range t.Config.Mappings {
+		if t.Config.Mappings[i].IntentPattern == pending.Mapping {
+			mapping = &t.Config.Mappings[i]
+			break
+		}
+	}
+	if mapping == nil || pending.Rule >= len(mapping.Input) {
+		return nil, nil
+	}
+
+	params := pending.Params
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	rule := mapping.Input[pending.Rule]
+	text, _ := extractTextFromTask(taskMap)
+	answer := text
+	if rule.CompiledPattern != nil {
+		answer = ""
+		if matches := rule.CompiledPattern.FindStringSubmatch(text); len(matches) > 1 {
+			answer = matches[1]
+		} else if len(matches) == 1 {
+			answer = matches[0]
+		}
+	}
+	if answer != "" {
+		if value, err := convertParameter(answer, rule.Type); err == nil {
+			setValue(params, rule.Param, value)
+		}
+	}
+	return mapping, params
+}
+
+// requestInput asks for the first param of an input rule without a when
+// condition that is missing from params; it stores the task and returns the
+// input meta. Otherwise, when an input rule of the mapping may ask after the
+// legacy call, it returns a copy of params to resume the task with.
+func (t *ConfigTransformer) requestInput(mapping *config.MappingConfig, taskMap, params map[string]interface{}) (input, inputParams map[string]interface{}) {
+	asksAfter := false
+	for i, rule := range mapping.Input {
+		if rule.CompiledWhen != nil {
+			asksAfter = true
+			continue
+		}
+		if value := getValueByPath(params, rule.Param); value != nil && value != "" {
+			continue
+		}
+		metadata, _ := taskMap["metadata"].(map[string]interface{})
+		return t.askInput(mapping, i, getTaskID(taskMap), params, map[string]interface{}{"params": params, "metadata": metadata}), nil
+	}
+	if asksAfter {
+		inputParams, _ = copyValue(params).(map[string]interface{})
+	}
+	return nil, inputParams
+}
+
+// responseInput asks for input when the when condition of an input rule holds
+// for a legacy response. It stores the task with params and returns the input
+// meta, or nil when no rule applies.
+func (t *ConfigTransformer) responseInput(mapping *config.MappingConfig, taskID string, params, legacyResponse map[string]interface{}) map[string]interface{} {
+	for i, rule := range mapping.Input {
+		if rule.CompiledWhen == nil || !rule.CompiledWhen.Match(func(path string) interface{} { return getValueByPath(legacyResponse, path) }) {
+			continue
+		}
+		return t.askInput(mapping, i, taskID, params, legacyResponse)
+	}
+	return nil
+}
+
+// askInput stores a task waiting for the input of a rule and returns the
+// input meta with the prompt rendered over scope
+func (t *ConfigTransformer) askInput(mapping *config.MappingConfig, rule int, taskID string, params, scope map[string]interface{}) map[string]interface{} {
+	input := mapping.Input[rule]
+	ttl := DefaultInputTTL
+	if d, err := time.ParseDuration(input.TTL); err == nil {
+		ttl = d
+	}
+	t.Tasks.put(taskID, pendingTask{Mapping: mapping.IntentPattern, Params: params, Rule: rule}, ttl)
+
+	prompt := input.Prompt
+	if input.CompiledPrompt != nil {
+		var buf bytes.Buffer
+		if err := input.CompiledPrompt.Execute(&buf, scope); err == nil {
+			prompt = strings.ReplaceAll(buf.String(), "<no value>", "")
+		}
+	}
+	return map[string]interface{}{"param": input.Param, "prompt": prompt}
+}
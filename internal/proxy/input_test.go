@@ -0,0 +1,131 @@
+package proxy_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func textTask(id, text string) string {
+	return `{"id":"` + id + `","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"` + text + `"}]}}}`
+}
+
+// respond answers a legacy request with status and result like executeTask
+func respond(t *testing.T, ct *proxy.ConfigTransformer, request map[string]interface{}, status string, result map[string]interface{}) map[string]interface{} {
+	response, _ := json.Marshal(map[string]interface{}{
+		"status": status,
+		"result": result,
+		"meta":   request["meta"],
+		"params": request["params"],
+	})
+	data, err := ct.TransformResponseData(response)
+	if err != nil {
+		t.Fatalf("TransformResponseData: %v", err)
+	}
+	var task map[string]interface{}
+	json.Unmarshal(data, &task)
+	return task
+}
+
+func transformTask(t *testing.T, ct *proxy.ConfigTransformer, task string) map[string]interface{} {
+	data, err := ct.TransformRequestData([]byte(task))
+	if err != nil {
+		t.Fatalf("TransformRequestData: %v", err)
+	}
+	var request map[string]interface{}
+	json.Unmarshal(data, &request)
+	return request
+}
+
+func taskState(task map[string]interface{}) (string, string) {
+	status := task["status"].(map[string]interface{})
+	parts := status["message"].(map[string]interface{})["parts"].([]interface{})
+	text, _ := parts[0].(map[string]interface{})["text"].(string)
+	return status["state"].(string), text
+}
+
+func TestInputRequired(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `find customer`,
+			Endpoint:      "/customers",
+			Method:        "GET",
+			ParameterMappings: []config.ParameterMapping{
+				{Source: "text", Pattern: `find customer (\w+)`, Target: "name"},
+			},
+			Input: []config.InputRule{
+				{Param: "name", Prompt: "Which customer?"},
+				{Param: "id", When: "result.matches > 1", Prompt: "{{.result.matches}} customers match, which id?", Pattern: `(\d+)`, Type: "int"},
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	// A missing param is asked for before the legacy call
+	request := transformTask(t, ct, textTask("task-1", "find customer"))
+	meta := request["meta"].(map[string]interface{})
+	if !proxy.AwaitsInput(meta) {
+		t.Fatalf("Expected the task to ask for the name, got %v", request)
+	}
+	task := respond(t, ct, request, proxy.StatusInputRequired, nil)
+	if state, text := taskState(task); state != "input-required" || text != "Which customer?" {
+		t.Errorf("Expected an input-required task with the prompt, got %s %q", state, text)
+	}
+
+	// The answer resumes the mapping, which then asks which of the matches
+	request = transformTask(t, ct, textTask("task-1", "ACME"))
+	if params := request["params"].(map[string]interface{}); params["name"] != "ACME" || proxy.AwaitsInput(request["meta"].(map[string]interface{})) {
+		t.Fatalf("Expected the answer in the params, got %v", request)
+	}
+	task = respond(t, ct, request, "success", map[string]interface{}{"matches": 2})
+	if state, text := taskState(task); state != "input-required" || text != "2 customers match, which id?" {
+		t.Errorf("Expected the response to ask for the id, got %s %q", state, text)
+	}
+	if metadata := task["metadata"].(map[string]interface{}); metadata["input"] == nil || metadata["inputParams"] != nil {
+		t.Errorf("Unexpected task metadata %v", metadata)
+	}
+
+	request = transformTask(t, ct, textTask("task-1", "the one with id 42"))
+	if params := request["params"].(map[string]interface{}); params["name"] != "ACME" || params["id"] != float64(42) {
+		t.Fatalf("Expected the original params with the id, got %v", params)
+	}
+	task = respond(t, ct, request, "success", map[string]interface{}{"matches": 1})
+	if state, _ := taskState(task); state != "completed" {
+		t.Errorf("Expected the resumed task to complete, got %s", state)
+	}
+
+	// Other tasks are matched by their text
+	if _, err := ct.TransformRequestData([]byte(textTask("task-1", "ACME"))); err == nil {
+		t.Error("Expected a finished task not to resume")
+	}
+}
+
+func TestPendingStatus(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter:  config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{IntentPattern: `idoc status`, Endpoint: "/idocs", Method: "GET"}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+	request := transformTask(t, ct, textTask("task-1", "idoc status"))
+
+	for status, expected := range map[string]string{
+		"success":   "completed",
+		"submitted": "submitted",
+		"working":   "working",
+		"error":     "failed",
+	} {
+		task := respond(t, ct, request, status, map[string]interface{}{"status_code": "64"})
+		if state := task["status"].(map[string]interface{})["state"]; state != expected {
+			t.Errorf("Expected status %s to give a %s task, got %v", status, expected, state)
+		}
+	}
+}
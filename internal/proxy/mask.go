@@ -0,0 +1,171 @@
+package proxy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+)
+
+// Mask rule actions
+const (
+	MaskRedact  = "redact"
+	MaskPartial = "partial"
+	MaskHash    = "hash"
+	MaskDrop    = "drop"
+)
+
+// DefaultMaskKeep is the number of trailing characters partial masking keeps
+const DefaultMaskKeep = 4
+
+// maskHashKey keys the hashes of masked values, so equal values hash the same
+// while the connector runs but hashes cannot be reversed by guessing values
+var maskHashKey = func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}()
+
+// maskResponse applies the mask rules of a mapping to the legacy response, all
+// of it but the meta the connector added
+func maskResponse(rules []config.MaskRule, legacyResponse map[string]interface{}) {
+	if len(rules) == 0 {
+		return
+	}
+	for key, value := range legacyResponse {
+		if key == "meta" {
+			continue
+		}
+		path := []string{key}
+		if drop, matched := maskFieldRules(rules, path); drop {
+			delete(legacyResponse, key)
+		} else {
+			legacyResponse[key] = maskValue(rules, path, value, matched)
+		}
+	}
+}
+
+// maskValue masks v found at path; matched holds the field rules that matched
+// it or a parent, which apply to everything below it
+func maskValue(rules []config.MaskRule, path []string, v interface{}, matched []config.MaskRule) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			childPath := append(path[:len(path):len(path)], key)
+			drop, childRules := maskFieldRules(rules, childPath)
+			if drop {
+				continue
+			}
+			result[key] = maskValue(rules, childPath, child, append(matched[:len(matched):len(matched)], childRules...))
+		}
+		return result
+	case []interface{}:
+		// Array elements are at the path of the array
+		result := make([]interface{}, len(value))
+		for i, child := range value {
+			result[i] = maskValue(rules, path, child, matched)
+		}
+		return result
+	case string:
+		return maskText(rules, value, matched)
+	case float64:
+		// Numbers, such as card numbers, are masked as their text
+		text := strconv.FormatFloat(value, 'f', -1, 64)
+		if masked := maskText(rules, text, matched); masked != text {
+			return masked
+		}
+	case bool:
+		text := strconv.FormatBool(value)
+		if masked := maskText(rules, text, matched); masked != text {
+			return masked
+		}
+	}
+	return v
+}
+
+// maskFieldRules returns the rules whose field matches the end of path, and
+// whether one of them drops the value
+func maskFieldRules(rules []config.MaskRule, path []string) (bool, []config.MaskRule) {
+	var matched []config.MaskRule
+	for _, rule := range rules {
+		if rule.Field == "" || !matchMaskPath(strings.Split(rule.Field, "."), path) {
+			continue
+		}
+		if rule.Action == MaskDrop && rule.Compiled == nil {
+			return true, nil
+		}
+		matched = append(matched, rule)
+	}
+	return false, matched
+}
+
+// matchMaskPath reports whether path ends with the segments of field, where *
+// matches any key
+func matchMaskPath(field, path []string) bool {
+	if len(field) > len(path) {
+		return false
+	}
+	path = path[len(path)-len(field):]
+	for i, segment := range field {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// maskText masks s, or the matches of the rule pattern within it, for the
+// field rules that matched and for rules without a field
+func maskText(rules []config.MaskRule, s string, matched []config.MaskRule) string {
+	for _, rule := range matched {
+		s = maskMatches(s, rule)
+	}
+	for _, rule := range rules {
+		if rule.Field == "" {
+			s = maskMatches(s, rule)
+		}
+	}
+	return s
+}
+
+func maskMatches(s string, rule config.MaskRule) string {
+	if rule.Compiled == nil {
+		return maskString(s, rule)
+	}
+	return rule.Compiled.ReplaceAllStringFunc(s, func(match string) string {
+		return maskString(match, rule)
+	})
+}
+
+// maskString applies the action of rule to s; dropping a pattern match
+// removes it from the text
+func maskString(s string, rule config.MaskRule) string {
+	switch rule.Action {
+	case MaskPartial:
+		keep := rule.Keep
+		if keep == 0 {
+			keep = DefaultMaskKeep
+		}
+		n := utf8.RuneCountInString(s)
+		if n <= keep {
+			// Keeping all of a short value would not mask it
+			return strings.Repeat("*", n)
+		}
+		runes := []rune(s)
+		return strings.Repeat("*", n-keep) + string(runes[n-keep:])
+	case MaskHash:
+		mac := hmac.New(sha256.New, maskHashKey)
+		mac.Write([]byte(s))
+		return "hash:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	case MaskDrop:
+		return ""
+	}
+	return secrets.Mask
+}
@@ -0,0 +1,75 @@
+package proxy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+)
+
+func TestResponseMask(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `show customer`,
+			Endpoint:      "/customers/42",
+			Method:        "GET",
+			ResponseTransform: config.ResponseTransform{
+				Template: "Customer {{.result.name}}, SSN {{.result.ssn}}",
+				Mask: []config.MaskRule{
+					{Field: "ssn"},
+					{Field: "cards.number", Action: "partial"},
+					{Field: "internalNotes", Action: "drop"},
+					{Field: "email", Action: "hash"},
+					{Pattern: `\b\d{3}-\d{3}-\d{4}\b`, Action: "partial", Keep: 2},
+				},
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	_, task := roundTrip(t, ct, `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"show customer"}]}}}`, map[string]interface{}{
+		"name":          "Ada",
+		"ssn":           "123-45-6789",
+		"email":         "ada@example.com",
+		"internalNotes": "VIP, call back",
+		"note":          "Call 555-123-4567 after 5",
+		"cards": []interface{}{
+			map[string]interface{}{"number": 4111111111111111, "brand": "visa"},
+			map[string]interface{}{"number": "5500 0000 0000 0004", "brand": "mastercard"},
+		},
+	})
+
+	parts := task["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{})
+	if text := parts[0].(map[string]interface{})["text"]; text != "Customer Ada, SSN "+secrets.Mask {
+		t.Errorf("Expected the template to render masked values, got %q", text)
+	}
+	data := parts[1].(map[string]interface{})["data"].(map[string]interface{})
+	if data["ssn"] != secrets.Mask {
+		t.Errorf("Expected ssn to be redacted, got %v", data["ssn"])
+	}
+	if _, ok := data["internalNotes"]; ok {
+		t.Error("Expected internalNotes to be dropped")
+	}
+	if email, _ := data["email"].(string); !strings.HasPrefix(email, "hash:") || strings.Contains(email, "ada") {
+		t.Errorf("Expected email to be hashed, got %v", data["email"])
+	}
+	if data["note"] != "Call **********67 after 5" {
+		t.Errorf("Expected the phone number in note to be masked, got %v", data["note"])
+	}
+	cards := data["cards"].([]interface{})
+	if number := cards[0].(map[string]interface{})["number"]; number != "************1111" {
+		t.Errorf("Expected numeric card number to keep its last digits, got %v", number)
+	}
+	if number := cards[1].(map[string]interface{})["number"]; number != "***************0004" {
+		t.Errorf("Expected card number to keep its last digits, got %v", number)
+	}
+	if brand := cards[1].(map[string]interface{})["brand"]; brand != "mastercard" {
+		t.Errorf("Expected unmasked fields to stay, got %v", brand)
+	}
+}
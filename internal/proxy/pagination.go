@@ -0,0 +1,160 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/i18n"
+)
+
+// ContinuationTokenKey is the task metadata key carrying the token that
+// fetches the next page of a truncated result
+const ContinuationTokenKey = "continuationToken"
+
+// TruncatedKey is the task metadata key marking a truncated result
+const TruncatedKey = "truncated"
+
+// pageMetaKey carries the paging state from request to response meta; it
+// is replaced by the continuation token before the task is returned
+const pageMetaKey = "page"
+
+// pageToken is the content of a continuation token. It is opaque to agents
+// but not secret: it holds the mapping, its original params and the
+// position of the next page.
+type pageToken struct {
+	Mapping string                 `json:"m"`
+	Params  map[string]interface{} `json:"p"`
+	Cursor  string                 `json:"c,omitempty"`
+	Offset  int                    `json:"o,omitempty"`
+}
+
+func encodePageToken(token pageToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(s string) (pageToken, error) {
+	var token pageToken
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return token, err
+	}
+	err = json.Unmarshal(data, &token)
+	return token, err
+}
+
+// resumedPage is a follow-up task for the next page of a mapping
+type resumedPage struct {
+	mapping *config.MappingConfig
+	token   pageToken
+}
+
+// resumePage returns the page a task's continuation token points to, or nil
+// when the task carries no token
+func (t *ConfigTransformer) resumePage(taskMap map[string]interface{}, locale string) (*resumedPage, error) {
+	metadata, _ := taskMap["metadata"].(map[string]interface{})
+	raw, _ := metadata[ContinuationTokenKey].(string)
+	if raw == "" {
+		return nil, nil
+	}
+
+	token, err := decodePageToken(raw)
+	if err == nil {
+		for i := range t.Config.Mappings {
+			mapping := &t.Config.Mappings[i]
+			if mapping.IntentPattern == token.Mapping && mapping.Pagination != nil {
+				return &resumedPage{mapping: mapping, token: token}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s", t.Messages.T(locale, i18n.MsgInvalidContinuation))
+}
+
+// request returns the params of the next page: the original params with the
+// legacy cursor set
+func (p *resumedPage) request() map[string]interface{} {
+	params := make(map[string]interface{}, len(p.token.Params))
+	for key, value := range p.token.Params {
+		params[key] = copyValue(value)
+	}
+	if pagination := p.mapping.Pagination; pagination.Param != "" && p.token.Cursor != "" {
+		setValue(params, pagination.Param, p.token.Cursor)
+	}
+	return params
+}
+
+// meta returns the paging state sent along with a request. For a first
+// page the params are those of the request; for later pages the original
+// params are kept, so the cursor is not repeated in every token.
+func (p *resumedPage) meta(params map[string]interface{}) map[string]interface{} {
+	if p == nil {
+		return map[string]interface{}{"params": params}
+	}
+	return map[string]interface{}{
+		"params": p.token.Params,
+		"cursor": p.token.Cursor,
+		"offset": p.token.Offset,
+	}
+}
+
+// paginate truncates the result of a paged mapping to maxItems and sets a
+// continuation token in the response meta when more items are available.
+// It reports whether the result was truncated.
+func (t *ConfigTransformer) paginate(mappingID string, legacyResponse map[string]interface{}) bool {
+	meta, _ := legacyResponse["meta"].(map[string]interface{})
+	state, _ := meta[pageMetaKey].(map[string]interface{})
+	delete(meta, pageMetaKey)
+	if state == nil {
+		return false
+	}
+	var pagination *config.PaginationConfig
+	for _, mapping := range t.Config.Mappings {
+		if mapping.IntentPattern == mappingID {
+			pagination = mapping.Pagination
+			break
+		}
+	}
+	result, _ := legacyResponse["result"].(map[string]interface{})
+	if pagination == nil || result == nil {
+		return false
+	}
+
+	params, _ := state["params"].(map[string]interface{})
+	cursor, _ := state["cursor"].(string)
+	offset := 0
+	if n, ok := state["offset"].(float64); ok {
+		offset = int(n)
+	}
+	next := pageToken{Mapping: mappingID, Params: params}
+
+	// Items beyond maxItems are served from the same legacy page next time
+	if pagination.ItemsPath != "" {
+		if items, ok := getValueByPath(result, pagination.ItemsPath).([]interface{}); ok {
+			if offset > len(items) {
+				offset = len(items)
+			}
+			items = items[offset:]
+			if pagination.MaxItems > 0 && len(items) > pagination.MaxItems {
+				setValue(result, pagination.ItemsPath, items[:pagination.MaxItems])
+				next.Cursor, next.Offset = cursor, offset+pagination.MaxItems
+				meta[ContinuationTokenKey] = encodePageToken(next)
+				meta[TruncatedKey] = true
+				return true
+			}
+			setValue(result, pagination.ItemsPath, items)
+		}
+	}
+
+	// Otherwise the legacy system's cursor points to the next page
+	if pagination.NextPath != "" {
+		if value := getValueByPath(result, pagination.NextPath); value != nil && value != "" && value != false {
+			next.Cursor = fmt.Sprint(value)
+			meta[ContinuationTokenKey] = encodePageToken(next)
+			meta[TruncatedKey] = true
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package proxy_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func newPagingTransformer(t *testing.T, pagination *config.PaginationConfig) *proxy.ConfigTransformer {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `list.*orders`,
+			Endpoint:      "/orders",
+			Method:        "GET",
+			ParameterMappings: []config.ParameterMapping{
+				{Source: "text", Pattern: `customer (\w+)`, Target: "customer"},
+			},
+			Pagination: pagination,
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	return proxy.NewConfigTransformer(cfg)
+}
+
+// roundTrip sends a task through the transformer and answers the legacy
+// request with result, echoing the request meta like the adapters do
+func roundTrip(t *testing.T, ct *proxy.ConfigTransformer, task string, result map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	data, err := ct.TransformRequestData([]byte(task))
+	if err != nil {
+		t.Fatalf("TransformRequestData: %v", err)
+	}
+	var request map[string]interface{}
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatalf("Failed to unmarshal legacy request: %v", err)
+	}
+
+	response, _ := json.Marshal(map[string]interface{}{
+		"status": "success",
+		"result": result,
+		"meta":   request["meta"],
+	})
+	data, err = ct.TransformResponseData(response)
+	if err != nil {
+		t.Fatalf("TransformResponseData: %v", err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Failed to unmarshal task: %v", err)
+	}
+	return request, out
+}
+
+func followUp(token interface{}) string {
+	return fmt.Sprintf(`{"id":"task-2","metadata":{"continuationToken":%q},"status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"next"}]}}}`, token)
+}
+
+func resultItems(task map[string]interface{}) []interface{} {
+	parts := task["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{})
+	for _, part := range parts {
+		if data, ok := part.(map[string]interface{})["data"].(map[string]interface{}); ok {
+			items, _ := data["orders"].([]interface{})
+			return items
+		}
+	}
+	return nil
+}
+
+func TestPaginationMaxItems(t *testing.T) {
+	ct := newPagingTransformer(t, &config.PaginationConfig{ItemsPath: "orders", MaxItems: 2})
+	orders := map[string]interface{}{"orders": []interface{}{"o1", "o2", "o3", "o4", "o5"}}
+	first := `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"List orders for customer c42"}]}}}`
+
+	_, task := roundTrip(t, ct, first, orders)
+	if items := resultItems(task); fmt.Sprint(items) != "[o1 o2]" {
+		t.Fatalf("Expected the first 2 orders, got %v", items)
+	}
+	metadata := task["metadata"].(map[string]interface{})
+	if metadata[proxy.TruncatedKey] != true || metadata[proxy.ContinuationTokenKey] == nil {
+		t.Fatalf("Expected a continuation token, got %v", metadata)
+	}
+	if _, ok := metadata["page"]; ok {
+		t.Error("Expected the paging state to be removed from the metadata")
+	}
+	text := task["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["text"]
+	if !strings.Contains(fmt.Sprint(text), "More results available") {
+		t.Errorf("Expected a more results note, got %q", text)
+	}
+
+	// The follow-up repeats the original request and skips the served items
+	request, task := roundTrip(t, ct, followUp(metadata[proxy.ContinuationTokenKey]), orders)
+	if params := request["params"].(map[string]interface{}); params["customer"] != "c42" {
+		t.Errorf("Expected the original params, got %v", params)
+	}
+	if items := resultItems(task); fmt.Sprint(items) != "[o3 o4]" {
+		t.Fatalf("Expected the next 2 orders, got %v", items)
+	}
+
+	_, task = roundTrip(t, ct, followUp(task["metadata"].(map[string]interface{})[proxy.ContinuationTokenKey]), orders)
+	if items := resultItems(task); fmt.Sprint(items) != "[o5]" {
+		t.Fatalf("Expected the last order, got %v", items)
+	}
+	if metadata := task["metadata"].(map[string]interface{}); metadata[proxy.ContinuationTokenKey] != nil {
+		t.Errorf("Expected no token on the last page, got %v", metadata)
+	}
+}
+
+func TestPaginationLegacyCursor(t *testing.T) {
+	ct := newPagingTransformer(t, &config.PaginationConfig{NextPath: "next", Param: "query.cursor"})
+	first := `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"List orders for customer c42"}]}}}`
+
+	_, task := roundTrip(t, ct, first, map[string]interface{}{"orders": []interface{}{"o1"}, "next": "abc"})
+	token := task["metadata"].(map[string]interface{})[proxy.ContinuationTokenKey]
+	if token == nil {
+		t.Fatal("Expected a continuation token")
+	}
+
+	request, _ := roundTrip(t, ct, followUp(token), map[string]interface{}{"orders": []interface{}{"o2"}})
+	params := request["params"].(map[string]interface{})
+	query, _ := params["query"].(map[string]interface{})
+	if params["customer"] != "c42" || query["cursor"] != "abc" {
+		t.Errorf("Expected the cursor in the params, got %v", params)
+	}
+}
+
+func TestPaginationInvalidToken(t *testing.T) {
+	ct := newPagingTransformer(t, &config.PaginationConfig{ItemsPath: "orders", MaxItems: 2})
+	if _, err := ct.TransformRequestData([]byte(followUp("not-a-token"))); err == nil || !strings.Contains(err.Error(), "continuation token") {
+		t.Fatalf("Expected an invalid token error, got %v", err)
+	}
+}
@@ -2,10 +2,20 @@ package proxy
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+	"github.com/A2AGateway/a2a-connector/internal/tlsclient"
 )
 
 // Proxy represents an HTTP proxy
@@ -13,6 +23,19 @@ type Proxy struct {
 	targetURL *url.URL
 	proxy     *httputil.ReverseProxy
 	transform *Transformer
+
+	// Breaker fails forwarding fast while the target is down; nil disables it
+	Breaker *resilience.CircuitBreaker
+
+	// Timeout limits each forwarded request; zero means no limit
+	Timeout time.Duration
+
+	// MaxRequestBody rejects larger request bodies with 413 before they reach
+	// the target; zero means no limit
+	MaxRequestBody int64
+
+	// transport carries the TLS settings toward the target; nil uses the default
+	transport http.RoundTripper
 }
 
 // NewProxy creates a new HTTP proxy
@@ -29,43 +52,92 @@ func NewProxy(targetURL string, transform *Transformer) (*Proxy, error) {
 	proxy.Director = func(req *http.Request) {
 		originalDirector(req)
 		
-		// Allow transformer to modify request if needed
+		// Allow transformer to modify request if needed, and route it to the
+		// endpoint of the matched mapping
 		if transform != nil {
-			transform.TransformRequest(req)
+			if body := transform.rewriteRequest(req); body != nil {
+				routeRequest(req, parsedURL, body)
+			}
 		}
 	}
 	
+	p := &Proxy{
+		targetURL: parsedURL,
+		proxy:     proxy,
+		transform: transform,
+	}
+
 	// Add a response modifier
 	proxy.ModifyResponse = func(resp *http.Response) error {
+		p.Breaker.Record(statusError(resp))
 		if transform != nil {
 			return transform.TransformResponse(resp)
 		}
 		return nil
 	}
+
+	// Count transport failures against the breaker; bodies over a limit are
+	// not failures of the target
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			http.Error(w, (&adapter.BodyTooLargeError{Limit: maxBytes.Limit}).Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		p.Breaker.Record(err)
+		http.Error(w, secrets.Redact(err.Error()), http.StatusBadGateway)
+	}
 	
-	return &Proxy{
-		targetURL: parsedURL,
-		proxy:     proxy,
-		transform: transform,
-	}, nil
+	return p, nil
+}
+
+// SetTLSConfig sets the TLS used toward the target, e.g. a private CA or a
+// client certificate; nil keeps the default
+func (p *Proxy) SetTLSConfig(tlsConfig *tls.Config) error {
+	if tlsConfig == nil {
+		return nil
+	}
+	transport, err := tlsclient.Transport(nil, tlsConfig)
+	if err != nil {
+		return err
+	}
+	p.transport = transport
+	p.proxy.Transport = transport
+	return nil
 }
 
 // ServeHTTP implements the http.Handler interface
 func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := p.Breaker.Allow(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if p.MaxRequestBody > 0 && r.Body != nil {
+		if r.ContentLength > p.MaxRequestBody {
+			http.Error(w, (&adapter.BodyTooLargeError{Limit: p.MaxRequestBody}).Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, p.MaxRequestBody)
+	}
+	if p.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), p.Timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
 	p.proxy.ServeHTTP(w, r)
 }
 
 // HandleRequest handles an HTTP request without using the built-in proxy
 func (p *Proxy) HandleRequest(r *http.Request) (*http.Response, error) {
-	// Create a new client
-	client := &http.Client{}
+	// Create a new client; its timeout also covers reading the body
+	client := &http.Client{Timeout: p.Timeout, Transport: p.transport}
 	
 	// Create a new request
 	targetURL := *p.targetURL
 	targetURL.Path = r.URL.Path
 	targetURL.RawQuery = r.URL.RawQuery
 	
-	req, err := http.NewRequest(r.Method, targetURL.String(), nil)
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +151,10 @@ func (p *Proxy) HandleRequest(r *http.Request) (*http.Response, error) {
 	
 	// Copy body if needed
 	if r.Body != nil {
-		body, err := ioutil.ReadAll(r.Body)
+		if p.MaxRequestBody > 0 && r.ContentLength > p.MaxRequestBody {
+			return nil, &adapter.BodyTooLargeError{Limit: p.MaxRequestBody}
+		}
+		body, err := ioutil.ReadAll(newLimitedReader(r.Body, p.MaxRequestBody))
 		if err != nil {
 			return nil, err
 		}
@@ -88,14 +163,21 @@ func (p *Proxy) HandleRequest(r *http.Request) (*http.Response, error) {
 	
 	// Apply transformer if needed
 	if p.transform != nil {
-		p.transform.TransformRequest(req)
+		if body := p.transform.rewriteRequest(req); body != nil {
+			routeRequest(req, p.targetURL, body)
+		}
 	}
 	
-	// Send request
+	// Send request unless the circuit is open
+	if err := p.Breaker.Allow(); err != nil {
+		return nil, err
+	}
 	resp, err := client.Do(req)
 	if err != nil {
+		p.Breaker.Record(err)
 		return nil, err
 	}
+	p.Breaker.Record(statusError(resp))
 	
 	// Apply transformer to response if needed
 	if p.transform != nil {
@@ -108,3 +190,11 @@ func (p *Proxy) HandleRequest(r *http.Request) (*http.Response, error) {
 	
 	return resp, nil
 }
+
+// statusError turns a server error response into an error for the breaker
+func statusError(resp *http.Response) error {
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("target returned HTTP %s", resp.Status)
+	}
+	return nil
+}
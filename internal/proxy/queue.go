@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// queueMetaKey is the legacy request meta key holding the priority and expiry
+// of a task
+const queueMetaKey = "queue"
+
+// queueMeta returns the priority and expiry of a task, or nil when it has
+// neither. Task metadata overrides the mapping: metadata.priority, and
+// metadata.expiresAt (RFC 3339) or metadata.ttl counted from the task's
+// status timestamp, i.e. when it was submitted.
+func queueMeta(mapping *config.MappingConfig, taskMap map[string]interface{}) (map[string]interface{}, error) {
+	metadata, _ := taskMap["metadata"].(map[string]interface{})
+
+	priority := mapping.Priority
+	switch value := metadata["priority"].(type) {
+	case nil:
+	case float64:
+		priority = int(value)
+	case string:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task priority %q", value)
+		}
+		priority = n
+	default:
+		return nil, fmt.Errorf("invalid task priority %v", value)
+	}
+
+	var expiresAt time.Time
+	ttl := mapping.TTL
+	if value, ok := metadata["ttl"].(string); ok {
+		ttl = value
+	}
+	if value, ok := metadata["expiresAt"].(string); ok {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task expiresAt %q", value)
+		}
+		expiresAt = t
+	} else if ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task ttl %q", ttl)
+		}
+		submitted := time.Now()
+		if status, ok := taskMap["status"].(map[string]interface{}); ok {
+			if timestamp, ok := status["timestamp"].(string); ok {
+				if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+					submitted = t
+				}
+			}
+		}
+		expiresAt = submitted.Add(d)
+	}
+
+	if priority == 0 && expiresAt.IsZero() {
+		return nil, nil
+	}
+	meta := map[string]interface{}{"priority": priority}
+	if !expiresAt.IsZero() {
+		meta["expiresAt"] = expiresAt.Format(time.RFC3339Nano)
+	}
+	return meta, nil
+}
@@ -0,0 +1,59 @@
+package proxy_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestQueueMeta(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{
+			{IntentPattern: `check.*price`, Endpoint: "/prices", Method: "GET", Priority: 2, TTL: "10m"},
+			{IntentPattern: `create.*order`, Endpoint: "/orders", Method: "POST"},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	queue := func(task string) map[string]interface{} {
+		data, err := ct.TransformRequestData([]byte(task))
+		if err != nil {
+			t.Fatalf("TransformRequestData: %v", err)
+		}
+		var request map[string]interface{}
+		json.Unmarshal(data, &request)
+		queue, _ := request["meta"].(map[string]interface{})["queue"].(map[string]interface{})
+		return queue
+	}
+
+	// The TTL counts from the task's submission
+	q := queue(`{"id":"t1","status":{"state":"submitted","timestamp":"2024-05-01T10:00:00Z","message":{"role":"user","parts":[{"type":"text","text":"check the price"}]}}}`)
+	if q["priority"] != float64(2) || q["expiresAt"] != "2024-05-01T10:10:00Z" {
+		t.Errorf("Unexpected queue meta %v", q)
+	}
+
+	// Task metadata overrides the mapping
+	q = queue(`{"id":"t2","metadata":{"priority":9,"ttl":"1h"},"status":{"state":"submitted","timestamp":"2024-05-01T10:00:00Z","message":{"role":"user","parts":[{"type":"text","text":"check the price"}]}}}`)
+	if q["priority"] != float64(9) || q["expiresAt"] != "2024-05-01T11:00:00Z" {
+		t.Errorf("Unexpected queue meta %v", q)
+	}
+	q = queue(`{"id":"t3","metadata":{"expiresAt":"2030-01-01T00:00:00Z"},"status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"create an order"}]}}}`)
+	if expiresAt, _ := time.Parse(time.RFC3339, q["expiresAt"].(string)); q["priority"] != float64(0) || expiresAt.Year() != 2030 {
+		t.Errorf("Unexpected queue meta %v", q)
+	}
+
+	// Tasks without priority or expiry carry no queue meta
+	if q := queue(`{"id":"t4","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"create an order"}]}}}`); q != nil {
+		t.Errorf("Expected no queue meta, got %v", q)
+	}
+	if _, err := ct.TransformRequestData([]byte(`{"id":"t5","metadata":{"ttl":"soon"},"status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"create an order"}]}}}`)); err == nil {
+		t.Error("Expected an error for an invalid ttl")
+	}
+}
@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// httpMethods are the mapping methods that are also the method of the
+// forwarded request; other methods, such as the query and execute of database
+// mappings, name an adapter action and keep the method of the client
+var httpMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// routeRequest points a forwarded request at the endpoint and method of the
+// mapping that matched its task and applies its per-call headers. body is the
+// legacy request built by the request transform; requests without a rendered
+// endpoint keep the path and method the client used. The endpoint is resolved
+// against the path of the target, and GET and HEAD requests are sent without
+// a body since their parameters are part of the endpoint.
+func routeRequest(req *http.Request, target *url.URL, body []byte) {
+	var legacy struct {
+		Action string                 `json:"action"`
+		Params map[string]interface{} `json:"params"`
+		Meta   struct {
+			Endpoint string `json:"endpoint"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &legacy); err != nil {
+		return
+	}
+	if headers, ok := legacy.Params["headers"].(map[string]interface{}); ok {
+		setHeaders(req.Header, headers)
+	}
+	if legacy.Meta.Endpoint == "" {
+		return
+	}
+	endpoint, err := url.Parse(legacy.Meta.Endpoint)
+	if err != nil {
+		return
+	}
+
+	// The escaped path keeps the slashes escaped in parameter values
+	req.URL.Path = joinURLPath(target.Path, endpoint.Path)
+	req.URL.RawPath = joinURLPath(target.EscapedPath(), endpoint.EscapedPath())
+	switch {
+	case target.RawQuery == "":
+		req.URL.RawQuery = endpoint.RawQuery
+	case endpoint.RawQuery == "":
+		req.URL.RawQuery = target.RawQuery
+	default:
+		req.URL.RawQuery = target.RawQuery + "&" + endpoint.RawQuery
+	}
+
+	// An explicit method param wins over the mapping method, as it does for
+	// the REST adapter
+	method, _ := legacy.Params["method"].(string)
+	if method == "" {
+		method = legacy.Action
+	}
+	if method = strings.ToUpper(method); httpMethods[method] {
+		req.Method = method
+	}
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		req.Body = http.NoBody
+		req.ContentLength = 0
+		req.Header.Del("Content-Length")
+		req.Header.Del("Content-Type")
+	}
+}
+
+// joinURLPath joins the path of the target and an endpoint with a single slash
+func joinURLPath(base, path string) string {
+	if base == "" {
+		return path
+	}
+	if path == "" {
+		return base
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,119 @@
+package proxy_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestProxyRoutesToMappingEndpoint(t *testing.T) {
+	type call struct {
+		method, uri, body string
+	}
+	calls := make(chan call, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		calls <- call{r.Method, r.URL.RequestURI(), string(body)}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","result":{}}`))
+	}))
+	defer backend.Close()
+
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: backend.URL},
+		Mappings: []config.MappingConfig{
+			{
+				IntentPattern: `get customer`,
+				Endpoint:      "/customers/{id}?expand=orders",
+				Method:        "GET",
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Pattern: `get customer (\w+)`, Target: "id"},
+				},
+			},
+			{
+				IntentPattern: `close ticket`,
+				Endpoint:      "/tickets/{id}/close",
+				Method:        "post",
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Pattern: `close ticket (\w+)`, Target: "id"},
+				},
+			},
+			{
+				IntentPattern: `open file`,
+				Endpoint:      "/files/{name}?version=1",
+				Method:        "GET",
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Pattern: `open file (\S+)`, Target: "name"},
+				},
+			},
+			{
+				IntentPattern: `search`,
+				Endpoint:      "/search?q={q}&limit=5",
+				Method:        "GET",
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Pattern: `search (\S+)`, Target: "q"},
+				},
+			},
+			{
+				IntentPattern: `run report`,
+				Endpoint:      "/reports",
+				Method:        "execute",
+			},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	transformer := proxy.NewConfigTransformer(cfg)
+	p, err := proxy.NewProxy(backend.URL+"/api/v1", &transformer.Transformer)
+	if err != nil {
+		t.Fatalf("Failed to create proxy: %v", err)
+	}
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	send := func(text string) call {
+		task := `{"id":"t1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"` + text + `"}]}}}`
+		resp, err := http.Post(front.URL+"/tasks/send", "application/json", strings.NewReader(task))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return <-calls
+	}
+
+	if got := send("get customer c42"); got.method != http.MethodGet || got.uri != "/api/v1/customers/c42?expand=orders" || got.body != "" {
+		t.Errorf("Expected a GET of the customer without a body, got %+v", got)
+	}
+	if got := send("close ticket t7"); got.method != http.MethodPost || got.uri != "/api/v1/tickets/t7/close" || !strings.Contains(got.body, `"action":"post"`) {
+		t.Errorf("Expected the legacy request to be posted to the ticket, got %+v", got)
+	}
+	// Adapter actions are not HTTP methods and keep the method of the client
+	if got := send("run report"); got.method != http.MethodPost || got.uri != "/api/v1/reports" {
+		t.Errorf("Expected the client method on the report endpoint, got %+v", got)
+	}
+	// Parameter values stay in the path segment or query value they fill
+	for text, uri := range map[string]string{
+		"open file ../admin/users?x=": "/api/v1/files/..%2Fadmin%2Fusers%3Fx=?version=1",
+		"open file 1#":                "/api/v1/files/1%23?version=1",
+		"search a&limit=1000#top":     "/api/v1/search?q=a%26limit%3D1000%23top&limit=5",
+	} {
+		if got := send(text); got.uri != uri {
+			t.Errorf("Expected %q to call %s, got %+v", text, uri, got)
+		}
+	}
+	// A value that makes a dot segment fails the mapping, so the task is not
+	// routed
+	if got := send("open file .."); got.uri != "/api/v1/tasks/send" {
+		t.Errorf("Expected a dot segment not to be routed, got %+v", got)
+	}
+	// Unmatched tasks keep the path the client used
+	if got := send("something else"); got.uri != "/api/v1/tasks/send" {
+		t.Errorf("Expected the client path for an unmatched task, got %+v", got)
+	}
+}
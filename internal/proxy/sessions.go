@@ -0,0 +1,112 @@
+package proxy
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// sessionMetaKey carries the A2A session of a task to its response
+const sessionMetaKey = "sessionId"
+
+// sessionTaskKey is where the variables of its session are set on a task, so
+// parameter mappings, conditions and templates read them as session.<name>
+const sessionTaskKey = "session"
+
+// DefaultSessionTTL is how long session variables are kept after their
+// session last set one
+const DefaultSessionTTL = time.Hour
+
+// SessionStore keeps the variables of A2A sessions, so a task can use what an
+// earlier task of its session resolved
+type SessionStore struct {
+	backend cache.Cache
+	ttl     time.Duration
+
+	// mu serializes merging variables into a session
+	mu sync.Mutex
+}
+
+// NewSessionStore creates a session store on a cache backend; nil keeps the
+// variables in memory. A zero ttl uses DefaultSessionTTL.
+func NewSessionStore(backend cache.Cache, ttl time.Duration) *SessionStore {
+	if backend == nil {
+		backend = cache.NewLRU(0)
+	}
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &SessionStore{backend: backend, ttl: ttl}
+}
+
+// NewSessionStoreFromConfig creates the session store selected in config
+func NewSessionStoreFromConfig(cfg config.SessionConfig) (*SessionStore, error) {
+	backend, err := cache.New(config.CacheConfig{Backend: cfg.Backend, MaxEntries: cfg.MaxEntries})
+	if err != nil {
+		return nil, err
+	}
+	ttl, _ := time.ParseDuration(cfg.TTL)
+	return NewSessionStore(backend, ttl), nil
+}
+
+// Get returns the variables of a session; it is empty for unknown or expired
+// sessions
+func (s *SessionStore) Get(sessionID string) map[string]interface{} {
+	vars := make(map[string]interface{})
+	if data, ok := s.backend.Get(sessionID); ok {
+		if err := json.Unmarshal(data, &vars); err != nil {
+			slog.Warn("failed to read session variables", "session_id", sessionID, logging.KeyError, err)
+		}
+	}
+	return vars
+}
+
+// Set merges vars into the variables of a session and restarts its TTL
+func (s *SessionStore) Set(sessionID string, vars map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged := s.Get(sessionID)
+	for name, value := range vars {
+		merged[name] = value
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		slog.Warn("failed to store session variables", "session_id", sessionID, logging.KeyError, err)
+		return
+	}
+	s.backend.Set(sessionID, data, s.ttl)
+}
+
+// loadSession sets the variables of the session of a task on it and returns
+// the session ID, or "" for tasks without a session
+func (t *ConfigTransformer) loadSession(taskMap map[string]interface{}) string {
+	sessionID, _ := taskMap["sessionId"].(string)
+	if sessionID == "" || t.Sessions == nil {
+		return ""
+	}
+	taskMap[sessionTaskKey] = t.Sessions.Get(sessionID)
+	return sessionID
+}
+
+// saveSession stores the session variables of a mapping from the fields of a
+// legacy response; variables whose path is missing are left as they were
+func (t *ConfigTransformer) saveSession(mapping *config.MappingConfig, sessionID string, legacyResponse map[string]interface{}) {
+	if mapping == nil || len(mapping.Session) == 0 || sessionID == "" || t.Sessions == nil {
+		return
+	}
+	vars := make(map[string]interface{})
+	for name, path := range mapping.Session {
+		if value := getValueByPath(legacyResponse, path); value != nil {
+			vars[name] = value
+		}
+	}
+	if len(vars) > 0 {
+		t.Sessions.Set(sessionID, vars)
+	}
+}
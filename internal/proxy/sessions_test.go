@@ -0,0 +1,81 @@
+package proxy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func sessionTask(id, session, text string) string {
+	return `{"id":"` + id + `","sessionId":"` + session + `","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"` + text + `"}]}}}`
+}
+
+func TestSessionVariables(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{
+			{
+				IntentPattern: `find customer`,
+				Endpoint:      "/customers",
+				Method:        "GET",
+				Session:       map[string]string{"customerId": "result.id"},
+			},
+			{
+				IntentPattern: `list orders`,
+				Endpoint:      "/orders",
+				Method:        "GET",
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "session.customerId", Target: "customer"},
+				},
+			},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	request := transformTask(t, ct, sessionTask("task-1", "s-1", "find customer ACME"))
+	task := respond(t, ct, request, "success", map[string]interface{}{"id": "C-7"})
+	if task["sessionId"] != "s-1" {
+		t.Errorf("Expected the task to keep its session, got %v", task["sessionId"])
+	}
+
+	// A later task of the session reads the variable; other sessions do not see it
+	request = transformTask(t, ct, sessionTask("task-2", "s-1", "list orders"))
+	if params := request["params"].(map[string]interface{}); params["customer"] != "C-7" {
+		t.Errorf("Expected the customer ID of the session, got %v", params)
+	}
+	request = transformTask(t, ct, sessionTask("task-3", "s-2", "list orders"))
+	if params := request["params"].(map[string]interface{}); params["customer"] != nil {
+		t.Errorf("Expected no customer ID in another session, got %v", params)
+	}
+
+	// Failed tasks do not set variables
+	request = transformTask(t, ct, sessionTask("task-4", "s-1", "find customer Initech"))
+	respond(t, ct, request, "error", map[string]interface{}{"id": "C-8"})
+	if vars := ct.Sessions.Get("s-1"); vars["customerId"] != "C-7" {
+		t.Errorf("Expected a failed task to leave the session, got %v", vars)
+	}
+}
+
+func TestSessionStoreExpiry(t *testing.T) {
+	now := time.Now()
+	backend := cache.NewLRU(0)
+	backend.Now = func() time.Time { return now }
+	store := proxy.NewSessionStore(backend, time.Minute)
+
+	store.Set("s-1", map[string]interface{}{"a": "1"})
+	store.Set("s-1", map[string]interface{}{"b": "2"})
+	if vars := store.Get("s-1"); vars["a"] != "1" || vars["b"] != "2" {
+		t.Errorf("Expected merged variables, got %v", vars)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if vars := store.Get("s-1"); len(vars) != 0 {
+		t.Errorf("Expected the session to expire, got %v", vars)
+	}
+}
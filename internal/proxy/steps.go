@@ -0,0 +1,228 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/expr"
+	"github.com/A2AGateway/a2a-connector/internal/tmplfunc"
+)
+
+// stepsMetaKey is the legacy request meta key holding the steps of a
+// multi-step mapping; after they run it holds what each step did
+const stepsMetaKey = "steps"
+
+// Step outcomes
+const (
+	StepSucceeded = "success"
+	StepSkipped   = "skipped"
+	StepFailed    = "error"
+)
+
+// StepCall is the legacy call of one step; a zero Timeout leaves the call to
+// the adapter timeouts
+type StepCall struct {
+	Name     string
+	Action   string
+	Endpoint string
+	Params   map[string]interface{}
+	Timeout  time.Duration
+}
+
+// StepExecutor makes the legacy call of a step
+type StepExecutor func(ctx context.Context, call StepCall) (map[string]interface{}, error)
+
+// StepError is returned when a step fails and aborts its mapping
+type StepError struct {
+	Step      string
+	Completed []string
+	Err       error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %s failed: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Details implements adapter.DetailedError, adding the step to the details
+// of the underlying error
+func (e *StepError) Details() map[string]interface{} {
+	details := map[string]interface{}{}
+	var detailed interface{ Details() map[string]interface{} }
+	if errors.As(e.Err, &detailed) {
+		for key, value := range detailed.Details() {
+			details[key] = value
+		}
+	}
+	details["step"] = e.Step
+	details["completedSteps"] = e.Completed
+	return details
+}
+
+// stepsMeta returns the steps or fan-out branches of a mapping for the legacy
+// request meta; steps without a method use the method of the mapping
+func stepsMeta(mapping *config.MappingConfig, mappingSteps []config.StepConfig) []config.StepConfig {
+	steps := make([]config.StepConfig, len(mappingSteps))
+	for i, step := range mappingSteps {
+		if step.Method == "" {
+			step.Method = mapping.Method
+		}
+		steps[i] = step
+	}
+	return steps
+}
+
+// HasSteps reports whether a legacy request meta holds the steps of a
+// multi-step mapping
+func HasSteps(meta map[string]interface{}) bool {
+	return meta[stepsMetaKey] != nil
+}
+
+// RunSteps runs the steps in the legacy request meta in order and returns
+// their results by step name. Steps whose when condition does not hold are
+// skipped; the first failing step without continueOnError aborts the run
+// with a StepError and the results so far. Meta is updated with the outcome
+// of each step.
+func RunSteps(ctx context.Context, meta map[string]interface{}, params map[string]interface{}, exec StepExecutor) (map[string]interface{}, error) {
+	var steps []config.StepConfig
+	data, _ := json.Marshal(meta[stepsMetaKey])
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("invalid steps: %w", err)
+	}
+	results := make(map[string]interface{})
+	scope := map[string]interface{}{"params": params, "steps": results}
+	outcomes := make([]interface{}, 0, len(steps))
+	defer func() { meta[stepsMetaKey] = outcomes }()
+
+	completed := []string{}
+	for _, step := range steps {
+		outcome := map[string]interface{}{"name": step.Name}
+		outcomes = append(outcomes, outcome)
+
+		if step.When != "" {
+			when, err := expr.Parse(step.When)
+			if err != nil {
+				return results, &StepError{Step: step.Name, Completed: completed, Err: err}
+			}
+			if !when.Match(func(path string) interface{} { return getValueByPath(scope, path) }) {
+				outcome["status"] = StepSkipped
+				continue
+			}
+		}
+
+		call, err := renderStep(step, scope)
+		if err != nil {
+			return results, &StepError{Step: step.Name, Completed: completed, Err: err}
+		}
+		outcome["endpoint"] = call.Endpoint
+
+		result, err := call.run(ctx, exec)
+		if err != nil {
+			outcome["status"] = StepFailed
+			if !step.ContinueOnError {
+				return results, &StepError{Step: step.Name, Completed: completed, Err: err}
+			}
+			results[step.Name] = map[string]interface{}{"error": err.Error()}
+			continue
+		}
+		outcome["status"] = StepSucceeded
+		results[step.Name] = result
+		completed = append(completed, step.Name)
+	}
+	return results, nil
+}
+
+// run makes the call within its timeout
+func (c StepCall) run(ctx context.Context, exec StepExecutor) (map[string]interface{}, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	return exec(ctx, c)
+}
+
+// renderStep renders the endpoint and params of a step; {param} placeholders
+// in the endpoint are filled like those of a mapping endpoint
+func renderStep(step config.StepConfig, scope map[string]interface{}) (StepCall, error) {
+	call := StepCall{Name: step.Name, Action: step.Method}
+	if step.Timeout != "" {
+		d, err := time.ParseDuration(step.Timeout)
+		if err != nil {
+			return call, fmt.Errorf("invalid timeout %q", step.Timeout)
+		}
+		call.Timeout = d
+	}
+
+	params, _ := scope["params"].(map[string]interface{})
+	if len(step.Params) > 0 {
+		rendered, err := renderValue(step.Params, scope)
+		if err != nil {
+			return call, err
+		}
+		params = rendered.(map[string]interface{})
+	} else {
+		params, _ = copyValue(params).(map[string]interface{})
+	}
+	call.Params = params
+
+	endpoint, err := renderTemplate(step.Endpoint, scope)
+	if err != nil {
+		return call, err
+	}
+	call.Endpoint, err = renderEndpoint(endpoint, params)
+	return call, err
+}
+
+// renderValue renders the strings of a params value as templates
+func renderValue(value interface{}, scope map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return renderTemplate(v, scope)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			rendered, err := renderValue(child, scope)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			result[key] = rendered
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, child := range v {
+			rendered, err := renderValue(child, scope)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = rendered
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// renderTemplate renders text as a template over scope; missing fields
+// render as empty strings
+func renderTemplate(text string, scope map[string]interface{}) (string, error) {
+	tmpl, err := template.New("step").Funcs(tmplfunc.FuncMap()).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, scope); err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(buf.String(), "<no value>", ""), nil
+}
@@ -0,0 +1,105 @@
+package proxy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestRunSteps(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `upgrade customer`,
+			Method:        "GET",
+			ParameterMappings: []config.ParameterMapping{
+				{Source: "text", Pattern: `customer (\S+)`, Target: "email"},
+			},
+			Steps: []config.StepConfig{
+				{Name: "lookup", Endpoint: "/customers?email={email}"},
+				{Name: "details", Endpoint: "/customers/{{.steps.lookup.id}}"},
+				{Name: "notify", Endpoint: "/notifications", Method: "POST", ContinueOnError: true,
+					Params: map[string]interface{}{"to": "{{.params.email}}", "tier": "{{.steps.details.tier}}"}},
+				{Name: "upgrade", Endpoint: "/customers/{{.steps.lookup.id}}/tier", Method: "PUT", When: "steps.details.tier != 'gold'",
+					Params: map[string]interface{}{"tier": "gold", "attempts": 1.0}},
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected a valid config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	run := func(tier string, failing string) (map[string]interface{}, map[string]interface{}, []proxy.StepCall, error) {
+		data, err := ct.TransformRequestData([]byte(`{"id":"t1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"upgrade customer ada@example.com"}]}}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var request map[string]interface{}
+		json.Unmarshal(data, &request)
+		meta := request["meta"].(map[string]interface{})
+		if !proxy.HasSteps(meta) {
+			t.Fatal("Expected the steps in the request meta")
+		}
+
+		var calls []proxy.StepCall
+		results, err := proxy.RunSteps(context.Background(), meta, request["params"].(map[string]interface{}), func(ctx context.Context, call proxy.StepCall) (map[string]interface{}, error) {
+			calls = append(calls, call)
+			switch {
+			case call.Name == failing:
+				return nil, errors.New("connection refused")
+			case call.Name == "lookup":
+				return map[string]interface{}{"id": 42.0}, nil
+			case call.Name == "details":
+				return map[string]interface{}{"tier": tier}, nil
+			}
+			return map[string]interface{}{"ok": true}, nil
+		})
+		return results, meta, calls, err
+	}
+
+	results, meta, calls, err := run("silver", "")
+	if err != nil {
+		t.Fatalf("RunSteps: %v", err)
+	}
+	if len(calls) != 4 || calls[0].Endpoint != "/customers?email=ada%40example.com" || calls[1].Endpoint != "/customers/42" || calls[1].Action != "GET" {
+		t.Fatalf("Unexpected calls %+v", calls)
+	}
+	if notify := calls[2].Params; notify["to"] != "ada@example.com" || notify["tier"] != "silver" || calls[2].Action != "POST" {
+		t.Errorf("Unexpected notify params %v", notify)
+	}
+	if upgrade := calls[3]; upgrade.Endpoint != "/customers/42/tier" || upgrade.Params["attempts"] != 1.0 {
+		t.Errorf("Unexpected upgrade call %+v", upgrade)
+	}
+	if details, _ := results["details"].(map[string]interface{}); details["tier"] != "silver" || results["upgrade"] == nil {
+		t.Errorf("Unexpected combined result %v", results)
+	}
+
+	// Conditions skip steps, and meta records what each step did
+	_, meta, calls, _ = run("gold", "")
+	outcomes := meta["steps"].([]interface{})
+	if len(calls) != 3 || outcomes[3].(map[string]interface{})["status"] != proxy.StepSkipped {
+		t.Errorf("Expected the upgrade to be skipped, got %v", outcomes)
+	}
+
+	// Failing steps abort the mapping unless they continue on error
+	results, _, calls, err = run("silver", "notify")
+	if err != nil || len(calls) != 4 || results["notify"].(map[string]interface{})["error"] != "connection refused" {
+		t.Errorf("Expected notify to fail without aborting, got %v %v", results, err)
+	}
+	results, _, calls, err = run("silver", "details")
+	var stepErr *proxy.StepError
+	if !errors.As(err, &stepErr) || len(calls) != 2 || results["lookup"] == nil {
+		t.Fatalf("Expected the details step to abort, got %v after %d calls", err, len(calls))
+	}
+	if details := stepErr.Details(); details["step"] != "details" || len(details["completedSteps"].([]string)) != 1 {
+		t.Errorf("Unexpected details %v", details)
+	}
+}
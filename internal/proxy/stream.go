@@ -0,0 +1,124 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Streaming content types that are forwarded incrementally instead of buffered
+const (
+	ContentTypeEventStream = "text/event-stream"
+	ContentTypeNDJSON      = "application/x-ndjson"
+)
+
+// streamingContentTypes are media types whose bodies arrive as a sequence of records
+var streamingContentTypes = map[string]bool{
+	ContentTypeEventStream:    true,
+	ContentTypeNDJSON:         true,
+	"application/ndjson":      true,
+	"application/jsonl":       true,
+	"application/json-seq":    true,
+	"application/stream+json": true,
+}
+
+// IsStreaming reports whether a response carries a stream of events or records
+func IsStreaming(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return streamingContentTypes[mediaType]
+}
+
+// streamTransformer applies a transform to each record of a streaming body as it
+// arrives: each data field of a server-sent event, or each line of NDJSON
+type streamTransformer struct {
+	src       io.ReadCloser
+	reader    *bufio.Reader
+	transform TransformFunc
+	sse       bool
+	pending   bytes.Buffer
+	err       error
+}
+
+func newStreamTransformer(body io.ReadCloser, transform TransformFunc, sse bool) *streamTransformer {
+	return &streamTransformer{
+		src:       body,
+		reader:    bufio.NewReader(body),
+		transform: transform,
+		sse:       sse,
+	}
+}
+
+// Read returns transformed records, reading one line from the source at a time
+func (s *streamTransformer) Read(p []byte) (int, error) {
+	for s.pending.Len() == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		line, err := s.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			transformed, terr := s.transformLine(line)
+			if terr != nil {
+				return 0, terr
+			}
+			s.pending.Write(transformed)
+		}
+		s.err = err
+	}
+	return s.pending.Read(p)
+}
+
+// transformLine transforms one record line, keeping its line ending
+func (s *streamTransformer) transformLine(line []byte) ([]byte, error) {
+	content := bytes.TrimRight(line, "\r\n")
+	ending := line[len(content):]
+
+	prefix := []byte(nil)
+	if s.sse {
+		// Only event data is transformed; ids, event names and comments pass through
+		if !bytes.HasPrefix(content, []byte("data:")) {
+			return line, nil
+		}
+		prefix = []byte("data:")
+		content = content[len("data:"):]
+		if bytes.HasPrefix(content, []byte(" ")) {
+			prefix = []byte("data: ")
+			content = content[1:]
+		}
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return line, nil
+	}
+
+	transformed, err := s.transform(content)
+	if err != nil {
+		return nil, err
+	}
+	// Keep records on one line so the framing survives the transform
+	transformed = bytes.ReplaceAll(transformed, []byte("\n"), []byte(" "))
+
+	out := make([]byte, 0, len(prefix)+len(transformed)+len(ending))
+	out = append(out, prefix...)
+	out = append(out, transformed...)
+	return append(out, ending...), nil
+}
+
+// Close closes the source body
+func (s *streamTransformer) Close() error {
+	return s.src.Close()
+}
+
+// transformStream switches a streaming response to incremental transformation
+func (t *Transformer) transformStream(resp *http.Response) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	resp.Body = newStreamTransformer(resp.Body, t.responseTransform, strings.EqualFold(mediaType, ContentTypeEventStream))
+
+	// The transformed length is unknown; let the server send it chunked
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+}
@@ -0,0 +1,88 @@
+package proxy_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestProxyStreamsNDJSON(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"n":"one"}`)
+		w.(http.Flusher).Flush()
+		// The second record is held back until the client has seen the first
+		<-release
+		fmt.Fprintln(w, `{"n":"two"}`)
+	}))
+	defer backend.Close()
+	defer close(release)
+
+	transformer := proxy.NewTransformer()
+	transformer.SetResponseTransform(func(data []byte) ([]byte, error) {
+		return bytes.ToUpper(data), nil
+	})
+	p, err := proxy.NewProxy(backend.URL, transformer)
+	if err != nil {
+		t.Fatalf("Failed to create proxy: %v", err)
+	}
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	resp, err := http.Get(front.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	select {
+	case line := <-lines:
+		if line != `{"N":"ONE"}` {
+			t.Errorf("Unexpected first record %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("First record was not forwarded before the stream completed")
+	}
+	release <- struct{}{}
+	if line := <-lines; line != `{"N":"TWO"}` {
+		t.Errorf("Unexpected second record %q", line)
+	}
+}
+
+func TestProxyTransformsServerSentEvents(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: update\ndata: {\"a\":1}\n\n: keep-alive\n\n")
+	}))
+	defer backend.Close()
+
+	transformer := proxy.NewTransformer()
+	transformer.SetResponseTransform(func(data []byte) ([]byte, error) {
+		return []byte(`{"wrapped":` + string(data) + `}`), nil
+	})
+	p, _ := proxy.NewProxy(backend.URL, transformer)
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	expected := "event: update\ndata: {\"wrapped\":{\"a\":1}}\n\n: keep-alive\n\n"
+	if rec.Body.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, rec.Body.String())
+	}
+}
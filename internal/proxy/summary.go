@@ -0,0 +1,52 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+	"github.com/A2AGateway/a2a-connector/internal/summary"
+)
+
+// summaryPart builds the text part summarizing the rows of a legacy
+// response; it returns nil when there are no rows or the summary fails
+func summaryPart(cfg *config.SummaryConfig, legacyResponse map[string]interface{}) map[string]interface{} {
+	rows, ok := summaryRows(cfg.Source, legacyResponse)
+	if !ok {
+		return nil
+	}
+	// The summary config is validated on load
+	summarizer, err := summary.New(cfg)
+	if err != nil {
+		slog.Warn("invalid summary config", logging.KeyError, err)
+		return nil
+	}
+	text, err := summarizer.Summarize(context.Background(), rows)
+	if err != nil {
+		slog.Warn("failed to summarize response", logging.KeyError, err)
+		return nil
+	}
+	return map[string]interface{}{"type": "text", "text": text}
+}
+
+// summaryRows returns the rows at source, or the first list of objects in
+// the result, by field name, when source is empty
+func summaryRows(source string, legacyResponse map[string]interface{}) ([]map[string]interface{}, bool) {
+	if source != "" {
+		return summary.Rows(getValueByPath(legacyResponse, source))
+	}
+	result, _ := legacyResponse["result"].(map[string]interface{})
+	fields := make([]string, 0, len(result))
+	for field := range result {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if rows, ok := summary.Rows(result[field]); ok && len(rows) > 0 {
+			return rows, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,50 @@
+package proxy_test
+
+import (
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestResponseSummary(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "db", Driver: "postgres"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `list orders`,
+			Method:        "query",
+			ResponseTransform: config.ResponseTransform{
+				Summary: &config.SummaryConfig{KeyColumns: []string{"status"}, Aggregates: []string{"total"}},
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	_, task := roundTrip(t, ct, `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"list orders"}]}}}`, map[string]interface{}{
+		"results": []interface{}{
+			map[string]interface{}{"id": 1, "status": "open", "total": 40},
+			map[string]interface{}{"id": 2, "status": "open", "total": 60},
+		},
+	})
+
+	parts := task["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{})
+	var text string
+	var data map[string]interface{}
+	for _, p := range parts {
+		part := p.(map[string]interface{})
+		if part["type"] == "text" {
+			text = part["text"].(string)
+		} else if part["type"] == "data" {
+			data = part["data"].(map[string]interface{})
+		}
+	}
+	if text != "2 rows. status: open (2). total: total 100, min 40, max 60, average 50." {
+		t.Errorf("Unexpected summary %q", text)
+	}
+	if data == nil || len(data["results"].([]interface{})) != 2 {
+		t.Errorf("Expected the rows next to the summary, got %v", parts)
+	}
+}
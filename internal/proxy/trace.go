@@ -0,0 +1,163 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Trace stage names, in the order they run
+const (
+	StageIntentMatch       = "intent match"
+	StageParams            = "extracted params"
+	StageEndpoint          = "rendered endpoint"
+	StageRequestBody       = "request body"
+	StageSimulatedResponse = "simulated response"
+	StageLegacyResponse    = "legacy response"
+	StageFinalTask         = "final task"
+)
+
+// TraceStage is the output of one transformation stage
+type TraceStage struct {
+	Name string      `json:"name"`
+	Data interface{} `json:"data"`
+}
+
+// MappingScore is a row of the intent match table
+type MappingScore struct {
+	IntentPattern string   `json:"intentPattern"`
+	Matched       bool     `json:"matched"`
+	Selected      bool     `json:"selected"`
+	Score         float64  `json:"score"`
+	MatchedWords  []string `json:"matchedWords,omitempty"`
+}
+
+// TraceOptions controls how the legacy call of a traced task is made
+type TraceOptions struct {
+	// Execute calls the legacy system; when nil the call is simulated
+	Execute func(action string, params map[string]interface{}) (interface{}, error)
+	// Result is the simulated legacy result, an empty object by default
+	Result interface{}
+}
+
+// Trace runs a task through each transformation stage and records their
+// output. It stops at the first failing stage and returns the stages so far
+// with the error.
+func (t *ConfigTransformer) Trace(task []byte, opts TraceOptions) ([]TraceStage, error) {
+	var stages []TraceStage
+
+	var taskMap map[string]interface{}
+	if err := json.Unmarshal(task, &taskMap); err != nil {
+		return stages, fmt.Errorf("invalid task: %w", err)
+	}
+	text, err := extractTextFromTask(taskMap)
+	if err != nil {
+		return stages, err
+	}
+
+	// Every mapping is scored so near misses are visible next to the winner
+	selected, slots, _ := t.findMatchingMapping(taskMap, text)
+	scores := make([]MappingScore, 0, len(t.Config.Mappings))
+	for i, mapping := range t.Config.Mappings {
+		miss := scoreMapping(mapping, text)
+		scores = append(scores, MappingScore{
+			IntentPattern: mapping.IntentPattern,
+			Matched:       t.mappingMatches(&t.Config.Mappings[i], taskMap, text),
+			Selected:      selected == &t.Config.Mappings[i],
+			Score:         miss.Score,
+			MatchedWords:  miss.MatchedWords,
+		})
+	}
+	stages = append(stages, TraceStage{Name: StageIntentMatch, Data: scores})
+	if selected == nil {
+		return stages, fmt.Errorf("no mapping matches %q", text)
+	}
+
+	params, err := t.extractParameters(selected, taskMap, text, slots)
+	if err != nil {
+		return stages, err
+	}
+	stages = append(stages, TraceStage{Name: StageParams, Data: params})
+	endpoint, err := renderEndpoint(selected.Endpoint, params)
+	if err != nil {
+		return stages, err
+	}
+	stages = append(stages, TraceStage{Name: StageEndpoint, Data: selected.Method + " " + endpoint})
+
+	// The request body is what the adapter receives, after global transform rules
+	legacyData, err := t.transformRequest(task)
+	if err != nil {
+		return stages, err
+	}
+	var legacyReq map[string]interface{}
+	if err := json.Unmarshal(legacyData, &legacyReq); err != nil {
+		return stages, err
+	}
+	stages = append(stages, TraceStage{Name: StageRequestBody, Data: legacyReq})
+
+	metadata, _ := taskMap["metadata"].(map[string]interface{})
+	legacyResp := map[string]interface{}{"meta": legacyReq["meta"], "status": "success", "params": legacyReq["params"], "metadata": metadata}
+	if opts.Execute == nil {
+		result := opts.Result
+		if result == nil {
+			result = map[string]interface{}{}
+		}
+		legacyResp["result"] = result
+		stages = append(stages, TraceStage{Name: StageSimulatedResponse, Data: legacyResp})
+	} else {
+		action, _ := legacyReq["action"].(string)
+		reqParams, _ := legacyReq["params"].(map[string]interface{})
+		result, err := opts.Execute(action, reqParams)
+		legacyResp["result"] = result
+		if err != nil {
+			legacyResp["status"] = "error"
+			legacyResp["error"] = err.Error()
+		}
+		stages = append(stages, TraceStage{Name: StageLegacyResponse, Data: legacyResp})
+	}
+
+	respData, err := json.Marshal(legacyResp)
+	if err != nil {
+		return stages, err
+	}
+	taskData, err := t.transformResponse(respData)
+	if err != nil {
+		return stages, err
+	}
+	var finalTask map[string]interface{}
+	if err := json.Unmarshal(taskData, &finalTask); err != nil {
+		return stages, err
+	}
+	stages = append(stages, TraceStage{Name: StageFinalTask, Data: finalTask})
+	return stages, nil
+}
+
+// RenderResponse runs a legacy result through the response transform of the
+// mapping with the given intent pattern, as if the legacy call had returned it
+func (t *ConfigTransformer) RenderResponse(intentPattern string, result interface{}) (map[string]interface{}, error) {
+	found := false
+	for _, mapping := range t.Config.Mappings {
+		found = found || mapping.IntentPattern == intentPattern
+	}
+	if !found {
+		return nil, fmt.Errorf("no mapping has intent pattern %q", intentPattern)
+	}
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+
+	respData, err := json.Marshal(map[string]interface{}{
+		"status": "success",
+		"result": result,
+		"meta":   map[string]interface{}{"mappingId": intentPattern},
+	})
+	if err != nil {
+		return nil, err
+	}
+	taskData, err := t.transformResponse(respData)
+	if err != nil {
+		return nil, err
+	}
+	var task map[string]interface{}
+	err = json.Unmarshal(taskData, &task)
+	return task, err
+}
@@ -0,0 +1,173 @@
+package proxy_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestTrace(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{
+			{IntentPattern: `create.*order`, Endpoint: "/orders", Method: "POST"},
+			{
+				IntentPattern: `get.*customer`,
+				Endpoint:      "/customers/{id}",
+				Method:        "GET",
+				ParameterMappings: []config.ParameterMapping{
+					{Source: "text", Target: "id", Pattern: `ID: (\d+)`, Type: "string"},
+				},
+			},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	task := []byte(`{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"Get customer data for ID: 12345"}]}}}`)
+	stages, err := ct.Trace(task, proxy.TraceOptions{Result: map[string]interface{}{"name": "Acme"}})
+	if err != nil {
+		t.Fatalf("Trace failed: %v", err)
+	}
+
+	names := []string{proxy.StageIntentMatch, proxy.StageParams, proxy.StageEndpoint, proxy.StageRequestBody, proxy.StageSimulatedResponse, proxy.StageFinalTask}
+	if len(stages) != len(names) {
+		t.Fatalf("Expected %d stages, got %+v", len(names), stages)
+	}
+	for i, name := range names {
+		if stages[i].Name != name {
+			t.Errorf("Expected stage %d to be %s, got %s", i+1, name, stages[i].Name)
+		}
+	}
+
+	scores := stages[0].Data.([]proxy.MappingScore)
+	if scores[0].Matched || !scores[1].Matched || !scores[1].Selected || scores[1].Score != 1 {
+		t.Errorf("Unexpected score table: %+v", scores)
+	}
+	if stages[2].Data != "GET /customers/12345" {
+		t.Errorf("Unexpected endpoint %v", stages[2].Data)
+	}
+	final := stages[5].Data.(map[string]interface{})
+	if state := final["status"].(map[string]interface{})["state"]; state != "completed" {
+		t.Errorf("Expected completed task, got %v", state)
+	}
+
+	// Tracing stops at the first failing stage
+	task = []byte(`{"id":"task-2","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"Cancel the customer order"}]}}}`)
+	stages, err = ct.Trace(task, proxy.TraceOptions{})
+	if err == nil || len(stages) != 1 {
+		t.Errorf("Expected the trace to stop after the intent match, got %d stages, %v", len(stages), err)
+	}
+}
+
+func TestRenderResponse(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern:     `get.*customer`,
+			Endpoint:          "/customers/{id}",
+			Method:            "GET",
+			ResponseTransform: config.ResponseTransform{Template: "Customer {{.result.name}}"},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	task, err := ct.RenderResponse(`get.*customer`, map[string]interface{}{"name": "Acme"})
+	if err != nil {
+		t.Fatalf("RenderResponse failed: %v", err)
+	}
+	parts := task["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{})
+	if text := parts[0].(map[string]interface{})["text"]; text != "Customer Acme" {
+		t.Errorf("Expected the rendered template, got %v", text)
+	}
+
+	if _, err := ct.RenderResponse(`create.*order`, nil); err == nil {
+		t.Error("Expected an error for an unknown mapping")
+	}
+}
+
+func TestPathMappings(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `ship.*order`,
+			Endpoint:      "/orders/{orderId}/ship",
+			Method:        "POST",
+			ParameterMappings: []config.ParameterMapping{
+				{Source: "metadata.orders[0].id", Target: "orderId"},
+				{Source: "metadata.orders[?(@.priority == true)].id.first()", Target: "express"},
+			},
+			ResponseTransform: config.ResponseTransform{Mappings: map[string]string{
+				"tracking":        "result.parcels[-1].tracking",
+				"summary.weights": "result.parcels[*].weight",
+			}},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	task := []byte(`{"id":"task-1","metadata":{"orders":[{"id":"O-1"},{"id":"O-2","priority":true}]},"status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"Ship the order"}]}}}`)
+	stages, err := ct.Trace(task, proxy.TraceOptions{})
+	if err != nil {
+		t.Fatalf("Trace failed: %v", err)
+	}
+	params := stages[1].Data.(map[string]interface{})
+	if fmt.Sprint(params) != "map[express:O-2 orderId:O-1]" {
+		t.Errorf("Unexpected params %v", params)
+	}
+
+	result := map[string]interface{}{"parcels": []interface{}{
+		map[string]interface{}{"tracking": "T1", "weight": 2.5},
+		map[string]interface{}{"tracking": "T2", "weight": 1.0},
+	}}
+	rendered, err := ct.RenderResponse(`ship.*order`, result)
+	if err != nil {
+		t.Fatalf("RenderResponse failed: %v", err)
+	}
+	parts := rendered["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{})
+	data := parts[len(parts)-1].(map[string]interface{})["data"]
+	if fmt.Sprint(data) != "map[summary:map[weights:[2.5 1]] tracking:T2]" {
+		t.Errorf("Expected the mapped data, got %v", data)
+	}
+}
+
+func TestTemplateData(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: `get.*invoice`,
+			Endpoint:      "/invoices/{id}",
+			Method:        "GET",
+			ParameterMappings: []config.ParameterMapping{
+				{Source: "text", Target: "id", Pattern: `invoice (\d+)`},
+			},
+			ResponseTransform: config.ResponseTransform{
+				Template: `Invoice {{.params.id}} for {{.metadata.tenant | upper}}: {{.result.total | number 2}} due {{.result.due | date "2 Jan 2006"}}{{if empty .result.paid}} (unpaid){{end}}`,
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	ct := proxy.NewConfigTransformer(cfg)
+
+	task := []byte(`{"id":"task-1","metadata":{"tenant":"acme"},"status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"Get invoice 42"}]}}}`)
+	stages, err := ct.Trace(task, proxy.TraceOptions{Result: map[string]interface{}{"total": 12500.5, "due": "2024-07-01"}})
+	if err != nil {
+		t.Fatalf("Trace failed: %v", err)
+	}
+	final := stages[len(stages)-1].Data.(map[string]interface{})
+	parts := final["status"].(map[string]interface{})["message"].(map[string]interface{})["parts"].([]interface{})
+	if text := parts[0].(map[string]interface{})["text"]; text != "Invoice 42 for ACME: 12,500.50 due 1 Jul 2024 (unpaid)" {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
@@ -3,7 +3,11 @@ package proxy
 import (
 	"bytes"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"strconv"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
 )
 
 // TransformFunc is a function that transforms data
@@ -13,8 +17,13 @@ type TransformFunc func([]byte) ([]byte, error)
 type Transformer struct {
 	requestHeaders  map[string]string
 	responseHeaders map[string]string
+	removedRequestHeaders  []string
+	removedResponseHeaders []string
 	requestTransform  TransformFunc
 	responseTransform TransformFunc
+	requestStream     StreamFunc
+	responseStream    StreamFunc
+	maxBodySize       int64
 }
 
 // NewTransformer creates a new transformer
@@ -35,6 +44,17 @@ func (t *Transformer) SetResponseHeader(key, value string) {
 	t.responseHeaders[key] = value
 }
 
+// RemoveRequestHeader removes a header from requests, e.g. a client
+// credential that must not reach the target
+func (t *Transformer) RemoveRequestHeader(key string) {
+	t.removedRequestHeaders = append(t.removedRequestHeaders, key)
+}
+
+// RemoveResponseHeader removes a header from responses
+func (t *Transformer) RemoveResponseHeader(key string) {
+	t.removedResponseHeaders = append(t.removedResponseHeaders, key)
+}
+
 // SetRequestTransform sets the request body transform function
 func (t *Transformer) SetRequestTransform(f TransformFunc) {
 	t.requestTransform = f
@@ -45,27 +65,77 @@ func (t *Transformer) SetResponseTransform(f TransformFunc) {
 	t.responseTransform = f
 }
 
+// SetRequestStreamTransform sets a transform applied to request bodies as
+// they are forwarded instead of buffering them; it replaces the request
+// transform function
+func (t *Transformer) SetRequestStreamTransform(f StreamFunc) {
+	t.requestStream = f
+}
+
+// SetResponseStreamTransform sets a transform applied to response bodies as
+// they are forwarded instead of buffering them; it replaces the response
+// transform function
+func (t *Transformer) SetResponseStreamTransform(f StreamFunc) {
+	t.responseStream = f
+}
+
+// SetMaxBodySize limits the bodies the transforms read; zero means no limit.
+// Larger request bodies are forwarded untransformed, larger responses fail
+// with an adapter.BodyTooLargeError, and stream transforms fail once they
+// read past the limit. Binary bodies are never transformed, so downloads of
+// any size pass through without being buffered.
+func (t *Transformer) SetMaxBodySize(n int64) {
+	t.maxBodySize = n
+}
+
 // TransformRequest transforms an HTTP request
 func (t *Transformer) TransformRequest(req *http.Request) {
-	// Add/modify headers
+	t.rewriteRequest(req)
+}
+
+// rewriteRequest transforms an HTTP request and returns the transformed
+// body, or nil when the body was left as is
+func (t *Transformer) rewriteRequest(req *http.Request) []byte {
+	// Add/modify/remove headers
 	for k, v := range t.requestHeaders {
 		req.Header.Set(k, v)
 	}
+	for _, k := range t.removedRequestHeaders {
+		req.Header.Del(k)
+	}
 	
+	// Uploads of binary content pass through; stream transforms apply as the
+	// body is forwarded
+	if req.Body == nil || IsBinary(req.Header) {
+		return nil
+	}
+	if t.requestStream != nil {
+		t.streamRequest(req)
+		return nil
+	}
+
 	// Transform body if needed
-	if t.requestTransform != nil && req.Body != nil {
-		body, err := ioutil.ReadAll(req.Body)
-		req.Body.Close()
+	if t.requestTransform != nil {
+		body, rest, err := readBody(req.Body, t.maxBodySize)
+		if rest != nil {
+			slog.Warn("request body exceeds the transform limit; forwarding it untransformed", "path", req.URL.Path, "limit", t.maxBodySize)
+			req.Body = rest
+			return nil
+		}
 		
 		if err == nil {
 			transformed, err := t.requestTransform(body)
 			if err == nil {
 				req.Body = ioutil.NopCloser(bytes.NewBuffer(transformed))
 				req.ContentLength = int64(len(transformed))
-				req.Header.Set("Content-Length", string(rune(len(transformed))))
+				req.Header.Set("Content-Length", strconv.Itoa(len(transformed)))
+				return transformed
 			}
 		}
+		// Bodies that cannot be transformed are forwarded as read
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
 	}
+	return nil
 }
 
 // TransformRequestData transforms raw bytes using the configured request transform function.
@@ -86,19 +156,39 @@ func (t *Transformer) TransformResponseData(data []byte) ([]byte, error) {
 
 // TransformResponse transforms an HTTP response
 func (t *Transformer) TransformResponse(resp *http.Response) error {
-	// Add/modify headers
+	// Add/modify/remove headers
 	for k, v := range t.responseHeaders {
 		resp.Header.Set(k, v)
 	}
+	for _, k := range t.removedResponseHeaders {
+		resp.Header.Del(k)
+	}
 	
+	// Downloads of binary content pass through without being buffered
+	if resp.Body == nil || IsBinary(resp.Header) {
+		return nil
+	}
+	if t.responseStream != nil {
+		t.streamResponse(resp)
+		return nil
+	}
+
+	// Streams are transformed record by record so the client is not stalled
+	if t.responseTransform != nil && IsStreaming(resp) {
+		t.transformStream(resp)
+		return nil
+	}
+
 	// Transform body if needed
-	if t.responseTransform != nil && resp.Body != nil {
-		body, err := ioutil.ReadAll(resp.Body)
-		resp.Body.Close()
-		
+	if t.responseTransform != nil {
+		body, rest, err := readBody(resp.Body, t.maxBodySize)
 		if err != nil {
 			return err
 		}
+		if rest != nil {
+			rest.Close()
+			return &adapter.BodyTooLargeError{Limit: t.maxBodySize}
+		}
 		
 		transformed, err := t.responseTransform(body)
 		if err != nil {
@@ -107,7 +197,7 @@ func (t *Transformer) TransformResponse(resp *http.Response) error {
 		
 		resp.Body = ioutil.NopCloser(bytes.NewBuffer(transformed))
 		resp.ContentLength = int64(len(transformed))
-		resp.Header.Set("Content-Length", string(rune(len(transformed))))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(transformed)))
 	}
 	
 	return nil
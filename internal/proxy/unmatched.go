@@ -0,0 +1,168 @@
+package proxy
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// DefaultUnmatchedLogSize is the number of unmatched intents kept in memory
+const DefaultUnmatchedLogSize = 100
+
+// maxSnippetLength limits how much of the task text is kept per entry
+const maxSnippetLength = 200
+
+// maxNearMisses limits the candidate mappings reported per entry
+const maxNearMisses = 3
+
+// patternWordPattern finds the literal words in an intent pattern
+var patternWordPattern = regexp.MustCompile(`[a-z][a-z0-9]{2,}`)
+
+// patternEscapePattern finds escape sequences such as \b or \s in an intent pattern
+var patternEscapePattern = regexp.MustCompile(`\\.`)
+
+// UnmatchedIntent is a task whose text matched no mapping
+type UnmatchedIntent struct {
+	TaskID     string     `json:"taskId,omitempty"`
+	Text       string     `json:"text"`
+	Timestamp  time.Time  `json:"timestamp"`
+	NearMisses []NearMiss `json:"nearMisses,omitempty"`
+}
+
+// NearMiss is a mapping whose intent pattern shares words with the task text
+type NearMiss struct {
+	IntentPattern string   `json:"intentPattern"`
+	MatchedWords  []string `json:"matchedWords"`
+	Score         float64  `json:"score"`
+}
+
+// UnmatchedLog is a rolling in-memory log of recent unmatched intents
+type UnmatchedLog struct {
+	mu      sync.Mutex
+	entries []UnmatchedIntent
+	next    int
+	full    bool
+}
+
+// NewUnmatchedLog creates a log holding up to size entries
+func NewUnmatchedLog(size int) *UnmatchedLog {
+	if size <= 0 {
+		size = DefaultUnmatchedLogSize
+	}
+	return &UnmatchedLog{entries: make([]UnmatchedIntent, size)}
+}
+
+// Add records an entry, overwriting the oldest one when the log is full
+func (l *UnmatchedLog) Add(entry UnmatchedIntent) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Entries returns the logged entries, newest first
+func (l *UnmatchedLog) Entries() []UnmatchedIntent {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := l.next
+	if l.full {
+		count = len(l.entries)
+	}
+
+	result := make([]UnmatchedIntent, 0, count)
+	for i := 1; i <= count; i++ {
+		idx := (l.next - i + len(l.entries)) % len(l.entries)
+		result = append(result, l.entries[idx])
+	}
+	return result
+}
+
+// Clear removes all entries
+func (l *UnmatchedLog) Clear() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = make([]UnmatchedIntent, len(l.entries))
+	l.next = 0
+	l.full = false
+}
+
+// findNearMisses ranks mappings by how many words of their intent pattern occur in the text
+func findNearMisses(mappings []config.MappingConfig, text string) []NearMiss {
+	var misses []NearMiss
+	for _, mapping := range mappings {
+		if miss := scoreMapping(mapping, text); len(miss.MatchedWords) > 0 {
+			misses = append(misses, miss)
+		}
+	}
+
+	sort.SliceStable(misses, func(i, j int) bool {
+		return misses[i].Score > misses[j].Score
+	})
+	if len(misses) > maxNearMisses {
+		misses = misses[:maxNearMisses]
+	}
+	return misses
+}
+
+// scoreMapping reports the words of the mapping's intent pattern that occur in the text
+// and the fraction of pattern words they make up
+func scoreMapping(mapping config.MappingConfig, text string) NearMiss {
+	text = strings.ToLower(text)
+	pattern := patternEscapePattern.ReplaceAllString(strings.ToLower(mapping.IntentPattern), " ")
+	words := uniqueWords(patternWordPattern.FindAllString(pattern, -1))
+
+	miss := NearMiss{IntentPattern: mapping.IntentPattern}
+	for _, word := range words {
+		if strings.Contains(text, word) {
+			miss.MatchedWords = append(miss.MatchedWords, word)
+		}
+	}
+	if len(words) > 0 {
+		miss.Score = float64(len(miss.MatchedWords)) / float64(len(words))
+	}
+	return miss
+}
+
+// uniqueWords removes duplicate words, keeping their order
+func uniqueWords(words []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+// snippet shortens text for logging
+func snippet(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxSnippetLength {
+		return text
+	}
+	return string(runes[:maxSnippetLength]) + "..."
+}
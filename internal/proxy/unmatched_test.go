@@ -0,0 +1,67 @@
+package proxy_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestUnmatchedIntentLog(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{
+			{IntentPattern: `get.*customer\b`, Endpoint: "/customers/{id}", Method: "GET"},
+			{IntentPattern: `create.*order`, Endpoint: "/orders", Method: "POST"},
+		},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+
+	ct := proxy.NewConfigTransformer(cfg)
+	task := `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"Show me the customer record"}]}}}`
+	if _, err := ct.TransformRequestData([]byte(task)); err == nil {
+		t.Fatal("Expected no matching mapping")
+	}
+
+	entries := ct.Unmatched.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 unmatched entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.TaskID != "task-1" || entry.Text != "Show me the customer record" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if len(entry.NearMisses) != 1 || entry.NearMisses[0].IntentPattern != `get.*customer\b` {
+		t.Errorf("Expected the customer mapping as near miss, got %+v", entry.NearMisses)
+	}
+
+	// Matched tasks are not logged
+	task = `{"id":"task-2","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"Create an order"}]}}}`
+	if _, err := ct.TransformRequestData([]byte(task)); err != nil {
+		t.Fatalf("Expected a match, got %v", err)
+	}
+	if len(ct.Unmatched.Entries()) != 1 {
+		t.Error("Matched task should not be logged")
+	}
+}
+
+func TestUnmatchedLogRollsOver(t *testing.T) {
+	log := proxy.NewUnmatchedLog(3)
+	for i := 0; i < 5; i++ {
+		log.Add(proxy.UnmatchedIntent{Text: fmt.Sprintf("text %d", i), Timestamp: time.Now()})
+	}
+
+	entries := log.Entries()
+	if len(entries) != 3 || entries[0].Text != "text 4" || entries[2].Text != "text 2" {
+		t.Errorf("Expected the 3 newest entries newest first, got %+v", entries)
+	}
+
+	log.Clear()
+	if len(log.Entries()) != 0 {
+		t.Error("Expected an empty log after Clear")
+	}
+}
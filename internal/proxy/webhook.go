@@ -0,0 +1,61 @@
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	a2a "github.com/A2AGateway/a2a-protocol"
+)
+
+// Webhook kinds
+const (
+	WebhookTask    = "task"
+	WebhookMessage = "message"
+)
+
+// TransformEvent turns an event a legacy system posted to a webhook into an
+// A2A task, or a message for webhooks of kind message. The message has the
+// text template of the webhook and the event as data; the legacyToA2a rules
+// of the webhook then copy event fields to the task or message. Tasks are
+// completed, with an ID made of the webhook name and the time unless a rule
+// sets one.
+func TransformEvent(webhook *config.WebhookConfig, event map[string]interface{}, now time.Time) (map[string]interface{}, error) {
+	parts := []map[string]interface{}{}
+	if webhook.CompiledTemplate != nil {
+		var buf bytes.Buffer
+		if err := webhook.CompiledTemplate.Execute(&buf, event); err != nil {
+			return nil, fmt.Errorf("webhook %s text: %w", webhook.Name, err)
+		}
+		parts = append(parts, map[string]interface{}{"type": "text", "text": buf.String()})
+	}
+	parts = append(parts, map[string]interface{}{"type": "data", "data": event})
+
+	message := map[string]interface{}{
+		"role":     "agent",
+		"parts":    parts,
+		"metadata": map[string]interface{}{"webhook": webhook.Name},
+	}
+	if webhook.Kind == WebhookMessage {
+		for _, rule := range webhook.LegacyToA2A {
+			applyTransformRule(rule, event, message)
+		}
+		return message, nil
+	}
+
+	delete(message, "metadata")
+	task := map[string]interface{}{
+		"id": fmt.Sprintf("webhook-%s-%d", webhook.Name, now.UnixNano()),
+		"status": map[string]interface{}{
+			"state":     string(a2a.TaskStateCompleted),
+			"message":   message,
+			"timestamp": now.Format(time.RFC3339),
+		},
+		"metadata": map[string]interface{}{"webhook": webhook.Name},
+	}
+	for _, rule := range webhook.LegacyToA2A {
+		applyTransformRule(rule, event, task)
+	}
+	return task, nil
+}
@@ -0,0 +1,76 @@
+package proxy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestTransformEvent(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter:  config.AdapterConfig{Type: "rest", BaseURL: "http://legacy"},
+		Mappings: []config.MappingConfig{{IntentPattern: `ship order`, Endpoint: "/orders", Method: "POST"}},
+		Webhooks: []config.WebhookConfig{{
+			Name: "orders",
+			Path: "/webhooks/orders",
+			Text: "Order {{.order.id}} shipped",
+			LegacyToA2A: []config.TransformRule{
+				{Source: "order.id", Target: "id", Template: "order-{value}"},
+				{Source: "order.customer", Target: "metadata.customer"},
+			},
+		}, {
+			Name: "alerts",
+			Path: "/webhooks/alerts",
+			Kind: "message",
+			Text: "{{.severity}}: {{.text}}",
+			LegacyToA2A: []config.TransformRule{
+				{Source: "source", Target: "metadata.system"},
+			},
+		}},
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected a valid config: %v", err)
+	}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	event := map[string]interface{}{"order": map[string]interface{}{"id": "A17", "customer": "ACME"}}
+	task, err := proxy.TransformEvent(&cfg.Webhooks[0], event, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task["id"] != "order-A17" {
+		t.Errorf("Expected the rule to set the task ID, got %v", task["id"])
+	}
+	status := task["status"].(map[string]interface{})
+	parts := status["message"].(map[string]interface{})["parts"].([]map[string]interface{})
+	if status["state"] != "completed" || len(parts) != 2 || parts[0]["text"] != "Order A17 shipped" {
+		t.Errorf("Unexpected status %v", status)
+	}
+	metadata := task["metadata"].(map[string]interface{})
+	if metadata["webhook"] != "orders" || metadata["customer"] != "ACME" {
+		t.Errorf("Unexpected metadata %v", metadata)
+	}
+
+	// Tasks without an ID rule are named after the webhook
+	task, _ = proxy.TransformEvent(&cfg.Webhooks[0], map[string]interface{}{}, now)
+	if task["id"] != "webhook-orders-1704164645000000000" {
+		t.Errorf("Unexpected generated ID %v", task["id"])
+	}
+
+	message, err := proxy.TransformEvent(&cfg.Webhooks[1], map[string]interface{}{"severity": "warning", "text": "disk full", "source": "erp"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts = message["parts"].([]map[string]interface{})
+	if message["role"] != "agent" || parts[0]["text"] != "warning: disk full" {
+		t.Errorf("Unexpected message %v", message)
+	}
+	if metadata := message["metadata"].(map[string]interface{}); metadata["system"] != "erp" || metadata["webhook"] != "alerts" {
+		t.Errorf("Unexpected message metadata %v", metadata)
+	}
+}
@@ -0,0 +1,241 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Default circuit breaker settings used when a config value is left empty
+const (
+	DefaultFailureThreshold = 5
+	DefaultCoolDown         = 30 * time.Second
+	DefaultHalfOpenRequests = 1
+)
+
+// ErrCircuitOpen is matched by errors returned while the circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	StateClosed   BreakerState = "closed"
+	StateOpen     BreakerState = "open"
+	StateHalfOpen BreakerState = "half-open"
+)
+
+// CircuitOpenError is returned instead of calling a backend whose circuit is open
+type CircuitOpenError struct {
+	Name    string
+	RetryAt time.Time
+}
+
+// Error implements the error interface
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("legacy system %s is unavailable (circuit breaker open, retry after %s)", e.Name, e.RetryAt.Format(time.RFC3339))
+}
+
+// Is makes errors.Is(err, ErrCircuitOpen) match
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// Details returns the breaker state for error reporting
+func (e *CircuitOpenError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"circuit": string(StateOpen),
+		"backend": e.Name,
+		"retryAt": e.RetryAt.Format(time.RFC3339),
+	}
+}
+
+// CircuitBreaker stops calling a failing backend for a cool-down period.
+// After FailureThreshold consecutive failures the circuit opens; once CoolDown
+// has passed, up to HalfOpenRequests trial calls are let through and the
+// circuit closes again when they all succeed.
+type CircuitBreaker struct {
+	Name             string
+	FailureThreshold int
+	CoolDown         time.Duration
+	HalfOpenRequests int
+
+	// IsFailure decides whether an error counts against the backend;
+	// by default every error does except HTTP responses below 500
+	IsFailure func(error) bool
+
+	// Now returns the current time; it defaults to time.Now
+	Now func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker with the default settings
+func NewCircuitBreaker(name string) *CircuitBreaker {
+	return &CircuitBreaker{
+		Name:             name,
+		FailureThreshold: DefaultFailureThreshold,
+		CoolDown:         DefaultCoolDown,
+		HalfOpenRequests: DefaultHalfOpenRequests,
+		state:            StateClosed,
+	}
+}
+
+// CircuitBreakerFromConfig builds a circuit breaker from config; nil config disables it
+func CircuitBreakerFromConfig(name string, cfg *config.CircuitBreakerConfig) (*CircuitBreaker, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	cb := NewCircuitBreaker(name)
+	if cfg.FailureThreshold > 0 {
+		cb.FailureThreshold = cfg.FailureThreshold
+	}
+	if cfg.CoolDown != "" {
+		d, err := time.ParseDuration(cfg.CoolDown)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coolDown: %w", err)
+		}
+		cb.CoolDown = d
+	}
+	if cfg.HalfOpenRequests > 0 {
+		cb.HalfOpenRequests = cfg.HalfOpenRequests
+	}
+
+	return cb, nil
+}
+
+// Execute runs fn unless the circuit is open and records its outcome.
+// A nil breaker always runs fn.
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	if err := cb.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	cb.Record(err)
+	return err
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by exactly one Record.
+func (cb *CircuitBreaker) Allow() error {
+	if cb == nil {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case StateOpen:
+		return &CircuitOpenError{Name: cb.Name, RetryAt: cb.openedAt.Add(cb.CoolDown)}
+	case StateHalfOpen:
+		if cb.probes >= cb.HalfOpenRequests {
+			return &CircuitOpenError{Name: cb.Name, RetryAt: cb.now().Add(time.Second)}
+		}
+		cb.probes++
+	}
+	return nil
+}
+
+// Record records the outcome of an allowed call
+func (cb *CircuitBreaker) Record(err error) {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	failed := err != nil && cb.isFailure(err)
+	state := cb.currentState()
+
+	if state == StateHalfOpen {
+		if cb.probes > 0 {
+			cb.probes--
+		}
+		if failed {
+			cb.trip()
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.HalfOpenRequests {
+			cb.reset()
+		}
+		return
+	}
+
+	if !failed {
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.failures >= cb.FailureThreshold {
+		cb.trip()
+	}
+}
+
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() BreakerState {
+	if cb == nil {
+		return StateClosed
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.currentState()
+}
+
+// currentState moves an open circuit to half-open once the cool-down has passed
+func (cb *CircuitBreaker) currentState() BreakerState {
+	if cb.state == "" {
+		cb.state = StateClosed
+	}
+	if cb.state == StateOpen && !cb.now().Before(cb.openedAt.Add(cb.CoolDown)) {
+		cb.state = StateHalfOpen
+		cb.probes = 0
+		cb.successes = 0
+	}
+	return cb.state
+}
+
+func (cb *CircuitBreaker) trip() {
+	cb.state = StateOpen
+	cb.openedAt = cb.now()
+	cb.failures = 0
+	cb.probes = 0
+	cb.successes = 0
+}
+
+func (cb *CircuitBreaker) reset() {
+	cb.state = StateClosed
+	cb.failures = 0
+	cb.probes = 0
+	cb.successes = 0
+}
+
+func (cb *CircuitBreaker) isFailure(err error) bool {
+	if cb.IsFailure != nil {
+		return cb.IsFailure(err)
+	}
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return coder.HTTPStatusCode() >= 500
+	}
+	return true
+}
+
+func (cb *CircuitBreaker) now() time.Time {
+	if cb.Now != nil {
+		return cb.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,96 @@
+package resilience_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	cb, err := resilience.CircuitBreakerFromConfig("crm", &config.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		CoolDown:         "10s",
+	})
+	if err != nil {
+		t.Fatalf("Failed to build breaker: %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cb.Now = func() time.Time { return now }
+
+	down := errors.New("connection refused")
+	calls := 0
+	failing := func() error {
+		calls++
+		return down
+	}
+
+	// Consecutive failures open the circuit
+	cb.Execute(failing)
+	cb.Execute(failing)
+	if cb.State() != resilience.StateOpen {
+		t.Fatalf("Expected open circuit, got %s", cb.State())
+	}
+
+	// Calls fail fast while open
+	err = cb.Execute(failing)
+	if !errors.Is(err, resilience.ErrCircuitOpen) || calls != 2 {
+		t.Fatalf("Expected fail-fast error without calling the backend, got %v after %d calls", err, calls)
+	}
+	var openErr *resilience.CircuitOpenError
+	if !errors.As(err, &openErr) || !openErr.RetryAt.Equal(now.Add(10*time.Second)) {
+		t.Errorf("Unexpected open error: %v", err)
+	}
+
+	// After the cool-down a failed trial call re-opens the circuit
+	now = now.Add(10 * time.Second)
+	if cb.State() != resilience.StateHalfOpen {
+		t.Fatalf("Expected half-open circuit, got %s", cb.State())
+	}
+	cb.Execute(failing)
+	if cb.State() != resilience.StateOpen || calls != 3 {
+		t.Fatalf("Expected circuit to re-open after a failed trial, got %s", cb.State())
+	}
+
+	// A successful trial call closes it
+	now = now.Add(10 * time.Second)
+	if err := cb.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("Expected trial call to run, got %v", err)
+	}
+	if cb.State() != resilience.StateClosed {
+		t.Errorf("Expected closed circuit, got %s", cb.State())
+	}
+
+	// Client errors do not count against the backend
+	for i := 0; i < 5; i++ {
+		cb.Execute(func() error { return statusError(404) })
+	}
+	if cb.State() != resilience.StateClosed {
+		t.Errorf("Expected 4xx responses to keep the circuit closed, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerHalfOpenLimit(t *testing.T) {
+	cb := resilience.NewCircuitBreaker("crm")
+	cb.FailureThreshold = 1
+	now := time.Now()
+	cb.Now = func() time.Time { return now }
+
+	cb.Execute(func() error { return errors.New("timeout") })
+	now = now.Add(cb.CoolDown)
+
+	// Only one trial call is let through while half-open
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("Expected first trial call to be allowed, got %v", err)
+	}
+	if err := cb.Allow(); !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Errorf("Expected second concurrent trial call to be rejected, got %v", err)
+	}
+	cb.Record(nil)
+	if cb.State() != resilience.StateClosed {
+		t.Errorf("Expected closed circuit, got %s", cb.State())
+	}
+}
@@ -0,0 +1,333 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Overflow behaviors when a limit is reached
+const (
+	OverflowQueue  = "queue"
+	OverflowReject = "reject"
+)
+
+// DefaultMaxWait is how long queued calls wait for capacity by default
+const DefaultMaxWait = 10 * time.Second
+
+// LimitError is returned when a call exceeds the rate or concurrency limit
+type LimitError struct {
+	Name       string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("legacy system %s is at capacity (%s), retry after %s", e.Name, e.Reason, e.RetryAfter)
+}
+
+// Details returns the limit and retry hint for error reporting
+func (e *LimitError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"backend":           e.Name,
+		"reason":            e.Reason,
+		"retryAfterSeconds": math.Ceil(e.RetryAfter.Seconds()),
+	}
+}
+
+// ExpiredError is returned when a call's task expires before it is executed,
+// e.g. while queued in the limiter
+type ExpiredError struct {
+	Name      string
+	ExpiresAt time.Time
+}
+
+// Error implements the error interface
+func (e *ExpiredError) Error() string {
+	return fmt.Sprintf("expired before execution: the task expired at %s", e.ExpiresAt.Format(time.RFC3339))
+}
+
+// Details returns the expiry for error reporting
+func (e *ExpiredError) Details() map[string]interface{} {
+	details := map[string]interface{}{
+		"reason":    "expired",
+		"expiresAt": e.ExpiresAt.Format(time.RFC3339),
+	}
+	if e.Name != "" {
+		details["backend"] = e.Name
+	}
+	return details
+}
+
+// Queueing is how a call waits in the limiter queue: calls with a higher
+// priority take a free concurrency slot first, and a call still queued at
+// ExpiresAt fails with an ExpiredError
+type Queueing struct {
+	Priority  int
+	ExpiresAt time.Time
+}
+
+type queueingKey struct{}
+
+// WithQueueing returns a context carrying the queueing of a call
+func WithQueueing(ctx context.Context, q Queueing) context.Context {
+	return context.WithValue(ctx, queueingKey{}, q)
+}
+
+// QueueingFromContext returns the queueing carried by ctx, if any
+func QueueingFromContext(ctx context.Context) (Queueing, bool) {
+	q, ok := ctx.Value(queueingKey{}).(Queueing)
+	return q, ok
+}
+
+// Limiter caps the call rate (token bucket) and the number of calls in flight
+// toward a backend. Calls over a limit either wait up to MaxWait or are
+// rejected immediately, depending on Overflow.
+type Limiter struct {
+	Name     string
+	Overflow string
+	MaxWait  time.Duration
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	maxConcurrent int
+	inFlight      int
+	waiters       []*slotWaiter
+}
+
+// slotWaiter is a call queued for a concurrency slot
+type slotWaiter struct {
+	priority int
+	ready    chan struct{}
+}
+
+// NewLimiter creates a limiter; a zero rate or maxConcurrent disables that limit
+func NewLimiter(name string, rate float64, burst, maxConcurrent int) *Limiter {
+	l := &Limiter{
+		Name:     name,
+		Overflow: OverflowQueue,
+		MaxWait:  DefaultMaxWait,
+		rate:     rate,
+	}
+	if rate > 0 {
+		if burst <= 0 {
+			burst = int(math.Max(1, math.Ceil(rate)))
+		}
+		l.burst = float64(burst)
+		l.tokens = l.burst
+		l.last = time.Now()
+	}
+	l.maxConcurrent = maxConcurrent
+	return l
+}
+
+// LimiterFromConfig builds a limiter from adapter config; nil config disables limiting
+func LimiterFromConfig(name string, cfg *config.LimitsConfig) (*Limiter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	l := NewLimiter(name, cfg.RatePerSecond, cfg.Burst, cfg.MaxConcurrent)
+	switch cfg.Overflow {
+	case "", OverflowQueue:
+	case OverflowReject:
+		l.Overflow = OverflowReject
+	default:
+		return nil, fmt.Errorf("unsupported overflow behavior %q", cfg.Overflow)
+	}
+	if cfg.MaxWait != "" {
+		d, err := time.ParseDuration(cfg.MaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxWait: %w", err)
+		}
+		l.MaxWait = d
+	}
+
+	return l, nil
+}
+
+// Acquire waits for or rejects a call according to the limits. On success the
+// returned function must be called when the call finishes. A nil limiter
+// always admits the call.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	q, _ := QueueingFromContext(ctx)
+	if !q.ExpiresAt.IsZero() && !time.Now().Before(q.ExpiresAt) {
+		return nil, &ExpiredError{Name: l.Name, ExpiresAt: q.ExpiresAt}
+	}
+
+	deadline := time.Now().Add(l.MaxWait)
+	if l.Overflow == OverflowReject {
+		deadline = time.Now()
+	}
+	// Calls stop waiting when their task expires
+	expiring := !q.ExpiresAt.IsZero() && q.ExpiresAt.Before(deadline)
+	if expiring {
+		deadline = q.ExpiresAt
+	}
+
+	err := l.takeToken(ctx, deadline)
+	if err == nil {
+		err = l.takeSlot(ctx, deadline, q.Priority)
+	}
+	var limitErr *LimitError
+	if expiring && errors.As(err, &limitErr) {
+		return nil, &ExpiredError{Name: l.Name, ExpiresAt: q.ExpiresAt}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(l.releaseSlot)
+	}, nil
+}
+
+// takeToken reserves a rate token, waiting until deadline at most
+func (l *Limiter) takeToken(ctx context.Context, deadline time.Time) error {
+	if l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	// Reserve the token; a negative balance is the queue of waiting calls
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	if wait > 0 && now.Add(wait).After(deadline) {
+		l.tokens++
+		l.mu.Unlock()
+		return &LimitError{Name: l.Name, Reason: "rate limit", RetryAfter: wait}
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// takeSlot occupies a concurrency slot, waiting until deadline at most
+func (l *Limiter) takeSlot(ctx context.Context, deadline time.Time, priority int) error {
+	if l.maxConcurrent <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	if l.inFlight < l.maxConcurrent {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	wait := time.Until(deadline)
+	if wait <= 0 {
+		l.mu.Unlock()
+		return &LimitError{Name: l.Name, Reason: "concurrency limit", RetryAfter: time.Second}
+	}
+	w := &slotWaiter{priority: priority, ready: make(chan struct{})}
+	l.waiters = append(l.waiters, w)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-timer.C:
+		err = &LimitError{Name: l.Name, Reason: "concurrency limit", RetryAfter: time.Second}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// The slot may have been handed over while giving up; pass it on
+	if !l.removeWaiter(w) {
+		l.releaseSlot()
+	}
+	return err
+}
+
+// releaseSlot hands a finished call's slot to the waiting call with the
+// highest priority, first come first served among equals, or frees it
+func (l *Limiter) releaseSlot() {
+	if l.maxConcurrent <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.waiters) == 0 {
+		l.inFlight--
+		return
+	}
+	next := 0
+	for i, w := range l.waiters {
+		if w.priority > l.waiters[next].priority {
+			next = i
+		}
+	}
+	w := l.waiters[next]
+	l.waiters = append(l.waiters[:next], l.waiters[next+1:]...)
+	close(w.ready)
+}
+
+// removeWaiter dequeues a waiting call and reports whether it was still queued
+func (l *Limiter) removeWaiter(w *slotWaiter) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, queued := range l.waiters {
+		if queued == w {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// InFlight returns the number of calls currently holding a concurrency slot
+func (l *Limiter) InFlight() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// Queued returns the number of calls waiting for a concurrency slot
+func (l *Limiter) Queued() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.waiters)
+}
@@ -0,0 +1,162 @@
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+func TestLimiterConcurrency(t *testing.T) {
+	l, err := resilience.LimiterFromConfig("mainframe", &config.LimitsConfig{
+		MaxConcurrent: 2,
+		Overflow:      "reject",
+	})
+	if err != nil {
+		t.Fatalf("Failed to build limiter: %v", err)
+	}
+
+	ctx := context.Background()
+	release1, err := l.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Expected first call to be admitted, got %v", err)
+	}
+	if _, err := l.Acquire(ctx); err != nil {
+		t.Fatalf("Expected second call to be admitted, got %v", err)
+	}
+
+	_, err = l.Acquire(ctx)
+	var limitErr *resilience.LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Expected LimitError, got %v", err)
+	}
+	if limitErr.Details()["retryAfterSeconds"] != float64(1) {
+		t.Errorf("Unexpected details: %v", limitErr.Details())
+	}
+	if l.InFlight() != 2 {
+		t.Errorf("Expected 2 calls in flight, got %d", l.InFlight())
+	}
+
+	// Releasing frees a slot; releasing twice is harmless
+	release1()
+	release1()
+	if _, err := l.Acquire(ctx); err != nil {
+		t.Errorf("Expected call to be admitted after release, got %v", err)
+	}
+}
+
+func TestLimiterQueue(t *testing.T) {
+	l, err := resilience.LimiterFromConfig("mainframe", &config.LimitsConfig{
+		MaxConcurrent: 1,
+		MaxWait:       "1s",
+	})
+	if err != nil {
+		t.Fatalf("Failed to build limiter: %v", err)
+	}
+
+	release, _ := l.Acquire(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		release()
+	}()
+
+	// Queued calls wait for a free slot
+	if _, err := l.Acquire(context.Background()); err != nil {
+		t.Errorf("Expected queued call to be admitted, got %v", err)
+	}
+
+	// Cancelled callers stop waiting
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestLimiterRate(t *testing.T) {
+	l := resilience.NewLimiter("mainframe", 1, 2, 0)
+	l.Overflow = resilience.OverflowReject
+
+	for i := 0; i < 2; i++ {
+		if _, err := l.Acquire(context.Background()); err != nil {
+			t.Fatalf("Expected burst call %d to be admitted, got %v", i, err)
+		}
+	}
+
+	_, err := l.Acquire(context.Background())
+	var limitErr *resilience.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Reason != "rate limit" {
+		t.Fatalf("Expected rate limit error, got %v", err)
+	}
+	if limitErr.RetryAfter <= 0 || limitErr.RetryAfter > time.Second {
+		t.Errorf("Unexpected retry-after %s", limitErr.RetryAfter)
+	}
+
+	// A nil limiter admits everything
+	var none *resilience.Limiter
+	if _, err := none.Acquire(context.Background()); err != nil {
+		t.Errorf("Expected nil limiter to admit, got %v", err)
+	}
+}
+
+func TestLimiterPriority(t *testing.T) {
+	l := resilience.NewLimiter("mainframe", 0, 0, 1)
+	l.MaxWait = time.Second
+	release, _ := l.Acquire(context.Background())
+
+	// A low and then a high priority call queue for the only slot
+	admitted := make(chan int, 2)
+	for i, priority := range []int{1, 5} {
+		ctx := resilience.WithQueueing(context.Background(), resilience.Queueing{Priority: priority})
+		go func(priority int) {
+			release, err := l.Acquire(ctx)
+			if err != nil {
+				t.Errorf("Expected priority %d to be admitted, got %v", priority, err)
+				admitted <- 0
+				return
+			}
+			admitted <- priority
+			release()
+		}(priority)
+		for l.Queued() < i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	release()
+	if first, second := <-admitted, <-admitted; first != 5 || second != 1 {
+		t.Errorf("Expected the high priority call first, got %d then %d", first, second)
+	}
+	if l.InFlight() != 0 || l.Queued() != 0 {
+		t.Errorf("Expected the limiter to be idle, got %d in flight, %d queued", l.InFlight(), l.Queued())
+	}
+}
+
+func TestLimiterExpiry(t *testing.T) {
+	l := resilience.NewLimiter("mainframe", 0, 0, 1)
+	l.MaxWait = time.Second
+	release, _ := l.Acquire(context.Background())
+	defer release()
+
+	// A queued call gives up when its task expires, before maxWait
+	expiresAt := time.Now().Add(20 * time.Millisecond)
+	ctx := resilience.WithQueueing(context.Background(), resilience.Queueing{ExpiresAt: expiresAt})
+	start := time.Now()
+	_, err := l.Acquire(ctx)
+	var expired *resilience.ExpiredError
+	if !errors.As(err, &expired) || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("Expected the call to expire, got %v after %s", err, time.Since(start))
+	}
+	if expired.Details()["reason"] != "expired" {
+		t.Errorf("Unexpected details: %v", expired.Details())
+	}
+
+	// Calls that are already expired are not admitted even with capacity
+	release()
+	if _, err := l.Acquire(ctx); !errors.As(err, &expired) {
+		t.Errorf("Expected an expired call to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// BusyError is returned when every worker is busy and the queue is full
+type BusyError struct {
+	Workers    int
+	Queued     int
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("connector is busy (%d workers, %d tasks queued), retry after %s", e.Workers, e.Queued, e.RetryAfter)
+}
+
+// Details returns the pool state and retry hint for error reporting
+func (e *BusyError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"reason":            "busy",
+		"workers":           e.Workers,
+		"queued":            e.Queued,
+		"retryAfterSeconds": math.Ceil(e.RetryAfter.Seconds()),
+	}
+}
+
+// WorkerPool bounds the tasks executing at once across all backends. Tasks
+// beyond the number of workers wait in a queue, taking a free worker by
+// priority and first come first served among equals; tasks that find the
+// queue full are rejected with a BusyError instead of piling up on the
+// legacy system.
+type WorkerPool struct {
+	size      int
+	queueSize int
+
+	mu      sync.Mutex
+	busy    int
+	waiters []*slotWaiter
+	// average is the moving average of how long tasks hold a worker
+	average time.Duration
+}
+
+// NewWorkerPool creates a pool of size workers with a queue of queueSize tasks
+func NewWorkerPool(size, queueSize int) *WorkerPool {
+	return &WorkerPool{size: size, queueSize: queueSize}
+}
+
+// WorkerPoolFromConfig builds a pool from config; nil config disables the pool
+func WorkerPoolFromConfig(cfg *config.WorkersConfig) (*WorkerPool, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.Size <= 0 {
+		return nil, fmt.Errorf("size must be positive")
+	}
+	if cfg.QueueSize < 0 {
+		return nil, fmt.Errorf("queueSize must not be negative")
+	}
+	return NewWorkerPool(cfg.Size, cfg.QueueSize), nil
+}
+
+// Acquire takes a worker for a task, waiting in the queue while all are busy.
+// A task still queued when it expires fails with an ExpiredError. On success
+// the returned function must be called when the task finishes. A nil pool
+// always admits the task.
+func (p *WorkerPool) Acquire(ctx context.Context) (func(), error) {
+	if p == nil {
+		return func() {}, nil
+	}
+
+	q, _ := QueueingFromContext(ctx)
+	p.mu.Lock()
+	if p.busy < p.size {
+		p.busy++
+		p.mu.Unlock()
+		return p.release(time.Now()), nil
+	}
+	if len(p.waiters) >= p.queueSize {
+		err := &BusyError{Workers: p.size, Queued: len(p.waiters), RetryAfter: p.retryAfter()}
+		p.mu.Unlock()
+		return nil, err
+	}
+	w := &slotWaiter{priority: q.Priority, ready: make(chan struct{})}
+	p.waiters = append(p.waiters, w)
+	p.mu.Unlock()
+
+	var expired <-chan time.Time
+	if !q.ExpiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(q.ExpiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var err error
+	select {
+	case <-w.ready:
+		return p.release(time.Now()), nil
+	case <-expired:
+		err = &ExpiredError{ExpiresAt: q.ExpiresAt}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// The worker may have been handed over while giving up; pass it on
+	if !p.removeWaiter(w) {
+		p.handOver()
+	}
+	return nil, err
+}
+
+// release returns the function that frees a worker taken at start
+func (p *WorkerPool) release(start time.Time) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			held := time.Since(start)
+			if p.average == 0 {
+				p.average = held
+			} else {
+				p.average += (held - p.average) / 8
+			}
+			p.mu.Unlock()
+			p.handOver()
+		})
+	}
+}
+
+// handOver gives a freed worker to the queued task with the highest
+// priority, or marks it idle
+func (p *WorkerPool) handOver() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.waiters) == 0 {
+		p.busy--
+		return
+	}
+	next := 0
+	for i, w := range p.waiters {
+		if w.priority > p.waiters[next].priority {
+			next = i
+		}
+	}
+	w := p.waiters[next]
+	p.waiters = append(p.waiters[:next], p.waiters[next+1:]...)
+	close(w.ready)
+}
+
+// removeWaiter dequeues a waiting task and reports whether it was still queued
+func (p *WorkerPool) removeWaiter(w *slotWaiter) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, queued := range p.waiters {
+		if queued == w {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter estimates when the queue has room again: the time the workers
+// take to work through it at the average task duration, at least a second.
+// The caller holds p.mu.
+func (p *WorkerPool) retryAfter() time.Duration {
+	wait := p.average * time.Duration(len(p.waiters)+1) / time.Duration(p.size)
+	if wait < time.Second {
+		return time.Second
+	}
+	return wait.Round(time.Second)
+}
+
+// Busy returns the number of workers executing a task
+func (p *WorkerPool) Busy() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.busy
+}
+
+// Queued returns the number of tasks waiting for a worker
+func (p *WorkerPool) Queued() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.waiters)
+}
@@ -0,0 +1,85 @@
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+func TestWorkerPool(t *testing.T) {
+	p, err := resilience.WorkerPoolFromConfig(&config.WorkersConfig{Size: 1, QueueSize: 2})
+	if err != nil {
+		t.Fatalf("Failed to build pool: %v", err)
+	}
+
+	ctx := context.Background()
+	release, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Expected the first task to get a worker, got %v", err)
+	}
+
+	// Two tasks queue, the higher priority one is served first
+	order := make(chan int, 2)
+	for _, priority := range []int{1, 5} {
+		go func(priority int) {
+			ctx := resilience.WithQueueing(ctx, resilience.Queueing{Priority: priority})
+			release, err := p.Acquire(ctx)
+			if err != nil {
+				t.Errorf("Expected queued task to get a worker, got %v", err)
+				return
+			}
+			order <- priority
+			release()
+		}(priority)
+	}
+	for deadline := time.Now().Add(time.Second); p.Queued() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A full queue rejects further tasks with a retry hint
+	_, err = p.Acquire(ctx)
+	var busy *resilience.BusyError
+	if !errors.As(err, &busy) {
+		t.Fatalf("Expected BusyError, got %v", err)
+	}
+	details := busy.Details()
+	if details["queued"] != 2 || details["retryAfterSeconds"] != float64(1) {
+		t.Errorf("Unexpected details: %v", details)
+	}
+
+	release()
+	if first, second := <-order, <-order; first != 5 || second != 1 {
+		t.Errorf("Expected priority 5 before 1, got %d then %d", first, second)
+	}
+	if p.Busy() != 0 || p.Queued() != 0 {
+		t.Errorf("Expected an idle pool, got %d busy and %d queued", p.Busy(), p.Queued())
+	}
+}
+
+func TestWorkerPoolExpiry(t *testing.T) {
+	p := resilience.NewWorkerPool(1, 1)
+	release, _ := p.Acquire(context.Background())
+	defer release()
+
+	expiresAt := time.Now().Add(20 * time.Millisecond)
+	ctx := resilience.WithQueueing(context.Background(), resilience.Queueing{ExpiresAt: expiresAt})
+	_, err := p.Acquire(ctx)
+	var expired *resilience.ExpiredError
+	if !errors.As(err, &expired) {
+		t.Fatalf("Expected ExpiredError, got %v", err)
+	}
+	if p.Queued() != 0 {
+		t.Errorf("Expected the expired task to leave the queue, got %d queued", p.Queued())
+	}
+
+	// Without a queue, tasks are rejected as soon as all workers are busy
+	p = resilience.NewWorkerPool(1, 0)
+	p.Acquire(context.Background())
+	if _, err := p.Acquire(context.Background()); err == nil {
+		t.Error("Expected the task to be rejected")
+	}
+}
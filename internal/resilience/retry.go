@@ -0,0 +1,182 @@
+// Package resilience provides retry and failure-isolation helpers shared by adapters
+package resilience
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Default retry settings used when a config value is left empty
+const (
+	DefaultMaxAttempts    = 3
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 5 * time.Second
+	DefaultMultiplier     = 2.0
+)
+
+// DefaultRetryableStatuses are the HTTP status codes retried by default
+var DefaultRetryableStatuses = []int{429, 502, 503, 504}
+
+// StatusCoder is implemented by errors that carry an HTTP status code
+type StatusCoder interface {
+	HTTPStatusCode() int
+}
+
+// RetryPolicy retries an operation with exponential backoff and jitter
+type RetryPolicy struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	Multiplier        float64
+	Jitter            float64
+	RetryableStatuses []int
+	RetryableErrors   []string
+
+	// Sleep waits between attempts; it defaults to time.Sleep
+	Sleep func(time.Duration)
+}
+
+// NewRetryPolicy creates a retry policy with the default settings
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       DefaultMaxAttempts,
+		InitialBackoff:    DefaultInitialBackoff,
+		MaxBackoff:        DefaultMaxBackoff,
+		Multiplier:        DefaultMultiplier,
+		RetryableStatuses: DefaultRetryableStatuses,
+	}
+}
+
+// RetryPolicyFromConfig builds a retry policy from adapter config; nil config disables retries
+func RetryPolicyFromConfig(cfg *config.RetryConfig) (*RetryPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	policy := NewRetryPolicy()
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoff != "" {
+		d, err := time.ParseDuration(cfg.InitialBackoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid initialBackoff: %w", err)
+		}
+		policy.InitialBackoff = d
+	}
+	if cfg.MaxBackoff != "" {
+		d, err := time.ParseDuration(cfg.MaxBackoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxBackoff: %w", err)
+		}
+		policy.MaxBackoff = d
+	}
+	if cfg.Multiplier > 0 {
+		policy.Multiplier = cfg.Multiplier
+	}
+	policy.Jitter = cfg.Jitter
+	if len(cfg.RetryableStatuses) > 0 {
+		policy.RetryableStatuses = cfg.RetryableStatuses
+	}
+	policy.RetryableErrors = cfg.RetryableErrors
+
+	return policy, nil
+}
+
+// Do runs fn until it succeeds, returns a non-retryable error, or attempts run out.
+// A nil policy runs fn exactly once.
+func (p *RetryPolicy) Do(fn func() error) error {
+	return p.DoContext(context.Background(), fn)
+}
+
+// DoContext is like Do but stops retrying once ctx is done
+func (p *RetryPolicy) DoContext(ctx context.Context, fn func() error) error {
+	if p == nil || p.MaxAttempts <= 1 {
+		return fn()
+	}
+
+	var err error
+	for attempt := 1; attempt <= p.MaxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !p.Retryable(err) || attempt == p.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+		if !p.wait(ctx, p.Backoff(attempt)) {
+			break
+		}
+	}
+	return err
+}
+
+// Retryable reports whether err is a transient failure worth retrying
+func (p *RetryPolicy) Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		code := coder.HTTPStatusCode()
+		for _, status := range p.RetryableStatuses {
+			if status == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, fragment := range p.RetryableErrors {
+		if fragment != "" && strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the wait before the attempt following the given one
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+// wait sleeps for d and reports false if ctx ended first
+func (p *RetryPolicy) wait(ctx context.Context, d time.Duration) bool {
+	if p.Sleep != nil {
+		p.Sleep(d)
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package resilience_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("HTTP %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+func TestRetryPolicy(t *testing.T) {
+	policy, err := resilience.RetryPolicyFromConfig(&config.RetryConfig{
+		MaxAttempts:     4,
+		InitialBackoff:  "10ms",
+		MaxBackoff:      "25ms",
+		RetryableErrors: []string{"deadlock"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build policy: %v", err)
+	}
+
+	var waits []time.Duration
+	policy.Sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	// Retryable status codes are retried until success
+	calls := 0
+	err = policy.Do(func() error {
+		calls++
+		if calls < 3 {
+			return statusError(503)
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("Expected success after 3 calls, got %d calls and error %v", calls, err)
+	}
+	if len(waits) != 2 || waits[0] != 10*time.Millisecond || waits[1] != 20*time.Millisecond {
+		t.Errorf("Unexpected backoff: %v", waits)
+	}
+
+	// Backoff is capped at maxBackoff
+	if got := policy.Backoff(5); got != 25*time.Millisecond {
+		t.Errorf("Expected backoff capped at 25ms, got %v", got)
+	}
+
+	// Non-retryable failures are returned immediately
+	calls = 0
+	err = policy.Do(func() error {
+		calls++
+		return statusError(404)
+	})
+	if calls != 1 || err == nil {
+		t.Errorf("Expected a single call for a 404, got %d", calls)
+	}
+
+	// Configured error fragments are retried until attempts run out
+	calls = 0
+	err = policy.Do(func() error {
+		calls++
+		return errors.New("deadlock detected")
+	})
+	if calls != 4 || err == nil {
+		t.Errorf("Expected 4 attempts, got %d", calls)
+	}
+
+	// A nil policy runs once
+	var none *resilience.RetryPolicy
+	calls = 0
+	none.Do(func() error {
+		calls++
+		return statusError(503)
+	})
+	if calls != 1 {
+		t.Errorf("Expected a nil policy to run once, got %d", calls)
+	}
+}
+
+func TestRetryJitter(t *testing.T) {
+	policy := resilience.NewRetryPolicy()
+	policy.InitialBackoff = 100 * time.Millisecond
+	policy.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		d := policy.Backoff(1)
+		if d < 50*time.Millisecond || d > 150*time.Millisecond {
+			t.Fatalf("Backoff %v outside jitter range", d)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Job states reported by Status
+const (
+	StateIdle    = "idle"
+	StateRunning = "running"
+)
+
+// RunFunc runs a job for the time it was due
+type RunFunc func(ctx context.Context, due time.Time) error
+
+// job is a scheduled job and the outcome of its last run
+type job struct {
+	name string
+	spec string
+	cron *Cron
+	run  RunFunc
+
+	mu        sync.Mutex
+	running   bool
+	next      time.Time
+	lastRun   time.Time
+	lastError string
+	runs      int
+	skipped   int
+}
+
+// JobStatus is the state of a job for the admin API
+type JobStatus struct {
+	Name      string     `json:"name"`
+	Cron      string     `json:"cron"`
+	State     string     `json:"state"`
+	Next      *time.Time `json:"next,omitempty"`
+	LastRun   *time.Time `json:"lastRun,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	Runs      int        `json:"runs"`
+	Skipped   int        `json:"skipped,omitempty"`
+}
+
+// Scheduler runs jobs when their cron specs are due. A job runs once at a
+// time; a run that takes longer than the interval skips the times it missed.
+type Scheduler struct {
+	mu   sync.Mutex
+	jobs []*job
+
+	// Active reports whether due jobs run on this replica, such as whether it
+	// holds the leader lease; jobs that are due while it returns false are
+	// skipped. Nil runs all jobs.
+	Active func() bool
+	// Now can be replaced in tests
+	Now func() time.Time
+}
+
+// New creates a scheduler without jobs
+func New() *Scheduler {
+	return &Scheduler{Now: time.Now}
+}
+
+// Add schedules run at the times of spec, evaluated in loc
+func (s *Scheduler) Add(name, spec string, loc *time.Location, run RunFunc) error {
+	cron, err := ParseCron(spec, loc)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &job{name: name, spec: spec, cron: cron, run: run})
+	return nil
+}
+
+// Len returns the number of jobs
+func (s *Scheduler) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+// Run runs the jobs as they are due until ctx is cancelled, and waits for
+// runs in progress to return
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+}
+
+// loop runs a job each time it is due
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	for {
+		now := s.Now()
+		next := j.cron.Next(now)
+		if next.IsZero() {
+			return
+		}
+		j.mu.Lock()
+		j.next = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if s.Active != nil && !s.Active() {
+			j.mu.Lock()
+			j.skipped++
+			j.mu.Unlock()
+			continue
+		}
+		s.runJob(ctx, j, next)
+	}
+}
+
+// runJob runs a job and records its outcome
+func (s *Scheduler) runJob(ctx context.Context, j *job, due time.Time) {
+	j.mu.Lock()
+	j.running = true
+	j.lastRun = s.Now()
+	j.mu.Unlock()
+
+	err := j.run(ctx, due)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.running = false
+	j.runs++
+	j.lastError = ""
+	if err != nil {
+		j.lastError = err.Error()
+	}
+}
+
+// Status returns the state of the jobs in the order they were added
+func (s *Scheduler) Status() []JobStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		status := JobStatus{
+			Name:      j.name,
+			Cron:      j.spec,
+			State:     StateIdle,
+			LastError: j.lastError,
+			Runs:      j.runs,
+			Skipped:   j.skipped,
+		}
+		if j.running {
+			status.State = StateRunning
+		}
+		if !j.next.IsZero() {
+			next := j.next
+			status.Next = &next
+		}
+		if !j.lastRun.IsZero() {
+			lastRun := j.lastRun
+			status.LastRun = &lastRun
+		}
+		j.mu.Unlock()
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,98 @@
+package schedule_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/schedule"
+)
+
+func TestCronNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+	from := time.Date(2024, 1, 31, 10, 17, 30, 0, time.UTC) // a Wednesday
+
+	tests := []struct {
+		spec string
+		loc  *time.Location
+		want time.Time
+	}{
+		{"*/15 * * * *", nil, time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", nil, time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * mon-fri", nil, time.Date(2024, 1, 31, 13, 0, 0, 0, time.UTC)},
+		{"30 2 * feb *", nil, time.Date(2024, 2, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", nil, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", nil, time.Date(2024, 2, 4, 12, 0, 0, 0, time.UTC)},
+		// With both day fields restricted, either day matches
+		{"0 0 15 * fri", nil, time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * *", berlin, time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"@every 90m", nil, from.Add(90 * time.Minute)},
+	}
+	for _, tt := range tests {
+		cron, err := schedule.ParseCron(tt.spec, tt.loc)
+		if err != nil {
+			t.Errorf("%s: %v", tt.spec, err)
+			continue
+		}
+		if got := cron.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.spec, tt.want, got.UTC())
+		}
+	}
+
+	// Specs that never match have no next time
+	if cron, _ := schedule.ParseCron("0 0 30 2 *", nil); !cron.Next(from).IsZero() {
+		t.Error("Expected no time for February 30th")
+	}
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "* * * foo *", "5-1 * * * *", "*/0 * * * *", "@every 1ms", "@often"} {
+		if _, err := schedule.ParseCron(spec, nil); err == nil {
+			t.Errorf("Expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	s := schedule.New()
+	var runs, passive atomic.Int32
+	if err := s.Add("sync", "@every 1s", nil, func(ctx context.Context, due time.Time) error {
+		runs.Add(1)
+		return errors.New("legacy down")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("report", "@every 1s", nil, func(ctx context.Context, due time.Time) error {
+		passive.Add(1)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Jobs run only while the scheduler is active
+	var active atomic.Bool
+	active.Store(true)
+	s.Active = active.Load
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+	defer cancel()
+	go func() {
+		time.Sleep(1500 * time.Millisecond)
+		active.Store(false)
+	}()
+	s.Run(ctx)
+
+	if runs.Load() != 1 || passive.Load() != 1 {
+		t.Errorf("Expected one run of each job while active, got %d and %d", runs.Load(), passive.Load())
+	}
+	status := s.Status()
+	if len(status) != 2 || status[0].Name != "sync" || status[0].Runs != 1 || status[0].Skipped != 1 || status[0].LastError != "legacy down" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if status[1].LastError != "" || status[1].State != schedule.StateIdle || status[1].LastRun == nil || status[1].Next == nil {
+		t.Errorf("Unexpected status %+v", status[1])
+	}
+}
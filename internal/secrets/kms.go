@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DecryptKMS decrypts a ciphertext blob with the AWS KMS key arn, such as the
+// data key of a SOPS file. The region is taken from the key ARN; credentials
+// come from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+func (r *Resolver) DecryptKMS(ctx context.Context, arn string, ciphertext []byte, encryptionContext map[string]string) ([]byte, error) {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 6 || parts[0] != "arn" || parts[2] != "kms" {
+		return nil, fmt.Errorf("invalid kms key arn %q", arn)
+	}
+	region := parts[3]
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("aws credentials are not configured")
+	}
+
+	endpoint := r.opts.KMSEndpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + region + ".amazonaws.com/"
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"CiphertextBlob":    base64.StdEncoding.EncodeToString(ciphertext),
+		"KeyId":             arn,
+		"EncryptionContext": encryptionContext,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signV4(req, payload, accessKey, secretKey, region, "kms", time.Now().UTC())
+
+	resp, err := r.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("kms request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type string `json:"__type"`
+		}
+		json.Unmarshal(body, &apiErr)
+		return nil, fmt.Errorf("kms returned HTTP %d %s", resp.StatusCode, apiErr.Type)
+	}
+
+	var result struct {
+		Plaintext string `json:"Plaintext"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid kms response: %w", err)
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(result.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kms response: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mask replaces redacted values
+const Mask = "[REDACTED]"
+
+// minRedactedLength keeps short values such as "1" from being masked everywhere
+const minRedactedLength = 4
+
+var redactions struct {
+	sync.RWMutex
+	values map[string]bool
+}
+
+// Patterns of credentials that are masked even when they are not configured:
+// authorization and API key headers, password=/token= style fields in query
+// strings, DSNs and JSON, and passwords in URLs
+var (
+	headerPattern   = regexp.MustCompile(`(?i)\b((?:proxy-)?authorization|x-api-key|cookie)(["']?\s*[:=]\s*["'\[]?)(?:(?:basic|bearer|digest|negotiate)\s+)?[^\s"',;&\]]+`)
+	fieldPattern    = regexp.MustCompile(`(?i)(\w*(?:password|passwd|pwd|secret|token|api[-_]?key))(=)[^\s"',;&]+`)
+	jsonPattern     = regexp.MustCompile(`(?i)("\w*(?:password|passwd|pwd|secret|token|api[-_]?key)"\s*:\s*")[^"]*`)
+	userinfoPattern = regexp.MustCompile(`(\b[a-zA-Z][\w+.-]*://[^/\s:@]+:)[^/\s@]+@`)
+)
+
+// sensitiveKeyPattern matches field and header names holding credentials
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(password|passwd|pwd|secret|token|api[-_]?key|authorization|cookie)$`)
+
+// AddRedaction marks a secret value to be masked by Redact
+func AddRedaction(value string) {
+	if len(value) < minRedactedLength {
+		return
+	}
+	redactions.Lock()
+	defer redactions.Unlock()
+	if redactions.values == nil {
+		redactions.values = make(map[string]bool)
+	}
+	redactions.values[value] = true
+}
+
+// SensitiveKey reports whether a field or header name holds credentials,
+// e.g. "password", "clientSecret" or "Authorization"
+func SensitiveKey(name string) bool {
+	return sensitiveKeyPattern.MatchString(name)
+}
+
+// Redact masks every resolved or configured secret value in s, as well as
+// credentials matching common patterns
+func Redact(s string) string {
+	redactions.RLock()
+	for value := range redactions.values {
+		if strings.Contains(s, value) {
+			s = strings.ReplaceAll(s, value, Mask)
+		}
+	}
+	redactions.RUnlock()
+
+	s = headerPattern.ReplaceAllString(s, "${1}${2}"+Mask)
+	s = fieldPattern.ReplaceAllString(s, "${1}${2}"+Mask)
+	s = jsonPattern.ReplaceAllString(s, "${1}"+Mask)
+	return userinfoPattern.ReplaceAllString(s, "${1}"+Mask+"@")
+}
+
+// RedactValue returns a copy of a decoded JSON value with strings redacted
+// and the values of sensitive keys masked
+func RedactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return Redact(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			if _, isString := elem.(string); isString && SensitiveKey(key) {
+				redacted[key] = Mask
+				continue
+			}
+			redacted[key] = RedactValue(elem)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, elem := range v {
+			redacted[i] = RedactValue(elem)
+		}
+		return redacted
+	}
+	return value
+}
@@ -0,0 +1,167 @@
+// Package secrets resolves config values that reference secrets in Vault, AWS
+// Secrets Manager or files. Resolved values are only held in memory and are
+// redacted from logs.
+package secrets
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reference schemes
+const (
+	SchemeVault = "vault:"
+	SchemeAWS   = "awssm:"
+	SchemeFile  = "file:"
+)
+
+// DefaultRefreshInterval is how often referenced secrets are checked for changes
+const DefaultRefreshInterval = 5 * time.Minute
+
+// Options configure the secret backends. Empty values fall back to the
+// usual environment variables: VAULT_ADDR, VAULT_TOKEN, VAULT_NAMESPACE
+// and AWS_REGION.
+type Options struct {
+	VaultAddress   string
+	VaultToken     string
+	VaultTokenFile string
+	VaultNamespace string
+	AWSRegion      string
+	AWSEndpoint    string // default: the regional Secrets Manager endpoint
+	KMSEndpoint    string // default: the KMS endpoint of the key's region
+	HTTPClient     *http.Client
+}
+
+// IsReference reports whether value references a secret. File references
+// need an absolute path, so file:// URLs are left alone.
+func IsReference(value string) bool {
+	switch {
+	case strings.HasPrefix(value, SchemeVault), strings.HasPrefix(value, SchemeAWS):
+		return true
+	case strings.HasPrefix(value, SchemeFile+"/"):
+		return !strings.HasPrefix(value, SchemeFile+"//")
+	}
+	return false
+}
+
+// Resolver fetches referenced secrets
+type Resolver struct {
+	opts Options
+}
+
+// NewResolver creates a resolver with the given backend options
+func NewResolver(opts Options) *Resolver {
+	if opts.VaultAddress == "" {
+		opts.VaultAddress = os.Getenv("VAULT_ADDR")
+	}
+	if opts.VaultToken == "" && opts.VaultTokenFile == "" {
+		opts.VaultToken = os.Getenv("VAULT_TOKEN")
+	}
+	if opts.VaultNamespace == "" {
+		opts.VaultNamespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if opts.AWSRegion == "" {
+		opts.AWSRegion = os.Getenv("AWS_REGION")
+	}
+	if opts.AWSRegion == "" {
+		opts.AWSRegion = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Resolver{opts: opts}
+}
+
+// Resolve returns the secret ref points to and marks it for redaction
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	var value string
+	var err error
+	switch {
+	case strings.HasPrefix(ref, SchemeVault):
+		value, err = r.fetchVault(ctx, strings.TrimPrefix(ref, SchemeVault))
+	case strings.HasPrefix(ref, SchemeAWS):
+		value, err = r.fetchAWS(ctx, strings.TrimPrefix(ref, SchemeAWS))
+	case strings.HasPrefix(ref, SchemeFile):
+		value, err = readFile(strings.TrimPrefix(ref, SchemeFile))
+	default:
+		return "", fmt.Errorf("unsupported secret reference %q", ref)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	AddRedaction(value)
+	return value, nil
+}
+
+// readFile reads a mounted secret, e.g. a Docker or Kubernetes secret file
+func readFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// splitKey splits "path#key" into the path and the optional key
+func splitKey(ref string) (string, string) {
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// Watch re-resolves refs every interval and calls onChange when any secret
+// changed, e.g. after a rotation. Failed fetches are logged and retried on
+// the next tick.
+func (r *Resolver) Watch(ctx context.Context, refs []string, interval time.Duration, onChange func()) {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	last, _ := r.fingerprint(ctx, refs)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sum, err := r.fingerprint(ctx, refs)
+		if err != nil {
+			slog.Warn("failed to refresh secrets", "error", err)
+			continue
+		}
+		if sum != last {
+			last = sum
+			onChange()
+		}
+	}
+}
+
+// fingerprint hashes the current values of refs, so they need not be kept
+func (r *Resolver) fingerprint(ctx context.Context, refs []string) ([sha256.Size]byte, error) {
+	sorted := append([]string(nil), refs...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, ref := range sorted {
+		value, err := r.Resolve(ctx, ref)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00", ref, value)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// fetchVault reads "mount/path#key" from a KV secrets engine. KV v2 is tried
+// first (mount/data/path), then KV v1. The key defaults to "value".
+func (r *Resolver) fetchVault(ctx context.Context, ref string) (string, error) {
+	if r.opts.VaultAddress == "" {
+		return "", fmt.Errorf("vault address is not configured (set VAULT_ADDR)")
+	}
+	token := r.opts.VaultToken
+	if r.opts.VaultTokenFile != "" {
+		data, err := ioutil.ReadFile(r.opts.VaultTokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read vault token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	path, key := splitKey(strings.Trim(ref, "/"))
+	if key == "" {
+		key = "value"
+	}
+
+	paths := []string{path}
+	if mount, rest, ok := strings.Cut(path, "/"); ok {
+		paths = []string{mount + "/data/" + rest, path}
+	}
+	for i, p := range paths {
+		data, status, err := r.vaultGet(ctx, token, p)
+		if err != nil {
+			return "", err
+		}
+		if status == http.StatusNotFound && i < len(paths)-1 {
+			continue
+		}
+		if status != http.StatusOK {
+			return "", fmt.Errorf("vault returned HTTP %d", status)
+		}
+
+		// KV v2 nests the secret under data.data
+		secret := data
+		if nested, ok := data["data"].(map[string]interface{}); ok && i == 0 && len(paths) > 1 {
+			secret = nested
+		}
+		value, ok := secret[key]
+		if !ok {
+			return "", fmt.Errorf("vault secret has no key %q", key)
+		}
+		return fmt.Sprint(value), nil
+	}
+	return "", fmt.Errorf("vault secret not found")
+}
+
+func (r *Resolver) vaultGet(ctx context.Context, token, path string) (map[string]interface{}, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(r.opts.VaultAddress, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if r.opts.VaultNamespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.opts.VaultNamespace)
+	}
+
+	resp, err := r.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, nil
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, 0, fmt.Errorf("invalid vault response: %w", err)
+	}
+	return body.Data, resp.StatusCode, nil
+}
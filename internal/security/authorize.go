@@ -0,0 +1,153 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Policy decides which authenticated callers may run a task from the scopes
+// and roles its mapping and the legacy actions it calls require
+type Policy struct {
+	DenyByDefault bool
+	LogDecisions  bool
+	Actions       []config.ActionRuleConfig
+}
+
+// NewPolicy creates the policy of cfg; it returns nil, which only checks the
+// requirements of mappings, when cfg is nil
+func NewPolicy(cfg *config.AuthorizationConfig) *Policy {
+	if cfg == nil {
+		return nil
+	}
+	return &Policy{DenyByDefault: cfg.DenyByDefault, LogDecisions: cfg.LogDecisions, Actions: cfg.Actions}
+}
+
+type policyKey struct{}
+
+// WithPolicy returns a context whose tasks are authorized by p
+func WithPolicy(ctx context.Context, p *Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, p)
+}
+
+// PolicyFromContext returns the policy carried by ctx, or nil
+func PolicyFromContext(ctx context.Context) *Policy {
+	p, _ := ctx.Value(policyKey{}).(*Policy)
+	return p
+}
+
+// Requirement is what a task asks of its caller: the scopes and roles of its
+// mapping and the legacy actions it calls
+type Requirement struct {
+	Mapping string
+	Scopes  []string
+	Roles   []string
+	Actions []string
+}
+
+// Decision is the outcome of authorizing a task
+type Decision struct {
+	Allowed bool
+	Subject string
+	Method  string
+	Mapping string
+	// Scopes are all required, and one role of each group
+	Scopes []string
+	Roles  [][]string
+	Reason string
+	// Log is set when allowed tasks are logged as well as denied ones
+	Log bool
+}
+
+// LogArgs returns the decision as slog attributes
+func (d Decision) LogArgs() []interface{} {
+	args := []interface{}{"subject", d.Subject, "authMethod", d.Method, "allowed", d.Allowed}
+	if len(d.Scopes) > 0 {
+		args = append(args, "requiredScopes", d.Scopes)
+	}
+	if len(d.Roles) > 0 {
+		groups := make([]string, len(d.Roles))
+		for i, group := range d.Roles {
+			groups[i] = strings.Join(group, "|")
+		}
+		args = append(args, "requiredRoles", groups)
+	}
+	if d.Reason != "" {
+		args = append(args, "reason", d.Reason)
+	}
+	return args
+}
+
+// AuthorizeTask decides whether the caller in ctx may run a task requiring
+// req under the policy of ctx. The caller needs every scope of the mapping,
+// one of its roles, and the same of each action rule naming one of the
+// actions. Tasks without a principal are allowed, since they only occur when
+// security is disabled or the connector runs them itself, e.g. on a schedule.
+func AuthorizeTask(ctx context.Context, req Requirement) Decision {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return Decision{Allowed: true, Mapping: req.Mapping}
+	}
+	policy := PolicyFromContext(ctx)
+
+	d := Decision{Subject: p.Subject, Method: p.Method, Mapping: req.Mapping}
+	d.Scopes = append(d.Scopes, req.Scopes...)
+	if len(req.Roles) > 0 {
+		d.Roles = append(d.Roles, req.Roles)
+	}
+	if policy != nil {
+		d.Log = policy.LogDecisions
+		for _, rule := range policy.Actions {
+			if !matchesAction(rule.Actions, req.Actions) {
+				continue
+			}
+			d.Scopes = append(d.Scopes, rule.Scopes...)
+			if len(rule.Roles) > 0 {
+				d.Roles = append(d.Roles, rule.Roles)
+			}
+		}
+	}
+
+	switch {
+	case len(d.Scopes) == 0 && len(d.Roles) == 0 && policy != nil && policy.DenyByDefault:
+		d.Reason = fmt.Sprintf("mapping %s requires no scopes or roles and authorization denies by default", req.Mapping)
+	case !p.HasScopes(d.Scopes):
+		d.Reason = fmt.Sprintf("%s lacks required scopes %s", p.Subject, strings.Join(missingScopes(p, d.Scopes), " "))
+	default:
+		d.Allowed = true
+		for _, group := range d.Roles {
+			if !p.HasAnyRole(group) {
+				d.Allowed = false
+				d.Reason = fmt.Sprintf("%s lacks one of roles %s", p.Subject, strings.Join(group, ", "))
+				break
+			}
+		}
+	}
+	return d
+}
+
+// matchesAction reports whether one of actions is named by the rule, ignoring
+// case
+func matchesAction(rule, actions []string) bool {
+	for _, want := range rule {
+		for _, action := range actions {
+			if strings.EqualFold(want, action) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// missingScopes returns the required scopes p was not granted
+func missingScopes(p *Principal, required []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if !p.HasScopes([]string{scope}) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
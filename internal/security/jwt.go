@@ -0,0 +1,352 @@
+package security
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// JWKS refresh limits: keys are refetched periodically for rotation, and at
+// most once per jwksMinRefresh when a token names an unknown key
+const (
+	jwksMaxAge     = time.Hour
+	jwksMinRefresh = 30 * time.Second
+)
+
+// clockLeeway tolerates clock differences when checking exp and nbf
+const clockLeeway = time.Minute
+
+// JWTVerifier validates RS256 and ES256 bearer tokens against a JWKS
+type JWTVerifier struct {
+	Issuer     string
+	Audience   string
+	JWKSURL    string
+	ScopeClaim string
+	RoleClaim  string
+	HTTPClient *http.Client
+
+	// Now can be replaced in tests
+	Now func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// NewJWTVerifier creates a verifier from config
+func NewJWTVerifier(cfg config.JWTConfig) *JWTVerifier {
+	return &JWTVerifier{
+		Issuer:     cfg.Issuer,
+		Audience:   cfg.Audience,
+		JWKSURL:    cfg.JWKSURL,
+		ScopeClaim: cfg.ScopeClaim,
+		RoleClaim:  cfg.RoleClaim,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		Now:        time.Now,
+	}
+}
+
+// Verify checks the signature and claims of token and returns its principal
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	claims, err := v.VerifyClaims(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	subject, _ := claims["sub"].(string)
+	return &Principal{Subject: subject, Method: MethodJWT, Scopes: v.scopes(claims), Roles: v.roles(claims)}, nil
+}
+
+// VerifyClaims checks the signature, issuer, audience and validity period of
+// token and returns its claims
+func (v *JWTVerifier) VerifyClaims(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed JWT")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed JWT header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT signature: %w", err)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(header.Alg, key, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed JWT claims: %w", err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// checkClaims validates issuer, audience and validity period
+func (v *JWTVerifier) checkClaims(claims map[string]interface{}) error {
+	if iss, _ := claims["iss"].(string); iss != v.Issuer {
+		return fmt.Errorf("unexpected JWT issuer %q", iss)
+	}
+	if v.Audience != "" && !hasAudience(claims["aud"], v.Audience) {
+		return fmt.Errorf("JWT is not issued for audience %q", v.Audience)
+	}
+
+	now := v.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("JWT has no expiry")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockLeeway)) {
+		return fmt.Errorf("JWT expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("JWT not valid yet")
+	}
+	return nil
+}
+
+// scopes reads the configured scope claim, accepting space-separated strings and arrays
+func (v *JWTVerifier) scopes(claims map[string]interface{}) []string {
+	names := []string{"scope", "scp"}
+	if v.ScopeClaim != "" {
+		names = []string{v.ScopeClaim}
+	}
+	for _, name := range names {
+		switch value := claims[name].(type) {
+		case string:
+			return strings.Fields(value)
+		case []interface{}:
+			var scopes []string
+			for _, item := range value {
+				if s, ok := item.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+			return scopes
+		}
+	}
+	return nil
+}
+
+// DefaultRoleClaim is the claim roles are read from
+const DefaultRoleClaim = "roles"
+
+// roles reads the configured role claim, accepting space-separated strings and
+// arrays. Claims not found by name are looked up as dotted paths into nested
+// claims, such as realm_access.roles.
+func (v *JWTVerifier) roles(claims map[string]interface{}) []string {
+	name := v.RoleClaim
+	if name == "" {
+		name = DefaultRoleClaim
+	}
+	value, ok := claims[name]
+	if !ok {
+		value = claims
+		for _, key := range strings.Split(name, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = object[key]
+		}
+	}
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var roles []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+	return nil
+}
+
+func hasAudience(aud interface{}, want string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == want
+	case []interface{}:
+		for _, item := range value {
+			if item == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifySignature checks an RS256 or ES256 signature over digest
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) error {
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("JWT key is not an RSA key")
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature); err != nil {
+			return fmt.Errorf("invalid JWT signature")
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return fmt.Errorf("JWT key is not a P-256 key")
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("invalid JWT signature")
+		}
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	return nil
+}
+
+// key returns the public key for kid, fetching the JWKS when it is stale or
+// does not know the key yet
+func (v *JWTVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.Now()
+	key, ok := v.keys[kid]
+	stale := now.Sub(v.fetched) > jwksMaxAge
+	if ok && !stale {
+		return key, nil
+	}
+	if !stale && now.Sub(v.fetched) < jwksMinRefresh {
+		return nil, fmt.Errorf("unknown JWT key %q", kid)
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		if ok {
+			// Keep using the cached key while the JWKS endpoint is unavailable
+			return key, nil
+		}
+		return nil, err
+	}
+	v.keys = keys
+	v.fetched = now
+
+	if key, ok = keys[kid]; !ok {
+		return nil, fmt.Errorf("unknown JWT key %q", kid)
+	}
+	return key, nil
+}
+
+// jwk is an RSA or EC public key of a JWKS
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (v *JWTVerifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	decode := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,287 @@
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/crewjam/saml"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// SAML routes on the admin plane
+const (
+	SAMLMetadataPath = "/admin/saml/metadata"
+	SAMLACSPath      = "/admin/saml/acs"
+)
+
+// maxSAMLMetadata limits the identity provider metadata read
+const maxSAMLMetadata = 1 << 20
+
+// SAML signs admin users in with SAML 2.0 as a service provider: it redirects
+// them to the identity provider with an authentication request and accepts
+// the signed assertion posted back in response to it
+type SAML struct {
+	IDPMetadataURL  string
+	GroupsAttribute string
+
+	sp saml.ServiceProvider
+
+	mu  sync.Mutex
+	idp *saml.EntityDescriptor
+}
+
+// newSAML creates the service provider of cfg; the identity provider
+// metadata is fetched on first use
+func newSAML(cfg *config.SAMLConfig) (*SAML, error) {
+	acs, err := url.Parse(cfg.ACSURL)
+	if err != nil || !strings.HasSuffix(acs.Path, SAMLACSPath) {
+		return nil, fmt.Errorf("acsUrl must be the URL of %s", SAMLACSPath)
+	}
+	metadata := *acs
+	metadata.Path = strings.TrimSuffix(acs.Path, SAMLACSPath) + SAMLMetadataPath
+
+	s := &SAML{
+		IDPMetadataURL:  cfg.IDPMetadataURL,
+		GroupsAttribute: cfg.GroupsAttribute,
+		sp: saml.ServiceProvider{
+			EntityID:    cfg.EntityID,
+			MetadataURL: metadata,
+			AcsURL:      *acs,
+		},
+	}
+	if s.GroupsAttribute == "" {
+		s.GroupsAttribute = "groups"
+	}
+	if cfg.Certificate != "" || cfg.PrivateKey != "" {
+		if s.sp.Certificate, err = parseCertificate(cfg.Certificate); err != nil {
+			return nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+		if s.sp.Key, err = parseRSAKey(cfg.PrivateKey); err != nil {
+			return nil, fmt.Errorf("invalid privateKey: %w", err)
+		}
+		s.sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+	}
+	return s, nil
+}
+
+// Metadata returns the service provider metadata to register at the
+// identity provider. Assertions are only accepted with the HTTP-POST binding.
+func (s *SAML) Metadata() *saml.EntityDescriptor {
+	metadata := s.sp.Metadata()
+	for i := range metadata.SPSSODescriptors {
+		descriptor := &metadata.SPSSODescriptors[i]
+		var services []saml.IndexedEndpoint
+		for _, service := range descriptor.AssertionConsumerServices {
+			if service.Binding == saml.HTTPPostBinding {
+				services = append(services, service)
+			}
+		}
+		descriptor.AssertionConsumerServices = services
+	}
+	return metadata
+}
+
+// serviceProvider returns the service provider with the identity provider
+// metadata, fetched once
+func (s *SAML) serviceProvider(ctx context.Context, client *http.Client) (*saml.ServiceProvider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idp == nil {
+		idp, err := fetchIDPMetadata(ctx, client, s.IDPMetadataURL)
+		if err != nil {
+			return nil, err
+		}
+		s.idp = idp
+	}
+	sp := s.sp
+	sp.IDPMetadata = s.idp
+	return &sp, nil
+}
+
+// fetchIDPMetadata fetches the metadata of the identity provider, the first
+// one of an aggregate
+func fetchIDPMetadata(ctx context.Context, client *http.Client, metadataURL string) (*saml.EntityDescriptor, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SAML metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch SAML metadata: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSAMLMetadata))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SAML metadata: %w", err)
+	}
+
+	var entity saml.EntityDescriptor
+	if err := xml.Unmarshal(data, &entity); err != nil {
+		var entities saml.EntitiesDescriptor
+		if xml.Unmarshal(data, &entities) != nil || len(entities.EntityDescriptors) == 0 {
+			return nil, fmt.Errorf("failed to decode SAML metadata: %w", err)
+		}
+		entity = entities.EntityDescriptors[0]
+	}
+	if len(entity.IDPSSODescriptors) == 0 {
+		return nil, fmt.Errorf("SAML metadata describes no identity provider")
+	}
+	return &entity, nil
+}
+
+// samlLogin redirects to the identity provider with an authentication
+// request, whose ID the response must answer
+func (s *SSO) samlLogin(w http.ResponseWriter, r *http.Request, returnTo string) {
+	sp, err := s.SAML.serviceProvider(r.Context(), s.HTTPClient)
+	if err != nil {
+		http.Error(w, "identity provider unavailable: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	location := sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+	if location == "" {
+		http.Error(w, "identity provider has no HTTP-Redirect sign-in endpoint", http.StatusBadGateway)
+		return
+	}
+	req, err := sp.MakeAuthenticationRequest(location, saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		http.Error(w, "sign-in failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	state := loginState{
+		State:   randomString(16),
+		Nonce:   req.ID,
+		Return:  returnTo,
+		Expires: s.Now().Add(loginTTL).Unix(),
+	}
+	redirect, err := req.Redirect(state.State, sp)
+	if err != nil {
+		http.Error(w, "sign-in failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.setCookie(w, loginCookie, s.seal(loginCookie, state), loginTTL)
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// samlACS completes sign-in: it checks the response the identity provider
+// posted, its signature and that it answers the request of this browser,
+// and starts a session
+func (s *SSO) samlACS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid SAML response", http.StatusBadRequest)
+		return
+	}
+
+	var state loginState
+	cookie, err := r.Cookie(loginCookie)
+	if err != nil || s.open(loginCookie, cookie.Value, &state) != nil || s.Now().Unix() >= state.Expires {
+		http.Error(w, "sign-in expired, please try again", http.StatusBadRequest)
+		return
+	}
+	if !hmac.Equal([]byte(r.PostForm.Get("RelayState")), []byte(state.State)) {
+		http.Error(w, "sign-in state mismatch", http.StatusBadRequest)
+		return
+	}
+	s.clearCookie(w, loginCookie)
+
+	sp, err := s.SAML.serviceProvider(r.Context(), s.HTTPClient)
+	if err != nil {
+		http.Error(w, "identity provider unavailable: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	response, err := base64.StdEncoding.DecodeString(r.PostForm.Get("SAMLResponse"))
+	if err != nil {
+		http.Error(w, "sign-in failed: invalid SAML response", http.StatusBadRequest)
+		return
+	}
+	assertion, err := sp.ParseXMLResponse(response, []string{state.Nonce})
+	if err != nil {
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) && invalid.PrivateErr != nil {
+			err = invalid.PrivateErr
+		}
+		http.Error(w, "sign-in failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var subject string
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		subject = assertion.Subject.NameID.Value
+	}
+	s.startSession(w, r, subject, s.rolesOf(s.SAML.groups(assertion)), state.Return)
+}
+
+// samlMetadata serves the service provider metadata
+func (s *SSO) samlMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata, err := xml.MarshalIndent(s.SAML.Metadata(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.Write(metadata)
+}
+
+// groups returns the values of the groups attribute of an assertion
+func (s *SAML) groups(assertion *saml.Assertion) []string {
+	var groups []string
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			if attribute.Name != s.GroupsAttribute && attribute.FriendlyName != s.GroupsAttribute {
+				continue
+			}
+			for _, value := range attribute.Values {
+				groups = append(groups, value.Value)
+			}
+		}
+	}
+	return groups
+}
+
+func parseCertificate(data string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parseRSAKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA key")
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,297 @@
+// Package security authenticates inbound task requests and checks the scopes
+// and roles mappings require
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Headers of authenticated requests
+const (
+	// DefaultAPIKeyHeader carries a static API key
+	DefaultAPIKeyHeader = "X-API-Key"
+	// KeyIDHeader names the HMAC key a request is signed with
+	KeyIDHeader = "X-A2A-Key-Id"
+	// TimestampHeader carries the Unix time the request was signed at
+	TimestampHeader = "X-A2A-Timestamp"
+	// RequestSignatureHeader carries the hex HMAC-SHA256 request signature
+	RequestSignatureHeader = "X-A2A-Request-Signature"
+	// WebhookSignatureHeader carries the hex HMAC-SHA256 of a webhook body
+	WebhookSignatureHeader = "X-Signature"
+)
+
+// DefaultMaxClockSkew limits how old a signed request may be
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// Authentication methods reported in a Principal
+const (
+	MethodAPIKey = "apikey"
+	MethodHMAC   = "hmac"
+	MethodJWT    = "jwt"
+)
+
+// ErrUnauthenticated is returned when a request carries no credentials
+var ErrUnauthenticated = errors.New("authentication required")
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string
+	Method  string
+	Scopes  []string
+	Roles   []string
+}
+
+// HasScopes reports whether the principal was granted every required scope
+func (p *Principal) HasScopes(required []string) bool {
+	granted := make(map[string]bool, len(p.Scopes))
+	for _, scope := range p.Scopes {
+		granted[scope] = true
+	}
+	for _, scope := range required {
+		if !granted[scope] {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAnyRole reports whether the principal holds one of roles
+func (p *Principal) HasAnyRole(roles []string) bool {
+	for _, held := range p.Roles {
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated caller carried by ctx
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// Authenticator checks the API key, HMAC signature or JWT of inbound requests
+type Authenticator struct {
+	APIKeyHeader string
+	APIKeys      []config.APIKeyConfig
+	HMACKeys     map[string]config.HMACKeyConfig
+	MaxClockSkew time.Duration
+	JWT          *JWTVerifier
+	Policy       *Policy
+
+	// Now can be replaced in tests
+	Now func() time.Time
+
+	signatures usedSignatures
+}
+
+// New creates an authenticator from config; it returns nil (disabled) when
+// no authentication method is configured
+func New(cfg config.SecurityConfig) (*Authenticator, error) {
+	if len(cfg.APIKeys) == 0 && len(cfg.HMACKeys) == 0 && cfg.JWT == nil {
+		return nil, nil
+	}
+
+	a := &Authenticator{
+		APIKeyHeader: cfg.APIKeyHeader,
+		APIKeys:      cfg.APIKeys,
+		HMACKeys:     make(map[string]config.HMACKeyConfig),
+		MaxClockSkew: DefaultMaxClockSkew,
+		Policy:       NewPolicy(cfg.Authorization),
+		Now:          time.Now,
+	}
+	if a.APIKeyHeader == "" {
+		a.APIKeyHeader = DefaultAPIKeyHeader
+	}
+	for _, key := range cfg.HMACKeys {
+		a.HMACKeys[key.KeyID] = key
+	}
+	if cfg.MaxClockSkew != "" {
+		d, err := time.ParseDuration(cfg.MaxClockSkew)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxClockSkew: %w", err)
+		}
+		a.MaxClockSkew = d
+	}
+	if cfg.JWT != nil {
+		a.JWT = NewJWTVerifier(*cfg.JWT)
+	}
+	return a, nil
+}
+
+// Authenticate identifies the caller of r; body is the request body, needed
+// to check HMAC signatures
+func (a *Authenticator) Authenticate(r *http.Request, body []byte) (*Principal, error) {
+	if header := r.Header.Get("Authorization"); a.JWT != nil && strings.HasPrefix(header, "Bearer ") {
+		return a.JWT.Verify(r.Context(), strings.TrimPrefix(header, "Bearer "))
+	}
+	if keyID := r.Header.Get(KeyIDHeader); keyID != "" && len(a.HMACKeys) > 0 {
+		return a.verifySignature(r, keyID, body)
+	}
+	if key := r.Header.Get(a.APIKeyHeader); key != "" && len(a.APIKeys) > 0 {
+		for _, candidate := range a.APIKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(candidate.Key)) == 1 {
+				return &Principal{Subject: candidate.Name, Method: MethodAPIKey, Scopes: candidate.Scopes, Roles: candidate.Roles}, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid API key")
+	}
+	return nil, ErrUnauthenticated
+}
+
+// verifySignature checks the HMAC signature and timestamp of a request
+func (a *Authenticator) verifySignature(r *http.Request, keyID string, body []byte) (*Principal, error) {
+	key, ok := a.HMACKeys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+
+	timestamp := r.Header.Get(TimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header", TimestampHeader)
+	}
+	skew := a.Now().Sub(time.Unix(seconds, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > a.MaxClockSkew {
+		return nil, fmt.Errorf("request signature expired")
+	}
+
+	expected := Sign(key.Secret, timestamp, r.Method, r.URL.RequestURI(), body)
+	if !hmac.Equal([]byte(r.Header.Get(RequestSignatureHeader)), []byte(expected)) {
+		return nil, fmt.Errorf("invalid request signature")
+	}
+	if !a.signatures.add(keyID+":"+expected, time.Unix(seconds, 0).Add(a.MaxClockSkew), a.Now()) {
+		return nil, fmt.Errorf("request signature already used")
+	}
+	return &Principal{Subject: keyID, Method: MethodHMAC, Scopes: key.Scopes, Roles: key.Roles}, nil
+}
+
+// usedSignatures holds the request signatures accepted until their timestamp
+// expires, so a captured request cannot be replayed
+type usedSignatures struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// add records a signature expiring at expires; it returns false when the
+// signature was already used
+func (u *usedSignatures) add(signature string, expires, now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.seen == nil {
+		u.seen = make(map[string]time.Time)
+	}
+	for seen, at := range u.seen {
+		if now.After(at) {
+			delete(u.seen, seen)
+		}
+	}
+	if _, ok := u.seen[signature]; ok {
+		return false
+	}
+	u.seen[signature] = expires
+	return true
+}
+
+// Sign returns the hex HMAC-SHA256 signature of a request: the timestamp,
+// method, request URI and body joined by newlines
+func Sign(secret, timestamp, method, requestURI string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + requestURI + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyBodySignature checks the hex HMAC-SHA256 signature of a body, as
+// legacy systems sign their webhooks; a sha256= prefix is accepted
+func VerifyBodySignature(secret, signature string, body []byte) error {
+	if signature == "" {
+		return ErrUnauthenticated
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	signature = strings.ToLower(strings.TrimPrefix(signature, "sha256="))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("invalid body signature")
+	}
+	return nil
+}
+
+// VerifyTimestampedBodySignature checks the hex HMAC-SHA256 signature of a
+// Unix timestamp and a body joined by a dot, as webhooks sign them so they
+// cannot be replayed later, and that the timestamp is within maxSkew of now
+func VerifyTimestampedBodySignature(secret, timestamp, signature string, body []byte, maxSkew time.Duration, now time.Time) error {
+	if signature == "" {
+		return ErrUnauthenticated
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp %q", timestamp)
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxSkew {
+		return fmt.Errorf("body signature expired")
+	}
+	return VerifyBodySignature(secret, signature, append([]byte(timestamp+"."), body...))
+}
+
+// Middleware rejects unauthenticated requests with 401 and passes the
+// principal and the authorization policy to next in the request context. A nil
+// authenticator returns next unchanged.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		principal, err := a.Authenticate(r, body)
+		if err != nil {
+			if a.JWT != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="a2a-connector"`)
+			}
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		ctx := WithPolicy(WithPrincipal(r.Context(), principal), a.Policy)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,269 @@
+package security_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/security"
+)
+
+// protected serves the authenticator's middleware around a handler echoing the principal
+func protected(t *testing.T, cfg config.SecurityConfig) http.Handler {
+	auth, err := security.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	return auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := security.PrincipalFromContext(r.Context())
+		if p == nil {
+			w.Write([]byte("anonymous"))
+			return
+		}
+		w.Write([]byte(p.Method + ":" + p.Subject + ":" + strings.Join(p.Scopes, ",")))
+	}))
+}
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDisabledSecurity(t *testing.T) {
+	rec := serve(protected(t, config.SecurityConfig{}), httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "anonymous" {
+		t.Errorf("Expected requests to pass without security, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAPIKeyAndHMAC(t *testing.T) {
+	h := protected(t, config.SecurityConfig{
+		APIKeys:  []config.APIKeyConfig{{Name: "billing", Key: "s3cret", Scopes: []string{"orders:read"}}},
+		HMACKeys: []config.HMACKeyConfig{{KeyID: "erp", Secret: "signing-secret", Scopes: []string{"orders:write"}}},
+	})
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without credentials, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(security.DefaultAPIKeyHeader, "wrong")
+	if rec := serve(h, req); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong key, got %d", rec.Code)
+	}
+	req.Header.Set(security.DefaultAPIKeyHeader, "s3cret")
+	if rec := serve(h, req); rec.Body.String() != "apikey:billing:orders:read" {
+		t.Errorf("Unexpected principal %q", rec.Body.String())
+	}
+
+	signed := func(ts time.Time, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		timestamp := strconv.FormatInt(ts.Unix(), 10)
+		req.Header.Set(security.KeyIDHeader, "erp")
+		req.Header.Set(security.TimestampHeader, timestamp)
+		req.Header.Set(security.RequestSignatureHeader, security.Sign("signing-secret", timestamp, http.MethodPost, "/", []byte(body)))
+		return req
+	}
+	first := signed(time.Now(), `{"id":1}`)
+	replayed := first.Clone(context.Background())
+	replayed.Body = io.NopCloser(strings.NewReader(`{"id":1}`))
+	if rec := serve(h, first); rec.Body.String() != "hmac:erp:orders:write" {
+		t.Errorf("Unexpected principal %q", rec.Body.String())
+	}
+	if rec := serve(h, replayed); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "already used") {
+		t.Errorf("Expected 401 for a replayed request, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(h, signed(time.Now().Add(-time.Hour), `{"id":1}`)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an expired signature, got %d", rec.Code)
+	}
+	tampered := signed(time.Now(), `{"id":1}`)
+	tampered.Body = http.NoBody
+	if rec := serve(h, tampered); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a tampered body, got %d", rec.Code)
+	}
+}
+
+func TestVerifyBodySignature(t *testing.T) {
+	body := []byte(`{"event":"order.shipped"}`)
+	mac := hmac.New(sha256.New, []byte("webhook-secret"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	for _, sig := range []string{signature, "sha256=" + signature, strings.ToUpper(signature)} {
+		if err := security.VerifyBodySignature("webhook-secret", sig, body); err != nil {
+			t.Errorf("Expected %q to verify, got %v", sig, err)
+		}
+	}
+	if err := security.VerifyBodySignature("other-secret", signature, body); err == nil {
+		t.Error("Expected a signature with another secret to fail")
+	}
+	if err := security.VerifyBodySignature("webhook-secret", "", body); err != security.ErrUnauthenticated {
+		t.Errorf("Expected a missing signature to be unauthenticated, got %v", err)
+	}
+}
+
+func TestJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1",
+			"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	token := func(kid string, claims map[string]interface{}) string {
+		header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+		payload, _ := json.Marshal(claims)
+		signingInput := b64(header) + "." + b64(payload)
+		digest := sha256.Sum256([]byte(signingInput))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signingInput + "." + b64(sig)
+	}
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": "https://idp.example.com", "aud": []string{"a2a-connector"}, "sub": "agent-7",
+			"exp": time.Now().Add(time.Hour).Unix(), "scope": "orders:read orders:write",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	h := protected(t, config.SecurityConfig{JWT: &config.JWTConfig{
+		Issuer: "https://idp.example.com", Audience: "a2a-connector", JWKSURL: jwks.URL,
+	}})
+	call := func(tok string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		return serve(h, req)
+	}
+
+	if rec := call(token("k1", claims(nil))); rec.Body.String() != "jwt:agent-7:orders:read,orders:write" {
+		t.Errorf("Unexpected principal %d %q", rec.Code, rec.Body.String())
+	}
+	for name, tok := range map[string]string{
+		"issuer":   token("k1", claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+		"audience": token("k1", claims(map[string]interface{}{"aud": "other"})),
+		"expired":  token("k1", claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"kid":      token("k2", claims(nil)),
+		"tampered": token("k1", claims(nil))[:40] + "x" + token("k1", claims(nil))[41:],
+	} {
+		rec := call(tok)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for bad %s, got %d", name, rec.Code)
+		}
+		if rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("Expected WWW-Authenticate challenge for bad %s", name)
+		}
+	}
+}
+
+func TestAuthorizeTask(t *testing.T) {
+	policy := security.NewPolicy(&config.AuthorizationConfig{
+		DenyByDefault: true,
+		Actions: []config.ActionRuleConfig{
+			{Actions: []string{"POST", "updateEntity"}, Scopes: []string{"orders:write"}},
+			{Actions: []string{"delete"}, Roles: []string{"admin"}},
+		},
+	})
+	reader := security.WithPolicy(security.WithPrincipal(context.Background(), &security.Principal{
+		Subject: "reporting", Scopes: []string{"orders:read"}, Roles: []string{"viewer"},
+	}), policy)
+	writer := security.WithPolicy(security.WithPrincipal(context.Background(), &security.Principal{
+		Subject: "erp", Scopes: []string{"orders:read", "orders:write"}, Roles: []string{"clerk"},
+	}), policy)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     security.Requirement
+		allowed bool
+	}{
+		{"read scope", reader, security.Requirement{Mapping: "show order", Scopes: []string{"orders:read"}, Actions: []string{"GET"}}, true},
+		{"write action", reader, security.Requirement{Mapping: "update order", Actions: []string{"updateentity"}}, false},
+		{"write action granted", writer, security.Requirement{Mapping: "update order", Actions: []string{"updateEntity"}}, true},
+		{"step action", writer, security.Requirement{Mapping: "cancel order", Actions: []string{"GET", "DELETE"}}, false},
+		{"mapping role", reader, security.Requirement{Mapping: "report", Roles: []string{"viewer", "auditor"}}, true},
+		{"missing role", writer, security.Requirement{Mapping: "report", Roles: []string{"viewer", "auditor"}}, false},
+		{"deny by default", writer, security.Requirement{Mapping: "unguarded", Actions: []string{"GET"}}, false},
+		{"no principal", context.Background(), security.Requirement{Mapping: "update order", Actions: []string{"POST"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := security.AuthorizeTask(tt.ctx, tt.req)
+			if d.Allowed != tt.allowed {
+				t.Errorf("Expected allowed %v, got %v (%s)", tt.allowed, d.Allowed, d.Reason)
+			}
+			if !d.Allowed && d.Reason == "" {
+				t.Error("Expected a reason for the denial")
+			}
+		})
+	}
+
+	open := security.WithPrincipal(context.Background(), &security.Principal{Subject: "erp"})
+	if d := security.AuthorizeTask(open, security.Requirement{Mapping: "unguarded"}); !d.Allowed {
+		t.Errorf("Expected unguarded mappings to pass without deny-by-default, got %s", d.Reason)
+	}
+}
+
+func TestJWTRoles(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1",
+			"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1"})
+	payload, _ := json.Marshal(map[string]interface{}{
+		"iss": "https://idp.example.com", "sub": "agent-7", "exp": time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]interface{}{"roles": []string{"clerk", "viewer"}},
+	})
+	signingInput := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := security.NewJWTVerifier(config.JWTConfig{Issuer: "https://idp.example.com", JWKSURL: jwks.URL, RoleClaim: "realm_access.roles"})
+	p, err := verifier.Verify(context.Background(), signingInput+"."+b64(sig))
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+	if strings.Join(p.Roles, ",") != "clerk,viewer" {
+		t.Errorf("Expected roles from the nested claim, got %v", p.Roles)
+	}
+}
@@ -0,0 +1,536 @@
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Admin roles granted by single sign-on: admins have full access, viewers
+// may only read
+const (
+	RoleAdmin  = "admin"
+	RoleViewer = "viewer"
+)
+
+// MethodSSO is the authentication method of single sign-on principals
+const MethodSSO = "sso"
+
+// Single sign-on routes on the admin plane
+const (
+	SSOLoginPath    = "/admin/login"
+	SSOCallbackPath = "/admin/callback"
+	SSOLogoutPath   = "/admin/logout"
+)
+
+const (
+	sessionCookie = "a2a_admin_session"
+	loginCookie   = "a2a_admin_login"
+
+	// DefaultSessionTTL is how long an admin session lasts by default
+	DefaultSessionTTL = 8 * time.Hour
+	// loginTTL limits how long a user may take to sign in at the provider
+	loginTTL = 10 * time.Minute
+)
+
+// SSO signs admin users in with the OpenID Connect authorization code flow
+// (with PKCE), or with SAML when SAML is set, and keeps them signed in with a
+// signed session cookie. With OpenID Connect, API clients can instead send an
+// ID token from the provider as a bearer token. Provider groups map to roles.
+type SSO struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	GroupRoles   map[string]string
+	SAML         *SAML
+	SessionTTL   time.Duration
+	HTTPClient   *http.Client
+
+	// Now can be replaced in tests
+	Now func() time.Time
+
+	secret []byte
+
+	mu       sync.Mutex
+	provider *oidcProvider
+	verifier *JWTVerifier
+}
+
+// oidcProvider is the part of the provider's discovery document the flow uses
+type oidcProvider struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// session is the content of the session cookie
+type session struct {
+	Subject string   `json:"sub"`
+	Roles   []string `json:"roles"`
+	Expires int64    `json:"exp"`
+}
+
+// loginState is the content of the cookie kept during sign-in
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Return   string `json:"return"`
+	Expires  int64  `json:"exp"`
+}
+
+// NewSSO creates single sign-on from config; it returns nil (disabled) when
+// cfg is nil
+func NewSSO(cfg *config.SSOConfig) (*SSO, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	s := &SSO{
+		Issuer:       strings.TrimSuffix(cfg.Issuer, "/"),
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+		GroupsClaim:  cfg.GroupsClaim,
+		GroupRoles:   cfg.GroupRoles,
+		SessionTTL:   DefaultSessionTTL,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+		Now:          time.Now,
+		secret:       []byte(cfg.SessionSecret),
+	}
+	if len(s.Scopes) == 0 {
+		s.Scopes = []string{"openid", "profile", "email", "groups"}
+	}
+	if s.GroupsClaim == "" {
+		s.GroupsClaim = "groups"
+	}
+	if cfg.SessionTTL != "" {
+		d, err := time.ParseDuration(cfg.SessionTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sessionTtl: %w", err)
+		}
+		s.SessionTTL = d
+	}
+	if len(s.secret) == 0 {
+		// Sessions then last until the process restarts
+		s.secret = []byte(randomString(32))
+	}
+	if cfg.SAML != nil {
+		saml, err := newSAML(cfg.SAML)
+		if err != nil {
+			return nil, fmt.Errorf("invalid saml: %w", err)
+		}
+		s.SAML = saml
+	}
+	return s, nil
+}
+
+// Middleware serves the sign-in routes and requires a session or an ID token
+// on every other request. Browsers without a session are sent to sign in;
+// API clients get 401. GET and HEAD require the viewer role, other methods
+// the admin role. A nil SSO returns next unchanged.
+func (s *SSO) Middleware(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case SSOLoginPath:
+			s.login(w, r)
+			return
+		case SSOCallbackPath:
+			s.callback(w, r)
+			return
+		case SSOLogoutPath:
+			s.logout(w, r)
+			return
+		case SAMLMetadataPath, SAMLACSPath:
+			if s.SAML == nil {
+				break
+			}
+			if r.URL.Path == SAMLMetadataPath {
+				s.samlMetadata(w, r)
+			} else {
+				s.samlACS(w, r)
+			}
+			return
+		}
+
+		principal, err := s.Authenticate(r)
+		if err != nil {
+			if errors.Is(err, ErrUnauthenticated) && strings.Contains(r.Header.Get("Accept"), "text/html") {
+				http.Redirect(w, r, SSOLoginPath+"?return="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="a2a-connector-admin"`)
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		role := RoleAdmin
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			role = RoleViewer
+		}
+		if !principal.HasScopes([]string{role}) {
+			http.Error(w, "forbidden: the "+role+" role is required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// Authenticate identifies the admin user of r from the session cookie or a
+// bearer ID token
+func (s *SSO) Authenticate(r *http.Request) (*Principal, error) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		if s.SAML != nil {
+			return nil, fmt.Errorf("bearer tokens require OpenID Connect sign-in")
+		}
+		_, verifier, err := s.discover(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		claims, err := verifier.VerifyClaims(r.Context(), strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			return nil, err
+		}
+		roles := s.roles(claims)
+		if len(roles) == 0 {
+			return nil, fmt.Errorf("%s has no admin role", subject(claims))
+		}
+		return &Principal{Subject: subject(claims), Method: MethodSSO, Scopes: roles}, nil
+	}
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	var sess session
+	if err := s.open(sessionCookie, cookie.Value, &sess); err != nil {
+		return nil, fmt.Errorf("invalid session")
+	}
+	if s.Now().Unix() >= sess.Expires {
+		return nil, ErrUnauthenticated
+	}
+	if len(sess.Roles) == 0 {
+		return nil, fmt.Errorf("%s has no admin role", sess.Subject)
+	}
+	return &Principal{Subject: sess.Subject, Method: MethodSSO, Scopes: sess.Roles}, nil
+}
+
+// login redirects to the provider's authorization endpoint
+func (s *SSO) login(w http.ResponseWriter, r *http.Request) {
+	// Only return to admin pages, so the login cannot redirect elsewhere
+	returnTo := r.URL.Query().Get("return")
+	if !strings.HasPrefix(returnTo, "/admin/") || strings.HasPrefix(returnTo, "//") {
+		returnTo = "/admin/whoami"
+	}
+	if s.SAML != nil {
+		s.samlLogin(w, r, returnTo)
+		return
+	}
+
+	provider, _, err := s.discover(r.Context())
+	if err != nil {
+		http.Error(w, "identity provider unavailable: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	state := loginState{
+		State:    randomString(16),
+		Nonce:    randomString(16),
+		Verifier: randomString(32),
+		Return:   returnTo,
+		Expires:  s.Now().Add(loginTTL).Unix(),
+	}
+	s.setCookie(w, loginCookie, s.seal(loginCookie, state), loginTTL)
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.ClientID},
+		"redirect_uri":          {s.RedirectURL},
+		"scope":                 {strings.Join(s.Scopes, " ")},
+		"state":                 {state.State},
+		"nonce":                 {state.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	http.Redirect(w, r, provider.AuthorizationEndpoint+separator+query.Encode(), http.StatusFound)
+}
+
+// callback completes sign-in: it exchanges the code for an ID token, checks
+// it and starts a session
+func (s *SSO) callback(w http.ResponseWriter, r *http.Request) {
+	if s.SAML != nil {
+		http.NotFound(w, r)
+		return
+	}
+	query := r.URL.Query()
+	if e := query.Get("error"); e != "" {
+		http.Error(w, "sign-in failed: "+e+" "+query.Get("error_description"), http.StatusUnauthorized)
+		return
+	}
+
+	var state loginState
+	cookie, err := r.Cookie(loginCookie)
+	if err != nil || s.open(loginCookie, cookie.Value, &state) != nil || s.Now().Unix() >= state.Expires {
+		http.Error(w, "sign-in expired, please try again", http.StatusBadRequest)
+		return
+	}
+	if !hmac.Equal([]byte(query.Get("state")), []byte(state.State)) {
+		http.Error(w, "sign-in state mismatch", http.StatusBadRequest)
+		return
+	}
+	s.clearCookie(w, loginCookie)
+
+	claims, err := s.exchange(r.Context(), query.Get("code"), state.Verifier)
+	if err != nil {
+		http.Error(w, "sign-in failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if nonce, _ := claims["nonce"].(string); !hmac.Equal([]byte(nonce), []byte(state.Nonce)) {
+		http.Error(w, "sign-in failed: ID token nonce mismatch", http.StatusUnauthorized)
+		return
+	}
+	s.startSession(w, r, subject(claims), s.roles(claims), state.Return)
+}
+
+// startSession signs a user holding roles in and returns them to returnTo
+func (s *SSO) startSession(w http.ResponseWriter, r *http.Request, subject string, roles []string, returnTo string) {
+	if len(roles) == 0 {
+		http.Error(w, "forbidden: none of your groups grants an admin role", http.StatusForbidden)
+		return
+	}
+	s.setCookie(w, sessionCookie, s.seal(sessionCookie, session{
+		Subject: subject,
+		Roles:   roles,
+		Expires: s.Now().Add(s.SessionTTL).Unix(),
+	}), s.SessionTTL)
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
+
+// logout ends the session
+func (s *SSO) logout(w http.ResponseWriter, r *http.Request) {
+	s.clearCookie(w, sessionCookie)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "signed out"})
+}
+
+// exchange redeems an authorization code at the token endpoint and returns
+// the verified claims of the ID token
+func (s *SSO) exchange(ctx context.Context, code, verifier string) (map[string]interface{}, error) {
+	provider, jwt, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.RedirectURL},
+		"client_id":     {s.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.ClientID), url.QueryEscape(s.ClientSecret))
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed: %s", resp.Status)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("token response has no ID token")
+	}
+	return jwt.VerifyClaims(ctx, tokens.IDToken)
+}
+
+// discover fetches the provider's discovery document once and returns it
+// with a verifier for its ID tokens
+func (s *SSO) discover(ctx context.Context) (*oidcProvider, *JWTVerifier, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider != nil {
+		return s.provider, s.verifier, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch OpenID configuration: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("failed to fetch OpenID configuration: %s", resp.Status)
+	}
+	var provider oidcProvider
+	if err := json.NewDecoder(resp.Body).Decode(&provider); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode OpenID configuration: %w", err)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("OpenID configuration lacks authorization, token or JWKS endpoint")
+	}
+
+	s.provider = &provider
+	s.verifier = NewJWTVerifier(config.JWTConfig{Issuer: s.Issuer, Audience: s.ClientID, JWKSURL: provider.JWKSURI})
+	s.verifier.HTTPClient = s.HTTPClient
+	s.verifier.Now = s.Now
+	return s.provider, s.verifier, nil
+}
+
+// roles maps the groups claim to roles
+func (s *SSO) roles(claims map[string]interface{}) []string {
+	var groups []string
+	switch value := claims[s.GroupsClaim].(type) {
+	case string:
+		groups = strings.Fields(value)
+	case []interface{}:
+		for _, item := range value {
+			if group, ok := item.(string); ok {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return s.rolesOf(groups)
+}
+
+// rolesOf maps groups to roles, sorted with admin first
+func (s *SSO) rolesOf(groups []string) []string {
+	granted := make(map[string]bool)
+	for _, group := range groups {
+		if role, ok := s.GroupRoles[group]; ok {
+			granted[role] = true
+		}
+	}
+	// Admins may do everything viewers may
+	if granted[RoleAdmin] {
+		granted[RoleViewer] = true
+	}
+	roles := make([]string, 0, len(granted))
+	for role := range granted {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// subject names the user of an ID token, preferring readable claims
+func subject(claims map[string]interface{}) string {
+	for _, name := range []string{"email", "preferred_username", "sub"} {
+		if value, ok := claims[name].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// cookieKey derives the signing key of the cookie named name from the
+// session secret, so a value sealed for one cookie is rejected by the others
+func (s *SSO) cookieKey(name string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("a2a-connector cookie " + name))
+	return mac.Sum(nil)
+}
+
+// seal encodes v as a value of the cookie named name, signed with its key
+func (s *SSO) seal(name string, v interface{}) string {
+	payload, _ := json.Marshal(v)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, s.cookieKey(name))
+	mac.Write([]byte(encoded))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// open checks that value was sealed for the cookie named name and decodes
+// it into v
+func (s *SSO) open(name, value string, v interface{}) error {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return fmt.Errorf("malformed cookie")
+	}
+	mac := hmac.New(sha256.New, s.cookieKey(name))
+	mac.Write([]byte(encoded))
+	expected := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("invalid cookie signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
+
+func (s *SSO) setCookie(w http.ResponseWriter, name, value string, ttl time.Duration) {
+	secure := strings.HasPrefix(s.RedirectURL, "https://")
+	sameSite := http.SameSiteLaxMode
+	if s.SAML != nil {
+		secure = s.SAML.sp.AcsURL.Scheme == "https"
+		// Browsers hold Lax cookies back from the SAML response the identity
+		// provider posts from its own site
+		if name == loginCookie && secure {
+			sameSite = http.SameSiteNoneMode
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/admin/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSite,
+	})
+}
+
+func (s *SSO) clearCookie(w http.ResponseWriter, name string) {
+	s.setCookie(w, name, "", -time.Second)
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,371 @@
+package security_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/security"
+	"github.com/crewjam/saml"
+)
+
+func TestSSO(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	token := func(claims map[string]interface{}) string {
+		header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1"})
+		payload, _ := json.Marshal(claims)
+		signingInput := b64(header) + "." + b64(payload)
+		digest := sha256.Sum256([]byte(signingInput))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signingInput + "." + b64(sig)
+	}
+
+	// A fake identity provider issuing ID tokens for the code it handed out
+	var idp *httptest.Server
+	var nonce, challenge string
+	groups := []string{"ops"}
+	idp = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"authorization_endpoint": idp.URL + "/authorize",
+				"token_endpoint":         idp.URL + "/token",
+				"jwks_uri":               idp.URL + "/keys",
+			})
+		case "/keys":
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kty": "RSA", "kid": "k1",
+				"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		case "/token":
+			r.ParseForm()
+			id, secret, _ := r.BasicAuth()
+			verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+			if r.PostForm.Get("code") != "code-1" || id != "connector" || secret != "s3cret" || b64(verifier[:]) != challenge {
+				http.Error(w, "invalid_grant", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"id_token": token(map[string]interface{}{
+				"iss": idp.URL, "aud": "connector", "sub": "u1", "email": "ana@example.com",
+				"exp": time.Now().Add(time.Hour).Unix(), "nonce": nonce, "groups": groups,
+			})})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer idp.Close()
+
+	sso, err := security.NewSSO(&config.SSOConfig{
+		Issuer: idp.URL, ClientID: "connector", ClientSecret: "s3cret",
+		RedirectURL: "https://connector.example.com/admin/callback",
+		GroupRoles:  map[string]string{"platform-admins": "admin", "ops": "viewer", "auditors": "auditor"},
+	})
+	if err != nil {
+		t.Fatalf("NewSSO: %v", err)
+	}
+	h := sso.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := security.PrincipalFromContext(r.Context())
+		w.Write([]byte(p.Subject + ":" + strings.Join(p.Scopes, ",")))
+	}))
+	serve := func(method, target string, cookies []*http.Cookie, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	signIn := func() []*http.Cookie {
+		rec := serve(http.MethodGet, "/admin/login?return=/admin/unmatched", nil, nil)
+		if rec.Code != http.StatusFound {
+			t.Fatalf("Expected a redirect to the provider, got %d %s", rec.Code, rec.Body.String())
+		}
+		location, _ := url.Parse(rec.Header().Get("Location"))
+		query := location.Query()
+		if !strings.HasPrefix(location.String(), idp.URL+"/authorize?") || query.Get("client_id") != "connector" || query.Get("code_challenge_method") != "S256" {
+			t.Fatalf("Unexpected authorization redirect %s", location)
+		}
+		nonce, challenge = query.Get("nonce"), query.Get("code_challenge")
+
+		rec = serve(http.MethodGet, "/admin/callback?code=code-1&state="+query.Get("state"), rec.Result().Cookies(), nil)
+		if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/admin/unmatched" {
+			t.Fatalf("Expected a redirect back after sign-in, got %d %s", rec.Code, rec.Body.String())
+		}
+		return rec.Result().Cookies()
+	}
+
+	// Browsers without a session are sent to sign in, API clients get 401
+	if rec := serve(http.MethodGet, "/admin/unmatched", nil, http.Header{"Accept": {"text/html"}}); rec.Code != http.StatusFound {
+		t.Errorf("Expected a redirect to sign in, got %d", rec.Code)
+	}
+	if rec := serve(http.MethodGet, "/admin/unmatched", nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a session, got %d", rec.Code)
+	}
+
+	// Viewers may read but not change
+	cookies := signIn()
+	if rec := serve(http.MethodGet, "/admin/unmatched", cookies, nil); rec.Body.String() != "ana@example.com:viewer" {
+		t.Errorf("Unexpected principal %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodDelete, "/admin/unmatched", cookies, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a viewer, got %d", rec.Code)
+	}
+
+	// Admins may do both
+	groups = []string{"ops", "platform-admins"}
+	cookies = signIn()
+	if rec := serve(http.MethodDelete, "/admin/unmatched", cookies, nil); rec.Body.String() != "ana@example.com:admin,viewer" {
+		t.Errorf("Unexpected principal %d %q", rec.Code, rec.Body.String())
+	}
+
+	// A forged state is rejected
+	rec := serve(http.MethodGet, "/admin/login", nil, nil)
+	if rec := serve(http.MethodGet, "/admin/callback?code=code-1&state=forged", rec.Result().Cookies(), nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a forged state, got %d", rec.Code)
+	}
+
+	// A tampered session is rejected
+	var tampered []*http.Cookie
+	for _, c := range cookies {
+		if c.Value != "" {
+			tampered = append(tampered, &http.Cookie{Name: c.Name, Value: "x" + c.Value})
+		}
+	}
+	if rec := serve(http.MethodGet, "/admin/unmatched", tampered, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a tampered session, got %d", rec.Code)
+	}
+
+	// The login cookie is signed with another key, so it cannot be replayed
+	// as a session
+	for _, c := range serve(http.MethodGet, "/admin/login", nil, nil).Result().Cookies() {
+		replayed := []*http.Cookie{{Name: "a2a_admin_session", Value: c.Value}}
+		if rec := serve(http.MethodGet, "/admin/unmatched", replayed, nil); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for a login cookie replayed as a session, got %d %q", rec.Code, rec.Body.String())
+		}
+	}
+
+	// API clients may send an ID token instead
+	bearer := func(groups []string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token(map[string]interface{}{
+			"iss": idp.URL, "aud": "connector", "sub": "ci-bot",
+			"exp": time.Now().Add(time.Hour).Unix(), "groups": groups,
+		})}}
+	}
+	if rec := serve(http.MethodGet, "/admin/unmatched", nil, bearer([]string{"ops"})); rec.Body.String() != "ci-bot:viewer" {
+		t.Errorf("Unexpected principal %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodGet, "/admin/unmatched", nil, bearer([]string{"sales"})); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a mapped group, got %d", rec.Code)
+	}
+	// Reading requires the viewer role, not just any role
+	if rec := serve(http.MethodGet, "/admin/unmatched", nil, bearer([]string{"auditors"})); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without the viewer role, got %d", rec.Code)
+	}
+}
+
+// samlIDP serves a fake SAML identity provider signing in one user
+type samlIDP struct {
+	sp     *saml.EntityDescriptor
+	groups []string
+}
+
+func (p *samlIDP) GetServiceProvider(r *http.Request, id string) (*saml.EntityDescriptor, error) {
+	if p.sp == nil || p.sp.EntityID != id {
+		return nil, os.ErrNotExist
+	}
+	return p.sp, nil
+}
+
+func (p *samlIDP) GetSession(w http.ResponseWriter, r *http.Request, req *saml.IdpAuthnRequest) *saml.Session {
+	values := make([]saml.AttributeValue, len(p.groups))
+	for i, group := range p.groups {
+		values[i] = saml.AttributeValue{Type: "xs:string", Value: group}
+	}
+	return &saml.Session{
+		ID: "s1", NameID: "ana@example.com",
+		CustomAttributes: []saml.Attribute{{Name: "groups", Values: values}},
+	}
+}
+
+func TestSSOSAML(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	fake := &samlIDP{groups: []string{"ops"}}
+	idpServer := httptest.NewUnstartedServer(nil)
+	metadataURL, _ := url.Parse("http://" + idpServer.Listener.Addr().String() + "/metadata")
+	ssoURL, _ := url.Parse("http://" + idpServer.Listener.Addr().String() + "/sso")
+	idp := &saml.IdentityProvider{
+		Key:                     key,
+		Certificate:             cert,
+		Logger:                  log.New(io.Discard, "", 0),
+		MetadataURL:             *metadataURL,
+		SSOURL:                  *ssoURL,
+		ServiceProviderProvider: fake,
+		SessionProvider:         fake,
+	}
+	idpServer.Config.Handler = idp.Handler()
+	idpServer.Start()
+	defer idpServer.Close()
+
+	sso, err := security.NewSSO(&config.SSOConfig{
+		SAML: &config.SAMLConfig{
+			IDPMetadataURL: idp.MetadataURL.String(),
+			ACSURL:         "https://connector.example.com/admin/saml/acs",
+		},
+		GroupRoles: map[string]string{"platform-admins": "admin", "ops": "viewer"},
+	})
+	if err != nil {
+		t.Fatalf("NewSSO: %v", err)
+	}
+	h := sso.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := security.PrincipalFromContext(r.Context())
+		w.Write([]byte(p.Subject + ":" + strings.Join(p.Scopes, ",")))
+	}))
+	serve := func(req *http.Request, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The identity provider registers the service provider from its metadata
+	rec := serve(httptest.NewRequest(http.MethodGet, "/admin/saml/metadata", nil), nil)
+	fake.sp = &saml.EntityDescriptor{}
+	if err := xml.Unmarshal(rec.Body.Bytes(), fake.sp); err != nil {
+		t.Fatalf("Invalid metadata %d %s: %v", rec.Code, rec.Body.String(), err)
+	}
+	if fake.sp.EntityID != "https://connector.example.com/admin/saml/metadata" || len(fake.sp.SPSSODescriptors[0].AssertionConsumerServices) != 1 {
+		t.Errorf("Unexpected metadata %s", rec.Body.String())
+	}
+
+	// login sends the browser to the identity provider, which posts the
+	// response back to the connector
+	formValue := regexp.MustCompile(`name="(SAMLResponse|RelayState)" value="([^"]*)"`)
+	login := func() (form url.Values, cookies []*http.Cookie) {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/admin/login?return=/admin/unmatched", nil), nil)
+		location := rec.Header().Get("Location")
+		if rec.Code != http.StatusFound || !strings.HasPrefix(location, idp.SSOURL.String()+"?SAMLRequest=") {
+			t.Fatalf("Expected a redirect to the identity provider, got %d %s", rec.Code, rec.Body.String())
+		}
+		resp, err := http.Get(location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		page, _ := io.ReadAll(resp.Body)
+		form = url.Values{}
+		for _, match := range formValue.FindAllStringSubmatch(string(page), -1) {
+			form.Set(match[1], html.UnescapeString(match[2]))
+		}
+		if form.Get("SAMLResponse") == "" {
+			t.Fatalf("Expected a SAML response form, got %d %s", resp.StatusCode, page)
+		}
+		return form, rec.Result().Cookies()
+	}
+	post := func(form url.Values, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/saml/acs", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return serve(req, cookies)
+	}
+
+	form, loginCookies := login()
+	for _, c := range loginCookies {
+		if c.SameSite != http.SameSiteNoneMode || !c.Secure {
+			t.Errorf("Expected the login cookie to reach the posted response, got %+v", c)
+		}
+	}
+	rec = post(form, loginCookies)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/admin/unmatched" {
+		t.Fatalf("Expected a redirect back after sign-in, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(httptest.NewRequest(http.MethodGet, "/admin/unmatched", nil), rec.Result().Cookies()); rec.Body.String() != "ana@example.com:viewer" {
+		t.Errorf("Unexpected principal %d %q", rec.Code, rec.Body.String())
+	}
+
+	// A response answering another sign-in is rejected
+	other, otherCookies := login()
+	form.Set("RelayState", other.Get("RelayState"))
+	if rec := post(form, otherCookies); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "InResponseTo") {
+		t.Errorf("Expected 401 for another sign-in's response, got %d %s", rec.Code, rec.Body.String())
+	}
+	form.Set("RelayState", "forged")
+	if rec := post(form, loginCookies); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a forged relay state, got %d", rec.Code)
+	}
+
+	// The assertion must carry the identity provider's signature
+	form, loginCookies = login()
+	response, _ := base64.StdEncoding.DecodeString(form.Get("SAMLResponse"))
+	tampered := strings.Replace(string(response), ">ops<", ">platform-admins<", 1)
+	if tampered == string(response) {
+		t.Fatalf("Expected the groups in the response, got %s", response)
+	}
+	form.Set("SAMLResponse", base64.StdEncoding.EncodeToString([]byte(tampered)))
+	if rec := post(form, loginCookies); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "ignature") {
+		t.Errorf("Expected 401 for a tampered assertion, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Admin groups grant admin, and groups without a role nothing
+	fake.groups = []string{"ops", "platform-admins"}
+	rec = post(login())
+	if rec := serve(httptest.NewRequest(http.MethodDelete, "/admin/unmatched", nil), rec.Result().Cookies()); rec.Body.String() != "ana@example.com:admin,viewer" {
+		t.Errorf("Unexpected principal %d %q", rec.Code, rec.Body.String())
+	}
+	fake.groups = []string{"sales"}
+	if rec := post(login()); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without a mapped group, got %d", rec.Code)
+	}
+
+	// Bearer ID tokens need OpenID Connect
+	req := httptest.NewRequest(http.MethodGet, "/admin/unmatched", nil)
+	req.Header.Set("Authorization", "Bearer x.y.z")
+	if rec := serve(req, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bearer token, got %d", rec.Code)
+	}
+}
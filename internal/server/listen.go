@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// Address schemes for local listeners
+const (
+	// UnixScheme prefixes Unix domain socket paths, e.g. unix:/run/a2a/connector.sock
+	UnixScheme = "unix:"
+	// PipeScheme prefixes Windows named pipes, e.g. npipe:\\.\pipe\a2a-connector
+	PipeScheme = "npipe:"
+)
+
+// SocketMode is the file mode of Unix domain sockets; group access lets a
+// sidecar in the same group connect
+const SocketMode os.FileMode = 0660
+
+// IsLocal reports whether addr is a Unix socket or named pipe rather than TCP
+func IsLocal(addr string) bool {
+	return strings.HasPrefix(addr, UnixScheme) || strings.HasPrefix(addr, PipeScheme)
+}
+
+// Listen opens a listener on a TCP address, a unix: socket path or, on
+// Windows, an npipe: named pipe. A stale socket file left by a previous run is
+// removed first.
+func Listen(addr string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(addr, UnixScheme):
+		return listenUnix(strings.TrimPrefix(addr, UnixScheme))
+	case strings.HasPrefix(addr, PipeScheme):
+		return listenPipe(strings.TrimPrefix(addr, PipeScheme))
+	default:
+		return net.Listen("tcp", addr)
+	}
+}
+
+func listenUnix(path string) (net.Listener, error) {
+	if path == "" {
+		return nil, fmt.Errorf("unix socket path is empty")
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		// Only remove the socket when nothing is listening on it
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket %s is already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, SocketMode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return ln, nil
+}
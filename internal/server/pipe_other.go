@@ -0,0 +1,13 @@
+//go:build !windows
+
+package server
+
+import (
+	"fmt"
+	"net"
+)
+
+// listenPipe fails: named pipes only exist on Windows
+func listenPipe(name string) (net.Listener, error) {
+	return nil, fmt.Errorf("named pipe %s: named pipes are only supported on Windows, use a %s socket path instead", name, UnixScheme)
+}
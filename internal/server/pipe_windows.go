@@ -0,0 +1,22 @@
+//go:build windows
+
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// pipeSecurity lets only the owner of the pipe, administrators and the
+// system account connect, like the group-restricted mode of Unix sockets
+const pipeSecurity = "D:P(A;;GA;;;OW)(A;;GA;;;BA)(A;;GA;;;SY)"
+
+// listenPipe listens on a named pipe such as \\.\pipe\a2a-connector
+func listenPipe(name string) (net.Listener, error) {
+	if name == "" {
+		return nil, fmt.Errorf("named pipe name is empty")
+	}
+	return winio.ListenPipe(name, &winio.PipeConfig{SecurityDescriptor: pipeSecurity})
+}
@@ -0,0 +1,266 @@
+// Package server runs the connector's HTTP listeners for the data, admin and metrics planes
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Listener is one HTTP listener serving one or more planes
+type Listener struct {
+	Planes []string
+	Addr   string
+	TLS    *tls.Config
+	Mux    *http.ServeMux
+	server *http.Server
+}
+
+// Group runs the listeners of the connector; planes that share an address
+// share a listener
+type Group struct {
+	listeners []*Listener
+	byAddr    map[string]*Listener
+}
+
+// NewGroup creates an empty listener group
+func NewGroup() *Group {
+	return &Group{byAddr: make(map[string]*Listener)}
+}
+
+// Mux returns the mux serving plane on addr, creating a listener for a new
+// address. Planes added to an existing listener must not ask for other TLS
+// settings, and a plane is served once per listener.
+func (g *Group) Mux(plane, addr string, tlsConfig *tls.Config) (*http.ServeMux, error) {
+	if l, ok := g.byAddr[addr]; ok {
+		for _, existing := range l.Planes {
+			if existing == plane {
+				return nil, fmt.Errorf("%s plane is already served on %s", plane, addr)
+			}
+		}
+		if tlsConfig != nil && tlsConfig != l.TLS {
+			return nil, fmt.Errorf("%s plane shares %s with %s and cannot set its own TLS", plane, addr, strings.Join(l.Planes, ", "))
+		}
+		l.Planes = append(l.Planes, plane)
+		return l.Mux, nil
+	}
+
+	l := &Listener{Planes: []string{plane}, Addr: addr, TLS: tlsConfig, Mux: http.NewServeMux()}
+	g.listeners = append(g.listeners, l)
+	g.byAddr[addr] = l
+	return l.Mux, nil
+}
+
+// Listeners returns the listeners in creation order
+func (g *Group) Listeners() []*Listener {
+	return g.listeners
+}
+
+// Start opens every listener and serves it in the background; serve errors
+// are sent to errs. Listeners already opened are closed when one fails to open.
+func (g *Group) Start(errs chan<- error) error {
+	lns := make([]net.Listener, 0, len(g.listeners))
+	for _, l := range g.listeners {
+		ln, err := Listen(l.Addr)
+		if err != nil {
+			for _, opened := range lns {
+				opened.Close()
+			}
+			return fmt.Errorf("%s listener on %s: %w", strings.Join(l.Planes, "/"), l.Addr, err)
+		}
+		lns = append(lns, ln)
+	}
+
+	for i, l := range g.listeners {
+		l.server = &http.Server{
+			Addr:         l.Addr,
+			Handler:      l.Mux,
+			TLSConfig:    l.TLS,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 30 * time.Second,
+		}
+		go func(l *Listener, ln net.Listener) {
+			var err error
+			if l.TLS != nil {
+				// Certificates are already loaded into the TLS config
+				err = l.server.ServeTLS(ln, "", "")
+			} else {
+				err = l.server.Serve(ln)
+			}
+			if err != nil && err != http.ErrServerClosed {
+				errs <- fmt.Errorf("%s listener on %s: %w", strings.Join(l.Planes, "/"), l.Addr, err)
+			}
+		}(l, lns[i])
+	}
+	return nil
+}
+
+// Shutdown gracefully stops every listener
+func (g *Group) Shutdown(ctx context.Context) error {
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	for _, l := range g.listeners {
+		if l.server == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(l *Listener) {
+			defer wg.Done()
+			if err := l.server.Shutdown(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(l)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// TLSConfig loads the certificates of a listener; nil config disables TLS
+func TLSConfig(cfg *config.TLSConfig) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	switch cfg.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS minVersion %q", cfg.MinVersion)
+	}
+	if len(cfg.CipherSuites) > 0 {
+		suites, err := cipherSuites(cfg.CipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.CipherSuites = suites
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+
+		switch cfg.ClientAuth {
+		case "", "require":
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		case "optional":
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("unsupported TLS clientAuth %q", cfg.ClientAuth)
+		}
+		if len(cfg.ClientNames) > 0 {
+			tlsConfig.VerifyConnection = verifyClientName(cfg.ClientNames)
+		}
+	} else if cfg.ClientAuth != "" || len(cfg.ClientNames) > 0 {
+		return nil, fmt.Errorf("client authentication requires clientCAFile")
+	}
+	return tlsConfig, nil
+}
+
+// cipherSuites resolves TLS 1.2 cipher suite names; insecure suites are
+// rejected. TLS 1.3 suites are not configurable.
+func cipherSuites(names []string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure TLS cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// verifyClientName accepts client certificates whose common name or a DNS
+// name matches one of names; connections without a certificate pass, since
+// ClientAuth decides whether one is required
+func verifyClientName(names []string) func(tls.ConnectionState) error {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return nil
+		}
+		leaf := cs.PeerCertificates[0]
+		if allowed[leaf.Subject.CommonName] {
+			return nil
+		}
+		for _, name := range leaf.DNSNames {
+			if allowed[name] {
+				return nil
+			}
+		}
+		return fmt.Errorf("client certificate %q is not allowed", leaf.Subject.CommonName)
+	}
+}
+
+// Authenticate requires basic or bearer credentials on next; an empty auth type returns next
+func Authenticate(cfg config.AuthConfig, next http.Handler) http.Handler {
+	switch cfg.Type {
+	case "basic":
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok || !equal(username, cfg.Username) || !equal(password, cfg.Password) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="a2a-connector"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	case "bearer":
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, "Bearer ") || !equal(strings.TrimPrefix(header, "Bearer "), cfg.Token) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	default:
+		return next
+	}
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,246 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/server"
+)
+
+func TestGroupSharesListeners(t *testing.T) {
+	g := server.NewGroup()
+	data, _ := g.Mux("data", ":8082", nil)
+	admin, _ := g.Mux("admin", ":8082", nil)
+	metrics, _ := g.Mux("metrics", ":9090", nil)
+
+	if data != admin || data == metrics {
+		t.Error("Expected planes on the same address to share a mux")
+	}
+	listeners := g.Listeners()
+	if len(listeners) != 2 || len(listeners[0].Planes) != 2 || listeners[1].Planes[0] != "metrics" {
+		t.Errorf("Unexpected listeners: %+v", listeners)
+	}
+
+	// A plane sharing a listener cannot bring its own TLS settings
+	if _, err := g.Mux("admin-tls", ":8082", &tls.Config{}); err == nil {
+		t.Error("Expected conflicting TLS settings to be rejected")
+	}
+
+	// A plane can listen on several addresses, but only once on each
+	if _, err := g.Mux("data", ":8443", &tls.Config{}); err != nil {
+		t.Errorf("Expected the data plane on a second address, got %v", err)
+	}
+	if _, err := g.Mux("data", ":8082", nil); err == nil {
+		t.Error("Expected the data plane to be rejected on an address it is served on")
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	bearer := server.Authenticate(config.AuthConfig{Type: "bearer", Token: "s3cret"}, ok)
+	for header, expected := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"s3cret":        http.StatusUnauthorized,
+		"Bearer s3cret": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		bearer.ServeHTTP(rec, req)
+		if rec.Code != expected {
+			t.Errorf("Authorization %q: expected %d, got %d", header, expected, rec.Code)
+		}
+	}
+
+	basic := server.Authenticate(config.AuthConfig{Type: "basic", Username: "ops", Password: "pw"}, ok)
+	req := httptest.NewRequest(http.MethodGet, "/admin/unmatched", nil)
+	req.SetBasicAuth("ops", "pw")
+	rec := httptest.NewRecorder()
+	basic.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected valid basic credentials to pass, got %d", rec.Code)
+	}
+
+	// No auth type leaves the handler open
+	rec = httptest.NewRecorder()
+	server.Authenticate(config.AuthConfig{}, ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected open handler, got %d", rec.Code)
+	}
+}
+
+func TestUnixSocketListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "connector.sock")
+
+	// A stale socket file from a previous run is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("Unix domain sockets unavailable: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	g := server.NewGroup()
+	mux, _ := g.Mux("data", server.UnixScheme+path, nil)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	if err := g.Start(make(chan error, 1)); err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	defer g.Shutdown(context.Background())
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != server.SocketMode {
+		t.Errorf("Unexpected socket file: %v %v", info, err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://connector/health")
+	if err != nil {
+		t.Fatalf("Request over socket failed: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("Unexpected response %q", body)
+	}
+
+	// A socket in use is not taken over
+	if _, err := server.Listen(server.UnixScheme + path); err == nil {
+		t.Error("Expected a socket in use to be rejected")
+	}
+	if ln, err := server.Listen(server.PipeScheme + `\\.\pipe\connector-test`); runtime.GOOS == "windows" {
+		if err != nil {
+			t.Errorf("Expected a named pipe listener, got %v", err)
+		} else {
+			ln.Close()
+		}
+	} else if err == nil {
+		t.Error("Expected named pipes to be rejected outside Windows")
+	}
+}
+
+// testCert issues a certificate for name signed by parent (self-signed when nil)
+func testCert(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, interface{}(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writePEM writes the certificate and key of cert and returns their paths
+func writePEM(t *testing.T, dir, name string, cert tls.Certificate) (string, string) {
+	t.Helper()
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestTLSConfigMutualAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := testCert(t, "test-ca", nil)
+	caFile, _ := writePEM(t, dir, "ca", ca)
+	certFile, keyFile := writePEM(t, dir, "server", testCert(t, "connector", &ca))
+
+	tlsConfig, err := server.TLSConfig(&config.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+		ClientCAFile: caFile,
+		ClientNames:  []string{"saas-gateway"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build TLS config: %v", err)
+	}
+	if tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert || len(tlsConfig.CipherSuites) != 1 {
+		t.Errorf("Unexpected TLS config: %+v", tlsConfig)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	get := func(certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(testCert(t, "saas-gateway", &ca)); err != nil {
+		t.Errorf("Expected allowed client certificate to connect, got %v", err)
+	}
+	if err := get(testCert(t, "intruder", &ca)); err == nil {
+		t.Error("Expected client certificate with another name to be rejected")
+	}
+	if err := get(); err == nil {
+		t.Error("Expected connection without client certificate to be rejected")
+	}
+
+	// Unknown or insecure cipher suites and client auth without a CA are config errors
+	if _, err := server.TLSConfig(&config.TLSConfig{CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}); err == nil {
+		t.Error("Expected insecure cipher suite to be rejected")
+	}
+	if _, err := server.TLSConfig(&config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: "optional"}); err == nil {
+		t.Error("Expected clientAuth without clientCAFile to be rejected")
+	}
+}
@@ -0,0 +1,320 @@
+// Package soak runs probe tasks for long periods and certifies the connector's stability
+package soak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// Defaults for soak runs
+const (
+	DefaultInterval     = 30 * time.Second
+	DefaultDuration     = 72 * time.Hour
+	DefaultWindow       = time.Hour
+	DefaultMaxErrorRate = 0.01
+)
+
+// Probe is a task text executed repeatedly during a soak run
+type Probe struct {
+	Name string
+	Text string
+}
+
+// ExecuteFunc runs one probe task against the legacy system
+type ExecuteFunc func(ctx context.Context, text string) error
+
+// Runner executes probes at a low rate and records stability metrics
+type Runner struct {
+	Probes   []Probe
+	Execute  ExecuteFunc
+	Interval time.Duration
+	Duration time.Duration
+	Window   time.Duration
+
+	// Thresholds for certification; zero memory growth disables that check
+	MaxErrorRate      float64
+	MaxMemoryGrowthMB float64
+
+	// Progress, if set, receives each completed window
+	Progress func(WindowStats)
+
+	// Now and ReadMemory can be replaced in tests
+	Now        func() time.Time
+	ReadMemory func() uint64
+}
+
+// Report is the certification report of a soak run
+type Report struct {
+	Start             time.Time              `json:"start"`
+	End               time.Time              `json:"end"`
+	Duration          string                 `json:"duration"`
+	Executions        int                    `json:"executions"`
+	Errors            int                    `json:"errors"`
+	ErrorRate         float64                `json:"errorRate"`
+	Reconnects        int                    `json:"reconnects"`
+	MemoryStartBytes  uint64                 `json:"memoryStartBytes"`
+	MemoryEndBytes    uint64                 `json:"memoryEndBytes"`
+	MemoryPeakBytes   uint64                 `json:"memoryPeakBytes"`
+	MemoryGrowthBytes int64                  `json:"memoryGrowthBytes"`
+	Probes            map[string]*ProbeStats `json:"probes"`
+	Windows           []WindowStats          `json:"windows"`
+	Certified         bool                   `json:"certified"`
+	Failures          []string               `json:"failures,omitempty"`
+}
+
+// ProbeStats counts executions of a single probe
+type ProbeStats struct {
+	Executions int      `json:"executions"`
+	Errors     int      `json:"errors"`
+	MaxLatency string   `json:"maxLatency"`
+	LastErrors []string `json:"lastErrors,omitempty"`
+	maxLatency time.Duration
+}
+
+// WindowStats summarizes one reporting window, used to spot error trends
+type WindowStats struct {
+	Start       time.Time `json:"start"`
+	Executions  int       `json:"executions"`
+	Errors      int       `json:"errors"`
+	MemoryBytes uint64    `json:"memoryBytes"`
+}
+
+// maxLastErrors bounds the error samples kept per probe
+const maxLastErrors = 5
+
+// NewRunner creates a runner from soak config
+func NewRunner(cfg config.SoakConfig, execute ExecuteFunc) (*Runner, error) {
+	r := &Runner{
+		Execute:           execute,
+		Interval:          DefaultInterval,
+		Duration:          DefaultDuration,
+		Window:            DefaultWindow,
+		MaxErrorRate:      DefaultMaxErrorRate,
+		MaxMemoryGrowthMB: cfg.MaxMemoryGrowthMB,
+		Now:               time.Now,
+		ReadMemory:        heapInUse,
+	}
+	if cfg.MaxErrorRate > 0 {
+		r.MaxErrorRate = cfg.MaxErrorRate
+	}
+	for _, field := range []struct {
+		value string
+		dst   *time.Duration
+	}{
+		{cfg.Interval, &r.Interval},
+		{cfg.Duration, &r.Duration},
+		{cfg.Window, &r.Window},
+	} {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid soak duration %q: %w", field.value, err)
+		}
+		*field.dst = d
+	}
+
+	for i, p := range cfg.Probes {
+		name := p.Name
+		if name == "" {
+			name = fmt.Sprintf("probe-%d", i+1)
+		}
+		r.Probes = append(r.Probes, Probe{Name: name, Text: p.Text})
+	}
+	if len(r.Probes) == 0 {
+		return nil, fmt.Errorf("soak mode requires at least one probe")
+	}
+	return r, nil
+}
+
+// Run executes the probes round-robin until the duration elapses or ctx is
+// cancelled, then returns the report
+func (r *Runner) Run(ctx context.Context) *Report {
+	start := r.Now()
+	report := &Report{
+		Start:            start,
+		Probes:           make(map[string]*ProbeStats),
+		MemoryStartBytes: r.ReadMemory(),
+	}
+	report.MemoryPeakBytes = report.MemoryStartBytes
+	for _, p := range r.Probes {
+		report.Probes[p.Name] = &ProbeStats{}
+	}
+
+	window := WindowStats{Start: start}
+	disconnected := false
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		probe := r.Probes[i%len(r.Probes)]
+		stats := report.Probes[probe.Name]
+
+		callStart := r.Now()
+		err := r.Execute(ctx, probe.Text)
+		latency := r.Now().Sub(callStart)
+
+		report.Executions++
+		stats.Executions++
+		window.Executions++
+		if latency > stats.maxLatency {
+			stats.maxLatency = latency
+		}
+		if err != nil && ctx.Err() == nil {
+			report.Errors++
+			stats.Errors++
+			window.Errors++
+			stats.LastErrors = append(stats.LastErrors, err.Error())
+			if len(stats.LastErrors) > maxLastErrors {
+				stats.LastErrors = stats.LastErrors[1:]
+			}
+			if IsConnectionError(err) {
+				disconnected = true
+			}
+		} else if err == nil && disconnected {
+			// The backend is reachable again after a connection failure
+			report.Reconnects++
+			disconnected = false
+		}
+
+		mem := r.ReadMemory()
+		if mem > report.MemoryPeakBytes {
+			report.MemoryPeakBytes = mem
+		}
+
+		now := r.Now()
+		if now.Sub(window.Start) >= r.Window {
+			window.MemoryBytes = mem
+			report.Windows = append(report.Windows, window)
+			if r.Progress != nil {
+				r.Progress(window)
+			}
+			window = WindowStats{Start: now}
+		}
+		if now.Sub(start) >= r.Duration || !waitTick(ctx, ticker) {
+			break
+		}
+	}
+
+	report.End = r.Now()
+	report.MemoryEndBytes = r.ReadMemory()
+	if report.MemoryEndBytes > report.MemoryPeakBytes {
+		report.MemoryPeakBytes = report.MemoryEndBytes
+	}
+	if window.Executions > 0 {
+		window.MemoryBytes = report.MemoryEndBytes
+		report.Windows = append(report.Windows, window)
+	}
+	r.certify(report)
+	return report
+}
+
+// waitTick blocks until the next tick; it returns false when ctx is cancelled
+func waitTick(ctx context.Context, ticker *time.Ticker) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-ticker.C:
+		return true
+	}
+}
+
+// certify fills in the derived figures and checks the thresholds
+func (r *Runner) certify(report *Report) {
+	report.Duration = report.End.Sub(report.Start).Round(time.Second).String()
+	report.MemoryGrowthBytes = int64(report.MemoryEndBytes) - int64(report.MemoryStartBytes)
+	if report.Executions > 0 {
+		report.ErrorRate = float64(report.Errors) / float64(report.Executions)
+	}
+	for _, stats := range report.Probes {
+		stats.MaxLatency = stats.maxLatency.String()
+	}
+
+	if report.Executions == 0 {
+		report.Failures = append(report.Failures, "no probes were executed")
+	}
+	if report.ErrorRate > r.MaxErrorRate {
+		report.Failures = append(report.Failures, fmt.Sprintf("error rate %.2f%% exceeds %.2f%%", report.ErrorRate*100, r.MaxErrorRate*100))
+	}
+	if r.MaxMemoryGrowthMB > 0 {
+		growthMB := float64(report.MemoryGrowthBytes) / (1 << 20)
+		if growthMB > r.MaxMemoryGrowthMB {
+			report.Failures = append(report.Failures, fmt.Sprintf("memory grew by %.1f MB, more than %.1f MB", growthMB, r.MaxMemoryGrowthMB))
+		}
+	}
+	if trend := errorTrend(report.Windows); trend != "" {
+		report.Failures = append(report.Failures, trend)
+	}
+	report.Certified = len(report.Failures) == 0
+}
+
+// errorTrend reports errors rising in each of the last three windows
+func errorTrend(windows []WindowStats) string {
+	if len(windows) < 3 {
+		return ""
+	}
+	last := windows[len(windows)-3:]
+	if last[0].Errors < last[1].Errors && last[1].Errors < last[2].Errors {
+		return fmt.Sprintf("errors rising over the last three windows (%d, %d, %d)", last[0].Errors, last[1].Errors, last[2].Errors)
+	}
+	return ""
+}
+
+// WriteText writes a human-readable summary of the report
+func (report *Report) WriteText(w io.Writer) {
+	verdict := "CERTIFIED"
+	if !report.Certified {
+		verdict = "NOT CERTIFIED"
+	}
+	fmt.Fprintf(w, "Soak test %s\n", verdict)
+	fmt.Fprintf(w, "  Period:      %s to %s (%s)\n", report.Start.Format(time.RFC3339), report.End.Format(time.RFC3339), report.Duration)
+	fmt.Fprintf(w, "  Executions:  %d\n", report.Executions)
+	fmt.Fprintf(w, "  Errors:      %d (%.2f%%)\n", report.Errors, report.ErrorRate*100)
+	fmt.Fprintf(w, "  Reconnects:  %d\n", report.Reconnects)
+	fmt.Fprintf(w, "  Memory:      %s -> %s (peak %s)\n", formatBytes(report.MemoryStartBytes), formatBytes(report.MemoryEndBytes), formatBytes(report.MemoryPeakBytes))
+
+	names := make([]string, 0, len(report.Probes))
+	for name := range report.Probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stats := report.Probes[name]
+		fmt.Fprintf(w, "  Probe %s: %d executions, %d errors, max latency %s\n", name, stats.Executions, stats.Errors, stats.MaxLatency)
+	}
+	for _, failure := range report.Failures {
+		fmt.Fprintf(w, "  FAIL: %s\n", failure)
+	}
+}
+
+// IsConnectionError reports whether err means the backend could not be reached
+func IsConnectionError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "connection refused") || strings.Contains(msg, "connection reset") || strings.Contains(msg, "broken pipe")
+}
+
+// heapInUse returns the bytes of in-use heap spans
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
+
+func formatBytes(b uint64) string {
+	return fmt.Sprintf("%.1f MB", float64(b)/(1<<20))
+}
@@ -0,0 +1,89 @@
+package soak_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/soak"
+)
+
+func TestSoakRunReport(t *testing.T) {
+	calls := 0
+	runner, err := soak.NewRunner(config.SoakConfig{
+		Probes:            []config.SoakProbe{{Name: "customer", Text: "get customer 1"}, {Text: "list orders"}},
+		Interval:          "1ms",
+		Duration:          "30m",
+		Window:            "10m",
+		MaxErrorRate:      0.2,
+		MaxMemoryGrowthMB: 1,
+	}, func(ctx context.Context, text string) error {
+		calls++
+		if calls == 3 {
+			return errors.New("dial tcp 10.0.0.1:443: connect: connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create runner: %v", err)
+	}
+
+	// Each clock reading advances one minute; memory grows slowly
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runner.Now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	mem := uint64(10 << 20)
+	runner.ReadMemory = func() uint64 {
+		mem += 1 << 10
+		return mem
+	}
+
+	report := runner.Run(context.Background())
+
+	if report.Executions != 10 || report.Errors != 1 || report.Reconnects != 1 {
+		t.Errorf("Unexpected counts: executions=%d errors=%d reconnects=%d", report.Executions, report.Errors, report.Reconnects)
+	}
+	if report.Probes["customer"].Errors != 1 || report.Probes["probe-2"].Executions != 5 {
+		t.Errorf("Unexpected probe stats: %+v %+v", report.Probes["customer"], report.Probes["probe-2"])
+	}
+	if len(report.Windows) < 3 {
+		t.Errorf("Expected error trend windows, got %d", len(report.Windows))
+	}
+	if report.MemoryGrowthBytes <= 0 || report.MemoryPeakBytes < report.MemoryEndBytes {
+		t.Errorf("Unexpected memory figures: %+v", report)
+	}
+	if !report.Certified {
+		t.Errorf("Expected run to be certified, failures: %v", report.Failures)
+	}
+
+	var buf bytes.Buffer
+	report.WriteText(&buf)
+	if !strings.HasPrefix(buf.String(), "Soak test CERTIFIED") {
+		t.Errorf("Unexpected summary:\n%s", buf.String())
+	}
+}
+
+func TestSoakCertificationFailures(t *testing.T) {
+	runner, _ := soak.NewRunner(config.SoakConfig{
+		Probes:   []config.SoakProbe{{Text: "get customer 1"}},
+		Interval: "1ms",
+		Duration: "1ms",
+	}, func(ctx context.Context, text string) error {
+		return errors.New("HTTP 500")
+	})
+
+	report := runner.Run(context.Background())
+	if report.Certified || len(report.Failures) == 0 {
+		t.Errorf("Expected failing run not to be certified: %+v", report)
+	}
+
+	if _, err := soak.NewRunner(config.SoakConfig{}, nil); err == nil {
+		t.Error("Expected a runner without probes to be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+package sops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ageIntro starts the header of binary age files
+const ageIntro = "age-encryption.org/v1\n"
+
+// errNoIdentity is returned when none of the identities can decrypt a file
+var errNoIdentity = errors.New("no age identity matches the file's recipients")
+
+// IsAge reports whether data is an age encrypted file, binary or armored
+func IsAge(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	return bytes.HasPrefix(data, []byte(ageIntro)) || bytes.HasPrefix(data, []byte(armor.Header))
+}
+
+// ParseIdentities parses age X25519 identities, one AGE-SECRET-KEY-1... per
+// line as in age key files; blank lines and # comments are skipped
+func ParseIdentities(text string) ([]age.Identity, error) {
+	return age.ParseIdentities(strings.NewReader(text))
+}
+
+// DecryptAge decrypts an age file, binary or armored, with the first
+// identity that matches one of its recipients
+func DecryptAge(data []byte, identities []age.Identity) ([]byte, error) {
+	var in io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(armor.Header)) {
+		in = armor.NewReader(in)
+	}
+	r, err := age.Decrypt(in, identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, errNoIdentity
+		}
+		return nil, err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age payload: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,321 @@
+// Package sops decrypts config files encrypted with SOPS or age in memory,
+// so configs holding credentials can be kept in Git. SOPS files are
+// decrypted with an age identity or AWS KMS; age files with an age identity.
+package sops
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables holding age identities, as used by the sops CLI
+const (
+	EnvAgeKey     = "SOPS_AGE_KEY"
+	EnvAgeKeyFile = "SOPS_AGE_KEY_FILE"
+)
+
+// metadataKey is the top-level key SOPS keeps its metadata under
+const metadataKey = "sops"
+
+// Keys are the keys encrypted files are decrypted with
+type Keys struct {
+	// Identities decrypt age files and the data key of SOPS age recipients
+	Identities []age.Identity
+	// KMS decrypts the data key of SOPS AWS KMS recipients
+	KMS func(ctx context.Context, arn string, ciphertext []byte, encryptionContext map[string]string) ([]byte, error)
+}
+
+// KeysFromEnv reads age identities like the sops CLI: from SOPS_AGE_KEY,
+// the file named by SOPS_AGE_KEY_FILE, or sops/age/keys.txt in the user
+// config directory. Having none is not an error; KMS is left to the caller.
+func KeysFromEnv() (Keys, error) {
+	var keys Keys
+	text := os.Getenv(EnvAgeKey)
+	if text == "" {
+		path := os.Getenv(EnvAgeKeyFile)
+		if path == "" {
+			dir, err := os.UserConfigDir()
+			if err != nil {
+				return keys, nil
+			}
+			path = filepath.Join(dir, "sops", "age", "keys.txt")
+			if _, err := os.Stat(path); err != nil {
+				return keys, nil
+			}
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return keys, fmt.Errorf("error reading age key file: %v", err)
+		}
+		text = string(data)
+	}
+	identities, err := ParseIdentities(text)
+	if err != nil {
+		return keys, fmt.Errorf("invalid age key: %v", err)
+	}
+	keys.Identities = identities
+	return keys, nil
+}
+
+// IsEncrypted reports whether data is a SOPS or age encrypted file
+func IsEncrypted(data []byte) bool {
+	return IsAge(data) || IsSOPS(data)
+}
+
+// IsSOPS reports whether data is a YAML or JSON file encrypted with SOPS
+func IsSOPS(data []byte) bool {
+	if !bytes.Contains(data, []byte(metadataKey)) {
+		return false
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return false
+	}
+	metadata := mappingValue(documentNode(&root), metadataKey)
+	return metadata != nil && mappingValue(metadata, "mac") != nil
+}
+
+// Decrypt decrypts a SOPS or age file. Decrypted SOPS files are returned as
+// YAML without the SOPS metadata; age files as they were encrypted.
+func Decrypt(ctx context.Context, data []byte, keys Keys) ([]byte, error) {
+	if IsAge(data) {
+		if len(keys.Identities) == 0 {
+			return nil, fmt.Errorf("no age identity to decrypt with (set %s or %s)", EnvAgeKey, EnvAgeKeyFile)
+		}
+		return DecryptAge(data, keys.Identities)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	doc := documentNode(&root)
+	var metadata struct {
+		Age []struct {
+			Recipient string `yaml:"recipient"`
+			Enc       string `yaml:"enc"`
+		} `yaml:"age"`
+		KMS []struct {
+			ARN     string            `yaml:"arn"`
+			Role    string            `yaml:"role"`
+			Context map[string]string `yaml:"context"`
+			Enc     string            `yaml:"enc"`
+		} `yaml:"kms"`
+		LastModified     string `yaml:"lastmodified"`
+		MAC              string `yaml:"mac"`
+		MACOnlyEncrypted bool   `yaml:"mac_only_encrypted"`
+	}
+	node := mappingValue(doc, metadataKey)
+	if node == nil {
+		return nil, errors.New("no sops metadata")
+	}
+	if err := node.Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("invalid sops metadata: %v", err)
+	}
+
+	// The data key is encrypted for each recipient; any one will do
+	var dataKey []byte
+	var failures []string
+	for _, recipient := range metadata.Age {
+		if len(keys.Identities) == 0 {
+			failures = append(failures, "age: no identity (set "+EnvAgeKey+" or "+EnvAgeKeyFile+")")
+			break
+		}
+		key, err := DecryptAge([]byte(recipient.Enc), keys.Identities)
+		if err == nil {
+			dataKey = key
+			break
+		}
+		failures = append(failures, fmt.Sprintf("age %s: %v", recipient.Recipient, err))
+	}
+	for _, recipient := range metadata.KMS {
+		if dataKey != nil {
+			break
+		}
+		if keys.KMS == nil || recipient.Role != "" {
+			failures = append(failures, fmt.Sprintf("kms %s: not supported", recipient.ARN))
+			continue
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(recipient.Enc)
+		if err == nil {
+			dataKey, err = keys.KMS(ctx, recipient.ARN, ciphertext, recipient.Context)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("kms %s: %v", recipient.ARN, err))
+		}
+	}
+	if dataKey == nil {
+		if len(failures) == 0 {
+			return nil, errors.New("no age or kms recipient to decrypt the data key with")
+		}
+		return nil, fmt.Errorf("cannot decrypt the data key: %s", strings.Join(failures, "; "))
+	}
+
+	hash := sha512.New()
+	if err := decryptTree(doc, nil, dataKey, metadata.MACOnlyEncrypted, hash.Write); err != nil {
+		return nil, err
+	}
+	mac, _, err := decryptValue(metadata.MAC, dataKey, metadata.LastModified)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt the sops MAC: %v", err)
+	}
+	if !strings.EqualFold(mac, fmt.Sprintf("%X", hash.Sum(nil))) {
+		return nil, errors.New("sops MAC mismatch: the file was modified after it was encrypted")
+	}
+	return yaml.Marshal(doc)
+}
+
+// decryptTree decrypts the values of node in place, in document order, and
+// hashes them for the MAC. The additional data of a value is the path of
+// keys leading to it, each followed by a colon; list items share the path of
+// their list.
+func decryptTree(node *yaml.Node, path []string, key []byte, onlyEncrypted bool, hash func([]byte) (int, error)) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if path == nil && k.Value == metadataKey {
+				continue
+			}
+			if err := decryptTree(v, append(path[:len(path):len(path)], k.Value), key, onlyEncrypted, hash); err != nil {
+				return err
+			}
+			content = append(content, k, v)
+		}
+		node.Content = content
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := decryptTree(item, path, key, onlyEncrypted, hash); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		encrypted := isEncryptedValue(node.Value)
+		if encrypted {
+			value, tag, err := decryptValue(node.Value, key, strings.Join(path, ":")+":")
+			if err != nil {
+				return fmt.Errorf("cannot decrypt %s: %v", strings.Join(path, "."), err)
+			}
+			node.Value, node.Tag, node.Style = value, tag, 0
+		}
+		if (encrypted || !onlyEncrypted) && node.ShortTag() != "!!null" {
+			hash(macBytes(node))
+		}
+	}
+	return nil
+}
+
+func isEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, "ENC[AES256_GCM,") && strings.HasSuffix(value, "]")
+}
+
+// decryptValue decrypts an ENC[AES256_GCM,data:...,iv:...,tag:...,type:...]
+// value and returns it with its YAML tag
+func decryptValue(value string, key []byte, additionalData string) (string, string, error) {
+	if !isEncryptedValue(value) {
+		return "", "", errors.New("not an encrypted value")
+	}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(value[len("ENC[AES256_GCM,"):len(value)-1], ",") {
+		if name, v, ok := strings.Cut(field, ":"); ok {
+			fields[name] = v
+		}
+	}
+	data, err1 := base64.StdEncoding.DecodeString(fields["data"])
+	iv, err2 := base64.StdEncoding.DecodeString(fields["iv"])
+	tag, err3 := base64.StdEncoding.DecodeString(fields["tag"])
+	if err1 != nil || err2 != nil || err3 != nil || len(iv) == 0 {
+		return "", "", errors.New("malformed encrypted value")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", "", errors.New("authentication failed")
+	}
+
+	text := string(plaintext)
+	switch fields["type"] {
+	case "str", "bytes", "comment":
+		return text, "!!str", nil
+	case "int":
+		return text, "!!int", nil
+	case "float":
+		return text, "!!float", nil
+	case "bool":
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid bool %q", text)
+		}
+		return strconv.FormatBool(b), "!!bool", nil
+	}
+	return "", "", fmt.Errorf("unknown value type %q", fields["type"])
+}
+
+// macBytes returns a value as SOPS hashes it for the MAC: numbers in their
+// shortest decimal form and booleans as True and False
+func macBytes(node *yaml.Node) []byte {
+	switch node.ShortTag() {
+	case "!!bool":
+		var b bool
+		if node.Decode(&b) == nil {
+			if b {
+				return []byte("True")
+			}
+			return []byte("False")
+		}
+	case "!!int":
+		var n int
+		if node.Decode(&n) == nil {
+			return []byte(strconv.Itoa(n))
+		}
+	case "!!float":
+		var f float64
+		if node.Decode(&f) == nil {
+			return []byte(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	}
+	return []byte(node.Value)
+}
+
+// documentNode returns the top node of a parsed file
+func documentNode(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	return node
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
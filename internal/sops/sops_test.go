@@ -0,0 +1,245 @@
+package sops_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	agetest "c2sp.org/CCTV/age"
+	"filippo.io/age"
+	"github.com/A2AGateway/a2a-connector/internal/sops"
+	"gopkg.in/yaml.v3"
+)
+
+// The age files were encrypted for testIdentity by an independent
+// implementation of the age format
+const (
+	testIdentity  = "AGE-SECRET-KEY-1ZP5FN0SFSUX7PY8DZJ2FH4ENQP4L936CF5E72WHQS3DCMVSR7P4SRJR3CD"
+	testRecipient = "age1xjntes9h4nuwc2ad8ak84g8uac38qkhv2zlznykz5780dv428ylskgv2pe"
+
+	testAgeConfig = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBHRW5QYWZZTWFONk1vNVFN
+a3UxVXVHREUySGxpQ0k0WFdkeXpHRzdLcXp3Ci9LNmsya2l0dHBLSVlQWEFFV0ZK
+bUlDSVR2V3RxRHQ0cUdHZHFVeWNwcncKLS0tIDNoMm5zWjdYQmxiYWEwNHlHcWJO
+bndRbGRrZ0s5RUZlM1VBQkVkUFAyMDAKAfUV4i1n1J0Tveq5/Dm02MtucIGol7+k
+AZ/YPaI6bcJFgG72qP8CyGhWN1FD8sfOPn6vmTkLZreQdC92zksKqNCG8zo1Czxl
+ppND1UQjhfCV4AaMVGcX
+-----END AGE ENCRYPTED FILE-----
+`
+
+	// testAgeDataKey holds the bytes 0 to 31
+	testAgeDataKey = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBweDZBZGhNb3JFR0VsZG9Q
+MVJqdTRJR3lOeFErS0d2YTh6V0Nad1BEd2hnCmdyWVQ4NlFiZHlVL2ZIdmJ4cU50
+V2R0NXZ0M3V4d1NFc2h0YXRhQWhxdjQKLS0tICsvVWwxYXcyZldueWdCS0RHWFpY
+aUVpM0lYNzFQcUtNTnc2TU9LNFNSMEEKB97JhMO0QStf4aqGBdQDJlaeftZeXOvZ
+M+DwN9PsWQcjid7q5Xspjs9elQMRoOhuLRs1qpjaT8ks4jS5vQ05rw==
+-----END AGE ENCRYPTED FILE-----
+`
+)
+
+func testKeys(t *testing.T) sops.Keys {
+	identities, err := sops.ParseIdentities("# created: 2026-10-16\n" + testIdentity + "\n")
+	if err != nil {
+		t.Fatalf("ParseIdentities failed: %v", err)
+	}
+	return sops.Keys{Identities: identities}
+}
+
+func TestDecryptAge(t *testing.T) {
+	data := []byte(testAgeConfig)
+	if !sops.IsEncrypted(data) || !sops.IsAge(data) || sops.IsSOPS(data) {
+		t.Fatal("Expected an age file")
+	}
+	plaintext, err := sops.Decrypt(context.Background(), data, testKeys(t))
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if string(plaintext) != "adapter:\n  type: rest\n  baseUrl: http://localhost:8081\n" {
+		t.Errorf("Unexpected plaintext %q", plaintext)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	if _, err := sops.Decrypt(context.Background(), data, sops.Keys{Identities: []age.Identity{other}}); err == nil {
+		t.Error("Expected another identity to be rejected")
+	}
+	tampered := strings.Replace(testAgeConfig, "ppND1UQj", "ppND1UQk", 1)
+	if _, err := sops.Decrypt(context.Background(), []byte(tampered), testKeys(t)); err == nil {
+		t.Error("Expected a modified payload to be rejected")
+	}
+	if _, err := sops.ParseIdentities("AGE-SECRET-KEY-1ZP5FN0SFSUX7PY8DZJ2FH4ENQP4L936CF5E72WHQS3DCMVSR7P4SRJR3CE"); err == nil {
+		t.Error("Expected an identity with a bad checksum to be rejected")
+	}
+}
+
+// TestAgeVectors decrypts the age test vectors of the C2SP CCTV project, which
+// cover armor, header, MAC and payload errors; passphrase vectors are skipped
+// as configs are only decrypted with X25519 identities
+func TestAgeVectors(t *testing.T) {
+	files, err := fs.ReadDir(agetest.Vectors, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		name := file.Name()
+		data, err := fs.ReadFile(agetest.Vectors, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header, body, _ := bytes.Cut(data, []byte("\n\n"))
+		var expect, payload, identities string
+		for _, line := range strings.Split(string(header), "\n") {
+			key, value, _ := strings.Cut(line, ": ")
+			switch key {
+			case "expect":
+				expect = value
+			case "payload":
+				payload = value
+			case "identity":
+				identities += value + "\n"
+			case "passphrase", "compressed":
+				identities = ""
+			}
+		}
+		if identities == "" {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			parsed, err := sops.ParseIdentities(identities)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plaintext, err := sops.Decrypt(context.Background(), body, sops.Keys{Identities: parsed})
+			if expect != "success" {
+				if err == nil {
+					t.Errorf("Expected %s, got success", expect)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decrypt failed: %v", err)
+			}
+			if sum := sha256.Sum256(plaintext); hex.EncodeToString(sum[:]) != payload {
+				t.Error("Unexpected payload")
+			}
+		})
+	}
+}
+
+// sopsFile encrypts values like SOPS with the data key 0 to 31: each value
+// with its key path as additional data, and the MAC over all values
+type sopsFile struct {
+	key  []byte
+	hash []byte
+}
+
+// plain returns a value left unencrypted, which is still covered by the MAC
+func (f *sopsFile) plain(value string) string {
+	f.hash = append(f.hash, value...)
+	return value
+}
+
+func (f *sopsFile) enc(value, typ, path string) string {
+	f.hash = append(f.hash, value...)
+	block, _ := aes.NewCipher(f.key)
+	gcm, _ := cipher.NewGCMWithNonceSize(block, 32)
+	iv := make([]byte, 32)
+	rand.Read(iv)
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(path))
+	data, tag := sealed[:len(sealed)-16], sealed[len(sealed)-16:]
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv), base64.StdEncoding.EncodeToString(tag), typ)
+}
+
+func (f *sopsFile) mac(lastModified string) string {
+	sum := sha512.Sum512(f.hash)
+	f.hash = nil
+	return f.enc(fmt.Sprintf("%X", sum), "str", lastModified)
+}
+
+func TestDecryptSOPS(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	f := &sopsFile{key: key}
+	body := fmt.Sprintf(`adapter:
+  type: %s
+  baseUrl: %s
+  auth:
+    password: %s
+mappings:
+  - intentPattern: %s
+    priority: %s
+`, f.enc("rest", "str", "adapter:type:"), f.plain("http://erp.internal"), f.enc("s3cret", "str", "adapter:auth:password:"),
+		f.enc("get.*order", "str", "mappings:intentPattern:"), f.enc("5", "int", "mappings:priority:"))
+	lastModified := "2026-10-16T10:00:00Z"
+	metadata := fmt.Sprintf(`sops:
+  age:
+    - recipient: %s
+      enc: |
+%s
+  lastmodified: "%s"
+  mac: %s
+  version: 3.9.0
+`, testRecipient, indent(testAgeDataKey, "        "), lastModified, f.mac(lastModified))
+	data := []byte(body + metadata)
+
+	if !sops.IsSOPS(data) || sops.IsAge(data) {
+		t.Fatal("Expected a SOPS file")
+	}
+	plaintext, err := sops.Decrypt(context.Background(), data, testKeys(t))
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	var cfg map[string]interface{}
+	if err := yaml.Unmarshal(plaintext, &cfg); err != nil {
+		t.Fatalf("Invalid plaintext: %v", err)
+	}
+	adapter := cfg["adapter"].(map[string]interface{})
+	mapping := cfg["mappings"].([]interface{})[0].(map[string]interface{})
+	if adapter["type"] != "rest" || adapter["auth"].(map[string]interface{})["password"] != "s3cret" ||
+		mapping["intentPattern"] != "get.*order" || mapping["priority"] != 5 || cfg["sops"] != nil {
+		t.Errorf("Unexpected plaintext %s", plaintext)
+	}
+
+	// Values are bound to their path, and the MAC to the set of values
+	moved := bytes.Replace(data, []byte("  baseUrl: http://erp.internal\n"), []byte("  baseUrl: http://evil.example\n"), 1)
+	if _, err := sops.Decrypt(context.Background(), moved, testKeys(t)); err == nil || !strings.Contains(err.Error(), "MAC mismatch") {
+		t.Errorf("Expected a modified value to fail the MAC, got %v", err)
+	}
+	if _, err := sops.Decrypt(context.Background(), data, sops.Keys{}); err == nil || !strings.Contains(err.Error(), "no identity") {
+		t.Errorf("Expected a missing key to be reported, got %v", err)
+	}
+
+	// The data key can come from KMS instead
+	kmsFile := bytes.Replace(data, []byte("  age:\n    - recipient: "+testRecipient), []byte("  kms:\n    - arn: arn:aws:kms:eu-west-1:123456789012:key/erp\n      context: {app: connector}\n      created_at: x"), 1)
+	kmsFile = bytes.Replace(kmsFile, []byte("      enc: |\n"+indent(testAgeDataKey, "        ")), []byte("      enc: "+base64.StdEncoding.EncodeToString([]byte("blob"))), 1)
+	keys := sops.Keys{KMS: func(ctx context.Context, arn string, ciphertext []byte, encryptionContext map[string]string) ([]byte, error) {
+		if arn != "arn:aws:kms:eu-west-1:123456789012:key/erp" || string(ciphertext) != "blob" || encryptionContext["app"] != "connector" {
+			return nil, fmt.Errorf("unexpected request %s %q %v", arn, ciphertext, encryptionContext)
+		}
+		return key, nil
+	}}
+	if _, err := sops.Decrypt(context.Background(), kmsFile, keys); err != nil {
+		t.Errorf("Expected the KMS data key to decrypt the file, got %v", err)
+	}
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i := range lines {
+		lines[i] = prefix + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,98 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DefaultPrompt instructs the model when no prompt is configured
+const DefaultPrompt = "Summarize the result of a database or business system query for the user in a few plain sentences: what was found, notable values and totals. Use only the figures and rows given; the rows may be a sample of the result."
+
+// LLM summarizes with a language model behind an OpenAI-compatible chat
+// completions endpoint
+type LLM struct {
+	Endpoint   string
+	Model      string
+	APIKey     string
+	Prompt     string
+	HTTPClient *http.Client
+}
+
+// Summarize asks the model for a summary of the figures and sample rows of a
+// result
+func (c *LLM) Summarize(ctx context.Context, summary Summary, rows []map[string]interface{}) (string, error) {
+	result, err := json.Marshal(map[string]interface{}{
+		"rowCount":   summary.Rows,
+		"columns":    summary.Columns,
+		"keys":       summary.Keys,
+		"aggregates": summary.Aggregates,
+		"sampleRows": rows,
+	})
+	if err != nil {
+		return "", err
+	}
+	prompt := c.Prompt
+	if prompt == "" {
+		prompt = DefaultPrompt
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"model":       c.Model,
+		"temperature": 0,
+		"messages": []map[string]string{
+			{"role": "system", "content": prompt},
+			{"role": "user", "content": string(result)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("summarizer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("summarizer returned %s", resp.Status)
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return "", fmt.Errorf("failed to decode summarizer response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("summarizer returned no choices")
+	}
+	text := strings.TrimSpace(completion.Choices[0].Message.Content)
+	if text == "" {
+		return "", fmt.Errorf("summarizer returned an empty summary")
+	}
+	return text, nil
+}
@@ -0,0 +1,277 @@
+// Package summary turns tabular results into text for agents: a built-in
+// summary of the row count, the most frequent values of key columns and
+// aggregates of numeric columns, rendered by a template or by a language
+// model behind an OpenAI-compatible endpoint
+package summary
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/logging"
+)
+
+// Summarizer kinds
+const (
+	KindTemplate = "template"
+	KindLLM      = "llm"
+)
+
+// DefaultTimeout limits a language model call by default
+const DefaultTimeout = 20 * time.Second
+
+// DefaultMaxRows is the number of rows sent to a language model by default
+const DefaultMaxRows = 20
+
+// maxKeyValues is the number of most frequent values listed per key column
+const maxKeyValues = 5
+
+// Summary holds the figures of a result, which templates render
+type Summary struct {
+	Rows       int
+	Columns    []string
+	Keys       []Key
+	Aggregates []Aggregate
+}
+
+// Key is a key column with its most frequent values, most frequent first;
+// Distinct counts all its values
+type Key struct {
+	Column   string
+	Distinct int
+	Values   []ValueCount
+}
+
+// ValueCount is a value of a key column and the number of rows holding it
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// Aggregate holds the figures of a numeric column over the Count rows
+// where it is a number
+type Aggregate struct {
+	Column string
+	Count  int
+	Sum    float64
+	Min    float64
+	Max    float64
+	Avg    float64
+}
+
+// Summarizer summarizes the rows of results
+type Summarizer struct {
+	KeyColumns []string
+	Aggregates []string
+	// Template renders the Summary; nil writes the built-in text
+	Template *template.Template
+	// LLM writes the summary instead of the template when set
+	LLM     *LLM
+	MaxRows int
+	Timeout time.Duration
+}
+
+// New creates a summarizer from config
+func New(cfg *config.SummaryConfig) (*Summarizer, error) {
+	s := &Summarizer{
+		KeyColumns: cfg.KeyColumns,
+		Aggregates: cfg.Aggregates,
+		Template:   cfg.CompiledTemplate,
+		MaxRows:    DefaultMaxRows,
+		Timeout:    DefaultTimeout,
+	}
+	if cfg.MaxRows > 0 {
+		s.MaxRows = cfg.MaxRows
+	}
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid summary timeout: %w", err)
+		}
+		s.Timeout = d
+	}
+	switch cfg.Summarizer {
+	case "", KindTemplate:
+	case KindLLM:
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("summarizer llm requires an endpoint")
+		}
+		s.LLM = &LLM{Endpoint: cfg.Endpoint, Model: cfg.Model, APIKey: cfg.APIKey, Prompt: cfg.Prompt}
+	default:
+		return nil, fmt.Errorf("unsupported summarizer %q", cfg.Summarizer)
+	}
+	return s, nil
+}
+
+// Summarize returns the text summarizing rows. A failed language model call
+// is logged and the template summary returned instead.
+func (s *Summarizer) Summarize(ctx context.Context, rows []map[string]interface{}) (string, error) {
+	summary := Compute(rows, s.KeyColumns, s.Aggregates)
+	if s.LLM != nil {
+		sample := rows
+		if len(sample) > s.MaxRows {
+			sample = sample[:s.MaxRows]
+		}
+		ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+		text, err := s.LLM.Summarize(ctx, summary, sample)
+		if err == nil {
+			return text, nil
+		}
+		slog.Warn("summarizer llm failed; using the template summary", logging.KeyError, err)
+	}
+	if s.Template == nil {
+		return summary.Text(), nil
+	}
+	var buf bytes.Buffer
+	if err := s.Template.Execute(&buf, summary); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Rows returns value as rows if it is a list of objects
+func Rows(value interface{}) ([]map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case []map[string]interface{}:
+		return v, true
+	case []interface{}:
+		rows := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			rows = append(rows, row)
+		}
+		return rows, true
+	}
+	return nil, false
+}
+
+// Compute computes the figures of rows: the value counts of keyColumns and
+// the aggregates of the numeric aggregates columns
+func Compute(rows []map[string]interface{}, keyColumns, aggregates []string) Summary {
+	summary := Summary{Rows: len(rows)}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for column := range row {
+			if !seen[column] {
+				seen[column] = true
+				summary.Columns = append(summary.Columns, column)
+			}
+		}
+	}
+	sort.Strings(summary.Columns)
+
+	for _, column := range keyColumns {
+		counts := make(map[string]int)
+		for _, row := range rows {
+			if value, ok := row[column]; ok && value != nil {
+				counts[fmt.Sprint(value)]++
+			}
+		}
+		key := Key{Column: column, Distinct: len(counts)}
+		for value, count := range counts {
+			key.Values = append(key.Values, ValueCount{Value: value, Count: count})
+		}
+		sort.Slice(key.Values, func(i, j int) bool {
+			a, b := key.Values[i], key.Values[j]
+			return a.Count > b.Count || a.Count == b.Count && a.Value < b.Value
+		})
+		if len(key.Values) > maxKeyValues {
+			key.Values = key.Values[:maxKeyValues]
+		}
+		summary.Keys = append(summary.Keys, key)
+	}
+
+	for _, column := range aggregates {
+		aggregate := Aggregate{Column: column}
+		for _, row := range rows {
+			n, ok := number(row[column])
+			if !ok {
+				continue
+			}
+			if aggregate.Count == 0 || n < aggregate.Min {
+				aggregate.Min = n
+			}
+			if aggregate.Count == 0 || n > aggregate.Max {
+				aggregate.Max = n
+			}
+			aggregate.Sum += n
+			aggregate.Count++
+		}
+		if aggregate.Count > 0 {
+			aggregate.Avg = aggregate.Sum / float64(aggregate.Count)
+		}
+		summary.Aggregates = append(summary.Aggregates, aggregate)
+	}
+	return summary
+}
+
+// Text is the built-in summary, a sentence per figure
+func (s Summary) Text() string {
+	var b strings.Builder
+	if s.Rows == 1 {
+		b.WriteString("1 row.")
+	} else {
+		fmt.Fprintf(&b, "%d rows.", s.Rows)
+	}
+	for _, key := range s.Keys {
+		if len(key.Values) == 0 {
+			continue
+		}
+		values := make([]string, len(key.Values))
+		for i, v := range key.Values {
+			values[i] = fmt.Sprintf("%s (%d)", v.Value, v.Count)
+		}
+		fmt.Fprintf(&b, " %s: %s", key.Column, strings.Join(values, ", "))
+		if more := key.Distinct - len(key.Values); more > 0 {
+			fmt.Fprintf(&b, " and %d more", more)
+		}
+		b.WriteString(".")
+	}
+	for _, a := range s.Aggregates {
+		if a.Count == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, " %s: total %s, min %s, max %s, average %s.", a.Column,
+			formatNumber(a.Sum), formatNumber(a.Min), formatNumber(a.Max), formatNumber(a.Avg))
+	}
+	return b.String()
+}
+
+// number returns value as a number; numeric strings, as drivers return
+// decimals, count as numbers
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// formatNumber formats n with at most two decimals
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(math.Round(n*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,88 @@
+package summary_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/summary"
+)
+
+var orders = []map[string]interface{}{
+	{"ID": int64(1), "REGION": "EU", "AMOUNT": "120.50"},
+	{"ID": int64(2), "REGION": "US", "AMOUNT": 80.0},
+	{"ID": int64(3), "REGION": "EU", "AMOUNT": 99.5},
+	{"ID": int64(4), "REGION": nil, "AMOUNT": nil},
+}
+
+func TestTemplateSummary(t *testing.T) {
+	s, err := summary.New(&config.SummaryConfig{KeyColumns: []string{"REGION"}, Aggregates: []string{"AMOUNT"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := s.Summarize(context.Background(), orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "4 rows. REGION: EU (2), US (1). AMOUNT: total 300, min 80, max 120.5, average 100."
+	if text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+
+	cfg := &config.SummaryConfig{
+		Template:   "{{.Rows}} orders{{range .Aggregates}}, {{.Column}} {{.Sum}}{{end}}",
+		Aggregates: []string{"AMOUNT"},
+	}
+	if err := (&config.ConnectorConfig{Mappings: []config.MappingConfig{{ResponseTransform: config.ResponseTransform{Summary: cfg}}}}).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = summary.New(cfg)
+	if text, _ := s.Summarize(context.Background(), orders); text != "4 orders, AMOUNT 300" {
+		t.Errorf("Unexpected templated summary %q", text)
+	}
+}
+
+func TestLLMSummary(t *testing.T) {
+	var sent struct {
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"choices":[{"message":{"content":" Four orders, mostly from the EU. "}}]}`))
+	}))
+	defer server.Close()
+
+	s, err := summary.New(&config.SummaryConfig{Summarizer: "llm", Endpoint: server.URL, MaxRows: 2, Aggregates: []string{"AMOUNT"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := s.Summarize(context.Background(), orders)
+	if err != nil || text != "Four orders, mostly from the EU." {
+		t.Fatalf("Unexpected summary %q, %v", text, err)
+	}
+	var result struct {
+		RowCount   int                      `json:"rowCount"`
+		SampleRows []map[string]interface{} `json:"sampleRows"`
+	}
+	json.Unmarshal([]byte(sent.Messages[1].Content), &result)
+	if sent.Messages[0].Content != summary.DefaultPrompt || result.RowCount != 4 || len(result.SampleRows) != 2 {
+		t.Errorf("Unexpected request %+v", sent)
+	}
+
+	// A failed call falls back to the built-in summary
+	fail = true
+	if text, err := s.Summarize(context.Background(), orders); err != nil || !strings.HasPrefix(text, "4 rows.") {
+		t.Errorf("Expected the built-in summary, got %q, %v", text, err)
+	}
+}
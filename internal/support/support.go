@@ -0,0 +1,290 @@
+// Package support builds support bundles: redacted archives of the config,
+// version, environment and recent errors of a connector that customers
+// attach to support tickets
+package support
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/kube"
+	"github.com/A2AGateway/a2a-connector/internal/secrets"
+)
+
+// ManifestFile lists the other files of a bundle
+const ManifestFile = "manifest.json"
+
+// DefaultLogLines is the number of recent warnings and errors kept from a log
+const DefaultLogLines = 200
+
+// proxyEnv are the environment variables that route outbound traffic
+var proxyEnv = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+// Bundle writes a gzipped tar archive. Every file is redacted: secret values
+// and credential patterns in text, and also the values of credential fields
+// in JSON.
+type Bundle struct {
+	gz      *gzip.Writer
+	tw      *tar.Writer
+	created time.Time
+	files   []string
+}
+
+// NewBundle creates a bundle writing to w
+func NewBundle(w io.Writer) *Bundle {
+	gz := gzip.NewWriter(w)
+	return &Bundle{gz: gz, tw: tar.NewWriter(gz), created: time.Now().UTC()}
+}
+
+// Add adds a text file
+func (b *Bundle) Add(name string, data []byte) error {
+	return b.write(name, []byte(secrets.Redact(string(data))))
+}
+
+// AddJSON adds a JSON file with the redacted value of v
+func (b *Bundle) AddJSON(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(secrets.RedactValue(value), "", "  ")
+	if err != nil {
+		return err
+	}
+	return b.write(name, append(data, '\n'))
+}
+
+func (b *Bundle) write(name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: b.created, Typeflag: tar.TypeReg}
+	if err := b.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := b.tw.Write(data); err != nil {
+		return err
+	}
+	b.files = append(b.files, name)
+	return nil
+}
+
+// Close adds the manifest and finishes the archive
+func (b *Bundle) Close() error {
+	files := append([]string(nil), b.files...)
+	if err := b.AddJSON(ManifestFile, map[string]interface{}{
+		"created": b.created.Format(time.RFC3339),
+		"files":   files,
+	}); err != nil {
+		return err
+	}
+	if err := b.tw.Close(); err != nil {
+		return err
+	}
+	return b.gz.Close()
+}
+
+// Version describes the connector build
+type Version struct {
+	Version   string            `json:"version"`
+	Revision  string            `json:"revision,omitempty"`
+	Time      string            `json:"time,omitempty"`
+	Modified  bool              `json:"modified,omitempty"`
+	GoVersion string            `json:"goVersion"`
+	Deps      map[string]string `json:"dependencies,omitempty"`
+}
+
+// BuildVersion returns the version of the running binary from its build info
+func BuildVersion() Version {
+	v := Version{Version: "unknown", GoVersion: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	v.Version = info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			v.Revision = setting.Value
+		case "vcs.time":
+			v.Time = setting.Value
+		case "vcs.modified":
+			v.Modified = setting.Value == "true"
+		}
+	}
+	if len(info.Deps) > 0 {
+		v.Deps = make(map[string]string, len(info.Deps))
+		for _, dep := range info.Deps {
+			v.Deps[dep.Path] = dep.Version
+		}
+	}
+	return v
+}
+
+// Fingerprint describes the host the connector runs on
+type Fingerprint struct {
+	OS         string            `json:"os"`
+	Arch       string            `json:"arch"`
+	Kernel     string            `json:"kernel,omitempty"`
+	CPUs       int               `json:"cpus"`
+	Hostname   string            `json:"hostname,omitempty"`
+	Timezone   string            `json:"timezone"`
+	Runtime    string            `json:"runtime"`
+	Pod        map[string]string `json:"pod,omitempty"`
+	Proxy      map[string]string `json:"proxy,omitempty"`
+	Interfaces []Interface       `json:"interfaces,omitempty"`
+}
+
+// Interface is a network interface of the host
+type Interface struct {
+	Name  string   `json:"name"`
+	Flags string   `json:"flags"`
+	MTU   int      `json:"mtu"`
+	Addrs []string `json:"addrs,omitempty"`
+}
+
+// TakeFingerprint describes the current host: OS, kernel, CPUs, time zone,
+// whether it runs in a container or pod, outbound proxies and network
+// interfaces
+func TakeFingerprint() Fingerprint {
+	fp := Fingerprint{OS: runtime.GOOS, Arch: runtime.GOARCH, CPUs: runtime.NumCPU(), Runtime: "host"}
+	fp.Hostname, _ = os.Hostname()
+	if data, err := ioutil.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		fp.Kernel = strings.TrimSpace(string(data))
+	}
+	zone, offset := time.Now().Zone()
+	fp.Timezone = (time.Duration(offset) * time.Second).String()
+	if zone != "" {
+		fp.Timezone = zone + " " + fp.Timezone
+	}
+
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		fp.Runtime = "container"
+	}
+	if kube.InCluster() {
+		fp.Runtime = "kubernetes"
+		pod := kube.PodFromEnv()
+		fp.Pod = map[string]string{"name": pod.Name, "namespace": pod.Namespace, "node": pod.Node, "ip": pod.IP}
+	}
+
+	for _, name := range proxyEnv {
+		if value := os.Getenv(name); value != "" {
+			if fp.Proxy == nil {
+				fp.Proxy = make(map[string]string)
+			}
+			fp.Proxy[name] = value
+		}
+	}
+
+	ifaces, _ := net.Interfaces()
+	for _, iface := range ifaces {
+		entry := Interface{Name: iface.Name, Flags: iface.Flags.String(), MTU: iface.MTU}
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			entry.Addrs = append(entry.Addrs, addr.String())
+		}
+		fp.Interfaces = append(fp.Interfaces, entry)
+	}
+	return fp
+}
+
+// Reachability is the outcome of resolving and connecting to an endpoint
+type Reachability struct {
+	Address   string   `json:"address"`
+	Resolved  []string `json:"resolved,omitempty"`
+	DNSMs     float64  `json:"dnsMs,omitempty"`
+	ConnectMs float64  `json:"connectMs,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Probe resolves the host of endpoint, a URL or host:port, and opens a TCP
+// connection to it
+func Probe(ctx context.Context, endpoint string) Reachability {
+	address := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		address = u.Host
+		if u.Port() == "" {
+			port := "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+			address = net.JoinHostPort(u.Hostname(), port)
+		}
+	}
+	r := Reachability{Address: address}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	start := time.Now()
+	resolved, err := net.DefaultResolver.LookupHost(ctx, host)
+	r.DNSMs = milliseconds(time.Since(start))
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Resolved = resolved
+
+	start = time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.ConnectMs = milliseconds(time.Since(start))
+	conn.Close()
+	return r
+}
+
+// RecentLogErrors returns the last max warning and error lines of a console
+// or JSON connector log
+func RecentLogErrors(r io.Reader, max int) ([]string, error) {
+	if max <= 0 {
+		max = DefaultLogLines
+	}
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !isWarning(line) {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) > max {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// isWarning reports whether a log line of the text or JSON handler is a
+// warning or an error
+func isWarning(line string) bool {
+	for _, level := range []string{"WARN", "ERROR"} {
+		if strings.Contains(line, "level="+level) || strings.Contains(line, `"level":"`+level+`"`) {
+			return true
+		}
+	}
+	return false
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,82 @@
+// Package tlsclient builds the TLS settings of outbound connections to legacy systems
+package tlsclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// New builds a client TLS config; nil config keeps the system defaults and returns nil.
+// The CA bundle is added to the system roots so public endpoints keep working.
+func New(cfg *config.ClientTLSConfig) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.InsecureSkipVerify {
+		slog.Warn("INSECURE: TLS certificate verification toward the legacy system is disabled; connections can be intercepted. Set caFile instead outside of testing")
+		tlsConfig.InsecureSkipVerify = true
+	}
+	return tlsConfig, nil
+}
+
+// Transport returns a clone of base (or the default transport) using tlsConfig
+func Transport(base http.RoundTripper, tlsConfig *tls.Config) (*http.Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("cannot set TLS on transport %T", base)
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// Apply sets tlsConfig on the client's transport; nil tlsConfig leaves the client unchanged
+func Apply(client *http.Client, tlsConfig *tls.Config) error {
+	if tlsConfig == nil {
+		return nil
+	}
+	transport, err := Transport(client.Transport, tlsConfig)
+	if err != nil {
+		return err
+	}
+	client.Transport = transport
+	return nil
+}
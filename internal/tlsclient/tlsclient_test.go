@@ -0,0 +1,81 @@
+package tlsclient_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+	"github.com/A2AGateway/a2a-connector/internal/tlsclient"
+)
+
+func TestOutboundMutualTLS(t *testing.T) {
+	// The legacy system requires a client certificate
+	legacy := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	legacy.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	legacy.StartTLS()
+	defer legacy.Close()
+
+	// The test server's certificate doubles as the private CA and client certificate
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	serverCert := legacy.TLS.Certificates[0]
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: legacy.Certificate().Raw}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600)
+
+	ping := func(cfg *config.ClientTLSConfig) error {
+		tlsConfig, err := tlsclient.New(cfg)
+		if err != nil {
+			t.Fatalf("Failed to build TLS config: %v", err)
+		}
+		rest := adapter.NewRESTAdapter("legacy", legacy.URL, nil, nil)
+		if err := rest.SetTLSConfig(tlsConfig); err != nil {
+			t.Fatalf("Failed to set TLS config: %v", err)
+		}
+		return rest.Ping(context.Background())
+	}
+
+	if err := ping(nil); err == nil {
+		t.Error("Expected the private CA to be untrusted by default")
+	}
+	if err := ping(&config.ClientTLSConfig{CAFile: caFile}); err == nil {
+		t.Error("Expected the handshake to fail without a client certificate")
+	}
+	mutual := &config.ClientTLSConfig{CAFile: caFile, CertFile: caFile, KeyFile: keyFile}
+	if err := ping(mutual); err != nil {
+		t.Errorf("Expected mutual TLS with the private CA to succeed, got %v", err)
+	}
+	if err := ping(&config.ClientTLSConfig{InsecureSkipVerify: true, CertFile: caFile, KeyFile: keyFile}); err != nil {
+		t.Errorf("Expected insecure mode to skip verification, got %v", err)
+	}
+
+	// The proxy forwards with the same settings
+	tlsConfig, _ := tlsclient.New(mutual)
+	p, err := proxy.NewProxy(legacy.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetTLSConfig(tlsConfig); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected proxied request to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
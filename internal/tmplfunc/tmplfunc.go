@@ -0,0 +1,255 @@
+// Package tmplfunc provides the functions available in response templates,
+// modelled on the common subset of sprig so that summaries of legacy
+// responses can format dates and numbers, fall back to defaults and embed
+// JSON
+package tmplfunc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// FuncMap returns the template functions. Functions taking the value to
+// format last work in pipelines, e.g. {{.result.total | number 2}}.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		// Defaults and conditionals
+		"default":  defaultValue,
+		"coalesce": coalesce,
+		"empty":    empty,
+		"ternary":  ternary,
+
+		// Strings
+		"upper":     func(v interface{}) string { return strings.ToUpper(toString(v)) },
+		"lower":     func(v interface{}) string { return strings.ToLower(toString(v)) },
+		"title":     title,
+		"trim":      func(v interface{}) string { return strings.TrimSpace(toString(v)) },
+		"trunc":     trunc,
+		"replace":   func(old, new string, v interface{}) string { return strings.ReplaceAll(toString(v), old, new) },
+		"contains":  func(substr string, v interface{}) bool { return strings.Contains(toString(v), substr) },
+		"hasPrefix": func(prefix string, v interface{}) bool { return strings.HasPrefix(toString(v), prefix) },
+		"hasSuffix": func(suffix string, v interface{}) bool { return strings.HasSuffix(toString(v), suffix) },
+		"join":      join,
+		"split":     func(sep string, v interface{}) []string { return strings.Split(toString(v), sep) },
+		"toString":  toString,
+
+		// Numbers
+		"number":  formatNumber,
+		"round":   round,
+		"add":     func(a, b interface{}) float64 { return toFloat(a) + toFloat(b) },
+		"sub":     func(a, b interface{}) float64 { return toFloat(a) - toFloat(b) },
+		"mul":     func(a, b interface{}) float64 { return toFloat(a) * toFloat(b) },
+		"div":     div,
+		"toFloat": toFloat,
+		"toInt":   func(v interface{}) int { return int(toFloat(v)) },
+
+		// Dates
+		"now":  time.Now,
+		"date": date,
+
+		// JSON and XML
+		"toJson":       toJSON,
+		"toPrettyJson": toPrettyJSON,
+		"xml":          xmlEscape,
+	}
+}
+
+// defaultValue returns value, or def when value is empty
+func defaultValue(def, value interface{}) interface{} {
+	if empty(value) {
+		return def
+	}
+	return value
+}
+
+// coalesce returns the first non-empty value
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !empty(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+// empty reports whether a value is nil, false, zero or has no elements
+func empty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+func ternary(yes, no interface{}, cond bool) interface{} {
+	if cond {
+		return yes
+	}
+	return no
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func title(value interface{}) string {
+	words := strings.Fields(strings.ToLower(toString(value)))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// trunc shortens a string to n characters
+func trunc(n int, value interface{}) string {
+	runes := []rune(toString(value))
+	if n < 0 || len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n])
+}
+
+// join joins the elements of a list with sep
+func join(sep string, list interface{}) string {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return toString(list)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = toString(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+// toFloat converts numbers and numeric strings; anything else is 0
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+func round(decimals int, value interface{}) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(toFloat(value)*scale) / scale
+}
+
+func div(a, b interface{}) (float64, error) {
+	divisor := toFloat(b)
+	if divisor == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	return toFloat(a) / divisor, nil
+}
+
+// formatNumber formats a number with the given decimals and thousands
+// separators, e.g. number 2 1234.5 is "1,234.50"
+func formatNumber(decimals int, value interface{}) string {
+	s := strconv.FormatFloat(toFloat(value), 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String() + fraction
+}
+
+// dateLayouts are the layouts date parses strings with
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02", "20060102"}
+
+// date formats a time with a Go layout, e.g. date "02 Jan 2006" .result.shipped.
+// It accepts times, RFC 3339 and ISO dates, and Unix timestamps in seconds;
+// other values are returned unchanged.
+func date(layout string, value interface{}) string {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case float64:
+		t = time.Unix(int64(v), 0).UTC()
+	case int64:
+		t = time.Unix(v, 0).UTC()
+	case string:
+		parsed := false
+		for _, l := range dateLayouts {
+			if p, err := time.Parse(l, v); err == nil {
+				t, parsed = p, true
+				break
+			}
+		}
+		if !parsed {
+			return v
+		}
+	default:
+		return toString(value)
+	}
+	return t.Format(layout)
+}
+
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// xmlEscape escapes a value for XML text and attribute values
+func xmlEscape(v interface{}) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(toString(v)))
+	return b.String()
+}
+
+func toPrettyJSON(value interface{}) (string, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	return string(data), err
+}
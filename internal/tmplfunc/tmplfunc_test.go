@@ -0,0 +1,57 @@
+package tmplfunc_test
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/A2AGateway/a2a-connector/internal/tmplfunc"
+)
+
+func TestFuncMap(t *testing.T) {
+	data := map[string]interface{}{
+		"result": map[string]interface{}{
+			"name":    "acme corp",
+			"total":   1234567.891,
+			"shipped": "2024-03-05T14:30:00Z",
+			"created": float64(1700000000),
+			"tags":    []interface{}{"new", "vip"},
+			"note":    "",
+		},
+	}
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{`{{.result.name | title}}`, "Acme Corp"},
+		{`{{.result.name | upper}}`, "ACME CORP"},
+		{`{{.result.note | default "none"}}`, "none"},
+		{`{{.result.missing | default "n/a"}}`, "n/a"},
+		{`{{coalesce .result.note .result.name}}`, "acme corp"},
+		{`{{.result.tags | join ", "}}`, "new, vip"},
+		{`{{.result.total | number 2}}`, "1,234,567.89"},
+		{`{{-5 | number 0}}`, "-5"},
+		{`{{3.14159 | round 2}}`, "3.14"},
+		{`{{div .result.total 1000 | number 1}}`, "1,234.6"},
+		{`{{.result.shipped | date "02 Jan 2006 15:04"}}`, "05 Mar 2024 14:30"},
+		{`{{.result.created | date "2006-01-02"}}`, "2023-11-14"},
+		{`{{.result.tags | toJson}}`, `["new","vip"]`},
+		{`<n>{{"Smith & <Sons>" | xml}}</n>`, `<n>Smith &amp; &lt;Sons&gt;</n>`},
+		{`{{ternary "yes" "no" (empty .result.note)}}`, "yes"},
+		{`{{.result.name | trunc 4}}`, "acme"},
+	}
+	for _, tt := range tests {
+		tmpl, err := template.New("test").Funcs(tmplfunc.FuncMap()).Parse(tt.template)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.template, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			t.Errorf("%s: %v", tt.template, err)
+			continue
+		}
+		if b.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.template, tt.expected, b.String())
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package xmlmap converts between XML documents and generic maps
+package xmlmap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/A2AGateway/a2a-connector/internal/charset"
+)
+
+// AttrPrefix is prepended to attribute names in decoded maps
+const AttrPrefix = "@"
+
+// TextKey holds the character data of elements that also have attributes or children
+const TextKey = "#text"
+
+// Decode reads an XML document and returns it as a map keyed by the root element name.
+//
+// Elements without attributes or children become strings, repeated elements become
+// lists, attributes are stored under "@name", and text next to attributes or
+// children is stored under "#text". Namespace prefixes are dropped.
+func Decode(r io.Reader) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReader
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("empty XML document")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing XML: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := decodeElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: value}, nil
+		}
+	}
+}
+
+// decodeElement decodes the content of an element whose start tag has been read
+func decodeElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	result := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		result[AttrPrefix+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	hasChildren := false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			hasChildren = true
+			child, err := decodeElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			addChild(result, t.Name.Local, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if !hasChildren && len(result) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				result[TextKey] = content
+			}
+			return result, nil
+		}
+	}
+}
+
+// addChild adds a child value, turning repeated elements into a list
+func addChild(parent map[string]interface{}, name string, value interface{}) {
+	existing, ok := parent[name]
+	if !ok {
+		parent[name] = value
+		return
+	}
+	if list, ok := existing.([]interface{}); ok {
+		parent[name] = append(list, value)
+		return
+	}
+	parent[name] = []interface{}{existing, value}
+}
+
+// Encode writes a map in the shape returned by Decode as an XML document. The
+// map must hold a single key, the root element name.
+//
+// Keys starting with "@" become attributes and "#text" the character data of
+// its element; lists become repeated elements. Other keys are written in
+// sorted order so the output is stable.
+func Encode(doc map[string]interface{}) ([]byte, error) {
+	if len(doc) != 1 {
+		return nil, fmt.Errorf("XML document needs a single root element, got %d", len(doc))
+	}
+	var buf bytes.Buffer
+	for name, value := range doc {
+		if err := encodeElement(&buf, name, value); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeElement writes an element, or one element per item of a list
+func encodeElement(buf *bytes.Buffer, name string, value interface{}) error {
+	if !validName(name) {
+		return fmt.Errorf("invalid XML element name %q", name)
+	}
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if err := encodeElement(buf, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	fields, isMap := value.(map[string]interface{})
+	if !isMap {
+		buf.WriteString("<" + name + ">")
+		if value != nil {
+			xml.EscapeText(buf, []byte(fmt.Sprint(value)))
+		}
+		buf.WriteString("</" + name + ">")
+		return nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("<" + name)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, AttrPrefix) {
+			continue
+		}
+		attr := strings.TrimPrefix(key, AttrPrefix)
+		if !validName(attr) {
+			return fmt.Errorf("invalid XML attribute name %q", attr)
+		}
+		buf.WriteString(" " + attr + `="`)
+		xml.EscapeText(buf, []byte(fmt.Sprint(fields[key])))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	if text, ok := fields[TextKey]; ok && text != nil {
+		xml.EscapeText(buf, []byte(fmt.Sprint(text)))
+	}
+	for _, key := range keys {
+		if key == TextKey || strings.HasPrefix(key, AttrPrefix) {
+			continue
+		}
+		if err := encodeElement(buf, key, fields[key]); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("</" + name + ">")
+	return nil
+}
+
+// validName reports whether name can be written as an element or attribute
+// name as is
+func validName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_' || r == ':' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
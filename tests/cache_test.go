@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+// countingAdapter counts calls that reach the backend
+type countingAdapter struct {
+	MockAdapter
+	calls int
+}
+
+func (c *countingAdapter) ExecuteTask(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	c.calls++
+	return c.MockAdapter.ExecuteTask(action, params)
+}
+
+func TestCachingAdapter(t *testing.T) {
+	backend := &countingAdapter{}
+	cached := adapter.NewCachingAdapter(backend, cache.NewLRU(10))
+
+	ctx := adapter.WithCacheEntry(context.Background(), adapter.CacheEntry{Key: "product|42", TTL: time.Minute})
+	for i := 0; i < 3; i++ {
+		result, err := cached.ExecuteTaskContext(ctx, "GET", map[string]interface{}{"id": "42"})
+		if err != nil || result["result"] != "mock_result" {
+			t.Fatalf("Unexpected result %v, %v", result, err)
+		}
+	}
+	if backend.calls != 1 {
+		t.Errorf("Expected 1 backend call, got %d", backend.calls)
+	}
+
+	// Calls without a cache entry always reach the backend
+	cached.ExecuteTaskContext(context.Background(), "GET", nil)
+	if backend.calls != 2 {
+		t.Errorf("Expected uncached call to reach the backend, got %d calls", backend.calls)
+	}
+}
+
+func TestMappingCacheKey(t *testing.T) {
+	cfg := &config.ConnectorConfig{
+		Adapter: config.AdapterConfig{Type: "rest", BaseURL: "http://example.com"},
+		Mappings: []config.MappingConfig{{
+			IntentPattern: "product",
+			Endpoint:      "/products/{sku}",
+			Method:        "GET",
+			ParameterMappings: []config.ParameterMapping{
+				{Source: "text", Pattern: `product (\w+)`, Target: "sku"},
+			},
+			Cache: &config.MappingCacheConfig{Enabled: true, TTL: "5m", Key: "sku={sku}"},
+		}},
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Invalid config: %v", err)
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile config: %v", err)
+	}
+
+	ct := proxy.NewConfigTransformer(cfg)
+	task := `{"id":"task-1","status":{"message":{"role":"user","parts":[{"type":"text","text":"Show product ABC123"}]}}}`
+	data, err := ct.TransformRequestData([]byte(task))
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	var req map[string]interface{}
+	json.Unmarshal(data, &req)
+	entry, _ := req["meta"].(map[string]interface{})["cache"].(map[string]interface{})
+	if entry["key"] != "product|sku=ABC123" || entry["ttl"] != "5m" {
+		t.Errorf("Unexpected cache entry: %v", entry)
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+)
+
+// kinds lists the kinds of events, oldest first
+func kinds(events []adapter.ConnEvent) []string {
+	var result []string
+	for i := len(events) - 1; i >= 0; i-- {
+		result = append(result, events[i].Kind)
+	}
+	return result
+}
+
+func TestTCPConnectionEvents(t *testing.T) {
+	framing := adapter.Framing{Mode: adapter.FramingDelimiter, Delimiter: []byte("\n")}
+	addr, _ := startSocketServer(t, framing, func(request []byte) []byte { return request })
+
+	tcp := adapter.NewTCPAdapter("mainframe", addr, framing, nil)
+	tcp.Events = adapter.NewConnEventLog(10)
+	tcp.Persistent = true
+	if err := tcp.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tcp.ExecuteTask("", map[string]interface{}{"op": "ping"}); err != nil {
+		t.Fatalf("ExecuteTask: %v", err)
+	}
+	tcp.Close()
+
+	events := tcp.Events.Events("mainframe")
+	if got := kinds(events); len(got) != 2 || got[0] != adapter.EventConnect || got[1] != adapter.EventDisconnect {
+		t.Fatalf("Unexpected events %v", got)
+	}
+	if events[1].Open != 1 || events[1].Address != addr || events[0].Open != 0 {
+		t.Errorf("Unexpected connect event %+v", events[1])
+	}
+
+	// Failing to connect is an error event
+	down := adapter.NewTCPAdapter("mainframe", "127.0.0.1:1", framing, nil)
+	down.Events = tcp.Events
+	down.Initialize()
+	if _, err := down.ExecuteTask("", map[string]interface{}{"op": "ping"}); err == nil {
+		t.Fatal("Expected a connection error")
+	}
+	if event := tcp.Events.Events("mainframe")[0]; event.Kind != adapter.EventError || event.Detail != "connect" || event.Error == "" {
+		t.Errorf("Unexpected error event %+v", event)
+	}
+}
+
+func TestHTTPConnectionEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("crm", server.URL, map[string]string{}, nil)
+	rest.Events = adapter.NewConnEventLog(10)
+	rest.ExecuteTask("/a", map[string]interface{}{})
+	rest.Headers["Authorization"] = "Bearer t"
+	if _, err := rest.ExecuteTask("/b", map[string]interface{}{}); err != nil {
+		t.Fatalf("ExecuteTask: %v", err)
+	}
+
+	// The keep-alive connection is reused; the rejected call is an auth event
+	if got := kinds(rest.Events.Events("")); len(got) != 2 || got[0] != adapter.EventConnect || got[1] != adapter.EventAuth {
+		t.Errorf("Unexpected events %v", got)
+	}
+	rest.Events.Clear("crm")
+	if adapters := rest.Events.Adapters(); len(adapters) != 0 {
+		t.Errorf("Expected no adapters after clearing, got %v", adapters)
+	}
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+type fakeAQMessage struct {
+	payload     []byte
+	correlation string
+}
+
+// fakeAQ is a database/sql driver running the AQ blocks of the db adapter
+// against an in-memory queue. It reads the binds in the order the blocks
+// declare them.
+type fakeAQ struct {
+	messages []fakeAQMessage
+	enqueued int
+}
+
+func (d *fakeAQ) Open(string) (driver.Conn, error) { return &fakeAQConn{d: d}, nil }
+
+type fakeAQConn struct{ d *fakeAQ }
+
+func (c *fakeAQConn) Prepare(string) (driver.Stmt, error)      { return nil, driver.ErrSkip }
+func (c *fakeAQConn) Close() error                             { return nil }
+func (c *fakeAQConn) Begin() (driver.Tx, error)                { return nil, driver.ErrSkip }
+func (c *fakeAQConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+func (c *fakeAQConn) Ping(context.Context) error               { return nil }
+
+func (c *fakeAQConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.Contains(query, "DBMS_AQ.ENQUEUE("):
+		correlation, _ := args[0].Value.(string)
+		c.d.enqueued++
+		c.d.messages = append(c.d.messages, fakeAQMessage{payload: args[5].Value.([]byte), correlation: correlation})
+		*args[6].Value.(sql.Out).Dest.(*sql.NullString) = sql.NullString{String: fmt.Sprintf("%032X", c.d.enqueued), Valid: true}
+	case strings.Contains(query, "DBMS_AQ.DEQUEUE("):
+		selector, _ := args[1].Value.(string)
+		for i, msg := range c.d.messages {
+			if selector != "" && selector != msg.correlation {
+				continue
+			}
+			c.d.messages = append(c.d.messages[:i], c.d.messages[i+1:]...)
+			*args[4].Value.(sql.Out).Dest.(*[]byte) = msg.payload
+			*args[5].Value.(sql.Out).Dest.(*sql.NullString) = sql.NullString{String: "MSG", Valid: true}
+			*args[6].Value.(sql.Out).Dest.(*sql.NullString) = sql.NullString{String: msg.correlation, Valid: true}
+			*args[7].Value.(sql.Out).Dest.(*sql.NullFloat64) = sql.NullFloat64{Float64: 1, Valid: true}
+			*args[8].Value.(sql.Out).Dest.(*sql.NullFloat64) = sql.NullFloat64{Valid: true}
+			break
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func TestDBQueue(t *testing.T) {
+	sql.Register("fakeaq", &fakeAQ{})
+	db := adapter.NewDBAdapter("erp", "fakeaq", "", "", nil)
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	defer db.Close()
+
+	for _, msg := range []map[string]interface{}{
+		{"queue": "orders_q", "payload": map[string]interface{}{"order": "A1"}, "correlation": "eu"},
+		{"queue": "orders_q", "payload": "plain text", "correlation": "us"},
+	} {
+		result, err := db.ExecuteTask("enqueue", msg)
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		if result["messageId"] == "" {
+			t.Errorf("Expected a message id, got %v", result)
+		}
+	}
+
+	result, err := db.ExecuteTask("dequeue", map[string]interface{}{"queue": "orders_q", "correlation": "us", "wait": 5})
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if result["found"] != true || result["payload"] != "plain text" || result["correlation"] != "us" {
+		t.Errorf("Expected the message selected by correlation, got %v", result)
+	}
+
+	result, err = db.ExecuteTask("dequeue", map[string]interface{}{"queue": "orders_q", "format": "json"})
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	payload, _ := result["payload"].(map[string]interface{})
+	if payload["order"] != "A1" {
+		t.Errorf("Expected the JSON payload decoded, got %v", result["payload"])
+	}
+
+	result, err = db.ExecuteTask("dequeue", map[string]interface{}{"queue": "orders_q"})
+	if err != nil {
+		t.Fatalf("Dequeue of an empty queue failed: %v", err)
+	}
+	if result["found"] != false {
+		t.Errorf("Expected an empty queue to return found false, got %v", result)
+	}
+
+	if _, err := db.ExecuteTask("enqueue", map[string]interface{}{"payload": "x"}); err == nil {
+		t.Error("Expected enqueue without a queue to fail")
+	}
+	if _, err := db.ExecuteTask("enqueue", map[string]interface{}{"queue": "orders_q", "payload": "%%", "format": "binary"}); err == nil {
+		t.Error("Expected a binary payload that is not base64 to fail")
+	}
+}
+
+func TestDBQueuePolicy(t *testing.T) {
+	sql.Register("fakeaqpolicy", &fakeAQ{})
+	db := adapter.NewDBAdapter("erp", "fakeaqpolicy", "", "", nil)
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	defer db.Close()
+	db.Policy, _ = adapter.SQLPolicyFromConfig(&config.SQLPolicyConfig{
+		Procedures: []string{"dbms_aq.enqueue"},
+		Queues:     []string{"erp.orders_q"},
+	})
+
+	if _, err := db.ExecuteTask("enqueue", map[string]interface{}{"queue": "ERP.ORDERS_Q", "payload": "x"}); err != nil {
+		t.Errorf("Expected an allowed queue to be enqueued to: %v", err)
+	}
+	for _, call := range []struct{ action, queue string }{
+		{"enqueue", "erp.payroll_q"},
+		{"enqueue", "orders_q"},
+		{"dequeue", "erp.orders_q"},
+	} {
+		_, err := db.ExecuteTask(call.action, map[string]interface{}{"queue": call.queue})
+		var policyErr *adapter.SQLPolicyError
+		if !errors.As(err, &policyErr) || policyErr.Action != call.action {
+			t.Errorf("Expected %s on %s to be rejected by the policy, got %v", call.action, call.queue, err)
+		}
+	}
+}
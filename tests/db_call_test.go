@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+)
+
+// fakeOracle is a database/sql driver answering like Oracle drivers do: a
+// procedure that opens a SYS_REFCURSOR and a table with BLOB and CLOB columns,
+// the CLOB read as a stream
+type fakeOracle struct {
+	written []byte
+}
+
+func (d *fakeOracle) Open(string) (driver.Conn, error) { return &fakeOracleConn{d: d}, nil }
+
+type fakeOracleConn struct{ d *fakeOracle }
+
+func (c *fakeOracleConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeOracleConn) Close() error                        { return nil }
+func (c *fakeOracleConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+// CheckNamedValue accepts sql.Out parameters and byte slices as they are
+func (c *fakeOracleConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *fakeOracleConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	for _, arg := range args {
+		switch v := arg.Value.(type) {
+		case sql.Out:
+			switch dest := v.Dest.(type) {
+			case *driver.Rows:
+				*dest = &fakeOracleRows{
+					columns: []string{"ID", "NAME"},
+					types:   []string{"NUMBER", "VARCHAR2"},
+					rows:    [][]driver.Value{{int64(1), "Ada"}, {int64(2), "Grace"}},
+				}
+			case *sql.NullString:
+				*dest = sql.NullString{String: "OK", Valid: true}
+			}
+		case []byte:
+			c.d.written = v
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeOracleConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeOracleRows{
+		columns: []string{"PHOTO", "NOTES"},
+		types:   []string{"BLOB", "CLOB"},
+		rows:    [][]driver.Value{{[]byte{0xff, 0x00, 0x10}, io.NopCloser(strings.NewReader("long text"))}},
+	}, nil
+}
+
+func (c *fakeOracleConn) Ping(context.Context) error { return nil }
+
+type fakeOracleRows struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+	closed  bool
+}
+
+func (r *fakeOracleRows) Columns() []string { return r.columns }
+func (r *fakeOracleRows) Close() error      { r.closed = true; return nil }
+func (r *fakeOracleRows) ColumnTypeDatabaseTypeName(i int) string {
+	return r.types[i]
+}
+func (r *fakeOracleRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestDBCallCursorsAndLOBs(t *testing.T) {
+	fake := &fakeOracle{}
+	sql.Register("fakeoracle", fake)
+	db := adapter.NewDBAdapter("erp", "fakeoracle", "", "", nil)
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.ExecuteTask("call", map[string]interface{}{
+		"statement": "BEGIN get_customers({region}, {customers}, {status}); END;",
+		"args":      map[string]interface{}{"region": "EU"},
+		"out":       map[string]interface{}{"customers": "cursor", "status": "string"},
+	})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	out := result["out"].(map[string]interface{})
+	if out["status"] != "OK" {
+		t.Errorf("Expected the string out parameter, got %v", out["status"])
+	}
+	customers, _ := out["customers"].([]map[string]interface{})
+	if len(customers) != 2 || customers[1]["NAME"] != "Grace" {
+		t.Errorf("Expected the cursor as a row set, got %v", out["customers"])
+	}
+
+	if result["truncated"] != nil {
+		t.Errorf("Expected a cursor under the row limit not to be truncated, got %v", result)
+	}
+
+	db.QueryLimits.MaxRows = 1
+	result, err = db.ExecuteTask("call", map[string]interface{}{
+		"statement": "BEGIN get_customers({customers}); END;",
+		"out":       map[string]interface{}{"customers": "cursor"},
+	})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	customers, _ = result["out"].(map[string]interface{})["customers"].([]map[string]interface{})
+	if len(customers) != 1 || customers[0]["NAME"] != "Ada" || result["truncated"] != true {
+		t.Errorf("Expected the cursor truncated to the row limit, got %v", result)
+	}
+	db.QueryLimits.MaxRows = 0
+
+	if _, err := db.ExecuteTask("call", map[string]interface{}{
+		"statement": "BEGIN x({a}); END;",
+		"out":       map[string]interface{}{"a": "table"},
+	}); err == nil {
+		t.Error("Expected an unsupported out kind to fail")
+	}
+
+	rows, err := db.ExecuteTask("query", map[string]interface{}{"query": "SELECT photo, notes FROM customers"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	row := rows["results"].([]map[string]interface{})[0]
+	if row["PHOTO"] != base64.StdEncoding.EncodeToString([]byte{0xff, 0x00, 0x10}) {
+		t.Errorf("Expected the BLOB base64 encoded, got %v", row["PHOTO"])
+	}
+	if row["NOTES"] != "long text" {
+		t.Errorf("Expected the streamed CLOB as text, got %v", row["NOTES"])
+	}
+
+	db.MaxLOBSize = 4
+	if _, err := db.ExecuteTask("query", map[string]interface{}{"query": "SELECT photo, notes FROM customers"}); err == nil {
+		t.Error("Expected a LOB over the limit to fail")
+	}
+
+	photo := base64.StdEncoding.EncodeToString([]byte("png"))
+	if _, err := db.ExecuteTask("execute", map[string]interface{}{
+		"statement": "UPDATE customers SET photo = {photo} WHERE id = {id}",
+		"args":      map[string]interface{}{"photo": photo, "id": 1},
+		"binary":    []interface{}{"photo"},
+	}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if string(fake.written) != "png" {
+		t.Errorf("Expected the base64 argument bound as bytes, got %q", fake.written)
+	}
+}
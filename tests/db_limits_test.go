@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// fakeLargeTable is a database/sql driver whose queries return rows numbered
+// 1 to 1000 and record the query run. A query reading from slow blocks until
+// it is cancelled.
+type fakeLargeTable struct {
+	queries []string
+}
+
+func (d *fakeLargeTable) Open(string) (driver.Conn, error) { return &fakeLargeConn{d: d}, nil }
+
+type fakeLargeConn struct{ d *fakeLargeTable }
+
+func (c *fakeLargeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeLargeConn) Close() error                        { return nil }
+func (c *fakeLargeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c *fakeLargeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.queries = append(c.d.queries, query)
+	if strings.Contains(query, "slow") {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &fakeLargeRows{}, nil
+}
+
+type fakeLargeRows struct{ n int }
+
+func (r *fakeLargeRows) Columns() []string { return []string{"ID"} }
+func (r *fakeLargeRows) Close() error      { return nil }
+func (r *fakeLargeRows) Next(dest []driver.Value) error {
+	if r.n == 1000 {
+		return io.EOF
+	}
+	r.n++
+	dest[0] = int64(r.n)
+	return nil
+}
+
+func TestDBQueryLimits(t *testing.T) {
+	fake := &fakeLargeTable{}
+	sql.Register("fakelimits", fake)
+	db := adapter.NewDBAdapter("erp", "fakelimits", "", "", nil)
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.ExecuteTask("query", map[string]interface{}{"query": "SELECT id FROM orders"})
+	if err != nil || len(result["results"].([]map[string]interface{})) != 1000 || result["truncated"] != nil {
+		t.Fatalf("Expected every row without limits, got %v", err)
+	}
+
+	db.QueryLimits, err = adapter.QueryLimitsFromConfig(&config.QueryLimitsConfig{MaxRows: 50, Timeout: "50ms", InjectLimit: true})
+	if err != nil {
+		t.Fatalf("Invalid limits: %v", err)
+	}
+	result, err = db.ExecuteTask("query", map[string]interface{}{"query": "SELECT id FROM orders ORDER BY id;"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	rows := result["results"].([]map[string]interface{})
+	if len(rows) != 50 || result["truncated"] != true {
+		t.Errorf("Expected 50 rows marked truncated, got %d, %v", len(rows), result["truncated"])
+	}
+	want := "SELECT * FROM (\nSELECT id FROM orders ORDER BY id\n) AS limited LIMIT 51"
+	if query := fake.queries[len(fake.queries)-1]; query != want {
+		t.Errorf("Expected the limit to be injected, got %q", query)
+	}
+
+	start := time.Now()
+	_, err = db.ExecuteTask("query", map[string]interface{}{"query": "SELECT id FROM slow"})
+	var timeoutErr *adapter.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != "query" {
+		t.Errorf("Expected a query timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the query to be cancelled, took %s", elapsed)
+	}
+
+	for _, cfg := range []config.QueryLimitsConfig{
+		{MaxRows: -1},
+		{InjectLimit: true},
+		{Timeout: "soon"},
+	} {
+		if _, err := adapter.QueryLimitsFromConfig(&cfg); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+func TestSQLPolicyStatements(t *testing.T) {
+	policy, err := adapter.SQLPolicyFromConfig(&config.SQLPolicyConfig{
+		Tables: []string{"customers", "orders", "hr.employees"},
+	})
+	if err != nil {
+		t.Fatalf("Invalid policy: %v", err)
+	}
+
+	allowed := []struct{ action, stmt string }{
+		{"query", "SELECT id, name FROM customers WHERE region = {region}"},
+		{"query", "select c.id from customers c join orders o on o.customer_id = c.id, hr.employees e;"},
+		{"query", "SELECT * FROM customers WHERE id IN (SELECT customer_id FROM orders) AND name = 'O''Brien -- not a comment'"},
+		{"query", "WITH recent AS (SELECT * FROM orders) SELECT EXTRACT(YEAR FROM created) FROM recent"},
+		{"query", "SELECT SYSDATE FROM dual"},
+		{"query", `SELECT * FROM "CUSTOMERS" FOR UPDATE`},
+		{"execute", "UPDATE customers SET name = {name} WHERE id = {id}"},
+		{"execute", "INSERT INTO orders (id, customer_id) SELECT {id}, id FROM customers WHERE id = {customer}"},
+		{"execute", "MERGE INTO customers c USING orders o ON (c.id = o.customer_id) WHEN MATCHED THEN UPDATE SET c.name = o.name"},
+	}
+	for _, s := range allowed {
+		if err := policy.CheckStatement(s.action, s.stmt); err != nil {
+			t.Errorf("Expected %s to be allowed: %v", s.stmt, err)
+		}
+	}
+
+	rejected := []struct{ action, stmt string }{
+		{"query", "SELECT * FROM customers; DROP TABLE customers"},
+		{"query", "SELECT * FROM customers -- WHERE tenant = 1"},
+		{"query", "SELECT * FROM customers /* hint */"},
+		{"query", `SELECT * FROM customers WHERE name = 'x\' OR 1=1'`},
+		{"query", "SELECT q'[a';b]' FROM customers"},
+		{"query", "SELECT $$;$$ FROM customers"},
+		{"query", "SELECT * FROM salaries"},
+		{"query", "SELECT * FROM customers, salaries"},
+		{"query", "SELECT * FROM customers WHERE id IN (SELECT id FROM hr.salaries)"},
+		{"query", "SELECT * FROM employees"},
+		{"query", "WITH gone AS (DELETE FROM orders RETURNING *) SELECT * FROM gone"},
+		{"query", "SELECT * INTO backup FROM customers"},
+		{"query", "DELETE FROM customers"},
+		{"execute", "SELECT * FROM customers"},
+		{"execute", "DROP TABLE customers"},
+		{"execute", "TRUNCATE TABLE orders"},
+		{"execute", "UPDATE salaries SET amount = 0"},
+		{"execute", "DELETE FROM customers WHERE id IN (SELECT id FROM salaries)"},
+		{"execute", "EXEC xp_cmdshell 'dir'"},
+	}
+	for _, s := range rejected {
+		err := policy.CheckStatement(s.action, s.stmt)
+		var policyErr *adapter.SQLPolicyError
+		if !errors.As(err, &policyErr) {
+			t.Errorf("Expected %s to be rejected, got %v", s.stmt, err)
+		}
+	}
+}
+
+func TestSQLPolicyRules(t *testing.T) {
+	policy, err := adapter.SQLPolicyFromConfig(&config.SQLPolicyConfig{
+		Verbs:    []string{"select", "insert", "create"},
+		AllowDDL: true,
+	})
+	if err != nil {
+		t.Fatalf("Invalid policy: %v", err)
+	}
+	if err := policy.CheckStatement("execute", "CREATE TABLE audit (id INT)"); err != nil {
+		t.Errorf("Expected DDL to be allowed: %v", err)
+	}
+	if err := policy.CheckStatement("execute", "UPDATE customers SET name = 'x'"); err == nil {
+		t.Error("Expected a verb outside the allowlist to be rejected")
+	}
+	if err := policy.CheckStatement("query", "CREATE TABLE audit (id INT)"); err == nil {
+		t.Error("Expected DDL to be rejected by query")
+	}
+	if _, err := adapter.SQLPolicyFromConfig(&config.SQLPolicyConfig{Verbs: []string{"EXEC"}}); err == nil {
+		t.Error("Expected an unknown verb to be rejected")
+	}
+
+	procedures, _ := adapter.SQLPolicyFromConfig(&config.SQLPolicyConfig{
+		Procedures:     []string{"crm.get_customers"},
+		ProceduresOnly: true,
+	})
+	for _, stmt := range []string{
+		"BEGIN crm.get_customers({region}, {customers}); END;",
+		"CALL CRM.GET_CUSTOMERS(region => {region}, customers => {customers})",
+		"EXEC crm.get_customers @region = {region}, @customers = {customers} OUTPUT",
+	} {
+		if err := procedures.CheckCall(stmt); err != nil {
+			t.Errorf("Expected %s to be allowed: %v", stmt, err)
+		}
+	}
+	for _, stmt := range []string{
+		"BEGIN crm.delete_customers; END;",
+		"BEGIN crm.get_customers({region}, {customers}); DELETE FROM customers; END;",
+		"BEGIN crm.get_customers((SELECT region FROM secrets), {customers}); END;",
+		"DECLARE x NUMBER; BEGIN crm.get_customers(x, {customers}); END;",
+	} {
+		if err := procedures.CheckCall(stmt); err == nil {
+			t.Errorf("Expected %s to be rejected", stmt)
+		}
+	}
+	if err := procedures.CheckStatement("query", "SELECT 1 FROM dual"); err == nil {
+		t.Error("Expected queries to be rejected in procedures-only mode")
+	}
+}
+
+func TestSQLPolicyFunctions(t *testing.T) {
+	policy, err := adapter.SQLPolicyFromConfig(&config.SQLPolicyConfig{
+		Procedures: []string{"crm.score"},
+		AllowDDL:   true,
+	})
+	if err != nil {
+		t.Fatalf("Invalid policy: %v", err)
+	}
+	for _, s := range []struct{ action, stmt string }{
+		{"query", "SELECT COUNT(*), MAX(created), crm.score(id) FROM customers WHERE id IN (SELECT id FROM orders)"},
+		{"query", "WITH recent (id) AS (SELECT id FROM orders) SELECT CAST(id AS NUMBER(10)) FROM recent"},
+		{"query", "SELECT ROW_NUMBER() OVER (PARTITION BY region ORDER BY id) FROM customers"},
+		{"execute", "INSERT INTO audit (id, score) VALUES ({id}, CRM.SCORE({id}))"},
+		{"execute", "CREATE TABLE audit (id NUMBER(10), note VARCHAR2(100))"},
+	} {
+		if err := policy.CheckStatement(s.action, s.stmt); err != nil {
+			t.Errorf("Expected %s to be allowed: %v", s.stmt, err)
+		}
+	}
+	for _, s := range []struct{ action, stmt string }{
+		{"query", "SELECT pkg.do_write(1) FROM dual"},
+		{"query", `SELECT "PKG"."DO_WRITE"(1) FROM dual`},
+		{"query", "SELECT * FROM customers WHERE id = do_write({id})"},
+		{"query", "SELECT * FROM TABLE(pkg.rows_of({id}))"},
+		{"query", "SELECT * FROM pkg.rows_of({id})"},
+		{"query", "WITH do_write AS (SELECT 1 FROM dual) SELECT do_write(1) FROM dual"},
+		{"execute", "UPDATE customers SET score = pkg.do_write(id)"},
+	} {
+		if err := policy.CheckStatement(s.action, s.stmt); err == nil {
+			t.Errorf("Expected %s to be rejected", s.stmt)
+		}
+	}
+
+	queues, _ := adapter.SQLPolicyFromConfig(&config.SQLPolicyConfig{Procedures: []string{"dbms_aq.dequeue"}})
+	if err := queues.CheckQueue("dequeue", "orders_q"); err != nil {
+		t.Errorf("Expected any queue to be dequeued from: %v", err)
+	}
+	if err := queues.CheckQueue("enqueue", "orders_q"); err == nil {
+		t.Error("Expected enqueue to be rejected without DBMS_AQ.ENQUEUE on the allowlist")
+	}
+}
+
+func TestDBAdapterSQLPolicy(t *testing.T) {
+	sql.Register("fakepolicy", &fakeOracle{})
+	db := adapter.NewDBAdapter("erp", "fakepolicy", "", "", nil)
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	defer db.Close()
+	db.Policy, _ = adapter.SQLPolicyFromConfig(&config.SQLPolicyConfig{Tables: []string{"customers"}})
+
+	if _, err := db.ExecuteTask("execute", map[string]interface{}{"statement": "UPDATE customers SET photo = {photo}"}); err != nil {
+		t.Errorf("Expected the statement to run: %v", err)
+	}
+	_, err := db.ExecuteTask("execute", map[string]interface{}{"statement": "DROP TABLE customers"})
+	var policyErr *adapter.SQLPolicyError
+	if !errors.As(err, &policyErr) || policyErr.HTTPStatusCode() != 403 {
+		t.Errorf("Expected the statement to be rejected by the policy, got %v", err)
+	}
+	if _, err := db.ExecuteTask("call", map[string]interface{}{"statement": "BEGIN x({a}); DELETE FROM customers; END;"}); err == nil {
+		t.Error("Expected a block with more than a call to be rejected")
+	}
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+	"github.com/A2AGateway/a2a-connector/internal/proxy"
+)
+
+func TestDBMappingGeneration(t *testing.T) {
+	columns := []adapter.ColumnInfo{
+		{Name: "id", DataType: "integer", PrimaryKey: true},
+		{Name: "name", DataType: "character varying"},
+		{Name: "balance", DataType: "numeric", Nullable: true},
+		{Name: "active", DataType: "boolean"},
+	}
+
+	mappings, err := adapter.GenerateTableMappings("postgres", "customers", columns)
+	if err != nil {
+		t.Fatalf("Failed to generate mappings: %v", err)
+	}
+	if len(mappings) != 3 {
+		t.Fatalf("Expected read, create and update mappings, got %d", len(mappings))
+	}
+
+	read, create, update := mappings[0], mappings[1], mappings[2]
+	if read.Method != "query" || create.Method != "execute" || update.Method != "execute" {
+		t.Errorf("Unexpected methods: %s, %s, %s", read.Method, create.Method, update.Method)
+	}
+	if got := read.Params["query"]; got != `SELECT "id", "name", "balance", "active" FROM "customers" WHERE "id" = {id}` {
+		t.Errorf("Unexpected read query: %v", got)
+	}
+	if got := create.Params["statement"]; got != `INSERT INTO "customers" ("id", "name", "balance", "active") VALUES ({id}, {name}, {balance}, {active})` {
+		t.Errorf("Unexpected insert statement: %v", got)
+	}
+	if got := update.Params["statement"].(string); !strings.Contains(got, `"name" = COALESCE({name}, "name")`) || !strings.HasSuffix(got, `WHERE "id" = {id}`) {
+		t.Errorf("Unexpected update statement: %v", got)
+	}
+
+	types := make(map[string]string)
+	for _, param := range create.ParameterMappings {
+		types[param.Target] = param.Type
+	}
+	expected := map[string]string{"args.id": "int", "args.name": "string", "args.balance": "number", "args.active": "bool"}
+	for target, typ := range expected {
+		if types[target] != typ {
+			t.Errorf("Expected %s to have type %s, got %q", target, typ, types[target])
+		}
+	}
+
+	if _, err := adapter.GenerateTableMappings("postgres", "audit", []adapter.ColumnInfo{{Name: "entry", DataType: "text"}}); err == nil {
+		t.Error("Expected an error for a table without a primary key")
+	}
+
+	// Generated mappings drive the config transformer end to end
+	cfg := &config.ConnectorConfig{
+		Adapter:  config.AdapterConfig{Type: "db", Name: "crm", Driver: "postgres", DSN: "postgres://localhost/crm"},
+		Mappings: mappings,
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatalf("Failed to compile generated mappings: %v", err)
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Generated config is invalid: %v", err)
+	}
+
+	ct := proxy.NewConfigTransformer(cfg)
+	task := `{"id":"task-1","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"Get customer id 42"}]}}}`
+	data, err := ct.TransformRequestData([]byte(task))
+	if err != nil {
+		t.Fatalf("Failed to transform request: %v", err)
+	}
+
+	var legacyReq map[string]interface{}
+	if err := json.Unmarshal(data, &legacyReq); err != nil {
+		t.Fatalf("Failed to parse legacy request: %v", err)
+	}
+	params := legacyReq["params"].(map[string]interface{})
+	args := params["args"].(map[string]interface{})
+	if legacyReq["action"] != "query" || args["id"] != float64(42) {
+		t.Errorf("Unexpected legacy request: %v", legacyReq)
+	}
+
+	query, bound, err := adapter.BindNamedParams("postgres", params["query"].(string), args)
+	if err != nil {
+		t.Fatalf("Failed to bind parameters: %v", err)
+	}
+	if !strings.HasSuffix(query, `WHERE "id" = $1`) || len(bound) != 1 || bound[0] != float64(42) {
+		t.Errorf("Unexpected bound query %q with args %v", query, bound)
+	}
+
+	// Type conversion failures are reported instead of sent to the database
+	task = `{"id":"task-2","status":{"state":"submitted","message":{"role":"user","parts":[{"type":"text","text":"Get customer id abc"}]}}}`
+	if _, err := ct.TransformRequestData([]byte(task)); err == nil {
+		t.Error("Expected an error for a non-integer id")
+	}
+}
+
+func TestBindNamedParams(t *testing.T) {
+	named := map[string]interface{}{"id": 7, "name": "Robert'); DROP TABLE customers;--"}
+
+	tests := []struct {
+		driver   string
+		expected string
+	}{
+		{"postgres", "UPDATE t SET name = $1 WHERE id = $2 AND note = $3"},
+		{"mysql", "UPDATE t SET name = ? WHERE id = ? AND note = ?"},
+		{"sqlserver", "UPDATE t SET name = @p1 WHERE id = @p2 AND note = @p3"},
+		{"godror", "UPDATE t SET name = :1 WHERE id = :2 AND note = :3"},
+	}
+
+	for _, tt := range tests {
+		query, args, err := adapter.BindNamedParams(tt.driver, "UPDATE t SET name = {name} WHERE id = {id} AND note = {note}", named)
+		if err != nil {
+			t.Fatalf("%s: failed to bind: %v", tt.driver, err)
+		}
+		if query != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.driver, tt.expected, query)
+		}
+		if len(args) != 3 || args[0] != named["name"] || args[1] != 7 || args[2] != nil {
+			t.Errorf("%s: unexpected args %v", tt.driver, args)
+		}
+	}
+
+	if _, _, err := adapter.BindNamedParams("postgres", "SELECT {x}", map[string]interface{}{"x": []interface{}{1}}); err == nil {
+		t.Error("Expected an error for a non-scalar parameter")
+	}
+}
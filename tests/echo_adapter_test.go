@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+func TestEchoAdapter(t *testing.T) {
+	echo := adapter.NewEchoAdapter("echo", nil)
+	if err := echo.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := echo.ExecuteTask("GET", map[string]interface{}{"id": "42"})
+	if err != nil {
+		t.Fatalf("ExecuteTask: %v", err)
+	}
+	if result["action"] != "GET" || result["params"].(map[string]interface{})["id"] != "42" {
+		t.Errorf("Expected the inputs back, got %v", result)
+	}
+
+	// The echo param injects a delay, limited by the call timeouts
+	params := map[string]interface{}{"id": "42", "echo": map[string]interface{}{"delay": "30ms"}}
+	start := time.Now()
+	result, err = echo.ExecuteTask("GET", params)
+	if err != nil || time.Since(start) < 30*time.Millisecond {
+		t.Fatalf("Expected a delayed echo, got %v after %v", err, time.Since(start))
+	}
+	if _, ok := result["params"].(map[string]interface{})["echo"]; ok {
+		t.Error("Expected the echo param not to be echoed")
+	}
+	ctx := adapter.WithTimeouts(context.Background(), adapter.Timeouts{Total: 5 * time.Millisecond})
+	var timeoutErr *adapter.TimeoutError
+	if _, err := echo.ExecuteTaskContext(ctx, "GET", params); !errors.As(err, &timeoutErr) {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+
+	// Injected errors with a status are retried like HTTP errors
+	echo.ErrorRate, echo.Error, echo.ErrorStatus = 1, "legacy system down", 503
+	_, err = echo.ExecuteTask("GET", nil)
+	var echoErr *adapter.EchoError
+	if !errors.As(err, &echoErr) || err.Error() != "legacy system down" || echoErr.Details()["statusCode"] != 503 {
+		t.Fatalf("Expected an injected 503, got %v", err)
+	}
+	if !(&resilience.RetryPolicy{RetryableStatuses: []int{503}}).Retryable(err) {
+		t.Error("Expected the injected error to be retryable")
+	}
+	params = map[string]interface{}{"echo": map[string]interface{}{"errorRate": 0.0}}
+	if _, err := echo.ExecuteTask("GET", params); err != nil {
+		t.Errorf("Expected the echo param to turn errors off, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+)
+
+func TestFileAdapter(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "exchange")
+	if err := os.Mkdir(base, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "secret.txt"), []byte("outside"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := adapter.NewFileAdapter("files", base, nil)
+	if err := files.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := files.ExecuteTask("write", map[string]interface{}{"filename": "order.csv", "content": "42,widget"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	result, err := files.ExecuteTask("read", map[string]interface{}{"filename": "order.csv"})
+	if err != nil || result["content"] != "42,widget" {
+		t.Fatalf("Expected the written content, got %v, %v", result, err)
+	}
+
+	// Binary content is written from base64
+	if _, err := files.ExecuteTask("write", map[string]interface{}{"filename": "scan.bin", "contentBase64": "AAH+/w=="}); err != nil {
+		t.Fatalf("write base64: %v", err)
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(base, "scan.bin")); string(data) != "\x00\x01\xfe\xff" {
+		t.Errorf("Expected the decoded content, got %q", data)
+	}
+
+	// Task params must not reach files outside of the base path
+	for _, call := range []struct {
+		action string
+		params map[string]interface{}
+	}{
+		{"read", map[string]interface{}{"filename": "../secret.txt"}},
+		{"write", map[string]interface{}{"filename": "../secret.txt", "content": "x"}},
+		{"delete", map[string]interface{}{"filename": "../secret.txt"}},
+		{"list", map[string]interface{}{"directory": ".."}},
+	} {
+		if _, err := files.ExecuteTask(call.action, call.params); err == nil {
+			t.Errorf("Expected %s %v to be rejected", call.action, call.params)
+		}
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(dir, "secret.txt")); string(data) != "outside" {
+		t.Errorf("Expected the file outside of the base path to be untouched, got %q", data)
+	}
+}
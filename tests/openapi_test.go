@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+)
+
+const customerAPISpec = `
+openapi: 3.0.0
+info:
+  title: Customer API
+  version: "1.0"
+paths:
+  /customers/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      operationId: getCustomerById
+      summary: Get a customer
+      parameters:
+        - name: verbose
+          in: query
+          schema:
+            type: boolean
+  /customers:
+    post:
+      operationId: createCustomer
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Customer'
+components:
+  schemas:
+    Customer:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        tier:
+          type: string
+          enum: [gold, silver]
+`
+
+func TestOpenAPIRESTAdapter(t *testing.T) {
+	var gotMethod, gotPath, gotQuery string
+	var gotBody map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotQuery = r.Method, r.URL.Path, r.URL.RawQuery
+		gotBody = nil
+		if body, _ := ioutil.ReadAll(r.Body); len(body) > 0 {
+			json.Unmarshal(body, &gotBody)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+	}))
+	defer server.Close()
+
+	spec, err := adapter.ParseOpenAPISpec([]byte(customerAPISpec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	rest := adapter.NewRESTAdapter("customers", server.URL, nil, nil)
+	rest.SetSpec(spec)
+
+	caps, err := rest.GetCapabilities()
+	if err != nil {
+		t.Fatalf("GetCapabilities failed: %v", err)
+	}
+	if ops, ok := caps["operations"].([]map[string]interface{}); !ok || len(ops) != 2 {
+		t.Fatalf("Expected 2 operations, got %v", caps["operations"])
+	}
+
+	// Path and query parameters
+	if _, err := rest.ExecuteTask("getCustomerById", map[string]interface{}{"id": "42", "verbose": "true"}); err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if gotMethod != "GET" || gotPath != "/customers/42" || gotQuery != "verbose=true" {
+		t.Errorf("Unexpected request: %s %s?%s", gotMethod, gotPath, gotQuery)
+	}
+
+	// Schema validation
+	if _, err := rest.ExecuteTask("getCustomerById", map[string]interface{}{"id": "abc"}); err == nil {
+		t.Error("Expected validation error for non-integer id")
+	}
+	if _, err := rest.ExecuteTask("createCustomer", map[string]interface{}{"tier": "bronze", "name": "ACME"}); err == nil {
+		t.Error("Expected validation error for enum value")
+	}
+
+	// Body built from remaining params
+	if _, err := rest.ExecuteTask("createCustomer", map[string]interface{}{"name": "ACME", "tier": "gold"}); err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if gotMethod != "POST" || gotBody["name"] != "ACME" {
+		t.Errorf("Unexpected request: %s with body %v", gotMethod, gotBody)
+	}
+
+	// Mapping generation
+	mappings := adapter.GenerateMappings(spec)
+	if len(mappings) != 2 {
+		t.Fatalf("Expected 2 generated mappings, got %d", len(mappings))
+	}
+	if mappings[1].Method != "getCustomerById" || mappings[1].IntentPattern != "get.*customer.*by.*id" {
+		t.Errorf("Unexpected generated mapping: %+v", mappings[1])
+	}
+}
@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	adapterplugin "github.com/A2AGateway/a2a-connector/adapters/plugin"
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// pluginModeEnv makes the test binary run as a plugin, in the mode it names
+const pluginModeEnv = "A2A_TEST_PLUGIN"
+
+// testPlugin is an adapter plugin served with the plugin package, as a Go
+// plugin author would write it
+type testPlugin struct {
+	adapterplugin.UnimplementedAdapterServer
+	name   string
+	config map[string]interface{}
+}
+
+func (p *testPlugin) Initialize(ctx context.Context, req *adapterplugin.InitializeRequest) (*adapterplugin.InitializeResponse, error) {
+	if os.Getenv(pluginModeEnv) == "hang-initialize" {
+		time.Sleep(time.Minute)
+	}
+	p.name, p.config = req.Name, req.Config.AsMap()
+	return &adapterplugin.InitializeResponse{}, nil
+}
+
+func (p *testPlugin) GetCapabilities(ctx context.Context, req *adapterplugin.GetCapabilitiesRequest) (*adapterplugin.GetCapabilitiesResponse, error) {
+	capabilities, err := structpb.NewStruct(map[string]interface{}{"name": p.name, "region": p.config["region"], "cookie": os.Getenv(adapterplugin.CookieKey), "pid": os.Getpid()})
+	return &adapterplugin.GetCapabilitiesResponse{Capabilities: capabilities}, err
+}
+
+func (p *testPlugin) ExecuteTask(ctx context.Context, req *adapterplugin.ExecuteTaskRequest) (*adapterplugin.ExecuteTaskResponse, error) {
+	switch req.Action {
+	case "fail":
+		return nil, errors.New("order not found")
+	case "slow":
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	case "crash":
+		fmt.Fprintln(os.Stderr, "crashing")
+		os.Exit(1)
+	}
+	result, err := structpb.NewStruct(map[string]interface{}{"action": req.Action, "params": req.Params.AsMap(), "secret": os.Getenv("ERP_PASSWORD")})
+	return &adapterplugin.ExecuteTaskResponse{Result: result}, err
+}
+
+// TestPluginProcess is not a test: it serves testPlugin when the test binary
+// is started as a plugin
+func TestPluginProcess(t *testing.T) {
+	switch os.Getenv(pluginModeEnv) {
+	case "":
+		return
+	case "silent":
+		time.Sleep(time.Minute)
+	case "bad-handshake":
+		fmt.Println("listening on /tmp/plugin.sock")
+		time.Sleep(time.Minute)
+	}
+	adapterplugin.Serve(&testPlugin{})
+	os.Exit(0)
+}
+
+func newTestPlugin(mode string) *adapter.PluginAdapter {
+	plugin := adapter.NewPluginAdapter("erp", os.Args[0], []string{"-test.run=^TestPluginProcess$"}, map[string]interface{}{"region": "eu"})
+	plugin.Env = []string{pluginModeEnv + "=" + mode, "ERP_PASSWORD=s3cret"}
+	plugin.StartTimeout = 5 * time.Second
+	return plugin
+}
+
+func TestPluginAdapter(t *testing.T) {
+	plugin := newTestPlugin("serve")
+	if err := plugin.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer plugin.Close()
+
+	caps, err := plugin.GetCapabilities()
+	if err != nil {
+		t.Fatalf("GetCapabilities failed: %v", err)
+	}
+	if caps["type"] != "plugin" || caps["name"] != "erp" || caps["region"] != "eu" || caps["cookie"] != adapterplugin.CookieValue {
+		t.Errorf("Unexpected capabilities %v", caps)
+	}
+
+	result, err := plugin.ExecuteTask("get", map[string]interface{}{"id": "42"})
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if result["action"] != "get" || result["params"].(map[string]interface{})["id"] != "42" || result["secret"] != "s3cret" {
+		t.Errorf("Unexpected result %v", result)
+	}
+	if _, err := plugin.ExecuteTask("fail", nil); err == nil || err.Error() != "order not found" {
+		t.Errorf("Expected the plugin's error, got %v", err)
+	}
+
+	ctx := adapter.WithTimeouts(context.Background(), adapter.Timeouts{Total: 100 * time.Millisecond})
+	var timeoutErr *adapter.TimeoutError
+	if _, err := plugin.ExecuteTaskContext(ctx, "slow", nil); !errors.As(err, &timeoutErr) {
+		t.Errorf("Expected a total timeout, got %v", err)
+	}
+
+	if err := plugin.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if _, err := plugin.ExecuteTask("get", nil); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("Expected a closed plugin to fail calls, got %v", err)
+	}
+}
+
+// pluginPID returns the process ID the plugin reports
+func pluginPID(t *testing.T, plugin *adapter.PluginAdapter) int {
+	t.Helper()
+	caps, err := plugin.GetCapabilities()
+	if err != nil {
+		t.Fatalf("GetCapabilities failed: %v", err)
+	}
+	return int(caps["pid"].(float64))
+}
+
+func TestPluginAdapterRestart(t *testing.T) {
+	plugin := newTestPlugin("serve")
+	plugin.RestartBackoff = 300 * time.Millisecond
+	if err := plugin.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer plugin.Close()
+
+	// A killed plugin is started again on the next call
+	pid := pluginPID(t, plugin)
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]interface{}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if result, err = plugin.ExecuteTask("get", nil); err == nil || time.Now().After(deadline) {
+			break
+		}
+		if !strings.Contains(err.Error(), "lost the connection") {
+			t.Fatalf("Expected the killed plugin to be reported, got %v", err)
+		}
+	}
+	if err != nil || result["action"] != "get" {
+		t.Fatalf("Expected the killed plugin to be restarted, got %v %v", result, err)
+	}
+	if restarted := pluginPID(t, plugin); restarted == pid {
+		t.Errorf("Expected a new plugin process, got pid %d again", restarted)
+	}
+
+	// A plugin that keeps exiting is restarted with a delay
+	if _, err := plugin.ExecuteTask("crash", nil); err == nil || !strings.Contains(err.Error(), "lost the connection") {
+		t.Fatalf("Expected a crashed plugin to be reported, got %v", err)
+	}
+	if _, err := plugin.ExecuteTask("get", nil); err == nil || !strings.Contains(err.Error(), "restarting in") {
+		t.Errorf("Expected calls to fail until the plugin is restarted, got %v", err)
+	}
+	time.Sleep(plugin.RestartBackoff)
+	if _, err := plugin.ExecuteTask("get", nil); err != nil {
+		t.Errorf("Expected the plugin to be restarted after the delay, got %v", err)
+	}
+
+	// A closed plugin is not restarted
+	plugin.Close()
+	if _, err := plugin.ExecuteTask("get", nil); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("Expected a closed plugin to fail calls, got %v", err)
+	}
+}
+
+func TestPluginAdapterFailures(t *testing.T) {
+	plugin := newTestPlugin("serve")
+	if err := plugin.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer plugin.Close()
+	if _, err := plugin.ExecuteTask("crash", nil); err == nil || !strings.Contains(err.Error(), "lost the connection") {
+		t.Errorf("Expected a crashed plugin to be reported, got %v", err)
+	}
+	plugin.Close()
+
+	plugin = newTestPlugin("bad-handshake")
+	if err := plugin.Initialize(); err == nil || !strings.Contains(err.Error(), "Unrecognized remote plugin message") {
+		t.Errorf("Expected an invalid handshake to be rejected, got %v", err)
+	}
+
+	plugin = newTestPlugin("silent")
+	plugin.StartTimeout = 200 * time.Millisecond
+	if err := plugin.Initialize(); err == nil || !strings.Contains(err.Error(), "timeout while waiting for plugin to start") {
+		t.Errorf("Expected a plugin without a handshake to time out, got %v", err)
+	}
+
+	// A plugin that hangs in Initialize is stopped after the start timeout;
+	// calls and Close do not wait for it longer than that
+	plugin = newTestPlugin("hang-initialize")
+	plugin.StartTimeout = 500 * time.Millisecond
+	initialized := make(chan error, 1)
+	go func() { initialized <- plugin.Initialize() }()
+	time.Sleep(100 * time.Millisecond)
+	ctx := adapter.WithTimeouts(context.Background(), adapter.Timeouts{Total: 50 * time.Millisecond})
+	var timeoutErr *adapter.TimeoutError
+	if _, err := plugin.ExecuteTaskContext(ctx, "get", nil); !errors.As(err, &timeoutErr) {
+		t.Errorf("Expected a call during the start to time out, got %v", err)
+	}
+	closed := time.Now()
+	plugin.Close()
+	if took := time.Since(closed); took > 2*time.Second {
+		t.Errorf("Expected Close to wait at most the start timeout, took %s", took)
+	}
+	select {
+	case err := <-initialized:
+		if err == nil || !strings.Contains(err.Error(), "did not initialize") {
+			t.Errorf("Expected a plugin hanging in Initialize to time out, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Initialize of a plugin hanging in Initialize did not return")
+	}
+
+	plugin = adapter.NewPluginAdapter("erp", filepath.Join(t.TempDir(), "missing"), nil, nil)
+	if err := plugin.Initialize(); err == nil {
+		t.Error("Expected a missing plugin binary to fail")
+	}
+}
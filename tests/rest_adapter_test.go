@@ -0,0 +1,416 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/cache"
+	"github.com/A2AGateway/a2a-connector/internal/resilience"
+)
+
+func TestRESTAdapterRequestBuilding(t *testing.T) {
+	var last *http.Request
+	var lastBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last, lastBody = r, ""
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			r.ParseMultipartForm(1 << 20)
+		} else {
+			body, _ := ioutil.ReadAll(r.Body)
+			lastBody = string(body)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("test", server.URL, map[string]string{"X-Api-Key": "default"}, nil)
+
+	// Path template, query params and per-call headers
+	_, err := rest.ExecuteTask("/customers/{id}/orders", map[string]interface{}{
+		"pathParams": map[string]interface{}{"id": "a b"},
+		"query":      map[string]interface{}{"status": []interface{}{"open", "late"}, "limit": 10},
+		"headers":    map[string]interface{}{"X-Api-Key": "override", "X-Trace": "1"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if last.Method != http.MethodGet || last.URL.EscapedPath() != "/customers/a%20b/orders" {
+		t.Errorf("Unexpected request: %s %s", last.Method, last.URL.EscapedPath())
+	}
+	if got := last.URL.Query()["status"]; len(got) != 2 || last.URL.Query().Get("limit") != "10" {
+		t.Errorf("Unexpected query: %s", last.URL.RawQuery)
+	}
+	if last.Header.Get("X-Api-Key") != "override" || last.Header.Get("X-Trace") != "1" {
+		t.Errorf("Unexpected headers: %v", last.Header)
+	}
+
+	// An empty per-call header removes the adapter default
+	if _, err := rest.ExecuteTask("/customers", map[string]interface{}{"headers": map[string]interface{}{"X-Api-Key": ""}}); err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if _, ok := last.Header["X-Api-Key"]; ok {
+		t.Errorf("Expected the default header to be removed, got %v", last.Header)
+	}
+
+	// Missing path parameter
+	if _, err := rest.ExecuteTask("/customers/{id}", map[string]interface{}{}); err == nil {
+		t.Error("Expected error for missing path parameter")
+	}
+
+	// Form-encoded body
+	_, err = rest.ExecuteTask("/login", map[string]interface{}{
+		"method": "post",
+		"form":   map[string]interface{}{"user": "alice"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if last.Method != http.MethodPost || last.Header.Get("Content-Type") != "application/x-www-form-urlencoded" || lastBody != "user=alice" {
+		t.Errorf("Unexpected form request: %s %s %q", last.Method, last.Header.Get("Content-Type"), lastBody)
+	}
+
+	// Multipart upload
+	_, err = rest.ExecuteTask("/upload", map[string]interface{}{
+		"method": "POST",
+		"multipart": map[string]interface{}{
+			"fields": map[string]interface{}{"folder": "invoices"},
+			"files": []interface{}{
+				map[string]interface{}{"field": "doc", "filename": "a.txt", "contentBase64": "aGVsbG8="},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if !strings.HasPrefix(last.Header.Get("Content-Type"), "multipart/form-data") {
+		t.Fatalf("Expected multipart request, got %s", last.Header.Get("Content-Type"))
+	}
+	if last.MultipartForm.Value["folder"][0] != "invoices" {
+		t.Errorf("Unexpected multipart fields: %v", last.MultipartForm.Value)
+	}
+	file, _, err := last.FormFile("doc")
+	if err != nil {
+		t.Fatalf("Missing uploaded file: %v", err)
+	}
+	content, _ := ioutil.ReadAll(file)
+	if string(content) != "hello" {
+		t.Errorf("Unexpected file content: %q", content)
+	}
+}
+
+func TestRESTAdapterResponseHandling(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/xml":
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.Write([]byte(`<customer id="7"><name>ACME</name><tag>a</tag><tag>b</tag></customer>`))
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("OK 123"))
+		case "/binary":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte{0x25, 0x50, 0x44, 0x46})
+		case "/list":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[1, 2]`))
+		case "/missing":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "no such customer"}`))
+		}
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("test", server.URL, nil, nil)
+
+	result, err := rest.ExecuteTask("/xml", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	customer, _ := result["customer"].(map[string]interface{})
+	if customer["@id"] != "7" || customer["name"] != "ACME" || len(customer["tag"].([]interface{})) != 2 {
+		t.Errorf("Unexpected XML result: %v", result)
+	}
+
+	result, _ = rest.ExecuteTask("/text", map[string]interface{}{})
+	if result["text"] != "OK 123" {
+		t.Errorf("Unexpected text result: %v", result)
+	}
+
+	result, _ = rest.ExecuteTask("/binary", map[string]interface{}{})
+	if result["contentBase64"] != "JVBERg==" || result["contentType"] != "application/pdf" {
+		t.Errorf("Unexpected binary result: %v", result)
+	}
+
+	result, _ = rest.ExecuteTask("/list", map[string]interface{}{})
+	if list, ok := result["data"].([]interface{}); !ok || len(list) != 2 {
+		t.Errorf("Unexpected list result: %v", result)
+	}
+
+	// Error statuses become structured errors
+	_, err = rest.ExecuteTask("/missing", map[string]interface{}{})
+	httpErr, ok := err.(*adapter.HTTPError)
+	if !ok {
+		t.Fatalf("Expected *adapter.HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusNotFound || httpErr.Body["message"] != "no such customer" {
+		t.Errorf("Unexpected error details: %v", httpErr.Details())
+	}
+
+	// Configured success ranges
+	ranges, err := adapter.ParseStatusRanges([]string{"2xx", "404"})
+	if err != nil {
+		t.Fatalf("ParseStatusRanges failed: %v", err)
+	}
+	rest.SuccessStatuses = ranges
+	result, err = rest.ExecuteTask("/missing", map[string]interface{}{})
+	if err != nil || result["message"] != "no such customer" {
+		t.Errorf("Expected 404 to be treated as success, got %v, %v", result, err)
+	}
+
+	if _, err := adapter.ParseStatusRanges([]string{"300-200"}); err == nil {
+		t.Error("Expected error for invalid range")
+	}
+}
+
+func TestRESTAdapterRetry(t *testing.T) {
+	var calls int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "created"}`))
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("test", server.URL, nil, nil)
+	rest.Retry = resilience.NewRetryPolicy()
+	rest.Retry.Sleep = func(time.Duration) {}
+
+	result, err := rest.ExecuteTask("/customers", map[string]interface{}{
+		"method": "POST",
+		"body":   map[string]interface{}{"name": "ACME"},
+	})
+	if err != nil {
+		t.Fatalf("Expected retries to succeed, got %v", err)
+	}
+	if calls != 3 || result["status"] != "created" {
+		t.Errorf("Unexpected result after %d calls: %v", calls, result)
+	}
+	for _, body := range bodies {
+		if body != bodies[0] || body == "" {
+			t.Errorf("Request body was not replayed on retry: %q", bodies)
+		}
+	}
+}
+
+func TestRESTAdapterMaxResponseBody(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": "` + strings.Repeat("x", 4096) + `"}`))
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("test", server.URL, nil, nil)
+	rest.Retry = resilience.NewRetryPolicy()
+	rest.Retry.Sleep = func(time.Duration) {}
+	rest.MaxResponseBody = 1024
+
+	_, err := rest.ExecuteTask("/export", map[string]interface{}{"method": "GET"})
+	var tooLarge *adapter.BodyTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 1024 {
+		t.Fatalf("Expected a body too large error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected an oversized response not to be retried, got %d calls", calls)
+	}
+
+	rest.MaxResponseBody = 8192
+	if result, err := rest.ExecuteTask("/export", map[string]interface{}{"method": "GET"}); err != nil || len(result["data"].(string)) != 4096 {
+		t.Errorf("Expected a response under the limit to be read, got %v", err)
+	}
+}
+
+func TestRESTAdapterTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("test", server.URL, nil, nil)
+	rest.Timeouts = adapter.Timeouts{Read: 50 * time.Millisecond}
+
+	// The adapter read timeout applies to slow responses
+	_, err := rest.ExecuteTask("/slow", map[string]interface{}{})
+	var timeoutErr *adapter.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != "read" {
+		t.Fatalf("Expected a read timeout, got %v", err)
+	}
+
+	if _, err := rest.ExecuteTask("/fast", map[string]interface{}{}); err != nil {
+		t.Fatalf("Expected fast call to succeed, got %v", err)
+	}
+
+	// A mapping-level override in the context replaces the adapter default
+	ctx := adapter.WithTimeouts(context.Background(), adapter.Timeouts{Read: time.Second})
+	if _, err := rest.ExecuteTaskContext(ctx, "/slow", map[string]interface{}{}); err != nil {
+		t.Fatalf("Expected the override to allow the slow call, got %v", err)
+	}
+
+	// The total timeout bounds the call including retries
+	rest.Timeouts = adapter.Timeouts{}
+	ctx = adapter.WithTimeouts(context.Background(), adapter.Timeouts{Total: 50 * time.Millisecond})
+	_, err = rest.ExecuteTaskContext(ctx, "/slow", map[string]interface{}{})
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != "total" {
+		t.Fatalf("Expected a total timeout, got %v", err)
+	}
+}
+
+func TestRESTAdapterConditionalRequests(t *testing.T) {
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"sku": "ABC"}`))
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("test", server.URL, nil, nil)
+	rest.Validators = cache.NewLRU(10)
+	ctx := adapter.WithConditionalKey(context.Background(), "product|ABC")
+
+	result, err := rest.ExecuteTaskContext(ctx, "/products/ABC", nil)
+	if err != nil || result["sku"] != "ABC" {
+		t.Fatalf("Unexpected first result %v, %v", result, err)
+	}
+
+	// The stored ETag makes the second read conditional
+	result, err = rest.ExecuteTaskContext(ctx, "/products/ABC", nil)
+	if err != nil {
+		t.Fatalf("Expected 304 to be handled, got %v", err)
+	}
+	if result["unchanged"] != true || result["etag"] != `"v1"` {
+		t.Errorf("Expected unchanged result, got %v", result)
+	}
+
+	// Calls without a key are never conditional
+	if _, err := rest.ExecuteTask("/products/ABC", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(conditional) != 3 || conditional[0] != "" || conditional[1] != `"v1"` || conditional[2] != "" {
+		t.Errorf("Unexpected If-None-Match headers: %q", conditional)
+	}
+}
+
+func TestRESTAdapterCharset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Latin-1 body sent without a charset
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{\"name\": \"M\xfcller\"}"))
+	}))
+	defer server.Close()
+
+	rest := adapter.NewRESTAdapter("test", server.URL, nil, nil)
+	result, err := rest.ExecuteTask("/customers/1", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result["name"] != "Müller" {
+		t.Errorf("Expected detected conversion, got %q", result["name"])
+	}
+
+	// A per-call override wins over detection
+	ctx := adapter.WithCharset(context.Background(), "iso-8859-15")
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("Total: 5 \xa4"))
+	})
+	result, err = rest.ExecuteTaskContext(ctx, "/totals", nil)
+	if err != nil || result["text"] != "Total: 5 €" {
+		t.Errorf("Expected override conversion, got %v, %v", result, err)
+	}
+}
+
+func TestRESTAdapterBodyFormats(t *testing.T) {
+	var contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		contentType, body = r.Header.Get("Content-Type"), string(data)
+		switch r.URL.Path {
+		case "/orders":
+			// XML sent as plain text, as some legacy servers do
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(`<order id="9"><status>open</status></order>`))
+		case "/status":
+			w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+			w.Write([]byte("status=ok&code=1&code=2"))
+		}
+	}))
+	defer server.Close()
+	rest := adapter.NewRESTAdapter("test", server.URL, nil, nil)
+
+	ctx := adapter.WithResponseFormat(context.Background(), "xml")
+	result, err := rest.ExecuteTaskContext(ctx, "/orders", map[string]interface{}{
+		"method": "POST",
+		"xml": map[string]interface{}{"order": map[string]interface{}{
+			"@type":    "rush",
+			"customer": "A&B",
+			"item":     []interface{}{"widget", "gadget"},
+			"qty":      2.0,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if contentType != "application/xml" || body != `<order type="rush"><customer>A&amp;B</customer><item>widget</item><item>gadget</item><qty>2</qty></order>` {
+		t.Errorf("Unexpected XML request %s %s", contentType, body)
+	}
+	if order, _ := result["order"].(map[string]interface{}); order["@id"] != "9" || order["status"] != "open" {
+		t.Errorf("Expected the response to be parsed as XML, got %v", result)
+	}
+
+	result, err = rest.ExecuteTask("/status", map[string]interface{}{"method": "PUT", "textBody": "STATUS 42"})
+	if err != nil {
+		t.Fatalf("ExecuteTask failed: %v", err)
+	}
+	if !strings.HasPrefix(contentType, "text/plain") || body != "STATUS 42" {
+		t.Errorf("Unexpected text request %s %s", contentType, body)
+	}
+	if codes, _ := result["code"].([]interface{}); result["status"] != "ok" || len(codes) != 2 {
+		t.Errorf("Expected the form response to be parsed, got %v", result)
+	}
+
+	if _, err := rest.ExecuteTask("/status", map[string]interface{}{"textBody": "<ping/>", "contentType": "application/xml"}); err != nil || contentType != "application/xml" {
+		t.Errorf("Expected the text body to be sent as %s, got %s: %v", "application/xml", contentType, err)
+	}
+
+	if _, err := rest.ExecuteTask("/orders", map[string]interface{}{"xml": map[string]interface{}{"a": "1", "b": "2"}}); err == nil {
+		t.Error("Expected an error for an XML body without a single root")
+	}
+}
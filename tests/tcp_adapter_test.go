@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/codec"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// startSocketServer answers every request frame with reply(request) until
+// the test ends, and counts accepted connections
+func startSocketServer(t *testing.T, framing adapter.Framing, reply func([]byte) []byte) (string, *int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var conns int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&conns, 1)
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					request, err := framing.ReadFrame(reader)
+					if err != nil {
+						return
+					}
+					response := reply(request)
+					if response == nil {
+						return
+					}
+					if err := framing.WriteFrame(conn, response); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), &conns
+}
+
+func TestTCPAdapterFraming(t *testing.T) {
+	echo := func(request []byte) []byte { return request }
+
+	for _, framing := range []adapter.Framing{
+		{Mode: adapter.FramingLength, LengthBytes: 2},
+		{Mode: adapter.FramingLength},
+		{Mode: adapter.FramingDelimiter, Delimiter: []byte("\r\n")},
+		{Mode: adapter.FramingDelimiter},
+	} {
+		t.Run(framing.Mode, func(t *testing.T) {
+			tcp := adapter.NewTCPAdapter("legacy", "", framing, nil)
+			if err := tcp.Initialize(); err != nil {
+				t.Fatalf("Initialize: %v", err)
+			}
+			tcp.Address, _ = startSocketServer(t, tcp.Framing, echo)
+
+			result, err := tcp.ExecuteTask("lookup", map[string]interface{}{
+				"body": map[string]interface{}{"customer": "C-42"},
+			})
+			if err != nil {
+				t.Fatalf("ExecuteTask: %v", err)
+			}
+			if result["customer"] != "C-42" {
+				t.Errorf("Expected echoed customer, got %v", result)
+			}
+		})
+	}
+}
+
+func TestTCPAdapterFixedWidthCodec(t *testing.T) {
+	fixed, err := codec.NewFixedWidth([]config.FixedFieldConfig{
+		{Name: "account", Length: 6},
+		{Name: "balance", Length: 8, Type: "int"},
+	})
+	if err != nil {
+		t.Fatalf("NewFixedWidth: %v", err)
+	}
+
+	framing := adapter.Framing{Mode: adapter.FramingFixed, RecordSize: fixed.RecordLength()}
+	var received string
+	address, _ := startSocketServer(t, framing, func(request []byte) []byte {
+		received = string(request)
+		return append(request[:6:6], "00001500"...)
+	})
+
+	tcp := adapter.NewTCPAdapter("ledger", address, framing, nil)
+	tcp.Codec = fixed
+	if err := tcp.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	result, err := tcp.ExecuteTask("balance", map[string]interface{}{"account": "AC1"})
+	if err != nil {
+		t.Fatalf("ExecuteTask: %v", err)
+	}
+	if received != "AC1   00000000" {
+		t.Errorf("Unexpected request record %q", received)
+	}
+	if result["account"] != "AC1" || result["balance"] != int64(1500) {
+		t.Errorf("Unexpected decoded record %v", result)
+	}
+}
+
+func TestTCPAdapterPersistentConnection(t *testing.T) {
+	framing := adapter.Framing{Mode: adapter.FramingDelimiter, Delimiter: []byte("\n")}
+	var calls int32
+	address, conns := startSocketServer(t, framing, func(request []byte) []byte {
+		// Drop the connection after the second call to force a reconnect
+		if atomic.AddInt32(&calls, 1) == 2 {
+			return nil
+		}
+		return []byte(`{"ok":true}`)
+	})
+
+	tcp := adapter.NewTCPAdapter("legacy", address, framing, nil)
+	tcp.Persistent = true
+	defer tcp.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := tcp.ExecuteTask("ping", nil); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if n := atomic.LoadInt32(conns); n != 2 {
+		t.Errorf("Expected 2 connections, got %d", n)
+	}
+}
+
+func TestTCPAdapterReadTimeout(t *testing.T) {
+	framing := adapter.Framing{Mode: adapter.FramingDelimiter, Delimiter: []byte("\n")}
+	address, _ := startSocketServer(t, framing, func(request []byte) []byte {
+		time.Sleep(200 * time.Millisecond)
+		return []byte(`{}`)
+	})
+
+	tcp := adapter.NewTCPAdapter("legacy", address, framing, nil)
+	tcp.Timeouts = adapter.Timeouts{Read: 20 * time.Millisecond}
+
+	_, err := tcp.ExecuteTaskContext(context.Background(), "slow", nil)
+	var timeoutErr *adapter.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != "read" {
+		t.Fatalf("Expected read timeout, got %v", err)
+	}
+}
+
+func TestTCPAdapterRejectsOversizedMessage(t *testing.T) {
+	framing := adapter.Framing{Mode: adapter.FramingLength, LengthBytes: 1}
+	address, _ := startSocketServer(t, framing, func(request []byte) []byte { return request })
+
+	tcp := adapter.NewTCPAdapter("legacy", address, framing, nil)
+	_, err := tcp.ExecuteTask("big", map[string]interface{}{"data": strings.Repeat("x", 300)})
+	if err == nil || !strings.Contains(err.Error(), "length prefix") {
+		t.Fatalf("Expected length prefix error, got %v", err)
+	}
+}
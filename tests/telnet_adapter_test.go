@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2AGateway/a2a-connector/internal/adapter"
+	"github.com/A2AGateway/a2a-connector/internal/config"
+)
+
+// startMenuServer emulates a telnet menu application: a login, a main menu
+// and a customer lookup screen. Negotiation replies from the client are
+// recorded in negotiated.
+func startMenuServer(t *testing.T, negotiated chan<- []byte) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				readLine := func() string {
+					line, _ := reader.ReadString('\n')
+					return strings.TrimRight(line, "\r\n")
+				}
+
+				// IAC WILL ECHO, IAC DO TERMINAL-TYPE
+				conn.Write([]byte{255, 251, 1, 255, 253, 24})
+				conn.Write([]byte("\x1b[2J\x1b[HACME LEDGER\r\nlogin: "))
+				reply := make([]byte, 6)
+				if _, err := io.ReadFull(reader, reply); err == nil {
+					negotiated <- reply
+				}
+				if readLine() != "clerk" {
+					return
+				}
+				conn.Write([]byte("Password: "))
+				if readLine() != "s3cret" {
+					conn.Write([]byte("Login incorrect\r\n"))
+					return
+				}
+				for {
+					conn.Write([]byte("\r\nMAIN MENU\r\n 1) Customer\r\n 9) Exit\r\nSELECT> "))
+					switch readLine() {
+					case "":
+						return
+					case "1":
+						conn.Write([]byte("1\r\nCustomer ID: "))
+						id := readLine()
+						conn.Write([]byte(id + "\r\nNAME: ACME CORP\r\nBALANCE: 1500.00\r\n"))
+					case "9":
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func newMenuAdapter(t *testing.T, address string) *adapter.TelnetAdapter {
+	login, err := adapter.CompileTelnetSteps([]config.TelnetStepConfig{
+		{Expect: `login: $`},
+		{Send: "clerk", Expect: `Password: $`},
+		{Send: "s3cret"},
+	})
+	if err != nil {
+		t.Fatalf("CompileTelnetSteps: %v", err)
+	}
+	telnet := adapter.NewTelnetAdapter("ledger", address, regexp.MustCompile(`SELECT> $`), nil)
+	telnet.Login = login
+	telnet.Logout = "9"
+	telnet.Timeouts = adapter.Timeouts{Read: 2 * time.Second}
+	if err := telnet.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	return telnet
+}
+
+func TestTelnetAdapterScript(t *testing.T) {
+	negotiated := make(chan []byte, 1)
+	telnet := newMenuAdapter(t, startMenuServer(t, negotiated))
+
+	result, err := telnet.ExecuteTask("", map[string]interface{}{
+		"id": "C-42",
+		"script": []interface{}{
+			map[string]interface{}{"send": "1", "expect": `Customer ID: $`},
+			map[string]interface{}{"send": "{id}", "capture": "customer"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask: %v", err)
+	}
+
+	want := "NAME: ACME CORP\nBALANCE: 1500.00\n\nMAIN MENU\n 1) Customer\n 9) Exit"
+	if result["customer"] != want || result["output"] != want {
+		t.Errorf("Unexpected captured output %q", result)
+	}
+
+	// Echo is accepted, the terminal type option refused
+	select {
+	case reply := <-negotiated:
+		if !bytes.Equal(reply, []byte{255, 253, 1, 255, 252, 24}) {
+			t.Errorf("Unexpected negotiation reply %v", reply)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected a negotiation reply")
+	}
+}
+
+func TestTelnetAdapterExpectTimeout(t *testing.T) {
+	telnet := newMenuAdapter(t, startMenuServer(t, make(chan []byte, 1)))
+	telnet.Timeouts = adapter.Timeouts{Read: 50 * time.Millisecond}
+
+	_, err := telnet.ExecuteTask("", map[string]interface{}{
+		"script": []interface{}{
+			map[string]interface{}{"send": "1", "expect": `Order ID: $`},
+		},
+	})
+	var timeoutErr *adapter.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != "read" {
+		t.Fatalf("Expected read timeout, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Customer ID:") {
+		t.Errorf("Expected the last output in the error, got %v", err)
+	}
+}
+
+func TestTelnetAdapterMissingParam(t *testing.T) {
+	telnet := newMenuAdapter(t, "127.0.0.1:1")
+	_, err := telnet.ExecuteTask("", map[string]interface{}{
+		"script": []interface{}{map[string]interface{}{"send": "{id}"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing value for id") {
+		t.Fatalf("Expected missing parameter error, got %v", err)
+	}
+}